@@ -0,0 +1,47 @@
+// tsbs_coordinator runs a load or query benchmark on several client machines
+// at once. It waits for the tsbs_load_* or tsbs_run_queries_* programs
+// started with --coordinator set to its address, assigns each of them a
+// shard of the data or queries, starts them at the same time, and writes
+// the aggregated results of all of them.
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/bodhiye/tsbs/pkg/distributed"
+	"github.com/bodhiye/tsbs/tools/utils"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+var coordinator *distributed.Coordinator
+
+// Parse args:
+func init() {
+	var config distributed.CoordinatorConfig
+	config.AddToFlagSet(pflag.CommandLine)
+
+	pflag.Parse()
+
+	err := utils.SetupConfigFile()
+
+	if err != nil {
+		panic(fmt.Errorf("fatal error config file: %s", err))
+	}
+
+	if err := viper.Unmarshal(&config); err != nil {
+		panic(fmt.Errorf("unable to decode config: %s", err))
+	}
+
+	coordinator, err = distributed.NewCoordinator(config)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func main() {
+	if _, err := coordinator.Run(); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -67,11 +67,11 @@ func main() {
 		defer startMemoryProfile(profileFile)()
 	}
 	target := initializers.GetTarget(config.Format)
-	points, err := dg.Generate(config, target)
+	numPoints, err := dg.Generate(config, target)
 	if err != nil {
 		fmt.Printf("error: %v\n", err)
 	}
-	log.Printf("Data-Generator generated %d points\n", len(points))
+	log.Printf("Data-Generator generated %d points\n", numPoints)
 }
 
 // startMemoryProfile sets up memory profiling to be written to profileFile. It
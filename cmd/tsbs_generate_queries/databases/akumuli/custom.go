@@ -0,0 +1,13 @@
+package akumuli
+
+import (
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/custom"
+	"github.com/bodhiye/tsbs/pkg/query"
+)
+
+// CustomQuery fills in a query rendered from a custom query template, which
+// is the JSON body of the query request.
+func (g *BaseGenerator) CustomQuery(qi query.Query, cq *custom.Query) {
+	humanLabel := cq.HumanLabel("Akumuli")
+	g.fillInQuery(qi, humanLabel, cq.HumanDescription(humanLabel), cq.Query, cq.Interval.StartUnixNano(), cq.Interval.EndUnixNano())
+}
@@ -0,0 +1,62 @@
+package cassandra
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/iot"
+	"github.com/bodhiye/tsbs/pkg/query"
+)
+
+// IoT produces Cassandra-specific queries for the iot query types. A
+// Cassandra HLQuery can only filter series by tags and aggregate them per
+// time bucket, so of the iot queries just the last location ones can be
+// expressed.
+type IoT struct {
+	*BaseGenerator
+	*iot.Core
+}
+
+// NewIoT makes an IoT object ready to generate Queries.
+func NewIoT(start, end time.Time, scale int, g *BaseGenerator) *IoT {
+	c, err := iot.NewCore(start, end, scale)
+	panicIfErr(err)
+	return &IoT{
+		BaseGenerator: g,
+		Core:          c,
+	}
+}
+
+func (i *IoT) getTruckWhereWithNames(names []string) []string {
+	tagSet := []string{}
+	for _, name := range names {
+		tagSet = append(tagSet, "name="+name)
+	}
+	return tagSet
+}
+
+// fillInLastLocQuery fills in a query for the last location of every truck
+// matching the tagSets.
+func (i *IoT) fillInLastLocQuery(qi query.Query, humanLabel, humanDesc string, tagSets [][]string) {
+	i.fillInQuery(qi, humanLabel, humanDesc, "", []string{"latitude", "longitude"}, i.Interval, tagSets)
+	q := qi.(*query.Cassandra)
+	q.MeasurementName = []byte(iot.ReadingsTableName)
+	q.ForEveryN = []byte("name,1")
+}
+
+// LastLocByTruck finds the truck location for nTrucks.
+func (i *IoT) LastLocByTruck(qi query.Query, nTrucks int) {
+	names, err := i.GetRandomTrucks(nTrucks)
+	panicIfErr(err)
+
+	humanLabel := "Cassandra last location by specific truck"
+	humanDesc := fmt.Sprintf("%s: random %4d trucks", humanLabel, nTrucks)
+	i.fillInLastLocQuery(qi, humanLabel, humanDesc, [][]string{i.getTruckWhereWithNames(names)})
+}
+
+// LastLocPerTruck finds all the truck locations of a random fleet.
+func (i *IoT) LastLocPerTruck(qi query.Query) {
+	humanLabel := "Cassandra last location per truck"
+	humanDesc := humanLabel
+	i.fillInLastLocQuery(qi, humanLabel, humanDesc, [][]string{{"fleet=" + i.GetRandomFleet()}})
+}
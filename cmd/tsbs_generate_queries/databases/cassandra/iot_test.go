@@ -0,0 +1,157 @@
+package cassandra
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/iot"
+	"github.com/bodhiye/tsbs/pkg/query"
+)
+
+const (
+	testScale = 10
+)
+
+type IoTTestCase struct {
+	desc               string
+	input              int
+	fail               bool
+	failMsg            string
+	expectedHumanLabel string
+	expectedHumanDesc  string
+	expectedTagSets    [][]string
+}
+
+func TestLastLocByTruck(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc:    "zero trucks",
+			input:   0,
+			fail:    true,
+			failMsg: "number of trucks cannot be < 1; got 0",
+		},
+		{
+			desc:    "more trucks than scale",
+			input:   2 * testScale,
+			fail:    true,
+			failMsg: "number of trucks (20) larger than total trucks. See --scale (10)",
+		},
+		{
+			desc:  "one truck",
+			input: 1,
+
+			expectedHumanLabel: "Cassandra last location by specific truck",
+			expectedHumanDesc:  "Cassandra last location by specific truck: random    1 trucks",
+			expectedTagSets:    [][]string{{"name=truck_5"}},
+		},
+		{
+			desc:  "three trucks",
+			input: 3,
+
+			expectedHumanLabel: "Cassandra last location by specific truck",
+			expectedHumanDesc:  "Cassandra last location by specific truck: random    3 trucks",
+			expectedTagSets:    [][]string{{"name=truck_9", "name=truck_3", "name=truck_5"}},
+		},
+	}
+
+	testFunc := func(i *IoT, c IoTTestCase) query.Query {
+		q := i.GenerateEmptyQuery()
+		i.LastLocByTruck(q, c.input)
+		return q
+	}
+
+	start := time.Unix(0, 0)
+	end := start.Add(time.Hour)
+
+	runIoTTestCases(t, testFunc, start, end, cases)
+}
+
+func TestLastLocPerTruck(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "Cassandra last location per truck",
+			expectedHumanDesc:  "Cassandra last location per truck",
+			expectedTagSets:    [][]string{{"fleet=South"}},
+		},
+	}
+
+	testFunc := func(i *IoT, c IoTTestCase) query.Query {
+		q := i.GenerateEmptyQuery()
+		i.LastLocPerTruck(q)
+		return q
+	}
+
+	start := time.Unix(0, 0)
+	end := start.Add(time.Hour)
+
+	runIoTTestCases(t, testFunc, start, end, cases)
+}
+
+func runIoTTestCases(t *testing.T, testFunc func(*IoT, IoTTestCase) query.Query, s time.Time, e time.Time, cases []IoTTestCase) {
+	rand.Seed(123) // Setting seed for testing purposes.
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			b := BaseGenerator{}
+			dq, err := b.NewIoT(s, e, testScale)
+			if err != nil {
+				t.Fatalf("Error while creating iot generator")
+			}
+			i := dq.(*IoT)
+
+			if c.fail {
+				func() {
+					defer func() {
+						r := recover()
+						if r == nil {
+							t.Fatalf("did not panic when should")
+						}
+
+						if r != c.failMsg {
+							t.Fatalf("incorrect fail message: got %s, want %s", r, c.failMsg)
+						}
+					}()
+
+					testFunc(i, c)
+				}()
+			} else {
+				q := testFunc(i, c)
+
+				verifyLastLocQuery(t, q, c)
+			}
+		})
+	}
+}
+
+func verifyLastLocQuery(t *testing.T, qi query.Query, c IoTTestCase) {
+	q, ok := qi.(*query.Cassandra)
+	if !ok {
+		t.Fatal("Filled query is not *query.Cassandra type")
+	}
+
+	if got := string(q.HumanLabel); got != c.expectedHumanLabel {
+		t.Errorf("incorrect human label:\ngot\n%s\nwant\n%s", got, c.expectedHumanLabel)
+	}
+	if got := string(q.HumanDescription); got != c.expectedHumanDesc {
+		t.Errorf("incorrect human description:\ngot\n%s\nwant\n%s", got, c.expectedHumanDesc)
+	}
+	if got := string(q.MeasurementName); got != iot.ReadingsTableName {
+		t.Errorf("incorrect measurement name: got %s want %s", got, iot.ReadingsTableName)
+	}
+	if got := string(q.FieldName); got != "latitude,longitude" {
+		t.Errorf("incorrect field name: got %s want latitude,longitude", got)
+	}
+	if len(q.AggregationType) != 0 {
+		t.Errorf("incorrect aggregation type: got %s want none", q.AggregationType)
+	}
+	if got := string(q.ForEveryN); got != "name,1" {
+		t.Errorf("incorrect for every: got %s want name,1", got)
+	}
+	if !reflect.DeepEqual(q.TagSets, c.expectedTagSets) {
+		t.Errorf("incorrect tag sets: got %v want %v", q.TagSets, c.expectedTagSets)
+	}
+}
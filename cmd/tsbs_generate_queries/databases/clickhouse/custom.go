@@ -0,0 +1,12 @@
+package clickhouse
+
+import (
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/custom"
+	"github.com/bodhiye/tsbs/pkg/query"
+)
+
+// CustomQuery fills in a query rendered from a custom query template.
+func (g *BaseGenerator) CustomQuery(qi query.Query, cq *custom.Query) {
+	humanLabel := cq.HumanLabel("ClickHouse")
+	g.fillInQuery(qi, humanLabel, cq.HumanDescription(humanLabel), cq.Table, cq.Query)
+}
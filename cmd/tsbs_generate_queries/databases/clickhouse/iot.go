@@ -0,0 +1,472 @@
+package clickhouse
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/iot"
+	"github.com/bodhiye/tsbs/pkg/query"
+)
+
+// IoT produces ClickHouse-specific queries for all the iot query types.
+// Truck tags only live in the separate `tags` table, so unlike devops the
+// iot queries always join it, regardless of UseTags.
+type IoT struct {
+	*iot.Core
+	*BaseGenerator
+}
+
+// NewIoT makes an IoT object ready to generate Queries.
+func NewIoT(start, end time.Time, scale int, g *BaseGenerator) *IoT {
+	c, err := iot.NewCore(start, end, scale)
+	panicIfErr(err)
+	return &IoT{
+		Core:          c,
+		BaseGenerator: g,
+	}
+}
+
+// getTrucksWhereWithNames creates WHERE SQL statement for multiple truck names.
+// NOTE: 'WHERE' itself is not included, just the name filter clause.
+func (i *IoT) getTrucksWhereWithNames(names []string) string {
+	nameClauses := []string{}
+	for _, s := range names {
+		nameClauses = append(nameClauses, fmt.Sprintf("'%s'", s))
+	}
+	return fmt.Sprintf("tags_id IN (SELECT id FROM tags WHERE name IN (%s))", strings.Join(nameClauses, ","))
+}
+
+// getTruckWhereString gets multiple random truck names and creates a WHERE SQL statement for these names.
+func (i *IoT) getTruckWhereString(nTrucks int) string {
+	names, err := i.GetRandomTrucks(nTrucks)
+	panicIfErr(err)
+	return i.getTrucksWhereWithNames(names)
+}
+
+// getFleetWhereString creates WHERE SQL statement for the named trucks of a random fleet.
+func (i *IoT) getFleetWhereString() string {
+	return fmt.Sprintf("tags_id IN (SELECT id FROM tags WHERE name IS NOT NULL AND fleet = '%s')", i.GetRandomFleet())
+}
+
+// LastLocByTruck finds the truck location for nTrucks.
+func (i *IoT) LastLocByTruck(qi query.Query, nTrucks int) {
+	sql := fmt.Sprintf(`
+        SELECT
+            t.name AS name,
+            t.driver AS driver,
+            r.longitude AS longitude,
+            r.latitude AS latitude
+        FROM tags AS t
+        INNER JOIN
+        (
+            SELECT
+                tags_id,
+                argMax(longitude, created_at) AS longitude,
+                argMax(latitude, created_at) AS latitude
+            FROM readings
+            WHERE %s
+            GROUP BY tags_id
+        ) AS r ON r.tags_id = t.id
+        `,
+		i.getTruckWhereString(nTrucks))
+
+	humanLabel := "ClickHouse last location by specific truck"
+	humanDesc := fmt.Sprintf("%s: random %4d trucks", humanLabel, nTrucks)
+
+	i.fillInQuery(qi, humanLabel, humanDesc, iot.ReadingsTableName, sql)
+}
+
+// LastLocPerTruck finds all the truck locations along with truck and driver names.
+func (i *IoT) LastLocPerTruck(qi query.Query) {
+	sql := fmt.Sprintf(`
+        SELECT
+            t.name AS name,
+            t.driver AS driver,
+            r.longitude AS longitude,
+            r.latitude AS latitude
+        FROM tags AS t
+        INNER JOIN
+        (
+            SELECT
+                tags_id,
+                argMax(longitude, created_at) AS longitude,
+                argMax(latitude, created_at) AS latitude
+            FROM readings
+            WHERE %s
+            GROUP BY tags_id
+        ) AS r ON r.tags_id = t.id
+        `,
+		i.getFleetWhereString())
+
+	humanLabel := "ClickHouse last location per truck"
+	humanDesc := humanLabel
+
+	i.fillInQuery(qi, humanLabel, humanDesc, iot.ReadingsTableName, sql)
+}
+
+// TrucksWithLowFuel finds all trucks with low fuel (less than 10%).
+func (i *IoT) TrucksWithLowFuel(qi query.Query) {
+	sql := fmt.Sprintf(`
+        SELECT
+            t.name AS name,
+            t.driver AS driver,
+            d.fuel_state AS fuel_state
+        FROM tags AS t
+        INNER JOIN
+        (
+            SELECT
+                tags_id,
+                argMax(fuel_state, created_at) AS fuel_state
+            FROM diagnostics
+            WHERE %s
+            GROUP BY tags_id
+        ) AS d ON d.tags_id = t.id
+        WHERE d.fuel_state < 0.1
+        `,
+		i.getFleetWhereString())
+
+	humanLabel := "ClickHouse trucks with low fuel"
+	humanDesc := fmt.Sprintf("%s: under 10 percent", humanLabel)
+
+	i.fillInQuery(qi, humanLabel, humanDesc, iot.DiagnosticsTableName, sql)
+}
+
+// TrucksWithHighLoad finds all trucks that have load over 90%.
+func (i *IoT) TrucksWithHighLoad(qi query.Query) {
+	sql := fmt.Sprintf(`
+        SELECT
+            t.name AS name,
+            t.driver AS driver,
+            d.current_load AS current_load
+        FROM tags AS t
+        INNER JOIN
+        (
+            SELECT
+                tags_id,
+                argMax(current_load, created_at) AS current_load
+            FROM diagnostics
+            WHERE %s
+            GROUP BY tags_id
+        ) AS d ON d.tags_id = t.id
+        WHERE d.current_load / t.load_capacity > 0.9
+        `,
+		i.getFleetWhereString())
+
+	humanLabel := "ClickHouse trucks with high load"
+	humanDesc := fmt.Sprintf("%s: over 90 percent", humanLabel)
+
+	i.fillInQuery(qi, humanLabel, humanDesc, iot.DiagnosticsTableName, sql)
+}
+
+// StationaryTrucks finds all trucks that have low average velocity in a time window.
+func (i *IoT) StationaryTrucks(qi query.Query) {
+	interval := i.Interval.MustRandWindow(iot.StationaryDuration)
+	sql := fmt.Sprintf(`
+        SELECT
+            t.name AS name,
+            t.driver AS driver
+        FROM tags AS t
+        INNER JOIN
+        (
+            SELECT tags_id
+            FROM readings
+            WHERE (created_at >= '%s') AND (created_at < '%s') AND %s
+            GROUP BY tags_id
+            HAVING avg(velocity) < 1
+        ) AS r ON r.tags_id = t.id
+        `,
+		interval.Start().Format(clickhouseTimeStringFormat),
+		interval.End().Format(clickhouseTimeStringFormat),
+		i.getFleetWhereString())
+
+	humanLabel := "ClickHouse stationary trucks"
+	humanDesc := fmt.Sprintf("%s: with low avg velocity in last 10 minutes", humanLabel)
+
+	i.fillInQuery(qi, humanLabel, humanDesc, iot.ReadingsTableName, sql)
+}
+
+// TrucksWithLongDrivingSessions finds all trucks that have not stopped at least 20 mins in the last 4 hours.
+func (i *IoT) TrucksWithLongDrivingSessions(qi query.Query) {
+	interval := i.Interval.MustRandWindow(iot.LongDrivingSessionDuration)
+	sql := i.drivingSessionsSQL(interval.Start(), interval.End(),
+		// Calculate number of 10 min intervals that is the max driving duration for the session if we rest 5 mins per hour.
+		tenMinutePeriods(5, iot.LongDrivingSessionDuration))
+
+	humanLabel := "ClickHouse trucks with longer driving sessions"
+	humanDesc := fmt.Sprintf("%s: stopped less than 20 mins in 4 hour period", humanLabel)
+
+	i.fillInQuery(qi, humanLabel, humanDesc, iot.ReadingsTableName, sql)
+}
+
+// TrucksWithLongDailySessions finds all trucks that have driven more than 10 hours in the last 24 hours.
+func (i *IoT) TrucksWithLongDailySessions(qi query.Query) {
+	interval := i.Interval.MustRandWindow(iot.DailyDrivingDuration)
+	sql := i.drivingSessionsSQL(interval.Start(), interval.End(),
+		// Calculate number of 10 min intervals that is the max driving duration for the session if we rest 35 mins per hour.
+		tenMinutePeriods(35, iot.DailyDrivingDuration))
+
+	humanLabel := "ClickHouse trucks with longer daily sessions"
+	humanDesc := fmt.Sprintf("%s: drove more than 10 hours in the last 24 hours", humanLabel)
+
+	i.fillInQuery(qi, humanLabel, humanDesc, iot.ReadingsTableName, sql)
+}
+
+// drivingSessionsSQL selects the trucks of a random fleet that were driving
+// in more than periods ten minute periods between start and end.
+func (i *IoT) drivingSessionsSQL(start, end time.Time, periods int) string {
+	return fmt.Sprintf(`
+        SELECT
+            t.name AS name,
+            t.driver AS driver
+        FROM tags AS t
+        INNER JOIN
+        (
+            SELECT tags_id
+            FROM
+            (
+                SELECT
+                    tags_id,
+                    toStartOfTenMinutes(created_at) AS ten_minutes
+                FROM readings
+                WHERE (created_at >= '%s') AND (created_at < '%s') AND %s
+                GROUP BY
+                    tags_id,
+                    ten_minutes
+                HAVING avg(velocity) > 1
+            )
+            GROUP BY tags_id
+            HAVING count() > %d
+        ) AS r ON r.tags_id = t.id
+        `,
+		start.Format(clickhouseTimeStringFormat),
+		end.Format(clickhouseTimeStringFormat),
+		i.getFleetWhereString(),
+		periods)
+}
+
+// AvgVsProjectedFuelConsumption calculates average and projected fuel consumption per fleet.
+func (i *IoT) AvgVsProjectedFuelConsumption(qi query.Query) {
+	sql := `
+        SELECT
+            t.fleet AS fleet,
+            avg(r.fuel_consumption) AS avg_fuel_consumption,
+            avg(t.nominal_fuel_consumption) AS projected_fuel_consumption
+        FROM readings AS r
+        INNER JOIN tags AS t ON r.tags_id = t.id
+        WHERE (r.velocity > 1)
+            AND (t.fleet IS NOT NULL)
+            AND (t.nominal_fuel_consumption IS NOT NULL)
+            AND (t.name IS NOT NULL)
+        GROUP BY fleet
+        `
+
+	humanLabel := "ClickHouse average vs projected fuel consumption per fleet"
+	humanDesc := humanLabel
+
+	i.fillInQuery(qi, humanLabel, humanDesc, iot.ReadingsTableName, sql)
+}
+
+// AvgDailyDrivingDuration finds the average driving duration per driver.
+func (i *IoT) AvgDailyDrivingDuration(qi query.Query) {
+	sql := `
+        SELECT
+            t.fleet AS fleet,
+            t.name AS name,
+            t.driver AS driver,
+            avg(d.hours) AS avg_daily_hours
+        FROM
+        (
+            SELECT
+                tags_id,
+                toStartOfDay(ten_minutes) AS day,
+                count() / 6 AS hours
+            FROM
+            (
+                SELECT
+                    tags_id,
+                    toStartOfTenMinutes(created_at) AS ten_minutes
+                FROM readings
+                GROUP BY
+                    tags_id,
+                    ten_minutes
+                HAVING avg(velocity) > 1
+            )
+            GROUP BY
+                tags_id,
+                day
+        ) AS d
+        INNER JOIN tags AS t ON t.id = d.tags_id
+        GROUP BY
+            fleet,
+            name,
+            driver
+        `
+
+	humanLabel := "ClickHouse average driver driving duration per day"
+	humanDesc := humanLabel
+
+	i.fillInQuery(qi, humanLabel, humanDesc, iot.ReadingsTableName, sql)
+}
+
+// AvgDailyDrivingSession finds the average driving session without stopping per driver per day.
+func (i *IoT) AvgDailyDrivingSession(qi query.Query) {
+	sql := `
+        SELECT
+            t.name AS name,
+            toStartOfDay(s.start) AS day,
+            avg(s.stop - s.start) AS duration
+        FROM
+        (
+            SELECT
+                tags_id,
+                ten_minutes AS start,
+                leadInFrame(ten_minutes) OVER (PARTITION BY tags_id ORDER BY ten_minutes ROWS BETWEEN UNBOUNDED PRECEDING AND UNBOUNDED FOLLOWING) AS stop,
+                driving
+            FROM
+            (
+                SELECT
+                    tags_id,
+                    ten_minutes,
+                    driving,
+                    lagInFrame(driving) OVER (PARTITION BY tags_id ORDER BY ten_minutes ROWS BETWEEN UNBOUNDED PRECEDING AND UNBOUNDED FOLLOWING) AS prev_driving
+                FROM
+                (
+                    SELECT
+                        tags_id,
+                        toStartOfTenMinutes(created_at) AS ten_minutes,
+                        avg(velocity) > 5 AS driving
+                    FROM readings
+                    GROUP BY
+                        tags_id,
+                        ten_minutes
+                )
+            )
+            WHERE driving != prev_driving
+        ) AS s
+        INNER JOIN tags AS t ON t.id = s.tags_id
+        WHERE (t.name IS NOT NULL) AND (s.driving = 1) AND (s.stop > s.start)
+        GROUP BY
+            name,
+            day
+        ORDER BY
+            name ASC,
+            day ASC
+        `
+
+	humanLabel := "ClickHouse average driver driving session without stopping per day"
+	humanDesc := humanLabel
+
+	i.fillInQuery(qi, humanLabel, humanDesc, iot.ReadingsTableName, sql)
+}
+
+// AvgLoad finds the average load per truck model per fleet.
+func (i *IoT) AvgLoad(qi query.Query) {
+	sql := `
+        SELECT
+            t.fleet AS fleet,
+            t.model AS model,
+            t.load_capacity AS load_capacity,
+            avg(d.avg_load / t.load_capacity) AS avg_load_percentage
+        FROM tags AS t
+        INNER JOIN
+        (
+            SELECT
+                tags_id,
+                avg(current_load) AS avg_load
+            FROM diagnostics
+            GROUP BY tags_id
+        ) AS d ON d.tags_id = t.id
+        WHERE t.name IS NOT NULL
+        GROUP BY
+            fleet,
+            model,
+            load_capacity
+        `
+
+	humanLabel := "ClickHouse average load per truck model per fleet"
+	humanDesc := humanLabel
+
+	i.fillInQuery(qi, humanLabel, humanDesc, iot.DiagnosticsTableName, sql)
+}
+
+// DailyTruckActivity returns the number of hours trucks has been active (not out-of-commission) per day per fleet per model.
+func (i *IoT) DailyTruckActivity(qi query.Query) {
+	sql := `
+        SELECT
+            t.fleet AS fleet,
+            t.model AS model,
+            y.day AS day,
+            sum(y.ten_mins_per_day) / 144 AS daily_activity
+        FROM tags AS t
+        INNER JOIN
+        (
+            SELECT
+                toStartOfDay(created_at) AS day,
+                toStartOfTenMinutes(created_at) AS ten_minutes,
+                tags_id,
+                count() AS ten_mins_per_day
+            FROM diagnostics
+            GROUP BY
+                day,
+                ten_minutes,
+                tags_id
+            HAVING avg(status) < 1
+        ) AS y ON y.tags_id = t.id
+        WHERE t.name IS NOT NULL
+        GROUP BY
+            fleet,
+            model,
+            day
+        ORDER BY day ASC
+        `
+
+	humanLabel := "ClickHouse daily truck activity per fleet per model"
+	humanDesc := humanLabel
+
+	i.fillInQuery(qi, humanLabel, humanDesc, iot.DiagnosticsTableName, sql)
+}
+
+// TruckBreakdownFrequency calculates the amount of times a truck model broke down in the last period.
+func (i *IoT) TruckBreakdownFrequency(qi query.Query) {
+	sql := `
+        SELECT
+            t.model AS model,
+            count() AS breakdowns
+        FROM
+        (
+            SELECT
+                tags_id,
+                broken_down,
+                leadInFrame(broken_down) OVER (PARTITION BY tags_id ORDER BY ten_minutes ROWS BETWEEN UNBOUNDED PRECEDING AND UNBOUNDED FOLLOWING) AS next_broken_down
+            FROM
+            (
+                SELECT
+                    tags_id,
+                    toStartOfTenMinutes(created_at) AS ten_minutes,
+                    countIf(status = 0) / count() >= 0.5 AS broken_down
+                FROM diagnostics
+                GROUP BY
+                    tags_id,
+                    ten_minutes
+            )
+        ) AS b
+        INNER JOIN tags AS t ON t.id = b.tags_id
+        WHERE (t.name IS NOT NULL) AND (b.broken_down = 0) AND (b.next_broken_down = 1)
+        GROUP BY model
+        `
+
+	humanLabel := "ClickHouse truck breakdown frequency per model"
+	humanDesc := humanLabel
+
+	i.fillInQuery(qi, humanLabel, humanDesc, iot.DiagnosticsTableName, sql)
+}
+
+// tenMinutePeriods calculates the number of 10 minute periods that can fit in
+// the time duration if we subtract the minutes specified by minutesPerHour value.
+// E.g.: 4 hours - 5 minutes per hour = 3 hours and 40 minutes = 22 ten minute periods
+func tenMinutePeriods(minutesPerHour float64, duration time.Duration) int {
+	durationMinutes := duration.Minutes()
+	leftover := minutesPerHour * duration.Hours()
+	return int((durationMinutes - leftover) / 10)
+}
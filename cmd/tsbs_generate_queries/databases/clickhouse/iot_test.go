@@ -0,0 +1,724 @@
+package clickhouse
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/bodhiye/tsbs/pkg/query"
+)
+
+const (
+	testScale = 10
+)
+
+type IoTTestCase struct {
+	desc               string
+	input              int
+	fail               bool
+	failMsg            string
+	expectedHumanLabel string
+	expectedHumanDesc  string
+	expectedQuery      string
+}
+
+func TestLastLocByTruck(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc:    "zero trucks",
+			input:   0,
+			fail:    true,
+			failMsg: "number of trucks cannot be < 1; got 0",
+		},
+		{
+			desc:    "more trucks than scale",
+			input:   2 * testScale,
+			fail:    true,
+			failMsg: "number of trucks (20) larger than total trucks. See --scale (10)",
+		},
+		{
+			desc:  "one truck",
+			input: 1,
+
+			expectedHumanLabel: "ClickHouse last location by specific truck",
+			expectedHumanDesc:  "ClickHouse last location by specific truck: random    1 trucks",
+			expectedQuery: `
+        SELECT
+            t.name AS name,
+            t.driver AS driver,
+            r.longitude AS longitude,
+            r.latitude AS latitude
+        FROM tags AS t
+        INNER JOIN
+        (
+            SELECT
+                tags_id,
+                argMax(longitude, created_at) AS longitude,
+                argMax(latitude, created_at) AS latitude
+            FROM readings
+            WHERE tags_id IN (SELECT id FROM tags WHERE name IN ('truck_5'))
+            GROUP BY tags_id
+        ) AS r ON r.tags_id = t.id
+        `,
+		},
+		{
+			desc:  "three trucks",
+			input: 3,
+
+			expectedHumanLabel: "ClickHouse last location by specific truck",
+			expectedHumanDesc:  "ClickHouse last location by specific truck: random    3 trucks",
+			expectedQuery: `
+        SELECT
+            t.name AS name,
+            t.driver AS driver,
+            r.longitude AS longitude,
+            r.latitude AS latitude
+        FROM tags AS t
+        INNER JOIN
+        (
+            SELECT
+                tags_id,
+                argMax(longitude, created_at) AS longitude,
+                argMax(latitude, created_at) AS latitude
+            FROM readings
+            WHERE tags_id IN (SELECT id FROM tags WHERE name IN ('truck_9','truck_3','truck_5'))
+            GROUP BY tags_id
+        ) AS r ON r.tags_id = t.id
+        `,
+		},
+	}
+
+	testFunc := func(i *IoT, c IoTTestCase) query.Query {
+		q := i.GenerateEmptyQuery()
+		i.LastLocByTruck(q, c.input)
+		return q
+	}
+
+	start := time.Unix(0, 0)
+	end := start.Add(time.Hour)
+
+	runIoTTestCases(t, testFunc, start, end, cases)
+}
+
+func TestLastLocPerTruck(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "ClickHouse last location per truck",
+			expectedHumanDesc:  "ClickHouse last location per truck",
+			expectedQuery: `
+        SELECT
+            t.name AS name,
+            t.driver AS driver,
+            r.longitude AS longitude,
+            r.latitude AS latitude
+        FROM tags AS t
+        INNER JOIN
+        (
+            SELECT
+                tags_id,
+                argMax(longitude, created_at) AS longitude,
+                argMax(latitude, created_at) AS latitude
+            FROM readings
+            WHERE tags_id IN (SELECT id FROM tags WHERE name IS NOT NULL AND fleet = 'South')
+            GROUP BY tags_id
+        ) AS r ON r.tags_id = t.id
+        `,
+		},
+	}
+
+	testFunc := func(i *IoT, c IoTTestCase) query.Query {
+		q := i.GenerateEmptyQuery()
+		i.LastLocPerTruck(q)
+		return q
+	}
+
+	start := time.Unix(0, 0)
+	end := start.Add(time.Hour)
+
+	runIoTTestCases(t, testFunc, start, end, cases)
+}
+
+func TestTrucksWithLowFuel(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "ClickHouse trucks with low fuel",
+			expectedHumanDesc:  "ClickHouse trucks with low fuel: under 10 percent",
+			expectedQuery: `
+        SELECT
+            t.name AS name,
+            t.driver AS driver,
+            d.fuel_state AS fuel_state
+        FROM tags AS t
+        INNER JOIN
+        (
+            SELECT
+                tags_id,
+                argMax(fuel_state, created_at) AS fuel_state
+            FROM diagnostics
+            WHERE tags_id IN (SELECT id FROM tags WHERE name IS NOT NULL AND fleet = 'South')
+            GROUP BY tags_id
+        ) AS d ON d.tags_id = t.id
+        WHERE d.fuel_state < 0.1
+        `,
+		},
+	}
+
+	testFunc := func(i *IoT, c IoTTestCase) query.Query {
+		q := i.GenerateEmptyQuery()
+		i.TrucksWithLowFuel(q)
+		return q
+	}
+
+	start := time.Unix(0, 0)
+	end := start.Add(time.Hour)
+
+	runIoTTestCases(t, testFunc, start, end, cases)
+}
+
+func TestTrucksWithHighLoad(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "ClickHouse trucks with high load",
+			expectedHumanDesc:  "ClickHouse trucks with high load: over 90 percent",
+			expectedQuery: `
+        SELECT
+            t.name AS name,
+            t.driver AS driver,
+            d.current_load AS current_load
+        FROM tags AS t
+        INNER JOIN
+        (
+            SELECT
+                tags_id,
+                argMax(current_load, created_at) AS current_load
+            FROM diagnostics
+            WHERE tags_id IN (SELECT id FROM tags WHERE name IS NOT NULL AND fleet = 'South')
+            GROUP BY tags_id
+        ) AS d ON d.tags_id = t.id
+        WHERE d.current_load / t.load_capacity > 0.9
+        `,
+		},
+	}
+
+	testFunc := func(i *IoT, c IoTTestCase) query.Query {
+		q := i.GenerateEmptyQuery()
+		i.TrucksWithHighLoad(q)
+		return q
+	}
+
+	start := time.Unix(0, 0)
+	end := start.Add(time.Hour)
+
+	runIoTTestCases(t, testFunc, start, end, cases)
+}
+
+func TestStationaryTrucks(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "ClickHouse stationary trucks",
+			expectedHumanDesc:  "ClickHouse stationary trucks: with low avg velocity in last 10 minutes",
+			expectedQuery: `
+        SELECT
+            t.name AS name,
+            t.driver AS driver
+        FROM tags AS t
+        INNER JOIN
+        (
+            SELECT tags_id
+            FROM readings
+            WHERE (created_at >= '1970-01-01 00:36:22') AND (created_at < '1970-01-01 00:46:22') AND tags_id IN (SELECT id FROM tags WHERE name IS NOT NULL AND fleet = 'West')
+            GROUP BY tags_id
+            HAVING avg(velocity) < 1
+        ) AS r ON r.tags_id = t.id
+        `,
+		},
+	}
+
+	testFunc := func(i *IoT, c IoTTestCase) query.Query {
+		q := i.GenerateEmptyQuery()
+		i.StationaryTrucks(q)
+		return q
+	}
+
+	start := time.Unix(0, 0)
+	end := start.Add(time.Hour)
+
+	runIoTTestCases(t, testFunc, start, end, cases)
+}
+
+func TestTrucksWithLongDrivingSessions(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "ClickHouse trucks with longer driving sessions",
+			expectedHumanDesc:  "ClickHouse trucks with longer driving sessions: stopped less than 20 mins in 4 hour period",
+			expectedQuery: `
+        SELECT
+            t.name AS name,
+            t.driver AS driver
+        FROM tags AS t
+        INNER JOIN
+        (
+            SELECT tags_id
+            FROM
+            (
+                SELECT
+                    tags_id,
+                    toStartOfTenMinutes(created_at) AS ten_minutes
+                FROM readings
+                WHERE (created_at >= '1970-01-01 00:16:22') AND (created_at < '1970-01-01 04:16:22') AND tags_id IN (SELECT id FROM tags WHERE name IS NOT NULL AND fleet = 'West')
+                GROUP BY
+                    tags_id,
+                    ten_minutes
+                HAVING avg(velocity) > 1
+            )
+            GROUP BY tags_id
+            HAVING count() > 22
+        ) AS r ON r.tags_id = t.id
+        `,
+		},
+	}
+
+	testFunc := func(i *IoT, c IoTTestCase) query.Query {
+		q := i.GenerateEmptyQuery()
+		i.TrucksWithLongDrivingSessions(q)
+		return q
+	}
+
+	start := time.Unix(0, 0)
+	end := start.Add(6 * time.Hour)
+
+	runIoTTestCases(t, testFunc, start, end, cases)
+}
+
+func TestTrucksWithLongDailySessions(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "ClickHouse trucks with longer daily sessions",
+			expectedHumanDesc:  "ClickHouse trucks with longer daily sessions: drove more than 10 hours in the last 24 hours",
+			expectedQuery: `
+        SELECT
+            t.name AS name,
+            t.driver AS driver
+        FROM tags AS t
+        INNER JOIN
+        (
+            SELECT tags_id
+            FROM
+            (
+                SELECT
+                    tags_id,
+                    toStartOfTenMinutes(created_at) AS ten_minutes
+                FROM readings
+                WHERE (created_at >= '1970-01-01 00:16:22') AND (created_at < '1970-01-02 00:16:22') AND tags_id IN (SELECT id FROM tags WHERE name IS NOT NULL AND fleet = 'West')
+                GROUP BY
+                    tags_id,
+                    ten_minutes
+                HAVING avg(velocity) > 1
+            )
+            GROUP BY tags_id
+            HAVING count() > 60
+        ) AS r ON r.tags_id = t.id
+        `,
+		},
+	}
+
+	testFunc := func(i *IoT, c IoTTestCase) query.Query {
+		q := i.GenerateEmptyQuery()
+		i.TrucksWithLongDailySessions(q)
+		return q
+	}
+
+	start := time.Unix(0, 0)
+	end := start.Add(25 * time.Hour)
+
+	runIoTTestCases(t, testFunc, start, end, cases)
+}
+
+func TestAvgVsProjectedFuelConsumption(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "ClickHouse average vs projected fuel consumption per fleet",
+			expectedHumanDesc:  "ClickHouse average vs projected fuel consumption per fleet",
+			expectedQuery: `
+        SELECT
+            t.fleet AS fleet,
+            avg(r.fuel_consumption) AS avg_fuel_consumption,
+            avg(t.nominal_fuel_consumption) AS projected_fuel_consumption
+        FROM readings AS r
+        INNER JOIN tags AS t ON r.tags_id = t.id
+        WHERE (r.velocity > 1)
+            AND (t.fleet IS NOT NULL)
+            AND (t.nominal_fuel_consumption IS NOT NULL)
+            AND (t.name IS NOT NULL)
+        GROUP BY fleet
+        `,
+		},
+	}
+
+	testFunc := func(i *IoT, c IoTTestCase) query.Query {
+		q := i.GenerateEmptyQuery()
+		i.AvgVsProjectedFuelConsumption(q)
+		return q
+	}
+
+	start := time.Unix(0, 0)
+	end := start.Add(25 * time.Hour)
+
+	runIoTTestCases(t, testFunc, start, end, cases)
+}
+
+func TestAvgDailyDrivingDuration(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "ClickHouse average driver driving duration per day",
+			expectedHumanDesc:  "ClickHouse average driver driving duration per day",
+			expectedQuery: `
+        SELECT
+            t.fleet AS fleet,
+            t.name AS name,
+            t.driver AS driver,
+            avg(d.hours) AS avg_daily_hours
+        FROM
+        (
+            SELECT
+                tags_id,
+                toStartOfDay(ten_minutes) AS day,
+                count() / 6 AS hours
+            FROM
+            (
+                SELECT
+                    tags_id,
+                    toStartOfTenMinutes(created_at) AS ten_minutes
+                FROM readings
+                GROUP BY
+                    tags_id,
+                    ten_minutes
+                HAVING avg(velocity) > 1
+            )
+            GROUP BY
+                tags_id,
+                day
+        ) AS d
+        INNER JOIN tags AS t ON t.id = d.tags_id
+        GROUP BY
+            fleet,
+            name,
+            driver
+        `,
+		},
+	}
+
+	testFunc := func(i *IoT, c IoTTestCase) query.Query {
+		q := i.GenerateEmptyQuery()
+		i.AvgDailyDrivingDuration(q)
+		return q
+	}
+
+	start := time.Unix(0, 0)
+	end := start.Add(25 * time.Hour)
+
+	runIoTTestCases(t, testFunc, start, end, cases)
+}
+
+func TestAvgDailyDrivingSession(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "ClickHouse average driver driving session without stopping per day",
+			expectedHumanDesc:  "ClickHouse average driver driving session without stopping per day",
+			expectedQuery: `
+        SELECT
+            t.name AS name,
+            toStartOfDay(s.start) AS day,
+            avg(s.stop - s.start) AS duration
+        FROM
+        (
+            SELECT
+                tags_id,
+                ten_minutes AS start,
+                leadInFrame(ten_minutes) OVER (PARTITION BY tags_id ORDER BY ten_minutes ROWS BETWEEN UNBOUNDED PRECEDING AND UNBOUNDED FOLLOWING) AS stop,
+                driving
+            FROM
+            (
+                SELECT
+                    tags_id,
+                    ten_minutes,
+                    driving,
+                    lagInFrame(driving) OVER (PARTITION BY tags_id ORDER BY ten_minutes ROWS BETWEEN UNBOUNDED PRECEDING AND UNBOUNDED FOLLOWING) AS prev_driving
+                FROM
+                (
+                    SELECT
+                        tags_id,
+                        toStartOfTenMinutes(created_at) AS ten_minutes,
+                        avg(velocity) > 5 AS driving
+                    FROM readings
+                    GROUP BY
+                        tags_id,
+                        ten_minutes
+                )
+            )
+            WHERE driving != prev_driving
+        ) AS s
+        INNER JOIN tags AS t ON t.id = s.tags_id
+        WHERE (t.name IS NOT NULL) AND (s.driving = 1) AND (s.stop > s.start)
+        GROUP BY
+            name,
+            day
+        ORDER BY
+            name ASC,
+            day ASC
+        `,
+		},
+	}
+
+	testFunc := func(i *IoT, c IoTTestCase) query.Query {
+		q := i.GenerateEmptyQuery()
+		i.AvgDailyDrivingSession(q)
+		return q
+	}
+
+	start := time.Unix(0, 0)
+	end := start.Add(25 * time.Hour)
+
+	runIoTTestCases(t, testFunc, start, end, cases)
+}
+
+func TestAvgLoad(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "ClickHouse average load per truck model per fleet",
+			expectedHumanDesc:  "ClickHouse average load per truck model per fleet",
+			expectedQuery: `
+        SELECT
+            t.fleet AS fleet,
+            t.model AS model,
+            t.load_capacity AS load_capacity,
+            avg(d.avg_load / t.load_capacity) AS avg_load_percentage
+        FROM tags AS t
+        INNER JOIN
+        (
+            SELECT
+                tags_id,
+                avg(current_load) AS avg_load
+            FROM diagnostics
+            GROUP BY tags_id
+        ) AS d ON d.tags_id = t.id
+        WHERE t.name IS NOT NULL
+        GROUP BY
+            fleet,
+            model,
+            load_capacity
+        `,
+		},
+	}
+
+	testFunc := func(i *IoT, c IoTTestCase) query.Query {
+		q := i.GenerateEmptyQuery()
+		i.AvgLoad(q)
+		return q
+	}
+
+	start := time.Unix(0, 0)
+	end := start.Add(25 * time.Hour)
+
+	runIoTTestCases(t, testFunc, start, end, cases)
+}
+
+func TestDailyTruckActivity(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "ClickHouse daily truck activity per fleet per model",
+			expectedHumanDesc:  "ClickHouse daily truck activity per fleet per model",
+			expectedQuery: `
+        SELECT
+            t.fleet AS fleet,
+            t.model AS model,
+            y.day AS day,
+            sum(y.ten_mins_per_day) / 144 AS daily_activity
+        FROM tags AS t
+        INNER JOIN
+        (
+            SELECT
+                toStartOfDay(created_at) AS day,
+                toStartOfTenMinutes(created_at) AS ten_minutes,
+                tags_id,
+                count() AS ten_mins_per_day
+            FROM diagnostics
+            GROUP BY
+                day,
+                ten_minutes,
+                tags_id
+            HAVING avg(status) < 1
+        ) AS y ON y.tags_id = t.id
+        WHERE t.name IS NOT NULL
+        GROUP BY
+            fleet,
+            model,
+            day
+        ORDER BY day ASC
+        `,
+		},
+	}
+
+	testFunc := func(i *IoT, c IoTTestCase) query.Query {
+		q := i.GenerateEmptyQuery()
+		i.DailyTruckActivity(q)
+		return q
+	}
+
+	start := time.Unix(0, 0)
+	end := start.Add(25 * time.Hour)
+
+	runIoTTestCases(t, testFunc, start, end, cases)
+}
+
+func TestTruckBreakdownFrequency(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "ClickHouse truck breakdown frequency per model",
+			expectedHumanDesc:  "ClickHouse truck breakdown frequency per model",
+			expectedQuery: `
+        SELECT
+            t.model AS model,
+            count() AS breakdowns
+        FROM
+        (
+            SELECT
+                tags_id,
+                broken_down,
+                leadInFrame(broken_down) OVER (PARTITION BY tags_id ORDER BY ten_minutes ROWS BETWEEN UNBOUNDED PRECEDING AND UNBOUNDED FOLLOWING) AS next_broken_down
+            FROM
+            (
+                SELECT
+                    tags_id,
+                    toStartOfTenMinutes(created_at) AS ten_minutes,
+                    countIf(status = 0) / count() >= 0.5 AS broken_down
+                FROM diagnostics
+                GROUP BY
+                    tags_id,
+                    ten_minutes
+            )
+        ) AS b
+        INNER JOIN tags AS t ON t.id = b.tags_id
+        WHERE (t.name IS NOT NULL) AND (b.broken_down = 0) AND (b.next_broken_down = 1)
+        GROUP BY model
+        `,
+		},
+	}
+
+	testFunc := func(i *IoT, c IoTTestCase) query.Query {
+		q := i.GenerateEmptyQuery()
+		i.TruckBreakdownFrequency(q)
+		return q
+	}
+
+	start := time.Unix(0, 0)
+	end := start.Add(25 * time.Hour)
+
+	runIoTTestCases(t, testFunc, start, end, cases)
+}
+
+func TestTenMinutePeriods(t *testing.T) {
+	cases := []struct {
+		minutesPerHour float64
+		duration       time.Duration
+		result         int
+	}{
+		{
+			minutesPerHour: 5.0,
+			duration:       4 * time.Hour,
+			result:         22,
+		},
+		{
+			minutesPerHour: 10.0,
+			duration:       24 * time.Hour,
+			result:         120,
+		},
+		{
+			minutesPerHour: 0.0,
+			duration:       24 * time.Hour,
+			result:         144,
+		},
+		{
+			minutesPerHour: 1.0,
+			duration:       0 * time.Minute,
+			result:         0,
+		},
+		{
+			minutesPerHour: 0.0,
+			duration:       0 * time.Minute,
+			result:         0,
+		},
+		{
+			minutesPerHour: 1.0,
+			duration:       30 * time.Minute,
+			result:         2,
+		},
+	}
+
+	for _, c := range cases {
+		if got := tenMinutePeriods(c.minutesPerHour, c.duration); got != c.result {
+			t.Errorf("incorrect result for %.2f minutes per hour, duration %s: got %d want %d", c.minutesPerHour, c.duration.String(), got, c.result)
+		}
+	}
+
+}
+
+func runIoTTestCases(t *testing.T, testFunc func(*IoT, IoTTestCase) query.Query, s time.Time, e time.Time, cases []IoTTestCase) {
+	rand.Seed(123) // Setting seed for testing purposes.
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			b := BaseGenerator{}
+			dq, err := b.NewIoT(s, e, testScale)
+			if err != nil {
+				t.Fatalf("Error while creating iot generator")
+			}
+			i := dq.(*IoT)
+
+			if c.fail {
+				func() {
+					defer func() {
+						r := recover()
+						if r == nil {
+							t.Fatalf("did not panic when should")
+						}
+
+						if r != c.failMsg {
+							t.Fatalf("incorrect fail message: got %s, want %s", r, c.failMsg)
+						}
+					}()
+
+					testFunc(i, c)
+				}()
+			} else {
+				q := testFunc(i, c)
+
+				verifyQuery(t, q, c.expectedHumanLabel, c.expectedHumanDesc, c.expectedQuery)
+			}
+		})
+	}
+}
@@ -0,0 +1,13 @@
+package cratedb
+
+import (
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/custom"
+	"github.com/bodhiye/tsbs/pkg/query"
+)
+
+// CustomQuery fills in a query rendered from a custom query template.
+func (g *BaseGenerator) CustomQuery(qi query.Query, cq *custom.Query) {
+	humanLabel := cq.HumanLabel("CrateDB")
+	g.fillInQuery(qi, humanLabel, cq.HumanDescription(humanLabel), cq.Query)
+	qi.(*query.CrateDB).Table = []byte(cq.Table)
+}
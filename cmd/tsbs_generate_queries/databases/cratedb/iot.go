@@ -0,0 +1,356 @@
+package cratedb
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/iot"
+	"github.com/bodhiye/tsbs/pkg/query"
+)
+
+// IoT produces CrateDB-specific queries for all the iot query types.
+type IoT struct {
+	*BaseGenerator
+	*iot.Core
+}
+
+// NewIoT makes an IoT object ready to generate Queries.
+func NewIoT(start, end time.Time, scale int, g *BaseGenerator) *IoT {
+	c, err := iot.NewCore(start, end, scale)
+	panicIfErr(err)
+	return &IoT{
+		BaseGenerator: g,
+		Core:          c,
+	}
+}
+
+// fillInIoTQuery fills the query struct with data, like fillInQuery, but
+// for the given iot table instead of cpu.
+func (i *IoT) fillInIoTQuery(qi query.Query, humanLabel, humanDesc, table, sql string) {
+	i.fillInQuery(qi, humanLabel, humanDesc, sql)
+	qi.(*query.CrateDB).Table = []byte(table)
+}
+
+// LastLocByTruck finds the truck location for nTrucks.
+//
+// Queries:
+// single-last-loc
+func (i *IoT) LastLocByTruck(qi query.Query, nTrucks int) {
+	trucks, err := i.GetRandomTrucks(nTrucks)
+	panicIfErr(err)
+
+	sql := fmt.Sprintf(`
+		SELECT tags['name'] AS name, tags['driver'] AS driver,
+			max_by(longitude, ts) AS longitude,
+			max_by(latitude, ts) AS latitude
+		FROM readings
+		WHERE tags['name'] IN ('%s')
+		GROUP BY 1, 2`,
+		strings.Join(trucks, "', '"))
+
+	humanLabel := "CrateDB last location by specific truck"
+	humanDesc := fmt.Sprintf("%s: random %4d trucks", humanLabel, nTrucks)
+	i.fillInIoTQuery(qi, humanLabel, humanDesc, iot.ReadingsTableName, sql)
+}
+
+// LastLocPerTruck finds all the truck locations along with truck and driver names.
+//
+// Queries:
+// last-loc
+func (i *IoT) LastLocPerTruck(qi query.Query) {
+	sql := fmt.Sprintf(`
+		SELECT tags['name'] AS name, tags['driver'] AS driver,
+			max_by(longitude, ts) AS longitude,
+			max_by(latitude, ts) AS latitude
+		FROM readings
+		WHERE tags['fleet'] = '%s'
+		  AND tags['name'] IS NOT NULL
+		GROUP BY 1, 2`,
+		i.GetRandomFleet())
+
+	humanLabel := "CrateDB last location per truck"
+	humanDesc := humanLabel
+	i.fillInIoTQuery(qi, humanLabel, humanDesc, iot.ReadingsTableName, sql)
+}
+
+// TrucksWithLowFuel finds all trucks with low fuel (less than 10%).
+//
+// Queries:
+// low-fuel
+func (i *IoT) TrucksWithLowFuel(qi query.Query) {
+	sql := fmt.Sprintf(`
+		SELECT tags['name'] AS name, tags['driver'] AS driver,
+			max_by(fuel_state, ts) AS fuel_state
+		FROM diagnostics
+		WHERE tags['fleet'] = '%s'
+		  AND tags['name'] IS NOT NULL
+		GROUP BY 1, 2
+		HAVING max_by(fuel_state, ts) < 0.1`,
+		i.GetRandomFleet())
+
+	humanLabel := "CrateDB trucks with low fuel"
+	humanDesc := fmt.Sprintf("%s: under 10 percent", humanLabel)
+	i.fillInIoTQuery(qi, humanLabel, humanDesc, iot.DiagnosticsTableName, sql)
+}
+
+// TrucksWithHighLoad finds all trucks that have load over 90%.
+//
+// Queries:
+// high-load
+func (i *IoT) TrucksWithHighLoad(qi query.Query) {
+	sql := fmt.Sprintf(`
+		SELECT tags['name'] AS name, tags['driver'] AS driver,
+			tags['load_capacity'] AS load_capacity,
+			max_by(current_load, ts) AS current_load
+		FROM diagnostics
+		WHERE tags['fleet'] = '%s'
+		  AND tags['name'] IS NOT NULL
+		GROUP BY 1, 2, 3
+		HAVING max_by(current_load, ts) / tags['load_capacity'] > 0.9`,
+		i.GetRandomFleet())
+
+	humanLabel := "CrateDB trucks with high load"
+	humanDesc := fmt.Sprintf("%s: over 90 percent", humanLabel)
+	i.fillInIoTQuery(qi, humanLabel, humanDesc, iot.DiagnosticsTableName, sql)
+}
+
+// StationaryTrucks finds all trucks that have low average velocity in a time window.
+//
+// Queries:
+// stationary-trucks
+func (i *IoT) StationaryTrucks(qi query.Query) {
+	interval := i.Interval.MustRandWindow(iot.StationaryDuration)
+	sql := fmt.Sprintf(`
+		SELECT tags['name'] AS name, tags['driver'] AS driver
+		FROM readings
+		WHERE ts >= %d
+		  AND ts < %d
+		  AND tags['fleet'] = '%s'
+		  AND tags['name'] IS NOT NULL
+		GROUP BY 1, 2
+		HAVING avg(velocity) < 1`,
+		interval.StartUnixMillis(),
+		interval.EndUnixMillis(),
+		i.GetRandomFleet())
+
+	humanLabel := "CrateDB stationary trucks"
+	humanDesc := fmt.Sprintf("%s: with low avg velocity in last 10 minutes", humanLabel)
+	i.fillInIoTQuery(qi, humanLabel, humanDesc, iot.ReadingsTableName, sql)
+}
+
+// TrucksWithLongDrivingSessions finds all trucks that have not stopped at least 20 mins in the last 4 hours.
+//
+// Queries:
+// long-driving-sessions
+func (i *IoT) TrucksWithLongDrivingSessions(qi query.Query) {
+	interval := i.Interval.MustRandWindow(iot.LongDrivingSessionDuration)
+	// Calculate number of 10 min intervals that is the max driving duration for the session if we rest 5 mins per hour.
+	sql := i.drivingSessionsSQL(interval.StartUnixMillis(), interval.EndUnixMillis(), tenMinutePeriods(5, iot.LongDrivingSessionDuration))
+
+	humanLabel := "CrateDB trucks with longer driving sessions"
+	humanDesc := fmt.Sprintf("%s: stopped less than 20 mins in 4 hour period", humanLabel)
+	i.fillInIoTQuery(qi, humanLabel, humanDesc, iot.ReadingsTableName, sql)
+}
+
+// TrucksWithLongDailySessions finds all trucks that have driven more than 10 hours in the last 24 hours.
+//
+// Queries:
+// long-daily-sessions
+func (i *IoT) TrucksWithLongDailySessions(qi query.Query) {
+	interval := i.Interval.MustRandWindow(iot.DailyDrivingDuration)
+	// Calculate number of 10 min intervals that is the max driving duration for the session if we rest 35 mins per hour.
+	sql := i.drivingSessionsSQL(interval.StartUnixMillis(), interval.EndUnixMillis(), tenMinutePeriods(35, iot.DailyDrivingDuration))
+
+	humanLabel := "CrateDB trucks with longer daily sessions"
+	humanDesc := fmt.Sprintf("%s: drove more than 10 hours in the last 24 hours", humanLabel)
+	i.fillInIoTQuery(qi, humanLabel, humanDesc, iot.ReadingsTableName, sql)
+}
+
+// drivingSessionsSQL selects the trucks of a random fleet that were driving
+// in more than periods ten minute periods between start and end.
+func (i *IoT) drivingSessionsSQL(start, end int64, periods int) string {
+	return fmt.Sprintf(`
+		SELECT name, driver
+		FROM (
+			SELECT tags['name'] AS name, tags['driver'] AS driver,
+				date_bin('10 minutes'::INTERVAL, ts, 0) AS ten_minutes
+			FROM readings
+			WHERE ts >= %d
+			  AND ts < %d
+			  AND tags['fleet'] = '%s'
+			  AND tags['name'] IS NOT NULL
+			GROUP BY 1, 2, 3
+			HAVING avg(velocity) > 1
+		) AS r
+		GROUP BY 1, 2
+		HAVING count(*) > %d`,
+		start,
+		end,
+		i.GetRandomFleet(),
+		periods)
+}
+
+// AvgVsProjectedFuelConsumption calculates average and projected fuel consumption per fleet.
+//
+// Queries:
+// avg-vs-projected-fuel-consumption
+func (i *IoT) AvgVsProjectedFuelConsumption(qi query.Query) {
+	sql := `
+		SELECT tags['fleet'] AS fleet,
+			avg(fuel_consumption) AS avg_fuel_consumption,
+			avg(tags['nominal_fuel_consumption']) AS projected_fuel_consumption
+		FROM readings
+		WHERE velocity > 1
+		  AND tags['fleet'] IS NOT NULL
+		  AND tags['nominal_fuel_consumption'] IS NOT NULL
+		  AND tags['name'] IS NOT NULL
+		GROUP BY 1`
+
+	humanLabel := "CrateDB average vs projected fuel consumption per fleet"
+	humanDesc := humanLabel
+	i.fillInIoTQuery(qi, humanLabel, humanDesc, iot.ReadingsTableName, sql)
+}
+
+// AvgDailyDrivingDuration finds the average driving duration per driver.
+//
+// Queries:
+// avg-daily-driving-duration
+func (i *IoT) AvgDailyDrivingDuration(qi query.Query) {
+	sql := `
+		SELECT fleet, name, driver, avg(hours) AS avg_daily_hours
+		FROM (
+			SELECT fleet, name, driver, date_trunc('day', ten_minutes) AS day, count(*) / 6 AS hours
+			FROM (
+				SELECT tags['fleet'] AS fleet, tags['name'] AS name, tags['driver'] AS driver,
+					date_bin('10 minutes'::INTERVAL, ts, 0) AS ten_minutes
+				FROM readings
+				WHERE tags['name'] IS NOT NULL
+				GROUP BY 1, 2, 3, 4
+				HAVING avg(velocity) > 1
+			) AS s
+			GROUP BY 1, 2, 3, 4
+		) AS d
+		GROUP BY 1, 2, 3`
+
+	humanLabel := "CrateDB average driver driving duration per day"
+	humanDesc := humanLabel
+	i.fillInIoTQuery(qi, humanLabel, humanDesc, iot.ReadingsTableName, sql)
+}
+
+// AvgDailyDrivingSession finds the average driving session without stopping per driver per day.
+//
+// Queries:
+// avg-daily-driving-session
+func (i *IoT) AvgDailyDrivingSession(qi query.Query) {
+	sql := `
+		SELECT name, date_trunc('day', start) AS day,
+			avg(stop::BIGINT - start::BIGINT) AS duration_ms
+		FROM (
+			SELECT name, ten_minutes AS start, driving,
+				lead(ten_minutes) OVER (PARTITION BY name ORDER BY ten_minutes) AS stop
+			FROM (
+				SELECT name, ten_minutes, driving,
+					lag(driving) OVER (PARTITION BY name ORDER BY ten_minutes) AS prev_driving
+				FROM (
+					SELECT tags['name'] AS name,
+						date_bin('10 minutes'::INTERVAL, ts, 0) AS ten_minutes,
+						avg(velocity) > 5 AS driving
+					FROM readings
+					WHERE tags['name'] IS NOT NULL
+					GROUP BY 1, 2
+				) AS s
+			) AS c
+			WHERE driving <> prev_driving
+		) AS d
+		WHERE driving = true
+		GROUP BY 1, 2
+		ORDER BY 1, 2`
+
+	humanLabel := "CrateDB average driver driving session without stopping per day"
+	humanDesc := humanLabel
+	i.fillInIoTQuery(qi, humanLabel, humanDesc, iot.ReadingsTableName, sql)
+}
+
+// AvgLoad finds the average load per truck model per fleet.
+//
+// Queries:
+// avg-load
+func (i *IoT) AvgLoad(qi query.Query) {
+	sql := `
+		SELECT fleet, model, load_capacity, avg(avg_load / load_capacity) AS avg_load_percentage
+		FROM (
+			SELECT tags['name'] AS name, tags['fleet'] AS fleet, tags['model'] AS model,
+				tags['load_capacity'] AS load_capacity,
+				avg(current_load) AS avg_load
+			FROM diagnostics
+			WHERE tags['name'] IS NOT NULL
+			GROUP BY 1, 2, 3, 4
+		) AS d
+		GROUP BY 1, 2, 3`
+
+	humanLabel := "CrateDB average load per truck model per fleet"
+	humanDesc := humanLabel
+	i.fillInIoTQuery(qi, humanLabel, humanDesc, iot.DiagnosticsTableName, sql)
+}
+
+// DailyTruckActivity returns the number of hours trucks has been active (not out-of-commission) per day per fleet per model.
+//
+// Queries:
+// daily-activity
+func (i *IoT) DailyTruckActivity(qi query.Query) {
+	sql := `
+		SELECT fleet, model, day, count(*) / 144.0 AS daily_activity
+		FROM (
+			SELECT tags['name'] AS name, tags['fleet'] AS fleet, tags['model'] AS model,
+				date_trunc('day', ts) AS day,
+				date_bin('10 minutes'::INTERVAL, ts, 0) AS ten_minutes
+			FROM diagnostics
+			WHERE tags['name'] IS NOT NULL
+			GROUP BY 1, 2, 3, 4, 5
+			HAVING avg(status) < 1
+		) AS a
+		GROUP BY 1, 2, 3
+		ORDER BY 3`
+
+	humanLabel := "CrateDB daily truck activity per fleet per model"
+	humanDesc := humanLabel
+	i.fillInIoTQuery(qi, humanLabel, humanDesc, iot.DiagnosticsTableName, sql)
+}
+
+// TruckBreakdownFrequency calculates the amount of times a truck model broke down in the last period.
+//
+// Queries:
+// breakdown-frequency
+func (i *IoT) TruckBreakdownFrequency(qi query.Query) {
+	sql := `
+		SELECT model, count(*) AS breakdowns
+		FROM (
+			SELECT model, broken_down,
+				lead(broken_down) OVER (PARTITION BY name ORDER BY ten_minutes) AS next_broken_down
+			FROM (
+				SELECT tags['name'] AS name, tags['model'] AS model,
+					date_bin('10 minutes'::INTERVAL, ts, 0) AS ten_minutes,
+					sum(CASE WHEN status = 0 THEN 1 ELSE 0 END) / count(*)::DOUBLE >= 0.5 AS broken_down
+				FROM diagnostics
+				WHERE tags['name'] IS NOT NULL
+				GROUP BY 1, 2, 3
+			) AS b
+		) AS c
+		WHERE broken_down = false
+		  AND next_broken_down = true
+		GROUP BY 1`
+
+	humanLabel := "CrateDB truck breakdown frequency per model"
+	humanDesc := humanLabel
+	i.fillInIoTQuery(qi, humanLabel, humanDesc, iot.DiagnosticsTableName, sql)
+}
+
+// tenMinutePeriods calculates the number of 10 minute periods that can fit in
+// the time duration if we subtract the minutes specified by minutesPerHour value.
+// E.g.: 4 hours - 5 minutes per hour = 3 hours and 40 minutes = 22 ten minute periods
+func tenMinutePeriods(minutesPerHour float64, duration time.Duration) int {
+	durationMinutes := duration.Minutes()
+	leftover := minutesPerHour * duration.Hours()
+	return int((durationMinutes - leftover) / 10)
+}
@@ -0,0 +1,612 @@
+package cratedb
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/bodhiye/tsbs/pkg/query"
+)
+
+type IoTTestCase struct {
+	desc               string
+	input              int
+	fail               bool
+	failMsg            string
+	expectedHumanLabel string
+	expectedHumanDesc  string
+	expectedTable      string
+	expectedQuery      string
+}
+
+func TestLastLocByTruck(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc:    "zero trucks",
+			input:   0,
+			fail:    true,
+			failMsg: "number of trucks cannot be < 1; got 0",
+		},
+		{
+			desc:    "more trucks than scale",
+			input:   2 * testScale,
+			fail:    true,
+			failMsg: "number of trucks (20) larger than total trucks. See --scale (10)",
+		},
+		{
+			desc:  "one truck",
+			input: 1,
+
+			expectedHumanLabel: "CrateDB last location by specific truck",
+			expectedHumanDesc:  "CrateDB last location by specific truck: random    1 trucks",
+			expectedTable:      "readings",
+			expectedQuery: `
+		SELECT tags['name'] AS name, tags['driver'] AS driver,
+			max_by(longitude, ts) AS longitude,
+			max_by(latitude, ts) AS latitude
+		FROM readings
+		WHERE tags['name'] IN ('truck_5')
+		GROUP BY 1, 2`,
+		},
+		{
+			desc:  "three trucks",
+			input: 3,
+
+			expectedHumanLabel: "CrateDB last location by specific truck",
+			expectedHumanDesc:  "CrateDB last location by specific truck: random    3 trucks",
+			expectedTable:      "readings",
+			expectedQuery: `
+		SELECT tags['name'] AS name, tags['driver'] AS driver,
+			max_by(longitude, ts) AS longitude,
+			max_by(latitude, ts) AS latitude
+		FROM readings
+		WHERE tags['name'] IN ('truck_9', 'truck_3', 'truck_5')
+		GROUP BY 1, 2`,
+		},
+	}
+
+	testFunc := func(i *IoT, c IoTTestCase) query.Query {
+		q := i.GenerateEmptyQuery()
+		i.LastLocByTruck(q, c.input)
+		return q
+	}
+
+	start := time.Unix(0, 0)
+	end := start.Add(time.Hour)
+
+	runIoTTestCases(t, testFunc, start, end, cases)
+}
+
+func TestLastLocPerTruck(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "CrateDB last location per truck",
+			expectedHumanDesc:  "CrateDB last location per truck",
+			expectedTable:      "readings",
+			expectedQuery: `
+		SELECT tags['name'] AS name, tags['driver'] AS driver,
+			max_by(longitude, ts) AS longitude,
+			max_by(latitude, ts) AS latitude
+		FROM readings
+		WHERE tags['fleet'] = 'South'
+		  AND tags['name'] IS NOT NULL
+		GROUP BY 1, 2`,
+		},
+	}
+
+	testFunc := func(i *IoT, c IoTTestCase) query.Query {
+		q := i.GenerateEmptyQuery()
+		i.LastLocPerTruck(q)
+		return q
+	}
+
+	start := time.Unix(0, 0)
+	end := start.Add(time.Hour)
+
+	runIoTTestCases(t, testFunc, start, end, cases)
+}
+
+func TestTrucksWithLowFuel(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "CrateDB trucks with low fuel",
+			expectedHumanDesc:  "CrateDB trucks with low fuel: under 10 percent",
+			expectedTable:      "diagnostics",
+			expectedQuery: `
+		SELECT tags['name'] AS name, tags['driver'] AS driver,
+			max_by(fuel_state, ts) AS fuel_state
+		FROM diagnostics
+		WHERE tags['fleet'] = 'South'
+		  AND tags['name'] IS NOT NULL
+		GROUP BY 1, 2
+		HAVING max_by(fuel_state, ts) < 0.1`,
+		},
+	}
+
+	testFunc := func(i *IoT, c IoTTestCase) query.Query {
+		q := i.GenerateEmptyQuery()
+		i.TrucksWithLowFuel(q)
+		return q
+	}
+
+	start := time.Unix(0, 0)
+	end := start.Add(time.Hour)
+
+	runIoTTestCases(t, testFunc, start, end, cases)
+}
+
+func TestTrucksWithHighLoad(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "CrateDB trucks with high load",
+			expectedHumanDesc:  "CrateDB trucks with high load: over 90 percent",
+			expectedTable:      "diagnostics",
+			expectedQuery: `
+		SELECT tags['name'] AS name, tags['driver'] AS driver,
+			tags['load_capacity'] AS load_capacity,
+			max_by(current_load, ts) AS current_load
+		FROM diagnostics
+		WHERE tags['fleet'] = 'South'
+		  AND tags['name'] IS NOT NULL
+		GROUP BY 1, 2, 3
+		HAVING max_by(current_load, ts) / tags['load_capacity'] > 0.9`,
+		},
+	}
+
+	testFunc := func(i *IoT, c IoTTestCase) query.Query {
+		q := i.GenerateEmptyQuery()
+		i.TrucksWithHighLoad(q)
+		return q
+	}
+
+	start := time.Unix(0, 0)
+	end := start.Add(time.Hour)
+
+	runIoTTestCases(t, testFunc, start, end, cases)
+}
+
+func TestStationaryTrucks(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "CrateDB stationary trucks",
+			expectedHumanDesc:  "CrateDB stationary trucks: with low avg velocity in last 10 minutes",
+			expectedTable:      "readings",
+			expectedQuery: `
+		SELECT tags['name'] AS name, tags['driver'] AS driver
+		FROM readings
+		WHERE ts >= 2182646
+		  AND ts < 2782646
+		  AND tags['fleet'] = 'West'
+		  AND tags['name'] IS NOT NULL
+		GROUP BY 1, 2
+		HAVING avg(velocity) < 1`,
+		},
+	}
+
+	testFunc := func(i *IoT, c IoTTestCase) query.Query {
+		q := i.GenerateEmptyQuery()
+		i.StationaryTrucks(q)
+		return q
+	}
+
+	start := time.Unix(0, 0)
+	end := start.Add(time.Hour)
+
+	runIoTTestCases(t, testFunc, start, end, cases)
+}
+
+func TestTrucksWithLongDrivingSessions(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "CrateDB trucks with longer driving sessions",
+			expectedHumanDesc:  "CrateDB trucks with longer driving sessions: stopped less than 20 mins in 4 hour period",
+			expectedTable:      "readings",
+			expectedQuery: `
+		SELECT name, driver
+		FROM (
+			SELECT tags['name'] AS name, tags['driver'] AS driver,
+				date_bin('10 minutes'::INTERVAL, ts, 0) AS ten_minutes
+			FROM readings
+			WHERE ts >= 982646
+			  AND ts < 15382646
+			  AND tags['fleet'] = 'West'
+			  AND tags['name'] IS NOT NULL
+			GROUP BY 1, 2, 3
+			HAVING avg(velocity) > 1
+		) AS r
+		GROUP BY 1, 2
+		HAVING count(*) > 22`,
+		},
+	}
+
+	testFunc := func(i *IoT, c IoTTestCase) query.Query {
+		q := i.GenerateEmptyQuery()
+		i.TrucksWithLongDrivingSessions(q)
+		return q
+	}
+
+	start := time.Unix(0, 0)
+	end := start.Add(6 * time.Hour)
+
+	runIoTTestCases(t, testFunc, start, end, cases)
+}
+
+func TestTrucksWithLongDailySessions(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "CrateDB trucks with longer daily sessions",
+			expectedHumanDesc:  "CrateDB trucks with longer daily sessions: drove more than 10 hours in the last 24 hours",
+			expectedTable:      "readings",
+			expectedQuery: `
+		SELECT name, driver
+		FROM (
+			SELECT tags['name'] AS name, tags['driver'] AS driver,
+				date_bin('10 minutes'::INTERVAL, ts, 0) AS ten_minutes
+			FROM readings
+			WHERE ts >= 982646
+			  AND ts < 87382646
+			  AND tags['fleet'] = 'West'
+			  AND tags['name'] IS NOT NULL
+			GROUP BY 1, 2, 3
+			HAVING avg(velocity) > 1
+		) AS r
+		GROUP BY 1, 2
+		HAVING count(*) > 60`,
+		},
+	}
+
+	testFunc := func(i *IoT, c IoTTestCase) query.Query {
+		q := i.GenerateEmptyQuery()
+		i.TrucksWithLongDailySessions(q)
+		return q
+	}
+
+	start := time.Unix(0, 0)
+	end := start.Add(25 * time.Hour)
+
+	runIoTTestCases(t, testFunc, start, end, cases)
+}
+
+func TestAvgVsProjectedFuelConsumption(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "CrateDB average vs projected fuel consumption per fleet",
+			expectedHumanDesc:  "CrateDB average vs projected fuel consumption per fleet",
+			expectedTable:      "readings",
+			expectedQuery: `
+		SELECT tags['fleet'] AS fleet,
+			avg(fuel_consumption) AS avg_fuel_consumption,
+			avg(tags['nominal_fuel_consumption']) AS projected_fuel_consumption
+		FROM readings
+		WHERE velocity > 1
+		  AND tags['fleet'] IS NOT NULL
+		  AND tags['nominal_fuel_consumption'] IS NOT NULL
+		  AND tags['name'] IS NOT NULL
+		GROUP BY 1`,
+		},
+	}
+
+	testFunc := func(i *IoT, c IoTTestCase) query.Query {
+		q := i.GenerateEmptyQuery()
+		i.AvgVsProjectedFuelConsumption(q)
+		return q
+	}
+
+	start := time.Unix(0, 0)
+	end := start.Add(25 * time.Hour)
+
+	runIoTTestCases(t, testFunc, start, end, cases)
+}
+
+func TestAvgDailyDrivingDuration(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "CrateDB average driver driving duration per day",
+			expectedHumanDesc:  "CrateDB average driver driving duration per day",
+			expectedTable:      "readings",
+			expectedQuery: `
+		SELECT fleet, name, driver, avg(hours) AS avg_daily_hours
+		FROM (
+			SELECT fleet, name, driver, date_trunc('day', ten_minutes) AS day, count(*) / 6 AS hours
+			FROM (
+				SELECT tags['fleet'] AS fleet, tags['name'] AS name, tags['driver'] AS driver,
+					date_bin('10 minutes'::INTERVAL, ts, 0) AS ten_minutes
+				FROM readings
+				WHERE tags['name'] IS NOT NULL
+				GROUP BY 1, 2, 3, 4
+				HAVING avg(velocity) > 1
+			) AS s
+			GROUP BY 1, 2, 3, 4
+		) AS d
+		GROUP BY 1, 2, 3`,
+		},
+	}
+
+	testFunc := func(i *IoT, c IoTTestCase) query.Query {
+		q := i.GenerateEmptyQuery()
+		i.AvgDailyDrivingDuration(q)
+		return q
+	}
+
+	start := time.Unix(0, 0)
+	end := start.Add(25 * time.Hour)
+
+	runIoTTestCases(t, testFunc, start, end, cases)
+}
+
+func TestAvgDailyDrivingSession(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "CrateDB average driver driving session without stopping per day",
+			expectedHumanDesc:  "CrateDB average driver driving session without stopping per day",
+			expectedTable:      "readings",
+			expectedQuery: `
+		SELECT name, date_trunc('day', start) AS day,
+			avg(stop::BIGINT - start::BIGINT) AS duration_ms
+		FROM (
+			SELECT name, ten_minutes AS start, driving,
+				lead(ten_minutes) OVER (PARTITION BY name ORDER BY ten_minutes) AS stop
+			FROM (
+				SELECT name, ten_minutes, driving,
+					lag(driving) OVER (PARTITION BY name ORDER BY ten_minutes) AS prev_driving
+				FROM (
+					SELECT tags['name'] AS name,
+						date_bin('10 minutes'::INTERVAL, ts, 0) AS ten_minutes,
+						avg(velocity) > 5 AS driving
+					FROM readings
+					WHERE tags['name'] IS NOT NULL
+					GROUP BY 1, 2
+				) AS s
+			) AS c
+			WHERE driving <> prev_driving
+		) AS d
+		WHERE driving = true
+		GROUP BY 1, 2
+		ORDER BY 1, 2`,
+		},
+	}
+
+	testFunc := func(i *IoT, c IoTTestCase) query.Query {
+		q := i.GenerateEmptyQuery()
+		i.AvgDailyDrivingSession(q)
+		return q
+	}
+
+	start := time.Unix(0, 0)
+	end := start.Add(25 * time.Hour)
+
+	runIoTTestCases(t, testFunc, start, end, cases)
+}
+
+func TestAvgLoad(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "CrateDB average load per truck model per fleet",
+			expectedHumanDesc:  "CrateDB average load per truck model per fleet",
+			expectedTable:      "diagnostics",
+			expectedQuery: `
+		SELECT fleet, model, load_capacity, avg(avg_load / load_capacity) AS avg_load_percentage
+		FROM (
+			SELECT tags['name'] AS name, tags['fleet'] AS fleet, tags['model'] AS model,
+				tags['load_capacity'] AS load_capacity,
+				avg(current_load) AS avg_load
+			FROM diagnostics
+			WHERE tags['name'] IS NOT NULL
+			GROUP BY 1, 2, 3, 4
+		) AS d
+		GROUP BY 1, 2, 3`,
+		},
+	}
+
+	testFunc := func(i *IoT, c IoTTestCase) query.Query {
+		q := i.GenerateEmptyQuery()
+		i.AvgLoad(q)
+		return q
+	}
+
+	start := time.Unix(0, 0)
+	end := start.Add(25 * time.Hour)
+
+	runIoTTestCases(t, testFunc, start, end, cases)
+}
+
+func TestDailyTruckActivity(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "CrateDB daily truck activity per fleet per model",
+			expectedHumanDesc:  "CrateDB daily truck activity per fleet per model",
+			expectedTable:      "diagnostics",
+			expectedQuery: `
+		SELECT fleet, model, day, count(*) / 144.0 AS daily_activity
+		FROM (
+			SELECT tags['name'] AS name, tags['fleet'] AS fleet, tags['model'] AS model,
+				date_trunc('day', ts) AS day,
+				date_bin('10 minutes'::INTERVAL, ts, 0) AS ten_minutes
+			FROM diagnostics
+			WHERE tags['name'] IS NOT NULL
+			GROUP BY 1, 2, 3, 4, 5
+			HAVING avg(status) < 1
+		) AS a
+		GROUP BY 1, 2, 3
+		ORDER BY 3`,
+		},
+	}
+
+	testFunc := func(i *IoT, c IoTTestCase) query.Query {
+		q := i.GenerateEmptyQuery()
+		i.DailyTruckActivity(q)
+		return q
+	}
+
+	start := time.Unix(0, 0)
+	end := start.Add(25 * time.Hour)
+
+	runIoTTestCases(t, testFunc, start, end, cases)
+}
+
+func TestTruckBreakdownFrequency(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "CrateDB truck breakdown frequency per model",
+			expectedHumanDesc:  "CrateDB truck breakdown frequency per model",
+			expectedTable:      "diagnostics",
+			expectedQuery: `
+		SELECT model, count(*) AS breakdowns
+		FROM (
+			SELECT model, broken_down,
+				lead(broken_down) OVER (PARTITION BY name ORDER BY ten_minutes) AS next_broken_down
+			FROM (
+				SELECT tags['name'] AS name, tags['model'] AS model,
+					date_bin('10 minutes'::INTERVAL, ts, 0) AS ten_minutes,
+					sum(CASE WHEN status = 0 THEN 1 ELSE 0 END) / count(*)::DOUBLE >= 0.5 AS broken_down
+				FROM diagnostics
+				WHERE tags['name'] IS NOT NULL
+				GROUP BY 1, 2, 3
+			) AS b
+		) AS c
+		WHERE broken_down = false
+		  AND next_broken_down = true
+		GROUP BY 1`,
+		},
+	}
+
+	testFunc := func(i *IoT, c IoTTestCase) query.Query {
+		q := i.GenerateEmptyQuery()
+		i.TruckBreakdownFrequency(q)
+		return q
+	}
+
+	start := time.Unix(0, 0)
+	end := start.Add(25 * time.Hour)
+
+	runIoTTestCases(t, testFunc, start, end, cases)
+}
+
+func TestTenMinutePeriods(t *testing.T) {
+	cases := []struct {
+		minutesPerHour float64
+		duration       time.Duration
+		result         int
+	}{
+		{
+			minutesPerHour: 5.0,
+			duration:       4 * time.Hour,
+			result:         22,
+		},
+		{
+			minutesPerHour: 10.0,
+			duration:       24 * time.Hour,
+			result:         120,
+		},
+		{
+			minutesPerHour: 0.0,
+			duration:       24 * time.Hour,
+			result:         144,
+		},
+		{
+			minutesPerHour: 1.0,
+			duration:       0 * time.Minute,
+			result:         0,
+		},
+		{
+			minutesPerHour: 0.0,
+			duration:       0 * time.Minute,
+			result:         0,
+		},
+		{
+			minutesPerHour: 1.0,
+			duration:       30 * time.Minute,
+			result:         2,
+		},
+	}
+
+	for _, c := range cases {
+		if got := tenMinutePeriods(c.minutesPerHour, c.duration); got != c.result {
+			t.Errorf("incorrect result for %.2f minutes per hour, duration %s: got %d want %d", c.minutesPerHour, c.duration.String(), got, c.result)
+		}
+	}
+
+}
+
+func runIoTTestCases(t *testing.T, testFunc func(*IoT, IoTTestCase) query.Query, s time.Time, e time.Time, cases []IoTTestCase) {
+	rand.Seed(123) // Setting seed for testing purposes.
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			b := BaseGenerator{}
+			dq, err := b.NewIoT(s, e, testScale)
+			if err != nil {
+				t.Fatalf("Error while creating iot generator")
+			}
+			i := dq.(*IoT)
+
+			if c.fail {
+				func() {
+					defer func() {
+						r := recover()
+						if r == nil {
+							t.Fatalf("did not panic when should")
+						}
+
+						if r != c.failMsg {
+							t.Fatalf("incorrect fail message: got %s, want %s", r, c.failMsg)
+						}
+					}()
+
+					testFunc(i, c)
+				}()
+			} else {
+				q := testFunc(i, c)
+
+				verifyIoTQuery(t, q, c)
+			}
+		})
+	}
+}
+
+func verifyIoTQuery(t *testing.T, qi query.Query, c IoTTestCase) {
+	q, ok := qi.(*query.CrateDB)
+	if !ok {
+		t.Fatal("Filled query is not *query.CrateDB type")
+	}
+
+	if got := string(q.HumanLabel); got != c.expectedHumanLabel {
+		t.Errorf("incorrect human label:\ngot\n%s\nwant\n%s", got, c.expectedHumanLabel)
+	}
+
+	if got := string(q.HumanDescription); got != c.expectedHumanDesc {
+		t.Errorf("incorrect human description:\ngot\n%s\nwant\n%s", got, c.expectedHumanDesc)
+	}
+
+	if got := string(q.Table); got != c.expectedTable {
+		t.Errorf("incorrect table:\ngot\n%s\nwant\n%s", got, c.expectedTable)
+	}
+
+	if got := string(q.SqlQuery); got != c.expectedQuery {
+		t.Errorf("incorrect sql query:\ngot\n%s\nwant\n%s", got, c.expectedQuery)
+	}
+}
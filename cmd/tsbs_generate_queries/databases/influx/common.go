@@ -6,7 +6,10 @@ import (
 	"time"
 
 	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/devops"
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/events"
 	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/iot"
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/k8s"
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/ticks"
 	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/utils"
 	"github.com/bodhiye/tsbs/pkg/query"
 )
@@ -64,3 +67,51 @@ func (g *BaseGenerator) NewIoT(start, end time.Time, scale int) (utils.QueryGene
 
 	return devops, nil
 }
+
+// NewK8s creates a new k8s use case query generator.
+func (g *BaseGenerator) NewK8s(start, end time.Time, scale int) (utils.QueryGenerator, error) {
+	core, err := k8s.NewCore(start, end, scale)
+
+	if err != nil {
+		return nil, err
+	}
+
+	k8s := &K8s{
+		BaseGenerator: g,
+		Core:          core,
+	}
+
+	return k8s, nil
+}
+
+// NewTicks creates a new ticks use case query generator.
+func (g *BaseGenerator) NewTicks(start, end time.Time, scale int) (utils.QueryGenerator, error) {
+	core, err := ticks.NewCore(start, end, scale)
+
+	if err != nil {
+		return nil, err
+	}
+
+	ticks := &Ticks{
+		BaseGenerator: g,
+		Core:          core,
+	}
+
+	return ticks, nil
+}
+
+// NewEvents creates a new events use case query generator.
+func (g *BaseGenerator) NewEvents(start, end time.Time, scale int) (utils.QueryGenerator, error) {
+	core, err := events.NewCore(start, end, scale)
+
+	if err != nil {
+		return nil, err
+	}
+
+	events := &Events{
+		BaseGenerator: g,
+		Core:          core,
+	}
+
+	return events, nil
+}
@@ -74,6 +74,7 @@ func TestDevopsGetHostWhereString(t *testing.T) {
 		},
 	}
 
+	rand.Seed(1) // Setting seed for testing purposes.
 	for _, c := range cases {
 		t.Run(c.desc, func(t *testing.T) {
 			b := BaseGenerator{}
@@ -0,0 +1,76 @@
+package influx
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/events"
+	"github.com/bodhiye/tsbs/pkg/query"
+)
+
+// Events produces Influx-specific queries for all the events query types.
+type Events struct {
+	*events.Core
+	*BaseGenerator
+}
+
+// ErrorsByService counts the error events of each service over a random
+// window, e.g. in pseudo-SQL:
+//
+// SELECT service, count(*)
+// FROM events
+// WHERE level = 'error' AND time >= '$START' AND time < '$END'
+// GROUP BY service
+func (e *Events) ErrorsByService(qi query.Query) {
+	interval := e.Interval.MustRandWindow(events.ErrorsByServiceDuration)
+	influxql := fmt.Sprintf(`SELECT count("status_code") AS "errors"
+		FROM "events"
+		WHERE "level" = 'error' AND time >= '%s' AND time < '%s'
+		GROUP BY "service"`,
+		interval.StartString(), interval.EndString())
+
+	humanLabel := events.GetErrorsByServiceLabel("Influx")
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
+	e.fillInQuery(qi, humanLabel, humanDesc, influxql)
+}
+
+// TraceLookup finds the events of a random trace by its ID within a random
+// window, e.g. in pseudo-SQL:
+//
+// SELECT time, service, instance, level, status_code, latency_ms, message
+// FROM events
+// WHERE trace_id = '$TRACE_ID' AND time >= '$START' AND time < '$END'
+// ORDER BY time
+func (e *Events) TraceLookup(qi query.Query) {
+	traceID, interval := e.GetRandomTrace()
+	influxql := fmt.Sprintf(`SELECT "service", "instance", "level", "status_code", "latency_ms", "message"
+		FROM "events"
+		WHERE "trace_id" = '%s' AND time >= '%s' AND time < '%s'`,
+		traceID, interval.StartString(), interval.EndString())
+
+	humanLabel := events.GetTraceLookupLabel("Influx")
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, traceID)
+	e.fillInQuery(qi, humanLabel, humanDesc, influxql)
+}
+
+// MessageSearch finds the last events whose message contains a random
+// phrase within a random window, e.g. in pseudo-SQL:
+//
+// SELECT time, service, level, message
+// FROM events
+// WHERE message LIKE '%$TERM%' AND time >= '$START' AND time < '$END'
+// ORDER BY time DESC LIMIT $LIMIT
+func (e *Events) MessageSearch(qi query.Query) {
+	interval := e.Interval.MustRandWindow(events.MessageSearchDuration)
+	term := e.GetRandomSearchTerm()
+	influxql := fmt.Sprintf(`SELECT "service", "level", "message"
+		FROM "events"
+		WHERE "message" =~ /%s/ AND time >= '%s' AND time < '%s'
+		ORDER BY time DESC
+		LIMIT %d`,
+		regexp.QuoteMeta(term), interval.StartString(), interval.EndString(), events.MessageSearchLimit)
+
+	humanLabel := events.GetMessageSearchLabel("Influx")
+	humanDesc := fmt.Sprintf("%s: '%s' %s", humanLabel, term, interval.StartString())
+	e.fillInQuery(qi, humanLabel, humanDesc, influxql)
+}
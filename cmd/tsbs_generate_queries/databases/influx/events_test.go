@@ -0,0 +1,104 @@
+package influx
+
+import (
+	"fmt"
+	"math/rand"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/bodhiye/tsbs/pkg/query"
+)
+
+func TestEventsErrorsByService(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "Influx error counts by service, random 1h0m0s",
+			expectedHumanDesc:  "Influx error counts by service, random 1h0m0s: 1970-01-01T20:16:22Z",
+			expectedQuery: `SELECT count("status_code") AS "errors"
+		FROM "events"
+		WHERE "level" = 'error' AND time >= '1970-01-01T20:16:22Z' AND time < '1970-01-01T21:16:22Z'
+		GROUP BY "service"`,
+		},
+	}
+
+	testFunc := func(e *Events, c IoTTestCase) query.Query {
+		q := e.GenerateEmptyQuery()
+		e.ErrorsByService(q)
+		return q
+	}
+
+	runEventsTestCases(t, testFunc, cases)
+}
+
+func TestEventsTraceLookup(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "Influx trace lookup by ID, random 1h0m0s",
+			expectedHumanDesc:  "Influx trace lookup by ID, random 1h0m0s: f71c4506bd41afc594bff8439bf91eac",
+			expectedQuery: `SELECT "service", "instance", "level", "status_code", "latency_ms", "message"
+		FROM "events"
+		WHERE "trace_id" = 'f71c4506bd41afc594bff8439bf91eac' AND time >= '1970-01-01T20:16:22Z' AND time < '1970-01-01T21:16:22Z'`,
+		},
+	}
+
+	testFunc := func(e *Events, c IoTTestCase) query.Query {
+		q := e.GenerateEmptyQuery()
+		e.TraceLookup(q)
+		return q
+	}
+
+	runEventsTestCases(t, testFunc, cases)
+}
+
+func TestEventsMessageSearch(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "Influx message substring search, random 1h0m0s",
+			expectedHumanDesc:  "Influx message substring search, random 1h0m0s: 'slow request' 1970-01-01T20:16:22Z",
+			expectedQuery: `SELECT "service", "level", "message"
+		FROM "events"
+		WHERE "message" =~ /slow request/ AND time >= '1970-01-01T20:16:22Z' AND time < '1970-01-01T21:16:22Z'
+		ORDER BY time DESC
+		LIMIT 100`,
+		},
+	}
+
+	testFunc := func(e *Events, c IoTTestCase) query.Query {
+		q := e.GenerateEmptyQuery()
+		e.MessageSearch(q)
+		return q
+	}
+
+	runEventsTestCases(t, testFunc, cases)
+}
+
+func runEventsTestCases(t *testing.T, testFunc func(*Events, IoTTestCase) query.Query, cases []IoTTestCase) {
+	start := time.Unix(0, 0).UTC()
+	end := start.Add(24 * time.Hour)
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			rand.Seed(123) // Setting seed for testing purposes.
+			b := BaseGenerator{}
+			eq, err := b.NewEvents(start, end, testScale)
+			if err != nil {
+				t.Fatalf("Error while creating events generator")
+			}
+
+			q := testFunc(eq.(*Events), c)
+
+			v := url.Values{}
+			v.Set("q", c.expectedQuery)
+			expectedPath := fmt.Sprintf("/query?%s", v.Encode())
+
+			verifyQuery(t, q, c.expectedHumanLabel, c.expectedHumanDesc, expectedPath)
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package influx
+
+import (
+	"fmt"
+
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/k8s"
+	"github.com/bodhiye/tsbs/pkg/query"
+)
+
+// K8s produces Influx-specific queries for all the k8s query types.
+type K8s struct {
+	*k8s.Core
+	*BaseGenerator
+}
+
+// NamespaceCPU sums the cpu usage of the containers of every namespace per
+// minute over a random window, e.g. in pseudo-SQL:
+//
+// SELECT minute, namespace, sum(usage_millicores)
+// FROM (SELECT minute, namespace, avg(usage_millicores) FROM container_cpu
+// WHERE time >= '$START' AND time < '$END'
+// GROUP BY minute, namespace, pod, container)
+// GROUP BY minute, namespace
+func (k *K8s) NamespaceCPU(qi query.Query) {
+	interval := k.Interval.MustRandWindow(k8s.NamespaceCPUDuration)
+	influxql := fmt.Sprintf(`SELECT sum("usage_millicores") AS "usage_millicores"
+		FROM (SELECT mean("usage_millicores") AS "usage_millicores" FROM "container_cpu"
+			WHERE time >= '%s' AND time < '%s'
+			GROUP BY time(1m), "namespace", "pod", "container")
+		WHERE time >= '%s' AND time < '%s'
+		GROUP BY time(1m), "namespace"`,
+		interval.StartString(), interval.EndString(),
+		interval.StartString(), interval.EndString())
+
+	humanLabel := k8s.GetNamespaceCPULabel("Influx")
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
+	k.fillInQuery(qi, humanLabel, humanDesc, influxql)
+}
+
+// TopPodsCPU finds the limit pods of a random namespace using the most cpu,
+// summed over their containers, in a random window, e.g. in pseudo-SQL:
+//
+// SELECT pod, sum(usage_millicores) AS usage
+// FROM (SELECT pod, avg(usage_millicores) FROM container_cpu
+// WHERE namespace = '$NAMESPACE' AND time >= '$START' AND time < '$END'
+// GROUP BY pod, container)
+// GROUP BY pod ORDER BY usage DESC LIMIT $LIMIT
+func (k *K8s) TopPodsCPU(qi query.Query, limit int) {
+	interval := k.Interval.MustRandWindow(k8s.TopPodsCPUDuration)
+	namespace := k.GetRandomNamespace()
+	influxql := fmt.Sprintf(`SELECT top("usage_millicores", "pod", %d)
+		FROM (SELECT sum("usage_millicores") AS "usage_millicores"
+			FROM (SELECT mean("usage_millicores") AS "usage_millicores" FROM "container_cpu"
+				WHERE "namespace" = '%s' AND time >= '%s' AND time < '%s'
+				GROUP BY "pod", "container")
+			GROUP BY "pod")`,
+		limit, namespace, interval.StartString(), interval.EndString())
+
+	humanLabel := k8s.GetTopPodsCPULabel("Influx", limit)
+	humanDesc := fmt.Sprintf("%s: %s (%s)", humanLabel, interval.StartString(), namespace)
+	k.fillInQuery(qi, humanLabel, humanDesc, influxql)
+}
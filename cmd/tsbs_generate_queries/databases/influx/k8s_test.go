@@ -0,0 +1,87 @@
+package influx
+
+import (
+	"fmt"
+	"math/rand"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/k8s"
+	"github.com/bodhiye/tsbs/pkg/query"
+)
+
+func TestK8sNamespaceCPU(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "Influx cpu per namespace, random 1h0m0s by 1m",
+			expectedHumanDesc:  "Influx cpu per namespace, random 1h0m0s by 1m: 1970-01-01T20:16:22Z",
+			expectedQuery: `SELECT sum("usage_millicores") AS "usage_millicores"
+		FROM (SELECT mean("usage_millicores") AS "usage_millicores" FROM "container_cpu"
+			WHERE time >= '1970-01-01T20:16:22Z' AND time < '1970-01-01T21:16:22Z'
+			GROUP BY time(1m), "namespace", "pod", "container")
+		WHERE time >= '1970-01-01T20:16:22Z' AND time < '1970-01-01T21:16:22Z'
+		GROUP BY time(1m), "namespace"`,
+		},
+	}
+
+	testFunc := func(k *K8s, c IoTTestCase) query.Query {
+		q := k.GenerateEmptyQuery()
+		k.NamespaceCPU(q)
+		return q
+	}
+
+	runK8sTestCases(t, testFunc, cases)
+}
+
+func TestK8sTopPodsCPU(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc:  "default",
+			input: k8s.TopPodsCPULimit,
+
+			expectedHumanLabel: "Influx top 10 pods by cpu, random namespace, random 1h0m0s",
+			expectedHumanDesc:  "Influx top 10 pods by cpu, random namespace, random 1h0m0s: 1970-01-01T20:16:22Z (kube-system)",
+			expectedQuery: `SELECT top("usage_millicores", "pod", 10)
+		FROM (SELECT sum("usage_millicores") AS "usage_millicores"
+			FROM (SELECT mean("usage_millicores") AS "usage_millicores" FROM "container_cpu"
+				WHERE "namespace" = 'kube-system' AND time >= '1970-01-01T20:16:22Z' AND time < '1970-01-01T21:16:22Z'
+				GROUP BY "pod", "container")
+			GROUP BY "pod")`,
+		},
+	}
+
+	testFunc := func(k *K8s, c IoTTestCase) query.Query {
+		q := k.GenerateEmptyQuery()
+		k.TopPodsCPU(q, c.input)
+		return q
+	}
+
+	runK8sTestCases(t, testFunc, cases)
+}
+
+func runK8sTestCases(t *testing.T, testFunc func(*K8s, IoTTestCase) query.Query, cases []IoTTestCase) {
+	start := time.Unix(0, 0).UTC()
+	end := start.Add(24 * time.Hour)
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			rand.Seed(123) // Setting seed for testing purposes.
+			b := BaseGenerator{}
+			kq, err := b.NewK8s(start, end, testScale)
+			if err != nil {
+				t.Fatalf("Error while creating k8s generator")
+			}
+
+			q := testFunc(kq.(*K8s), c)
+
+			v := url.Values{}
+			v.Set("q", c.expectedQuery)
+			expectedPath := fmt.Sprintf("/query?%s", v.Encode())
+
+			verifyQuery(t, q, c.expectedHumanLabel, c.expectedHumanDesc, expectedPath)
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package influx
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/databases"
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/ticks"
+	"github.com/bodhiye/tsbs/pkg/query"
+)
+
+// Ticks produces Influx-specific queries for all the ticks query types.
+type Ticks struct {
+	*ticks.Core
+	*BaseGenerator
+}
+
+func (t *Ticks) getSymbolWhereString(nSymbols int) string {
+	symbols, err := t.GetRandomSymbols(nSymbols)
+	databases.PanicIfErr(err)
+
+	symbolClauses := make([]string, len(symbols))
+	for i, s := range symbols {
+		symbolClauses[i] = fmt.Sprintf(`"symbol" = '%s'`, s)
+	}
+	return "(" + strings.Join(symbolClauses, " OR ") + ")"
+}
+
+// OHLCV computes the open, high, low and close prices and the volume of
+// the trades of a random symbol per minute over a random window, e.g. in
+// pseudo-SQL:
+//
+// SELECT minute, first(price), max(price), min(price), last(price), sum(size)
+// FROM trades
+// WHERE symbol = '$SYMBOL' AND time >= '$START' AND time < '$END'
+// GROUP BY minute
+func (t *Ticks) OHLCV(qi query.Query) {
+	interval := t.Interval.MustRandWindow(ticks.OHLCVDuration)
+	influxql := fmt.Sprintf(`SELECT first("price") AS "open", max("price") AS "high", min("price") AS "low", last("price") AS "close", sum("size") AS "volume"
+		FROM "trades"
+		WHERE %s AND time >= '%s' AND time < '%s'
+		GROUP BY time(1m)`,
+		t.getSymbolWhereString(1), interval.StartString(), interval.EndString())
+
+	humanLabel := ticks.GetOHLCVLabel("Influx")
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
+	t.fillInQuery(qi, humanLabel, humanDesc, influxql)
+}
+
+// VWAP computes the volume-weighted average price of nSymbols random
+// symbols over a random window, e.g. in pseudo-SQL:
+//
+// SELECT symbol, sum(price * size) / sum(size), sum(size)
+// FROM trades
+// WHERE symbol IN ('$SYMBOL_1',...,'$SYMBOL_N') AND time >= '$START' AND time < '$END'
+// GROUP BY symbol
+func (t *Ticks) VWAP(qi query.Query, nSymbols int) {
+	interval := t.Interval.MustRandWindow(ticks.VWAPDuration)
+	influxql := fmt.Sprintf(`SELECT sum("notional") / sum("size") AS "vwap", sum("size") AS "volume"
+		FROM (SELECT "price" * "size" AS "notional", "size" FROM "trades"
+			WHERE %s AND time >= '%s' AND time < '%s'
+			GROUP BY "symbol")
+		GROUP BY "symbol"`,
+		t.getSymbolWhereString(nSymbols), interval.StartString(), interval.EndString())
+
+	humanLabel := ticks.GetVWAPLabel("Influx", nSymbols)
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
+	t.fillInQuery(qi, humanLabel, humanDesc, influxql)
+}
+
+// LastQuote finds the last quote of every symbol as of a random time,
+// looking back LastQuoteLookback, e.g. in pseudo-SQL:
+//
+// SELECT DISTINCT ON (symbol) symbol, time, bid_price, bid_size, ask_price, ask_size
+// FROM quotes
+// WHERE time > '$TIME' - $LOOKBACK AND time <= '$TIME'
+// ORDER BY symbol, time DESC
+func (t *Ticks) LastQuote(qi query.Query) {
+	interval := t.Interval.MustRandWindow(ticks.LastQuoteLookback)
+	influxql := fmt.Sprintf(`SELECT "bid_price", "bid_size", "ask_price", "ask_size"
+		FROM "quotes"
+		WHERE time > '%s' AND time <= '%s'
+		GROUP BY "symbol"
+		ORDER BY "time" DESC
+		LIMIT 1`,
+		interval.StartString(), interval.EndString())
+
+	humanLabel := ticks.GetLastQuoteLabel("Influx")
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.EndString())
+	t.fillInQuery(qi, humanLabel, humanDesc, influxql)
+}
@@ -0,0 +1,108 @@
+package influx
+
+import (
+	"fmt"
+	"math/rand"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/bodhiye/tsbs/pkg/query"
+)
+
+func TestTicksOHLCV(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "Influx OHLCV bars, random symbol, random 1h0m0s by 1m",
+			expectedHumanDesc:  "Influx OHLCV bars, random symbol, random 1h0m0s by 1m: 1970-01-01T20:16:22Z",
+			expectedQuery: `SELECT first("price") AS "open", max("price") AS "high", min("price") AS "low", last("price") AS "close", sum("size") AS "volume"
+		FROM "trades"
+		WHERE ("symbol" = 'AAAJ') AND time >= '1970-01-01T20:16:22Z' AND time < '1970-01-01T21:16:22Z'
+		GROUP BY time(1m)`,
+		},
+	}
+
+	testFunc := func(tk *Ticks, c IoTTestCase) query.Query {
+		q := tk.GenerateEmptyQuery()
+		tk.OHLCV(q)
+		return q
+	}
+
+	runTicksTestCases(t, testFunc, cases)
+}
+
+func TestTicksVWAP(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc:  "two symbols",
+			input: 2,
+
+			expectedHumanLabel: "Influx VWAP, random    2 symbols, random 1h0m0s",
+			expectedHumanDesc:  "Influx VWAP, random    2 symbols, random 1h0m0s: 1970-01-01T20:16:22Z",
+			expectedQuery: `SELECT sum("notional") / sum("size") AS "vwap", sum("size") AS "volume"
+		FROM (SELECT "price" * "size" AS "notional", "size" FROM "trades"
+			WHERE ("symbol" = 'AAAJ' OR "symbol" = 'AAAD') AND time >= '1970-01-01T20:16:22Z' AND time < '1970-01-01T21:16:22Z'
+			GROUP BY "symbol")
+		GROUP BY "symbol"`,
+		},
+	}
+
+	testFunc := func(tk *Ticks, c IoTTestCase) query.Query {
+		q := tk.GenerateEmptyQuery()
+		tk.VWAP(q, c.input)
+		return q
+	}
+
+	runTicksTestCases(t, testFunc, cases)
+}
+
+func TestTicksLastQuote(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "Influx last quote per symbol, random time",
+			expectedHumanDesc:  "Influx last quote per symbol, random time: 1970-01-01T21:16:22Z",
+			expectedQuery: `SELECT "bid_price", "bid_size", "ask_price", "ask_size"
+		FROM "quotes"
+		WHERE time > '1970-01-01T21:15:22Z' AND time <= '1970-01-01T21:16:22Z'
+		GROUP BY "symbol"
+		ORDER BY "time" DESC
+		LIMIT 1`,
+		},
+	}
+
+	testFunc := func(tk *Ticks, c IoTTestCase) query.Query {
+		q := tk.GenerateEmptyQuery()
+		tk.LastQuote(q)
+		return q
+	}
+
+	runTicksTestCases(t, testFunc, cases)
+}
+
+func runTicksTestCases(t *testing.T, testFunc func(*Ticks, IoTTestCase) query.Query, cases []IoTTestCase) {
+	start := time.Unix(0, 0).UTC()
+	end := start.Add(24 * time.Hour)
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			rand.Seed(123) // Setting seed for testing purposes.
+			b := BaseGenerator{}
+			tq, err := b.NewTicks(start, end, testScale)
+			if err != nil {
+				t.Fatalf("Error while creating ticks generator")
+			}
+
+			q := testFunc(tq.(*Ticks), c)
+
+			v := url.Values{}
+			v.Set("q", c.expectedQuery)
+			expectedPath := fmt.Sprintf("/query?%s", v.Encode())
+
+			verifyQuery(t, q, c.expectedHumanLabel, c.expectedHumanDesc, expectedPath)
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/devops"
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/iot"
 	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/utils"
 	"github.com/bodhiye/tsbs/pkg/query"
 )
@@ -41,3 +42,20 @@ func (g *BaseGenerator) NewDevops(start, end time.Time, scale int) (utils.QueryG
 
 	return devops, nil
 }
+
+// NewIoT creates a new iot use case query generator. The iot queries only
+// exist for the naive, document-per-event storage format.
+func (g *BaseGenerator) NewIoT(start, end time.Time, scale int) (utils.QueryGenerator, error) {
+	core, err := iot.NewCore(start, end, scale)
+
+	if err != nil {
+		return nil, err
+	}
+
+	iot := &NaiveIoT{
+		BaseGenerator: g,
+		Core:          core,
+	}
+
+	return iot, nil
+}
@@ -0,0 +1,25 @@
+package mongo
+
+import (
+	"fmt"
+
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/custom"
+	"github.com/bodhiye/tsbs/pkg/query"
+	"github.com/globalsign/mgo/bson"
+)
+
+// CustomQuery fills in a query rendered from a custom query template, which
+// is the aggregation pipeline on the point_data collection as a JSON array.
+func (g *BaseGenerator) CustomQuery(qi query.Query, cq *custom.Query) {
+	var pipelineQuery []bson.M
+	if err := bson.UnmarshalJSON([]byte(cq.Query), &pipelineQuery); err != nil {
+		panic(fmt.Sprintf("custom query '%s' is not a JSON aggregation pipeline: %v", cq.Name, err))
+	}
+
+	humanLabel := cq.HumanLabel("Mongo")
+	q := qi.(*query.Mongo)
+	q.HumanLabel = []byte(humanLabel)
+	q.BsonDoc = pipelineQuery
+	q.CollectionName = []byte("point_data")
+	q.HumanDescription = []byte(fmt.Sprintf("%s (%s)", cq.HumanDescription(humanLabel), q.CollectionName))
+}
@@ -0,0 +1,53 @@
+package mongo
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/custom"
+	"github.com/bodhiye/tsbs/pkg/query"
+	"github.com/bodhiye/tsbs/tools/utils"
+	"github.com/globalsign/mgo/bson"
+)
+
+func TestCustomQuery(t *testing.T) {
+	start := time.Unix(0, 0)
+	interval, err := utils.NewTimeInterval(start, start.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cq := &custom.Query{
+		Name:     "hosts",
+		Query:    `[{"$match": {"measurement": "cpu", "tags.hostname": {"$in": ["host_1"]}}}, {"$limit": 1}]`,
+		Interval: interval,
+	}
+
+	b := &BaseGenerator{}
+	q := b.GenerateEmptyQuery().(*query.Mongo)
+	b.CustomQuery(q, cq)
+
+	wantPipeline := []bson.M{
+		{"$match": bson.M{"measurement": "cpu", "tags.hostname": bson.M{"$in": []interface{}{"host_1"}}}},
+		{"$limit": 1},
+	}
+	// the JSON decoder makes its own map and number types, so the pipelines
+	// are compared as they print
+	if fmt.Sprint(q.BsonDoc) != fmt.Sprint(wantPipeline) {
+		t.Errorf("incorrect pipeline:\ngot\n%v\nwant\n%v", q.BsonDoc, wantPipeline)
+	}
+	if got, want := string(q.HumanLabel), "Mongo custom query hosts"; got != want {
+		t.Errorf("incorrect human label: got %s want %s", got, want)
+	}
+	if got, want := string(q.HumanDescription), "Mongo custom query hosts: 1970-01-01T00:00:00Z (point_data)"; got != want {
+		t.Errorf("incorrect human description: got %s want %s", got, want)
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("did not panic for a query that is not a JSON pipeline")
+		}
+	}()
+	cq.Query = "db.point_data.find()"
+	b.CustomQuery(b.GenerateEmptyQuery(), cq)
+}
@@ -0,0 +1,508 @@
+package mongo
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/iot"
+	"github.com/bodhiye/tsbs/pkg/query"
+	"github.com/globalsign/mgo/bson"
+)
+
+const (
+	tenMinutesNano = int64(10 * time.Minute)
+	dayNano        = int64(24 * time.Hour)
+)
+
+// NaiveIoT produces Mongo-specific queries for the iot use case. It works on
+// the document-per-event format, where every reading or diagnostics point is
+// a document of the point_data collection. The loader stores the numeric
+// truck tags (load_capacity, fuel_capacity, nominal_fuel_consumption) as
+// strings, so they are converted with $toDouble. Mongo aggregation pipelines
+// have no lead/lag operators, so the avg-daily-driving-session and
+// breakdown-frequency queries are not implemented.
+type NaiveIoT struct {
+	*BaseGenerator
+	*iot.Core
+}
+
+// timeBucket truncates timestamp_ns to a multiple of bucketNano.
+func timeBucket(bucketNano int64) bson.M {
+	return bson.M{
+		"$subtract": []interface{}{
+			"$timestamp_ns",
+			bson.M{"$mod": []interface{}{"$timestamp_ns", bucketNano}},
+		},
+	}
+}
+
+func (i *NaiveIoT) fillInQuery(qi query.Query, humanLabel, humanDesc string, pipelineQuery []bson.M) {
+	q := qi.(*query.Mongo)
+	q.HumanLabel = []byte(humanLabel)
+	q.BsonDoc = pipelineQuery
+	q.CollectionName = []byte("point_data")
+	q.HumanDescription = []byte(fmt.Sprintf("%s (%s)", humanDesc, q.CollectionName))
+}
+
+// lastLocPipeline returns the pipeline for the last location of every truck
+// matching match.
+func lastLocPipeline(match bson.M) []bson.M {
+	match["measurement"] = iot.ReadingsTableName
+	return []bson.M{
+		{"$match": match},
+		{"$sort": bson.M{"timestamp_ns": -1}},
+		{
+			"$group": bson.M{
+				"_id":       "$tags.name",
+				"driver":    bson.M{"$first": "$tags.driver"},
+				"longitude": bson.M{"$first": "$fields.longitude"},
+				"latitude":  bson.M{"$first": "$fields.latitude"},
+			},
+		},
+	}
+}
+
+// LastLocByTruck finds the truck location for nTrucks,
+// e.g. in pseudo-SQL:
+//
+// SELECT last(longitude), last(latitude)
+// FROM readings
+// WHERE name IN ('truck_1', ..., 'truck_N')
+// GROUP BY name
+func (i *NaiveIoT) LastLocByTruck(qi query.Query, nTrucks int) {
+	trucks, err := i.GetRandomTrucks(nTrucks)
+	panicIfErr(err)
+
+	pipelineQuery := lastLocPipeline(bson.M{
+		"tags.name": bson.M{"$in": trucks},
+	})
+
+	humanLabel := "Mongo [NAIVE] last location by specific truck"
+	humanDesc := fmt.Sprintf("%s: random %4d trucks", humanLabel, nTrucks)
+	i.fillInQuery(qi, humanLabel, humanDesc, pipelineQuery)
+}
+
+// LastLocPerTruck finds all the truck locations of a random fleet,
+// e.g. in pseudo-SQL:
+//
+// SELECT last(longitude), last(latitude)
+// FROM readings
+// WHERE fleet = '$FLEET'
+// GROUP BY name
+func (i *NaiveIoT) LastLocPerTruck(qi query.Query) {
+	pipelineQuery := lastLocPipeline(bson.M{
+		"tags.fleet": i.GetRandomFleet(),
+		"tags.name":  bson.M{"$ne": nil},
+	})
+
+	humanLabel := "Mongo [NAIVE] last location per truck"
+	humanDesc := humanLabel
+	i.fillInQuery(qi, humanLabel, humanDesc, pipelineQuery)
+}
+
+// TrucksWithLowFuel finds all trucks of a random fleet with low fuel (less than 10%),
+// e.g. in pseudo-SQL:
+//
+// SELECT name, driver, last(fuel_state)
+// FROM diagnostics
+// WHERE fleet = '$FLEET'
+// GROUP BY name
+// HAVING last(fuel_state) < 0.1
+func (i *NaiveIoT) TrucksWithLowFuel(qi query.Query) {
+	pipelineQuery := []bson.M{
+		{
+			"$match": bson.M{
+				"measurement": iot.DiagnosticsTableName,
+				"tags.fleet":  i.GetRandomFleet(),
+				"tags.name":   bson.M{"$ne": nil},
+			},
+		},
+		{"$sort": bson.M{"timestamp_ns": -1}},
+		{
+			"$group": bson.M{
+				"_id":        "$tags.name",
+				"driver":     bson.M{"$first": "$tags.driver"},
+				"fuel_state": bson.M{"$first": "$fields.fuel_state"},
+			},
+		},
+		{"$match": bson.M{"fuel_state": bson.M{"$lt": 0.1}}},
+	}
+
+	humanLabel := "Mongo [NAIVE] trucks with low fuel"
+	humanDesc := fmt.Sprintf("%s: under 10 percent", humanLabel)
+	i.fillInQuery(qi, humanLabel, humanDesc, pipelineQuery)
+}
+
+// TrucksWithHighLoad finds all trucks of a random fleet that have load over 90%,
+// e.g. in pseudo-SQL:
+//
+// SELECT name, driver, last(current_load), load_capacity
+// FROM diagnostics
+// WHERE fleet = '$FLEET'
+// GROUP BY name
+// HAVING last(current_load) / load_capacity > 0.9
+func (i *NaiveIoT) TrucksWithHighLoad(qi query.Query) {
+	pipelineQuery := []bson.M{
+		{
+			"$match": bson.M{
+				"measurement": iot.DiagnosticsTableName,
+				"tags.fleet":  i.GetRandomFleet(),
+				"tags.name":   bson.M{"$ne": nil},
+			},
+		},
+		{"$sort": bson.M{"timestamp_ns": -1}},
+		{
+			"$group": bson.M{
+				"_id":           "$tags.name",
+				"driver":        bson.M{"$first": "$tags.driver"},
+				"current_load":  bson.M{"$first": "$fields.current_load"},
+				"load_capacity": bson.M{"$first": bson.M{"$toDouble": "$tags.load_capacity"}},
+			},
+		},
+		{
+			"$match": bson.M{
+				"$expr": bson.M{
+					"$gt": []interface{}{
+						bson.M{"$divide": []interface{}{"$current_load", "$load_capacity"}},
+						0.9,
+					},
+				},
+			},
+		},
+	}
+
+	humanLabel := "Mongo [NAIVE] trucks with high load"
+	humanDesc := fmt.Sprintf("%s: over 90 percent", humanLabel)
+	i.fillInQuery(qi, humanLabel, humanDesc, pipelineQuery)
+}
+
+// StationaryTrucks finds all trucks of a random fleet that have low average velocity in a time window,
+// e.g. in pseudo-SQL:
+//
+// SELECT name, driver
+// FROM readings
+// WHERE fleet = '$FLEET' AND time >= '$TIME_START' AND time < '$TIME_END'
+// GROUP BY name
+// HAVING avg(velocity) < 1
+func (i *NaiveIoT) StationaryTrucks(qi query.Query) {
+	interval := i.Interval.MustRandWindow(iot.StationaryDuration)
+	pipelineQuery := []bson.M{
+		{
+			"$match": bson.M{
+				"measurement": iot.ReadingsTableName,
+				"tags.fleet":  i.GetRandomFleet(),
+				"tags.name":   bson.M{"$ne": nil},
+				"timestamp_ns": bson.M{
+					"$gte": interval.StartUnixNano(),
+					"$lt":  interval.EndUnixNano(),
+				},
+			},
+		},
+		{
+			"$group": bson.M{
+				"_id":           "$tags.name",
+				"driver":        bson.M{"$first": "$tags.driver"},
+				"mean_velocity": bson.M{"$avg": "$fields.velocity"},
+			},
+		},
+		{"$match": bson.M{"mean_velocity": bson.M{"$lt": 1}}},
+	}
+
+	humanLabel := "Mongo [NAIVE] stationary trucks"
+	humanDesc := fmt.Sprintf("%s: with low avg velocity in last 10 minutes", humanLabel)
+	i.fillInQuery(qi, humanLabel, humanDesc, pipelineQuery)
+}
+
+// TrucksWithLongDrivingSessions finds all trucks of a random fleet that have not stopped at least 20 mins in the last 4 hours,
+// e.g. in pseudo-SQL:
+//
+// SELECT name, driver
+// FROM (
+// SELECT name, driver, avg(velocity) AS mean_velocity
+// FROM readings
+// WHERE fleet = '$FLEET' AND time >= '$TIME_START' AND time < '$TIME_END'
+// GROUP BY name, ten_minutes
+// )
+// WHERE mean_velocity > 1
+// GROUP BY name
+// HAVING count(*) > 22
+func (i *NaiveIoT) TrucksWithLongDrivingSessions(qi query.Query) {
+	interval := i.Interval.MustRandWindow(iot.LongDrivingSessionDuration)
+	// Calculate number of 10 min intervals that is the max driving duration for the session if we rest 5 mins per hour.
+	pipelineQuery := i.drivingSessionsPipeline(interval.StartUnixNano(), interval.EndUnixNano(), tenMinutePeriods(5, iot.LongDrivingSessionDuration))
+
+	humanLabel := "Mongo [NAIVE] trucks with longer driving sessions"
+	humanDesc := fmt.Sprintf("%s: stopped less than 20 mins in 4 hour period", humanLabel)
+	i.fillInQuery(qi, humanLabel, humanDesc, pipelineQuery)
+}
+
+// TrucksWithLongDailySessions finds all trucks of a random fleet that have driven more than 10 hours in the last 24 hours,
+// e.g. in pseudo-SQL:
+//
+// SELECT name, driver
+// FROM (
+// SELECT name, driver, avg(velocity) AS mean_velocity
+// FROM readings
+// WHERE fleet = '$FLEET' AND time >= '$TIME_START' AND time < '$TIME_END'
+// GROUP BY name, ten_minutes
+// )
+// WHERE mean_velocity > 1
+// GROUP BY name
+// HAVING count(*) > 60
+func (i *NaiveIoT) TrucksWithLongDailySessions(qi query.Query) {
+	interval := i.Interval.MustRandWindow(iot.DailyDrivingDuration)
+	// Calculate number of 10 min intervals that is the max driving duration for the session if we rest 35 mins per hour.
+	pipelineQuery := i.drivingSessionsPipeline(interval.StartUnixNano(), interval.EndUnixNano(), tenMinutePeriods(35, iot.DailyDrivingDuration))
+
+	humanLabel := "Mongo [NAIVE] trucks with longer daily sessions"
+	humanDesc := fmt.Sprintf("%s: drove more than 10 hours in the last 24 hours", humanLabel)
+	i.fillInQuery(qi, humanLabel, humanDesc, pipelineQuery)
+}
+
+// drivingSessionsPipeline selects the trucks of a random fleet that were
+// driving in more than periods ten minute periods between start and end.
+func (i *NaiveIoT) drivingSessionsPipeline(start, end int64, periods int) []bson.M {
+	return []bson.M{
+		{
+			"$match": bson.M{
+				"measurement": iot.ReadingsTableName,
+				"tags.fleet":  i.GetRandomFleet(),
+				"tags.name":   bson.M{"$ne": nil},
+				"timestamp_ns": bson.M{
+					"$gte": start,
+					"$lt":  end,
+				},
+			},
+		},
+		{
+			"$group": bson.M{
+				"_id": bson.M{
+					"name":        "$tags.name",
+					"ten_minutes": timeBucket(tenMinutesNano),
+				},
+				"driver":        bson.M{"$first": "$tags.driver"},
+				"mean_velocity": bson.M{"$avg": "$fields.velocity"},
+			},
+		},
+		{"$match": bson.M{"mean_velocity": bson.M{"$gt": 1}}},
+		{
+			"$group": bson.M{
+				"_id":         "$_id.name",
+				"driver":      bson.M{"$first": "$driver"},
+				"ten_minutes": bson.M{"$sum": 1},
+			},
+		},
+		{"$match": bson.M{"ten_minutes": bson.M{"$gt": periods}}},
+	}
+}
+
+// AvgVsProjectedFuelConsumption calculates average and projected fuel consumption per fleet,
+// e.g. in pseudo-SQL:
+//
+// SELECT fleet, avg(fuel_consumption), avg(nominal_fuel_consumption)
+// FROM readings
+// WHERE velocity > 1
+// GROUP BY fleet
+func (i *NaiveIoT) AvgVsProjectedFuelConsumption(qi query.Query) {
+	pipelineQuery := []bson.M{
+		{
+			"$match": bson.M{
+				"measurement":                   iot.ReadingsTableName,
+				"fields.velocity":               bson.M{"$gt": 1},
+				"tags.fleet":                    bson.M{"$ne": nil},
+				"tags.nominal_fuel_consumption": bson.M{"$ne": nil},
+				"tags.name":                     bson.M{"$ne": nil},
+			},
+		},
+		{
+			"$group": bson.M{
+				"_id":                        "$tags.fleet",
+				"avg_fuel_consumption":       bson.M{"$avg": "$fields.fuel_consumption"},
+				"projected_fuel_consumption": bson.M{"$avg": bson.M{"$toDouble": "$tags.nominal_fuel_consumption"}},
+			},
+		},
+	}
+
+	humanLabel := "Mongo [NAIVE] average vs projected fuel consumption per fleet"
+	humanDesc := humanLabel
+	i.fillInQuery(qi, humanLabel, humanDesc, pipelineQuery)
+}
+
+// AvgDailyDrivingDuration finds the average driving hours per day per driver,
+// e.g. in pseudo-SQL:
+//
+// SELECT fleet, name, driver, avg(hours)
+// FROM (
+// SELECT name, day, count(*) / 6 AS hours
+// FROM (
+// SELECT name, driver, avg(velocity) AS mean_velocity
+// FROM readings
+// GROUP BY name, ten_minutes
+// )
+// WHERE mean_velocity > 1
+// GROUP BY name, day
+// )
+// GROUP BY name
+func (i *NaiveIoT) AvgDailyDrivingDuration(qi query.Query) {
+	pipelineQuery := []bson.M{
+		{
+			"$match": bson.M{
+				"measurement": iot.ReadingsTableName,
+				"tags.name":   bson.M{"$ne": nil},
+			},
+		},
+		{
+			"$group": bson.M{
+				"_id": bson.M{
+					"name":        "$tags.name",
+					"ten_minutes": timeBucket(tenMinutesNano),
+				},
+				"fleet":         bson.M{"$first": "$tags.fleet"},
+				"driver":        bson.M{"$first": "$tags.driver"},
+				"mean_velocity": bson.M{"$avg": "$fields.velocity"},
+			},
+		},
+		{"$match": bson.M{"mean_velocity": bson.M{"$gt": 1}}},
+		{
+			"$group": bson.M{
+				"_id": bson.M{
+					"name": "$_id.name",
+					"day": bson.M{
+						"$subtract": []interface{}{
+							"$_id.ten_minutes",
+							bson.M{"$mod": []interface{}{"$_id.ten_minutes", dayNano}},
+						},
+					},
+				},
+				"fleet":       bson.M{"$first": "$fleet"},
+				"driver":      bson.M{"$first": "$driver"},
+				"ten_minutes": bson.M{"$sum": 1},
+			},
+		},
+		{
+			"$group": bson.M{
+				"_id":             "$_id.name",
+				"fleet":           bson.M{"$first": "$fleet"},
+				"driver":          bson.M{"$first": "$driver"},
+				"avg_daily_hours": bson.M{"$avg": bson.M{"$divide": []interface{}{"$ten_minutes", 6}}},
+			},
+		},
+	}
+
+	humanLabel := "Mongo [NAIVE] average driver driving duration per day"
+	humanDesc := humanLabel
+	i.fillInQuery(qi, humanLabel, humanDesc, pipelineQuery)
+}
+
+// AvgLoad finds the average load per truck model per fleet,
+// e.g. in pseudo-SQL:
+//
+// SELECT fleet, model, load_capacity, avg(avg_load / load_capacity)
+// FROM (
+// SELECT name, fleet, model, load_capacity, avg(current_load) AS avg_load
+// FROM diagnostics
+// GROUP BY name
+// )
+// GROUP BY fleet, model, load_capacity
+func (i *NaiveIoT) AvgLoad(qi query.Query) {
+	pipelineQuery := []bson.M{
+		{
+			"$match": bson.M{
+				"measurement": iot.DiagnosticsTableName,
+				"tags.name":   bson.M{"$ne": nil},
+			},
+		},
+		{
+			"$group": bson.M{
+				"_id":           "$tags.name",
+				"fleet":         bson.M{"$first": "$tags.fleet"},
+				"model":         bson.M{"$first": "$tags.model"},
+				"load_capacity": bson.M{"$first": bson.M{"$toDouble": "$tags.load_capacity"}},
+				"avg_load":      bson.M{"$avg": "$fields.current_load"},
+			},
+		},
+		{
+			"$group": bson.M{
+				"_id": bson.M{
+					"fleet":         "$fleet",
+					"model":         "$model",
+					"load_capacity": "$load_capacity",
+				},
+				"avg_load_percentage": bson.M{"$avg": bson.M{"$divide": []interface{}{"$avg_load", "$load_capacity"}}},
+			},
+		},
+	}
+
+	humanLabel := "Mongo [NAIVE] average load per truck model per fleet"
+	humanDesc := humanLabel
+	i.fillInQuery(qi, humanLabel, humanDesc, pipelineQuery)
+}
+
+// DailyTruckActivity returns the number of hours trucks has been active (not out-of-commission) per day per fleet per model,
+// e.g. in pseudo-SQL:
+//
+// SELECT day, fleet, model, count(*) / 144
+// FROM (
+// SELECT name, fleet, model, avg(status) AS mean_status
+// FROM diagnostics
+// GROUP BY name, ten_minutes
+// )
+// WHERE mean_status < 1
+// GROUP BY day, fleet, model
+// ORDER BY day
+func (i *NaiveIoT) DailyTruckActivity(qi query.Query) {
+	pipelineQuery := []bson.M{
+		{
+			"$match": bson.M{
+				"measurement": iot.DiagnosticsTableName,
+				"tags.name":   bson.M{"$ne": nil},
+			},
+		},
+		{
+			"$group": bson.M{
+				"_id": bson.M{
+					"name":        "$tags.name",
+					"ten_minutes": timeBucket(tenMinutesNano),
+				},
+				"fleet":       bson.M{"$first": "$tags.fleet"},
+				"model":       bson.M{"$first": "$tags.model"},
+				"mean_status": bson.M{"$avg": "$fields.status"},
+			},
+		},
+		{"$match": bson.M{"mean_status": bson.M{"$lt": 1}}},
+		{
+			"$group": bson.M{
+				"_id": bson.M{
+					"fleet": "$fleet",
+					"model": "$model",
+					"day": bson.M{
+						"$subtract": []interface{}{
+							"$_id.ten_minutes",
+							bson.M{"$mod": []interface{}{"$_id.ten_minutes", dayNano}},
+						},
+					},
+				},
+				"ten_minutes": bson.M{"$sum": 1},
+			},
+		},
+		{
+			"$project": bson.M{
+				"daily_activity": bson.M{"$divide": []interface{}{"$ten_minutes", 144}},
+			},
+		},
+		{"$sort": bson.M{"_id.day": 1}},
+	}
+
+	humanLabel := "Mongo [NAIVE] daily truck activity per fleet per model"
+	humanDesc := humanLabel
+	i.fillInQuery(qi, humanLabel, humanDesc, pipelineQuery)
+}
+
+// tenMinutePeriods calculates the number of 10 minute periods that can fit in
+// the time duration if we subtract the minutes specified by minutesPerHour value.
+// E.g.: 4 hours - 5 minutes per hour = 3 hours and 40 minutes = 22 ten minute periods
+func tenMinutePeriods(minutesPerHour float64, duration time.Duration) int {
+	durationMinutes := duration.Minutes()
+	leftover := minutesPerHour * duration.Hours()
+	return int((durationMinutes - leftover) / 10)
+}
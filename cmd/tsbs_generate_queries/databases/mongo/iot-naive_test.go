@@ -0,0 +1,473 @@
+package mongo
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/bodhiye/tsbs/pkg/query"
+	"github.com/globalsign/mgo/bson"
+)
+
+const (
+	testScale = 10
+)
+
+type IoTTestCase struct {
+	desc               string
+	input              int
+	fail               bool
+	failMsg            string
+	expectedHumanLabel string
+	expectedHumanDesc  string
+	expectedMatch      bson.M
+	expectedStages     int
+}
+
+func TestLastLocByTruck(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc:    "zero trucks",
+			input:   0,
+			fail:    true,
+			failMsg: "number of trucks cannot be < 1; got 0",
+		},
+		{
+			desc:    "more trucks than scale",
+			input:   2 * testScale,
+			fail:    true,
+			failMsg: "number of trucks (20) larger than total trucks. See --scale (10)",
+		},
+		{
+			desc:  "one truck",
+			input: 1,
+
+			expectedHumanLabel: "Mongo [NAIVE] last location by specific truck",
+			expectedHumanDesc:  "Mongo [NAIVE] last location by specific truck: random    1 trucks (point_data)",
+			expectedMatch: bson.M{
+				"measurement": "readings",
+				"tags.name":   bson.M{"$in": []string{"truck_5"}},
+			},
+			expectedStages: 3,
+		},
+		{
+			desc:  "three trucks",
+			input: 3,
+
+			expectedHumanLabel: "Mongo [NAIVE] last location by specific truck",
+			expectedHumanDesc:  "Mongo [NAIVE] last location by specific truck: random    3 trucks (point_data)",
+			expectedMatch: bson.M{
+				"measurement": "readings",
+				"tags.name":   bson.M{"$in": []string{"truck_9", "truck_3", "truck_5"}},
+			},
+			expectedStages: 3,
+		},
+	}
+
+	testFunc := func(i *NaiveIoT, c IoTTestCase) query.Query {
+		q := i.GenerateEmptyQuery()
+		i.LastLocByTruck(q, c.input)
+		return q
+	}
+
+	start := time.Unix(0, 0)
+	end := start.Add(time.Hour)
+
+	runIoTTestCases(t, testFunc, start, end, cases)
+}
+
+func TestLastLocPerTruck(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "Mongo [NAIVE] last location per truck",
+			expectedHumanDesc:  "Mongo [NAIVE] last location per truck (point_data)",
+			expectedMatch: bson.M{
+				"measurement": "readings",
+				"tags.fleet":  "South",
+				"tags.name":   bson.M{"$ne": nil},
+			},
+			expectedStages: 3,
+		},
+	}
+
+	testFunc := func(i *NaiveIoT, c IoTTestCase) query.Query {
+		q := i.GenerateEmptyQuery()
+		i.LastLocPerTruck(q)
+		return q
+	}
+
+	start := time.Unix(0, 0)
+	end := start.Add(time.Hour)
+
+	runIoTTestCases(t, testFunc, start, end, cases)
+}
+
+func TestTrucksWithLowFuel(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "Mongo [NAIVE] trucks with low fuel",
+			expectedHumanDesc:  "Mongo [NAIVE] trucks with low fuel: under 10 percent (point_data)",
+			expectedMatch: bson.M{
+				"measurement": "diagnostics",
+				"tags.fleet":  "South",
+				"tags.name":   bson.M{"$ne": nil},
+			},
+			expectedStages: 4,
+		},
+	}
+
+	testFunc := func(i *NaiveIoT, c IoTTestCase) query.Query {
+		q := i.GenerateEmptyQuery()
+		i.TrucksWithLowFuel(q)
+		return q
+	}
+
+	start := time.Unix(0, 0)
+	end := start.Add(time.Hour)
+
+	runIoTTestCases(t, testFunc, start, end, cases)
+}
+
+func TestTrucksWithHighLoad(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "Mongo [NAIVE] trucks with high load",
+			expectedHumanDesc:  "Mongo [NAIVE] trucks with high load: over 90 percent (point_data)",
+			expectedMatch: bson.M{
+				"measurement": "diagnostics",
+				"tags.fleet":  "South",
+				"tags.name":   bson.M{"$ne": nil},
+			},
+			expectedStages: 4,
+		},
+	}
+
+	testFunc := func(i *NaiveIoT, c IoTTestCase) query.Query {
+		q := i.GenerateEmptyQuery()
+		i.TrucksWithHighLoad(q)
+		return q
+	}
+
+	start := time.Unix(0, 0)
+	end := start.Add(time.Hour)
+
+	runIoTTestCases(t, testFunc, start, end, cases)
+}
+
+func TestStationaryTrucks(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "Mongo [NAIVE] stationary trucks",
+			expectedHumanDesc:  "Mongo [NAIVE] stationary trucks: with low avg velocity in last 10 minutes (point_data)",
+			expectedMatch: bson.M{
+				"measurement": "readings",
+				"tags.fleet":  "West",
+				"tags.name":   bson.M{"$ne": nil},
+				"timestamp_ns": bson.M{
+					"$gte": int64(2182646325489),
+					"$lt":  int64(2782646325489),
+				},
+			},
+			expectedStages: 3,
+		},
+	}
+
+	testFunc := func(i *NaiveIoT, c IoTTestCase) query.Query {
+		q := i.GenerateEmptyQuery()
+		i.StationaryTrucks(q)
+		return q
+	}
+
+	start := time.Unix(0, 0)
+	end := start.Add(time.Hour)
+
+	runIoTTestCases(t, testFunc, start, end, cases)
+}
+
+func TestTrucksWithLongDrivingSessions(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "Mongo [NAIVE] trucks with longer driving sessions",
+			expectedHumanDesc:  "Mongo [NAIVE] trucks with longer driving sessions: stopped less than 20 mins in 4 hour period (point_data)",
+			expectedMatch: bson.M{
+				"measurement": "readings",
+				"tags.fleet":  "West",
+				"tags.name":   bson.M{"$ne": nil},
+				"timestamp_ns": bson.M{
+					"$gte": int64(982646325489),
+					"$lt":  int64(15382646325489),
+				},
+			},
+			expectedStages: 5,
+		},
+	}
+
+	testFunc := func(i *NaiveIoT, c IoTTestCase) query.Query {
+		q := i.GenerateEmptyQuery()
+		i.TrucksWithLongDrivingSessions(q)
+		return q
+	}
+
+	start := time.Unix(0, 0)
+	end := start.Add(6 * time.Hour)
+
+	runIoTTestCases(t, testFunc, start, end, cases)
+}
+
+func TestTrucksWithLongDailySessions(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "Mongo [NAIVE] trucks with longer daily sessions",
+			expectedHumanDesc:  "Mongo [NAIVE] trucks with longer daily sessions: drove more than 10 hours in the last 24 hours (point_data)",
+			expectedMatch: bson.M{
+				"measurement": "readings",
+				"tags.fleet":  "West",
+				"tags.name":   bson.M{"$ne": nil},
+				"timestamp_ns": bson.M{
+					"$gte": int64(982646325489),
+					"$lt":  int64(87382646325489),
+				},
+			},
+			expectedStages: 5,
+		},
+	}
+
+	testFunc := func(i *NaiveIoT, c IoTTestCase) query.Query {
+		q := i.GenerateEmptyQuery()
+		i.TrucksWithLongDailySessions(q)
+		return q
+	}
+
+	start := time.Unix(0, 0)
+	end := start.Add(25 * time.Hour)
+
+	runIoTTestCases(t, testFunc, start, end, cases)
+}
+
+func TestAvgVsProjectedFuelConsumption(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "Mongo [NAIVE] average vs projected fuel consumption per fleet",
+			expectedHumanDesc:  "Mongo [NAIVE] average vs projected fuel consumption per fleet (point_data)",
+			expectedMatch: bson.M{
+				"measurement":                   "readings",
+				"fields.velocity":               bson.M{"$gt": 1},
+				"tags.fleet":                    bson.M{"$ne": nil},
+				"tags.nominal_fuel_consumption": bson.M{"$ne": nil},
+				"tags.name":                     bson.M{"$ne": nil},
+			},
+			expectedStages: 2,
+		},
+	}
+
+	testFunc := func(i *NaiveIoT, c IoTTestCase) query.Query {
+		q := i.GenerateEmptyQuery()
+		i.AvgVsProjectedFuelConsumption(q)
+		return q
+	}
+
+	start := time.Unix(0, 0)
+	end := start.Add(time.Hour)
+
+	runIoTTestCases(t, testFunc, start, end, cases)
+}
+
+func TestAvgDailyDrivingDuration(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "Mongo [NAIVE] average driver driving duration per day",
+			expectedHumanDesc:  "Mongo [NAIVE] average driver driving duration per day (point_data)",
+			expectedMatch: bson.M{
+				"measurement": "readings",
+				"tags.name":   bson.M{"$ne": nil},
+			},
+			expectedStages: 5,
+		},
+	}
+
+	testFunc := func(i *NaiveIoT, c IoTTestCase) query.Query {
+		q := i.GenerateEmptyQuery()
+		i.AvgDailyDrivingDuration(q)
+		return q
+	}
+
+	start := time.Unix(0, 0)
+	end := start.Add(time.Hour)
+
+	runIoTTestCases(t, testFunc, start, end, cases)
+}
+
+func TestAvgLoad(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "Mongo [NAIVE] average load per truck model per fleet",
+			expectedHumanDesc:  "Mongo [NAIVE] average load per truck model per fleet (point_data)",
+			expectedMatch: bson.M{
+				"measurement": "diagnostics",
+				"tags.name":   bson.M{"$ne": nil},
+			},
+			expectedStages: 3,
+		},
+	}
+
+	testFunc := func(i *NaiveIoT, c IoTTestCase) query.Query {
+		q := i.GenerateEmptyQuery()
+		i.AvgLoad(q)
+		return q
+	}
+
+	start := time.Unix(0, 0)
+	end := start.Add(time.Hour)
+
+	runIoTTestCases(t, testFunc, start, end, cases)
+}
+
+func TestDailyTruckActivity(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "Mongo [NAIVE] daily truck activity per fleet per model",
+			expectedHumanDesc:  "Mongo [NAIVE] daily truck activity per fleet per model (point_data)",
+			expectedMatch: bson.M{
+				"measurement": "diagnostics",
+				"tags.name":   bson.M{"$ne": nil},
+			},
+			expectedStages: 6,
+		},
+	}
+
+	testFunc := func(i *NaiveIoT, c IoTTestCase) query.Query {
+		q := i.GenerateEmptyQuery()
+		i.DailyTruckActivity(q)
+		return q
+	}
+
+	start := time.Unix(0, 0)
+	end := start.Add(time.Hour)
+
+	runIoTTestCases(t, testFunc, start, end, cases)
+}
+
+func TestTenMinutePeriods(t *testing.T) {
+	cases := []struct {
+		minutesPerHour float64
+		duration       time.Duration
+		result         int
+	}{
+		{
+			minutesPerHour: 5.0,
+			duration:       4 * time.Hour,
+			result:         22,
+		},
+		{
+			minutesPerHour: 10.0,
+			duration:       24 * time.Hour,
+			result:         120,
+		},
+		{
+			minutesPerHour: 0.0,
+			duration:       24 * time.Hour,
+			result:         144,
+		},
+		{
+			minutesPerHour: 1.0,
+			duration:       0 * time.Minute,
+			result:         0,
+		},
+		{
+			minutesPerHour: 0.0,
+			duration:       0 * time.Minute,
+			result:         0,
+		},
+		{
+			minutesPerHour: 1.0,
+			duration:       30 * time.Minute,
+			result:         2,
+		},
+	}
+
+	for _, c := range cases {
+		if got := tenMinutePeriods(c.minutesPerHour, c.duration); got != c.result {
+			t.Errorf("incorrect result for %.2f minutes per hour, duration %s: got %d want %d", c.minutesPerHour, c.duration.String(), got, c.result)
+		}
+	}
+}
+
+func runIoTTestCases(t *testing.T, testFunc func(*NaiveIoT, IoTTestCase) query.Query, s time.Time, e time.Time, cases []IoTTestCase) {
+	rand.Seed(123) // Setting seed for testing purposes.
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			b := BaseGenerator{}
+			dq, err := b.NewIoT(s, e, testScale)
+			if err != nil {
+				t.Fatalf("Error while creating iot generator")
+			}
+			i := dq.(*NaiveIoT)
+
+			if c.fail {
+				func() {
+					defer func() {
+						r := recover()
+						if r == nil {
+							t.Fatalf("did not panic when should")
+						}
+
+						if r != c.failMsg {
+							t.Fatalf("incorrect fail message: got %s, want %s", r, c.failMsg)
+						}
+					}()
+
+					testFunc(i, c)
+				}()
+			} else {
+				q := testFunc(i, c)
+
+				verifyIoTQuery(t, q, c)
+			}
+		})
+	}
+}
+
+func verifyIoTQuery(t *testing.T, qi query.Query, c IoTTestCase) {
+	q, ok := qi.(*query.Mongo)
+	if !ok {
+		t.Fatal("Filled query is not *query.Mongo type")
+	}
+
+	if got := string(q.HumanLabel); got != c.expectedHumanLabel {
+		t.Errorf("incorrect human label:\ngot\n%s\nwant\n%s", got, c.expectedHumanLabel)
+	}
+	if got := string(q.HumanDescription); got != c.expectedHumanDesc {
+		t.Errorf("incorrect human description:\ngot\n%s\nwant\n%s", got, c.expectedHumanDesc)
+	}
+	if got := string(q.CollectionName); got != "point_data" {
+		t.Errorf("incorrect collection name: got %s want point_data", got)
+	}
+	if got := len(q.BsonDoc); got != c.expectedStages {
+		t.Fatalf("incorrect number of pipeline stages: got %d want %d", got, c.expectedStages)
+	}
+	if got := q.BsonDoc[0]["$match"]; !reflect.DeepEqual(got, c.expectedMatch) {
+		t.Errorf("incorrect $match stage:\ngot\n%v\nwant\n%v", got, c.expectedMatch)
+	}
+}
@@ -6,6 +6,9 @@ import (
 	"time"
 
 	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/devops"
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/events"
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/iot"
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/ticks"
 	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/utils"
 	"github.com/bodhiye/tsbs/pkg/query"
 )
@@ -48,3 +51,51 @@ func (g *BaseGenerator) NewDevops(start, end time.Time, scale int) (utils.QueryG
 
 	return devops, nil
 }
+
+// NewIoT creates a new iot use case query generator.
+func (g *BaseGenerator) NewIoT(start, end time.Time, scale int) (utils.QueryGenerator, error) {
+	core, err := iot.NewCore(start, end, scale)
+
+	if err != nil {
+		return nil, err
+	}
+
+	iot := &IoT{
+		BaseGenerator: g,
+		Core:          core,
+	}
+
+	return iot, nil
+}
+
+// NewTicks creates a new ticks use case query generator.
+func (g *BaseGenerator) NewTicks(start, end time.Time, scale int) (utils.QueryGenerator, error) {
+	core, err := ticks.NewCore(start, end, scale)
+
+	if err != nil {
+		return nil, err
+	}
+
+	ticks := &Ticks{
+		BaseGenerator: g,
+		Core:          core,
+	}
+
+	return ticks, nil
+}
+
+// NewEvents creates a new events use case query generator.
+func (g *BaseGenerator) NewEvents(start, end time.Time, scale int) (utils.QueryGenerator, error) {
+	core, err := events.NewCore(start, end, scale)
+
+	if err != nil {
+		return nil, err
+	}
+
+	events := &Events{
+		BaseGenerator: g,
+		Core:          core,
+	}
+
+	return events, nil
+}
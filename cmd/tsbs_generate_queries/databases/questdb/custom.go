@@ -0,0 +1,12 @@
+package questdb
+
+import (
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/custom"
+	"github.com/bodhiye/tsbs/pkg/query"
+)
+
+// CustomQuery fills in a query rendered from a custom query template.
+func (g *BaseGenerator) CustomQuery(qi query.Query, cq *custom.Query) {
+	humanLabel := cq.HumanLabel("QuestDB")
+	g.fillInQuery(qi, humanLabel, cq.HumanDescription(humanLabel), cq.Query)
+}
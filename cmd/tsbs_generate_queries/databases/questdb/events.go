@@ -0,0 +1,85 @@
+package questdb
+
+import (
+	"fmt"
+
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/events"
+	"github.com/bodhiye/tsbs/pkg/query"
+)
+
+// Events produces QuestDB-specific queries for all the events query types.
+type Events struct {
+	*BaseGenerator
+	*events.Core
+}
+
+// ErrorsByService counts the error events of each service over a random
+// window.
+//
+// Queries:
+// errors-by-service
+func (e *Events) ErrorsByService(qi query.Query) {
+	interval := e.Interval.MustRandWindow(events.ErrorsByServiceDuration)
+	sql := fmt.Sprintf(`
+		SELECT service, count() AS errors
+		FROM events
+		WHERE level = 'error'
+		  AND timestamp >= '%s'
+		  AND timestamp < '%s'
+		ORDER BY errors DESC`,
+		interval.StartString(),
+		interval.EndString())
+
+	humanLabel := events.GetErrorsByServiceLabel("QuestDB")
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
+	e.fillInQuery(qi, humanLabel, humanDesc, sql)
+}
+
+// TraceLookup finds the events of a random trace by its ID within a random
+// window.
+//
+// Queries:
+// trace-lookup
+func (e *Events) TraceLookup(qi query.Query) {
+	traceID, interval := e.GetRandomTrace()
+	sql := fmt.Sprintf(`
+		SELECT timestamp, service, instance, level, status_code, latency_ms, message
+		FROM events
+		WHERE trace_id = '%s'
+		  AND timestamp >= '%s'
+		  AND timestamp < '%s'
+		ORDER BY timestamp`,
+		traceID,
+		interval.StartString(),
+		interval.EndString())
+
+	humanLabel := events.GetTraceLookupLabel("QuestDB")
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, traceID)
+	e.fillInQuery(qi, humanLabel, humanDesc, sql)
+}
+
+// MessageSearch finds the last events whose message contains a random
+// phrase within a random window.
+//
+// Queries:
+// message-search
+func (e *Events) MessageSearch(qi query.Query) {
+	interval := e.Interval.MustRandWindow(events.MessageSearchDuration)
+	term := e.GetRandomSearchTerm()
+	sql := fmt.Sprintf(`
+		SELECT timestamp, service, level, message
+		FROM events
+		WHERE message LIKE '%%%s%%'
+		  AND timestamp >= '%s'
+		  AND timestamp < '%s'
+		ORDER BY timestamp DESC
+		LIMIT %d`,
+		term,
+		interval.StartString(),
+		interval.EndString(),
+		events.MessageSearchLimit)
+
+	humanLabel := events.GetMessageSearchLabel("QuestDB")
+	humanDesc := fmt.Sprintf("%s: '%s' %s", humanLabel, term, interval.StartString())
+	e.fillInQuery(qi, humanLabel, humanDesc, sql)
+}
@@ -0,0 +1,92 @@
+package questdb
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/bodhiye/tsbs/pkg/query"
+)
+
+func TestEventsErrorsByService(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "QuestDB error counts by service, random 1h0m0s",
+			expectedHumanDesc:  "QuestDB error counts by service, random 1h0m0s: 1970-01-01T20:16:22Z",
+			expectedQuery: "SELECT service, count() AS errors FROM events " +
+				"WHERE level = 'error' AND timestamp >= '1970-01-01T20:16:22Z' AND timestamp < '1970-01-01T21:16:22Z' ORDER BY errors DESC",
+		},
+	}
+
+	testFunc := func(e *Events, c IoTTestCase) query.Query {
+		q := e.GenerateEmptyQuery()
+		e.ErrorsByService(q)
+		return q
+	}
+
+	runEventsTestCases(t, testFunc, cases)
+}
+
+func TestEventsTraceLookup(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "QuestDB trace lookup by ID, random 1h0m0s",
+			expectedHumanDesc:  "QuestDB trace lookup by ID, random 1h0m0s: f71c4506bd41afc594bff8439bf91eac",
+			expectedQuery: "SELECT timestamp, service, instance, level, status_code, latency_ms, message FROM events " +
+				"WHERE trace_id = 'f71c4506bd41afc594bff8439bf91eac' AND timestamp >= '1970-01-01T20:16:22Z' AND timestamp < '1970-01-01T21:16:22Z' ORDER BY timestamp",
+		},
+	}
+
+	testFunc := func(e *Events, c IoTTestCase) query.Query {
+		q := e.GenerateEmptyQuery()
+		e.TraceLookup(q)
+		return q
+	}
+
+	runEventsTestCases(t, testFunc, cases)
+}
+
+func TestEventsMessageSearch(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "QuestDB message substring search, random 1h0m0s",
+			expectedHumanDesc:  "QuestDB message substring search, random 1h0m0s: 'slow request' 1970-01-01T20:16:22Z",
+			expectedQuery: "SELECT timestamp, service, level, message FROM events " +
+				"WHERE message LIKE '%slow request%' AND timestamp >= '1970-01-01T20:16:22Z' AND timestamp < '1970-01-01T21:16:22Z' " +
+				"ORDER BY timestamp DESC LIMIT 100",
+		},
+	}
+
+	testFunc := func(e *Events, c IoTTestCase) query.Query {
+		q := e.GenerateEmptyQuery()
+		e.MessageSearch(q)
+		return q
+	}
+
+	runEventsTestCases(t, testFunc, cases)
+}
+
+func runEventsTestCases(t *testing.T, testFunc func(*Events, IoTTestCase) query.Query, cases []IoTTestCase) {
+	s := time.Unix(0, 0).UTC()
+	e := s.Add(24 * time.Hour)
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			rand.Seed(123) // Setting seed for testing purposes.
+			b := BaseGenerator{}
+			eq, err := b.NewEvents(s, e, testScale)
+			if err != nil {
+				t.Fatalf("Error while creating events generator")
+			}
+
+			q := testFunc(eq.(*Events), c)
+			verifyQuery(t, q, c.expectedHumanLabel, c.expectedHumanDesc, c.expectedQuery)
+		})
+	}
+}
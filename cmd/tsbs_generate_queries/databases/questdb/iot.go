@@ -0,0 +1,282 @@
+package questdb
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/iot"
+	"github.com/bodhiye/tsbs/pkg/query"
+)
+
+// IoT produces QuestDB-specific queries for the iot query types. The truck
+// tags are columns of the readings and diagnostics tables, so no joins are
+// needed. QuestDB has no lead/lag window functions, so the
+// avg-daily-driving-session and breakdown-frequency queries are not
+// implemented.
+type IoT struct {
+	*iot.Core
+	*BaseGenerator
+}
+
+// NewIoT makes an IoT object ready to generate Queries.
+func NewIoT(start, end time.Time, scale int, g *BaseGenerator) *IoT {
+	c, err := iot.NewCore(start, end, scale)
+	panicIfErr(err)
+	return &IoT{
+		Core:          c,
+		BaseGenerator: g,
+	}
+}
+
+// LastLocByTruck finds the truck location for nTrucks.
+//
+// Queries:
+// single-last-loc
+func (i *IoT) LastLocByTruck(qi query.Query, nTrucks int) {
+	trucks, err := i.GetRandomTrucks(nTrucks)
+	panicIfErr(err)
+
+	sql := fmt.Sprintf(`
+		SELECT name, driver, longitude, latitude
+		FROM readings
+		WHERE name IN ('%s')
+		LATEST ON timestamp PARTITION BY name`,
+		strings.Join(trucks, "', '"))
+
+	humanLabel := "QuestDB last location by specific truck"
+	humanDesc := fmt.Sprintf("%s: random %4d trucks", humanLabel, nTrucks)
+	i.fillInQuery(qi, humanLabel, humanDesc, sql)
+}
+
+// LastLocPerTruck finds all the truck locations along with truck and driver names.
+//
+// Queries:
+// last-loc
+func (i *IoT) LastLocPerTruck(qi query.Query) {
+	sql := fmt.Sprintf(`
+		SELECT name, driver, longitude, latitude
+		FROM readings
+		WHERE fleet = '%s'
+		  AND name IS NOT NULL
+		LATEST ON timestamp PARTITION BY name`,
+		i.GetRandomFleet())
+
+	humanLabel := "QuestDB last location per truck"
+	humanDesc := humanLabel
+	i.fillInQuery(qi, humanLabel, humanDesc, sql)
+}
+
+// TrucksWithLowFuel finds all trucks with low fuel (less than 10%).
+//
+// Queries:
+// low-fuel
+func (i *IoT) TrucksWithLowFuel(qi query.Query) {
+	sql := fmt.Sprintf(`
+		SELECT name, driver, fuel_state
+		FROM (
+			SELECT name, driver, fuel_state
+			FROM diagnostics
+			WHERE fleet = '%s'
+			  AND name IS NOT NULL
+			LATEST ON timestamp PARTITION BY name
+		)
+		WHERE fuel_state < 0.1`,
+		i.GetRandomFleet())
+
+	humanLabel := "QuestDB trucks with low fuel"
+	humanDesc := fmt.Sprintf("%s: under 10 percent", humanLabel)
+	i.fillInQuery(qi, humanLabel, humanDesc, sql)
+}
+
+// TrucksWithHighLoad finds all trucks that have load over 90%.
+//
+// Queries:
+// high-load
+func (i *IoT) TrucksWithHighLoad(qi query.Query) {
+	sql := fmt.Sprintf(`
+		SELECT name, driver, current_load, load_capacity
+		FROM (
+			SELECT name, driver, current_load, load_capacity
+			FROM diagnostics
+			WHERE fleet = '%s'
+			  AND name IS NOT NULL
+			LATEST ON timestamp PARTITION BY name
+		)
+		WHERE current_load / load_capacity > 0.9`,
+		i.GetRandomFleet())
+
+	humanLabel := "QuestDB trucks with high load"
+	humanDesc := fmt.Sprintf("%s: over 90 percent", humanLabel)
+	i.fillInQuery(qi, humanLabel, humanDesc, sql)
+}
+
+// StationaryTrucks finds all trucks that have low average velocity in a time window.
+//
+// Queries:
+// stationary-trucks
+func (i *IoT) StationaryTrucks(qi query.Query) {
+	interval := i.Interval.MustRandWindow(iot.StationaryDuration)
+	sql := fmt.Sprintf(`
+		SELECT name, driver
+		FROM (
+			SELECT name, driver, avg(velocity) AS mean_velocity
+			FROM readings
+			WHERE fleet = '%s'
+			  AND name IS NOT NULL
+			  AND timestamp >= '%s'
+			  AND timestamp < '%s'
+		)
+		WHERE mean_velocity < 1`,
+		i.GetRandomFleet(),
+		interval.StartString(),
+		interval.EndString())
+
+	humanLabel := "QuestDB stationary trucks"
+	humanDesc := fmt.Sprintf("%s: with low avg velocity in last 10 minutes", humanLabel)
+	i.fillInQuery(qi, humanLabel, humanDesc, sql)
+}
+
+// TrucksWithLongDrivingSessions finds all trucks that have not stopped at least 20 mins in the last 4 hours.
+//
+// Queries:
+// long-driving-sessions
+func (i *IoT) TrucksWithLongDrivingSessions(qi query.Query) {
+	interval := i.Interval.MustRandWindow(iot.LongDrivingSessionDuration)
+	// Calculate number of 10 min intervals that is the max driving duration for the session if we rest 5 mins per hour.
+	sql := i.drivingSessionsSQL(interval.StartString(), interval.EndString(), tenMinutePeriods(5, iot.LongDrivingSessionDuration))
+
+	humanLabel := "QuestDB trucks with longer driving sessions"
+	humanDesc := fmt.Sprintf("%s: stopped less than 20 mins in 4 hour period", humanLabel)
+	i.fillInQuery(qi, humanLabel, humanDesc, sql)
+}
+
+// TrucksWithLongDailySessions finds all trucks that have driven more than 10 hours in the last 24 hours.
+//
+// Queries:
+// long-daily-sessions
+func (i *IoT) TrucksWithLongDailySessions(qi query.Query) {
+	interval := i.Interval.MustRandWindow(iot.DailyDrivingDuration)
+	// Calculate number of 10 min intervals that is the max driving duration for the session if we rest 35 mins per hour.
+	sql := i.drivingSessionsSQL(interval.StartString(), interval.EndString(), tenMinutePeriods(35, iot.DailyDrivingDuration))
+
+	humanLabel := "QuestDB trucks with longer daily sessions"
+	humanDesc := fmt.Sprintf("%s: drove more than 10 hours in the last 24 hours", humanLabel)
+	i.fillInQuery(qi, humanLabel, humanDesc, sql)
+}
+
+// drivingSessionsSQL selects the trucks of a random fleet that were driving
+// in more than periods ten minute periods between start and end.
+func (i *IoT) drivingSessionsSQL(start, end string, periods int) string {
+	return fmt.Sprintf(`
+		SELECT name, driver
+		FROM (
+			SELECT name, driver, count() AS ten_minutes
+			FROM (
+				SELECT timestamp, name, driver, avg(velocity) AS mean_velocity
+				FROM readings
+				WHERE fleet = '%s'
+				  AND name IS NOT NULL
+				  AND timestamp >= '%s'
+				  AND timestamp < '%s'
+				SAMPLE BY 10m
+			)
+			WHERE mean_velocity > 1
+		)
+		WHERE ten_minutes > %d`,
+		i.GetRandomFleet(),
+		start,
+		end,
+		periods)
+}
+
+// AvgVsProjectedFuelConsumption calculates average and projected fuel consumption per fleet.
+//
+// Queries:
+// avg-vs-projected-fuel-consumption
+func (i *IoT) AvgVsProjectedFuelConsumption(qi query.Query) {
+	sql := `
+		SELECT fleet,
+			avg(fuel_consumption) AS avg_fuel_consumption,
+			avg(nominal_fuel_consumption) AS projected_fuel_consumption
+		FROM readings
+		WHERE velocity > 1
+		  AND fleet IS NOT NULL
+		  AND nominal_fuel_consumption IS NOT NULL
+		  AND name IS NOT NULL`
+
+	humanLabel := "QuestDB average vs projected fuel consumption per fleet"
+	humanDesc := humanLabel
+	i.fillInQuery(qi, humanLabel, humanDesc, sql)
+}
+
+// AvgDailyDrivingDuration finds the average driving duration per driver.
+//
+// Queries:
+// avg-daily-driving-duration
+func (i *IoT) AvgDailyDrivingDuration(qi query.Query) {
+	sql := `
+		SELECT fleet, name, driver, avg(hours) AS avg_daily_hours
+		FROM (
+			SELECT timestamp_floor('d', timestamp) AS day, fleet, name, driver, count() / 6 AS hours
+			FROM (
+				SELECT timestamp, fleet, name, driver, avg(velocity) AS mean_velocity
+				FROM readings
+				WHERE name IS NOT NULL
+				SAMPLE BY 10m
+			)
+			WHERE mean_velocity > 1
+		)`
+
+	humanLabel := "QuestDB average driver driving duration per day"
+	humanDesc := humanLabel
+	i.fillInQuery(qi, humanLabel, humanDesc, sql)
+}
+
+// AvgLoad finds the average load per truck model per fleet.
+//
+// Queries:
+// avg-load
+func (i *IoT) AvgLoad(qi query.Query) {
+	sql := `
+		SELECT fleet, model, load_capacity, avg(avg_load / load_capacity) AS avg_load_percentage
+		FROM (
+			SELECT name, fleet, model, load_capacity, avg(current_load) AS avg_load
+			FROM diagnostics
+			WHERE name IS NOT NULL
+		)`
+
+	humanLabel := "QuestDB average load per truck model per fleet"
+	humanDesc := humanLabel
+	i.fillInQuery(qi, humanLabel, humanDesc, sql)
+}
+
+// DailyTruckActivity returns the number of hours trucks has been active (not out-of-commission) per day per fleet per model.
+//
+// Queries:
+// daily-activity
+func (i *IoT) DailyTruckActivity(qi query.Query) {
+	sql := `
+		SELECT timestamp_floor('d', timestamp) AS day, fleet, model, count() / 144.0 AS daily_activity
+		FROM (
+			SELECT timestamp, name, fleet, model, avg(status) AS mean_status
+			FROM diagnostics
+			WHERE name IS NOT NULL
+			SAMPLE BY 10m
+		)
+		WHERE mean_status < 1
+		ORDER BY day`
+
+	humanLabel := "QuestDB daily truck activity per fleet per model"
+	humanDesc := humanLabel
+	i.fillInQuery(qi, humanLabel, humanDesc, sql)
+}
+
+// tenMinutePeriods calculates the number of 10 minute periods that can fit in
+// the time duration if we subtract the minutes specified by minutesPerHour value.
+// E.g.: 4 hours - 5 minutes per hour = 3 hours and 40 minutes = 22 ten minute periods
+func tenMinutePeriods(minutesPerHour float64, duration time.Duration) int {
+	durationMinutes := duration.Minutes()
+	leftover := minutesPerHour * duration.Hours()
+	return int((durationMinutes - leftover) / 10)
+}
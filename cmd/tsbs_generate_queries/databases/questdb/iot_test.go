@@ -0,0 +1,379 @@
+package questdb
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/bodhiye/tsbs/pkg/query"
+)
+
+const (
+	testScale = 10
+)
+
+type IoTTestCase struct {
+	desc               string
+	input              int
+	fail               bool
+	failMsg            string
+	expectedHumanLabel string
+	expectedHumanDesc  string
+	expectedQuery      string
+}
+
+func TestLastLocByTruck(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc:    "zero trucks",
+			input:   0,
+			fail:    true,
+			failMsg: "number of trucks cannot be < 1; got 0",
+		},
+		{
+			desc:    "more trucks than scale",
+			input:   2 * testScale,
+			fail:    true,
+			failMsg: "number of trucks (20) larger than total trucks. See --scale (10)",
+		},
+		{
+			desc:  "one truck",
+			input: 1,
+
+			expectedHumanLabel: "QuestDB last location by specific truck",
+			expectedHumanDesc:  "QuestDB last location by specific truck: random    1 trucks",
+			expectedQuery:      "SELECT name, driver, longitude, latitude FROM readings WHERE name IN ('truck_5') LATEST ON timestamp PARTITION BY name",
+		},
+		{
+			desc:  "three trucks",
+			input: 3,
+
+			expectedHumanLabel: "QuestDB last location by specific truck",
+			expectedHumanDesc:  "QuestDB last location by specific truck: random    3 trucks",
+			expectedQuery:      "SELECT name, driver, longitude, latitude FROM readings WHERE name IN ('truck_9', 'truck_3', 'truck_5') LATEST ON timestamp PARTITION BY name",
+		},
+	}
+
+	testFunc := func(i *IoT, c IoTTestCase) query.Query {
+		q := i.GenerateEmptyQuery()
+		i.LastLocByTruck(q, c.input)
+		return q
+	}
+
+	start := time.Unix(0, 0)
+	end := start.Add(time.Hour)
+
+	runIoTTestCases(t, testFunc, start, end, cases)
+}
+
+func TestLastLocPerTruck(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "QuestDB last location per truck",
+			expectedHumanDesc:  "QuestDB last location per truck",
+			expectedQuery:      "SELECT name, driver, longitude, latitude FROM readings WHERE fleet = 'South' AND name IS NOT NULL LATEST ON timestamp PARTITION BY name",
+		},
+	}
+
+	testFunc := func(i *IoT, c IoTTestCase) query.Query {
+		q := i.GenerateEmptyQuery()
+		i.LastLocPerTruck(q)
+		return q
+	}
+
+	start := time.Unix(0, 0)
+	end := start.Add(time.Hour)
+
+	runIoTTestCases(t, testFunc, start, end, cases)
+}
+
+func TestTrucksWithLowFuel(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "QuestDB trucks with low fuel",
+			expectedHumanDesc:  "QuestDB trucks with low fuel: under 10 percent",
+			expectedQuery:      "SELECT name, driver, fuel_state FROM ( SELECT name, driver, fuel_state FROM diagnostics WHERE fleet = 'South' AND name IS NOT NULL LATEST ON timestamp PARTITION BY name ) WHERE fuel_state < 0.1",
+		},
+	}
+
+	testFunc := func(i *IoT, c IoTTestCase) query.Query {
+		q := i.GenerateEmptyQuery()
+		i.TrucksWithLowFuel(q)
+		return q
+	}
+
+	start := time.Unix(0, 0)
+	end := start.Add(time.Hour)
+
+	runIoTTestCases(t, testFunc, start, end, cases)
+}
+
+func TestTrucksWithHighLoad(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "QuestDB trucks with high load",
+			expectedHumanDesc:  "QuestDB trucks with high load: over 90 percent",
+			expectedQuery:      "SELECT name, driver, current_load, load_capacity FROM ( SELECT name, driver, current_load, load_capacity FROM diagnostics WHERE fleet = 'South' AND name IS NOT NULL LATEST ON timestamp PARTITION BY name ) WHERE current_load / load_capacity > 0.9",
+		},
+	}
+
+	testFunc := func(i *IoT, c IoTTestCase) query.Query {
+		q := i.GenerateEmptyQuery()
+		i.TrucksWithHighLoad(q)
+		return q
+	}
+
+	start := time.Unix(0, 0)
+	end := start.Add(time.Hour)
+
+	runIoTTestCases(t, testFunc, start, end, cases)
+}
+
+func TestStationaryTrucks(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "QuestDB stationary trucks",
+			expectedHumanDesc:  "QuestDB stationary trucks: with low avg velocity in last 10 minutes",
+			expectedQuery:      "SELECT name, driver FROM ( SELECT name, driver, avg(velocity) AS mean_velocity FROM readings WHERE fleet = 'West' AND name IS NOT NULL AND timestamp >= '1970-01-01T00:36:22Z' AND timestamp < '1970-01-01T00:46:22Z' ) WHERE mean_velocity < 1",
+		},
+	}
+
+	testFunc := func(i *IoT, c IoTTestCase) query.Query {
+		q := i.GenerateEmptyQuery()
+		i.StationaryTrucks(q)
+		return q
+	}
+
+	start := time.Unix(0, 0)
+	end := start.Add(time.Hour)
+
+	runIoTTestCases(t, testFunc, start, end, cases)
+}
+
+func TestTrucksWithLongDrivingSessions(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "QuestDB trucks with longer driving sessions",
+			expectedHumanDesc:  "QuestDB trucks with longer driving sessions: stopped less than 20 mins in 4 hour period",
+			expectedQuery:      "SELECT name, driver FROM ( SELECT name, driver, count() AS ten_minutes FROM ( SELECT timestamp, name, driver, avg(velocity) AS mean_velocity FROM readings WHERE fleet = 'West' AND name IS NOT NULL AND timestamp >= '1970-01-01T00:16:22Z' AND timestamp < '1970-01-01T04:16:22Z' SAMPLE BY 10m ) WHERE mean_velocity > 1 ) WHERE ten_minutes > 22",
+		},
+	}
+
+	testFunc := func(i *IoT, c IoTTestCase) query.Query {
+		q := i.GenerateEmptyQuery()
+		i.TrucksWithLongDrivingSessions(q)
+		return q
+	}
+
+	start := time.Unix(0, 0)
+	end := start.Add(6 * time.Hour)
+
+	runIoTTestCases(t, testFunc, start, end, cases)
+}
+
+func TestTrucksWithLongDailySessions(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "QuestDB trucks with longer daily sessions",
+			expectedHumanDesc:  "QuestDB trucks with longer daily sessions: drove more than 10 hours in the last 24 hours",
+			expectedQuery:      "SELECT name, driver FROM ( SELECT name, driver, count() AS ten_minutes FROM ( SELECT timestamp, name, driver, avg(velocity) AS mean_velocity FROM readings WHERE fleet = 'West' AND name IS NOT NULL AND timestamp >= '1970-01-01T00:16:22Z' AND timestamp < '1970-01-02T00:16:22Z' SAMPLE BY 10m ) WHERE mean_velocity > 1 ) WHERE ten_minutes > 60",
+		},
+	}
+
+	testFunc := func(i *IoT, c IoTTestCase) query.Query {
+		q := i.GenerateEmptyQuery()
+		i.TrucksWithLongDailySessions(q)
+		return q
+	}
+
+	start := time.Unix(0, 0)
+	end := start.Add(25 * time.Hour)
+
+	runIoTTestCases(t, testFunc, start, end, cases)
+}
+
+func TestAvgVsProjectedFuelConsumption(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "QuestDB average vs projected fuel consumption per fleet",
+			expectedHumanDesc:  "QuestDB average vs projected fuel consumption per fleet",
+			expectedQuery:      "SELECT fleet, avg(fuel_consumption) AS avg_fuel_consumption, avg(nominal_fuel_consumption) AS projected_fuel_consumption FROM readings WHERE velocity > 1 AND fleet IS NOT NULL AND nominal_fuel_consumption IS NOT NULL AND name IS NOT NULL",
+		},
+	}
+
+	testFunc := func(i *IoT, c IoTTestCase) query.Query {
+		q := i.GenerateEmptyQuery()
+		i.AvgVsProjectedFuelConsumption(q)
+		return q
+	}
+
+	start := time.Unix(0, 0)
+	end := start.Add(25 * time.Hour)
+
+	runIoTTestCases(t, testFunc, start, end, cases)
+}
+
+func TestAvgDailyDrivingDuration(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "QuestDB average driver driving duration per day",
+			expectedHumanDesc:  "QuestDB average driver driving duration per day",
+			expectedQuery:      "SELECT fleet, name, driver, avg(hours) AS avg_daily_hours FROM ( SELECT timestamp_floor('d', timestamp) AS day, fleet, name, driver, count() / 6 AS hours FROM ( SELECT timestamp, fleet, name, driver, avg(velocity) AS mean_velocity FROM readings WHERE name IS NOT NULL SAMPLE BY 10m ) WHERE mean_velocity > 1 )",
+		},
+	}
+
+	testFunc := func(i *IoT, c IoTTestCase) query.Query {
+		q := i.GenerateEmptyQuery()
+		i.AvgDailyDrivingDuration(q)
+		return q
+	}
+
+	start := time.Unix(0, 0)
+	end := start.Add(25 * time.Hour)
+
+	runIoTTestCases(t, testFunc, start, end, cases)
+}
+
+func TestAvgLoad(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "QuestDB average load per truck model per fleet",
+			expectedHumanDesc:  "QuestDB average load per truck model per fleet",
+			expectedQuery:      "SELECT fleet, model, load_capacity, avg(avg_load / load_capacity) AS avg_load_percentage FROM ( SELECT name, fleet, model, load_capacity, avg(current_load) AS avg_load FROM diagnostics WHERE name IS NOT NULL )",
+		},
+	}
+
+	testFunc := func(i *IoT, c IoTTestCase) query.Query {
+		q := i.GenerateEmptyQuery()
+		i.AvgLoad(q)
+		return q
+	}
+
+	start := time.Unix(0, 0)
+	end := start.Add(25 * time.Hour)
+
+	runIoTTestCases(t, testFunc, start, end, cases)
+}
+
+func TestDailyTruckActivity(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "QuestDB daily truck activity per fleet per model",
+			expectedHumanDesc:  "QuestDB daily truck activity per fleet per model",
+			expectedQuery:      "SELECT timestamp_floor('d', timestamp) AS day, fleet, model, count() / 144.0 AS daily_activity FROM ( SELECT timestamp, name, fleet, model, avg(status) AS mean_status FROM diagnostics WHERE name IS NOT NULL SAMPLE BY 10m ) WHERE mean_status < 1 ORDER BY day",
+		},
+	}
+
+	testFunc := func(i *IoT, c IoTTestCase) query.Query {
+		q := i.GenerateEmptyQuery()
+		i.DailyTruckActivity(q)
+		return q
+	}
+
+	start := time.Unix(0, 0)
+	end := start.Add(25 * time.Hour)
+
+	runIoTTestCases(t, testFunc, start, end, cases)
+}
+
+func TestTenMinutePeriods(t *testing.T) {
+	cases := []struct {
+		minutesPerHour float64
+		duration       time.Duration
+		result         int
+	}{
+		{
+			minutesPerHour: 5.0,
+			duration:       4 * time.Hour,
+			result:         22,
+		},
+		{
+			minutesPerHour: 10.0,
+			duration:       24 * time.Hour,
+			result:         120,
+		},
+		{
+			minutesPerHour: 0.0,
+			duration:       24 * time.Hour,
+			result:         144,
+		},
+		{
+			minutesPerHour: 1.0,
+			duration:       0 * time.Minute,
+			result:         0,
+		},
+		{
+			minutesPerHour: 0.0,
+			duration:       0 * time.Minute,
+			result:         0,
+		},
+		{
+			minutesPerHour: 1.0,
+			duration:       30 * time.Minute,
+			result:         2,
+		},
+	}
+
+	for _, c := range cases {
+		if got := tenMinutePeriods(c.minutesPerHour, c.duration); got != c.result {
+			t.Errorf("incorrect result for %.2f minutes per hour, duration %s: got %d want %d", c.minutesPerHour, c.duration.String(), got, c.result)
+		}
+	}
+
+}
+
+func runIoTTestCases(t *testing.T, testFunc func(*IoT, IoTTestCase) query.Query, s time.Time, e time.Time, cases []IoTTestCase) {
+	rand.Seed(123) // Setting seed for testing purposes.
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			b := BaseGenerator{}
+			dq, err := b.NewIoT(s, e, testScale)
+			if err != nil {
+				t.Fatalf("Error while creating iot generator")
+			}
+			i := dq.(*IoT)
+
+			if c.fail {
+				func() {
+					defer func() {
+						r := recover()
+						if r == nil {
+							t.Fatalf("did not panic when should")
+						}
+
+						if r != c.failMsg {
+							t.Fatalf("incorrect fail message: got %s, want %s", r, c.failMsg)
+						}
+					}()
+
+					testFunc(i, c)
+				}()
+			} else {
+				q := testFunc(i, c)
+
+				verifyQuery(t, q, c.expectedHumanLabel, c.expectedHumanDesc, c.expectedQuery)
+			}
+		})
+	}
+}
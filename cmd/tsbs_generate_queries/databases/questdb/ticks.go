@@ -0,0 +1,91 @@
+package questdb
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/ticks"
+	"github.com/bodhiye/tsbs/pkg/query"
+)
+
+// Ticks produces QuestDB-specific queries for all the ticks query types.
+type Ticks struct {
+	*BaseGenerator
+	*ticks.Core
+}
+
+// OHLCV computes the open, high, low and close prices and the volume of
+// the trades of a random symbol per minute over a random window.
+//
+// Queries:
+// ohlcv
+func (t *Ticks) OHLCV(qi query.Query) {
+	interval := t.Interval.MustRandWindow(ticks.OHLCVDuration)
+	symbols, err := t.GetRandomSymbols(1)
+	panicIfErr(err)
+
+	sql := fmt.Sprintf(`
+		SELECT timestamp AS minute,
+			first(price) AS open, max(price) AS high, min(price) AS low, last(price) AS close,
+			sum(size) AS volume
+		FROM trades
+		WHERE symbol = '%s'
+		  AND timestamp >= '%s'
+		  AND timestamp < '%s'
+		SAMPLE BY 1m`,
+		symbols[0],
+		interval.StartString(),
+		interval.EndString())
+
+	humanLabel := ticks.GetOHLCVLabel("QuestDB")
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
+	t.fillInQuery(qi, humanLabel, humanDesc, sql)
+}
+
+// VWAP computes the volume-weighted average price of nSymbols random
+// symbols over a random window.
+//
+// Queries:
+// vwap-1
+// vwap-10
+func (t *Ticks) VWAP(qi query.Query, nSymbols int) {
+	interval := t.Interval.MustRandWindow(ticks.VWAPDuration)
+	symbols, err := t.GetRandomSymbols(nSymbols)
+	panicIfErr(err)
+
+	sql := fmt.Sprintf(`
+		SELECT symbol, sum(price * size) / sum(size) AS vwap, sum(size) AS volume
+		FROM trades
+		WHERE symbol IN ('%s')
+		  AND timestamp >= '%s'
+		  AND timestamp < '%s'
+		ORDER BY symbol`,
+		strings.Join(symbols, "', '"),
+		interval.StartString(),
+		interval.EndString())
+
+	humanLabel := ticks.GetVWAPLabel("QuestDB", nSymbols)
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
+	t.fillInQuery(qi, humanLabel, humanDesc, sql)
+}
+
+// LastQuote finds the last quote of every symbol as of a random time,
+// looking back LastQuoteLookback.
+//
+// Queries:
+// last-quote
+func (t *Ticks) LastQuote(qi query.Query) {
+	interval := t.Interval.MustRandWindow(ticks.LastQuoteLookback)
+	sql := fmt.Sprintf(`
+		SELECT symbol, timestamp, bid_price, bid_size, ask_price, ask_size
+		FROM quotes
+		WHERE timestamp > '%s'
+		  AND timestamp <= '%s'
+		LATEST ON timestamp PARTITION BY symbol`,
+		interval.StartString(),
+		interval.EndString())
+
+	humanLabel := ticks.GetLastQuoteLabel("QuestDB")
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.EndString())
+	t.fillInQuery(qi, humanLabel, humanDesc, sql)
+}
@@ -0,0 +1,122 @@
+package questdb
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/bodhiye/tsbs/pkg/query"
+)
+
+func TestTicksOHLCV(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "QuestDB OHLCV bars, random symbol, random 1h0m0s by 1m",
+			expectedHumanDesc:  "QuestDB OHLCV bars, random symbol, random 1h0m0s by 1m: 1970-01-01T20:16:22Z",
+			expectedQuery: "SELECT timestamp AS minute, first(price) AS open, max(price) AS high, min(price) AS low, last(price) AS close, sum(size) AS volume " +
+				"FROM trades WHERE symbol = 'AAAJ' AND timestamp >= '1970-01-01T20:16:22Z' AND timestamp < '1970-01-01T21:16:22Z' SAMPLE BY 1m",
+		},
+	}
+
+	testFunc := func(tk *Ticks, c IoTTestCase) query.Query {
+		q := tk.GenerateEmptyQuery()
+		tk.OHLCV(q)
+		return q
+	}
+
+	runTicksTestCases(t, testFunc, cases)
+}
+
+func TestTicksVWAP(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc:    "zero symbols",
+			input:   0,
+			fail:    true,
+			failMsg: "number of symbols cannot be < 1; got 0",
+		},
+		{
+			desc:    "more symbols than scale",
+			input:   2 * testScale,
+			fail:    true,
+			failMsg: "number of symbols (20) larger than total symbols. See --scale (10)",
+		},
+		{
+			desc:  "two symbols",
+			input: 2,
+
+			expectedHumanLabel: "QuestDB VWAP, random    2 symbols, random 1h0m0s",
+			expectedHumanDesc:  "QuestDB VWAP, random    2 symbols, random 1h0m0s: 1970-01-01T20:16:22Z",
+			expectedQuery: "SELECT symbol, sum(price * size) / sum(size) AS vwap, sum(size) AS volume " +
+				"FROM trades WHERE symbol IN ('AAAJ', 'AAAD') AND timestamp >= '1970-01-01T20:16:22Z' AND timestamp < '1970-01-01T21:16:22Z' ORDER BY symbol",
+		},
+	}
+
+	testFunc := func(tk *Ticks, c IoTTestCase) query.Query {
+		q := tk.GenerateEmptyQuery()
+		tk.VWAP(q, c.input)
+		return q
+	}
+
+	runTicksTestCases(t, testFunc, cases)
+}
+
+func TestTicksLastQuote(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "QuestDB last quote per symbol, random time",
+			expectedHumanDesc:  "QuestDB last quote per symbol, random time: 1970-01-01T21:16:22Z",
+			expectedQuery: "SELECT symbol, timestamp, bid_price, bid_size, ask_price, ask_size FROM quotes " +
+				"WHERE timestamp > '1970-01-01T21:15:22Z' AND timestamp <= '1970-01-01T21:16:22Z' LATEST ON timestamp PARTITION BY symbol",
+		},
+	}
+
+	testFunc := func(tk *Ticks, c IoTTestCase) query.Query {
+		q := tk.GenerateEmptyQuery()
+		tk.LastQuote(q)
+		return q
+	}
+
+	runTicksTestCases(t, testFunc, cases)
+}
+
+func runTicksTestCases(t *testing.T, testFunc func(*Ticks, IoTTestCase) query.Query, cases []IoTTestCase) {
+	s := time.Unix(0, 0).UTC()
+	e := s.Add(24 * time.Hour)
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			rand.Seed(123) // Setting seed for testing purposes.
+			b := BaseGenerator{}
+			tq, err := b.NewTicks(s, e, testScale)
+			if err != nil {
+				t.Fatalf("Error while creating ticks generator")
+			}
+			tk := tq.(*Ticks)
+
+			if c.fail {
+				func() {
+					defer func() {
+						r := recover()
+						if r == nil {
+							t.Fatalf("did not panic when should")
+						}
+
+						if r != c.failMsg {
+							t.Fatalf("incorrect fail message: got %s, want %s", r, c.failMsg)
+						}
+					}()
+
+					testFunc(tk, c)
+				}()
+			} else {
+				q := testFunc(tk, c)
+				verifyQuery(t, q, c.expectedHumanLabel, c.expectedHumanDesc, c.expectedQuery)
+			}
+		})
+	}
+}
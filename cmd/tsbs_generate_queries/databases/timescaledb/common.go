@@ -1,10 +1,14 @@
 package timescaledb
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/devops"
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/events"
 	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/iot"
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/k8s"
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/ticks"
 	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/utils"
 	"github.com/bodhiye/tsbs/pkg/query"
 )
@@ -32,6 +36,24 @@ func (g *BaseGenerator) fillInQuery(qi query.Query, humanLabel, humanDesc, table
 	q.SqlQuery = []byte(sql)
 }
 
+// getTimeBucket returns the expression bucketing time into buckets of
+// seconds, with time_bucket if enabled.
+func (g *BaseGenerator) getTimeBucket(seconds int) string {
+	if g.UseTimeBucket {
+		return fmt.Sprintf(timeBucketFmt, seconds)
+	}
+	return fmt.Sprintf(nonTimeBucketFmt, seconds, seconds)
+}
+
+// tagColumn returns the expression selecting a tag from the tags table
+// joined as t, from the JSON tagset if enabled.
+func (g *BaseGenerator) tagColumn(column string) string {
+	if g.UseJSON {
+		return fmt.Sprintf("t.tagset->>'%s'", column)
+	}
+	return "t." + column
+}
+
 // NewDevops creates a new devops use case query generator.
 func (g *BaseGenerator) NewDevops(start, end time.Time, scale int) (utils.QueryGenerator, error) {
 	core, err := devops.NewCore(start, end, scale)
@@ -63,3 +85,51 @@ func (g *BaseGenerator) NewIoT(start, end time.Time, scale int) (utils.QueryGene
 
 	return iot, nil
 }
+
+// NewK8s creates a new k8s use case query generator.
+func (g *BaseGenerator) NewK8s(start, end time.Time, scale int) (utils.QueryGenerator, error) {
+	core, err := k8s.NewCore(start, end, scale)
+
+	if err != nil {
+		return nil, err
+	}
+
+	k8s := &K8s{
+		BaseGenerator: g,
+		Core:          core,
+	}
+
+	return k8s, nil
+}
+
+// NewTicks creates a new ticks use case query generator.
+func (g *BaseGenerator) NewTicks(start, end time.Time, scale int) (utils.QueryGenerator, error) {
+	core, err := ticks.NewCore(start, end, scale)
+
+	if err != nil {
+		return nil, err
+	}
+
+	ticks := &Ticks{
+		BaseGenerator: g,
+		Core:          core,
+	}
+
+	return ticks, nil
+}
+
+// NewEvents creates a new events use case query generator.
+func (g *BaseGenerator) NewEvents(start, end time.Time, scale int) (utils.QueryGenerator, error) {
+	core, err := events.NewCore(start, end, scale)
+
+	if err != nil {
+		return nil, err
+	}
+
+	events := &Events{
+		BaseGenerator: g,
+		Core:          core,
+	}
+
+	return events, nil
+}
@@ -61,13 +61,6 @@ func (d *Devops) getHostWhereString(nHosts int) string {
 	return d.getHostWhereWithHostnames(hostnames)
 }
 
-func (d *Devops) getTimeBucket(seconds int) string {
-	if d.UseTimeBucket {
-		return fmt.Sprintf(timeBucketFmt, seconds)
-	}
-	return fmt.Sprintf(nonTimeBucketFmt, seconds, seconds)
-}
-
 func (d *Devops) getSelectClausesAggMetrics(agg string, metrics []string) []string {
 	selectClauses := make([]string, len(metrics))
 	for i, m := range metrics {
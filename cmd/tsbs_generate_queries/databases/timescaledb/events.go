@@ -0,0 +1,86 @@
+package timescaledb
+
+import (
+	"fmt"
+
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/events"
+	"github.com/bodhiye/tsbs/pkg/query"
+)
+
+// Events produces TimescaleDB-specific queries for all the events query
+// types.
+type Events struct {
+	*events.Core
+	*BaseGenerator
+}
+
+// ErrorsByService counts the error events of each service over a random
+// window, e.g. in pseudo-SQL:
+//
+// SELECT service, count(*)
+// FROM events
+// WHERE level = 'error' AND time >= '$START' AND time < '$END'
+// GROUP BY service ORDER BY count DESC
+func (e *Events) ErrorsByService(qi query.Query) {
+	interval := e.Interval.MustRandWindow(events.ErrorsByServiceDuration)
+	sql := fmt.Sprintf(`SELECT %s AS service, count(*) AS errors
+		FROM events e INNER JOIN tags t ON e.tags_id = t.id
+		WHERE e.level = 'error' AND time >= '%s' AND time < '%s'
+		GROUP BY service ORDER BY errors DESC`,
+		e.tagColumn("service"),
+		interval.Start().Format(goTimeFmt),
+		interval.End().Format(goTimeFmt))
+
+	humanLabel := events.GetErrorsByServiceLabel("TimescaleDB")
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
+	e.fillInQuery(qi, humanLabel, humanDesc, events.TableName, sql)
+}
+
+// TraceLookup finds the events of a random trace by its ID within a random
+// window, e.g. in pseudo-SQL:
+//
+// SELECT time, service, instance, level, status_code, latency_ms, message
+// FROM events
+// WHERE trace_id = '$TRACE_ID' AND time >= '$START' AND time < '$END'
+// ORDER BY time
+func (e *Events) TraceLookup(qi query.Query) {
+	traceID, interval := e.GetRandomTrace()
+	sql := fmt.Sprintf(`SELECT e.time, %s AS service, %s AS instance, e.level, e.status_code, e.latency_ms, e.message
+		FROM events e INNER JOIN tags t ON e.tags_id = t.id
+		WHERE e.trace_id = '%s' AND time >= '%s' AND time < '%s'
+		ORDER BY e.time`,
+		e.tagColumn("service"),
+		e.tagColumn("instance"),
+		traceID,
+		interval.Start().Format(goTimeFmt),
+		interval.End().Format(goTimeFmt))
+
+	humanLabel := events.GetTraceLookupLabel("TimescaleDB")
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, traceID)
+	e.fillInQuery(qi, humanLabel, humanDesc, events.TableName, sql)
+}
+
+// MessageSearch finds the last events whose message contains a random
+// phrase within a random window, e.g. in pseudo-SQL:
+//
+// SELECT time, service, level, message
+// FROM events
+// WHERE message LIKE '%$TERM%' AND time >= '$START' AND time < '$END'
+// ORDER BY time DESC LIMIT $LIMIT
+func (e *Events) MessageSearch(qi query.Query) {
+	interval := e.Interval.MustRandWindow(events.MessageSearchDuration)
+	term := e.GetRandomSearchTerm()
+	sql := fmt.Sprintf(`SELECT e.time, %s AS service, e.level, e.message
+		FROM events e INNER JOIN tags t ON e.tags_id = t.id
+		WHERE e.message LIKE '%%%s%%' AND time >= '%s' AND time < '%s'
+		ORDER BY e.time DESC LIMIT %d`,
+		e.tagColumn("service"),
+		term,
+		interval.Start().Format(goTimeFmt),
+		interval.End().Format(goTimeFmt),
+		events.MessageSearchLimit)
+
+	humanLabel := events.GetMessageSearchLabel("TimescaleDB")
+	humanDesc := fmt.Sprintf("%s: '%s' %s", humanLabel, term, interval.StartString())
+	e.fillInQuery(qi, humanLabel, humanDesc, events.TableName, sql)
+}
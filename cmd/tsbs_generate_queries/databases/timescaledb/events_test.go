@@ -0,0 +1,114 @@
+package timescaledb
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/events"
+	"github.com/bodhiye/tsbs/pkg/query"
+)
+
+func TestEventsErrorsByService(t *testing.T) {
+	cases := []testCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "TimescaleDB error counts by service, random 1h0m0s",
+			expectedHumanDesc:  "TimescaleDB error counts by service, random 1h0m0s: 1970-01-01T20:16:22Z",
+			expectedHypertable: events.TableName,
+			expectedSQLQuery: `SELECT t.service AS service, count(*) AS errors
+		FROM events e INNER JOIN tags t ON e.tags_id = t.id
+		WHERE e.level = 'error' AND time >= '1970-01-01 20:16:22.646325 +0000' AND time < '1970-01-01 21:16:22.646325 +0000'
+		GROUP BY service ORDER BY errors DESC`,
+		},
+		{
+			desc:    "use json",
+			useJSON: true,
+
+			expectedHumanLabel: "TimescaleDB error counts by service, random 1h0m0s",
+			expectedHumanDesc:  "TimescaleDB error counts by service, random 1h0m0s: 1970-01-01T20:16:22Z",
+			expectedHypertable: events.TableName,
+			expectedSQLQuery: `SELECT t.tagset->>'service' AS service, count(*) AS errors
+		FROM events e INNER JOIN tags t ON e.tags_id = t.id
+		WHERE e.level = 'error' AND time >= '1970-01-01 20:16:22.646325 +0000' AND time < '1970-01-01 21:16:22.646325 +0000'
+		GROUP BY service ORDER BY errors DESC`,
+		},
+	}
+
+	testFunc := func(e *Events, c testCase) query.Query {
+		q := e.GenerateEmptyQuery()
+		e.ErrorsByService(q)
+		return q
+	}
+
+	runEventsTestCases(t, testFunc, cases)
+}
+
+func TestEventsTraceLookup(t *testing.T) {
+	cases := []testCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "TimescaleDB trace lookup by ID, random 1h0m0s",
+			expectedHumanDesc:  "TimescaleDB trace lookup by ID, random 1h0m0s: f71c4506bd41afc594bff8439bf91eac",
+			expectedHypertable: events.TableName,
+			expectedSQLQuery: `SELECT e.time, t.service AS service, t.instance AS instance, e.level, e.status_code, e.latency_ms, e.message
+		FROM events e INNER JOIN tags t ON e.tags_id = t.id
+		WHERE e.trace_id = 'f71c4506bd41afc594bff8439bf91eac' AND time >= '1970-01-01 20:16:22.646325 +0000' AND time < '1970-01-01 21:16:22.646325 +0000'
+		ORDER BY e.time`,
+		},
+	}
+
+	testFunc := func(e *Events, c testCase) query.Query {
+		q := e.GenerateEmptyQuery()
+		e.TraceLookup(q)
+		return q
+	}
+
+	runEventsTestCases(t, testFunc, cases)
+}
+
+func TestEventsMessageSearch(t *testing.T) {
+	cases := []testCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "TimescaleDB message substring search, random 1h0m0s",
+			expectedHumanDesc:  "TimescaleDB message substring search, random 1h0m0s: 'slow request' 1970-01-01T20:16:22Z",
+			expectedHypertable: events.TableName,
+			expectedSQLQuery: `SELECT e.time, t.service AS service, e.level, e.message
+		FROM events e INNER JOIN tags t ON e.tags_id = t.id
+		WHERE e.message LIKE '%slow request%' AND time >= '1970-01-01 20:16:22.646325 +0000' AND time < '1970-01-01 21:16:22.646325 +0000'
+		ORDER BY e.time DESC LIMIT 100`,
+		},
+	}
+
+	testFunc := func(e *Events, c testCase) query.Query {
+		q := e.GenerateEmptyQuery()
+		e.MessageSearch(q)
+		return q
+	}
+
+	runEventsTestCases(t, testFunc, cases)
+}
+
+func runEventsTestCases(t *testing.T, testFunc func(*Events, testCase) query.Query, cases []testCase) {
+	s := time.Unix(0, 0).UTC()
+	e := s.Add(24 * time.Hour)
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			rand.Seed(123) // Setting seed for testing purposes.
+			b := BaseGenerator{}
+			b.UseJSON = c.useJSON
+			eq, err := b.NewEvents(s, e, testScale)
+			if err != nil {
+				t.Fatalf("Error while creating events generator")
+			}
+
+			q := testFunc(eq.(*Events), c)
+			verifyQuery(t, q, c.expectedHumanLabel, c.expectedHumanDesc, c.expectedHypertable, c.expectedSQLQuery)
+		})
+	}
+}
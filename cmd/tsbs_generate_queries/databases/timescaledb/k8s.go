@@ -0,0 +1,74 @@
+package timescaledb
+
+import (
+	"fmt"
+
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/k8s"
+	"github.com/bodhiye/tsbs/pkg/query"
+)
+
+// K8s produces TimescaleDB-specific queries for all the k8s query types.
+type K8s struct {
+	*k8s.Core
+	*BaseGenerator
+}
+
+// NamespaceCPU sums the cpu usage of the containers of every namespace per
+// minute over a random window, e.g. in pseudo-SQL:
+//
+// SELECT minute, namespace, sum(usage_millicores)
+// FROM (SELECT minute, namespace, avg(usage_millicores) FROM container_cpu
+// WHERE time >= '$START' AND time < '$END'
+// GROUP BY minute, namespace, container series)
+// GROUP BY minute, namespace ORDER BY minute, namespace
+func (k *K8s) NamespaceCPU(qi query.Query) {
+	interval := k.Interval.MustRandWindow(k8s.NamespaceCPUDuration)
+	sql := fmt.Sprintf(`SELECT minute, namespace, sum(usage_millicores) AS usage_millicores
+		FROM (
+			SELECT %s AS minute, %s AS namespace, avg(c.usage_millicores) AS usage_millicores
+			FROM container_cpu c INNER JOIN tags t ON c.tags_id = t.id
+			WHERE time >= '%s' AND time < '%s'
+			GROUP BY minute, namespace, c.tags_id) series
+		GROUP BY minute, namespace
+		ORDER BY minute, namespace`,
+		k.getTimeBucket(oneMinute),
+		k.tagColumn("namespace"),
+		interval.Start().Format(goTimeFmt),
+		interval.End().Format(goTimeFmt))
+
+	humanLabel := k8s.GetNamespaceCPULabel("TimescaleDB")
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
+	k.fillInQuery(qi, humanLabel, humanDesc, k8s.CPUTableName, sql)
+}
+
+// TopPodsCPU finds the limit pods of a random namespace using the most cpu,
+// summed over their containers, in a random window, e.g. in pseudo-SQL:
+//
+// SELECT pod, sum(usage_millicores) AS usage
+// FROM (SELECT pod, avg(usage_millicores) FROM container_cpu
+// WHERE namespace = '$NAMESPACE' AND time >= '$START' AND time < '$END'
+// GROUP BY pod, container series)
+// GROUP BY pod ORDER BY usage DESC LIMIT $LIMIT
+func (k *K8s) TopPodsCPU(qi query.Query, limit int) {
+	interval := k.Interval.MustRandWindow(k8s.TopPodsCPUDuration)
+	namespace := k.GetRandomNamespace()
+	sql := fmt.Sprintf(`SELECT pod, sum(usage_millicores) AS usage_millicores
+		FROM (
+			SELECT %s AS pod, avg(c.usage_millicores) AS usage_millicores
+			FROM container_cpu c INNER JOIN tags t ON c.tags_id = t.id
+			WHERE %s = '%s' AND time >= '%s' AND time < '%s'
+			GROUP BY pod, c.tags_id) containers
+		GROUP BY pod
+		ORDER BY usage_millicores DESC
+		LIMIT %d`,
+		k.tagColumn("pod"),
+		k.tagColumn("namespace"),
+		namespace,
+		interval.Start().Format(goTimeFmt),
+		interval.End().Format(goTimeFmt),
+		limit)
+
+	humanLabel := k8s.GetTopPodsCPULabel("TimescaleDB", limit)
+	humanDesc := fmt.Sprintf("%s: %s (%s)", humanLabel, interval.StartString(), namespace)
+	k.fillInQuery(qi, humanLabel, humanDesc, k8s.CPUTableName, sql)
+}
@@ -0,0 +1,125 @@
+package timescaledb
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/k8s"
+	"github.com/bodhiye/tsbs/pkg/query"
+)
+
+func TestK8sNamespaceCPU(t *testing.T) {
+	cases := []testCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "TimescaleDB cpu per namespace, random 1h0m0s by 1m",
+			expectedHumanDesc:  "TimescaleDB cpu per namespace, random 1h0m0s by 1m: 1970-01-01T20:16:22Z",
+			expectedHypertable: k8s.CPUTableName,
+			expectedSQLQuery: `SELECT minute, namespace, sum(usage_millicores) AS usage_millicores
+		FROM (
+			SELECT to_timestamp(((extract(epoch from time)::int)/60)*60) AS minute, t.namespace AS namespace, avg(c.usage_millicores) AS usage_millicores
+			FROM container_cpu c INNER JOIN tags t ON c.tags_id = t.id
+			WHERE time >= '1970-01-01 20:16:22.646325 +0000' AND time < '1970-01-01 21:16:22.646325 +0000'
+			GROUP BY minute, namespace, c.tags_id) series
+		GROUP BY minute, namespace
+		ORDER BY minute, namespace`,
+		},
+		{
+			desc:    "use json",
+			useJSON: true,
+
+			expectedHumanLabel: "TimescaleDB cpu per namespace, random 1h0m0s by 1m",
+			expectedHumanDesc:  "TimescaleDB cpu per namespace, random 1h0m0s by 1m: 1970-01-01T20:16:22Z",
+			expectedHypertable: k8s.CPUTableName,
+			expectedSQLQuery: `SELECT minute, namespace, sum(usage_millicores) AS usage_millicores
+		FROM (
+			SELECT to_timestamp(((extract(epoch from time)::int)/60)*60) AS minute, t.tagset->>'namespace' AS namespace, avg(c.usage_millicores) AS usage_millicores
+			FROM container_cpu c INNER JOIN tags t ON c.tags_id = t.id
+			WHERE time >= '1970-01-01 20:16:22.646325 +0000' AND time < '1970-01-01 21:16:22.646325 +0000'
+			GROUP BY minute, namespace, c.tags_id) series
+		GROUP BY minute, namespace
+		ORDER BY minute, namespace`,
+		},
+	}
+
+	testFunc := func(k *K8s, c testCase) query.Query {
+		q := k.GenerateEmptyQuery()
+		k.NamespaceCPU(q)
+		return q
+	}
+
+	start := time.Unix(0, 0).UTC()
+	end := start.Add(24 * time.Hour)
+
+	runK8sTestCases(t, testFunc, start, end, cases)
+}
+
+func TestK8sTopPodsCPU(t *testing.T) {
+	cases := []testCase{
+		{
+			desc:  "default",
+			input: k8s.TopPodsCPULimit,
+
+			expectedHumanLabel: "TimescaleDB top 10 pods by cpu, random namespace, random 1h0m0s",
+			expectedHumanDesc:  "TimescaleDB top 10 pods by cpu, random namespace, random 1h0m0s: 1970-01-01T20:16:22Z (kube-system)",
+			expectedHypertable: k8s.CPUTableName,
+			expectedSQLQuery: `SELECT pod, sum(usage_millicores) AS usage_millicores
+		FROM (
+			SELECT t.pod AS pod, avg(c.usage_millicores) AS usage_millicores
+			FROM container_cpu c INNER JOIN tags t ON c.tags_id = t.id
+			WHERE t.namespace = 'kube-system' AND time >= '1970-01-01 20:16:22.646325 +0000' AND time < '1970-01-01 21:16:22.646325 +0000'
+			GROUP BY pod, c.tags_id) containers
+		GROUP BY pod
+		ORDER BY usage_millicores DESC
+		LIMIT 10`,
+		},
+		{
+			desc:    "use json",
+			input:   5,
+			useJSON: true,
+
+			expectedHumanLabel: "TimescaleDB top 5 pods by cpu, random namespace, random 1h0m0s",
+			expectedHumanDesc:  "TimescaleDB top 5 pods by cpu, random namespace, random 1h0m0s: 1970-01-01T20:16:22Z (kube-system)",
+			expectedHypertable: k8s.CPUTableName,
+			expectedSQLQuery: `SELECT pod, sum(usage_millicores) AS usage_millicores
+		FROM (
+			SELECT t.tagset->>'pod' AS pod, avg(c.usage_millicores) AS usage_millicores
+			FROM container_cpu c INNER JOIN tags t ON c.tags_id = t.id
+			WHERE t.tagset->>'namespace' = 'kube-system' AND time >= '1970-01-01 20:16:22.646325 +0000' AND time < '1970-01-01 21:16:22.646325 +0000'
+			GROUP BY pod, c.tags_id) containers
+		GROUP BY pod
+		ORDER BY usage_millicores DESC
+		LIMIT 5`,
+		},
+	}
+
+	testFunc := func(k *K8s, c testCase) query.Query {
+		q := k.GenerateEmptyQuery()
+		k.TopPodsCPU(q, c.input)
+		return q
+	}
+
+	start := time.Unix(0, 0).UTC()
+	end := start.Add(24 * time.Hour)
+
+	runK8sTestCases(t, testFunc, start, end, cases)
+}
+
+func runK8sTestCases(t *testing.T, testFunc func(*K8s, testCase) query.Query, s time.Time, e time.Time, cases []testCase) {
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			rand.Seed(123) // Setting seed for testing purposes.
+			b := BaseGenerator{}
+			b.UseJSON = c.useJSON
+			kq, err := b.NewK8s(s, e, testScale)
+			if err != nil {
+				t.Fatalf("Error while creating k8s generator")
+			}
+
+			q := testFunc(kq.(*K8s), c)
+			verifyQuery(t, q, c.expectedHumanLabel, c.expectedHumanDesc, c.expectedHypertable, c.expectedSQLQuery)
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package timescaledb
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/ticks"
+	"github.com/bodhiye/tsbs/pkg/query"
+)
+
+// Ticks produces TimescaleDB-specific queries for all the ticks query types.
+type Ticks struct {
+	*ticks.Core
+	*BaseGenerator
+}
+
+// getSymbolWhereWithSymbols creates a WHERE SQL statement for the symbols,
+// without 'WHERE' itself.
+func (t *Ticks) getSymbolWhereWithSymbols(symbols []string) string {
+	var symbolClauses []string
+	if t.UseJSON {
+		for _, s := range symbols {
+			symbolClauses = append(symbolClauses, fmt.Sprintf("tagset @> '{\"symbol\": \"%s\"}'", s))
+		}
+		return fmt.Sprintf("tags_id IN (SELECT id FROM tags WHERE %s)", strings.Join(symbolClauses, " OR "))
+	}
+	for _, s := range symbols {
+		symbolClauses = append(symbolClauses, fmt.Sprintf("'%s'", s))
+	}
+	return fmt.Sprintf("tags_id IN (SELECT id FROM tags WHERE symbol IN (%s))", strings.Join(symbolClauses, ","))
+}
+
+// getSymbolWhereString gets random symbols and creates a WHERE SQL
+// statement for them.
+func (t *Ticks) getSymbolWhereString(nSymbols int) string {
+	symbols, err := t.GetRandomSymbols(nSymbols)
+	panicIfErr(err)
+	return t.getSymbolWhereWithSymbols(symbols)
+}
+
+// OHLCV computes the open, high, low and close prices and the volume of
+// the trades of a random symbol per minute over a random window, e.g. in
+// pseudo-SQL:
+//
+// SELECT minute, first(price), max(price), min(price), last(price), sum(size)
+// FROM trades
+// WHERE symbol = '$SYMBOL' AND time >= '$START' AND time < '$END'
+// GROUP BY minute ORDER BY minute
+func (t *Ticks) OHLCV(qi query.Query) {
+	interval := t.Interval.MustRandWindow(ticks.OHLCVDuration)
+	sql := fmt.Sprintf(`SELECT %s AS minute,
+		first(price, time) AS open, max(price) AS high, min(price) AS low, last(price, time) AS close,
+		sum(size) AS volume
+		FROM trades
+		WHERE %s AND time >= '%s' AND time < '%s'
+		GROUP BY minute ORDER BY minute ASC`,
+		t.getTimeBucket(oneMinute),
+		t.getSymbolWhereString(1),
+		interval.Start().Format(goTimeFmt),
+		interval.End().Format(goTimeFmt))
+
+	humanLabel := ticks.GetOHLCVLabel("TimescaleDB")
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
+	t.fillInQuery(qi, humanLabel, humanDesc, ticks.TradesTableName, sql)
+}
+
+// VWAP computes the volume-weighted average price of nSymbols random
+// symbols over a random window, e.g. in pseudo-SQL:
+//
+// SELECT symbol, sum(price * size) / sum(size), sum(size)
+// FROM trades
+// WHERE symbol IN ('$SYMBOL_1',...,'$SYMBOL_N') AND time >= '$START' AND time < '$END'
+// GROUP BY symbol ORDER BY symbol
+func (t *Ticks) VWAP(qi query.Query, nSymbols int) {
+	interval := t.Interval.MustRandWindow(ticks.VWAPDuration)
+	sql := fmt.Sprintf(`SELECT %s AS symbol, sum(r.price * r.size) / sum(r.size) AS vwap, sum(r.size) AS volume
+		FROM trades r INNER JOIN tags t ON r.tags_id = t.id
+		WHERE %s AND time >= '%s' AND time < '%s'
+		GROUP BY symbol ORDER BY symbol`,
+		t.tagColumn("symbol"),
+		t.getSymbolWhereString(nSymbols),
+		interval.Start().Format(goTimeFmt),
+		interval.End().Format(goTimeFmt))
+
+	humanLabel := ticks.GetVWAPLabel("TimescaleDB", nSymbols)
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
+	t.fillInQuery(qi, humanLabel, humanDesc, ticks.TradesTableName, sql)
+}
+
+// LastQuote finds the last quote of every symbol as of a random time,
+// looking back LastQuoteLookback, e.g. in pseudo-SQL:
+//
+// SELECT DISTINCT ON (symbol) symbol, time, bid_price, bid_size, ask_price, ask_size
+// FROM quotes
+// WHERE time > '$TIME' - $LOOKBACK AND time <= '$TIME'
+// ORDER BY symbol, time DESC
+func (t *Ticks) LastQuote(qi query.Query) {
+	interval := t.Interval.MustRandWindow(ticks.LastQuoteLookback)
+	sql := fmt.Sprintf(`SELECT %s AS symbol, q.*
+		FROM tags t INNER JOIN LATERAL
+			(SELECT time, bid_price, bid_size, ask_price, ask_size
+			FROM quotes q
+			WHERE q.tags_id = t.id AND time > '%s' AND time <= '%s'
+			ORDER BY time DESC LIMIT 1) q ON true
+		ORDER BY symbol`,
+		t.tagColumn("symbol"),
+		interval.Start().Format(goTimeFmt),
+		interval.End().Format(goTimeFmt))
+
+	humanLabel := ticks.GetLastQuoteLabel("TimescaleDB")
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.EndString())
+	t.fillInQuery(qi, humanLabel, humanDesc, ticks.QuotesTableName, sql)
+}
@@ -0,0 +1,135 @@
+package timescaledb
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/ticks"
+	"github.com/bodhiye/tsbs/pkg/query"
+)
+
+func TestTicksOHLCV(t *testing.T) {
+	cases := []testCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "TimescaleDB OHLCV bars, random symbol, random 1h0m0s by 1m",
+			expectedHumanDesc:  "TimescaleDB OHLCV bars, random symbol, random 1h0m0s by 1m: 1970-01-01T20:16:22Z",
+			expectedHypertable: ticks.TradesTableName,
+			expectedSQLQuery: `SELECT to_timestamp(((extract(epoch from time)::int)/60)*60) AS minute,
+		first(price, time) AS open, max(price) AS high, min(price) AS low, last(price, time) AS close,
+		sum(size) AS volume
+		FROM trades
+		WHERE tags_id IN (SELECT id FROM tags WHERE symbol IN ('AAAJ')) AND time >= '1970-01-01 20:16:22.646325 +0000' AND time < '1970-01-01 21:16:22.646325 +0000'
+		GROUP BY minute ORDER BY minute ASC`,
+		},
+		{
+			desc:    "use json",
+			useJSON: true,
+
+			expectedHumanLabel: "TimescaleDB OHLCV bars, random symbol, random 1h0m0s by 1m",
+			expectedHumanDesc:  "TimescaleDB OHLCV bars, random symbol, random 1h0m0s by 1m: 1970-01-01T20:16:22Z",
+			expectedHypertable: ticks.TradesTableName,
+			expectedSQLQuery: `SELECT to_timestamp(((extract(epoch from time)::int)/60)*60) AS minute,
+		first(price, time) AS open, max(price) AS high, min(price) AS low, last(price, time) AS close,
+		sum(size) AS volume
+		FROM trades
+		WHERE tags_id IN (SELECT id FROM tags WHERE tagset @> '{"symbol": "AAAJ"}') AND time >= '1970-01-01 20:16:22.646325 +0000' AND time < '1970-01-01 21:16:22.646325 +0000'
+		GROUP BY minute ORDER BY minute ASC`,
+		},
+	}
+
+	testFunc := func(tk *Ticks, c testCase) query.Query {
+		q := tk.GenerateEmptyQuery()
+		tk.OHLCV(q)
+		return q
+	}
+
+	runTicksTestCases(t, testFunc, cases)
+}
+
+func TestTicksVWAP(t *testing.T) {
+	cases := []testCase{
+		{
+			desc:  "two symbols",
+			input: 2,
+
+			expectedHumanLabel: "TimescaleDB VWAP, random    2 symbols, random 1h0m0s",
+			expectedHumanDesc:  "TimescaleDB VWAP, random    2 symbols, random 1h0m0s: 1970-01-01T20:16:22Z",
+			expectedHypertable: ticks.TradesTableName,
+			expectedSQLQuery: `SELECT t.symbol AS symbol, sum(r.price * r.size) / sum(r.size) AS vwap, sum(r.size) AS volume
+		FROM trades r INNER JOIN tags t ON r.tags_id = t.id
+		WHERE tags_id IN (SELECT id FROM tags WHERE symbol IN ('AAAJ','AAAD')) AND time >= '1970-01-01 20:16:22.646325 +0000' AND time < '1970-01-01 21:16:22.646325 +0000'
+		GROUP BY symbol ORDER BY symbol`,
+		},
+		{
+			desc:    "two symbols use json",
+			input:   2,
+			useJSON: true,
+
+			expectedHumanLabel: "TimescaleDB VWAP, random    2 symbols, random 1h0m0s",
+			expectedHumanDesc:  "TimescaleDB VWAP, random    2 symbols, random 1h0m0s: 1970-01-01T20:16:22Z",
+			expectedHypertable: ticks.TradesTableName,
+			expectedSQLQuery: `SELECT t.tagset->>'symbol' AS symbol, sum(r.price * r.size) / sum(r.size) AS vwap, sum(r.size) AS volume
+		FROM trades r INNER JOIN tags t ON r.tags_id = t.id
+		WHERE tags_id IN (SELECT id FROM tags WHERE tagset @> '{"symbol": "AAAJ"}' OR tagset @> '{"symbol": "AAAD"}') AND time >= '1970-01-01 20:16:22.646325 +0000' AND time < '1970-01-01 21:16:22.646325 +0000'
+		GROUP BY symbol ORDER BY symbol`,
+		},
+	}
+
+	testFunc := func(tk *Ticks, c testCase) query.Query {
+		q := tk.GenerateEmptyQuery()
+		tk.VWAP(q, c.input)
+		return q
+	}
+
+	runTicksTestCases(t, testFunc, cases)
+}
+
+func TestTicksLastQuote(t *testing.T) {
+	cases := []testCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "TimescaleDB last quote per symbol, random time",
+			expectedHumanDesc:  "TimescaleDB last quote per symbol, random time: 1970-01-01T21:16:22Z",
+			expectedHypertable: ticks.QuotesTableName,
+			expectedSQLQuery: `SELECT t.symbol AS symbol, q.*
+		FROM tags t INNER JOIN LATERAL
+			(SELECT time, bid_price, bid_size, ask_price, ask_size
+			FROM quotes q
+			WHERE q.tags_id = t.id AND time > '1970-01-01 21:15:22.646325 +0000' AND time <= '1970-01-01 21:16:22.646325 +0000'
+			ORDER BY time DESC LIMIT 1) q ON true
+		ORDER BY symbol`,
+		},
+	}
+
+	testFunc := func(tk *Ticks, c testCase) query.Query {
+		q := tk.GenerateEmptyQuery()
+		tk.LastQuote(q)
+		return q
+	}
+
+	runTicksTestCases(t, testFunc, cases)
+}
+
+func runTicksTestCases(t *testing.T, testFunc func(*Ticks, testCase) query.Query, cases []testCase) {
+	s := time.Unix(0, 0).UTC()
+	e := s.Add(24 * time.Hour)
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			rand.Seed(123) // Setting seed for testing purposes.
+			b := BaseGenerator{}
+			b.UseJSON = c.useJSON
+			tq, err := b.NewTicks(s, e, testScale)
+			if err != nil {
+				t.Fatalf("Error while creating ticks generator")
+			}
+
+			q := testFunc(tq.(*Ticks), c)
+			verifyQuery(t, q, c.expectedHumanLabel, c.expectedHumanDesc, c.expectedHypertable, c.expectedSQLQuery)
+		})
+	}
+}
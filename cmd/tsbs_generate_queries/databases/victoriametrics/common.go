@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/devops"
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/iot"
 	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/utils"
 	"github.com/bodhiye/tsbs/pkg/query"
 	iutils "github.com/bodhiye/tsbs/tools/utils"
@@ -31,6 +32,18 @@ func (g *BaseGenerator) NewDevops(start, end time.Time, scale int) (utils.QueryG
 	}, nil
 }
 
+// NewIoT creates a new iot use case query generator.
+func (g *BaseGenerator) NewIoT(start, end time.Time, scale int) (utils.QueryGenerator, error) {
+	core, err := iot.NewCore(start, end, scale)
+	if err != nil {
+		return nil, err
+	}
+	return &IoT{
+		BaseGenerator: g,
+		Core:          core,
+	}, nil
+}
+
 type queryInfo struct {
 	// prometheus query
 	query string
@@ -0,0 +1,19 @@
+package victoriametrics
+
+import (
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/custom"
+	"github.com/bodhiye/tsbs/pkg/query"
+)
+
+// CustomQuery fills in a query rendered from a custom query template, which
+// is PromQL evaluated over the time window of the template with a step of
+// 60s.
+func (g *BaseGenerator) CustomQuery(qi query.Query, cq *custom.Query) {
+	qq := &queryInfo{
+		query:    cq.Query,
+		label:    cq.HumanLabel("VictoriaMetrics"),
+		interval: cq.Interval,
+		step:     "60",
+	}
+	g.fillInQuery(qi, qq)
+}
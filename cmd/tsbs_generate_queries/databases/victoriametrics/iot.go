@@ -0,0 +1,268 @@
+package victoriametrics
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/common"
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/iot"
+	"github.com/bodhiye/tsbs/pkg/query"
+	iutils "github.com/bodhiye/tsbs/tools/utils"
+)
+
+// lastValueLookback is how far back the last-value queries look for a point.
+const lastValueLookback = "1h"
+
+// IoT produces PromQL queries for the iot query types. The string truck tags
+// are labels, while the numeric ones (e.g. load_capacity) are series of
+// their own, like diagnostics_load_capacity.
+type IoT struct {
+	*BaseGenerator
+	*iot.Core
+}
+
+// NewIoT makes an IoT object ready to generate Queries.
+func NewIoT(start, end time.Time, scale int, g *BaseGenerator) *IoT {
+	c, err := iot.NewCore(start, end, scale)
+	if err != nil {
+		panic(err.Error())
+	}
+	return &IoT{
+		BaseGenerator: g,
+		Core:          c,
+	}
+}
+
+// mustGetRandomTrucks is the form of GetRandomTrucks that cannot error; if it does error,
+// it causes a panic.
+func (i *IoT) mustGetRandomTrucks(nTrucks int) []string {
+	trucks, err := i.GetRandomTrucks(nTrucks)
+	if err != nil {
+		panic(err.Error())
+	}
+	return trucks
+}
+
+// instantAt returns the zero-length interval at t, so that the query is
+// evaluated exactly once.
+func instantAt(t time.Time) *iutils.TimeInterval {
+	ti, err := iutils.NewTimeInterval(t, t)
+	if err != nil {
+		panic(err.Error())
+	}
+	return ti
+}
+
+// promDuration formats d as a PromQL duration in seconds.
+func promDuration(d time.Duration) string {
+	return fmt.Sprintf("%ds", int64(d.Seconds()))
+}
+
+func getTruckClause(trucks []string) string {
+	if len(trucks) == 1 {
+		return fmt.Sprintf("name='%s'", trucks[0])
+	}
+	return fmt.Sprintf("name=~'%s'", strings.Join(trucks, "|"))
+}
+
+func getFleetClause(fleet string) string {
+	return fmt.Sprintf("fleet='%s', name!=''", fleet)
+}
+
+// AvgDailyDrivingSession is not supported: PromQL can't split a series into
+// driving sessions.
+func (i *IoT) AvgDailyDrivingSession(qi query.Query) {
+	common.PanicUnimplementedQuery(i)
+}
+
+// TruckBreakdownFrequency is not supported: PromQL can't count the
+// transitions of a series between two states.
+func (i *IoT) TruckBreakdownFrequency(qi query.Query) {
+	common.PanicUnimplementedQuery(i)
+}
+
+// LastLocByTruck finds the truck location for nTrucks,
+// e.g. in pseudo-PromQL:
+//
+// last_over_time({__name__=~"readings_(latitude|longitude)",name=~"truck1|...|truckN"}[1h])
+func (i *IoT) LastLocByTruck(qq query.Query, nTrucks int) {
+	trucks := i.mustGetRandomTrucks(nTrucks)
+	qi := &queryInfo{
+		query:    fmt.Sprintf("last_over_time({__name__=~'readings_(latitude|longitude)', %s}[%s])", getTruckClause(trucks), lastValueLookback),
+		label:    "VictoriaMetrics last location by specific truck",
+		interval: instantAt(i.Interval.End()),
+		step:     "60",
+	}
+	i.fillInQuery(qq, qi)
+}
+
+// LastLocPerTruck finds all the truck locations of a random fleet,
+// e.g. in pseudo-PromQL:
+//
+// last_over_time({__name__=~"readings_(latitude|longitude)",fleet="fleet"}[1h])
+func (i *IoT) LastLocPerTruck(qq query.Query) {
+	qi := &queryInfo{
+		query:    fmt.Sprintf("last_over_time({__name__=~'readings_(latitude|longitude)', %s}[%s])", getFleetClause(i.GetRandomFleet()), lastValueLookback),
+		label:    "VictoriaMetrics last location per truck",
+		interval: instantAt(i.Interval.End()),
+		step:     "60",
+	}
+	i.fillInQuery(qq, qi)
+}
+
+// TrucksWithLowFuel finds all trucks of a random fleet with low fuel (less than 10%),
+// e.g. in pseudo-PromQL:
+//
+// last_over_time(diagnostics_fuel_state{fleet="fleet"}[1h]) < 0.1
+func (i *IoT) TrucksWithLowFuel(qq query.Query) {
+	qi := &queryInfo{
+		query:    fmt.Sprintf("last_over_time(diagnostics_fuel_state{%s}[%s]) < 0.1", getFleetClause(i.GetRandomFleet()), lastValueLookback),
+		label:    "VictoriaMetrics trucks with low fuel",
+		interval: instantAt(i.Interval.End()),
+		step:     "60",
+	}
+	i.fillInQuery(qq, qi)
+}
+
+// TrucksWithHighLoad finds all trucks of a random fleet that have load over 90%,
+// e.g. in pseudo-PromQL:
+//
+// last_over_time(diagnostics_current_load{fleet="fleet"}[1h])
+// / last_over_time(diagnostics_load_capacity{fleet="fleet"}[1h]) > 0.9
+func (i *IoT) TrucksWithHighLoad(qq query.Query) {
+	fleetClause := getFleetClause(i.GetRandomFleet())
+	qi := &queryInfo{
+		query: fmt.Sprintf("last_over_time(diagnostics_current_load{%[1]s}[%[2]s]) / last_over_time(diagnostics_load_capacity{%[1]s}[%[2]s]) > 0.9",
+			fleetClause, lastValueLookback),
+		label:    "VictoriaMetrics trucks with high load",
+		interval: instantAt(i.Interval.End()),
+		step:     "60",
+	}
+	i.fillInQuery(qq, qi)
+}
+
+// StationaryTrucks finds all trucks of a random fleet that have low average velocity in a time window,
+// e.g. in pseudo-PromQL:
+//
+// avg_over_time(readings_velocity{fleet="fleet"}[10m]) < 1
+func (i *IoT) StationaryTrucks(qq query.Query) {
+	interval := i.Interval.MustRandWindow(iot.StationaryDuration)
+	qi := &queryInfo{
+		query:    fmt.Sprintf("avg_over_time(readings_velocity{%s}[%s]) < 1", getFleetClause(i.GetRandomFleet()), promDuration(iot.StationaryDuration)),
+		label:    "VictoriaMetrics stationary trucks",
+		interval: instantAt(interval.End()),
+		step:     "60",
+	}
+	i.fillInQuery(qq, qi)
+}
+
+// TrucksWithLongDrivingSessions finds all trucks of a random fleet that have not stopped at least 20 mins in the last 4 hours,
+// e.g. in pseudo-PromQL:
+//
+// count_over_time((avg_over_time(readings_velocity{fleet="fleet"}[10m]) > 1)[4h:10m]) > 22
+func (i *IoT) TrucksWithLongDrivingSessions(qq query.Query) {
+	interval := i.Interval.MustRandWindow(iot.LongDrivingSessionDuration)
+	qi := &queryInfo{
+		// Calculate number of 10 min intervals that is the max driving duration for the session if we rest 5 mins per hour.
+		query:    i.drivingSessionsQuery(iot.LongDrivingSessionDuration, tenMinutePeriods(5, iot.LongDrivingSessionDuration)),
+		label:    "VictoriaMetrics trucks with longer driving sessions",
+		interval: instantAt(interval.End()),
+		step:     "60",
+	}
+	i.fillInQuery(qq, qi)
+}
+
+// TrucksWithLongDailySessions finds all trucks of a random fleet that have driven more than 10 hours in the last 24 hours,
+// e.g. in pseudo-PromQL:
+//
+// count_over_time((avg_over_time(readings_velocity{fleet="fleet"}[10m]) > 1)[24h:10m]) > 60
+func (i *IoT) TrucksWithLongDailySessions(qq query.Query) {
+	interval := i.Interval.MustRandWindow(iot.DailyDrivingDuration)
+	qi := &queryInfo{
+		// Calculate number of 10 min intervals that is the max driving duration for the session if we rest 35 mins per hour.
+		query:    i.drivingSessionsQuery(iot.DailyDrivingDuration, tenMinutePeriods(35, iot.DailyDrivingDuration)),
+		label:    "VictoriaMetrics trucks with longer daily sessions",
+		interval: instantAt(interval.End()),
+		step:     "60",
+	}
+	i.fillInQuery(qq, qi)
+}
+
+// drivingSessionsQuery selects the trucks of a random fleet that were driving
+// in more than periods ten minute periods of the last duration.
+func (i *IoT) drivingSessionsQuery(duration time.Duration, periods int) string {
+	return fmt.Sprintf("count_over_time((avg_over_time(readings_velocity{%s}[600s]) > 1)[%s:600s]) > %d",
+		getFleetClause(i.GetRandomFleet()), promDuration(duration), periods)
+}
+
+// AvgVsProjectedFuelConsumption calculates average and projected fuel consumption per fleet,
+// e.g. in pseudo-PromQL:
+//
+// avg(avg_over_time((readings_fuel_consumption and readings_velocity > 1)[all:1m])) by (fleet)
+// or avg(avg_over_time(readings_nominal_fuel_consumption[all])) by (fleet)
+func (i *IoT) AvgVsProjectedFuelConsumption(qq query.Query) {
+	all := promDuration(i.Interval.Duration())
+	qi := &queryInfo{
+		query: fmt.Sprintf("label_set(avg(avg_over_time((readings_fuel_consumption{name!=''} and readings_velocity{name!=''} > 1)[%[1]s:60s])) by (fleet), 'consumption', 'average') "+
+			"or label_set(avg(avg_over_time(readings_nominal_fuel_consumption{name!=''}[%[1]s])) by (fleet), 'consumption', 'projected')", all),
+		label:    "VictoriaMetrics average vs projected fuel consumption per fleet",
+		interval: instantAt(i.Interval.End()),
+		step:     "60",
+	}
+	i.fillInQuery(qq, qi)
+}
+
+// AvgDailyDrivingDuration finds the average driving hours per day per driver,
+// e.g. in pseudo-PromQL:
+//
+// avg_over_time((count_over_time((avg_over_time(readings_velocity[10m]) > 1)[1d:10m]) / 6)[all:1d])
+func (i *IoT) AvgDailyDrivingDuration(qq query.Query) {
+	qi := &queryInfo{
+		query: fmt.Sprintf("avg_over_time((count_over_time((avg_over_time(readings_velocity{name!=''}[600s]) > 1)[86400s:600s]) / 6)[%s:86400s])",
+			promDuration(i.Interval.Duration())),
+		label:    "VictoriaMetrics average driver driving duration per day",
+		interval: instantAt(i.Interval.End()),
+		step:     "60",
+	}
+	i.fillInQuery(qq, qi)
+}
+
+// AvgLoad finds the average load per truck model per fleet,
+// e.g. in pseudo-PromQL:
+//
+// avg(avg_over_time(diagnostics_current_load[all]) / avg_over_time(diagnostics_load_capacity[all])) by (fleet, model)
+func (i *IoT) AvgLoad(qq query.Query) {
+	qi := &queryInfo{
+		query: fmt.Sprintf("avg(avg_over_time(diagnostics_current_load{name!=''}[%[1]s]) / avg_over_time(diagnostics_load_capacity{name!=''}[%[1]s])) by (fleet, model)",
+			promDuration(i.Interval.Duration())),
+		label:    "VictoriaMetrics average load per truck model per fleet",
+		interval: instantAt(i.Interval.End()),
+		step:     "60",
+	}
+	i.fillInQuery(qq, qi)
+}
+
+// DailyTruckActivity returns the share of ten minute periods trucks have been active
+// (not out-of-commission) per day per fleet per model,
+// e.g. in pseudo-PromQL:
+//
+// sum(count_over_time((avg_over_time(diagnostics_status[10m]) < 1)[1d:10m])) by (fleet, model) / 144
+func (i *IoT) DailyTruckActivity(qq query.Query) {
+	qi := &queryInfo{
+		query:    "sum(count_over_time((avg_over_time(diagnostics_status{name!=''}[600s]) < 1)[86400s:600s])) by (fleet, model) / 144",
+		label:    "VictoriaMetrics daily truck activity per fleet per model",
+		interval: i.Interval,
+		step:     "86400",
+	}
+	i.fillInQuery(qq, qi)
+}
+
+// tenMinutePeriods calculates the number of 10 minute periods that can fit in
+// the time duration if we subtract the minutes specified by minutesPerHour value.
+// E.g.: 4 hours - 5 minutes per hour = 3 hours and 40 minutes = 22 ten minute periods
+func tenMinutePeriods(minutesPerHour float64, duration time.Duration) int {
+	durationMinutes := duration.Minutes()
+	leftover := minutesPerHour * duration.Hours()
+	return int((durationMinutes - leftover) / 10)
+}
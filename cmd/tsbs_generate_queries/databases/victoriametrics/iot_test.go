@@ -0,0 +1,451 @@
+package victoriametrics
+
+import (
+	"math/rand"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/bodhiye/tsbs/pkg/query"
+)
+
+const (
+	testScale = 10
+)
+
+type IoTTestCase struct {
+	desc               string
+	input              int
+	fail               bool
+	failMsg            string
+	expectedHumanLabel string
+	expectedHumanDesc  string
+	expectedQuery      string
+	expectedStep       string
+}
+
+func TestLastLocByTruck(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc:    "zero trucks",
+			input:   0,
+			fail:    true,
+			failMsg: "number of trucks cannot be < 1; got 0",
+		},
+		{
+			desc:    "more trucks than scale",
+			input:   2 * testScale,
+			fail:    true,
+			failMsg: "number of trucks (20) larger than total trucks. See --scale (10)",
+		},
+		{
+			desc:  "one truck",
+			input: 1,
+
+			expectedHumanLabel: "VictoriaMetrics last location by specific truck",
+			expectedHumanDesc:  "VictoriaMetrics last location by specific truck: 1970-01-01T01:00:00Z",
+			expectedQuery:      "last_over_time({__name__=~'readings_(latitude|longitude)', name='truck_5'}[1h])",
+			expectedStep:       "60",
+		},
+		{
+			desc:  "three trucks",
+			input: 3,
+
+			expectedHumanLabel: "VictoriaMetrics last location by specific truck",
+			expectedHumanDesc:  "VictoriaMetrics last location by specific truck: 1970-01-01T01:00:00Z",
+			expectedQuery:      "last_over_time({__name__=~'readings_(latitude|longitude)', name=~'truck_9|truck_3|truck_5'}[1h])",
+			expectedStep:       "60",
+		},
+	}
+
+	testFunc := func(i *IoT, c IoTTestCase) query.Query {
+		q := i.GenerateEmptyQuery()
+		i.LastLocByTruck(q, c.input)
+		return q
+	}
+
+	start := time.Unix(0, 0)
+	end := start.Add(time.Hour)
+
+	runIoTTestCases(t, testFunc, start, end, cases)
+}
+
+func TestLastLocPerTruck(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "VictoriaMetrics last location per truck",
+			expectedHumanDesc:  "VictoriaMetrics last location per truck: 1970-01-01T01:00:00Z",
+			expectedQuery:      "last_over_time({__name__=~'readings_(latitude|longitude)', fleet='South', name!=''}[1h])",
+			expectedStep:       "60",
+		},
+	}
+
+	testFunc := func(i *IoT, c IoTTestCase) query.Query {
+		q := i.GenerateEmptyQuery()
+		i.LastLocPerTruck(q)
+		return q
+	}
+
+	start := time.Unix(0, 0)
+	end := start.Add(time.Hour)
+
+	runIoTTestCases(t, testFunc, start, end, cases)
+}
+
+func TestTrucksWithLowFuel(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "VictoriaMetrics trucks with low fuel",
+			expectedHumanDesc:  "VictoriaMetrics trucks with low fuel: 1970-01-01T01:00:00Z",
+			expectedQuery:      "last_over_time(diagnostics_fuel_state{fleet='South', name!=''}[1h]) < 0.1",
+			expectedStep:       "60",
+		},
+	}
+
+	testFunc := func(i *IoT, c IoTTestCase) query.Query {
+		q := i.GenerateEmptyQuery()
+		i.TrucksWithLowFuel(q)
+		return q
+	}
+
+	start := time.Unix(0, 0)
+	end := start.Add(time.Hour)
+
+	runIoTTestCases(t, testFunc, start, end, cases)
+}
+
+func TestTrucksWithHighLoad(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "VictoriaMetrics trucks with high load",
+			expectedHumanDesc:  "VictoriaMetrics trucks with high load: 1970-01-01T01:00:00Z",
+			expectedQuery:      "last_over_time(diagnostics_current_load{fleet='South', name!=''}[1h]) / last_over_time(diagnostics_load_capacity{fleet='South', name!=''}[1h]) > 0.9",
+			expectedStep:       "60",
+		},
+	}
+
+	testFunc := func(i *IoT, c IoTTestCase) query.Query {
+		q := i.GenerateEmptyQuery()
+		i.TrucksWithHighLoad(q)
+		return q
+	}
+
+	start := time.Unix(0, 0)
+	end := start.Add(time.Hour)
+
+	runIoTTestCases(t, testFunc, start, end, cases)
+}
+
+func TestStationaryTrucks(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "VictoriaMetrics stationary trucks",
+			expectedHumanDesc:  "VictoriaMetrics stationary trucks: 1970-01-01T00:46:22Z",
+			expectedQuery:      "avg_over_time(readings_velocity{fleet='West', name!=''}[600s]) < 1",
+			expectedStep:       "60",
+		},
+	}
+
+	testFunc := func(i *IoT, c IoTTestCase) query.Query {
+		q := i.GenerateEmptyQuery()
+		i.StationaryTrucks(q)
+		return q
+	}
+
+	start := time.Unix(0, 0)
+	end := start.Add(time.Hour)
+
+	runIoTTestCases(t, testFunc, start, end, cases)
+}
+
+func TestTrucksWithLongDrivingSessions(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "VictoriaMetrics trucks with longer driving sessions",
+			expectedHumanDesc:  "VictoriaMetrics trucks with longer driving sessions: 1970-01-01T04:16:22Z",
+			expectedQuery:      "count_over_time((avg_over_time(readings_velocity{fleet='West', name!=''}[600s]) > 1)[14400s:600s]) > 22",
+			expectedStep:       "60",
+		},
+	}
+
+	testFunc := func(i *IoT, c IoTTestCase) query.Query {
+		q := i.GenerateEmptyQuery()
+		i.TrucksWithLongDrivingSessions(q)
+		return q
+	}
+
+	start := time.Unix(0, 0)
+	end := start.Add(6 * time.Hour)
+
+	runIoTTestCases(t, testFunc, start, end, cases)
+}
+
+func TestTrucksWithLongDailySessions(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "VictoriaMetrics trucks with longer daily sessions",
+			expectedHumanDesc:  "VictoriaMetrics trucks with longer daily sessions: 1970-01-02T00:16:22Z",
+			expectedQuery:      "count_over_time((avg_over_time(readings_velocity{fleet='West', name!=''}[600s]) > 1)[86400s:600s]) > 60",
+			expectedStep:       "60",
+		},
+	}
+
+	testFunc := func(i *IoT, c IoTTestCase) query.Query {
+		q := i.GenerateEmptyQuery()
+		i.TrucksWithLongDailySessions(q)
+		return q
+	}
+
+	start := time.Unix(0, 0)
+	end := start.Add(25 * time.Hour)
+
+	runIoTTestCases(t, testFunc, start, end, cases)
+}
+
+func TestAvgVsProjectedFuelConsumption(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "VictoriaMetrics average vs projected fuel consumption per fleet",
+			expectedHumanDesc:  "VictoriaMetrics average vs projected fuel consumption per fleet: 1970-01-02T01:00:00Z",
+			expectedQuery:      "label_set(avg(avg_over_time((readings_fuel_consumption{name!=''} and readings_velocity{name!=''} > 1)[90000s:60s])) by (fleet), 'consumption', 'average') or label_set(avg(avg_over_time(readings_nominal_fuel_consumption{name!=''}[90000s])) by (fleet), 'consumption', 'projected')",
+			expectedStep:       "60",
+		},
+	}
+
+	testFunc := func(i *IoT, c IoTTestCase) query.Query {
+		q := i.GenerateEmptyQuery()
+		i.AvgVsProjectedFuelConsumption(q)
+		return q
+	}
+
+	start := time.Unix(0, 0)
+	end := start.Add(25 * time.Hour)
+
+	runIoTTestCases(t, testFunc, start, end, cases)
+}
+
+func TestAvgDailyDrivingDuration(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "VictoriaMetrics average driver driving duration per day",
+			expectedHumanDesc:  "VictoriaMetrics average driver driving duration per day: 1970-01-02T01:00:00Z",
+			expectedQuery:      "avg_over_time((count_over_time((avg_over_time(readings_velocity{name!=''}[600s]) > 1)[86400s:600s]) / 6)[90000s:86400s])",
+			expectedStep:       "60",
+		},
+	}
+
+	testFunc := func(i *IoT, c IoTTestCase) query.Query {
+		q := i.GenerateEmptyQuery()
+		i.AvgDailyDrivingDuration(q)
+		return q
+	}
+
+	start := time.Unix(0, 0)
+	end := start.Add(25 * time.Hour)
+
+	runIoTTestCases(t, testFunc, start, end, cases)
+}
+
+func TestAvgDailyDrivingSession(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc:    "default",
+			fail:    true,
+			failMsg: "database (*victoriametrics.IoT) does not implement query",
+		},
+	}
+
+	testFunc := func(i *IoT, c IoTTestCase) query.Query {
+		q := i.GenerateEmptyQuery()
+		i.AvgDailyDrivingSession(q)
+		return q
+	}
+
+	start := time.Unix(0, 0)
+	end := start.Add(25 * time.Hour)
+
+	runIoTTestCases(t, testFunc, start, end, cases)
+}
+
+func TestAvgLoad(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "VictoriaMetrics average load per truck model per fleet",
+			expectedHumanDesc:  "VictoriaMetrics average load per truck model per fleet: 1970-01-02T01:00:00Z",
+			expectedQuery:      "avg(avg_over_time(diagnostics_current_load{name!=''}[90000s]) / avg_over_time(diagnostics_load_capacity{name!=''}[90000s])) by (fleet, model)",
+			expectedStep:       "60",
+		},
+	}
+
+	testFunc := func(i *IoT, c IoTTestCase) query.Query {
+		q := i.GenerateEmptyQuery()
+		i.AvgLoad(q)
+		return q
+	}
+
+	start := time.Unix(0, 0)
+	end := start.Add(25 * time.Hour)
+
+	runIoTTestCases(t, testFunc, start, end, cases)
+}
+
+func TestDailyTruckActivity(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc: "default",
+
+			expectedHumanLabel: "VictoriaMetrics daily truck activity per fleet per model",
+			expectedHumanDesc:  "VictoriaMetrics daily truck activity per fleet per model: 1970-01-01T00:00:00Z",
+			expectedQuery:      "sum(count_over_time((avg_over_time(diagnostics_status{name!=''}[600s]) < 1)[86400s:600s])) by (fleet, model) / 144",
+			expectedStep:       "86400",
+		},
+	}
+
+	testFunc := func(i *IoT, c IoTTestCase) query.Query {
+		q := i.GenerateEmptyQuery()
+		i.DailyTruckActivity(q)
+		return q
+	}
+
+	start := time.Unix(0, 0)
+	end := start.Add(25 * time.Hour)
+
+	runIoTTestCases(t, testFunc, start, end, cases)
+}
+
+func TestTruckBreakdownFrequency(t *testing.T) {
+	cases := []IoTTestCase{
+		{
+			desc:    "default",
+			fail:    true,
+			failMsg: "database (*victoriametrics.IoT) does not implement query",
+		},
+	}
+
+	testFunc := func(i *IoT, c IoTTestCase) query.Query {
+		q := i.GenerateEmptyQuery()
+		i.TruckBreakdownFrequency(q)
+		return q
+	}
+
+	start := time.Unix(0, 0)
+	end := start.Add(25 * time.Hour)
+
+	runIoTTestCases(t, testFunc, start, end, cases)
+}
+
+func TestTenMinutePeriods(t *testing.T) {
+	cases := []struct {
+		minutesPerHour float64
+		duration       time.Duration
+		result         int
+	}{
+		{
+			minutesPerHour: 5.0,
+			duration:       4 * time.Hour,
+			result:         22,
+		},
+		{
+			minutesPerHour: 10.0,
+			duration:       24 * time.Hour,
+			result:         120,
+		},
+		{
+			minutesPerHour: 0.0,
+			duration:       24 * time.Hour,
+			result:         144,
+		},
+		{
+			minutesPerHour: 1.0,
+			duration:       0 * time.Minute,
+			result:         0,
+		},
+		{
+			minutesPerHour: 0.0,
+			duration:       0 * time.Minute,
+			result:         0,
+		},
+		{
+			minutesPerHour: 1.0,
+			duration:       30 * time.Minute,
+			result:         2,
+		},
+	}
+
+	for _, c := range cases {
+		if got := tenMinutePeriods(c.minutesPerHour, c.duration); got != c.result {
+			t.Errorf("incorrect result for %.2f minutes per hour, duration %s: got %d want %d", c.minutesPerHour, c.duration.String(), got, c.result)
+		}
+	}
+
+}
+
+func runIoTTestCases(t *testing.T, testFunc func(*IoT, IoTTestCase) query.Query, s time.Time, e time.Time, cases []IoTTestCase) {
+	rand.Seed(123) // Setting seed for testing purposes.
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			b := BaseGenerator{}
+			dq, err := b.NewIoT(s, e, testScale)
+			if err != nil {
+				t.Fatalf("Error while creating iot generator")
+			}
+			i := dq.(*IoT)
+
+			if c.fail {
+				func() {
+					defer func() {
+						r := recover()
+						if r == nil {
+							t.Fatalf("did not panic when should")
+						}
+
+						if r != c.failMsg {
+							t.Fatalf("incorrect fail message: got %s, want %s", r, c.failMsg)
+						}
+					}()
+
+					testFunc(i, c)
+				}()
+			} else {
+				q := testFunc(i, c)
+
+				verifyIoTQuery(t, q, c)
+			}
+		})
+	}
+}
+
+func verifyIoTQuery(t *testing.T, qi query.Query, c IoTTestCase) {
+	q := qi.(*query.HTTP)
+	checkEqual(t, "human label", c.expectedHumanLabel, string(q.HumanLabel))
+	checkEqual(t, "human description", c.expectedHumanDesc, string(q.HumanDescription))
+	checkEqual(t, "method", http.MethodGet, string(q.Method))
+
+	u, err := url.Parse(string(q.Path))
+	if err != nil {
+		t.Fatalf("unexpected err while parsing query: %s", err)
+	}
+	vals := u.Query()
+	checkEqual(t, "query", c.expectedQuery, vals.Get("query"))
+	checkEqual(t, "step", c.expectedStep, vals.Get("step"))
+}
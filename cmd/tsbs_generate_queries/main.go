@@ -7,6 +7,7 @@ import (
 	"log"
 	"os"
 
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/custom"
 	"github.com/bodhiye/tsbs/pkg/query/config"
 	"github.com/bodhiye/tsbs/tools/inputs"
 	"github.com/bodhiye/tsbs/tools/utils"
@@ -34,6 +35,7 @@ func init() {
 				fmt.Fprintf(os.Stderr, "  use case: %s, query type: %s\n", uc, qt)
 			}
 		}
+		fmt.Fprintf(os.Stderr, "  use case: any, query type: %s (requires --custom-queries-file)\n", custom.LabelCustom)
 	}
 
 	conf.AddToFlagSet(pflag.CommandLine)
@@ -2,7 +2,6 @@ package common
 
 import (
 	"fmt"
-	"math/rand"
 	"reflect"
 	"time"
 
@@ -21,6 +20,10 @@ type Core struct {
 
 	// Scale is the cardinality of the dataset in terms of devices/hosts
 	Scale int
+
+	// picker picks the random hosts and trucks of the queries, see
+	// SetEntitySelection
+	picker entityPicker
 }
 
 // NewCore returns a new Core for the given time range and cardinality
@@ -30,7 +33,19 @@ func NewCore(start, end time.Time, scale int) (*Core, error) {
 		return nil, err
 	}
 
-	return &Core{Interval: ti, Scale: scale}, nil
+	return &Core{Interval: ti, Scale: scale, picker: uniformPicker{}}, nil
+}
+
+// SetWindowRecencyMean makes the random time windows of the queries biased
+// towards the end of the dataset, see TimeInterval.SetWindowRecencyMean.
+func (c *Core) SetWindowRecencyMean(mean time.Duration) error {
+	return c.Interval.SetWindowRecencyMean(mean)
+}
+
+// GetRandomSubsetPerm is like the GetRandomSubsetPerm function, picking the
+// items as set with SetEntitySelection.
+func (c *Core) GetRandomSubsetPerm(numItems int, totalItems int) ([]int, error) {
+	return getRandomSubsetPerm(c.picker, numItems, totalItems)
 }
 
 // PanicUnimplementedQuery generates a panic for the provided query generator.
@@ -43,7 +58,12 @@ func PanicUnimplementedQuery(dg utils.QueryGenerator) {
 // which used up a lot more memory and slowed down query generation significantly.
 // The subset of the permutation should have no duplicates and thus, can not be longer that original set
 // Ex.: 12, 7, 25 for numItems=3 and totalItems=30 (3 out of 30)
+// The items are picked uniformly.
 func GetRandomSubsetPerm(numItems int, totalItems int) ([]int, error) {
+	return getRandomSubsetPerm(uniformPicker{}, numItems, totalItems)
+}
+
+func getRandomSubsetPerm(picker entityPicker, numItems int, totalItems int) ([]int, error) {
 	if numItems > totalItems {
 		// Cannot make a subset longer than the original set
 		return nil, fmt.Errorf(errMoreItemsThanScale)
@@ -52,15 +72,9 @@ func GetRandomSubsetPerm(numItems int, totalItems int) ([]int, error) {
 	seen := map[int]bool{}
 	res := make([]int, numItems)
 	for i := 0; i < numItems; i++ {
-		for {
-			n := rand.Intn(totalItems)
-			// Keep iterating until a previously unseen int is found
-			if !seen[n] {
-				seen[n] = true
-				res[i] = n
-				break
-			}
-		}
+		n := picker.pick(totalItems, seen)
+		seen[n] = true
+		res[i] = n
 	}
 	return res, nil
 }
@@ -0,0 +1,137 @@
+package common
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+const (
+	// DistributionUniform picks every host or truck with the same probability.
+	DistributionUniform = "uniform"
+	// DistributionZipf picks the hosts or trucks following a Zipf
+	// distribution, so the lower numbered ones are picked the most.
+	DistributionZipf = "zipf"
+	// DistributionHotSet picks the hosts or trucks of a hot set, the lower
+	// numbered ones, for a fixed share of the picks.
+	DistributionHotSet = "hot-set"
+
+	// maxSkewedDraws is how many times a skewed distribution is drawn from
+	// for an item not picked yet before taking the next unpicked one.
+	maxSkewedDraws = 100
+)
+
+// EntitySelection configures how the hosts or trucks of the queries are
+// picked out of all of them.
+type EntitySelection struct {
+	// Distribution is one of DistributionUniform, DistributionZipf or
+	// DistributionHotSet. Empty means uniform.
+	Distribution string
+	// ZipfExponent is the exponent (> 1) of the Zipf distribution; the
+	// larger it is, the more the first hosts or trucks are picked.
+	ZipfExponent float64
+	// HotSetPercent is the percentage of the hosts or trucks in the hot set.
+	HotSetPercent float64
+	// HotSetQueryPercent is the percentage of the picks from the hot set.
+	HotSetQueryPercent float64
+}
+
+// entityPicker picks one of totalItems items, among the ones not in seen.
+type entityPicker interface {
+	pick(totalItems int, seen map[int]bool) int
+}
+
+// SetEntitySelection sets how GetRandomSubsetPerm of the Core, and so the
+// random hosts and trucks of the queries, picks items.
+func (c *Core) SetEntitySelection(s EntitySelection) error {
+	switch s.Distribution {
+	case "", DistributionUniform:
+		c.picker = uniformPicker{}
+	case DistributionZipf:
+		if s.ZipfExponent <= 1 {
+			return fmt.Errorf("zipf exponent must be larger than 1; got %v", s.ZipfExponent)
+		}
+		c.picker = &zipfPicker{exponent: s.ZipfExponent, zipfs: make(map[int]*rand.Zipf)}
+	case DistributionHotSet:
+		if s.HotSetPercent <= 0 || s.HotSetPercent > 100 {
+			return fmt.Errorf("hot set percentage must be in (0, 100]; got %v", s.HotSetPercent)
+		}
+		if s.HotSetQueryPercent < 0 || s.HotSetQueryPercent > 100 {
+			return fmt.Errorf("hot set query percentage must be in [0, 100]; got %v", s.HotSetQueryPercent)
+		}
+		c.picker = &hotSetPicker{percent: s.HotSetPercent, queryPercent: s.HotSetQueryPercent}
+	default:
+		return fmt.Errorf("unknown entity distribution '%s'; choices are %s, %s and %s",
+			s.Distribution, DistributionUniform, DistributionZipf, DistributionHotSet)
+	}
+	return nil
+}
+
+type uniformPicker struct{}
+
+func (uniformPicker) pick(totalItems int, seen map[int]bool) int {
+	for {
+		n := rand.Intn(totalItems)
+		// Keep iterating until a previously unseen int is found
+		if !seen[n] {
+			return n
+		}
+	}
+}
+
+// pickSkewed draws from draw until it returns an unseen item. A skewed
+// distribution rarely draws the items of its tail, so after maxSkewedDraws
+// the next unseen item after the last draw is taken instead.
+func pickSkewed(totalItems int, seen map[int]bool, draw func() int) int {
+	n := 0
+	for i := 0; i < maxSkewedDraws; i++ {
+		n = draw()
+		if !seen[n] {
+			return n
+		}
+	}
+	for seen[n] {
+		n = (n + 1) % totalItems
+	}
+	return n
+}
+
+// globalSource is a rand.Source drawing from the global math/rand source, so
+// that the seed of the query generation keeps the queries deterministic.
+type globalSource struct{}
+
+func (globalSource) Int63() int64 { return rand.Int63() }
+
+func (globalSource) Seed(int64) {}
+
+type zipfPicker struct {
+	exponent float64
+	// zipfs has a Zipf per number of items
+	zipfs map[int]*rand.Zipf
+}
+
+func (p *zipfPicker) pick(totalItems int, seen map[int]bool) int {
+	z, ok := p.zipfs[totalItems]
+	if !ok {
+		z = rand.NewZipf(rand.New(globalSource{}), p.exponent, 1, uint64(totalItems-1))
+		p.zipfs[totalItems] = z
+	}
+	return pickSkewed(totalItems, seen, func() int {
+		return int(z.Uint64())
+	})
+}
+
+type hotSetPicker struct {
+	percent      float64
+	queryPercent float64
+}
+
+func (p *hotSetPicker) pick(totalItems int, seen map[int]bool) int {
+	hot := int(math.Ceil(float64(totalItems) * p.percent / 100))
+	return pickSkewed(totalItems, seen, func() int {
+		if hot == totalItems || rand.Float64()*100 < p.queryPercent {
+			return rand.Intn(hot)
+		}
+		return hot + rand.Intn(totalItems-hot)
+	})
+}
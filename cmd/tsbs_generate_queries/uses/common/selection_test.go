@@ -0,0 +1,173 @@
+package common
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// newSelectionCore returns a Core picking items as set by s.
+func newSelectionCore(t *testing.T, s EntitySelection) *Core {
+	core, err := NewCore(time.Now(), time.Now(), 10)
+	if err != nil {
+		t.Fatalf("unexpected error creating core: %v", err)
+	}
+	if err := core.SetEntitySelection(s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return core
+}
+
+func TestSetEntitySelection(t *testing.T) {
+	cases := []struct {
+		desc      string
+		selection EntitySelection
+		errMsg    string
+	}{
+		{
+			desc:      "default",
+			selection: EntitySelection{},
+		},
+		{
+			desc:      "zipf",
+			selection: EntitySelection{Distribution: DistributionZipf, ZipfExponent: 1.5},
+		},
+		{
+			desc:      "zipf with too small exponent",
+			selection: EntitySelection{Distribution: DistributionZipf, ZipfExponent: 1},
+			errMsg:    "zipf exponent must be larger than 1; got 1",
+		},
+		{
+			desc:      "hot set",
+			selection: EntitySelection{Distribution: DistributionHotSet, HotSetPercent: 20, HotSetQueryPercent: 80},
+		},
+		{
+			desc:      "hot set of no items",
+			selection: EntitySelection{Distribution: DistributionHotSet, HotSetPercent: 0, HotSetQueryPercent: 80},
+			errMsg:    "hot set percentage must be in (0, 100]; got 0",
+		},
+		{
+			desc:      "hot set with too many queries",
+			selection: EntitySelection{Distribution: DistributionHotSet, HotSetPercent: 20, HotSetQueryPercent: 120},
+			errMsg:    "hot set query percentage must be in [0, 100]; got 120",
+		},
+		{
+			desc:      "unknown distribution",
+			selection: EntitySelection{Distribution: "normal"},
+			errMsg:    "unknown entity distribution 'normal'; choices are uniform, zipf and hot-set",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			core := newSelectionCore(t, EntitySelection{})
+			err := core.SetEntitySelection(c.selection)
+			if c.errMsg == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+			} else if err == nil {
+				t.Errorf("unexpected lack of error")
+			} else if got := err.Error(); got != c.errMsg {
+				t.Errorf("incorrect error: got\n%s\nwant\n%s", got, c.errMsg)
+			}
+		})
+	}
+}
+
+func TestGetRandomSubsetPermUniformUnchanged(t *testing.T) {
+	core := newSelectionCore(t, EntitySelection{Distribution: DistributionUniform})
+
+	rand.Seed(123)
+	got, err := core.GetRandomSubsetPerm(5, 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the uniform distribution draws like plain rand.Intn, so the queries
+	// stay the same for a given seed
+	rand.Seed(123)
+	seen := map[int]bool{}
+	want := []int{}
+	for len(want) < 5 {
+		n := rand.Intn(10)
+		if !seen[n] {
+			seen[n] = true
+			want = append(want, n)
+		}
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("incorrect items: got %v want %v", got, want)
+	}
+}
+
+// countPicks returns how many times every item of totalItems was picked in
+// n single item subsets.
+func countPicks(t *testing.T, core *Core, totalItems, n int) []int {
+	counts := make([]int, totalItems)
+	for i := 0; i < n; i++ {
+		items, err := core.GetRandomSubsetPerm(1, totalItems)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		counts[items[0]]++
+	}
+	return counts
+}
+
+func TestGetRandomSubsetPermZipf(t *testing.T) {
+	core := newSelectionCore(t, EntitySelection{Distribution: DistributionZipf, ZipfExponent: 1.5})
+
+	rand.Seed(123)
+	counts := countPicks(t, core, 100, 10000)
+	for i := 1; i < 5; i++ {
+		if counts[i] >= counts[i-1] {
+			t.Errorf("item %d picked as much as item %d: %v", i, i-1, counts[:5])
+		}
+	}
+	if counts[0] < 3000 {
+		t.Errorf("first item not picked most of the time: got %d of 10000", counts[0])
+	}
+}
+
+func TestGetRandomSubsetPermHotSet(t *testing.T) {
+	core := newSelectionCore(t, EntitySelection{Distribution: DistributionHotSet, HotSetPercent: 10, HotSetQueryPercent: 90})
+
+	rand.Seed(123)
+	counts := countPicks(t, core, 100, 10000)
+	hot := 0
+	for _, c := range counts[:10] {
+		hot += c
+	}
+	if hot < 8800 || hot > 9200 {
+		t.Errorf("incorrect number of hot set picks: got %d want about 9000 of 10000", hot)
+	}
+	for i, c := range counts[10:] {
+		if c == 0 {
+			t.Errorf("item %d of the cold set never picked", i+10)
+		}
+	}
+}
+
+func TestGetRandomSubsetPermSkewedAllItems(t *testing.T) {
+	for _, s := range []EntitySelection{
+		{Distribution: DistributionZipf, ZipfExponent: 3},
+		{Distribution: DistributionHotSet, HotSetPercent: 1, HotSetQueryPercent: 100},
+	} {
+		core := newSelectionCore(t, s)
+		// the tail items are (almost) never drawn, so they have to be
+		// taken without drawing them
+		items, err := core.GetRandomSubsetPerm(1000, 1000)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		seen := map[int]bool{}
+		for _, n := range items {
+			if n < 0 || n >= 1000 || seen[n] {
+				t.Fatalf("%s: invalid or duplicate item %d", s.Distribution, n)
+			}
+			seen[n] = true
+		}
+	}
+}
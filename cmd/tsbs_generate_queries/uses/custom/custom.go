@@ -0,0 +1,361 @@
+package custom
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/common"
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/devops"
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/iot"
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/utils"
+	iotData "github.com/bodhiye/tsbs/pkg/data/usecases/iot"
+	"github.com/bodhiye/tsbs/pkg/query"
+	internalutils "github.com/bodhiye/tsbs/tools/utils"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// LabelCustom is the query type of the queries rendered from the
+	// templates of a custom queries file.
+	LabelCustom = "custom"
+
+	errNoTemplates         = "custom queries file %s must have at least one query"
+	errEmptyName           = "custom query in %s must have a name"
+	errDuplicateName       = "custom query '%s' is more than once in %s"
+	errNonPositiveWeight   = "weight of custom query '%s' must be positive"
+	errNegativeCount       = "number of %s of custom query '%s' cannot be negative"
+	errNoTemplateForFormat = "custom query '%s' has no template for format '%s'"
+	errBadWindow           = "cannot parse window of custom query '%s': %v"
+	errBadTemplate         = "cannot parse template of custom query '%s': %v"
+	errTooManyHosts        = "custom query '%s' asks for %d hosts, more than the scale (%d)"
+	errTooManyTrucks       = "custom query '%s' asks for %d trucks, more than the scale (%d)"
+	errWindowTooLarge      = "window of custom query '%s' (%s) is larger than the dataset (%s)"
+	errCannotRender        = "cannot render custom query '%s': %v"
+)
+
+// templateFuncs are the functions available to the query templates.
+var templateFuncs = template.FuncMap{
+	// join joins the items of a list with sep, e.g. {{join .Hosts "|"}}
+	"join": func(items []string, sep string) string {
+		return strings.Join(items, sep)
+	},
+	// quote single-quotes every item of a list, e.g.
+	// {{join (quote .Hosts) ", "}} => 'host_1', 'host_7'
+	"quote": func(items []string) []string {
+		quoted := make([]string, len(items))
+		for i, item := range items {
+			quoted[i] = "'" + item + "'"
+		}
+		return quoted
+	},
+	// rfc3339 formats a time like the built-in queries do, e.g.
+	// {{rfc3339 .Start}} => 2016-01-01T08:12:00Z
+	"rfc3339": func(t time.Time) string {
+		return t.UTC().Format(time.RFC3339)
+	},
+}
+
+// Template is a named query template of a custom queries file, with the
+// query of the format queries are generated for.
+type Template struct {
+	Name    string
+	Weight  uint64
+	Hosts   int
+	Trucks  int
+	Window  time.Duration
+	Metrics []string
+	Table   string
+
+	text *template.Template
+}
+
+// templateSpec is how a Template is written in a custom queries file.
+type templateSpec struct {
+	Name    string            `yaml:"name"`
+	Weight  int               `yaml:"weight"`
+	Hosts   int               `yaml:"hosts"`
+	Trucks  int               `yaml:"trucks"`
+	Window  string            `yaml:"window"`
+	Metrics []string          `yaml:"metrics"`
+	Table   string            `yaml:"table"`
+	Queries map[string]string `yaml:"queries"`
+}
+
+// ReadTemplatesFile reads the query templates for format from a YAML file
+// of named queries, e.g.:
+//
+//	# dashboards.yaml
+//	- name: cpu-dashboard
+//	  weight: 3
+//	  hosts: 4
+//	  window: 1h
+//	  metrics: [usage_user, usage_system]
+//	  table: cpu
+//	  queries:
+//	    timescaledb: >
+//	      SELECT time_bucket('1 minute', time) AS minute, {{join .Metrics ", "}}
+//	      FROM cpu
+//	      WHERE hostname IN ({{join (quote .Hosts) ", "}})
+//	      AND time >= '{{rfc3339 .Start}}' AND time < '{{rfc3339 .End}}'
+//	    influx: >
+//	      SELECT {{join .Metrics ", "}} FROM cpu
+//	      WHERE hostname =~ /^({{join .Hosts "|"}})$/
+//	      AND time >= '{{rfc3339 .Start}}' AND time < '{{rfc3339 .End}}'
+//
+// Every query needs a template for the format, which is a Go text/template
+// rendered with the fields of Params.
+func ReadTemplatesFile(fileName, format string) ([]*Template, error) {
+	contents, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("could not read custom queries file: %v", err)
+	}
+	var specs []templateSpec
+	if err := yaml.UnmarshalStrict(contents, &specs); err != nil {
+		return nil, fmt.Errorf("could not parse custom queries file %s: %v", fileName, err)
+	}
+	if len(specs) == 0 {
+		return nil, fmt.Errorf(errNoTemplates, fileName)
+	}
+
+	templates := make([]*Template, 0, len(specs))
+	seen := make(map[string]bool, len(specs))
+	for _, spec := range specs {
+		if spec.Name == "" {
+			return nil, fmt.Errorf(errEmptyName, fileName)
+		}
+		if seen[spec.Name] {
+			return nil, fmt.Errorf(errDuplicateName, spec.Name, fileName)
+		}
+		seen[spec.Name] = true
+
+		t, err := spec.toTemplate(format)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, t)
+	}
+	return templates, nil
+}
+
+func (s *templateSpec) toTemplate(format string) (*Template, error) {
+	if s.Weight <= 0 {
+		return nil, fmt.Errorf(errNonPositiveWeight, s.Name)
+	}
+	if s.Hosts < 0 {
+		return nil, fmt.Errorf(errNegativeCount, "hosts", s.Name)
+	}
+	if s.Trucks < 0 {
+		return nil, fmt.Errorf(errNegativeCount, "trucks", s.Name)
+	}
+	var window time.Duration
+	if s.Window != "" {
+		var err error
+		window, err = time.ParseDuration(s.Window)
+		if err != nil || window <= 0 {
+			return nil, fmt.Errorf(errBadWindow, s.Name, s.Window)
+		}
+	}
+	text, ok := s.Queries[format]
+	if !ok {
+		return nil, fmt.Errorf(errNoTemplateForFormat, s.Name, format)
+	}
+	parsed, err := template.New(s.Name).Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf(errBadTemplate, s.Name, err)
+	}
+
+	return &Template{
+		Name:    s.Name,
+		Weight:  uint64(s.Weight),
+		Hosts:   s.Hosts,
+		Trucks:  s.Trucks,
+		Window:  window,
+		Metrics: s.Metrics,
+		Table:   s.Table,
+		text:    parsed,
+	}, nil
+}
+
+// Params are the values a query template is rendered with. Hosts, Trucks,
+// Fleet and the time window are picked at random for every query.
+type Params struct {
+	// Hosts is a random set of Template.Hosts hosts of the devops use case
+	Hosts []string
+	// Trucks is a random set of Template.Trucks trucks of the iot use case
+	Trucks []string
+	// Fleet is a random fleet of the iot use case
+	Fleet string
+	// Metrics is the metric list of the Template
+	Metrics []string
+	// Start and End are a random window of Template.Window length within
+	// the dataset, or the whole dataset if the Template has no window
+	Start time.Time
+	End   time.Time
+}
+
+// Query is a query rendered from a Template, ready to be filled in by a
+// database.
+type Query struct {
+	// Name is the name of the Template
+	Name string
+	// Table is the table of the Template, if any
+	Table string
+	// Query is the rendered query, e.g. SQL or InfluxQL
+	Query string
+	// Interval is the time window the query was rendered with
+	Interval *internalutils.TimeInterval
+}
+
+// HumanLabel returns the Query human-readable label for dbName.
+func (q *Query) HumanLabel(dbName string) string {
+	return fmt.Sprintf("%s custom query %s", dbName, q.Name)
+}
+
+// HumanDescription returns the Query human-readable description for the
+// given label.
+func (q *Query) HumanDescription(humanLabel string) string {
+	return fmt.Sprintf("%s: %s", humanLabel, q.Interval.StartString())
+}
+
+// Filler is a type that can fill in a custom query.
+type Filler interface {
+	CustomQuery(query.Query, *Query)
+}
+
+// Core is the common component of all custom query fillers, picking hosts
+// and trucks like the devops and iot use cases do.
+type Core struct {
+	*common.Core
+	devops *devops.Core
+	iot    *iot.Core
+}
+
+// NewCore returns a new Core for the given time range and cardinality
+func NewCore(start, end time.Time, scale int) (*Core, error) {
+	c, err := common.NewCore(start, end, scale)
+	if err != nil {
+		return nil, err
+	}
+	return &Core{
+		Core:   c,
+		devops: &devops.Core{Core: c},
+		iot:    &iot.Core{Core: c},
+	}, nil
+}
+
+// SetTagValueLength sets the minimum length the hostnames of the data were
+// padded to, see devops.Core.SetTagValueLength.
+func (c *Core) SetTagValueLength(n int) {
+	c.devops.SetTagValueLength(n)
+}
+
+// Custom is a QueryFiller that renders a Template.
+type Custom struct {
+	core     utils.QueryGenerator
+	c        *Core
+	template *Template
+}
+
+// NewCustom returns a new Custom filling in queries rendered from t. It
+// checks that t fits the dataset of c and renders it once with fixed
+// Params, so that a bad template fails before any query is generated
+// without drawing from the random stream of the generated queries.
+func NewCustom(core utils.QueryGenerator, c *Core, t *Template) (*Custom, error) {
+	if t.Hosts > c.Scale {
+		return nil, fmt.Errorf(errTooManyHosts, t.Name, t.Hosts, c.Scale)
+	}
+	if t.Trucks > c.Scale {
+		return nil, fmt.Errorf(errTooManyTrucks, t.Name, t.Trucks, c.Scale)
+	}
+	if t.Window > c.Interval.Duration() {
+		return nil, fmt.Errorf(errWindowTooLarge, t.Name, t.Window, c.Interval.Duration())
+	}
+	d := &Custom{core: core, c: c, template: t}
+	interval, params := d.fixedParams()
+	if _, err := d.execute(interval, params); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// fixedParams returns the Params of the first hosts and trucks, the first
+// fleet and the window at the start of the dataset.
+func (d *Custom) fixedParams() (*internalutils.TimeInterval, *Params) {
+	interval := d.c.Interval
+	if d.template.Window > 0 {
+		interval, _ = internalutils.NewTimeInterval(interval.Start(), interval.Start().Add(d.template.Window))
+	}
+	params := &Params{
+		Metrics: d.template.Metrics,
+		Start:   interval.Start(),
+		End:     interval.End(),
+		Fleet:   iotData.FleetChoices[0],
+	}
+	for n := 0; n < d.template.Hosts; n++ {
+		params.Hosts = append(params.Hosts, fmt.Sprintf("host_%d", n))
+	}
+	for n := 0; n < d.template.Trucks; n++ {
+		params.Trucks = append(params.Trucks, fmt.Sprintf("truck_%d", n))
+	}
+	return interval, params
+}
+
+// render renders the Template with a new set of random Params.
+func (d *Custom) render() (*Query, error) {
+	interval := d.c.Interval
+	if d.template.Window > 0 {
+		interval = interval.MustRandWindow(d.template.Window)
+	}
+	params := &Params{
+		Metrics: d.template.Metrics,
+		Start:   interval.Start(),
+		End:     interval.End(),
+	}
+	var err error
+	if d.template.Hosts > 0 {
+		params.Hosts, err = d.c.devops.GetRandomHosts(d.template.Hosts)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if d.template.Trucks > 0 {
+		params.Trucks, err = d.c.iot.GetRandomTrucks(d.template.Trucks)
+		if err != nil {
+			return nil, err
+		}
+	}
+	params.Fleet = d.c.iot.GetRandomFleet()
+	return d.execute(interval, params)
+}
+
+// execute renders the Template with params.
+func (d *Custom) execute(interval *internalutils.TimeInterval, params *Params) (*Query, error) {
+	buf := new(bytes.Buffer)
+	if err := d.template.text.Execute(buf, params); err != nil {
+		return nil, fmt.Errorf(errCannotRender, d.template.Name, err)
+	}
+	return &Query{
+		Name:     d.template.Name,
+		Table:    d.template.Table,
+		Query:    strings.TrimSpace(buf.String()),
+		Interval: interval,
+	}, nil
+}
+
+// Fill fills in the query.Query with query details
+func (d *Custom) Fill(q query.Query) query.Query {
+	fc, ok := d.core.(Filler)
+	if !ok {
+		common.PanicUnimplementedQuery(d.core)
+	}
+	cq, err := d.render()
+	if err != nil {
+		panic(err.Error())
+	}
+	fc.CustomQuery(q, cq)
+	return q
+}
@@ -0,0 +1,311 @@
+package custom
+
+import (
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bodhiye/tsbs/pkg/query"
+)
+
+const testTemplates = `
+- name: cpu-dashboard
+  weight: 3
+  hosts: 2
+  window: 1h
+  metrics: [usage_user, usage_system]
+  table: cpu
+  queries:
+    timescaledb: >
+      SELECT {{join .Metrics ", "}} FROM cpu
+      WHERE hostname IN ({{join (quote .Hosts) ", "}})
+      AND time >= '{{rfc3339 .Start}}' AND time < '{{rfc3339 .End}}'
+    influx: SELECT * FROM cpu WHERE hostname =~ /^({{join .Hosts "|"}})$/
+- name: fleet-trucks
+  weight: 1
+  trucks: 1
+  queries:
+    timescaledb: SELECT * FROM readings WHERE fleet = '{{.Fleet}}' AND name = '{{index .Trucks 0}}'
+`
+
+func writeTestFile(t *testing.T, contents string) string {
+	fileName := filepath.Join(t.TempDir(), "custom.yaml")
+	if err := ioutil.WriteFile(fileName, []byte(contents), 0644); err != nil {
+		t.Fatalf("could not write custom queries file: %v", err)
+	}
+	return fileName
+}
+
+func TestReadTemplatesFile(t *testing.T) {
+	templates, err := ReadTemplatesFile(writeTestFile(t, testTemplates), "timescaledb")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := len(templates); got != 2 {
+		t.Fatalf("incorrect number of templates: got %d want 2", got)
+	}
+	want := &Template{
+		Name:    "cpu-dashboard",
+		Weight:  3,
+		Hosts:   2,
+		Window:  time.Hour,
+		Metrics: []string{"usage_user", "usage_system"},
+		Table:   "cpu",
+	}
+	got := *templates[0]
+	got.text = nil
+	if !reflect.DeepEqual(&got, want) {
+		t.Errorf("incorrect template:\ngot\n%+v\nwant\n%+v", got, *want)
+	}
+	if got := templates[1].Name; got != "fleet-trucks" {
+		t.Errorf("incorrect template order: got %s want fleet-trucks", got)
+	}
+}
+
+func TestReadTemplatesFileErrors(t *testing.T) {
+	cases := []struct {
+		desc     string
+		contents string
+		want     string
+	}{
+		{
+			desc:     "no queries",
+			contents: "[]",
+			want:     "must have at least one query",
+		},
+		{
+			desc:     "no name",
+			contents: "- weight: 1\n  queries: {timescaledb: SELECT 1}",
+			want:     "must have a name",
+		},
+		{
+			desc:     "duplicate name",
+			contents: "- {name: a, weight: 1, queries: {timescaledb: SELECT 1}}\n- {name: a, weight: 1, queries: {timescaledb: SELECT 1}}",
+			want:     "custom query 'a' is more than once",
+		},
+		{
+			desc:     "no weight",
+			contents: "- {name: a, queries: {timescaledb: SELECT 1}}",
+			want:     fmt.Sprintf(errNonPositiveWeight, "a"),
+		},
+		{
+			desc:     "negative hosts",
+			contents: "- {name: a, weight: 1, hosts: -1, queries: {timescaledb: SELECT 1}}",
+			want:     fmt.Sprintf(errNegativeCount, "hosts", "a"),
+		},
+		{
+			desc:     "bad window",
+			contents: "- {name: a, weight: 1, window: forever, queries: {timescaledb: SELECT 1}}",
+			want:     fmt.Sprintf(errBadWindow, "a", "forever"),
+		},
+		{
+			desc:     "no template for format",
+			contents: "- {name: a, weight: 1, queries: {influx: SELECT 1}}",
+			want:     fmt.Sprintf(errNoTemplateForFormat, "a", "timescaledb"),
+		},
+		{
+			desc:     "bad template",
+			contents: "- {name: a, weight: 1, queries: {timescaledb: 'SELECT {{.Hosts'}}",
+			want:     "cannot parse template of custom query 'a'",
+		},
+		{
+			desc:     "unknown key",
+			contents: "- {name: a, weight: 1, host: 1, queries: {timescaledb: SELECT 1}}",
+			want:     "could not parse custom queries file",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			_, err := ReadTemplatesFile(writeTestFile(t, c.contents), "timescaledb")
+			if err == nil {
+				t.Fatalf("unexpected lack of error")
+			}
+			if got := err.Error(); !strings.Contains(got, c.want) {
+				t.Errorf("incorrect error: got\n%s\nwant it to contain\n%s", got, c.want)
+			}
+		})
+	}
+
+	if _, err := ReadTemplatesFile(filepath.Join(t.TempDir(), "missing.yaml"), "timescaledb"); err == nil {
+		t.Errorf("unexpected lack of error for missing file")
+	}
+}
+
+type testGenerator struct {
+	queries []*Query
+}
+
+func (g *testGenerator) GenerateEmptyQuery() query.Query {
+	return query.NewTimescaleDB()
+}
+
+func (g *testGenerator) CustomQuery(qi query.Query, cq *Query) {
+	g.queries = append(g.queries, cq)
+}
+
+func TestCustomFill(t *testing.T) {
+	templates, err := ReadTemplatesFile(writeTestFile(t, testTemplates), "timescaledb")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	start := time.Date(2016, time.January, 1, 0, 0, 0, 0, time.UTC)
+	c, err := NewCore(start, start.Add(24*time.Hour), 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	g := &testGenerator{}
+	hostsFiller, err := NewCustom(g, c, templates[0])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	trucksFiller, err := NewCustom(g, c, templates[1])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rand.Seed(123)
+	hostsFiller.Fill(g.GenerateEmptyQuery())
+	trucksFiller.Fill(g.GenerateEmptyQuery())
+
+	want := []*Query{
+		{
+			Name:  "cpu-dashboard",
+			Table: "cpu",
+			Query: "SELECT usage_user, usage_system FROM cpu WHERE hostname IN ('host_9', 'host_3') AND time >= '2016-01-01T20:16:22Z' AND time < '2016-01-01T21:16:22Z'",
+		},
+		{
+			Name:  "fleet-trucks",
+			Query: "SELECT * FROM readings WHERE fleet = 'South' AND name = 'truck_9'",
+		},
+	}
+	if got := len(g.queries); got != len(want) {
+		t.Fatalf("incorrect number of queries: got %d want %d", got, len(want))
+	}
+	for i, w := range want {
+		got := g.queries[i]
+		if got.Name != w.Name || got.Table != w.Table || got.Query != w.Query {
+			t.Errorf("incorrect query %d:\ngot\n%s | %s | %s\nwant\n%s | %s | %s", i, got.Name, got.Table, got.Query, w.Name, w.Table, w.Query)
+		}
+	}
+	if got := g.queries[0].Interval.Duration(); got != time.Hour {
+		t.Errorf("incorrect window: got %s want 1h", got)
+	}
+	if got := g.queries[1].Interval.Duration(); got != 24*time.Hour {
+		t.Errorf("incorrect window without window: got %s want the dataset", got)
+	}
+	if got, want := g.queries[0].HumanLabel("TimescaleDB"), "TimescaleDB custom query cpu-dashboard"; got != want {
+		t.Errorf("incorrect human label: got %s want %s", got, want)
+	}
+}
+
+func TestNewCustomKeepsRandomStream(t *testing.T) {
+	templates, err := ReadTemplatesFile(writeTestFile(t, testTemplates), "timescaledb")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	start := time.Date(2016, time.January, 1, 0, 0, 0, 0, time.UTC)
+	c, err := NewCore(start, start.Add(24*time.Hour), 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rand.Seed(123)
+	want := rand.Int63()
+
+	rand.Seed(123)
+	for _, tmpl := range templates {
+		if _, err := NewCustom(&testGenerator{}, c, tmpl); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if got := rand.Int63(); got != want {
+		t.Errorf("NewCustom drew from the shared random stream: got %d want %d", got, want)
+	}
+}
+
+func TestNewCustomErrors(t *testing.T) {
+	start := time.Date(2016, time.January, 1, 0, 0, 0, 0, time.UTC)
+	c, err := NewCore(start, start.Add(time.Hour), 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cases := []struct {
+		desc     string
+		contents string
+		want     string
+	}{
+		{
+			desc:     "too many hosts",
+			contents: "- {name: a, weight: 1, hosts: 3, queries: {timescaledb: SELECT 1}}",
+			want:     fmt.Sprintf(errTooManyHosts, "a", 3, 2),
+		},
+		{
+			desc:     "too many trucks",
+			contents: "- {name: a, weight: 1, trucks: 3, queries: {timescaledb: SELECT 1}}",
+			want:     fmt.Sprintf(errTooManyTrucks, "a", 3, 2),
+		},
+		{
+			desc:     "window too large",
+			contents: "- {name: a, weight: 1, window: 2h, queries: {timescaledb: SELECT 1}}",
+			want:     fmt.Sprintf(errWindowTooLarge, "a", 2*time.Hour, time.Hour),
+		},
+		{
+			desc:     "unknown field",
+			contents: "- {name: a, weight: 1, queries: {timescaledb: 'SELECT {{.Host}}'}}",
+			want:     "cannot render custom query 'a'",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			templates, err := ReadTemplatesFile(writeTestFile(t, tc.contents), "timescaledb")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			_, err = NewCustom(&testGenerator{}, c, templates[0])
+			if err == nil {
+				t.Fatalf("unexpected lack of error")
+			}
+			if got := err.Error(); !strings.HasPrefix(got, tc.want) {
+				t.Errorf("incorrect error: got\n%s\nwant\n%s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCustomFillUnimplemented(t *testing.T) {
+	templates, err := ReadTemplatesFile(writeTestFile(t, testTemplates), "timescaledb")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	start := time.Date(2016, time.January, 1, 0, 0, 0, 0, time.UTC)
+	c, err := NewCore(start, start.Add(24*time.Hour), 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	d, err := NewCustom(&unimplementedGenerator{}, c, templates[0])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("did not panic when should")
+		}
+	}()
+	d.Fill(query.NewTimescaleDB())
+}
+
+type unimplementedGenerator struct{}
+
+func (g *unimplementedGenerator) GenerateEmptyQuery() query.Query {
+	return query.NewTimescaleDB()
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/common"
+	devopsData "github.com/bodhiye/tsbs/pkg/data/usecases/devops"
 	"github.com/bodhiye/tsbs/pkg/query"
 )
 
@@ -41,6 +42,10 @@ const (
 // Core is the common component of all generators for all systems
 type Core struct {
 	*common.Core
+
+	// tagValueLength is the minimum length of the hostnames, see
+	// SetTagValueLength
+	tagValueLength int
 }
 
 // NewCore returns a new Core for the given time range and cardinality
@@ -52,7 +57,7 @@ func NewCore(start, end time.Time, scale int) (*Core, error) {
 
 // GetRandomHosts returns a random set of nHosts from a given Core
 func (d *Core) GetRandomHosts(nHosts int) ([]string, error) {
-	return getRandomHosts(nHosts, d.Scale)
+	return d.getRandomHosts(nHosts, d.Scale)
 }
 
 // cpuMetrics is the list of metric names for CPU
@@ -143,10 +148,17 @@ func GetMaxAllLabel(dbName string, nHosts int) string {
 	return fmt.Sprintf("%s max of all CPU metrics, random %4d hosts, random %s by 1h", dbName, nHosts, MaxAllDuration)
 }
 
+// SetTagValueLength sets the minimum length the numbered tag values, and so
+// the hostnames, of the data were padded to, so that the queries ask for
+// hosts that exist.
+func (d *Core) SetTagValueLength(n int) {
+	d.tagValueLength = n
+}
+
 // getRandomHosts returns a subset of numHosts hostnames of a permutation of hostnames,
 // numbered from 0 to totalHosts.
 // Ex.: host_12, host_7, host_25 for numHosts=3 and totalHosts=30 (3 out of 30)
-func getRandomHosts(numHosts int, totalHosts int) ([]string, error) {
+func (d *Core) getRandomHosts(numHosts int, totalHosts int) ([]string, error) {
 	if numHosts < 1 {
 		return nil, fmt.Errorf("number of hosts cannot be < 1; got %d", numHosts)
 	}
@@ -154,14 +166,14 @@ func getRandomHosts(numHosts int, totalHosts int) ([]string, error) {
 		return nil, fmt.Errorf("number of hosts (%d) larger than total hosts. See --scale (%d)", numHosts, totalHosts)
 	}
 
-	randomNumbers, err := common.GetRandomSubsetPerm(numHosts, totalHosts)
+	randomNumbers, err := d.GetRandomSubsetPerm(numHosts, totalHosts)
 	if err != nil {
 		return nil, err
 	}
 
 	hostnames := []string{}
 	for _, n := range randomNumbers {
-		hostnames = append(hostnames, fmt.Sprintf("host_%d", n))
+		hostnames = append(hostnames, devopsData.HostName(n, d.tagValueLength))
 	}
 
 	return hostnames, nil
@@ -57,7 +57,7 @@ func TestCoreGetRandomHosts(t *testing.T) {
 	coreHosts := strings.Join(hosts, ",")
 
 	rand.Seed(100) // Resetting seed to get a deterministic output.
-	hosts, err = getRandomHosts(n, scale)
+	hosts, err = c.getRandomHosts(n, scale)
 	if err != nil {
 		t.Fatalf("unexpected error for getRandomHosts: %v", err)
 	}
@@ -192,10 +192,14 @@ func TestGetRandomHosts(t *testing.T) {
 		},
 	}
 
+	core, err := NewCore(time.Now(), time.Now(), 1)
+	if err != nil {
+		t.Fatalf("unexpected error for NewCore: %v", err)
+	}
 	for _, c := range cases {
 		rand.Seed(100) // always reset the random number generator
 		if c.shouldErr {
-			hosts, err := getRandomHosts(c.nHosts, c.scale)
+			hosts, err := core.getRandomHosts(c.nHosts, c.scale)
 			if hosts != nil {
 				t.Errorf("%s: errored but with non-nil return: %v", c.desc, hosts)
 			}
@@ -203,7 +207,7 @@ func TestGetRandomHosts(t *testing.T) {
 				t.Errorf("%s: incorrect error:\ngot\n%s\nwant\n%s", c.desc, got, c.errMsg)
 			}
 		} else {
-			hosts, err := getRandomHosts(c.nHosts, c.scale)
+			hosts, err := core.getRandomHosts(c.nHosts, c.scale)
 			if err != nil {
 				t.Fatalf("%s: unexpected error: got %v", c.desc, err)
 			} else if got := strings.Join(hosts, ","); got != c.want {
@@ -213,6 +217,24 @@ func TestGetRandomHosts(t *testing.T) {
 	}
 }
 
+func TestGetRandomHostsTagValueLength(t *testing.T) {
+	core, err := NewCore(time.Now(), time.Now(), 100)
+	if err != nil {
+		t.Fatalf("unexpected error for NewCore: %v", err)
+	}
+	core.SetTagValueLength(10)
+
+	rand.Seed(100)
+	hosts, err := core.GetRandomHosts(2)
+	if err != nil {
+		t.Fatalf("unexpected error: got %v", err)
+	}
+	want := "host_00083,host_00068"
+	if got := strings.Join(hosts, ","); got != want {
+		t.Errorf("incorrect output: got %s want %s", got, want)
+	}
+}
+
 func TestGetDoubleGroupByLabel(t *testing.T) {
 	want := fmt.Sprintf("Foo mean of 10 metrics, all hosts, random %s by 1h", DoubleGroupByDuration)
 	got := GetDoubleGroupByLabel("Foo", 10)
@@ -0,0 +1,104 @@
+package events
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/common"
+	"github.com/bodhiye/tsbs/pkg/data/usecases/events"
+	"github.com/bodhiye/tsbs/pkg/query"
+	internalutils "github.com/bodhiye/tsbs/tools/utils"
+)
+
+const (
+	// TableName is the name of the table where the events are stored.
+	TableName = "events"
+
+	// ErrorsByServiceDuration is the time range of the error counts by
+	// service query.
+	ErrorsByServiceDuration = time.Hour
+	// TraceLookupDuration is the time range the trace lookup query looks
+	// for the events of a trace in.
+	TraceLookupDuration = time.Hour
+	// MessageSearchDuration is the time range of the message search query.
+	MessageSearchDuration = time.Hour
+	// MessageSearchLimit is the maximum number of events the message search
+	// query returns.
+	MessageSearchLimit = 100
+
+	// LabelErrorsByService is the label for the error counts by service
+	// query.
+	LabelErrorsByService = "errors-by-service"
+	// LabelTraceLookup is the label for the trace lookup by ID query.
+	LabelTraceLookup = "trace-lookup"
+	// LabelMessageSearch is the label for the message substring search
+	// query.
+	LabelMessageSearch = "message-search"
+)
+
+// Core is the common component of all generators for all systems.
+type Core struct {
+	*common.Core
+}
+
+// NewCore returns a new Core for the given time range and cardinality
+func NewCore(start, end time.Time, scale int) (*Core, error) {
+	c, err := common.NewCore(start, end, scale)
+	return &Core{Core: c}, err
+}
+
+// GetRandomTrace returns the ID of a random trace of the generated data and
+// a random TraceLookupDuration window it is in.
+func (c *Core) GetRandomTrace() (string, *internalutils.TimeInterval) {
+	interval := c.Interval.MustRandWindow(TraceLookupDuration)
+	// The trace has to be of one of the periods fully in the window.
+	start := interval.Start().Truncate(events.TracePeriod)
+	if start.Before(interval.Start()) {
+		start = start.Add(events.TracePeriod)
+	}
+	periods := int(interval.End().Sub(start) / events.TracePeriod)
+	traceTime := start.Add(time.Duration(rand.Intn(periods)) * events.TracePeriod)
+	return events.TraceID(traceTime, rand.Intn(events.TraceCount(c.Scale))), interval
+}
+
+// GetRandomSearchTerm returns one of the phrases of the event messages by
+// random.
+func (c *Core) GetRandomSearchTerm() string {
+	return events.SearchTerms[rand.Intn(len(events.SearchTerms))]
+}
+
+// ErrorsByServiceFiller is a type that can fill in an error counts by
+// service query.
+type ErrorsByServiceFiller interface {
+	ErrorsByService(query.Query)
+}
+
+// TraceLookupFiller is a type that can fill in a trace lookup by ID query.
+type TraceLookupFiller interface {
+	TraceLookup(query.Query)
+}
+
+// MessageSearchFiller is a type that can fill in a message substring search
+// query.
+type MessageSearchFiller interface {
+	MessageSearch(query.Query)
+}
+
+// GetErrorsByServiceLabel returns the Query human-readable label for error
+// counts by service queries.
+func GetErrorsByServiceLabel(dbName string) string {
+	return fmt.Sprintf("%s error counts by service, random %s", dbName, ErrorsByServiceDuration)
+}
+
+// GetTraceLookupLabel returns the Query human-readable label for trace
+// lookup by ID queries.
+func GetTraceLookupLabel(dbName string) string {
+	return fmt.Sprintf("%s trace lookup by ID, random %s", dbName, TraceLookupDuration)
+}
+
+// GetMessageSearchLabel returns the Query human-readable label for message
+// substring search queries.
+func GetMessageSearchLabel(dbName string) string {
+	return fmt.Sprintf("%s message substring search, random %s", dbName, MessageSearchDuration)
+}
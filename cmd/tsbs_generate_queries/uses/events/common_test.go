@@ -0,0 +1,57 @@
+package events
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/bodhiye/tsbs/pkg/data/usecases/events"
+)
+
+func TestCoreGetRandomTrace(t *testing.T) {
+	s := time.Date(2016, time.January, 1, 0, 0, 0, 0, time.UTC)
+	c, err := NewCore(s, s.Add(24*time.Hour), 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rand.Seed(123)
+	for i := 0; i < 100; i++ {
+		id, interval := c.GetRandomTrace()
+		if got := interval.End().Sub(interval.Start()); got != TraceLookupDuration {
+			t.Fatalf("incorrect window duration: got %v want %v", got, TraceLookupDuration)
+		}
+
+		// The trace has to be of a period fully in the window.
+		found := false
+		for ts := interval.Start(); !ts.Add(events.TracePeriod).After(interval.End()); ts = ts.Add(time.Second) {
+			if !ts.Truncate(events.TracePeriod).Before(interval.Start()) {
+				for n := 0; n < events.TraceCount(c.Scale); n++ {
+					if events.TraceID(ts, n) == id {
+						found = true
+					}
+				}
+			}
+		}
+		if !found {
+			t.Fatalf("trace %s not in window %s to %s", id, interval.StartString(), interval.EndString())
+		}
+	}
+}
+
+func TestCoreGetRandomSearchTerm(t *testing.T) {
+	s := time.Now()
+	c, err := NewCore(s, s.Add(time.Hour), 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rand.Seed(123)
+	term := c.GetRandomSearchTerm()
+	for _, want := range events.SearchTerms {
+		if term == want {
+			return
+		}
+	}
+	t.Errorf("unknown search term %q", term)
+}
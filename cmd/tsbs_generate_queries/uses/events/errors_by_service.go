@@ -0,0 +1,29 @@
+package events
+
+import (
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/common"
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/utils"
+	"github.com/bodhiye/tsbs/pkg/query"
+)
+
+// ErrorsByService contains info for filling in error counts by service queries.
+type ErrorsByService struct {
+	core utils.QueryGenerator
+}
+
+// NewErrorsByService creates a new error counts by service query filler.
+func NewErrorsByService(core utils.QueryGenerator) utils.QueryFiller {
+	return &ErrorsByService{
+		core: core,
+	}
+}
+
+// Fill fills in the query.Query with query details.
+func (i *ErrorsByService) Fill(q query.Query) query.Query {
+	fc, ok := i.core.(ErrorsByServiceFiller)
+	if !ok {
+		common.PanicUnimplementedQuery(i.core)
+	}
+	fc.ErrorsByService(q)
+	return q
+}
@@ -0,0 +1,29 @@
+package events
+
+import (
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/common"
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/utils"
+	"github.com/bodhiye/tsbs/pkg/query"
+)
+
+// MessageSearch contains info for filling in message substring search queries.
+type MessageSearch struct {
+	core utils.QueryGenerator
+}
+
+// NewMessageSearch creates a new message substring search query filler.
+func NewMessageSearch(core utils.QueryGenerator) utils.QueryFiller {
+	return &MessageSearch{
+		core: core,
+	}
+}
+
+// Fill fills in the query.Query with query details.
+func (i *MessageSearch) Fill(q query.Query) query.Query {
+	fc, ok := i.core.(MessageSearchFiller)
+	if !ok {
+		common.PanicUnimplementedQuery(i.core)
+	}
+	fc.MessageSearch(q)
+	return q
+}
@@ -0,0 +1,29 @@
+package events
+
+import (
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/common"
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/utils"
+	"github.com/bodhiye/tsbs/pkg/query"
+)
+
+// TraceLookup contains info for filling in trace lookup by ID queries.
+type TraceLookup struct {
+	core utils.QueryGenerator
+}
+
+// NewTraceLookup creates a new trace lookup by ID query filler.
+func NewTraceLookup(core utils.QueryGenerator) utils.QueryFiller {
+	return &TraceLookup{
+		core: core,
+	}
+}
+
+// Fill fills in the query.Query with query details.
+func (i *TraceLookup) Fill(q query.Query) query.Query {
+	fc, ok := i.core.(TraceLookupFiller)
+	if !ok {
+		common.PanicUnimplementedQuery(i.core)
+	}
+	fc.TraceLookup(q)
+	return q
+}
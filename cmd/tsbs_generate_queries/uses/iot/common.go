@@ -74,13 +74,13 @@ func NewCore(start, end time.Time, scale int) (*Core, error) {
 
 // GetRandomTrucks returns a random set of nTrucks from a given Core
 func (c *Core) GetRandomTrucks(nTrucks int) ([]string, error) {
-	return getRandomTrucks(nTrucks, c.Scale)
+	return c.getRandomTrucks(nTrucks, c.Scale)
 }
 
 // getRandomTruckNames returns a subset of numTrucks names of a permutation of truck names,
 // numbered from 0 to totalTrucks.
 // Ex.: truck_12, truck_7, truck_25 for numTrucks=3 and totalTrucks=30 (3 out of 30)
-func getRandomTrucks(numTrucks int, totalTrucks int) ([]string, error) {
+func (c *Core) getRandomTrucks(numTrucks int, totalTrucks int) ([]string, error) {
 	if numTrucks < 1 {
 		return nil, fmt.Errorf("number of trucks cannot be < 1; got %d", numTrucks)
 	}
@@ -88,7 +88,7 @@ func getRandomTrucks(numTrucks int, totalTrucks int) ([]string, error) {
 		return nil, fmt.Errorf("number of trucks (%d) larger than total trucks. See --scale (%d)", numTrucks, totalTrucks)
 	}
 
-	randomNumbers, err := common.GetRandomSubsetPerm(numTrucks, totalTrucks)
+	randomNumbers, err := c.GetRandomSubsetPerm(numTrucks, totalTrucks)
 	if err != nil {
 		return nil, err
 	}
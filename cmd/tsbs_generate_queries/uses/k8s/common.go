@@ -0,0 +1,71 @@
+package k8s
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/common"
+	"github.com/bodhiye/tsbs/pkg/data/usecases/k8s"
+	"github.com/bodhiye/tsbs/pkg/query"
+)
+
+const (
+	// CPUTableName is the name of the table where the container cpu usage
+	// is stored.
+	CPUTableName = "container_cpu"
+
+	// NamespaceCPUDuration is the time range of the per-namespace cpu query.
+	NamespaceCPUDuration = time.Hour
+	// TopPodsCPUDuration is the time range of the top pods by cpu query.
+	TopPodsCPUDuration = time.Hour
+	// TopPodsCPULimit is the number of pods of the top pods by cpu query.
+	TopPodsCPULimit = 10
+
+	// LabelNamespaceCPU is the label for the per-namespace cpu query.
+	LabelNamespaceCPU = "namespace-cpu"
+	// LabelTopPodsCPU is the label for the top pods by cpu query.
+	LabelTopPodsCPU = "top-pods-cpu"
+)
+
+// Core is the common component of all generators for all systems.
+type Core struct {
+	*common.Core
+}
+
+// NewCore returns a new Core for the given time range and cardinality
+func NewCore(start, end time.Time, scale int) (*Core, error) {
+	c, err := common.NewCore(start, end, scale)
+	return &Core{Core: c}, err
+}
+
+// GetRandomNamespace returns one of the namespaces with pods by random.
+func (c *Core) GetRandomNamespace() string {
+	namespaces := k8s.Namespaces(uint64(c.Scale))
+	n, err := c.GetRandomSubsetPerm(1, len(namespaces))
+	if err != nil {
+		panic(err.Error())
+	}
+	return namespaces[n[0]]
+}
+
+// NamespaceCPUFiller is a type that can fill in a per-namespace cpu query.
+type NamespaceCPUFiller interface {
+	NamespaceCPU(query.Query)
+}
+
+// TopPodsCPUFiller is a type that can fill in a top pods by cpu query.
+type TopPodsCPUFiller interface {
+	TopPodsCPU(query.Query, int)
+}
+
+// GetNamespaceCPULabel returns the Query human-readable label for
+// per-namespace cpu queries.
+func GetNamespaceCPULabel(dbName string) string {
+	return fmt.Sprintf("%s cpu per namespace, random %s by 1m", dbName, NamespaceCPUDuration)
+}
+
+// GetTopPodsCPULabel returns the Query human-readable label for top pods by
+// cpu queries.
+func GetTopPodsCPULabel(dbName string, limit int) string {
+	return fmt.Sprintf("%s top %d pods by cpu, random namespace, random %s", dbName, limit, TopPodsCPUDuration)
+}
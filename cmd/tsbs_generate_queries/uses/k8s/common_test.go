@@ -0,0 +1,35 @@
+package k8s
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/bodhiye/tsbs/pkg/data/usecases/k8s"
+)
+
+func TestCoreGetRandomNamespace(t *testing.T) {
+	s := time.Now()
+	c, err := NewCore(s, s.Add(time.Hour), 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// 10 pods are 4 deployments in the first 4 namespaces
+	want := map[string]bool{}
+	for _, ns := range k8s.NamespaceChoices[:4] {
+		want[ns] = true
+	}
+	rand.Seed(123)
+	got := map[string]bool{}
+	for i := 0; i < 100; i++ {
+		ns := c.GetRandomNamespace()
+		if !want[ns] {
+			t.Fatalf("namespace without pods: %s", ns)
+		}
+		got[ns] = true
+	}
+	if len(got) != len(want) {
+		t.Errorf("not all namespaces picked: got %v", got)
+	}
+}
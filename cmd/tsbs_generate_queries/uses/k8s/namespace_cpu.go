@@ -0,0 +1,29 @@
+package k8s
+
+import (
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/common"
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/utils"
+	"github.com/bodhiye/tsbs/pkg/query"
+)
+
+// NamespaceCPU contains info for filling in per-namespace cpu queries.
+type NamespaceCPU struct {
+	core utils.QueryGenerator
+}
+
+// NewNamespaceCPU creates a new per-namespace cpu query filler.
+func NewNamespaceCPU(core utils.QueryGenerator) utils.QueryFiller {
+	return &NamespaceCPU{
+		core: core,
+	}
+}
+
+// Fill fills in the query.Query with query details.
+func (i *NamespaceCPU) Fill(q query.Query) query.Query {
+	fc, ok := i.core.(NamespaceCPUFiller)
+	if !ok {
+		common.PanicUnimplementedQuery(i.core)
+	}
+	fc.NamespaceCPU(q)
+	return q
+}
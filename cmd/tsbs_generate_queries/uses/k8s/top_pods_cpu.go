@@ -0,0 +1,34 @@
+package k8s
+
+import (
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/common"
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/utils"
+	"github.com/bodhiye/tsbs/pkg/query"
+)
+
+// TopPodsCPU contains info for filling in top pods by cpu queries.
+type TopPodsCPU struct {
+	core  utils.QueryGenerator
+	limit int
+}
+
+// NewTopPodsCPU produces a new function that produces a new TopPodsCPU
+// filler of the limit pods using the most cpu.
+func NewTopPodsCPU(limit int) utils.QueryFillerMaker {
+	return func(core utils.QueryGenerator) utils.QueryFiller {
+		return &TopPodsCPU{
+			core:  core,
+			limit: limit,
+		}
+	}
+}
+
+// Fill fills in the query.Query with query details.
+func (i *TopPodsCPU) Fill(q query.Query) query.Query {
+	fc, ok := i.core.(TopPodsCPUFiller)
+	if !ok {
+		common.PanicUnimplementedQuery(i.core)
+	}
+	fc.TopPodsCPU(q, i.limit)
+	return q
+}
@@ -0,0 +1,96 @@
+package ticks
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/common"
+	"github.com/bodhiye/tsbs/pkg/data/usecases/ticks"
+	"github.com/bodhiye/tsbs/pkg/query"
+)
+
+const (
+	// TradesTableName is the name of the table where the trades are stored.
+	TradesTableName = "trades"
+	// QuotesTableName is the name of the table where the quotes are stored.
+	QuotesTableName = "quotes"
+
+	// OHLCVDuration is the time range of the OHLCV bars query.
+	OHLCVDuration = time.Hour
+	// VWAPDuration is the time range of the VWAP query.
+	VWAPDuration = time.Hour
+	// LastQuoteLookback is how far back from its random time the last quote
+	// query looks for quotes.
+	LastQuoteLookback = time.Minute
+
+	// LabelOHLCV is the label for the OHLCV bars query.
+	LabelOHLCV = "ohlcv"
+	// LabelVWAP is the label prefix for the VWAP queries.
+	LabelVWAP = "vwap"
+	// LabelLastQuote is the label for the last quote per symbol query.
+	LabelLastQuote = "last-quote"
+)
+
+// Core is the common component of all generators for all systems.
+type Core struct {
+	*common.Core
+}
+
+// NewCore returns a new Core for the given time range and cardinality
+func NewCore(start, end time.Time, scale int) (*Core, error) {
+	c, err := common.NewCore(start, end, scale)
+	return &Core{Core: c}, err
+}
+
+// GetRandomSymbols returns nSymbols of the symbols by random.
+func (c *Core) GetRandomSymbols(nSymbols int) ([]string, error) {
+	if nSymbols < 1 {
+		return nil, fmt.Errorf("number of symbols cannot be < 1; got %d", nSymbols)
+	}
+	if nSymbols > c.Scale {
+		return nil, fmt.Errorf("number of symbols (%d) larger than total symbols. See --scale (%d)", nSymbols, c.Scale)
+	}
+
+	randomNumbers, err := c.GetRandomSubsetPerm(nSymbols, c.Scale)
+	if err != nil {
+		return nil, err
+	}
+
+	symbols := make([]string, len(randomNumbers))
+	for i, n := range randomNumbers {
+		symbols[i] = ticks.SymbolName(n)
+	}
+	return symbols, nil
+}
+
+// OHLCVFiller is a type that can fill in an OHLCV bars query.
+type OHLCVFiller interface {
+	OHLCV(query.Query)
+}
+
+// VWAPFiller is a type that can fill in a VWAP query.
+type VWAPFiller interface {
+	VWAP(query.Query, int)
+}
+
+// LastQuoteFiller is a type that can fill in a last quote per symbol query.
+type LastQuoteFiller interface {
+	LastQuote(query.Query)
+}
+
+// GetOHLCVLabel returns the Query human-readable label for OHLCV bars
+// queries.
+func GetOHLCVLabel(dbName string) string {
+	return fmt.Sprintf("%s OHLCV bars, random symbol, random %s by 1m", dbName, OHLCVDuration)
+}
+
+// GetVWAPLabel returns the Query human-readable label for VWAP queries.
+func GetVWAPLabel(dbName string, nSymbols int) string {
+	return fmt.Sprintf("%s VWAP, random %4d symbols, random %s", dbName, nSymbols, VWAPDuration)
+}
+
+// GetLastQuoteLabel returns the Query human-readable label for last quote
+// per symbol queries.
+func GetLastQuoteLabel(dbName string) string {
+	return fmt.Sprintf("%s last quote per symbol, random time", dbName)
+}
@@ -0,0 +1,53 @@
+package ticks
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestCoreGetRandomSymbols(t *testing.T) {
+	s := time.Now()
+	c, err := NewCore(s, s.Add(time.Hour), 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cases := []struct {
+		desc     string
+		nSymbols int
+		errMsg   string
+	}{
+		{desc: "zero symbols", nSymbols: 0, errMsg: "number of symbols cannot be < 1; got 0"},
+		{desc: "more symbols than scale", nSymbols: 4, errMsg: "number of symbols (4) larger than total symbols. See --scale (3)"},
+		{desc: "all symbols", nSymbols: 3},
+	}
+
+	rand.Seed(123)
+	for _, tc := range cases {
+		symbols, err := c.GetRandomSymbols(tc.nSymbols)
+		if tc.errMsg != "" {
+			if err == nil {
+				t.Errorf("%s: unexpected lack of error", tc.desc)
+			} else if got := err.Error(); got != tc.errMsg {
+				t.Errorf("%s: incorrect error: got %s want %s", tc.desc, got, tc.errMsg)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.desc, err)
+		}
+		seen := map[string]bool{}
+		for _, s := range symbols {
+			switch s {
+			case "AAAA", "AAAB", "AAAC":
+			default:
+				t.Errorf("%s: unknown symbol %s", tc.desc, s)
+			}
+			seen[s] = true
+		}
+		if len(seen) != tc.nSymbols {
+			t.Errorf("%s: incorrect number of symbols: got %v", tc.desc, symbols)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package ticks
+
+import (
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/common"
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/utils"
+	"github.com/bodhiye/tsbs/pkg/query"
+)
+
+// OHLCV contains info for filling in OHLCV bars queries.
+type OHLCV struct {
+	core utils.QueryGenerator
+}
+
+// NewOHLCV creates a new OHLCV bars query filler.
+func NewOHLCV(core utils.QueryGenerator) utils.QueryFiller {
+	return &OHLCV{
+		core: core,
+	}
+}
+
+// Fill fills in the query.Query with query details.
+func (i *OHLCV) Fill(q query.Query) query.Query {
+	fc, ok := i.core.(OHLCVFiller)
+	if !ok {
+		common.PanicUnimplementedQuery(i.core)
+	}
+	fc.OHLCV(q)
+	return q
+}
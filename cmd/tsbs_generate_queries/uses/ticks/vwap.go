@@ -0,0 +1,34 @@
+package ticks
+
+import (
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/common"
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/utils"
+	"github.com/bodhiye/tsbs/pkg/query"
+)
+
+// VWAP contains info for filling in VWAP queries.
+type VWAP struct {
+	core     utils.QueryGenerator
+	nSymbols int
+}
+
+// NewVWAP produces a new function that produces a new VWAP filler of the
+// volume-weighted average price of nSymbols random symbols.
+func NewVWAP(nSymbols int) utils.QueryFillerMaker {
+	return func(core utils.QueryGenerator) utils.QueryFiller {
+		return &VWAP{
+			core:     core,
+			nSymbols: nSymbols,
+		}
+	}
+}
+
+// Fill fills in the query.Query with query details.
+func (i *VWAP) Fill(q query.Query) query.Query {
+	fc, ok := i.core.(VWAPFiller)
+	if !ok {
+		common.PanicUnimplementedQuery(i.core)
+	}
+	fc.VWAP(q, i.nSymbols)
+	return q
+}
@@ -25,10 +25,21 @@ type RunnerConfig struct {
 	DoAbortOnExist  bool          `yaml:"do-abort-on-exist" mapstructure:"do-abort-on-exist"`
 	ReportingPeriod time.Duration `yaml:"reporting-period" mapstructure:"reporting-period"`
 	Seed            int64
-	HashWorkers     bool   `yaml:"hash-workers" mapstructure:"hash-workers"`
-	InsertIntervals string `yaml:"insert-intervals" mapstructure:"insert-intervals"`
-	FlowControl     bool   `yaml:"flow-control" mapstructure:"flow-control"`
-	ChannelCapacity uint   `yaml:"channel-capacity" mapstructure:"channel-capacity"`
+	HashWorkers     bool          `yaml:"hash-workers" mapstructure:"hash-workers"`
+	InsertIntervals string        `yaml:"insert-intervals" mapstructure:"insert-intervals"`
+	InsertRate      string        `yaml:"insert-rate" mapstructure:"insert-rate"`
+	InsertRateUnit  string        `yaml:"insert-rate-unit" mapstructure:"insert-rate-unit"`
+	FlowControl     bool          `yaml:"flow-control" mapstructure:"flow-control"`
+	ChannelCapacity uint          `yaml:"channel-capacity" mapstructure:"channel-capacity"`
+	MaxRetries      uint          `yaml:"max-retries" mapstructure:"max-retries"`
+	RetryBackoff    time.Duration `yaml:"retry-backoff" mapstructure:"retry-backoff"`
+	MaxRetryBackoff time.Duration `yaml:"max-retry-backoff" mapstructure:"max-retry-backoff"`
+	DeadLetterFile  string        `yaml:"dead-letter-file" mapstructure:"dead-letter-file"`
+	CheckpointFile  string        `yaml:"checkpoint-file" mapstructure:"checkpoint-file"`
+	CheckpointEvery time.Duration `yaml:"checkpoint-interval" mapstructure:"checkpoint-interval"`
+	Resume          bool          `yaml:"resume" mapstructure:"resume"`
+	Coordinator     string
+	AgentID         string `yaml:"agent-id" mapstructure:"agent-id"`
 }
 
 type DataSourceConfig struct {
@@ -42,13 +53,30 @@ type FileDataSourceConfig struct {
 }
 
 type SimulatorDataSourceConfig struct {
-	Use                   string `yaml:"use-case" mapstructure:"use-case"`
-	Scale                 uint64
-	TimeStart             string `yaml:"timestamp-start" mapstructure:"timestamp-start"`
-	TimeEnd               string `yaml:"timestamp-end" mapstructure:"timestamp-end"`
-	Seed                  int64
-	Debug                 int           `yaml:"debug,omitempty"`
-	Limit                 uint64        `yaml:"max-data-points" mapstructure:"max-data-points"`
-	LogInterval           time.Duration `yaml:"log-interval" mapstructure:"log-interval"`
-	MaxMetricCountPerHost uint64        `yaml:"max-metric-count" mapstructure:"max-metric-count"`
+	Use                       string `yaml:"use-case" mapstructure:"use-case"`
+	Scale                     uint64
+	TimeStart                 string `yaml:"timestamp-start" mapstructure:"timestamp-start"`
+	TimeEnd                   string `yaml:"timestamp-end" mapstructure:"timestamp-end"`
+	Seed                      int64
+	Debug                     int           `yaml:"debug,omitempty"`
+	Limit                     uint64        `yaml:"max-data-points" mapstructure:"max-data-points"`
+	LogInterval               time.Duration `yaml:"log-interval" mapstructure:"log-interval"`
+	MaxMetricCountPerHost     uint64        `yaml:"max-metric-count" mapstructure:"max-metric-count"`
+	RealTime                  bool          `yaml:"real-time" mapstructure:"real-time"`
+	RealTimeShiftToNow        bool          `yaml:"real-time-shift-to-now" mapstructure:"real-time-shift-to-now"`
+	RealTimeAcceleration      float64       `yaml:"real-time-acceleration" mapstructure:"real-time-acceleration"`
+	SchemaFile                string        `yaml:"schema-file" mapstructure:"schema-file"`
+	K8sChurnRate              float64       `yaml:"k8s-churn-rate" mapstructure:"k8s-churn-rate"`
+	K8sRestartRate            float64       `yaml:"k8s-restart-rate" mapstructure:"k8s-restart-rate"`
+	TicksRate                 float64       `yaml:"ticks-rate" mapstructure:"ticks-rate"`
+	TicksBurstFactor          float64       `yaml:"ticks-burst-factor" mapstructure:"ticks-burst-factor"`
+	EventsMessageSize         int           `yaml:"events-message-size" mapstructure:"events-message-size"`
+	DevopsRegions             int           `yaml:"devops-regions" mapstructure:"devops-regions"`
+	DevopsDatacenters         int           `yaml:"devops-datacenters" mapstructure:"devops-datacenters"`
+	DevopsRacks               int           `yaml:"devops-racks" mapstructure:"devops-racks"`
+	DevopsServices            int           `yaml:"devops-services" mapstructure:"devops-services"`
+	DevopsServiceVersions     int           `yaml:"devops-service-versions" mapstructure:"devops-service-versions"`
+	DevopsExtraTags           int           `yaml:"devops-extra-tags" mapstructure:"devops-extra-tags"`
+	DevopsExtraTagCardinality int           `yaml:"devops-extra-tag-cardinality" mapstructure:"devops-extra-tag-cardinality"`
+	DevopsTagValueLength      int           `yaml:"devops-tag-value-length" mapstructure:"devops-tag-value-length"`
 }
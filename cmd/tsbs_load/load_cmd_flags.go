@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/bodhiye/tsbs/load"
+	"github.com/bodhiye/tsbs/load/insertstrategy"
 	"github.com/bodhiye/tsbs/pkg/data/source"
 	"github.com/spf13/pflag"
 )
@@ -24,6 +25,65 @@ func addLoaderRunnerFlags(fs *pflag.FlagSet) {
 		"Time to wait between each insert, default '' => all workers insert ASAP. '1,2' = worker 1 waits 1s "+
 			"between inserts, worker 2 and others wait 2s",
 	)
+	fs.String(
+		"loader.runner.insert-rate",
+		"",
+		"Target rate of all workers together, per second, default '' => insert ASAP. '500000' = insert at "+
+			"500000/s, '100000:5m,500000' = insert at 100000/s for 5 minutes, then at 500000/s",
+	)
+	fs.String(
+		"loader.runner.insert-rate-unit",
+		insertstrategy.RateUnitMetrics,
+		"Unit of loader.runner.insert-rate, 'metrics' or 'rows'",
+	)
+	fs.Uint(
+		"loader.runner.max-retries",
+		0,
+		"Number of times to retry a failed batch insert, for targets that report failed inserts",
+	)
+	fs.Duration(
+		"loader.runner.retry-backoff",
+		load.DefaultRetryBackoff,
+		"Time to wait before the first retry of a failed batch insert, doubled on every retry",
+	)
+	fs.Duration(
+		"loader.runner.max-retry-backoff",
+		load.DefaultMaxRetryBackoff,
+		"Maximum time to wait between retries of a failed batch insert",
+	)
+	fs.String(
+		"loader.runner.dead-letter-file",
+		"",
+		"Write batches that could not be inserted after all retries to this file and keep loading, "+
+			"instead of aborting the load",
+	)
+	fs.String(
+		"loader.runner.checkpoint-file",
+		"",
+		"Periodically save the position of the load and the number of points, metrics and rows loaded to this file",
+	)
+	fs.Duration(
+		"loader.runner.checkpoint-interval",
+		load.DefaultCheckpointInterval,
+		"Time between two checkpoints",
+	)
+	fs.String(
+		"loader.runner.coordinator",
+		"",
+		"Address (host:port) of a tsbs_coordinator to join as an agent, which loads its shard of the data "+
+			"at the same time as the other agents",
+	)
+	fs.String(
+		"loader.runner.agent-id",
+		"",
+		"ID of the agent, unique among the agents, which its shard of the data depends on (default: the host name)",
+	)
+	fs.Bool(
+		"loader.runner.resume",
+		false,
+		"Resume the load after the position saved in loader.runner.checkpoint-file, without creating the database, "+
+			"if the file exists",
+	)
 	fs.Bool(
 		"loader.runner.hash-workers",
 		false,
@@ -97,9 +157,96 @@ func addDataSourceFlags(fs *pflag.FlagSet) {
 		100,
 		"Max number of metric fields to generate per host. Used only in devops-generic use-case",
 	)
+	fs.String(
+		"data-source.simulator.schema-file",
+		"",
+		"YAML file defining the measurements, tags and fields to generate. Used only in custom use-case",
+	)
+	fs.Float64(
+		"data-source.simulator.k8s-churn-rate",
+		0.5,
+		"How many times per hour a pod is replaced by a new one. Used only in k8s use-case",
+	)
+	fs.Float64(
+		"data-source.simulator.k8s-restart-rate",
+		0.05,
+		"How many times per hour a container restarts. Used only in k8s use-case",
+	)
+	fs.Float64(
+		"data-source.simulator.ticks-rate",
+		10,
+		"Mean number of trades and quotes per second of a symbol. Used only in ticks use-case",
+	)
+	fs.Float64(
+		"data-source.simulator.ticks-burst-factor",
+		10,
+		"How many times more trades and quotes a symbol has during a burst. Used only in ticks use-case",
+	)
+	fs.Int(
+		"data-source.simulator.events-message-size",
+		128,
+		"Length in bytes the event messages are padded to. Used only in events use-case",
+	)
+	fs.Int(
+		"data-source.simulator.devops-regions",
+		0,
+		"Number of regions, the built-in ones first, 0 for the 9 built-in ones. Used only in devops use-cases",
+	)
+	fs.Int(
+		"data-source.simulator.devops-datacenters",
+		0,
+		"Number of datacenters per region, 0 for the built-in ones. Used only in devops use-cases",
+	)
+	fs.Int(
+		"data-source.simulator.devops-racks",
+		100,
+		"Number of racks per datacenter. Used only in devops use-cases",
+	)
+	fs.Int(
+		"data-source.simulator.devops-services",
+		20,
+		"Number of services. Used only in devops use-cases",
+	)
+	fs.Int(
+		"data-source.simulator.devops-service-versions",
+		2,
+		"Number of service versions. Used only in devops use-cases",
+	)
+	fs.Int(
+		"data-source.simulator.devops-extra-tags",
+		0,
+		"Number of synthetic tags, tag_0 to tag_N-1, added to the hosts. Used only in devops use-cases",
+	)
+	fs.Int(
+		"data-source.simulator.devops-extra-tag-cardinality",
+		10,
+		"Number of values of each synthetic tag. Used only in devops use-cases",
+	)
+	fs.Int(
+		"data-source.simulator.devops-tag-value-length",
+		0,
+		"Minimum length the numbered tag values, hostnames included, are padded to with zeros. Used only in devops use-cases",
+	)
 	fs.Uint64(
 		"data-source.simulator.scale",
 		defaultScale,
 		"Scaling value specific to use case (e.g., devices in 'devops', trucks in iot).")
 	fs.Duration("data-source.simulator.log-interval", defaultLogInterval, "Duration between data points")
+	fs.Bool(
+		"data-source.simulator.real-time",
+		false,
+		"Release the data points at the pace of their timestamps instead of as fast as possible, to emulate "+
+			"a live fleet reporting every log-interval. Points are sent once a batch is full, so set "+
+			"loader.runner.batch-size accordingly (e.g. to the number of points per log-interval)",
+	)
+	fs.Bool(
+		"data-source.simulator.real-time-shift-to-now",
+		false,
+		"With real-time, replace the timestamp of each point with the time it is released at",
+	)
+	fs.Float64(
+		"data-source.simulator.real-time-acceleration",
+		1,
+		"With real-time, how many times faster than real time to release the data points",
+	)
 }
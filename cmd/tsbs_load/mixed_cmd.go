@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/bodhiye/tsbs/pkg/mixed"
+	"github.com/bodhiye/tsbs/pkg/query"
+	"github.com/bodhiye/tsbs/pkg/targets"
+	"github.com/bodhiye/tsbs/pkg/targets/constants"
+	"github.com/bodhiye/tsbs/pkg/targets/initializers"
+	"github.com/bodhiye/tsbs/tools/utils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+func initMixedCMD() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:              "mixed",
+		Short:            "Load data into a specified target database while running queries against it",
+		PersistentPreRun: initViperConfig,
+	}
+	cmd.PersistentFlags().AddFlagSet(mixedCmdFlags())
+	// don't bind --config which specifies the file from where to read config
+	cmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is ./config.yaml)")
+
+	cmd.AddCommand(initMixedSubCommands()...)
+	return cmd
+}
+
+func mixedCmdFlags() *pflag.FlagSet {
+	fs := loadCmdFlags()
+	addQueryRunnerFlags(fs)
+	addMixedRunnerFlags(fs)
+	return fs
+}
+
+func addQueryRunnerFlags(fs *pflag.FlagSet) {
+	fs.String("queries.file", "", "File name to read queries from")
+	fs.String("queries.db-name", "", "Name of database to run queries against (default: loader.runner.db-name)")
+	fs.Uint("queries.workers", 1, "Number of concurrent requests to make")
+	fs.Uint64("queries.max-queries", 0, "Limit the number of queries to send, 0 = no limit")
+	fs.Uint64("queries.max-rps", 0, "Limit the rate of queries per second, 0 = no limit")
+	fs.Float64("queries.arrival-rate", 0, "Send queries open-loop at this rate per second, measuring latency from the intended send time, 0 = closed-loop")
+	fs.String("queries.arrival-distribution", query.ArrivalConstant, "Distribution of the intervals between queries with queries.arrival-rate, 'constant' or 'poisson'")
+	fs.Int64("queries.seed", 0, "PRNG seed of the poisson intervals between queries with queries.arrival-rate (default: 0, which uses the current timestamp)")
+	fs.String("queries.error-policy", query.ErrorPolicyAbort, "What to do when a query fails or times out: 'abort', 'continue' or 'abort-after' more than queries.max-errors failed")
+	fs.Uint64("queries.max-errors", 0, "Number of failed queries tolerated with queries.error-policy=abort-after")
+	fs.Duration("queries.query-timeout", 0, "Count queries that take longer than this as timed out, 0 = no timeout")
+	fs.Uint64("queries.burn-in", 0, "Number of queries to ignore before collecting statistics")
+	fs.Uint64("queries.print-interval", 100, "Print timing stats to stderr after this many queries (0 to disable)")
+	fs.Bool("queries.prewarm-queries", false, "Run each query twice in a row so the warm query is guaranteed to be a cache hit")
+	fs.Bool("queries.print-responses", false, "Pretty print response bodies for correctness checking (default false).")
+	fs.Int("queries.debug", 0, "Whether to print debug messages.")
+}
+
+func addMixedRunnerFlags(fs *pflag.FlagSet) {
+	fs.Duration("mixed.query-start-delay", 0, "How long to wait after the load has started before starting the queries")
+	fs.String(
+		"mixed.baseline-results",
+		"",
+		"Results file of a query run without write load, to report the query latency degradation against",
+	)
+	fs.String("mixed.results-file", "", "Write the combined test results summary json to this file")
+}
+
+// initMixedSubCommands creates a sub command for each target that can also
+// run queries.
+func initMixedSubCommands() []*cobra.Command {
+	var commands []*cobra.Command
+	for _, format := range constants.SupportedFormats() {
+		target := initializers.GetTarget(format)
+		queryTarget, ok := target.(targets.ImplementedQueryTarget)
+		if !ok {
+			continue
+		}
+		cmd := &cobra.Command{
+			Use:   format,
+			Short: "Load data into " + format + " while running queries against it",
+			Run:   createRunMixed(target, queryTarget),
+		}
+
+		target.TargetSpecificFlags("loader.db-specific.", cmd.PersistentFlags())
+		queryTarget.QueryFlags("loader.db-specific.", cmd.PersistentFlags())
+		commands = append(commands, cmd)
+	}
+	return commands
+}
+
+func createRunMixed(target targets.ImplementedTarget, queryTarget targets.ImplementedQueryTarget) cmdRunner {
+	return func(cmd *cobra.Command, args []string) {
+		// bind the common flags here instead of when defining them, so they
+		// don't collide with the same flags of the load command
+		if err := viper.BindPFlags(cmd.InheritedFlags()); err != nil {
+			panic(fmt.Errorf("could not bind flags to configuration: %v", err))
+		}
+		if err := viper.BindPFlags(cmd.PersistentFlags()); err != nil {
+			panic(fmt.Errorf("could not bind db-specific flags for %s: %v", target.TargetName(), err))
+		}
+		bench, loader, err := parseConfig(target, viper.GetViper())
+		if err != nil {
+			panic(err)
+		}
+		queriesConfig, err := parseQueryRunnerConfig(viper.GetViper(), loader.DatabaseName())
+		if err != nil {
+			panic(err)
+		}
+		mixedConfig, err := parseMixedRunnerConfig(viper.GetViper())
+		if err != nil {
+			panic(err)
+		}
+		processorCreate, err := queryTarget.QueryProcessorCreate(
+			queriesConfig.DBName, queriesConfig, viper.GetViper().Sub("loader").Sub("db-specific"),
+		)
+		if err != nil {
+			panic(err)
+		}
+
+		runner := mixed.NewRunner(*mixedConfig, loader, query.NewBenchmarkRunner(*queriesConfig))
+		if err := runner.Run(bench, queryTarget.QueryPool(), processorCreate); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+func parseQueryRunnerConfig(v *viper.Viper, loaderDBName string) (*query.BenchmarkRunnerConfig, error) {
+	queriesViper, err := utils.SubWithFlags(v, "queries")
+	if err != nil {
+		return nil, err
+	}
+	var conf query.BenchmarkRunnerConfig
+	if err := queriesViper.Unmarshal(&conf); err != nil {
+		return nil, err
+	}
+	if len(conf.FileName) == 0 {
+		return nil, fmt.Errorf("queries.file must be specified")
+	}
+	if len(conf.DBName) == 0 {
+		conf.DBName = loaderDBName
+	}
+	return &conf, nil
+}
+
+func parseMixedRunnerConfig(v *viper.Viper) (*mixed.RunnerConfig, error) {
+	mixedViper, err := utils.SubWithFlags(v, "mixed")
+	if err != nil {
+		return nil, err
+	}
+	var conf mixed.RunnerConfig
+	if err := mixedViper.Unmarshal(&conf); err != nil {
+		return nil, err
+	}
+	if conf.QueryStartDelay < 0 {
+		return nil, fmt.Errorf("mixed.query-start-delay can not be negative: %v", conf.QueryStartDelay)
+	}
+	return &conf, nil
+}
@@ -43,6 +43,11 @@ func parseConfig(target targets.ImplementedTarget, v *viper.Viper) (targets.Benc
 		return nil, nil, fmt.Errorf("config file didn't have loader.db-specific specified")
 	}
 
+	// an agent loads the shard of the data assigned by the coordinator, and
+	// the checkpoints are of the data source set up by the benchmark, e.g.
+	// with a random seed
+	loaderConfigInternal.DataSource = dataSourceInternal
+	loaderConfigInternal.JoinCoordinator()
 	benchmark, err := target.Benchmark(loaderConfigInternal.DBName, dataSourceInternal, dbSpecificViper)
 	if err != nil {
 		return nil, nil, err
@@ -72,8 +77,19 @@ func convertRunnerConfigToInternalRep(r *RunnerConfig) *load.BenchmarkRunnerConf
 		Seed:            r.Seed,
 		HashWorkers:     r.HashWorkers,
 		InsertIntervals: r.InsertIntervals,
+		InsertRate:      r.InsertRate,
+		InsertRateUnit:  r.InsertRateUnit,
 		NoFlowControl:   !r.FlowControl,
 		ChannelCapacity: r.ChannelCapacity,
+		MaxRetries:      r.MaxRetries,
+		RetryBackoff:    r.RetryBackoff,
+		MaxRetryBackoff: r.MaxRetryBackoff,
+		DeadLetterFile:  r.DeadLetterFile,
+		CheckpointFile:  r.CheckpointFile,
+		CheckpointEvery: r.CheckpointEvery,
+		Resume:          r.Resume,
+		Coordinator:     r.Coordinator,
+		AgentID:         r.AgentID,
 	}
 }
 
@@ -134,10 +150,27 @@ func convertDataSourceConfigToInternalRepresentation(format string, d *DataSourc
 				TimeEnd:   d.Simulator.TimeEnd,
 				Debug:     d.Simulator.Debug,
 			},
-			Limit:                 d.Simulator.Limit,
-			LogInterval:           d.Simulator.LogInterval,
-			MaxMetricCountPerHost: d.Simulator.MaxMetricCountPerHost,
-			InterleavedNumGroups:  1,
+			Limit:                     d.Simulator.Limit,
+			LogInterval:               d.Simulator.LogInterval,
+			MaxMetricCountPerHost:     d.Simulator.MaxMetricCountPerHost,
+			InterleavedNumGroups:      1,
+			RealTime:                  d.Simulator.RealTime,
+			RealTimeShiftToNow:        d.Simulator.RealTimeShiftToNow,
+			RealTimeAcceleration:      d.Simulator.RealTimeAcceleration,
+			SchemaFile:                d.Simulator.SchemaFile,
+			K8sChurnRate:              d.Simulator.K8sChurnRate,
+			K8sRestartRate:            d.Simulator.K8sRestartRate,
+			TicksRate:                 d.Simulator.TicksRate,
+			TicksBurstFactor:          d.Simulator.TicksBurstFactor,
+			EventsMessageSize:         d.Simulator.EventsMessageSize,
+			DevopsRegions:             d.Simulator.DevopsRegions,
+			DevopsDatacenters:         d.Simulator.DevopsDatacenters,
+			DevopsRacks:               d.Simulator.DevopsRacks,
+			DevopsServices:            d.Simulator.DevopsServices,
+			DevopsServiceVersions:     d.Simulator.DevopsServiceVersions,
+			DevopsExtraTags:           d.Simulator.DevopsExtraTags,
+			DevopsExtraTagCardinality: d.Simulator.DevopsExtraTagCardinality,
+			DevopsTagValueLength:      d.Simulator.DevopsTagValueLength,
 		}
 	}
 	return &source.DataSourceConfig{
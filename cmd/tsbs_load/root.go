@@ -18,6 +18,7 @@ func init() {
 		panic(err)
 	}
 	rootCmd.AddCommand(loadCmd)
+	rootCmd.AddCommand(initMixedCMD())
 	configCmd := initConfigCMD()
 	rootCmd.AddCommand(configCmd)
 }
@@ -44,6 +44,7 @@ func initProgramOptions() (*cassandra.SpecificConfig, *load.BenchmarkRunnerConfi
 
 	config.HashWorkers = false
 	config.BatchSize = 100
+	config.JoinCoordinator()
 	loader := load.GetBenchmarkRunner(config)
 	return dbConfig, &config, loader
 }
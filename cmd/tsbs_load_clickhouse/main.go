@@ -6,27 +6,18 @@ package main
 import (
 	"fmt"
 
-	"github.com/spf13/viper"
 	"github.com/bodhiye/tsbs/load"
-	"github.com/bodhiye/tsbs/pkg/targets"
+	"github.com/bodhiye/tsbs/pkg/data/source"
 	"github.com/bodhiye/tsbs/pkg/targets/clickhouse"
 	"github.com/bodhiye/tsbs/tools/utils"
 	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
 )
 
-// Global vars
-var (
-	target targets.ImplementedTarget
-)
-
-var loader load.BenchmarkRunner
-var loaderConf load.BenchmarkRunnerConfig
-var conf *clickhouse.ClickhouseConfig
-
 // Parse args:
-func init() {
-	loaderConf = load.BenchmarkRunnerConfig{}
+func initProgramOptions() (*clickhouse.ClickhouseConfig, load.BenchmarkRunner, *load.BenchmarkRunnerConfig) {
 	target := clickhouse.NewTarget()
+	loaderConf := load.BenchmarkRunnerConfig{}
 	loaderConf.AddToFlagSet(pflag.CommandLine)
 	target.TargetSpecificFlags("", pflag.CommandLine)
 	pflag.Parse()
@@ -40,7 +31,7 @@ func init() {
 	if err := viper.Unmarshal(&loaderConf); err != nil {
 		panic(fmt.Errorf("unable to decode config: %s", err))
 	}
-	conf = &clickhouse.ClickhouseConfig{
+	conf := &clickhouse.ClickhouseConfig{
 		Host:       viper.GetString("host"),
 		User:       viper.GetString("user"),
 		Password:   viper.GetString("password"),
@@ -49,9 +40,20 @@ func init() {
 		DbName:     loaderConf.DBName,
 	}
 
-	loader = load.GetBenchmarkRunner(loaderConf)
+	loaderConf.JoinCoordinator()
+	loader := load.GetBenchmarkRunner(loaderConf)
+	return conf, loader, &loaderConf
 }
 
 func main() {
-	loader.RunBenchmark(clickhouse.NewBenchmark(loaderConf.FileName, loaderConf.HashWorkers, conf))
+	conf, loader, loaderConf := initProgramOptions()
+
+	benchmark, err := clickhouse.NewBenchmark(conf, &source.DataSourceConfig{
+		Type: source.FileDataSourceType,
+		File: &source.FileDataSourceConfig{Location: loaderConf.FileName},
+	})
+	if err != nil {
+		panic(err)
+	}
+	loader.RunBenchmark(benchmark)
 }
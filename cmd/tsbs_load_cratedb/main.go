@@ -1,65 +1,22 @@
+// tsbs_load_cratedb loads a CrateDB instance with data from stdin or file.
 package main
 
 import (
-	"bufio"
-	"flag"
 	"fmt"
-	"log"
 
-	"github.com/spf13/viper"
 	"github.com/bodhiye/tsbs/load"
-	"github.com/bodhiye/tsbs/pkg/targets"
-	"github.com/bodhiye/tsbs/pkg/targets/constants"
-	"github.com/bodhiye/tsbs/pkg/targets/initializers"
+	"github.com/bodhiye/tsbs/pkg/data/source"
+	"github.com/bodhiye/tsbs/pkg/targets/crate"
 	"github.com/bodhiye/tsbs/tools/utils"
-	"github.com/jackc/pgx/v4"
 	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
 )
 
-var loader load.BenchmarkRunner
-var config load.BenchmarkRunnerConfig
-var target targets.ImplementedTarget
-
-// the logger is used in implementations of interface methods that
-// do not return error on failures to allow testing such methods
-var fatal = log.Fatalf
-
-type benchmark struct {
-	dbc *dbCreator
-	ds  targets.DataSource
-}
-
-func (b *benchmark) GetDataSource() targets.DataSource {
-	return b.ds
-}
-
-func (b *benchmark) GetBatchFactory() targets.BatchFactory {
-	return &factory{}
-}
-
-func (b *benchmark) GetPointIndexer(maxPartitions uint) targets.PointIndexer {
-	return &targets.ConstantIndexer{}
-}
-
-func (b *benchmark) GetProcessor() targets.Processor {
-	tableDefs := make(map[string]*tableDef)
-	for _, td := range b.dbc.tableDefs {
-		tableDefs[td.name] = td
-	}
-	return &processor{
-		tableDefs: tableDefs,
-		connCfg:   b.dbc.cfg,
-	}
-}
-
-func (b *benchmark) GetDBCreator() targets.DBCreator {
-	return b.dbc
-}
-
-func main() {
-	target = initializers.GetTarget(constants.FormatCrateDB)
-	config = load.BenchmarkRunnerConfig{}
-	config.AddToFlagSet(pflag.CommandLine)
+// Parse args:
+func initProgramOptions() (*crate.SpecificConfig, load.BenchmarkRunner, *load.BenchmarkRunnerConfig) {
+	target := crate.NewTarget()
+	loaderConf := load.BenchmarkRunnerConfig{}
+	loaderConf.AddToFlagSet(pflag.CommandLine)
 	target.TargetSpecificFlags("", pflag.CommandLine)
 	pflag.Parse()
 
@@ -69,35 +26,34 @@ func main() {
 		panic(fmt.Errorf("fatal error config file: %s", err))
 	}
 
-	if err := viper.Unmarshal(&config); err != nil {
+	if err := viper.Unmarshal(&loaderConf); err != nil {
 		panic(fmt.Errorf("unable to decode config: %s", err))
 	}
 
-	hosts := viper.GetString("hosts")
-	port := viper.GetUint("port")
-	user := viper.GetString("user")
-	pass := viper.GetString("pass")
+	conf := &crate.SpecificConfig{
+		Hosts:       viper.GetString("hosts"),
+		Port:        viper.GetUint("port"),
+		User:        viper.GetString("user"),
+		Pass:        viper.GetString("pass"),
+		NumReplicas: viper.GetInt("replicas"),
+		NumShards:   viper.GetInt("shards"),
+	}
 
-	numReplicas := flag.Int("replicas", 0, "Number of replicas per a metric table")
-	numShards := flag.Int("shards", 5, "Number of shards per a metric table")
-	config.HashWorkers = false
-	loader = load.GetBenchmarkRunner(config)
+	loaderConf.HashWorkers = false
+	loaderConf.JoinCoordinator()
+	loader := load.GetBenchmarkRunner(loaderConf)
+	return conf, loader, &loaderConf
+}
 
-	connStr := fmt.Sprintf("host=%s port=%d user=%s password='%s' dbname=doc", hosts, port, user, pass)
-	connConfig, err := pgx.ParseConfig(connStr)
-	if err != nil {
-		panic("could not parse connection config: " + err.Error())
-	}
+func main() {
+	conf, loader, loaderConf := initProgramOptions()
 
-	// TODO implement or check if anything has to be done to support WorkerPerQueue mode
-	ds := &fileDataSource{scanner: bufio.NewScanner(load.GetBufferedReader(config.FileName))}
-	loader.RunBenchmark(&benchmark{
-		dbc: &dbCreator{
-			cfg:         connConfig,
-			numReplicas: *numReplicas,
-			numShards:   *numShards,
-			ds:          ds,
-		},
-		ds: ds,
+	benchmark, err := crate.NewBenchmark(conf, &source.DataSourceConfig{
+		Type: source.FileDataSourceType,
+		File: &source.FileDataSourceConfig{Location: loaderConf.FileName},
 	})
+	if err != nil {
+		panic(err)
+	}
+	loader.RunBenchmark(benchmark)
 }
@@ -5,59 +5,24 @@
 package main
 
 import (
-	"bufio"
-	"bytes"
 	"fmt"
 	"log"
 	"strings"
-	"sync"
-	"time"
 
-	"github.com/spf13/viper"
 	"github.com/bodhiye/tsbs/load"
-	"github.com/bodhiye/tsbs/pkg/targets"
-	"github.com/bodhiye/tsbs/pkg/targets/constants"
-	"github.com/bodhiye/tsbs/pkg/targets/initializers"
+	"github.com/bodhiye/tsbs/pkg/data/source"
+	"github.com/bodhiye/tsbs/pkg/targets/influx"
 	"github.com/bodhiye/tsbs/tools/utils"
 	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
 )
 
-// Program option vars:
-var (
-	daemonURLs        []string
-	replicationFactor int
-	backoff           time.Duration
-	useGzip           bool
-	doAbortOnExist    bool
-	consistency       string
-)
-
-// Global vars
-var (
-	loader  load.BenchmarkRunner
-	config  load.BenchmarkRunnerConfig
-	bufPool sync.Pool
-	target  targets.ImplementedTarget
-)
-
-var consistencyChoices = map[string]struct{}{
-	"any":    {},
-	"one":    {},
-	"quorum": {},
-	"all":    {},
-}
-
-// allows for testing
-var fatal = log.Fatalf
-
 // Parse args:
-func init() {
-	target = initializers.GetTarget(constants.FormatInflux)
-	config = load.BenchmarkRunnerConfig{}
-	config.AddToFlagSet(pflag.CommandLine)
+func initProgramOptions() (*influx.SpecificConfig, load.BenchmarkRunner, *load.BenchmarkRunnerConfig) {
+	target := influx.NewTarget()
+	loaderConf := load.BenchmarkRunnerConfig{}
+	loaderConf.AddToFlagSet(pflag.CommandLine)
 	target.TargetSpecificFlags("", pflag.CommandLine)
-	var csvDaemonURLs string
-
 	pflag.Parse()
 
 	err := utils.SetupConfigFile()
@@ -66,56 +31,37 @@ func init() {
 		panic(fmt.Errorf("fatal error config file: %s", err))
 	}
 
-	if err := viper.Unmarshal(&config); err != nil {
+	if err := viper.Unmarshal(&loaderConf); err != nil {
 		panic(fmt.Errorf("unable to decode config: %s", err))
 	}
 
-	csvDaemonURLs = viper.GetString("urls")
-	replicationFactor = viper.GetInt("replication-factor")
-	consistency = viper.GetString("consistency")
-	backoff = viper.GetDuration("backoff")
-	useGzip = viper.GetBool("gzip")
-
-	if _, ok := consistencyChoices[consistency]; !ok {
-		log.Fatalf("invalid consistency settings")
-	}
-
-	daemonURLs = strings.Split(csvDaemonURLs, ",")
-	if len(daemonURLs) == 0 {
+	csvDaemonURLs := viper.GetString("urls")
+	if len(csvDaemonURLs) == 0 {
 		log.Fatal("missing 'urls' flag")
 	}
-	config.HashWorkers = false
-	loader = load.GetBenchmarkRunner(config)
-}
-
-type benchmark struct{}
-
-func (b *benchmark) GetDataSource() targets.DataSource {
-	return &fileDataSource{scanner: bufio.NewScanner(load.GetBufferedReader(config.FileName))}
-}
 
-func (b *benchmark) GetBatchFactory() targets.BatchFactory {
-	return &factory{}
-}
-
-func (b *benchmark) GetPointIndexer(_ uint) targets.PointIndexer {
-	return &targets.ConstantIndexer{}
-}
-
-func (b *benchmark) GetProcessor() targets.Processor {
-	return &processor{}
-}
+	conf := &influx.SpecificConfig{
+		URLs:              strings.Split(csvDaemonURLs, ","),
+		ReplicationFactor: viper.GetInt("replication-factor"),
+		Consistency:       viper.GetString("consistency"),
+		UseGzip:           viper.GetBool("gzip"),
+	}
 
-func (b *benchmark) GetDBCreator() targets.DBCreator {
-	return &dbCreator{}
+	loaderConf.HashWorkers = false
+	loaderConf.JoinCoordinator()
+	loader := load.GetBenchmarkRunner(loaderConf)
+	return conf, loader, &loaderConf
 }
 
 func main() {
-	bufPool = sync.Pool{
-		New: func() interface{} {
-			return bytes.NewBuffer(make([]byte, 0, 4*1024*1024))
-		},
-	}
+	conf, loader, loaderConf := initProgramOptions()
 
-	loader.RunBenchmark(&benchmark{})
+	benchmark, err := influx.NewBenchmark(loaderConf.DBName, conf, &source.DataSourceConfig{
+		Type: source.FileDataSourceType,
+		File: &source.FileDataSourceConfig{Location: loaderConf.FileName},
+	})
+	if err != nil {
+		panic(err)
+	}
+	loader.RunBenchmark(benchmark)
 }
@@ -67,6 +67,7 @@ func init() {
 		config.HashWorkers = true
 	}
 
+	config.JoinCoordinator()
 	loader = load.GetBenchmarkRunner(config)
 }
 
@@ -35,6 +35,7 @@ func init() {
 		panic(fmt.Errorf("unable to decode config: %s", err))
 	}
 	adapterWriteUrl = viper.GetString("adapter-write-url")
+	config.JoinCoordinator()
 	loader = load.GetBenchmarkRunner(config)
 }
 
@@ -5,7 +5,6 @@
 package main
 
 import (
-	"bufio"
 	"bytes"
 	"fmt"
 	"log"
@@ -70,13 +69,14 @@ func init() {
 	questdbRESTEndPoint = viper.GetString("url")
 	questdbILPBindTo = viper.GetString("ilp-bind-to")
 	config.HashWorkers = false
+	config.JoinCoordinator()
 	loader = load.GetBenchmarkRunner(config)
 }
 
 type benchmark struct{}
 
 func (b *benchmark) GetDataSource() targets.DataSource {
-	return &fileDataSource{scanner: bufio.NewScanner(load.GetBufferedReader(config.FileName))}
+	return &fileDataSource{scanner: targets.NewLineScanner(load.GetBufferedReader(config.FileName))}
 }
 
 func (b *benchmark) GetBatchFactory() targets.BatchFactory {
@@ -15,29 +15,52 @@ type processor struct {
 }
 
 func (p *processor) Init(numWorker int, _, _ bool) {
+	if err := p.connect(); err != nil {
+		fatal("Failed connect to %s: %s\n", questdbILPBindTo, err.Error())
+	}
+}
+
+// connect opens the ILP connection to QuestDB.
+func (p *processor) connect() error {
 	tcpAddr, err := net.ResolveTCPAddr("tcp4", questdbILPBindTo)
 	if err != nil {
-		fatal("Failed to resolve %s: %s\n", questdbILPBindTo, err.Error())
+		return err
 	}
 	p.ilpConn, err = net.DialTCP("tcp", nil, tcpAddr)
-	if err != nil {
-		fatal("Failed connect to %s: %s\n", questdbILPBindTo, err.Error())
-	}
+	return err
 }
 
 func (p *processor) Close(_ bool) {
-	defer p.ilpConn.Close()
+	if p.ilpConn != nil {
+		p.ilpConn.Close()
+	}
 }
 
 func (p *processor) ProcessBatch(b targets.Batch, doLoad bool) (uint64, uint64) {
+	metricCnt, rowCnt, err := p.ProcessBatchWithError(b, doLoad)
+	if err != nil {
+		fatal("Error writing: %s\n", err.Error())
+	}
+	return metricCnt, rowCnt
+}
+
+// ProcessBatchWithError writes the batch, reconnecting first if the previous
+// write failed. ILP has no acknowledgements, so the lines of a failed write
+// that did reach QuestDB are written again when the batch is retried.
+func (p *processor) ProcessBatchWithError(b targets.Batch, doLoad bool) (uint64, uint64, error) {
 	batch := b.(*batch)
 
-	// Write the batch: try until backoff is not needed.
 	if doLoad {
-		var err error
-		_, err = p.ilpConn.Write(batch.buf.Bytes())
+		if p.ilpConn == nil {
+			if err := p.connect(); err != nil {
+				return 0, 0, err
+			}
+		}
+		_, err := p.ilpConn.Write(batch.buf.Bytes())
 		if err != nil {
-			fatal("Error writing: %s\n", err.Error())
+			p.ilpConn.Close()
+			p.ilpConn = nil
+			return 0, 0, err
 		}
 	}
 
@@ -47,5 +70,5 @@ func (p *processor) ProcessBatch(b targets.Batch, doLoad bool) (uint64, uint64)
 	// Return the batch buffer to the pool.
 	batch.buf.Reset()
 	bufPool.Put(batch.buf)
-	return metricCnt, uint64(rowCnt)
+	return metricCnt, uint64(rowCnt), nil
 }
@@ -49,7 +49,7 @@ func mockServerStart() *mockServer {
 					rc, err := conn.Read(data)
 					if err != nil {
 						if err != io.EOF {
-							fatal("failed to read from connection: ", err.Error())
+							fatal("failed to read from connection: %s", err.Error())
 						}
 						return
 					}
@@ -122,3 +122,44 @@ func TestProcessorProcessBatch(t *testing.T) {
 		time.Sleep(50 * time.Millisecond)
 	}
 }
+
+func TestProcessorProcessBatchWithErrorReconnects(t *testing.T) {
+	bufPool = sync.Pool{
+		New: func() interface{} {
+			return bytes.NewBuffer(make([]byte, 0, 4*1024*1024))
+		},
+	}
+	b := (&factory{}).New().(*batch)
+	line := "tag1=tag1val,tag2=tag2val col1=0.0,col2=0.0 140"
+	b.Append(data.LoadedPoint{Data: []byte(line)})
+
+	ms := mockServerStart()
+	defer mockServerStop(ms)
+	questdbILPBindTo = fmt.Sprintf("127.0.0.1:%d", ms.listenPort)
+
+	p := &processor{}
+	p.Init(0, true, true)
+	defer p.Close(true)
+
+	// a write on a broken connection fails and leaves the batch intact
+	p.ilpConn.Close()
+	mCnt, rCnt, err := p.ProcessBatchWithError(b, true)
+	if err == nil {
+		t.Fatalf("expected an error writing to a closed connection")
+	}
+	if mCnt != 0 || rCnt != 0 {
+		t.Errorf("failed write returned counts: got %d metrics %d rows", mCnt, rCnt)
+	}
+	if got := b.buf.String(); got != line+"\n" {
+		t.Errorf("batch changed after failed write: got %q", got)
+	}
+
+	// the retry reconnects
+	mCnt, rCnt, err = p.ProcessBatchWithError(b, true)
+	if err != nil {
+		t.Fatalf("unexpected error on retry: %v", err)
+	}
+	if mCnt != 2 || rCnt != 1 {
+		t.Errorf("incorrect counts on retry: got %d metrics %d rows, want 2 metrics 1 row", mCnt, rCnt)
+	}
+}
@@ -1,8 +1,8 @@
 package main
 
 import (
-	"bufio"
 	"bytes"
+	"io"
 	"strings"
 
 	"github.com/bodhiye/tsbs/pkg/data"
@@ -15,7 +15,17 @@ const errNotThreeTuplesFmt = "parse error: line does not have 3 tuples, has %d"
 var newLine = []byte("\n")
 
 type fileDataSource struct {
-	scanner *bufio.Scanner
+	scanner *targets.LineScanner
+}
+
+// Offset implements targets.OffsetDataSource.
+func (d *fileDataSource) Offset() int64 {
+	return d.scanner.Offset()
+}
+
+// SkipTo implements targets.OffsetDataSource.
+func (d *fileDataSource) SkipTo(offset int64) error {
+	return d.scanner.SkipTo(offset)
 }
 
 func (d *fileDataSource) NextItem() data.LoadedPoint {
@@ -58,6 +68,12 @@ func (b *batch) Append(item data.LoadedPoint) {
 	b.buf.Write(newLine)
 }
 
+// WriteTo writes the lines of the batch as they were read.
+func (b *batch) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write(b.buf.Bytes())
+	return int64(n), err
+}
+
 type factory struct{}
 
 func (f *factory) New() targets.Batch {
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/bodhiye/tsbs/pkg/data"
+	"github.com/bodhiye/tsbs/pkg/targets"
 )
 
 func TestBatch(t *testing.T) {
@@ -83,7 +84,7 @@ func TestFileDataSourceNextItem(t *testing.T) {
 
 	for _, c := range cases {
 		br := bufio.NewReader(bytes.NewReader([]byte(c.input)))
-		ds := &fileDataSource{scanner: bufio.NewScanner(br)}
+		ds := &fileDataSource{scanner: targets.NewLineScanner(br)}
 		p := ds.NextItem()
 		data := p.Data.([]byte)
 		if !bytes.Equal(data, c.result) {
@@ -95,7 +96,7 @@ func TestFileDataSourceNextItem(t *testing.T) {
 func TestDecodeEOF(t *testing.T) {
 	input := []byte("cpu,tag1=tag1text,tag2=tag2text col1=0.0,col2=0.0 140")
 	br := bufio.NewReader(bytes.NewReader([]byte(input)))
-	ds := &fileDataSource{scanner: bufio.NewScanner(br)}
+	ds := &fileDataSource{scanner: targets.NewLineScanner(br)}
 	_ = ds.NextItem()
 	// nothing left, should be EOF
 	p := ds.NextItem()
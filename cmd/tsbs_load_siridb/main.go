@@ -59,6 +59,7 @@ func init() {
 	logBatches = viper.GetBool("log-batches")
 	writeTimeout = viper.GetInt("write-timeout")
 	config.HashWorkers = false
+	config.JoinCoordinator()
 	loader = load.GetBenchmarkRunner(config)
 }
 
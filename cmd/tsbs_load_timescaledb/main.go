@@ -73,6 +73,7 @@ func initProgramOptions() (*timescaledb.LoadingOptions, load.BenchmarkRunner, *l
 	opts.ForceTextFormat = viper.GetBool("force-text-format")
 	opts.UseInsert = viper.GetBool("use-insert")
 
+	loaderConf.JoinCoordinator()
 	loader := load.GetBenchmarkRunner(loaderConf)
 	return &opts, loader, &loaderConf
 }
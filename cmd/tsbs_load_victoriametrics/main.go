@@ -36,6 +36,7 @@ func initProgramOptions() (*victoriametrics.SpecificConfig, load.BenchmarkRunner
 	}
 	vmURLs := strings.Split(urls, ",")
 
+	loaderConf.JoinCoordinator()
 	loader := load.GetBenchmarkRunner(loaderConf)
 	return &victoriametrics.SpecificConfig{ServerURLs: vmURLs}, loader, &loaderConf
 }
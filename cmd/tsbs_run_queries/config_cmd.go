@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bodhiye/tsbs/pkg/targets"
+	"github.com/bodhiye/tsbs/pkg/targets/constants"
+	"github.com/bodhiye/tsbs/pkg/targets/initializers"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+const (
+	targetDbFlag = "target"
+
+	writeConfigTo = "./config.yaml"
+)
+
+func initConfigCMD() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Generate example config yaml file and save it to " + writeConfigTo,
+		Run:   config,
+	}
+
+	cmd.Flags().String(
+		targetDbFlag,
+		constants.FormatTimescaleDB,
+		"specify target db, valid: "+strings.Join(queryFormats(), ", "),
+	)
+	return cmd
+}
+
+func config(cmd *cobra.Command, _ []string) {
+	targetSelected, err := cmd.Flags().GetString(targetDbFlag)
+	if err != nil {
+		panic(fmt.Sprintf("could not read value for %s flag: %v", targetDbFlag, err))
+	}
+	target := initializers.GetTarget(targetSelected)
+	if _, ok := target.(targets.ImplementedQueryTarget); !ok {
+		panic(fmt.Sprintf("target %s can not run queries", targetSelected))
+	}
+
+	v := viper.New()
+	v.SetConfigType("yaml")
+	if err := v.BindPFlags(runnerFlags()); err != nil {
+		panic(fmt.Errorf("could not bind queries.runner flags in viper: %v", err))
+	}
+	flagSet := pflag.NewFlagSet("", pflag.ContinueOnError)
+	addDBSpecificFlags(target, flagSet)
+	if err := v.BindPFlags(flagSet); err != nil {
+		panic(fmt.Errorf("could not bind target specific config flags in viper: %v", err))
+	}
+
+	if err := v.WriteConfigAs(writeConfigTo); err != nil {
+		panic(fmt.Errorf("could not write sample config to file %s: %v", writeConfigTo, err))
+	}
+	fmt.Printf("Wrote example config to: %s\n", writeConfigTo)
+}
@@ -0,0 +1,10 @@
+package main
+
+import "os"
+
+func main() {
+	// cobra prints the error and usage itself
+	if err := rootCmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/bodhiye/tsbs/pkg/query"
+	"github.com/bodhiye/tsbs/pkg/targets"
+	"github.com/bodhiye/tsbs/tools/utils"
+	"github.com/spf13/viper"
+)
+
+// parseConfig creates the query benchmark runner and the query processors of
+// target from the 'queries' object of the configuration. The configuration
+// is validated against the query processors when the runner is run.
+func parseConfig(target targets.ImplementedQueryTarget, v *viper.Viper) (*query.BenchmarkRunner, query.ProcessorCreate, error) {
+	queriesViper, err := utils.SubWithFlags(v, "queries")
+	if err != nil {
+		return nil, nil, err
+	}
+	runnerViper, err := utils.SubWithFlags(queriesViper, "runner")
+	if err != nil {
+		return nil, nil, err
+	}
+	var runnerConfig query.BenchmarkRunnerConfig
+	if err := runnerViper.Unmarshal(&runnerConfig); err != nil {
+		return nil, nil, err
+	}
+
+	dbSpecificViper, err := utils.SubWithFlags(queriesViper, "db-specific")
+	if err != nil {
+		return nil, nil, err
+	}
+	processorCreate, err := target.QueryProcessorCreate(runnerConfig.DBName, &runnerConfig, dbSpecificViper)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not create query processors: %v", err)
+	}
+	return query.NewBenchmarkRunner(runnerConfig), processorCreate, nil
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/bodhiye/tsbs/pkg/query"
+	"github.com/bodhiye/tsbs/pkg/targets"
+	"github.com/bodhiye/tsbs/pkg/targets/constants"
+	"github.com/bodhiye/tsbs/pkg/targets/initializers"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+const (
+	runnerFlagPrefix     = "queries.runner."
+	dbSpecificFlagPrefix = "queries.db-specific."
+)
+
+type cmdRunner func(*cobra.Command, []string)
+
+var (
+	cfgFile string
+	rootCmd = &cobra.Command{
+		Use:              "tsbs_run_queries",
+		Short:            "Run the queries generated by tsbs_generate_queries against a db",
+		PersistentPreRun: initViperConfig,
+	}
+)
+
+func init() {
+	rootCmd.PersistentFlags().AddFlagSet(runnerFlags())
+	if err := viper.BindPFlags(rootCmd.PersistentFlags()); err != nil {
+		panic(fmt.Errorf("could not bind flags to configuration: %v", err))
+	}
+	// don't bind --config which specifies the file from where to read config
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is ./config.yaml)")
+
+	rootCmd.AddCommand(initTargetCommands()...)
+	rootCmd.AddCommand(initConfigCMD())
+}
+
+func runnerFlags() *pflag.FlagSet {
+	fs := pflag.NewFlagSet("", pflag.ContinueOnError)
+	var config query.BenchmarkRunnerConfig
+	config.AddToFlagSetWithPrefix(runnerFlagPrefix, fs)
+	return fs
+}
+
+// queryFormats returns the formats of the targets that can run queries.
+func queryFormats() []string {
+	var formats []string
+	for _, format := range constants.SupportedFormats() {
+		if _, ok := initializers.GetTarget(format).(targets.ImplementedQueryTarget); ok {
+			formats = append(formats, format)
+		}
+	}
+	return formats
+}
+
+// addDBSpecificFlags adds the flags of target used to run queries.
+func addDBSpecificFlags(target targets.ImplementedTarget, fs *pflag.FlagSet) {
+	target.TargetSpecificFlags(dbSpecificFlagPrefix, fs)
+	target.(targets.ImplementedQueryTarget).QueryFlags(dbSpecificFlagPrefix, fs)
+}
+
+// initTargetCommands creates a sub command for each target that can run
+// queries.
+func initTargetCommands() []*cobra.Command {
+	var commands []*cobra.Command
+	for _, format := range queryFormats() {
+		target := initializers.GetTarget(format)
+		cmd := &cobra.Command{
+			Use:   format,
+			Short: "Run queries against " + format + " as a target db",
+			Run:   createRunQueries(target),
+		}
+
+		addDBSpecificFlags(target, cmd.PersistentFlags())
+		commands = append(commands, cmd)
+	}
+	return commands
+}
+
+func createRunQueries(target targets.ImplementedTarget) cmdRunner {
+	queryTarget := target.(targets.ImplementedQueryTarget)
+	return func(cmd *cobra.Command, args []string) {
+		// bind only the flags of the executed sub-command, so viper doesn't
+		// have the flags of all targets
+		if err := viper.BindPFlags(cmd.PersistentFlags()); err != nil {
+			panic(fmt.Errorf("could not bind db-specific flags for %s: %v", target.TargetName(), err))
+		}
+		runner, processorCreate, err := parseConfig(queryTarget, viper.GetViper())
+		if err != nil {
+			panic(err)
+		}
+		if err := runner.Run(queryTarget.QueryPool(), processorCreate); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+func initViperConfig(*cobra.Command, []string) {
+	if cfgFile != "" {
+		// Use config file from the flag.
+		viper.SetConfigFile(cfgFile)
+	} else {
+		// Search config in execution directory with name "config.yaml" (without extension).
+		viper.AddConfigPath(".")
+		viper.SetConfigName("config")
+		viper.SetConfigType("yaml")
+	}
+
+	if err := viper.ReadInConfig(); err == nil {
+		fmt.Println("Using config file:", viper.ConfigFileUsed())
+	}
+}
@@ -7,21 +7,19 @@ package main
 
 import (
 	"fmt"
+	"log"
 
-	"github.com/spf13/viper"
 	"github.com/bodhiye/tsbs/pkg/query"
+	"github.com/bodhiye/tsbs/pkg/targets/akumuli"
 	"github.com/bodhiye/tsbs/tools/utils"
 	"github.com/spf13/pflag"
-)
-
-// Program option vars:
-var (
-	endpoint string
+	"github.com/spf13/viper"
 )
 
 // Global vars:
 var (
 	runner *query.BenchmarkRunner
+	opts   *akumuli.QueryOptions
 )
 
 // Parse args:
@@ -29,7 +27,7 @@ func init() {
 	var config query.BenchmarkRunnerConfig
 	config.AddToFlagSet(pflag.CommandLine)
 
-	pflag.StringVar(&endpoint, "endpoint", "http://localhost:8181", "Akumuli API endpoint IP address.")
+	pflag.String("endpoint", "http://localhost:8181", "Akumuli API endpoint IP address.")
 
 	pflag.Parse()
 
@@ -43,38 +41,16 @@ func init() {
 		panic(fmt.Errorf("unable to decode config: %s", err))
 	}
 
-	endpoint = viper.GetString("endpoint")
-
 	runner = query.NewBenchmarkRunner(config)
-}
-
-func main() {
-	runner.Run(&query.HTTPPool, newProcessor)
-}
-
-type processor struct {
-	w    *HTTPClient
-	opts *HTTPClientDoOptions
-}
-
-func newProcessor() query.Processor { return &processor{} }
-
-func (p *processor) Init(workerNumber int) {
-	p.opts = &HTTPClientDoOptions{
+	opts = &akumuli.QueryOptions{
+		Endpoint:       viper.GetString("endpoint"),
 		Debug:          runner.DebugLevel(),
 		PrintResponses: runner.DoPrintResponses(),
 	}
-	url := endpoint
-	p.w = NewHTTPClient(url)
 }
 
-func (p *processor) ProcessQuery(q query.Query, _ bool) ([]*query.Stat, error) {
-	hq := q.(*query.HTTP)
-	lag, err := p.w.Do(hq, p.opts)
-	if err != nil {
-		return nil, err
+func main() {
+	if err := runner.Run(&query.HTTPPool, akumuli.NewQueryProcessorCreate(opts)); err != nil {
+		log.Fatal(err)
 	}
-	stat := query.GetStat()
-	stat.Init(q.HumanLabelName(), lag)
-	return []*query.Stat{stat}, nil
 }
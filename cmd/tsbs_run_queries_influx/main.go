@@ -10,28 +10,23 @@ import (
 	"log"
 	"strings"
 
-	"github.com/spf13/viper"
 	"github.com/bodhiye/tsbs/pkg/query"
+	"github.com/bodhiye/tsbs/pkg/targets/influx"
 	"github.com/bodhiye/tsbs/tools/utils"
 	"github.com/spf13/pflag"
-)
-
-// Program option vars:
-var (
-	daemonUrls []string
-	chunkSize  uint64
+	"github.com/spf13/viper"
 )
 
 // Global vars:
 var (
 	runner *query.BenchmarkRunner
+	opts   *influx.QueryOptions
 )
 
 // Parse args:
 func init() {
 	var config query.BenchmarkRunnerConfig
 	config.AddToFlagSet(pflag.CommandLine)
-	var csvDaemonUrls string
 
 	pflag.String("urls", "http://localhost:8086", "Daemon URLs, comma-separated. Will be used in a round-robin fashion.")
 	pflag.Uint64("chunk-response-size", 0, "Number of series to chunk results into. 0 means no chunking.")
@@ -48,46 +43,23 @@ func init() {
 		panic(fmt.Errorf("unable to decode config: %s", err))
 	}
 
-	csvDaemonUrls = viper.GetString("urls")
-	chunkSize = viper.GetUint64("chunk-response-size")
-
-	daemonUrls = strings.Split(csvDaemonUrls, ",")
+	daemonUrls := strings.Split(viper.GetString("urls"), ",")
 	if len(daemonUrls) == 0 {
 		log.Fatal("missing 'urls' flag")
 	}
 
 	runner = query.NewBenchmarkRunner(config)
-}
-
-func main() {
-	runner.Run(&query.HTTPPool, newProcessor)
-}
-
-type processor struct {
-	w    *HTTPClient
-	opts *HTTPClientDoOptions
-}
-
-func newProcessor() query.Processor { return &processor{} }
-
-func (p *processor) Init(workerNumber int) {
-	p.opts = &HTTPClientDoOptions{
-		Debug:                runner.DebugLevel(),
-		PrettyPrintResponses: runner.DoPrintResponses(),
-		chunkSize:            chunkSize,
-		database:             runner.DatabaseName(),
+	opts = &influx.QueryOptions{
+		URLs:           daemonUrls,
+		ChunkSize:      viper.GetUint64("chunk-response-size"),
+		DBName:         runner.DatabaseName(),
+		Debug:          runner.DebugLevel(),
+		PrintResponses: runner.DoPrintResponses(),
 	}
-	url := daemonUrls[workerNumber%len(daemonUrls)]
-	p.w = NewHTTPClient(url)
 }
 
-func (p *processor) ProcessQuery(q query.Query, _ bool) ([]*query.Stat, error) {
-	hq := q.(*query.HTTP)
-	lag, err := p.w.Do(hq, p.opts)
-	if err != nil {
-		return nil, err
+func main() {
+	if err := runner.Run(&query.HTTPPool, influx.NewQueryProcessorCreate(opts)); err != nil {
+		log.Fatal(err)
 	}
-	stat := query.GetStat()
-	stat.Init(q.HumanLabelName(), lag)
-	return []*query.Stat{stat}, nil
 }
@@ -5,40 +5,25 @@
 package main
 
 import (
-	"encoding/gob"
 	"fmt"
 	"log"
 	"time"
 
-	"github.com/spf13/viper"
 	"github.com/bodhiye/tsbs/pkg/query"
+	"github.com/bodhiye/tsbs/pkg/targets/mongo"
 	"github.com/bodhiye/tsbs/tools/utils"
-	"github.com/globalsign/mgo"
-	"github.com/globalsign/mgo/bson"
 	"github.com/spf13/pflag"
-)
-
-// Program option vars:
-var (
-	daemonURL string
-	timeout   time.Duration
+	"github.com/spf13/viper"
 )
 
 // Global vars:
 var (
-	runner  *query.BenchmarkRunner
-	session *mgo.Session
+	runner *query.BenchmarkRunner
+	opts   *mongo.QueryOptions
 )
 
 // Parse args:
 func init() {
-	// needed for deserializing the mongo query from gob
-	gob.Register([]interface{}{})
-	gob.Register(map[string]interface{}{})
-	gob.Register([]map[string]interface{}{})
-	gob.Register(bson.M{})
-	gob.Register([]bson.M{})
-
 	var config query.BenchmarkRunnerConfig
 	config.AddToFlagSet(pflag.CommandLine)
 
@@ -57,57 +42,22 @@ func init() {
 		panic(fmt.Errorf("unable to decode config: %s", err))
 	}
 
-	daemonURL = viper.GetString("url")
-	timeout = viper.GetDuration("read-timeout")
-
 	runner = query.NewBenchmarkRunner(config)
+	opts = &mongo.QueryOptions{
+		URL:            viper.GetString("url"),
+		ReadTimeout:    viper.GetDuration("read-timeout"),
+		DBName:         runner.DatabaseName(),
+		Debug:          runner.DebugLevel(),
+		PrintResponses: runner.DoPrintResponses(),
+	}
 }
 
 func main() {
-	var err error
-	session, err = mgo.DialWithTimeout(daemonURL, timeout)
+	processorCreate, err := mongo.NewQueryProcessorCreate(opts)
 	if err != nil {
 		log.Fatal(err)
 	}
-	runner.Run(&query.MongoPool, newProcessor)
-}
-
-type processor struct {
-	collection *mgo.Collection
-}
-
-func newProcessor() query.Processor { return &processor{} }
-
-func (p *processor) Init(workerNumber int) {
-	sess := session.Copy()
-	db := sess.DB(runner.DatabaseName())
-	p.collection = db.C("point_data")
-}
-
-func (p *processor) ProcessQuery(q query.Query, _ bool) ([]*query.Stat, error) {
-	mq := q.(*query.Mongo)
-	start := time.Now().UnixNano()
-	pipe := p.collection.Pipe(mq.BsonDoc).AllowDiskUse()
-	iter := pipe.Iter()
-	if runner.DebugLevel() > 0 {
-		fmt.Println(mq.BsonDoc)
-	}
-	var result map[string]interface{}
-	cnt := 0
-	for iter.Next(&result) {
-		if runner.DoPrintResponses() {
-			fmt.Printf("ID %d: %v\n", q.GetID(), result)
-		}
-		cnt++
-	}
-	if runner.DebugLevel() > 0 {
-		fmt.Println(cnt)
+	if err := runner.Run(&query.MongoPool, processorCreate); err != nil {
+		log.Fatal(err)
 	}
-	err := iter.Close()
-
-	took := time.Now().UnixNano() - start
-	lag := float64(took) / 1e6 // milliseconds
-	stat := query.GetStat()
-	stat.Init(q.HumanLabelName(), lag)
-	return []*query.Stat{stat}, err
 }
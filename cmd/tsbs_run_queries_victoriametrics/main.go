@@ -6,30 +6,21 @@
 package main
 
 import (
-	"bytes"
-	"encoding/json"
 	"fmt"
-	"io/ioutil"
 	"log"
-	"net/http"
-	"os"
 	"strings"
-	"time"
 
-	"github.com/spf13/viper"
 	"github.com/bodhiye/tsbs/pkg/query"
+	"github.com/bodhiye/tsbs/pkg/targets/victoriametrics"
 	"github.com/bodhiye/tsbs/tools/utils"
 	"github.com/spf13/pflag"
-)
-
-// Program option vars:
-var (
-	vmURLs []string
+	"github.com/spf13/viper"
 )
 
 // Global vars:
 var (
 	runner *query.BenchmarkRunner
+	opts   *victoriametrics.QueryOptions
 )
 
 // Parse args:
@@ -53,76 +44,15 @@ func init() {
 	if len(urls) == 0 {
 		log.Fatalf("missing `urls` flag")
 	}
-	vmURLs = strings.Split(urls, ",")
 	runner = query.NewBenchmarkRunner(config)
-}
-
-func main() {
-	runner.Run(&query.HTTPPool, newProcessor)
-}
-
-func newProcessor() query.Processor {
-	return &processor{}
-}
-
-// query.Processor interface implementation
-type processor struct {
-	url string
-
-	prettyPrintResponses bool
-}
-
-// query.Processor interface implementation
-func (p *processor) Init(workerNum int) {
-	p.url = vmURLs[workerNum%len(vmURLs)]
-	p.prettyPrintResponses = runner.DoPrintResponses()
-}
-
-// query.Processor interface implementation
-func (p *processor) ProcessQuery(q query.Query, isWarm bool) ([]*query.Stat, error) {
-	hq := q.(*query.HTTP)
-	lag, err := p.do(hq)
-	if err != nil {
-		return nil, err
+	opts = &victoriametrics.QueryOptions{
+		URLs:                 strings.Split(urls, ","),
+		PrettyPrintResponses: runner.DoPrintResponses(),
 	}
-	stat := query.GetStat()
-	stat.Init(q.HumanLabelName(), lag)
-	return []*query.Stat{stat}, nil
 }
 
-func (p *processor) do(q *query.HTTP) (float64, error) {
-	// populate a request with data from the Query:
-	req, err := http.NewRequest(string(q.Method), p.url+string(q.Path), nil)
-	if err != nil {
-		return 0, fmt.Errorf("error while creating request: %s", err)
-	}
-
-	start := time.Now()
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return 0, fmt.Errorf("query execution error: %s", err)
-	}
-	defer resp.Body.Close()
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return 0, fmt.Errorf("error while reading response body: %s", err)
-	}
-	if resp.StatusCode != http.StatusOK {
-		return 0, fmt.Errorf("non-200 statuscode received: %d; Body: %s", resp.StatusCode, string(body))
-	}
-	lag := float64(time.Since(start).Nanoseconds()) / 1e6 // milliseconds
-
-	// Pretty print JSON responses, if applicable:
-	if p.prettyPrintResponses {
-		var pretty bytes.Buffer
-		prefix := fmt.Sprintf("ID %d: ", q.GetID())
-		if err := json.Indent(&pretty, body, prefix, "  "); err != nil {
-			return lag, err
-		}
-		_, err = fmt.Fprintf(os.Stderr, "%s%s\n", prefix, pretty.Bytes())
-		if err != nil {
-			return lag, err
-		}
+func main() {
+	if err := runner.Run(&query.HTTPPool, victoriametrics.NewQueryProcessorCreate(opts)); err != nil {
+		log.Fatal(err)
 	}
-	return lag, nil
 }
@@ -0,0 +1,112 @@
+package load
+
+import (
+	"encoding/json"
+	"sync/atomic"
+	"time"
+
+	"github.com/bodhiye/tsbs/pkg/data/source"
+	"github.com/bodhiye/tsbs/pkg/distributed"
+)
+
+// JoinCoordinator joins the coordinator set with --coordinator, if any, as
+// an agent, and sets the data source to the shard of the data assigned to
+// the agent: a simulator generates the interleaved group of the agent, and
+// a file data source reads the file the coordinator assigned to the agent,
+// if any, or else its own file, which must then hold the shard of the
+// agent. It is called before the benchmark is created from the data
+// source, and the benchmark runner created from the config runs as the
+// agent. Only the first agent creates the database.
+func (c *BenchmarkRunnerConfig) JoinCoordinator() {
+	if c.Coordinator == "" || c.agent != nil {
+		return
+	}
+	agent, err := distributed.Join(c.Coordinator, distributed.KindLoad, c.AgentID)
+	if err != nil {
+		fatal("could not join coordinator %s: %v", c.Coordinator, err)
+		return
+	}
+	printFn("joined coordinator %s as agent %d of %d with id '%s'\n", c.Coordinator, agent.Agent, agent.Agents, agent.ID)
+	c.agent = agent
+	if agent.Agent != 0 {
+		c.DoCreateDB = false
+	}
+	ds := c.DataSource
+	switch {
+	case ds != nil && ds.Type == source.SimulatorDataSourceType:
+		ds.Simulator.InterleavedGroupID = agent.InterleavedGroupID
+		ds.Simulator.InterleavedNumGroups = agent.InterleavedNumGroups
+		printFn("simulating interleaved group %d of %d\n", agent.InterleavedGroupID, agent.InterleavedNumGroups)
+	case agent.File != "":
+		c.FileName = agent.File
+		if ds != nil {
+			ds.File = &source.FileDataSourceConfig{Location: agent.File}
+		}
+		printFn("loading file %s\n", agent.File)
+	default:
+		printFn("loading the whole input as the shard of the agent\n")
+	}
+}
+
+// barrier waits for all agents to reach the named barrier, when running as
+// an agent.
+func (l *CommonBenchmarkRunner) barrier(name string) {
+	if l.agent == nil {
+		return
+	}
+	if err := l.agent.Barrier(name); err != nil {
+		fatal("agent %d could not reach barrier '%s': %v", l.agent.Agent, name, err)
+	}
+}
+
+// share returns the share of the limit of the agent, as the limit is the
+// limit of all agents together.
+func (l *CommonBenchmarkRunner) share() uint64 {
+	if l.agent == nil {
+		return l.Limit
+	}
+	return l.agent.Share(l.Limit)
+}
+
+// reportProgress sends the number of metrics and rows loaded to the
+// coordinator periodically, until the returned function is called. The load
+// is stopped when the coordinator aborted the benchmark.
+func (l *CommonBenchmarkRunner) reportProgress() func() {
+	if l.agent == nil {
+		return func() {}
+	}
+	return l.agent.ReportProgress(func() distributed.Counters {
+		return distributed.Counters{
+			Metrics: atomic.LoadUint64(&l.metricCnt),
+			Rows:    atomic.LoadUint64(&l.rowCnt),
+		}
+	}, func(reason string) {
+		printFn("\ncoordinator aborted the benchmark: %s, stopping after the batches in flight are inserted\n", reason)
+		l.Stop()
+	})
+}
+
+// reportResult sends the results of the agent to the coordinator.
+func (l *CommonBenchmarkRunner) reportResult(result *LoaderTestResult, start, end time.Time) {
+	if l.agent == nil {
+		return
+	}
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		fatal("could not encode results: %v", err)
+		return
+	}
+	report := &distributed.Report{
+		StartTime: start.UnixMilli(),
+		EndTime:   end.UnixMilli(),
+		Counters:  distributed.Counters{Metrics: l.metricCnt, Rows: l.rowCnt},
+		Partial:   result.Partial,
+		Result:    encoded,
+	}
+	if latencies := result.BatchLatencies; latencies != nil && latencies.All != nil {
+		report.Latencies = map[string]string{"batch insert": latencies.All.Histogram}
+	}
+	if err := l.agent.Report(report); err != nil {
+		fatal("could not report results to coordinator %s: %v", l.Coordinator, err)
+	}
+}
@@ -0,0 +1,112 @@
+package load
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bodhiye/tsbs/pkg/data/source"
+	"github.com/bodhiye/tsbs/pkg/data/usecases/common"
+	"github.com/bodhiye/tsbs/pkg/distributed"
+)
+
+func TestShare(t *testing.T) {
+	l := GetBenchmarkRunner(BenchmarkRunnerConfig{Workers: 1, Limit: 8}).(*CommonBenchmarkRunner)
+	if got := l.share(); got != 8 {
+		t.Errorf("incorrect limit without a coordinator: got %d want 8", got)
+	}
+	l.agent = &distributed.Agent{Assignment: distributed.Assignment{Agent: 2, Agents: 3}}
+	if got := l.share(); got != 2 {
+		t.Errorf("incorrect share of the limit: got %d want 2", got)
+	}
+}
+
+func TestJoinCoordinator(t *testing.T) {
+	oldPrintFn := printFn
+	defer func() { printFn = oldPrintFn }()
+	printFn = func(string, ...interface{}) (int, error) { return 0, nil }
+
+	cases := []struct {
+		desc       string
+		files      []string
+		dataSource *source.DataSourceConfig
+		wantFile   string
+		wantGroup  uint
+		wantGroups uint
+	}{
+		{
+			desc:     "file assigned by the coordinator",
+			files:    []string{"/data/shard-0", "/data/shard-1"},
+			wantFile: "/data/shard-1",
+		},
+		{
+			desc:       "file data source assigned by the coordinator",
+			files:      []string{"/data/shard-0", "/data/shard-1"},
+			dataSource: &source.DataSourceConfig{Type: source.FileDataSourceType, File: &source.FileDataSourceConfig{Location: "/data/all"}},
+			wantFile:   "/data/shard-1",
+		},
+		{
+			desc:     "own file",
+			wantFile: "/data/own",
+		},
+		{
+			desc:       "simulator",
+			dataSource: &source.DataSourceConfig{Type: source.SimulatorDataSourceType, Simulator: &common.DataGeneratorConfig{InterleavedNumGroups: 1}},
+			wantFile:   "/data/own",
+			wantGroup:  1,
+			wantGroups: 2,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			coordinator, err := distributed.NewCoordinator(distributed.CoordinatorConfig{Agents: 2, Files: c.files})
+			if err != nil {
+				t.Fatalf("could not create coordinator: %v", err)
+			}
+			server := httptest.NewServer(coordinator.Handler())
+			defer server.Close()
+			address := server.Listener.Addr().String()
+
+			// the other agent, sorted first
+			joined := make(chan error)
+			go func() {
+				_, err := distributed.Join(address, distributed.KindLoad, "agent-a")
+				joined <- err
+			}()
+
+			conf := BenchmarkRunnerConfig{
+				Coordinator: address,
+				AgentID:     "agent-b",
+				DoCreateDB:  true,
+				FileName:    "/data/own",
+				DataSource:  c.dataSource,
+			}
+			conf.JoinCoordinator()
+			if err := <-joined; err != nil {
+				t.Fatalf("other agent could not join: %v", err)
+			}
+
+			if conf.agent == nil || conf.agent.Agent != 1 {
+				t.Fatalf("incorrect agent: %+v", conf.agent)
+			}
+			if conf.DoCreateDB {
+				t.Errorf("second agent creates the database")
+			}
+			if conf.FileName != c.wantFile {
+				t.Errorf("incorrect file: got %s want %s", conf.FileName, c.wantFile)
+			}
+			ds := c.dataSource
+			if ds == nil {
+				return
+			}
+			if ds.File != nil && ds.File.Location != c.wantFile {
+				t.Errorf("incorrect data source file: got %s want %s", ds.File.Location, c.wantFile)
+			}
+			if ds.Simulator != nil {
+				if ds.Simulator.InterleavedGroupID != c.wantGroup || ds.Simulator.InterleavedNumGroups != c.wantGroups {
+					t.Errorf("incorrect interleaved group: got %d of %d want %d of %d",
+						ds.Simulator.InterleavedGroupID, ds.Simulator.InterleavedNumGroups, c.wantGroup, c.wantGroups)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package load
+
+import (
+	"sync"
+	"time"
+
+	"github.com/HdrHistogram/hdrhistogram-go"
+)
+
+const (
+	// batch insert latencies are recorded in microseconds, from 1us up to an
+	// hour with 3 significant digits
+	batchLatencyMin       = 1
+	batchLatencyMax       = 3600 * 1000 * 1000
+	batchLatencySigDigits = 3
+	usecsPerMilli         = 1e3
+)
+
+// BatchLatencyStats summarizes the latencies of batch inserts. All latencies
+// are in milliseconds.
+type BatchLatencyStats struct {
+	Count  int64   `json:"count"`
+	Min    float64 `json:"min"`
+	Mean   float64 `json:"mean"`
+	Max    float64 `json:"max"`
+	StdDev float64 `json:"stddev"`
+	P50    float64 `json:"p50"`
+	P90    float64 `json:"p90"`
+	P95    float64 `json:"p95"`
+	P99    float64 `json:"p99"`
+	P999   float64 `json:"p999"`
+	// Histogram is the base64 encoded, compressed HDR histogram of the
+	// latencies in microseconds, which can be decoded with hdrhistogram.Decode
+	Histogram string `json:"histogram,omitempty"`
+}
+
+// BatchLatencyResults holds the batch insert latencies of all workers
+// combined and of each worker.
+type BatchLatencyResults struct {
+	All     *BatchLatencyStats   `json:"All"`
+	Workers []*BatchLatencyStats `json:"Workers"`
+}
+
+func newBatchLatencyHistogram() *hdrhistogram.Histogram {
+	return hdrhistogram.New(batchLatencyMin, batchLatencyMax, batchLatencySigDigits)
+}
+
+func newBatchLatencyStats(h *hdrhistogram.Histogram, withHistogram bool) (*BatchLatencyStats, error) {
+	s := &BatchLatencyStats{
+		Count:  h.TotalCount(),
+		Min:    float64(h.Min()) / usecsPerMilli,
+		Mean:   h.Mean() / usecsPerMilli,
+		Max:    float64(h.Max()) / usecsPerMilli,
+		StdDev: h.StdDev() / usecsPerMilli,
+		P50:    float64(h.ValueAtQuantile(50.0)) / usecsPerMilli,
+		P90:    float64(h.ValueAtQuantile(90.0)) / usecsPerMilli,
+		P95:    float64(h.ValueAtQuantile(95.0)) / usecsPerMilli,
+		P99:    float64(h.ValueAtQuantile(99.0)) / usecsPerMilli,
+		P999:   float64(h.ValueAtQuantile(99.9)) / usecsPerMilli,
+	}
+	if withHistogram {
+		encoded, err := h.Encode(hdrhistogram.V2CompressedEncodingCookieBase)
+		if err != nil {
+			return nil, err
+		}
+		s.Histogram = string(encoded)
+	}
+	return s, nil
+}
+
+// workerLatencies holds the batch insert latencies of a single worker. The
+// latencies recorded since the last collection are kept in current and
+// merged into total on collection, so they can be reported per interval.
+type workerLatencies struct {
+	mu      sync.Mutex
+	current *hdrhistogram.Histogram
+	total   *hdrhistogram.Histogram
+}
+
+// batchLatencies records the time spent by each worker in inserting batches.
+type batchLatencies struct {
+	workers []*workerLatencies
+	// mu guards the combined histogram, which is only updated on collect
+	mu  sync.Mutex
+	all *hdrhistogram.Histogram
+}
+
+func newBatchLatencies(workers uint) *batchLatencies {
+	l := &batchLatencies{
+		workers: make([]*workerLatencies, workers),
+		all:     newBatchLatencyHistogram(),
+	}
+	for i := range l.workers {
+		l.workers[i] = &workerLatencies{
+			current: newBatchLatencyHistogram(),
+			total:   newBatchLatencyHistogram(),
+		}
+	}
+	return l
+}
+
+// record records the latency of a batch insert by a worker. It is a no-op
+// for a nil batchLatencies.
+func (l *batchLatencies) record(workerNum uint, took time.Duration) {
+	if l == nil || int(workerNum) >= len(l.workers) {
+		return
+	}
+	w := l.workers[workerNum]
+	w.mu.Lock()
+	w.current.RecordValue(took.Microseconds())
+	w.mu.Unlock()
+}
+
+// collect merges the latencies recorded since the last collection into the
+// totals, returning them combined for all workers.
+func (l *batchLatencies) collect() *hdrhistogram.Histogram {
+	interval := newBatchLatencyHistogram()
+	for _, w := range l.workers {
+		w.mu.Lock()
+		interval.Merge(w.current)
+		w.total.Merge(w.current)
+		w.current.Reset()
+		w.mu.Unlock()
+	}
+	l.mu.Lock()
+	l.all.Merge(interval)
+	l.mu.Unlock()
+	return interval
+}
+
+// summary collects the outstanding latencies and returns the latency stats
+// of all workers combined, without the encoded histogram. It returns nil for
+// a nil batchLatencies.
+func (l *batchLatencies) summary() *BatchLatencyStats {
+	if l == nil {
+		return nil
+	}
+	l.collect()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	// encoding is skipped, so no error can occur
+	s, _ := newBatchLatencyStats(l.all, false)
+	return s
+}
+
+// results collects the outstanding latencies and returns the latency stats
+// of all workers combined and per worker.
+func (l *batchLatencies) results() (*BatchLatencyResults, error) {
+	l.collect()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	all, err := newBatchLatencyStats(l.all, true)
+	if err != nil {
+		return nil, err
+	}
+	res := &BatchLatencyResults{All: all}
+	for _, w := range l.workers {
+		w.mu.Lock()
+		s, err := newBatchLatencyStats(w.total, false)
+		w.mu.Unlock()
+		if err != nil {
+			return nil, err
+		}
+		res.Workers = append(res.Workers, s)
+	}
+	return res, nil
+}
@@ -0,0 +1,62 @@
+package load
+
+import (
+	"testing"
+	"time"
+
+	"github.com/HdrHistogram/hdrhistogram-go"
+)
+
+func TestBatchLatencies(t *testing.T) {
+	l := newBatchLatencies(2)
+	for i := 1; i <= 100; i++ {
+		l.record(0, time.Duration(i)*time.Millisecond)
+	}
+	l.record(1, 500*time.Millisecond)
+	// out of range workers are ignored
+	l.record(2, time.Second)
+
+	interval := l.collect()
+	if got := interval.TotalCount(); got != 101 {
+		t.Errorf("incorrect interval count: got %d want %d", got, 101)
+	}
+	if got := l.collect().TotalCount(); got != 0 {
+		t.Errorf("incorrect count of empty interval: got %d want %d", got, 0)
+	}
+
+	l.record(1, 200*time.Millisecond)
+	res, err := l.results()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := res.All.Count; got != 102 {
+		t.Errorf("incorrect total count: got %d want %d", got, 102)
+	}
+	if got := res.All.Max; got < 499 || got > 501 {
+		t.Errorf("incorrect max: got %v want %v", got, 500)
+	}
+	if len(res.Workers) != 2 {
+		t.Fatalf("incorrect number of workers: got %d want %d", len(res.Workers), 2)
+	}
+	if got := res.Workers[0]; got.Count != 100 || got.P50 < 49.9 || got.P50 > 50.1 {
+		t.Errorf("incorrect worker 0 stats: got %+v", got)
+	}
+	if got := res.Workers[1]; got.Count != 2 || got.Min < 199.9 || got.Min > 200.1 {
+		t.Errorf("incorrect worker 1 stats: got %+v", got)
+	}
+	decoded, err := hdrhistogram.Decode([]byte(res.All.Histogram))
+	if err != nil {
+		t.Fatalf("could not decode histogram: %v", err)
+	}
+	if got := decoded.TotalCount(); got != 102 {
+		t.Errorf("incorrect decoded histogram count: got %d want %d", got, 102)
+	}
+}
+
+func TestBatchLatenciesNil(t *testing.T) {
+	var l *batchLatencies
+	l.record(0, time.Second)
+	if s := l.summary(); s != nil {
+		t.Errorf("summary of nil batchLatencies is not nil: %+v", s)
+	}
+}
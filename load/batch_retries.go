@@ -0,0 +1,197 @@
+package load
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/bodhiye/tsbs/pkg/data/usecases/common"
+	"github.com/bodhiye/tsbs/pkg/targets"
+)
+
+const (
+	// DefaultRetryBackoff is the default time to wait before the first
+	// retry of a failed batch insert
+	DefaultRetryBackoff = time.Second
+	// DefaultMaxRetryBackoff is the default maximum time to wait between
+	// retries of a failed batch insert
+	DefaultMaxRetryBackoff = 30 * time.Second
+)
+
+// WorkerBatchErrors holds the number of batch inserts of a worker that were
+// retried, and the number of batches that could not be inserted at all.
+type WorkerBatchErrors struct {
+	Retried uint64 `json:"retried"`
+	Failed  uint64 `json:"failed"`
+}
+
+// BatchErrorResults holds the retried and failed batches of all workers
+// combined and of each worker.
+type BatchErrorResults struct {
+	All     WorkerBatchErrors   `json:"All"`
+	Workers []WorkerBatchErrors `json:"Workers"`
+}
+
+// batchErrors counts the retried and failed batches of each worker.
+type batchErrors struct {
+	workers []WorkerBatchErrors
+}
+
+func newBatchErrors(workers uint) *batchErrors {
+	return &batchErrors{workers: make([]WorkerBatchErrors, workers)}
+}
+
+func (e *batchErrors) retried(workerNum uint) {
+	atomic.AddUint64(&e.workers[workerNum].Retried, 1)
+}
+
+func (e *batchErrors) failed(workerNum uint) {
+	atomic.AddUint64(&e.workers[workerNum].Failed, 1)
+}
+
+// totals returns the retried and failed batches of all workers combined.
+func (e *batchErrors) totals() WorkerBatchErrors {
+	var all WorkerBatchErrors
+	if e == nil {
+		return all
+	}
+	for i := range e.workers {
+		all.Retried += atomic.LoadUint64(&e.workers[i].Retried)
+		all.Failed += atomic.LoadUint64(&e.workers[i].Failed)
+	}
+	return all
+}
+
+func (e *batchErrors) results() *BatchErrorResults {
+	if e == nil {
+		return nil
+	}
+	workers := make([]WorkerBatchErrors, len(e.workers))
+	for i := range e.workers {
+		workers[i] = WorkerBatchErrors{
+			Retried: atomic.LoadUint64(&e.workers[i].Retried),
+			Failed:  atomic.LoadUint64(&e.workers[i].Failed),
+		}
+	}
+	return &BatchErrorResults{All: e.totals(), Workers: workers}
+}
+
+// deadLetterWriter writes batches that could not be inserted to a file.
+// Batches that implement targets.WriterToBatch are written in the format
+// they were read in, so the file can be loaded again. For formats with a
+// header, like TimescaleDB's, the header of the data is written first.
+type deadLetterWriter struct {
+	mu   sync.Mutex
+	file *os.File
+	w    *bufio.Writer
+	// headers returns the headers of the data, if the format has any
+	headers      func() *common.GeneratedDataHeaders
+	wroteHeaders bool
+}
+
+func newDeadLetterWriter(fileName string) (*deadLetterWriter, error) {
+	f, err := os.Create(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open dead-letter file for write %s: %v", fileName, err)
+	}
+	return &deadLetterWriter{file: f, w: bufio.NewWriter(f)}, nil
+}
+
+func (d *deadLetterWriter) write(batch targets.Batch) error {
+	wb, ok := batch.(targets.WriterToBatch)
+	if !ok {
+		return fmt.Errorf("batch of %d items can not be written to the dead-letter file", batch.Len())
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.wroteHeaders && d.headers != nil {
+		if h := d.headers(); h != nil {
+			targets.WriteHeaders(d.w, h)
+		}
+		d.wroteHeaders = true
+	}
+	_, err := wb.WriteTo(d.w)
+	return err
+}
+
+func (d *deadLetterWriter) close() error {
+	if err := d.w.Flush(); err != nil {
+		return err
+	}
+	return d.file.Close()
+}
+
+// validateBatchRetries checks that the target of b supports the retries and
+// the dead-letter file that are set, so they don't silently do nothing.
+// Retries need processors that implement targets.ProcessorWithError, and the
+// dead-letter file also needs batches that implement targets.WriterToBatch.
+func (l *CommonBenchmarkRunner) validateBatchRetries(b targets.Benchmark) error {
+	if l.MaxRetries == 0 && l.DeadLetterFile == "" {
+		return nil
+	}
+	if _, ok := b.GetProcessor().(targets.ProcessorWithError); !ok {
+		return errors.New("max-retries and dead-letter-file are not supported by this target, as it does not report failed batch inserts")
+	}
+	if l.DeadLetterFile != "" {
+		if _, ok := b.GetBatchFactory().New().(targets.WriterToBatch); !ok {
+			return errors.New("dead-letter-file is not supported by this target, as its batches can not be written to a file")
+		}
+	}
+	return nil
+}
+
+// processBatch inserts a batch with the worker's processor. Processors that
+// implement targets.ProcessorWithError have failed batches retried up to
+// MaxRetries times, with an exponential backoff starting at RetryBackoff.
+// Batches that still fail are written to the dead-letter file if set,
+// otherwise the load is aborted. The metrics and rows of the part of a
+// batch inserted by a failed attempt are counted too, as only the rest of
+// it is retried. The latency of every insert attempt is recorded on its
+// own, without the backoffs in between.
+func (l *CommonBenchmarkRunner) processBatch(proc targets.Processor, batch targets.Batch, workerNum uint) (uint64, uint64) {
+	procWithErr, ok := proc.(targets.ProcessorWithError)
+	if !ok {
+		start := time.Now()
+		metricCnt, rowCnt := proc.ProcessBatch(batch, l.DoLoad)
+		l.latencies.record(workerNum, time.Since(start))
+		return metricCnt, rowCnt
+	}
+
+	backoff := l.RetryBackoff
+	var metricCnt, rowCnt uint64
+	for attempt := uint(0); ; attempt++ {
+		start := time.Now()
+		metrics, rows, err := procWithErr.ProcessBatchWithError(batch, l.DoLoad)
+		l.latencies.record(workerNum, time.Since(start))
+		metricCnt += metrics
+		rowCnt += rows
+		if err == nil {
+			return metricCnt, rowCnt
+		}
+		if attempt < l.MaxRetries {
+			l.batchErrors.retried(workerNum)
+			printFn("[worker %d] batch insert failed, retrying in %v: %v\n", workerNum, backoff, err)
+			l.sleepFn(backoff)
+			backoff *= 2
+			if backoff > l.MaxRetryBackoff {
+				backoff = l.MaxRetryBackoff
+			}
+			continue
+		}
+
+		l.batchErrors.failed(workerNum)
+		if l.deadLetters == nil {
+			fatal("[worker %d] batch insert failed after %d retries: %v", workerNum, attempt, err)
+			return metricCnt, rowCnt
+		}
+		printFn("[worker %d] batch insert failed after %d retries, writing it to the dead-letter file: %v\n", workerNum, attempt, err)
+		if err := l.deadLetters.write(batch); err != nil {
+			fatal("[worker %d] %v", workerNum, err)
+		}
+		return metricCnt, rowCnt
+	}
+}
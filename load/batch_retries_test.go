@@ -0,0 +1,285 @@
+package load
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bodhiye/tsbs/pkg/data/usecases/common"
+	"github.com/bodhiye/tsbs/pkg/targets"
+)
+
+// failingProcessor fails the first failures inserts of every batch.
+type failingProcessor struct {
+	testProcessor
+	failures int
+	attempts int
+}
+
+func (p *failingProcessor) ProcessBatchWithError(targets.Batch, bool) (uint64, uint64, error) {
+	p.attempts++
+	if p.attempts <= p.failures {
+		return 0, 0, errors.New("insert failed")
+	}
+	return 1, 1, nil
+}
+
+// partialProcessor inserts part of every batch on its first attempt and
+// fails, then inserts the rest.
+type partialProcessor struct {
+	testProcessor
+	attempts int
+}
+
+func (p *partialProcessor) ProcessBatchWithError(targets.Batch, bool) (uint64, uint64, error) {
+	p.attempts++
+	if p.attempts == 1 {
+		return 2, 1, errors.New("insert failed")
+	}
+	return 3, 2, nil
+}
+
+type writerToBatch struct {
+	testBatch
+	data string
+}
+
+func (b *writerToBatch) WriteTo(w io.Writer) (int64, error) {
+	n, err := io.WriteString(w, b.data)
+	return int64(n), err
+}
+
+func newRetryTestRunner(c BenchmarkRunnerConfig) (*CommonBenchmarkRunner, *[]time.Duration) {
+	c.Workers = 2
+	l := GetBenchmarkRunner(c).(*CommonBenchmarkRunner)
+	sleeps := &[]time.Duration{}
+	l.sleepFn = func(d time.Duration) {
+		*sleeps = append(*sleeps, d)
+	}
+	return l, sleeps
+}
+
+func TestProcessBatchRetries(t *testing.T) {
+	oldPrintFn := printFn
+	defer func() { printFn = oldPrintFn }()
+	printFn = func(string, ...interface{}) (int, error) { return 0, nil }
+
+	l, sleeps := newRetryTestRunner(BenchmarkRunnerConfig{
+		MaxRetries:      5,
+		RetryBackoff:    time.Second,
+		MaxRetryBackoff: 3 * time.Second,
+	})
+	p := &failingProcessor{failures: 4}
+	metricCnt, rowCnt := l.processBatch(p, &testBatch{}, 1)
+	if metricCnt != 1 || rowCnt != 1 {
+		t.Errorf("incorrect counts after retries: got %d metrics, %d rows", metricCnt, rowCnt)
+	}
+	want := []time.Duration{time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second}
+	if fmt.Sprint(*sleeps) != fmt.Sprint(want) {
+		t.Errorf("incorrect backoffs: got %v want %v", *sleeps, want)
+	}
+	results := l.batchErrors.results()
+	if results.All.Retried != 4 || results.All.Failed != 0 {
+		t.Errorf("incorrect totals: %+v", results.All)
+	}
+	if results.Workers[1].Retried != 4 || results.Workers[0].Retried != 0 {
+		t.Errorf("retries not counted for the right worker: %+v", results.Workers)
+	}
+	if got := l.latencies.summary().Count; got != 5 {
+		t.Errorf("incorrect number of insert attempt latencies: got %d want %d", got, 5)
+	}
+}
+
+func TestProcessBatchRetriesPartialInsert(t *testing.T) {
+	oldPrintFn := printFn
+	defer func() { printFn = oldPrintFn }()
+	printFn = func(string, ...interface{}) (int, error) { return 0, nil }
+
+	l, _ := newRetryTestRunner(BenchmarkRunnerConfig{MaxRetries: 1})
+	metricCnt, rowCnt := l.processBatch(&partialProcessor{}, &testBatch{}, 0)
+	if metricCnt != 5 || rowCnt != 3 {
+		t.Errorf("incorrect counts after partial insert: got %d metrics, %d rows want 5 metrics, 3 rows", metricCnt, rowCnt)
+	}
+}
+
+func TestProcessBatchFails(t *testing.T) {
+	oldPrintFn, oldFatal := printFn, fatal
+	defer func() { printFn, fatal = oldPrintFn, oldFatal }()
+	printFn = func(string, ...interface{}) (int, error) { return 0, nil }
+	fatalCalled := false
+	fatal = func(string, ...interface{}) { fatalCalled = true }
+
+	l, sleeps := newRetryTestRunner(BenchmarkRunnerConfig{MaxRetries: 2})
+	metricCnt, _ := l.processBatch(&failingProcessor{failures: 10}, &testBatch{}, 0)
+	if !fatalCalled {
+		t.Errorf("fatal not called for batch that failed without a dead-letter file")
+	}
+	if metricCnt != 0 {
+		t.Errorf("failed batch counted %d metrics", metricCnt)
+	}
+	if len(*sleeps) != 2 {
+		t.Errorf("expected 2 retries, got %d", len(*sleeps))
+	}
+	if e := l.batchErrors.totals(); e.Retried != 2 || e.Failed != 1 {
+		t.Errorf("incorrect totals: %+v", e)
+	}
+}
+
+func TestProcessBatchDeadLetter(t *testing.T) {
+	oldPrintFn, oldFatal := printFn, fatal
+	defer func() { printFn, fatal = oldPrintFn, oldFatal }()
+	printFn = func(string, ...interface{}) (int, error) { return 0, nil }
+	fatal = func(format string, args ...interface{}) {
+		t.Errorf("fatal called unexpectedly: "+format, args...)
+	}
+
+	dir, err := ioutil.TempDir("", "dead_letter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fileName := filepath.Join(dir, "failed.txt")
+
+	l, _ := newRetryTestRunner(BenchmarkRunnerConfig{DeadLetterFile: fileName})
+	l.processBatch(&failingProcessor{failures: 1}, &writerToBatch{data: "first\n"}, 0)
+	l.processBatch(&failingProcessor{failures: 0}, &writerToBatch{data: "ok\n"}, 0)
+	l.processBatch(&failingProcessor{failures: 1}, &writerToBatch{data: "second\n"}, 1)
+	if err := l.deadLetters.close(); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "first\nsecond\n" {
+		t.Errorf("incorrect dead-letter file contents: %q", got)
+	}
+	if e := l.batchErrors.totals(); e.Retried != 0 || e.Failed != 2 {
+		t.Errorf("incorrect totals: %+v", e)
+	}
+}
+
+func TestProcessBatchDeadLetterHeaders(t *testing.T) {
+	oldPrintFn, oldFatal := printFn, fatal
+	defer func() { printFn, fatal = oldPrintFn, oldFatal }()
+	printFn = func(string, ...interface{}) (int, error) { return 0, nil }
+	fatal = func(format string, args ...interface{}) {
+		t.Errorf("fatal called unexpectedly: "+format, args...)
+	}
+
+	dir, err := ioutil.TempDir("", "dead_letter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fileName := filepath.Join(dir, "failed.txt")
+
+	l, _ := newRetryTestRunner(BenchmarkRunnerConfig{DeadLetterFile: fileName})
+	l.deadLetters.headers = func() *common.GeneratedDataHeaders {
+		return &common.GeneratedDataHeaders{
+			TagKeys:   []string{"hostname"},
+			TagTypes:  []string{"string"},
+			FieldKeys: map[string][]string{"cpu": {"usage_user"}},
+		}
+	}
+	l.processBatch(&failingProcessor{failures: 1}, &writerToBatch{data: "first\n"}, 0)
+	l.processBatch(&failingProcessor{failures: 1}, &writerToBatch{data: "second\n"}, 1)
+	if err := l.deadLetters.close(); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "tags,hostname string\ncpu,usage_user\n\nfirst\nsecond\n"
+	if string(got) != want {
+		t.Errorf("incorrect dead-letter file contents: got %q want %q", got, want)
+	}
+}
+
+func TestProcessBatchWithoutError(t *testing.T) {
+	l, _ := newRetryTestRunner(BenchmarkRunnerConfig{MaxRetries: 2})
+	metricCnt, rowCnt := l.processBatch(&testProcessor{}, &testBatch{}, 0)
+	if metricCnt != 1 || rowCnt != 0 {
+		t.Errorf("incorrect counts: got %d metrics, %d rows", metricCnt, rowCnt)
+	}
+}
+
+// retryBenchmark is a benchmark with a single processor and batch, used to
+// check the retry support of its target.
+type retryBenchmark struct {
+	testBenchmark
+	processor targets.Processor
+	batch     targets.Batch
+}
+
+func (b *retryBenchmark) GetProcessor() targets.Processor       { return b.processor }
+func (b *retryBenchmark) GetBatchFactory() targets.BatchFactory { return b }
+func (b *retryBenchmark) New() targets.Batch                    { return b.batch }
+
+func TestValidateBatchRetries(t *testing.T) {
+	cases := []struct {
+		desc      string
+		config    BenchmarkRunnerConfig
+		processor targets.Processor
+		batch     targets.Batch
+		wantErr   bool
+	}{
+		{
+			desc:      "no retries",
+			processor: &testProcessor{},
+			batch:     &testBatch{},
+		},
+		{
+			desc:      "retries without errors",
+			config:    BenchmarkRunnerConfig{MaxRetries: 2},
+			processor: &testProcessor{},
+			batch:     &testBatch{},
+			wantErr:   true,
+		},
+		{
+			desc:      "retries with errors",
+			config:    BenchmarkRunnerConfig{MaxRetries: 2},
+			processor: &failingProcessor{},
+			batch:     &testBatch{},
+		},
+		{
+			desc:      "dead-letter file without errors",
+			config:    BenchmarkRunnerConfig{DeadLetterFile: "failed.txt"},
+			processor: &testProcessor{},
+			batch:     &writerToBatch{},
+			wantErr:   true,
+		},
+		{
+			desc:      "dead-letter file without writer",
+			config:    BenchmarkRunnerConfig{DeadLetterFile: "failed.txt"},
+			processor: &failingProcessor{},
+			batch:     &testBatch{},
+			wantErr:   true,
+		},
+		{
+			desc:      "dead-letter file with errors and writer",
+			config:    BenchmarkRunnerConfig{DeadLetterFile: "failed.txt"},
+			processor: &failingProcessor{},
+			batch:     &writerToBatch{},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			l := &CommonBenchmarkRunner{BenchmarkRunnerConfig: c.config}
+			err := l.validateBatchRetries(&retryBenchmark{processor: c.processor, batch: c.batch})
+			if c.wantErr && err == nil {
+				t.Errorf("expected an error")
+			} else if !c.wantErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
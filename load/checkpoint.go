@@ -0,0 +1,431 @@
+package load
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/bodhiye/tsbs/pkg/data"
+	"github.com/bodhiye/tsbs/pkg/data/source"
+	"github.com/bodhiye/tsbs/pkg/targets"
+)
+
+// DefaultCheckpointInterval is the default time between two checkpoints
+const DefaultCheckpointInterval = 30 * time.Second
+
+// Checkpoint is the position of a load and the cumulative counters up to
+// that position. All points up to Points, counted from the start of the
+// data, have been inserted, so a resumed load skips them. Offset is the
+// position after them in the input of data sources reading a file, and
+// Simulated in the points of the simulator of data sources generating
+// them, so a resumed load skips them without reading them again.
+type Checkpoint struct {
+	DBName    string                     `json:"db-name"`
+	Source    CheckpointSource           `json:"source"`
+	Points    uint64                     `json:"points"`
+	Offset    int64                      `json:"offset,omitempty"`
+	Simulated *targets.SimulatorPosition `json:"simulated,omitempty"`
+	Metrics   uint64                     `json:"metrics"`
+	Rows      uint64                     `json:"rows"`
+	Time      int64                      `json:"time"`
+}
+
+// CheckpointSource identifies the data a load reads, so a load is only
+// resumed from a checkpoint of the same data.
+type CheckpointSource struct {
+	Type                 string `json:"type"`
+	File                 string `json:"file,omitempty"`
+	Use                  string `json:"use-case,omitempty"`
+	Seed                 int64  `json:"seed,omitempty"`
+	Scale                uint64 `json:"scale,omitempty"`
+	TimeStart            string `json:"timestamp-start,omitempty"`
+	TimeEnd              string `json:"timestamp-end,omitempty"`
+	InterleavedGroupID   uint   `json:"interleaved-generation-group-id,omitempty"`
+	InterleavedNumGroups uint   `json:"interleaved-generation-groups,omitempty"`
+}
+
+// checkpointSource returns the identity of the data source of the load,
+// which is the file named by --file for the tsbs_load_* commands.
+func (l *CommonBenchmarkRunner) checkpointSource() (CheckpointSource, error) {
+	ds := l.DataSource
+	if ds == nil {
+		ds = &source.DataSourceConfig{
+			Type: source.FileDataSourceType,
+			File: &source.FileDataSourceConfig{Location: l.FileName},
+		}
+	}
+	if ds.Type != source.SimulatorDataSourceType {
+		var file string
+		if ds.File != nil && ds.File.Location != "" {
+			var err error
+			if file, err = filepath.Abs(ds.File.Location); err != nil {
+				return CheckpointSource{}, err
+			}
+		}
+		return CheckpointSource{Type: ds.Type, File: file}, nil
+	}
+	sim := ds.Simulator
+	return CheckpointSource{
+		Type:                 ds.Type,
+		Use:                  sim.Use,
+		Seed:                 sim.Seed,
+		Scale:                sim.Scale,
+		TimeStart:            sim.TimeStart,
+		TimeEnd:              sim.TimeEnd,
+		InterleavedGroupID:   sim.InterleavedGroupID,
+		InterleavedNumGroups: sim.InterleavedNumGroups,
+	}, nil
+}
+
+func readCheckpoint(fileName string) (*Checkpoint, error) {
+	b, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read checkpoint file %s: %v", fileName, err)
+	}
+	var c Checkpoint
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, fmt.Errorf("cannot parse checkpoint file %s: %v", fileName, err)
+	}
+	return &c, nil
+}
+
+// writeCheckpoint replaces the checkpoint file, writing to a temporary file
+// first so a crash never leaves a truncated checkpoint behind.
+func writeCheckpoint(fileName string, c *Checkpoint) error {
+	b, err := json.MarshalIndent(c, "", " ")
+	if err != nil {
+		return err
+	}
+	tmp := fileName + ".tmp"
+	if err := ioutil.WriteFile(tmp, b, 0644); err != nil {
+		return fmt.Errorf("cannot write checkpoint file %s: %v", tmp, err)
+	}
+	return os.Rename(tmp, fileName)
+}
+
+// batchRange holds the sequence numbers of the first and last point of a
+// batch, the positions in the data source before the first point and after
+// the last one, and the metrics and rows it inserted.
+type batchRange struct {
+	first, last   uint64
+	start, end    sourcePosition
+	metrics, rows uint64
+}
+
+// sourcePosition is a position in a data source, the offset in its input or
+// its position in the points of its simulator.
+type sourcePosition struct {
+	offset    int64
+	simulated targets.SimulatorPosition
+}
+
+// positionedDataSource keeps track of the position in the data source src of
+// the points read from ds, which reads them from src, for the checkpoints to
+// save the position a resumed load skips to.
+type positionedDataSource struct {
+	targets.DataSource
+	offsets   targets.OffsetDataSource
+	simulated targets.SimulatedDataSource
+	// prev is the position before the last point read, and cur the
+	// position after it
+	prev, cur sourcePosition
+}
+
+// newPositionedDataSource returns a positionedDataSource reading ds, or nil
+// if src keeps track of neither an offset nor a simulator position.
+func newPositionedDataSource(ds, src targets.DataSource) *positionedDataSource {
+	d := &positionedDataSource{DataSource: ds}
+	if sim, ok := src.(targets.SimulatedDataSource); ok {
+		d.simulated = sim
+	} else if off, ok := src.(targets.OffsetDataSource); ok {
+		d.offsets = off
+	} else {
+		return nil
+	}
+	d.cur = d.position()
+	return d
+}
+
+func (d *positionedDataSource) NextItem() data.LoadedPoint {
+	d.prev = d.cur
+	item := d.DataSource.NextItem()
+	d.cur = d.position()
+	return item
+}
+
+func (d *positionedDataSource) position() sourcePosition {
+	if d.simulated != nil {
+		return sourcePosition{simulated: d.simulated.SimulatorPosition()}
+	}
+	return sourcePosition{offset: d.offsets.Offset()}
+}
+
+// checkpointer tracks which points of the data have been inserted. Batches
+// are inserted concurrently and may complete out of order, and with
+// hash-workers the points of a batch are not contiguous, so the position of
+// a checkpoint is the point before the first point of the oldest batch that
+// is still being filled or inserted.
+type checkpointer struct {
+	mu       sync.Mutex
+	saveMu   sync.Mutex
+	fileName string
+	// skipped is the number of points skipped when resuming
+	skipped uint64
+	// acked holds the position and counters of the last checkpoint
+	acked Checkpoint
+	// batches holds the ranges of the batches that are filled or waiting
+	// for a worker. They are looked up by identity, so they are removed as
+	// soon as a worker takes the batch, before a target can reuse it.
+	batches  map[targets.Batch]*batchRange
+	pending  map[*batchRange]struct{}
+	done     []*batchRange
+	lastDone uint64
+	// lastDoneEnd is the position in the data source after lastDone
+	lastDoneEnd sourcePosition
+	// source is the data source the scanner reads, nil if the positions in
+	// the data source are not saved
+	source *positionedDataSource
+}
+
+func newCheckpointer(fileName, dbName string, src CheckpointSource, resumed *Checkpoint) *checkpointer {
+	cp := &checkpointer{
+		fileName: fileName,
+		acked:    Checkpoint{DBName: dbName, Source: src},
+		batches:  make(map[targets.Batch]*batchRange),
+		pending:  make(map[*batchRange]struct{}),
+	}
+	if resumed != nil {
+		cp.skipped = resumed.Points
+		cp.acked = *resumed
+		cp.lastDone = resumed.Points
+		cp.lastDoneEnd.offset = resumed.Offset
+		if resumed.Simulated != nil {
+			cp.lastDoneEnd.simulated = *resumed.Simulated
+		}
+	}
+	return cp
+}
+
+// started registers a batch when its first point, the seq-th point read by
+// the scanner, is appended to it.
+func (cp *checkpointer) started(b targets.Batch, seq uint64) {
+	if cp == nil {
+		return
+	}
+	r := &batchRange{first: cp.skipped + seq}
+	if cp.source != nil {
+		r.start = cp.source.prev
+	}
+	cp.mu.Lock()
+	cp.batches[b] = r
+	cp.pending[r] = struct{}{}
+	cp.mu.Unlock()
+}
+
+// sent records the seq-th point read by the scanner as the last point of a
+// batch sent to the workers.
+func (cp *checkpointer) sent(b targets.Batch, seq uint64) {
+	if cp == nil {
+		return
+	}
+	cp.mu.Lock()
+	if r, ok := cp.batches[b]; ok {
+		r.last = cp.skipped + seq
+		if cp.source != nil {
+			r.end = cp.source.cur
+		}
+	}
+	cp.mu.Unlock()
+}
+
+// take returns the range of a batch a worker is about to insert.
+func (cp *checkpointer) take(b targets.Batch) *batchRange {
+	if cp == nil {
+		return nil
+	}
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	r := cp.batches[b]
+	delete(cp.batches, b)
+	return r
+}
+
+// inserted marks the range of a batch as inserted, with the metrics and rows
+// the batch inserted.
+func (cp *checkpointer) inserted(r *batchRange, metrics, rows uint64) {
+	if cp == nil || r == nil {
+		return
+	}
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	delete(cp.pending, r)
+	r.metrics, r.rows = metrics, rows
+	cp.done = append(cp.done, r)
+	if r.last > cp.lastDone {
+		cp.lastDone = r.last
+		cp.lastDoneEnd = r.end
+	}
+}
+
+// checkpoint advances the position to the point before the oldest pending
+// batch, adding the counters of the inserted batches that end before it.
+func (cp *checkpointer) checkpoint() Checkpoint {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	position, at := cp.lastDone, cp.lastDoneEnd
+	for r := range cp.pending {
+		if r.first-1 < position {
+			position, at = r.first-1, r.start
+		}
+	}
+	done := cp.done[:0]
+	for _, r := range cp.done {
+		if r.last > position {
+			done = append(done, r)
+			continue
+		}
+		cp.acked.Metrics += r.metrics
+		cp.acked.Rows += r.rows
+	}
+	cp.done = done
+	if position > cp.acked.Points {
+		cp.acked.Points = position
+		cp.setPosition(at)
+	}
+	cp.acked.Time = time.Now().Unix()
+	return cp.acked
+}
+
+// setPosition sets the position in the data source of the checkpoint.
+func (cp *checkpointer) setPosition(at sourcePosition) {
+	switch {
+	case cp.source == nil:
+	case cp.source.simulated != nil:
+		cp.acked.Simulated = &at.simulated
+	default:
+		cp.acked.Offset = at.offset
+	}
+}
+
+// save writes a checkpoint to the checkpoint file.
+func (cp *checkpointer) save() {
+	if cp == nil {
+		return
+	}
+	cp.saveMu.Lock()
+	defer cp.saveMu.Unlock()
+	c := cp.checkpoint()
+	if err := writeCheckpoint(cp.fileName, &c); err != nil {
+		fatal("%v", err)
+	}
+}
+
+// run saves a checkpoint every period, until done is closed.
+func (cp *checkpointer) run(period time.Duration, done <-chan struct{}) {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			cp.save()
+		case <-done:
+			return
+		}
+	}
+}
+
+// initCheckpoints sets up the checkpoints of the load. When resuming, the
+// checkpoint to resume from is read from the checkpoint file, unless there
+// is none yet, in which case the load starts from the beginning.
+func (l *CommonBenchmarkRunner) initCheckpoints() error {
+	if l.CheckpointEvery <= 0 {
+		l.CheckpointEvery = DefaultCheckpointInterval
+	}
+	src, err := l.checkpointSource()
+	if err != nil {
+		return err
+	}
+	if l.Resume {
+		_, err := os.Stat(l.CheckpointFile)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if err == nil {
+			c, err := readCheckpoint(l.CheckpointFile)
+			if err != nil {
+				return err
+			}
+			if c.DBName != l.DBName {
+				return fmt.Errorf("checkpoint file %s is for database '%s', not '%s'", l.CheckpointFile, c.DBName, l.DBName)
+			}
+			if c.Source != src {
+				return fmt.Errorf("checkpoint file %s is for different data: %+v, not %+v", l.CheckpointFile, c.Source, src)
+			}
+			l.resumed = c
+			// the database holds the data loaded before the checkpoint
+			l.DoCreateDB = false
+		}
+	}
+	l.checkpoints = newCheckpointer(l.CheckpointFile, l.DBName, src, l.resumed)
+	return nil
+}
+
+// resume skips the points inserted before the checkpoint the load resumes
+// from, so the scanner starts with the first point that has not been
+// inserted. Data sources reading a file skip to the offset of the
+// checkpoint and data sources generating the points fast-forward their
+// simulator to its position, others read the points and drop them. It
+// returns the data source to scan and the limit of the points left to read.
+func (l *CommonBenchmarkRunner) resume(ds targets.DataSource, limit uint64) (targets.DataSource, uint64) {
+	if l.resumed == nil {
+		return ds, limit
+	}
+	skip := l.resumed.Points
+	if limit > 0 && skip >= limit {
+		return emptyDataSource{ds}, 0
+	}
+	if err := l.skip(ds, skip); err != nil {
+		fatal("cannot resume from checkpoint: %v", err)
+		return emptyDataSource{ds}, 0
+	}
+	if limit > 0 {
+		return ds, limit - skip
+	}
+	return ds, 0
+}
+
+// skip skips the first points of the data source, up to the position of the
+// checkpoint the load resumes from.
+func (l *CommonBenchmarkRunner) skip(ds targets.DataSource, points uint64) error {
+	c := l.resumed
+	if sim, ok := ds.(targets.SimulatedDataSource); ok && c.Simulated != nil {
+		printFn("resuming from checkpoint, fast-forwarding the simulator past %d points already loaded, up to %s\n", points, c.Simulated.Time.Format(time.RFC3339))
+		return sim.FastForward(*c.Simulated)
+	}
+	if off, ok := ds.(targets.OffsetDataSource); ok && c.Offset > 0 {
+		printFn("resuming from checkpoint, skipping %d points already loaded, up to offset %d\n", points, c.Offset)
+		return off.SkipTo(c.Offset)
+	}
+	printFn("resuming from checkpoint, skipping %d points already loaded\n", points)
+	for i := uint64(0); i < points; i++ {
+		if ds.NextItem().Data == nil {
+			break
+		}
+	}
+	return nil
+}
+
+// emptyDataSource has no data, for loads resumed after the limit of points
+// was loaded.
+type emptyDataSource struct {
+	targets.DataSource
+}
+
+func (emptyDataSource) NextItem() data.LoadedPoint {
+	return data.LoadedPoint{}
+}
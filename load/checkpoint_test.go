@@ -0,0 +1,294 @@
+package load
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bodhiye/tsbs/pkg/data/source"
+	"github.com/bodhiye/tsbs/pkg/data/usecases/common"
+	"github.com/bodhiye/tsbs/pkg/targets"
+)
+
+// offsetDataSource is a countingDataSource whose items are 10 bytes long.
+type offsetDataSource struct {
+	countingDataSource
+}
+
+func (d *offsetDataSource) Offset() int64 {
+	return int64(d.items) * 10
+}
+
+func (d *offsetDataSource) SkipTo(offset int64) error {
+	if offset%10 != 0 {
+		return fmt.Errorf("offset %d is not at the start of an item", offset)
+	}
+	d.items = int(offset / 10)
+	return nil
+}
+
+// simulatedDataSource is a countingDataSource whose items are simulated a
+// minute apart.
+type simulatedDataSource struct {
+	countingDataSource
+}
+
+var simulatedStart = time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func (d *simulatedDataSource) SimulatorPosition() targets.SimulatorPosition {
+	return targets.SimulatorPosition{
+		Points: uint64(d.items),
+		Time:   simulatedStart.Add(time.Duration(d.items) * time.Minute),
+	}
+}
+
+func (d *simulatedDataSource) FastForward(pos targets.SimulatorPosition) error {
+	d.items = int(pos.Points)
+	return nil
+}
+
+func TestCheckpointerOutOfOrder(t *testing.T) {
+	cp := newCheckpointer("", "benchmark", CheckpointSource{}, nil)
+	batches := []*testBatch{{id: 1}, {id: 2}, {id: 3}}
+	for i, b := range batches {
+		cp.started(b, uint64(2*i+1))
+		cp.sent(b, uint64(2*i+2))
+	}
+	ranges := make([]*batchRange, len(batches))
+	for i, b := range batches {
+		ranges[i] = cp.take(b)
+	}
+
+	cp.inserted(ranges[2], 30, 3)
+	cp.inserted(ranges[1], 20, 2)
+	if c := cp.checkpoint(); c.Points != 0 || c.Metrics != 0 {
+		t.Errorf("checkpoint advanced past a batch that is not inserted: %+v", c)
+	}
+	cp.inserted(ranges[0], 10, 1)
+	if c := cp.checkpoint(); c.Points != 6 || c.Metrics != 60 || c.Rows != 6 {
+		t.Errorf("incorrect checkpoint after all batches were inserted: %+v", c)
+	}
+}
+
+func TestCheckpointerInterleaved(t *testing.T) {
+	// with hash-workers the points of batches are interleaved
+	cp := newCheckpointer("", "benchmark", CheckpointSource{}, &Checkpoint{DBName: "benchmark", Points: 10, Metrics: 100, Rows: 10})
+	a, b := &testBatch{id: 1}, &testBatch{id: 2}
+	cp.started(a, 1)
+	cp.started(b, 2)
+	cp.sent(a, 5)
+	cp.sent(b, 6)
+	ra, rb := cp.take(a), cp.take(b)
+
+	cp.inserted(ra, 3, 3)
+	if c := cp.checkpoint(); c.Points != 11 || c.Metrics != 100 {
+		t.Errorf("incorrect checkpoint with the second batch pending: %+v", c)
+	}
+	cp.inserted(rb, 3, 3)
+	if c := cp.checkpoint(); c.Points != 16 || c.Metrics != 106 || c.Rows != 16 {
+		t.Errorf("incorrect checkpoint after both batches were inserted: %+v", c)
+	}
+}
+
+func TestInitCheckpoints(t *testing.T) {
+	dir, err := ioutil.TempDir("", "checkpoint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fileName := filepath.Join(dir, "checkpoint.json")
+
+	c := BenchmarkRunnerConfig{DBName: "benchmark", Workers: 1, DoCreateDB: true, CheckpointFile: fileName, Resume: true}
+	l := GetBenchmarkRunner(c).(*CommonBenchmarkRunner)
+	if l.resumed != nil || !l.DoCreateDB {
+		t.Errorf("resumed without a checkpoint file")
+	}
+
+	src := CheckpointSource{Type: source.FileDataSourceType}
+	if err := writeCheckpoint(fileName, &Checkpoint{DBName: "benchmark", Source: src, Points: 5, Metrics: 50}); err != nil {
+		t.Fatal(err)
+	}
+	l = GetBenchmarkRunner(c).(*CommonBenchmarkRunner)
+	if l.resumed == nil || l.resumed.Points != 5 || l.resumed.Metrics != 50 {
+		t.Errorf("checkpoint not read: %+v", l.resumed)
+	}
+	if l.DoCreateDB {
+		t.Errorf("database created when resuming")
+	}
+
+	c.Resume = false
+	l = GetBenchmarkRunner(c).(*CommonBenchmarkRunner)
+	if l.resumed != nil {
+		t.Errorf("resumed without resume set")
+	}
+
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Errorf("did not panic for a checkpoint of another database")
+			}
+		}()
+		c.Resume = true
+		c.DBName = "other"
+		GetBenchmarkRunner(c)
+	}()
+
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Errorf("did not panic for a checkpoint of another file")
+			}
+		}()
+		c.DBName = "benchmark"
+		c.FileName = "other.dat"
+		GetBenchmarkRunner(c)
+	}()
+}
+
+func TestCheckpointSource(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sim := &common.DataGeneratorConfig{
+		BaseConfig: common.BaseConfig{
+			Use:       common.UseCaseDevops,
+			Seed:      123,
+			Scale:     10,
+			TimeStart: "2016-01-01T00:00:00Z",
+			TimeEnd:   "2016-01-02T00:00:00Z",
+		},
+		InterleavedNumGroups: 1,
+	}
+	cases := []struct {
+		desc string
+		c    BenchmarkRunnerConfig
+		want CheckpointSource
+	}{
+		{
+			desc: "file name",
+			c:    BenchmarkRunnerConfig{FileName: "data.dat"},
+			want: CheckpointSource{Type: source.FileDataSourceType, File: filepath.Join(wd, "data.dat")},
+		},
+		{
+			desc: "standard input",
+			c:    BenchmarkRunnerConfig{},
+			want: CheckpointSource{Type: source.FileDataSourceType},
+		},
+		{
+			desc: "file data source",
+			c: BenchmarkRunnerConfig{DataSource: &source.DataSourceConfig{
+				Type: source.FileDataSourceType,
+				File: &source.FileDataSourceConfig{Location: "/tmp/data.dat"},
+			}},
+			want: CheckpointSource{Type: source.FileDataSourceType, File: "/tmp/data.dat"},
+		},
+		{
+			desc: "simulator data source",
+			c: BenchmarkRunnerConfig{DataSource: &source.DataSourceConfig{
+				Type:      source.SimulatorDataSourceType,
+				Simulator: sim,
+			}},
+			want: CheckpointSource{
+				Type:                 source.SimulatorDataSourceType,
+				Use:                  common.UseCaseDevops,
+				Seed:                 123,
+				Scale:                10,
+				TimeStart:            "2016-01-01T00:00:00Z",
+				TimeEnd:              "2016-01-02T00:00:00Z",
+				InterleavedNumGroups: 1,
+			},
+		},
+	}
+	for _, c := range cases {
+		l := &CommonBenchmarkRunner{BenchmarkRunnerConfig: c.c}
+		got, err := l.checkpointSource()
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", c.desc, err)
+		} else if got != c.want {
+			t.Errorf("%s: incorrect source: got %+v want %+v", c.desc, got, c.want)
+		}
+	}
+}
+
+func TestCheckpointerPosition(t *testing.T) {
+	cases := []struct {
+		desc          string
+		src           targets.DataSource
+		wantOffset    int64
+		wantSimulated *targets.SimulatorPosition
+	}{
+		{desc: "no position", src: &countingDataSource{}},
+		{desc: "offset", src: &offsetDataSource{}, wantOffset: 20},
+		{
+			desc:          "simulator",
+			src:           &simulatedDataSource{},
+			wantSimulated: &targets.SimulatorPosition{Points: 2, Time: simulatedStart.Add(2 * time.Minute)},
+		},
+	}
+	for _, c := range cases {
+		cp := newCheckpointer("", "benchmark", CheckpointSource{}, nil)
+		ds := targets.DataSource(c.src)
+		if positioned := newPositionedDataSource(c.src, c.src); positioned != nil {
+			cp.source = positioned
+			ds = positioned
+		}
+		// the first two points are inserted, the next two are pending
+		a, b := &testBatch{id: 1}, &testBatch{id: 2}
+		ds.NextItem()
+		cp.started(a, 1)
+		ds.NextItem()
+		cp.sent(a, 2)
+		ds.NextItem()
+		cp.started(b, 3)
+		ds.NextItem()
+		cp.sent(b, 4)
+		cp.inserted(cp.take(a), 2, 2)
+		cp.take(b)
+
+		got := cp.checkpoint()
+		if got.Points != 2 || got.Offset != c.wantOffset {
+			t.Errorf("%s: incorrect position: got %d points at offset %d want 2 at offset %d", c.desc, got.Points, got.Offset, c.wantOffset)
+		}
+		if (got.Simulated == nil) != (c.wantSimulated == nil) ||
+			got.Simulated != nil && *got.Simulated != *c.wantSimulated {
+			t.Errorf("%s: incorrect simulator position: got %+v want %+v", c.desc, got.Simulated, c.wantSimulated)
+		}
+	}
+}
+
+func TestResume(t *testing.T) {
+	oldPrintFn := printFn
+	defer func() { printFn = oldPrintFn }()
+	printFn = func(string, ...interface{}) (int, error) { return 0, nil }
+
+	cases := []struct {
+		desc      string
+		ds        targets.DataSource
+		limit     uint64
+		wantLimit uint64
+		wantItem  interface{}
+	}{
+		{desc: "no limit", ds: &countingDataSource{}, limit: 0, wantLimit: 0, wantItem: 4},
+		{desc: "limit left", ds: &countingDataSource{}, limit: 5, wantLimit: 2, wantItem: 4},
+		{desc: "limit reached", ds: &countingDataSource{}, limit: 3, wantLimit: 0, wantItem: nil},
+		// the positions of the checkpoint differ from its points to tell how
+		// the points are skipped
+		{desc: "offset", ds: &offsetDataSource{}, limit: 0, wantLimit: 0, wantItem: 6},
+		{desc: "simulator", ds: &simulatedDataSource{}, limit: 0, wantLimit: 0, wantItem: 8},
+	}
+	for _, c := range cases {
+		l := GetBenchmarkRunner(BenchmarkRunnerConfig{Workers: 1}).(*CommonBenchmarkRunner)
+		l.resumed = &Checkpoint{Points: 3, Offset: 50, Simulated: &targets.SimulatorPosition{Points: 7}}
+		ds, limit := l.resume(c.ds, c.limit)
+		if limit != c.wantLimit {
+			t.Errorf("%s: incorrect limit: got %d want %d", c.desc, limit, c.wantLimit)
+		}
+		if got := ds.NextItem().Data; got != c.wantItem {
+			t.Errorf("%s: incorrect first item: got %v want %v", c.desc, got, c.wantItem)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package insertstrategy
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+type sleepFn func(time.Duration)
+
+// RateRegulator limits the rate at which all load workers together insert
+// data. Unlike a SleepRegulator, which makes each worker sleep between
+// batches regardless of their size, it accounts for the number of metrics
+// or rows in each batch.
+type RateRegulator interface {
+	// Wait makes the worker wait, if required, after it inserted the given
+	// number of metrics and rows, so that the overall insert rate does not
+	// exceed the target rate
+	Wait(metrics, rows uint64)
+}
+
+// rateRegulator is a token bucket shared by all workers. Each inserted
+// batch takes its size in tokens, and the worker waits until the bucket has
+// been refilled with them at the target rate of the current step of the
+// schedule. If the database can't keep up with the target rate, the workers
+// never wait.
+type rateRegulator struct {
+	countRows bool
+	steps     []rateStep
+
+	mu    sync.Mutex
+	start time.Time
+	// next is when the tokens taken so far have been refilled
+	next time.Time
+
+	nowFn   nowProviderFn
+	sleepFn sleepFn
+}
+
+// NewRateRegulator returns a RateRegulator that limits the rate of inserted
+// metrics or rows (depending on unit) to the rate schedule. See
+// parseRateSchedule for the format of the schedule.
+func NewRateRegulator(schedule string, unit string) (RateRegulator, error) {
+	if unit != RateUnitMetrics && unit != RateUnitRows {
+		return nil, fmt.Errorf("insert rate unit must be '%s' or '%s', can't be '%s'", RateUnitMetrics, RateUnitRows, unit)
+	}
+	steps, err := parseRateSchedule(schedule)
+	if err != nil {
+		return nil, err
+	}
+	return &rateRegulator{
+		countRows: unit == RateUnitRows,
+		steps:     steps,
+		nowFn:     time.Now,
+		sleepFn:   time.Sleep,
+	}, nil
+}
+
+func (r *rateRegulator) Wait(metrics, rows uint64) {
+	tokens := metrics
+	if r.countRows {
+		tokens = rows
+	}
+
+	r.mu.Lock()
+	now := r.nowFn()
+	if r.start.IsZero() {
+		r.start = now
+	}
+	cost := time.Duration(float64(tokens) / r.rateAt(now.Sub(r.start)) * float64(time.Second))
+	// the bucket holds at most one batch worth of tokens, so they don't
+	// accumulate while the database is slower than the target rate
+	if earliest := now.Add(-cost); r.next.Before(earliest) {
+		r.next = earliest
+	}
+	r.next = r.next.Add(cost)
+	wait := r.next.Sub(now)
+	r.mu.Unlock()
+
+	if wait > 0 {
+		r.sleepFn(wait)
+	}
+}
+
+// rateAt returns the target rate at the given time since the start.
+func (r *rateRegulator) rateAt(sinceStart time.Duration) float64 {
+	for _, step := range r.steps {
+		if step.duration == 0 || sinceStart < step.duration {
+			return step.rate
+		}
+		sinceStart -= step.duration
+	}
+	return r.steps[len(r.steps)-1].rate
+}
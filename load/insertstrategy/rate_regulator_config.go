@@ -0,0 +1,69 @@
+package insertstrategy
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// RateUnitMetrics limits the rate of inserted metrics
+	RateUnitMetrics = "metrics"
+	// RateUnitRows limits the rate of inserted rows
+	RateUnitRows = "rows"
+
+	rateStepSeparator     = ","
+	rateDurationSeparator = ":"
+	rateFormatError       = "insert rate step could not be parsed. Required: 'rate' or 'rate:duration' | rate is a positive number, duration like '5m'"
+)
+
+// rateStep is a target insert rate held for a duration. The last step of a
+// schedule is held until the end of the benchmark.
+type rateStep struct {
+	rate     float64
+	duration time.Duration
+}
+
+// parseRateSchedule parses a string representation of an insert rate
+// schedule. The rate is in metrics or rows per second. It goes like this:
+// string='500000' => insert at 500000/s
+// string='100000:5m,250000:5m,500000' => insert at 100000/s for 5 minutes,
+// then at 250000/s for 5 minutes, then at 500000/s until the end
+// Every step but the last one must have a duration.
+func parseRateSchedule(schedule string) ([]rateStep, error) {
+	parts := strings.Split(schedule, rateStepSeparator)
+	steps := make([]rateStep, len(parts))
+	for i, part := range parts {
+		step, err := parseRateStep(strings.TrimSpace(part))
+		if err != nil {
+			return nil, err
+		}
+		last := i == len(parts)-1
+		if !last && step.duration <= 0 {
+			return nil, fmt.Errorf("insert rate step '%s' must have a duration, only the last step can be held until the end", part)
+		}
+		if last {
+			step.duration = 0
+		}
+		steps[i] = step
+	}
+	return steps, nil
+}
+
+func parseRateStep(stepStr string) (rateStep, error) {
+	parts := strings.SplitN(stepStr, rateDurationSeparator, 2)
+	rate, err := strconv.ParseFloat(parts[0], 64)
+	if err != nil || rate <= 0 {
+		return rateStep{}, errors.New(rateFormatError)
+	}
+	step := rateStep{rate: rate}
+	if len(parts) == 2 {
+		step.duration, err = time.ParseDuration(parts[1])
+		if err != nil || step.duration <= 0 {
+			return rateStep{}, errors.New(rateFormatError)
+		}
+	}
+	return step, nil
+}
@@ -0,0 +1,58 @@
+package insertstrategy
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseRateSchedule(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		in        string
+		out       []rateStep
+		expectErr bool
+	}{
+		{desc: "empty", in: "", expectErr: true},
+		{desc: "not a number", in: "a", expectErr: true},
+		{desc: "zero rate", in: "0", expectErr: true},
+		{desc: "negative rate", in: "-100", expectErr: true},
+		{desc: "bad duration", in: "100:5x,200", expectErr: true},
+		{desc: "missing duration", in: "100,200", expectErr: true},
+		{
+			desc: "single rate",
+			in:   "500000",
+			out:  []rateStep{{rate: 500000}},
+		}, {
+			desc: "duration of last step is ignored",
+			in:   "1.5:1m",
+			out:  []rateStep{{rate: 1.5}},
+		}, {
+			desc: "ramp up",
+			in:   "100000:5m, 250000:30s,500000",
+			out: []rateStep{
+				{rate: 100000, duration: 5 * time.Minute},
+				{rate: 250000, duration: 30 * time.Second},
+				{rate: 500000},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			res, err := parseRateSchedule(tc.in)
+			if tc.expectErr {
+				if err == nil {
+					t.Error("unexpected lack of error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(tc.out, res) {
+				t.Errorf("expected: %v; got: %v", tc.out, res)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package insertstrategy
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewRateRegulator(t *testing.T) {
+	if _, err := NewRateRegulator("100", "points"); err == nil {
+		t.Error("expected error for unknown unit")
+	}
+	if _, err := NewRateRegulator("a", RateUnitMetrics); err == nil {
+		t.Error("expected error for bad schedule")
+	}
+	res, err := NewRateRegulator("100", RateUnitRows)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rr := res.(*rateRegulator)
+	if !rr.countRows {
+		t.Error("rate regulator should count rows")
+	}
+	if rr.nowFn == nil || rr.sleepFn == nil {
+		t.Error("time functions not set up")
+	}
+}
+
+// fakeClock advances only when sleepFn is called.
+type fakeClock struct {
+	now    time.Time
+	sleeps []time.Duration
+}
+
+func (c *fakeClock) nowFn() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) sleepFn(d time.Duration) {
+	c.sleeps = append(c.sleeps, d)
+	c.now = c.now.Add(d)
+}
+
+func newTestRateRegulator(t *testing.T, schedule, unit string) (*rateRegulator, *fakeClock) {
+	res, err := NewRateRegulator(schedule, unit)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	rr := res.(*rateRegulator)
+	rr.nowFn = clock.nowFn
+	rr.sleepFn = clock.sleepFn
+	return rr, clock
+}
+
+func TestRateRegulatorWait(t *testing.T) {
+	rr, clock := newTestRateRegulator(t, "100", RateUnitMetrics)
+	// the bucket starts with one batch worth of tokens
+	rr.Wait(50, 1)
+	rr.Wait(100, 1)
+	rr.Wait(100, 1)
+	want := []time.Duration{time.Second, time.Second}
+	if len(clock.sleeps) != len(want) {
+		t.Fatalf("expected sleeps %v, got %v", want, clock.sleeps)
+	}
+	for i := range want {
+		if clock.sleeps[i] != want[i] {
+			t.Errorf("sleep %d: expected %v, got %v", i, want[i], clock.sleeps[i])
+		}
+	}
+}
+
+func TestRateRegulatorWaitRows(t *testing.T) {
+	rr, clock := newTestRateRegulator(t, "10", RateUnitRows)
+	rr.Wait(1000, 5)
+	rr.Wait(1000, 5)
+	if len(clock.sleeps) != 1 || clock.sleeps[0] != 500*time.Millisecond {
+		t.Errorf("expected a single sleep of 500ms, got %v", clock.sleeps)
+	}
+}
+
+func TestRateRegulatorWaitSlowDatabase(t *testing.T) {
+	rr, clock := newTestRateRegulator(t, "100", RateUnitMetrics)
+	rr.Wait(100, 1)
+	// the batch took longer than the target rate allows, so tokens have been
+	// refilled already
+	clock.now = clock.now.Add(5 * time.Second)
+	rr.Wait(100, 1)
+	if len(clock.sleeps) != 0 {
+		t.Errorf("expected no batch to wait, got sleeps %v", clock.sleeps)
+	}
+	// unused tokens don't accumulate
+	rr.Wait(100, 1)
+	if len(clock.sleeps) != 1 || clock.sleeps[0] != time.Second {
+		t.Errorf("expected the third batch to wait 1s, got sleeps %v", clock.sleeps)
+	}
+}
+
+func TestRateRegulatorRampUp(t *testing.T) {
+	rr, clock := newTestRateRegulator(t, "10:2s,100", RateUnitMetrics)
+	for i := 0; i < 5; i++ {
+		rr.Wait(10, 1)
+	}
+	want := []time.Duration{time.Second, time.Second, 100 * time.Millisecond, 100 * time.Millisecond}
+	if len(clock.sleeps) != len(want) {
+		t.Fatalf("expected sleeps %v, got %v", want, clock.sleeps)
+	}
+	for i := range want {
+		if clock.sleeps[i] != want[i] {
+			t.Errorf("sleep %d: expected %v, got %v", i, want[i], clock.sleeps[i])
+		}
+	}
+}
@@ -12,7 +12,7 @@ type noFlowBenchmarkRunner struct {
 }
 
 func (l *noFlowBenchmarkRunner) RunBenchmark(b targets.Benchmark) {
-	wg, start := l.preRun(b)
+	ds, limit, wg, start := l.preRun(b)
 
 	var numChannels uint
 	if l.HashWorkers {
@@ -27,7 +27,7 @@ func (l *noFlowBenchmarkRunner) RunBenchmark(b targets.Benchmark) {
 		go l.work(b, wg, channels[i%numChannels], i)
 	}
 	// Start scan process - actual data read process
-	scanWithoutFlowControl(b.GetDataSource(), b.GetPointIndexer(numChannels), b.GetBatchFactory(), channels, l.BatchSize, l.Limit)
+	scanWithoutFlowControl(ds, b.GetPointIndexer(numChannels), b.GetBatchFactory(), channels, l.BatchSize, limit, l.checkpoints)
 	for _, c := range channels {
 		close(c)
 	}
@@ -54,10 +54,13 @@ func (l *noFlowBenchmarkRunner) work(b targets.Benchmark, wg *sync.WaitGroup, c
 	// Process batches coming from the incoming queue (c)
 	for batch := range c {
 		startedWorkAt := time.Now()
-		metricCnt, rowCnt := proc.ProcessBatch(batch, l.DoLoad)
+		inserted := l.checkpoints.take(batch)
+		metricCnt, rowCnt := l.processBatch(proc, batch, workerNum)
 		atomic.AddUint64(&l.metricCnt, metricCnt)
 		atomic.AddUint64(&l.rowCnt, rowCnt)
+		l.checkpoints.inserted(inserted, metricCnt, rowCnt)
 		l.timeToSleep(workerNum, startedWorkAt)
+		l.waitForRate(metricCnt, rowCnt)
 	}
 
 	// Close proc if necessary
@@ -10,6 +10,8 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/bodhiye/tsbs/pkg/data/source"
+	"github.com/bodhiye/tsbs/pkg/distributed"
 	"github.com/bodhiye/tsbs/pkg/targets"
 
 	"github.com/bodhiye/tsbs/load/insertstrategy"
@@ -44,10 +46,27 @@ type BenchmarkRunnerConfig struct {
 	NoFlowControl   bool          `yaml:"no-flow-control" mapstructure:"no-flow-control" json:"no-flow-control"`
 	ChannelCapacity uint          `yaml:"channel-capacity" mapstructure:"channel-capacity" json:"channel-capacity"`
 	InsertIntervals string        `yaml:"insert-intervals" mapstructure:"insert-intervals" json:"insert-intervals"`
+	InsertRate      string        `yaml:"insert-rate" mapstructure:"insert-rate" json:"insert-rate"`
+	InsertRateUnit  string        `yaml:"insert-rate-unit" mapstructure:"insert-rate-unit" json:"insert-rate-unit"`
+	MaxRetries      uint          `yaml:"max-retries" mapstructure:"max-retries" json:"max-retries"`
+	RetryBackoff    time.Duration `yaml:"retry-backoff" mapstructure:"retry-backoff" json:"retry-backoff"`
+	MaxRetryBackoff time.Duration `yaml:"max-retry-backoff" mapstructure:"max-retry-backoff" json:"max-retry-backoff"`
+	DeadLetterFile  string        `yaml:"dead-letter-file" mapstructure:"dead-letter-file" json:"dead-letter-file"`
+	CheckpointFile  string        `yaml:"checkpoint-file" mapstructure:"checkpoint-file" json:"checkpoint-file"`
+	CheckpointEvery time.Duration `yaml:"checkpoint-interval" mapstructure:"checkpoint-interval" json:"checkpoint-interval"`
+	Resume          bool          `yaml:"resume" mapstructure:"resume" json:"resume"`
+	Coordinator     string        `yaml:"coordinator" mapstructure:"coordinator" json:"coordinator"`
+	AgentID         string        `yaml:"agent-id" mapstructure:"agent-id" json:"agent-id"`
 	ResultsFile     string        `yaml:"results-file" mapstructure:"results-file" json:"results-file"`
 	// deprecated, should not be used in other places other than tsbs_load_xx commands
 	FileName string `yaml:"file" mapstructure:"file" json:"file"`
 	Seed     int64  `yaml:"seed" mapstructure:"seed" json:"seed"`
+	// DataSource is the data source of tsbs_load, which a checkpoint
+	// identifies instead of FileName
+	DataSource *source.DataSourceConfig `yaml:"-" mapstructure:"-" json:"-"`
+
+	// agent is set once the loader joined the coordinator
+	agent *distributed.Agent
 }
 
 // AddToFlagSet adds command line flags needed by the BenchmarkRunnerConfig to the flag set.
@@ -63,6 +82,17 @@ func (c BenchmarkRunnerConfig) AddToFlagSet(fs *pflag.FlagSet) {
 	fs.String("file", "", "File name to read data from")
 	fs.Int64("seed", 0, "PRNG seed (default: 0, which uses the current timestamp)")
 	fs.String("insert-intervals", "", "Time to wait between each insert, default '' => all workers insert ASAP. '1,2' = worker 1 waits 1s between inserts, worker 2 and others wait 2s")
+	fs.String("insert-rate", "", "Target rate of all workers together, per second, default '' => insert ASAP. '500000' = insert at 500000/s, '100000:5m,500000' = insert at 100000/s for 5 minutes, then at 500000/s")
+	fs.String("insert-rate-unit", insertstrategy.RateUnitMetrics, "Unit of --insert-rate, 'metrics' or 'rows'")
+	fs.Uint("max-retries", 0, "Number of times to retry a failed batch insert, for targets that report failed inserts")
+	fs.Duration("retry-backoff", DefaultRetryBackoff, "Time to wait before the first retry of a failed batch insert, doubled on every retry")
+	fs.Duration("max-retry-backoff", DefaultMaxRetryBackoff, "Maximum time to wait between retries of a failed batch insert")
+	fs.String("dead-letter-file", "", "Write batches that could not be inserted after all retries to this file and keep loading, instead of aborting the load")
+	fs.String("checkpoint-file", "", "Periodically save the position of the load and the number of points, metrics and rows loaded to this file")
+	fs.Duration("checkpoint-interval", DefaultCheckpointInterval, "Time between two checkpoints")
+	fs.Bool("resume", false, "Resume the load after the position saved in --checkpoint-file, without creating the database, if the file exists")
+	fs.String("coordinator", "", "Address (host:port) of a tsbs_coordinator to join as an agent, which loads its shard of the data at the same time as the other agents")
+	fs.String("agent-id", "", "ID of the agent, unique among the agents, which its shard of the data depends on (default: the host name)")
 	fs.Bool("hash-workers", false, "Whether to consistently hash insert data to the same workers (i.e., the data for a particular host always goes to the same worker)")
 	fs.String("results-file", "", "Write the test results summary json to this file")
 }
@@ -70,6 +100,12 @@ func (c BenchmarkRunnerConfig) AddToFlagSet(fs *pflag.FlagSet) {
 type BenchmarkRunner interface {
 	DatabaseName() string
 	RunBenchmark(b targets.Benchmark)
+	// Result returns the results of the benchmark, or nil if it has not
+	// finished running
+	Result() *LoaderTestResult
+	// Stop makes the benchmark stop reading data, insert the batches in
+	// flight and report partial results
+	Stop()
 }
 
 // CommonBenchmarkRunner is responsible for initializing and storing common
@@ -80,6 +116,24 @@ type CommonBenchmarkRunner struct {
 	rowCnt         uint64
 	initialRand    *rand.Rand
 	sleepRegulator insertstrategy.SleepRegulator
+	rateRegulator  insertstrategy.RateRegulator
+	// rowsUnreported warns once that the insert rate is limited in metrics
+	// because the target does not report inserted rows
+	rowsUnreported *sync.Once
+	latencies      *batchLatencies
+	batchErrors    *batchErrors
+	deadLetters    *deadLetterWriter
+	checkpoints    *checkpointer
+	resumed        *Checkpoint
+	sleepFn        func(time.Duration)
+	result         *LoaderTestResult
+
+	checkpointsDone chan struct{}
+	stopProgress    func()
+
+	stopCh      chan struct{}
+	stopOnce    *sync.Once
+	stopSignals func()
 }
 
 // GetBenchmarkRunnerWithBatchSize returns the singleton CommonBenchmarkRunner for use in a benchmark program
@@ -93,6 +147,18 @@ func GetBenchmarkRunner(c BenchmarkRunnerConfig) BenchmarkRunner {
 	}
 
 	loader.initialRand = rand.New(rand.NewSource(loader.Seed))
+	loader.latencies = newBatchLatencies(loader.Workers)
+	loader.batchErrors = newBatchErrors(loader.Workers)
+	loader.sleepFn = time.Sleep
+	loader.stopCh = make(chan struct{})
+	loader.stopOnce = &sync.Once{}
+	loader.rowsUnreported = &sync.Once{}
+	if loader.RetryBackoff <= 0 {
+		loader.RetryBackoff = DefaultRetryBackoff
+	}
+	if loader.MaxRetryBackoff < loader.RetryBackoff {
+		loader.MaxRetryBackoff = loader.RetryBackoff
+	}
 
 	var err error
 	if c.InsertIntervals == "" {
@@ -103,6 +169,34 @@ func GetBenchmarkRunner(c BenchmarkRunnerConfig) BenchmarkRunner {
 			panic(fmt.Sprintf("could not initialize BenchmarkRunner: %v", err))
 		}
 	}
+	if c.InsertRate != "" {
+		if c.InsertIntervals != "" {
+			panic("could not initialize BenchmarkRunner: insert-intervals and insert-rate can not be used together")
+		}
+		if loader.InsertRateUnit == "" {
+			loader.InsertRateUnit = insertstrategy.RateUnitMetrics
+		}
+		loader.rateRegulator, err = insertstrategy.NewRateRegulator(c.InsertRate, loader.InsertRateUnit)
+		if err != nil {
+			panic(fmt.Sprintf("could not initialize BenchmarkRunner: %v", err))
+		}
+	}
+	if c.DeadLetterFile != "" {
+		loader.deadLetters, err = newDeadLetterWriter(c.DeadLetterFile)
+		if err != nil {
+			panic(fmt.Sprintf("could not initialize BenchmarkRunner: %v", err))
+		}
+	}
+	if c.Resume && c.CheckpointFile == "" {
+		panic("could not initialize BenchmarkRunner: resume requires checkpoint-file to be set")
+	}
+	// the checkpoints are of the shard of the data of the agent
+	loader.JoinCoordinator()
+	if c.CheckpointFile != "" {
+		if err = loader.initCheckpoints(); err != nil {
+			panic(fmt.Sprintf("could not initialize BenchmarkRunner: %v", err))
+		}
+	}
 	if !c.NoFlowControl {
 		return &loader
 	}
@@ -123,51 +217,123 @@ func (l *CommonBenchmarkRunner) DatabaseName() string {
 	return l.DBName
 }
 
-func (l *CommonBenchmarkRunner) preRun(b targets.Benchmark) (*sync.WaitGroup, *time.Time) {
+// preRun creates the database and starts the reporting, returning the data
+// source to scan, which starts after the checkpoint the load resumes from,
+// and the limit of the points left to read. When running as an agent, the
+// load starts when all agents are ready.
+func (l *CommonBenchmarkRunner) preRun(b targets.Benchmark) (targets.DataSource, uint64, *sync.WaitGroup, *time.Time) {
+	if err := l.validateBatchRetries(b); err != nil {
+		panic(fmt.Sprintf("could not run benchmark: %v", err))
+	}
+	// The first agent creates the database before the others use it
+	if l.agent != nil && l.agent.Agent != 0 {
+		l.barrier(distributed.BarrierCreated)
+	}
 	// Create required DB
 	if b.GetDBCreator() != nil {
 		cleanupFn := l.useDBCreator(b.GetDBCreator())
 		defer cleanupFn()
 	}
+	if l.agent != nil && l.agent.Agent == 0 {
+		l.barrier(distributed.BarrierCreated)
+	}
+	// some benchmarks open the data again on every call
+	src := b.GetDataSource()
+	if l.deadLetters != nil {
+		l.deadLetters.headers = src.Headers
+	}
+	ds, limit := l.resume(src, l.share())
+	if l.checkpoints != nil {
+		if positioned := newPositionedDataSource(ds, src); positioned != nil {
+			l.checkpoints.source = positioned
+			ds = positioned
+		}
+	}
+	l.barrier(distributed.BarrierStart)
 
 	if l.ReportingPeriod.Nanoseconds() > 0 {
 		go l.report(l.ReportingPeriod)
 	}
+	l.stopSignals = l.handleSignals()
+	if l.checkpoints != nil {
+		l.checkpointsDone = make(chan struct{})
+		go l.checkpoints.run(l.CheckpointEvery, l.checkpointsDone)
+	}
+	l.stopProgress = l.reportProgress()
 	wg := &sync.WaitGroup{}
 	wg.Add(int(l.Workers))
 	start := time.Now()
-	return wg, &start
+	return l.stoppable(ds), limit, wg, &start
 }
 
 func (l *CommonBenchmarkRunner) postRun(wg *sync.WaitGroup, start *time.Time) {
 	// Wait for all workers to finish
 	wg.Wait()
 	end := time.Now()
+	if l.stopSignals != nil {
+		l.stopSignals()
+	}
+	if l.stopProgress != nil {
+		l.stopProgress()
+	}
+	if l.deadLetters != nil {
+		if err := l.deadLetters.close(); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if l.checkpoints != nil {
+		close(l.checkpointsDone)
+		l.checkpoints.save()
+	}
 	took := end.Sub(*start)
 	l.summary(took)
+	metricRate := float64(l.metricCnt) / took.Seconds()
+	rowRate := float64(l.rowCnt) / took.Seconds()
+	l.result = l.testResult(took, *start, end, metricRate, rowRate)
 	if l.BenchmarkRunnerConfig.ResultsFile != "" {
-		metricRate := float64(l.metricCnt) / took.Seconds()
-		rowRate := float64(l.rowCnt) / took.Seconds()
-		l.saveTestResult(took, *start, end, metricRate, rowRate)
+		l.saveTestResult(l.result)
 	}
+	l.reportResult(l.result, *start, end)
+}
+
+// Result returns the results of the benchmark, or nil if it has not
+// finished running.
+func (l *CommonBenchmarkRunner) Result() *LoaderTestResult {
+	return l.result
 }
 
-func (l *CommonBenchmarkRunner) saveTestResult(took time.Duration, start time.Time, end time.Time, metricRate, rowRate float64) {
+func (l *CommonBenchmarkRunner) testResult(took time.Duration, start time.Time, end time.Time, metricRate, rowRate float64) *LoaderTestResult {
 	totals := make(map[string]interface{})
 	totals["metricRate"] = metricRate
 	if l.rowCnt > 0 {
 		totals["rowRate"] = rowRate
 	}
 
-	testResult := LoaderTestResult{
+	testResult := &LoaderTestResult{
 		ResultFormatVersion: LoaderTestResultVersion,
 		RunnerConfig:        l.BenchmarkRunnerConfig,
 		StartTime:           start.Unix(),
 		EndTime:             end.Unix(),
 		DurationMillis:      took.Milliseconds(),
 		Totals:              totals,
+		Partial:             l.stopped(),
 	}
+	if l.latencies != nil {
+		latencies, err := l.latencies.results()
+		if err != nil {
+			log.Fatal(err)
+		}
+		testResult.BatchLatencies = latencies
+	}
+	testResult.BatchErrors = l.batchErrors.results()
+	if l.checkpoints != nil {
+		c := l.checkpoints.checkpoint()
+		testResult.Checkpoint = &c
+	}
+	return testResult
+}
 
+func (l *CommonBenchmarkRunner) saveTestResult(testResult *LoaderTestResult) {
 	_, _ = fmt.Printf("Saving results json file to %s\n", l.BenchmarkRunnerConfig.ResultsFile)
 	file, err := json.MarshalIndent(testResult, "", " ")
 	if err != nil {
@@ -182,7 +348,7 @@ func (l *CommonBenchmarkRunner) saveTestResult(took time.Duration, start time.Ti
 
 // RunBenchmark takes in a Benchmark b and uses it to run the load benchmark
 func (l *CommonBenchmarkRunner) RunBenchmark(b targets.Benchmark) {
-	wg, start := l.preRun(b)
+	ds, limit, wg, start := l.preRun(b)
 	var numChannels, capacity uint
 	if l.HashWorkers {
 		numChannels = l.Workers
@@ -200,7 +366,7 @@ func (l *CommonBenchmarkRunner) RunBenchmark(b targets.Benchmark) {
 	}
 
 	// Start scan process - actual data read process
-	scanWithFlowControl(channels, l.BatchSize, l.Limit, b.GetDataSource(), b.GetBatchFactory(), b.GetPointIndexer(uint(len(channels))))
+	scanWithFlowControl(channels, l.BatchSize, limit, ds, b.GetBatchFactory(), b.GetPointIndexer(uint(len(channels))), l.checkpoints)
 	// After scan process completed (no more data to come) - begin shutdown process
 
 	// Close all communication channels to/from workers
@@ -283,11 +449,14 @@ func (l *CommonBenchmarkRunner) work(b targets.Benchmark, wg *sync.WaitGroup, c
 	// and send ACKs into duplexChannel.toScanner queue
 	for batch := range c.toWorker {
 		startedWorkAt := time.Now()
-		metricCnt, rowCnt := proc.ProcessBatch(batch, l.DoLoad)
+		inserted := l.checkpoints.take(batch)
+		metricCnt, rowCnt := l.processBatch(proc, batch, workerNum)
 		atomic.AddUint64(&l.metricCnt, metricCnt)
 		atomic.AddUint64(&l.rowCnt, rowCnt)
+		l.checkpoints.inserted(inserted, metricCnt, rowCnt)
 		c.sendToScanner()
 		l.timeToSleep(workerNum, startedWorkAt)
+		l.waitForRate(metricCnt, rowCnt)
 	}
 
 	// Close proc if necessary
@@ -305,15 +474,63 @@ func (l *CommonBenchmarkRunner) timeToSleep(workerNum uint, startedWorkAt time.T
 	}
 }
 
+// waitForRate waits for the rate regulator, if any. Targets like Cassandra,
+// MongoDB and SiriDB don't report inserted rows, so a rate in rows falls
+// back to metrics for them instead of never throttling.
+func (l *CommonBenchmarkRunner) waitForRate(metricCnt, rowCnt uint64) {
+	if l.rateRegulator == nil {
+		return
+	}
+	if l.InsertRateUnit == insertstrategy.RateUnitRows && rowCnt == 0 && metricCnt > 0 {
+		l.rowsUnreported.Do(func() {
+			printFn("warning: the target does not report inserted rows, limiting the insert rate in metrics instead\n")
+		})
+		rowCnt = metricCnt
+	}
+	l.rateRegulator.Wait(metricCnt, rowCnt)
+}
+
 // summary prints the summary of statistics from loading
 func (l *CommonBenchmarkRunner) summary(took time.Duration) {
 	metricRate := float64(l.metricCnt) / took.Seconds()
 	printFn("\nSummary:\n")
+	if l.stopped() {
+		printFn("load was stopped before all data was loaded, results are partial\n")
+	}
 	printFn("loaded %d metrics in %0.3fsec with %d workers (mean rate %0.2f metrics/sec)\n", l.metricCnt, took.Seconds(), l.Workers, metricRate)
 	if l.rowCnt > 0 {
 		rowRate := float64(l.rowCnt) / float64(took.Seconds())
 		printFn("loaded %d rows in %0.3fsec with %d workers (mean rate %0.2f rows/sec)\n", l.rowCnt, took.Seconds(), l.Workers, rowRate)
 	}
+	if s := l.latencies.summary(); s != nil && s.Count > 0 {
+		printFn("batch insert latency: min %0.2fms, p50 %0.2fms, p90 %0.2fms, p99 %0.2fms, p99.9 %0.2fms, max %0.2fms\n", s.Min, s.P50, s.P90, s.P99, s.P999, s.Max)
+	}
+	if e := l.batchErrors.totals(); e.Retried > 0 || e.Failed > 0 {
+		printFn("retried %d batch inserts, %d batches failed\n", e.Retried, e.Failed)
+		for i, w := range l.batchErrors.results().Workers {
+			printFn("[worker %d] retried %d batch inserts, %d batches failed\n", i, w.Retried, w.Failed)
+		}
+	}
+	if l.checkpoints != nil {
+		c := l.checkpoints.checkpoint()
+		printFn("checkpoint saved to %s: %d points, %d metrics, %d rows loaded in total\n", l.CheckpointFile, c.Points, c.Metrics, c.Rows)
+	}
+}
+
+// intervalLatencies returns the p50, p99 and max batch insert latencies since
+// the previous call as report columns, or dashes if no batch was inserted.
+func (l *CommonBenchmarkRunner) intervalLatencies() string {
+	if l.latencies == nil {
+		return "-,-,-"
+	}
+	h := l.latencies.collect()
+	if h.TotalCount() == 0 {
+		return "-,-,-"
+	}
+	return fmt.Sprintf("%0.2f,%0.2f,%0.2f",
+		float64(h.ValueAtQuantile(50.0))/usecsPerMilli,
+		float64(h.ValueAtQuantile(99.0))/usecsPerMilli,
+		float64(h.Max())/usecsPerMilli)
 }
 
 // report handles periodic reporting of loading stats
@@ -323,10 +540,12 @@ func (l *CommonBenchmarkRunner) report(period time.Duration) {
 	prevColCount := uint64(0)
 	prevRowCount := uint64(0)
 
-	printFn("time,per. metric/s,metric total,overall metric/s,per. row/s,row total,overall row/s\n")
+	printFn("time,per. metric/s,metric total,overall metric/s,per. row/s,row total,overall row/s,per. batch p50 ms,per. batch p99 ms,per. batch max ms,retried batches,failed batches\n")
 	for now := range time.NewTicker(period).C {
 		cCount := atomic.LoadUint64(&l.metricCnt)
 		rCount := atomic.LoadUint64(&l.rowCnt)
+		latencies := l.intervalLatencies()
+		batchErrors := l.batchErrors.totals()
 
 		sinceStart := now.Sub(start)
 		took := now.Sub(prevTime)
@@ -335,9 +554,9 @@ func (l *CommonBenchmarkRunner) report(period time.Duration) {
 		if rCount > 0 {
 			rowrate := float64(rCount-prevRowCount) / float64(took.Seconds())
 			overallRowRate := float64(rCount) / float64(sinceStart.Seconds())
-			printFn("%d,%0.2f,%E,%0.2f,%0.2f,%E,%0.2f\n", now.Unix(), colrate, float64(cCount), overallColRate, rowrate, float64(rCount), overallRowRate)
+			printFn("%d,%0.2f,%E,%0.2f,%0.2f,%E,%0.2f,%s,%d,%d\n", now.Unix(), colrate, float64(cCount), overallColRate, rowrate, float64(rCount), overallRowRate, latencies, batchErrors.Retried, batchErrors.Failed)
 		} else {
-			printFn("%d,%0.2f,%E,%0.2f,-,-,-\n", now.Unix(), colrate, float64(cCount), overallColRate)
+			printFn("%d,%0.2f,%E,%0.2f,-,-,-,%s,%d,%d\n", now.Unix(), colrate, float64(cCount), overallColRate, latencies, batchErrors.Retried, batchErrors.Failed)
 		}
 
 		prevColCount = cCount
@@ -3,6 +3,7 @@ package load
 import (
 	"bytes"
 	"fmt"
+	"github.com/bodhiye/tsbs/load/insertstrategy"
 	"github.com/bodhiye/tsbs/pkg/targets"
 	"strings"
 	"sync"
@@ -306,7 +307,7 @@ func TestCreateChannelsAndPartitions(t *testing.T) {
 }
 
 func TestWork(t *testing.T) {
-	br := &CommonBenchmarkRunner{}
+	br := &CommonBenchmarkRunner{latencies: newBatchLatencies(2)}
 	b := &testBenchmark{}
 	for i := 0; i < 2; i++ {
 		b.processors = append(b.processors, &testProcessor{})
@@ -336,6 +337,10 @@ func TestWork(t *testing.T) {
 		t.Errorf("TestWork: invalid metric count: got %d want %d", got, 2)
 	}
 
+	if got := br.latencies.summary().Count; got != 2 {
+		t.Errorf("TestWork: invalid batch latency count: got %d want %d", got, 2)
+	}
+
 	if !b.processors[0].closed {
 		t.Errorf("TestWork: processor 0 not closed")
 	}
@@ -378,6 +383,56 @@ func TestWorkWithSleep(t *testing.T) {
 	}
 }
 
+type testRateRegulator struct {
+	metrics, rows []uint64
+}
+
+func (r *testRateRegulator) Wait(metrics, rows uint64) {
+	r.metrics = append(r.metrics, metrics)
+	r.rows = append(r.rows, rows)
+}
+
+func TestWaitForRate(t *testing.T) {
+	oldPrintFn := printFn
+	defer func() { printFn = oldPrintFn }()
+	cases := []struct {
+		desc         string
+		unit         string
+		metrics      uint64
+		rows         uint64
+		wantRows     uint64
+		wantWarnings int
+	}{
+		{desc: "metrics", unit: insertstrategy.RateUnitMetrics, metrics: 10},
+		{desc: "rows", unit: insertstrategy.RateUnitRows, metrics: 10, rows: 2, wantRows: 2},
+		{desc: "rows not reported", unit: insertstrategy.RateUnitRows, metrics: 10, wantRows: 10, wantWarnings: 1},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			warnings := 0
+			printFn = func(string, ...interface{}) (int, error) {
+				warnings++
+				return 0, nil
+			}
+			r := &testRateRegulator{}
+			br := &CommonBenchmarkRunner{
+				BenchmarkRunnerConfig: BenchmarkRunnerConfig{InsertRateUnit: c.unit},
+				rateRegulator:         r,
+				rowsUnreported:        &sync.Once{},
+			}
+			br.waitForRate(c.metrics, c.rows)
+			br.waitForRate(c.metrics, c.rows)
+
+			if len(r.rows) != 2 || r.rows[0] != c.wantRows || r.metrics[0] != c.metrics {
+				t.Errorf("incorrect regulator calls: got metrics %v rows %v, want %d metrics and %d rows twice", r.metrics, r.rows, c.metrics, c.wantRows)
+			}
+			if warnings != c.wantWarnings {
+				t.Errorf("incorrect number of warnings: got %d want %d", warnings, c.wantWarnings)
+			}
+		})
+	}
+}
+
 func TestSummary(t *testing.T) {
 	cases := []struct {
 		desc    string
@@ -460,10 +515,10 @@ func TestReport(t *testing.T) {
 		t.Errorf("TestReport: counter check incorrect (2): got %d want %d", got, 3)
 	}
 	m.Lock()
-	end := strings.TrimSpace(string(b.Bytes()))
+	end := strings.Split(strings.TrimSpace(string(b.Bytes())), ",")
 	m.Unlock()
-	if end[len(end)-1:len(end)] != "-" {
-		t.Errorf("TestReport: non-row report does not end in -")
+	if end[len(end)-8] != "-" {
+		t.Errorf("TestReport: non-row report does not have - for row rate")
 	}
 
 	// update row count so line is different
@@ -473,9 +528,9 @@ func TestReport(t *testing.T) {
 		t.Errorf("TestReport: counter check incorrect (1): got %d want %d", got, 4)
 	}
 	m.Lock()
-	end = strings.TrimSpace(string(b.Bytes()))
+	end = strings.Split(strings.TrimSpace(string(b.Bytes())), ",")
 	m.Unlock()
-	if end[len(end)-1:len(end)] == "-" {
-		t.Errorf("TestReport: row report ends in -")
+	if end[len(end)-8] == "-" {
+		t.Errorf("TestReport: row report has - for row rate")
 	}
 }
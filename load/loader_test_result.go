@@ -11,10 +11,25 @@ type LoaderTestResult struct {
 	RunnerConfig BenchmarkRunnerConfig `json:"RunnerConfig"`
 
 	// Run info
-	StartTime      int64 `json:"StartTime`
+	StartTime      int64 `json:"StartTime"`
 	EndTime        int64 `json:"EndTime"`
 	DurationMillis int64 `json:"DurationMillis"`
 
 	// Totals
 	Totals map[string]interface{} `json:"Totals"`
+
+	// Partial is set when the load was stopped before all data was loaded
+	Partial bool `json:"Partial,omitempty"`
+
+	// BatchLatencies holds the batch insert latencies of all workers and of
+	// each worker
+	BatchLatencies *BatchLatencyResults `json:"BatchLatencies,omitempty"`
+
+	// BatchErrors holds the number of retried and failed batch inserts of
+	// all workers and of each worker
+	BatchErrors *BatchErrorResults `json:"BatchErrors,omitempty"`
+
+	// Checkpoint holds the position of the load and the points, metrics and
+	// rows loaded in total, including the runs the load was resumed from
+	Checkpoint *Checkpoint `json:"Checkpoint,omitempty"`
 }
@@ -10,9 +10,10 @@ import (
 // readDs does no flow control, if the capacity of a channel is reached, scanning stops for all
 // workers. (should only happen if channel-capacity is low and one worker is unreasonable slower than the rest)
 // in that case just set hash-workers to false and use 1 channel for all workers.
+// The batches are registered with the checkpointer cp, if set.
 func scanWithoutFlowControl(
 	ds targets.DataSource, indexer targets.PointIndexer, factory targets.BatchFactory, channels []chan targets.Batch,
-	batchSize uint, limit uint64,
+	batchSize uint, limit uint64, cp *checkpointer,
 ) uint64 {
 	if batchSize == 0 {
 		panic("batch size can't be 0")
@@ -36,9 +37,13 @@ func scanWithoutFlowControl(
 		itemsRead++
 
 		idx := indexer.GetIndex(item)
+		if batches[idx].Len() == 0 {
+			cp.started(batches[idx], itemsRead)
+		}
 		batches[idx].Append(item)
 
 		if batches[idx].Len() >= batchSize {
+			cp.sent(batches[idx], itemsRead)
 			channels[idx] <- batches[idx]
 			batches[idx] = factory.New()
 		}
@@ -46,6 +51,7 @@ func scanWithoutFlowControl(
 
 	for idx, unfilledBatch := range batches {
 		if unfilledBatch.Len() > 0 {
+			cp.sent(unfilledBatch, itemsRead)
 			channels[idx] <- unfilledBatch
 		}
 	}
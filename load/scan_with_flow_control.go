@@ -42,9 +42,11 @@ func sendOrQueueBatch(ch *duplexChannel, count *int, batch targets.Batch, unsent
 // which are then dispatched to workers (duplexChannel chosen by PointIndexer).
 // Scan does flow control to make sure workers are not left idle for too long
 // and also that the scanning process does not starve them of CPU.
+// The batches are registered with the checkpointer cp, if set.
 func scanWithFlowControl(
 	channels []*duplexChannel, batchSize uint, limit uint64,
 	ds targets.DataSource, factory targets.BatchFactory, indexer targets.PointIndexer,
+	cp *checkpointer,
 ) uint64 {
 	var itemsRead uint64
 	numChannels := len(channels)
@@ -123,11 +125,15 @@ func scanWithFlowControl(
 
 		// Append new item to batch
 		idx := indexer.GetIndex(item)
+		if fillingBatches[idx].Len() == 0 {
+			cp.started(fillingBatches[idx], itemsRead)
+		}
 		fillingBatches[idx].Append(item)
 
 		if fillingBatches[idx].Len() >= batchSize {
 			// Batch is full (contains at least batchSize items) - ready to be sent to worker,
 			// or moved to outstanding, in case no workers available atm.
+			cp.sent(fillingBatches[idx], itemsRead)
 			unsentBatches[idx] = sendOrQueueBatch(channels[idx], &ocnt, fillingBatches[idx], unsentBatches[idx])
 			// Place new empty batch
 			fillingBatches[idx] = factory.New()
@@ -139,6 +145,7 @@ func scanWithFlowControl(
 	for idx, b := range fillingBatches {
 		// Do not enqueue empty batches (with 0 items)
 		if b.Len() > 0 {
+			cp.sent(b, itemsRead)
 			unsentBatches[idx] = sendOrQueueBatch(channels[idx], &ocnt, fillingBatches[idx], unsentBatches[idx])
 		}
 	}
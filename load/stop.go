@@ -0,0 +1,72 @@
+package load
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/bodhiye/tsbs/pkg/data"
+	"github.com/bodhiye/tsbs/pkg/targets"
+)
+
+// Stop makes the benchmark stop reading data. The batches already read are
+// still inserted, and the results are marked as partial.
+func (l *CommonBenchmarkRunner) Stop() {
+	l.stopOnce.Do(func() {
+		close(l.stopCh)
+	})
+}
+
+// stopped returns whether the benchmark was stopped before all data was read.
+func (l *CommonBenchmarkRunner) stopped() bool {
+	select {
+	case <-l.stopCh:
+		return true
+	default:
+		return false
+	}
+}
+
+// handleSignals stops the benchmark on SIGINT or SIGTERM, so the data loaded
+// so far is still summarized and written to the results file. A second
+// signal exits at once. The returned function stops handling signals.
+func (l *CommonBenchmarkRunner) handleSignals() func() {
+	c := make(chan os.Signal, 2)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		sig, ok := <-c
+		if !ok {
+			return
+		}
+		printFn("\ncaught %v, stopping after the batches in flight are inserted\n", sig)
+		l.Stop()
+		if sig, ok = <-c; ok {
+			fatal("caught %v again, exiting", sig)
+		}
+	}()
+	return func() {
+		signal.Stop(c)
+		close(c)
+	}
+}
+
+// stoppableDataSource ends the data of a DataSource once the benchmark is
+// stopped, so the scanner sends out the batches it is filling and waits for
+// the workers to insert them, as it does at the end of the data.
+type stoppableDataSource struct {
+	targets.DataSource
+	stop <-chan struct{}
+}
+
+func (l *CommonBenchmarkRunner) stoppable(ds targets.DataSource) targets.DataSource {
+	return &stoppableDataSource{DataSource: ds, stop: l.stopCh}
+}
+
+func (d *stoppableDataSource) NextItem() data.LoadedPoint {
+	select {
+	case <-d.stop:
+		return data.LoadedPoint{}
+	default:
+		return d.DataSource.NextItem()
+	}
+}
@@ -0,0 +1,66 @@
+package load
+
+import (
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/bodhiye/tsbs/pkg/data"
+	"github.com/bodhiye/tsbs/pkg/data/usecases/common"
+)
+
+type countingDataSource struct {
+	items int
+}
+
+func (d *countingDataSource) NextItem() data.LoadedPoint {
+	d.items++
+	return data.NewLoadedPoint(d.items)
+}
+
+func (d *countingDataSource) Headers() *common.GeneratedDataHeaders { return nil }
+
+func TestStoppableDataSource(t *testing.T) {
+	l := GetBenchmarkRunner(BenchmarkRunnerConfig{Workers: 1}).(*CommonBenchmarkRunner)
+	ds := l.stoppable(&countingDataSource{})
+	for i := 1; i <= 3; i++ {
+		if got := ds.NextItem(); got.Data != i {
+			t.Errorf("incorrect item: got %v want %d", got.Data, i)
+		}
+	}
+	if l.stopped() {
+		t.Errorf("runner stopped unexpectedly")
+	}
+
+	l.Stop()
+	// stopping twice is fine
+	l.Stop()
+	if got := ds.NextItem(); got.Data != nil {
+		t.Errorf("data source returned an item after stop: %v", got.Data)
+	}
+	if !l.stopped() {
+		t.Errorf("runner not stopped")
+	}
+	if !l.testResult(time.Second, time.Now(), time.Now(), 0, 0).Partial {
+		t.Errorf("results of stopped runner not marked as partial")
+	}
+}
+
+func TestHandleSignals(t *testing.T) {
+	oldPrintFn := printFn
+	defer func() { printFn = oldPrintFn }()
+	printFn = func(string, ...interface{}) (int, error) { return 0, nil }
+
+	l := GetBenchmarkRunner(BenchmarkRunnerConfig{Workers: 1}).(*CommonBenchmarkRunner)
+	stopSignals := l.handleSignals()
+	defer stopSignals()
+	if err := syscall.Kill(os.Getpid(), syscall.SIGINT); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-l.stopCh:
+	case <-time.After(time.Second):
+		t.Errorf("runner not stopped on SIGINT")
+	}
+}
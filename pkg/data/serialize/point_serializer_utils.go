@@ -16,12 +16,14 @@ var (
 	TestColFloat    = []byte("usage_guest_nice")
 	TestColInt      = []byte("usage_guest")
 	TestColInt64    = []byte("big_usage_guest")
+	TestColString   = []byte("message")
 )
 
 const (
 	TestFloat             = float64(38.24311829)
 	TestInt               = 38
 	TestInt64             = int64(5000000000)
+	TestString            = `request "GET /" failed`
 	ErrWriterAlwaysErr    = "bad write: I always error"
 	ErrWriterSometimesErr = "bad write: I sometimes error"
 )
@@ -86,6 +88,11 @@ func TestPointWithNilField() *data.Point {
 		[][]byte{TestColInt64, TestColFloat}, []interface{}{nil, TestFloat})
 }
 
+func TestPointString() *data.Point {
+	return generateTestPoint(TestMeasurement, TestTagKeys, TestTagVals, &TestNow,
+		[][]byte{TestColString, TestColFloat}, []interface{}{TestString, TestFloat})
+}
+
 type SerializeCase struct {
 	Desc       string
 	InputPoint *data.Point
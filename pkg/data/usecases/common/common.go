@@ -24,6 +24,10 @@ const (
 	UseCaseDevops        = "devops"
 	UseCaseIoT           = "iot"
 	UseCaseDevopsGeneric = "devops-generic"
+	UseCaseCustom        = "custom"
+	UseCaseK8s           = "k8s"
+	UseCaseTicks         = "ticks"
+	UseCaseEvents        = "events"
 )
 
 var UseCaseChoices = []string{
@@ -32,4 +36,8 @@ var UseCaseChoices = []string{
 	UseCaseDevops,
 	UseCaseIoT,
 	UseCaseDevopsGeneric,
+	UseCaseCustom,
+	UseCaseK8s,
+	UseCaseTicks,
+	UseCaseEvents,
 }
@@ -25,7 +25,13 @@ type Tag struct {
 
 const (
 	errMaxMetricCountValue = "max metric count per host has to be greater than 0"
+	errNoSchemaFile        = "custom use case needs a schema file"
+	errK8sRateNegative     = "k8s churn and restart rates cannot be negative"
+	errTicksRateValue      = "ticks rate has to be greater than 0 and burst factor at least 1"
+	errEventsMessageSize   = "events message size cannot be negative"
+	errDevopsTagsNegative  = "devops tag counts and value length cannot be negative"
 	errLogIntervalZero     = "cannot have log interval of 0"
+	errAccelerationValue   = "real time acceleration cannot be negative"
 	defaultLogInterval     = 10 * time.Second
 )
 
@@ -41,6 +47,52 @@ type DataGeneratorConfig struct {
 	InterleavedGroupID    uint          `yaml:"interleaved-generation-group-id" mapstructure:"interleaved-generation-group-id"`
 	InterleavedNumGroups  uint          `yaml:"interleaved-generation-groups" mapstructure:"interleaved-generation-groups"`
 	MaxMetricCountPerHost uint64        `yaml:"max-metric-count" mapstructure:"max-metric-count"`
+	// RealTime releases the generated points at the pace of their timestamps
+	// instead of as fast as possible, see PacedSimulator
+	RealTime             bool    `yaml:"real-time" mapstructure:"real-time"`
+	RealTimeShiftToNow   bool    `yaml:"real-time-shift-to-now" mapstructure:"real-time-shift-to-now"`
+	RealTimeAcceleration float64 `yaml:"real-time-acceleration" mapstructure:"real-time-acceleration"`
+	// SchemaFile is the YAML file defining the measurements, tags and fields
+	// of the custom use case
+	SchemaFile string `yaml:"schema-file" mapstructure:"schema-file"`
+	// K8sChurnRate is how many times per hour a pod of the k8s use case is
+	// replaced by a new one, creating new series
+	K8sChurnRate float64 `yaml:"k8s-churn-rate" mapstructure:"k8s-churn-rate"`
+	// K8sRestartRate is how many times per hour a container of the k8s use
+	// case restarts
+	K8sRestartRate float64 `yaml:"k8s-restart-rate" mapstructure:"k8s-restart-rate"`
+	// TicksRate is the mean number of trades and quotes per second of a
+	// symbol of the ticks use case
+	TicksRate float64 `yaml:"ticks-rate" mapstructure:"ticks-rate"`
+	// TicksBurstFactor is how many times more trades and quotes a symbol of
+	// the ticks use case has during a burst of activity
+	TicksBurstFactor float64 `yaml:"ticks-burst-factor" mapstructure:"ticks-burst-factor"`
+	// EventsMessageSize is the length in bytes the messages of the events
+	// use case are padded to
+	EventsMessageSize int `yaml:"events-message-size" mapstructure:"events-message-size"`
+	// DevopsRegions is the number of regions of the hosts of the devops
+	// use cases, 0 for the built-in ones
+	DevopsRegions int `yaml:"devops-regions" mapstructure:"devops-regions"`
+	// DevopsDatacenters is the number of datacenters per region of the
+	// devops use cases, 0 for the built-in ones
+	DevopsDatacenters int `yaml:"devops-datacenters" mapstructure:"devops-datacenters"`
+	// DevopsRacks is the number of racks per datacenter of the devops use
+	// cases
+	DevopsRacks int `yaml:"devops-racks" mapstructure:"devops-racks"`
+	// DevopsServices is the number of services of the devops use cases
+	DevopsServices int `yaml:"devops-services" mapstructure:"devops-services"`
+	// DevopsServiceVersions is the number of service versions of the devops
+	// use cases
+	DevopsServiceVersions int `yaml:"devops-service-versions" mapstructure:"devops-service-versions"`
+	// DevopsExtraTags is the number of synthetic tags added to the hosts of
+	// the devops use cases
+	DevopsExtraTags int `yaml:"devops-extra-tags" mapstructure:"devops-extra-tags"`
+	// DevopsExtraTagCardinality is the number of values of each synthetic
+	// tag of the devops use cases
+	DevopsExtraTagCardinality int `yaml:"devops-extra-tag-cardinality" mapstructure:"devops-extra-tag-cardinality"`
+	// DevopsTagValueLength is the minimum length the numbered tag values of
+	// the devops use cases, hostnames included, are padded to with zeros
+	DevopsTagValueLength int `yaml:"devops-tag-value-length" mapstructure:"devops-tag-value-length"`
 }
 
 // Validate checks that the values of the DataGeneratorConfig are reasonable.
@@ -58,12 +110,39 @@ func (c *DataGeneratorConfig) Validate() error {
 		return fmt.Errorf(errLogIntervalZero)
 	}
 
+	if c.RealTimeAcceleration < 0 {
+		return fmt.Errorf(errAccelerationValue)
+	}
+
 	err = utils.ValidateGroups(c.InterleavedGroupID, c.InterleavedNumGroups)
 
 	if c.Use == UseCaseDevopsGeneric && c.MaxMetricCountPerHost < 1 {
 		return fmt.Errorf(errMaxMetricCountValue)
 	}
 
+	if c.Use == UseCaseCustom && c.SchemaFile == "" {
+		return fmt.Errorf(errNoSchemaFile)
+	}
+
+	if c.K8sChurnRate < 0 || c.K8sRestartRate < 0 {
+		return fmt.Errorf(errK8sRateNegative)
+	}
+
+	if c.Use == UseCaseTicks && (c.TicksRate <= 0 || c.TicksBurstFactor < 1) {
+		return fmt.Errorf(errTicksRateValue)
+	}
+
+	if c.EventsMessageSize < 0 {
+		return fmt.Errorf(errEventsMessageSize)
+	}
+
+	for _, v := range []int{c.DevopsRegions, c.DevopsDatacenters, c.DevopsRacks, c.DevopsServices,
+		c.DevopsServiceVersions, c.DevopsExtraTags, c.DevopsExtraTagCardinality, c.DevopsTagValueLength} {
+		if v < 0 {
+			return fmt.Errorf(errDevopsTagsNegative)
+		}
+	}
+
 	return err
 }
 
@@ -78,6 +157,24 @@ func (c *DataGeneratorConfig) AddToFlagSet(fs *pflag.FlagSet) {
 	fs.Uint("interleaved-generation-groups", 1,
 		"The number of round-robin serialization groups. Use this to scale up data generation to multiple processes.")
 	fs.Uint64("max-metric-count", 100, "Max number of metric fields to generate per host. Used only in devops-generic use-case")
+	fs.String("schema-file", "", "YAML file defining the measurements, tags and fields to generate. Used only in custom use-case")
+	fs.Float64("k8s-churn-rate", 0.5, "How many times per hour a pod is replaced by a new one. Used only in k8s use-case")
+	fs.Float64("k8s-restart-rate", 0.05, "How many times per hour a container restarts. Used only in k8s use-case")
+	fs.Float64("ticks-rate", 10, "Mean number of trades and quotes per second of a symbol. Used only in ticks use-case")
+	fs.Float64("ticks-burst-factor", 10, "How many times more trades and quotes a symbol has during a burst. Used only in ticks use-case")
+	fs.Int("events-message-size", 128, "Length in bytes the event messages are padded to. Used only in events use-case")
+	fs.Int("devops-regions", 0, "Number of regions, the built-in ones first, 0 for the 9 built-in ones. Used only in devops use-cases")
+	fs.Int("devops-datacenters", 0, "Number of datacenters per region, 0 for the built-in ones. Used only in devops use-cases")
+	fs.Int("devops-racks", 100, "Number of racks per datacenter. Used only in devops use-cases")
+	fs.Int("devops-services", 20, "Number of services. Used only in devops use-cases")
+	fs.Int("devops-service-versions", 2, "Number of service versions. Used only in devops use-cases")
+	fs.Int("devops-extra-tags", 0, "Number of synthetic tags, tag_0 to tag_N-1, added to the hosts. Used only in devops use-cases")
+	fs.Int("devops-extra-tag-cardinality", 10, "Number of values of each synthetic tag. Used only in devops use-cases")
+	fs.Int("devops-tag-value-length", 0, "Minimum length the numbered tag values, hostnames included, are padded to with zeros. Used only in devops use-cases")
+
+	fs.Bool("real-time", false, "Release the data points at the pace of their timestamps instead of as fast as possible")
+	fs.Bool("real-time-shift-to-now", false, "With --real-time, replace the timestamp of each point with the time it is released at")
+	fs.Float64("real-time-acceleration", 1, "With --real-time, how many times faster than real time to release the data points")
 }
 
 const defaultTimeStart = "2016-01-01T00:00:00Z"
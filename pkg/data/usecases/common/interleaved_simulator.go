@@ -0,0 +1,38 @@
+package common
+
+import "github.com/bodhiye/tsbs/pkg/data"
+
+// InterleavedSimulator wraps a Simulator to only write the points of one of
+// several interleaved groups, like tsbs_generate_data does with
+// --interleaved-generation-group-id and
+// --interleaved-generation-groups. The written points are distributed
+// round-robin over the groups, so the groups together write all the points
+// of the wrapped simulator, each of them exactly once.
+type InterleavedSimulator struct {
+	Simulator
+
+	groupID   uint
+	numGroups uint
+	current   uint
+}
+
+// NewInterleavedSimulator creates an InterleavedSimulator writing only the
+// points of sim in the group groupID out of numGroups.
+func NewInterleavedSimulator(sim Simulator, groupID, numGroups uint) *InterleavedSimulator {
+	return &InterleavedSimulator{
+		Simulator: sim,
+		groupID:   groupID,
+		numGroups: numGroups,
+	}
+}
+
+// Next advances a Point to the next state of the wrapped simulator and
+// returns whether the point belongs to the group of the simulator.
+func (s *InterleavedSimulator) Next(p *data.Point) bool {
+	if !s.Simulator.Next(p) {
+		return false
+	}
+	write := s.current == s.groupID
+	s.current = (s.current + 1) % s.numGroups
+	return write
+}
@@ -0,0 +1,53 @@
+package common
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bodhiye/tsbs/pkg/data"
+)
+
+// skippingSimulator makes points with increasing timestamps, not writing
+// every third one.
+type skippingSimulator struct {
+	timestampSimulator
+}
+
+func (s *skippingSimulator) Next(p *data.Point) bool {
+	s.timestampSimulator.Next(p)
+	return s.made%3 != 0
+}
+
+func TestInterleavedSimulatorNext(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	const numGroups = 3
+	const points = 30
+
+	seen := make(map[time.Time]uint)
+	for groupID := uint(0); groupID < numGroups; groupID++ {
+		inner := &skippingSimulator{timestampSimulator{start: start, interval: time.Second}}
+		sim := NewInterleavedSimulator(inner, groupID, numGroups)
+		for i := 0; i < points; i++ {
+			p := data.NewPoint()
+			if sim.Next(p) {
+				if prev, ok := seen[*p.Timestamp()]; ok {
+					t.Errorf("point at %v written by groups %d and %d", *p.Timestamp(), prev, groupID)
+				}
+				seen[*p.Timestamp()] = groupID
+			}
+		}
+	}
+
+	// every third point is not written by the wrapped simulator
+	if got, want := len(seen), points*2/3; got != want {
+		t.Errorf("incorrect number of points written: got %d want %d", got, want)
+	}
+	for ts, groupID := range seen {
+		made := int(ts.Sub(start)/time.Second) + 1
+		// the written points before this one, in the order of the wrapped simulator
+		written := made - made/3 - 1
+		if want := uint(written % numGroups); groupID != want {
+			t.Errorf("point at %v written by group %d, want %d", ts, groupID, want)
+		}
+	}
+}
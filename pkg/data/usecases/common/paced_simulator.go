@@ -0,0 +1,95 @@
+package common
+
+import (
+	"time"
+
+	"github.com/bodhiye/tsbs/pkg/data"
+)
+
+// PacedSimulator wraps a Simulator to release points in real time: Next
+// blocks until the wall clock has advanced as much since the first point as
+// the simulated clock has, divided by the acceleration factor. This turns a
+// simulator that generates data as fast as possible into a live fleet of
+// hosts reporting every log interval.
+type PacedSimulator struct {
+	Simulator
+
+	acceleration float64
+	shiftToNow   bool
+
+	started   bool
+	simStart  time.Time
+	wallStart time.Time
+
+	// change for testing
+	now   func() time.Time
+	sleep func(time.Duration)
+}
+
+// NewPacedSimulator creates a PacedSimulator releasing the points of sim
+// acceleration times faster than real time. An acceleration of 0 means real
+// time. If shiftToNow is set, the timestamp of each point is replaced with the
+// time it is released at, so the data is always fresh.
+func NewPacedSimulator(sim Simulator, acceleration float64, shiftToNow bool) *PacedSimulator {
+	if acceleration <= 0 {
+		acceleration = 1
+	}
+	return &PacedSimulator{
+		Simulator:    sim,
+		acceleration: acceleration,
+		shiftToNow:   shiftToNow,
+		now:          time.Now,
+		sleep:        time.Sleep,
+	}
+}
+
+// Next advances a Point to the next state of the wrapped simulator, waiting
+// until it is due.
+func (s *PacedSimulator) Next(p *data.Point) bool {
+	ret := s.Simulator.Next(p)
+	ts := p.Timestamp()
+	if ts == nil {
+		return ret
+	}
+
+	if !s.started {
+		s.started = true
+		s.simStart = *ts
+		s.wallStart = s.now()
+	}
+	release := s.wallStart.Add(time.Duration(float64(ts.Sub(s.simStart)) / s.acceleration))
+	if wait := release.Sub(s.now()); wait > 0 {
+		s.sleep(wait)
+	}
+
+	if s.shiftToNow {
+		shifted := release.UTC()
+		p.SetTimestamp(&shifted)
+	}
+	return ret
+}
+
+// FastForward generates and discards the next n points of sim that would be
+// written, without waiting for them if sim is paced, so a resumed load can
+// skip the points it already inserted. It returns the number of points
+// skipped, fewer than n if the simulator finished, and the timestamp of the
+// last one.
+func FastForward(sim Simulator, n uint64) (uint64, time.Time) {
+	if paced, ok := sim.(*PacedSimulator); ok {
+		sim = paced.Simulator
+	}
+	var skipped uint64
+	var last time.Time
+	p := data.NewPoint()
+	for skipped < n && !sim.Finished() {
+		p.Reset()
+		if !sim.Next(p) {
+			continue
+		}
+		skipped++
+		if ts := p.Timestamp(); ts != nil {
+			last = *ts
+		}
+	}
+	return skipped, last
+}
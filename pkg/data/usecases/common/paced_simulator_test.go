@@ -0,0 +1,131 @@
+package common
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bodhiye/tsbs/pkg/data"
+)
+
+// timestampSimulator makes points with timestamps interval apart.
+type timestampSimulator struct {
+	Simulator
+	start    time.Time
+	interval time.Duration
+	made     int
+}
+
+func (s *timestampSimulator) Next(p *data.Point) bool {
+	ts := s.start.Add(time.Duration(s.made) * s.interval)
+	p.SetTimestamp(&ts)
+	s.made++
+	return true
+}
+
+func (s *timestampSimulator) Finished() bool {
+	return false
+}
+
+func TestPacedSimulatorNext(t *testing.T) {
+	simStart := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	wallStart := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		desc         string
+		acceleration float64
+		shiftToNow   bool
+		wantSleeps   []time.Duration
+	}{
+		{
+			desc:       "real time",
+			wantSleeps: []time.Duration{10 * time.Second, 10 * time.Second},
+		},
+		{
+			desc:         "accelerated",
+			acceleration: 10,
+			wantSleeps:   []time.Duration{time.Second, time.Second},
+		},
+		{
+			desc:       "shifted to now",
+			shiftToNow: true,
+			wantSleeps: []time.Duration{10 * time.Second, 10 * time.Second},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			sim := NewPacedSimulator(&timestampSimulator{start: simStart, interval: 10 * time.Second}, c.acceleration, c.shiftToNow)
+			now := wallStart
+			var sleeps []time.Duration
+			sim.now = func() time.Time { return now }
+			sim.sleep = func(d time.Duration) {
+				sleeps = append(sleeps, d)
+				now = now.Add(d)
+			}
+
+			for i := 0; i < 3; i++ {
+				p := data.NewPoint()
+				if !sim.Next(p) {
+					t.Fatalf("point %d was not written", i)
+				}
+				want := simStart.Add(time.Duration(i) * 10 * time.Second)
+				if c.shiftToNow {
+					want = now
+				}
+				if got := *p.Timestamp(); !got.Equal(want) {
+					t.Errorf("point %d has incorrect timestamp: got %v want %v", i, got, want)
+				}
+			}
+
+			if len(sleeps) != len(c.wantSleeps) {
+				t.Fatalf("incorrect number of sleeps: got %v want %v", sleeps, c.wantSleeps)
+			}
+			for i := range sleeps {
+				if sleeps[i] != c.wantSleeps[i] {
+					t.Errorf("incorrect sleep %d: got %v want %v", i, sleeps[i], c.wantSleeps[i])
+				}
+			}
+		})
+	}
+}
+
+func TestPacedSimulatorNextNoWaitWhenBehind(t *testing.T) {
+	simStart := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	sim := NewPacedSimulator(&timestampSimulator{start: simStart, interval: time.Second}, 1, false)
+	now := time.Now()
+	sim.now = func() time.Time {
+		// the wall clock advances faster than the simulated data
+		now = now.Add(2 * time.Second)
+		return now
+	}
+	sim.sleep = func(d time.Duration) {
+		t.Errorf("unexpected sleep of %v", d)
+	}
+	for i := 0; i < 3; i++ {
+		sim.Next(data.NewPoint())
+	}
+}
+
+func TestFastForward(t *testing.T) {
+	simStart := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	sim := NewPacedSimulator(&timestampSimulator{start: simStart, interval: 10 * time.Second}, 1, false)
+	now := time.Now()
+	sim.now = func() time.Time { return now }
+	sim.sleep = func(d time.Duration) {
+		t.Errorf("unexpected sleep of %v", d)
+	}
+
+	skipped, last := FastForward(sim, 3)
+	if skipped != 3 {
+		t.Errorf("incorrect number of points skipped: got %d want 3", skipped)
+	}
+	if want := simStart.Add(20 * time.Second); !last.Equal(want) {
+		t.Errorf("incorrect timestamp of the last point skipped: got %v want %v", last, want)
+	}
+	// the pacing starts with the first point after the skipped ones
+	p := data.NewPoint()
+	sim.Next(p)
+	if want := simStart.Add(30 * time.Second); !p.Timestamp().Equal(want) {
+		t.Errorf("incorrect timestamp of the next point: got %v want %v", *p.Timestamp(), want)
+	}
+}
@@ -67,6 +67,10 @@ type GeneratedDataHeaders struct {
 	TagTypes  []string
 	TagKeys   []string
 	FieldKeys map[string][]string
+	// FieldTypes optionally holds the type of each field of a measurement,
+	// in the order of FieldKeys. The fields of measurements without types
+	// are numbers.
+	FieldTypes map[string][]string
 }
 
 // Simulator simulates a use case.
@@ -0,0 +1,116 @@
+package custom
+
+import (
+	"fmt"
+
+	"github.com/bodhiye/tsbs/pkg/data/usecases/common"
+)
+
+// Distribution types of a DistributionSpec, named after the constructors
+// of the common package.
+const (
+	// DistributionND is a normal distribution of Mean and StdDev.
+	DistributionND = "ND"
+	// DistributionUD is a uniform distribution between Low and High.
+	DistributionUD = "UD"
+	// DistributionWD is a random walk of Step from State.
+	DistributionWD = "WD"
+	// DistributionCWD is a random walk of Step from State, clamped between
+	// Min and Max.
+	DistributionCWD = "CWD"
+	// DistributionMWD is an increasing random walk of Step from State.
+	DistributionMWD = "MWD"
+	// DistributionLD is a distribution that takes the next value of Dist
+	// only when Motive is at least Threshold.
+	DistributionLD = "LD"
+	// DistributionFP rounds Step down to Precision decimals.
+	DistributionFP = "FP"
+	// DistributionConstant is always Value.
+	DistributionConstant = "constant"
+)
+
+// DistributionSpec defines a common.Distribution. Only the parameters of
+// its Type are used.
+type DistributionSpec struct {
+	Type      string  `yaml:"type"`
+	Mean      float64 `yaml:"mean"`
+	StdDev    float64 `yaml:"stddev"`
+	Low       float64 `yaml:"low"`
+	High      float64 `yaml:"high"`
+	Min       float64 `yaml:"min"`
+	Max       float64 `yaml:"max"`
+	State     float64 `yaml:"state"`
+	Threshold float64 `yaml:"threshold"`
+	Value     float64 `yaml:"value"`
+	Precision int     `yaml:"precision"`
+
+	Step   *DistributionSpec `yaml:"step"`
+	Motive *DistributionSpec `yaml:"motive"`
+	Dist   *DistributionSpec `yaml:"dist"`
+}
+
+func (s *DistributionSpec) validate() error {
+	switch s.Type {
+	case DistributionND:
+		if s.StdDev < 0 {
+			return fmt.Errorf("%s stddev cannot be negative", s.Type)
+		}
+	case DistributionUD:
+		if s.Low > s.High {
+			return fmt.Errorf("%s low cannot be larger than high", s.Type)
+		}
+	case DistributionCWD:
+		if s.Min > s.Max {
+			return fmt.Errorf("%s min cannot be larger than max", s.Type)
+		}
+		return s.validateNested("step", s.Step)
+	case DistributionWD, DistributionMWD, DistributionFP:
+		return s.validateNested("step", s.Step)
+	case DistributionLD:
+		if err := s.validateNested("motive", s.Motive); err != nil {
+			return err
+		}
+		return s.validateNested("dist", s.Dist)
+	case DistributionConstant:
+	default:
+		return fmt.Errorf("unknown distribution type '%s'; choices are %s, %s, %s, %s, %s, %s, %s and %s",
+			s.Type, DistributionND, DistributionUD, DistributionWD, DistributionCWD,
+			DistributionMWD, DistributionLD, DistributionFP, DistributionConstant)
+	}
+	return nil
+}
+
+// validateNested validates the nested distribution key of s.
+func (s *DistributionSpec) validateNested(key string, nested *DistributionSpec) error {
+	if nested == nil {
+		return fmt.Errorf("%s needs a %s distribution", s.Type, key)
+	}
+	if err := nested.validate(); err != nil {
+		return fmt.Errorf("%s of %s: %v", key, s.Type, err)
+	}
+	return nil
+}
+
+// New returns a new common.Distribution as defined by s, which must be
+// valid.
+func (s *DistributionSpec) New() common.Distribution {
+	switch s.Type {
+	case DistributionND:
+		return common.ND(s.Mean, s.StdDev)
+	case DistributionUD:
+		return common.UD(s.Low, s.High)
+	case DistributionWD:
+		return common.WD(s.Step.New(), s.State)
+	case DistributionCWD:
+		return common.CWD(s.Step.New(), s.Min, s.Max, s.State)
+	case DistributionMWD:
+		return common.MWD(s.Step.New(), s.State)
+	case DistributionLD:
+		return common.LD(s.Motive.New(), s.Dist.New(), s.Threshold)
+	case DistributionFP:
+		return common.FP(s.Step.New(), s.Precision)
+	case DistributionConstant:
+		return &common.ConstantDistribution{State: s.Value}
+	}
+	panic(fmt.Sprintf("unknown distribution type '%s'", s.Type))
+}
@@ -0,0 +1,120 @@
+package custom
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bodhiye/tsbs/pkg/data/usecases/common"
+)
+
+func TestDistributionSpecNew(t *testing.T) {
+	nd := &DistributionSpec{Type: DistributionND, Mean: 1, StdDev: 2}
+	cases := []struct {
+		spec *DistributionSpec
+		want common.Distribution
+	}{
+		{
+			spec: nd,
+			want: common.ND(1, 2),
+		},
+		{
+			spec: &DistributionSpec{Type: DistributionUD, Low: 1, High: 2},
+			want: common.UD(1, 2),
+		},
+		{
+			spec: &DistributionSpec{Type: DistributionWD, Step: nd, State: 3},
+			want: common.WD(common.ND(1, 2), 3),
+		},
+		{
+			spec: &DistributionSpec{Type: DistributionCWD, Step: nd, Min: 0, Max: 10, State: 3},
+			want: common.CWD(common.ND(1, 2), 0, 10, 3),
+		},
+		{
+			spec: &DistributionSpec{Type: DistributionMWD, Step: nd, State: 3},
+			want: common.MWD(common.ND(1, 2), 3),
+		},
+		{
+			spec: &DistributionSpec{Type: DistributionLD, Motive: nd, Dist: nd, Threshold: 0.5},
+			want: common.LD(common.ND(1, 2), common.ND(1, 2), 0.5),
+		},
+		{
+			spec: &DistributionSpec{Type: DistributionFP, Step: nd, Precision: 2},
+			want: common.FP(common.ND(1, 2), 2),
+		},
+		{
+			spec: &DistributionSpec{Type: DistributionConstant, Value: 4},
+			want: &common.ConstantDistribution{State: 4},
+		},
+	}
+
+	for _, c := range cases {
+		if err := c.spec.validate(); err != nil {
+			t.Errorf("%s: unexpected error: %v", c.spec.Type, err)
+		}
+		if got := c.spec.New(); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: incorrect distribution: got %+v want %+v", c.spec.Type, got, c.want)
+		}
+	}
+
+	// every New is a distribution of its own
+	a, b := nd.New(), nd.New()
+	if a == b {
+		t.Errorf("New returned the same distribution twice")
+	}
+}
+
+func TestDistributionSpecValidate(t *testing.T) {
+	cases := []struct {
+		desc string
+		spec *DistributionSpec
+		want string
+	}{
+		{
+			desc: "unknown type",
+			spec: &DistributionSpec{Type: "XD"},
+			want: "unknown distribution type 'XD'; choices are ND, UD, WD, CWD, MWD, LD, FP and constant",
+		},
+		{
+			desc: "negative stddev",
+			spec: &DistributionSpec{Type: DistributionND, StdDev: -1},
+			want: "ND stddev cannot be negative",
+		},
+		{
+			desc: "low above high",
+			spec: &DistributionSpec{Type: DistributionUD, Low: 2, High: 1},
+			want: "UD low cannot be larger than high",
+		},
+		{
+			desc: "min above max",
+			spec: &DistributionSpec{Type: DistributionCWD, Min: 2, Max: 1, Step: &DistributionSpec{Type: DistributionConstant}},
+			want: "CWD min cannot be larger than max",
+		},
+		{
+			desc: "no step",
+			spec: &DistributionSpec{Type: DistributionMWD},
+			want: "MWD needs a step distribution",
+		},
+		{
+			desc: "no motive",
+			spec: &DistributionSpec{Type: DistributionLD, Dist: &DistributionSpec{Type: DistributionConstant}},
+			want: "LD needs a motive distribution",
+		},
+		{
+			desc: "bad nested distribution",
+			spec: &DistributionSpec{Type: DistributionWD, Step: &DistributionSpec{Type: DistributionFP}},
+			want: "step of WD: FP needs a step distribution",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			err := c.spec.validate()
+			if err == nil {
+				t.Fatalf("unexpected lack of error")
+			}
+			if got := err.Error(); got != c.want {
+				t.Errorf("incorrect error: got\n%s\nwant\n%s", got, c.want)
+			}
+		})
+	}
+}
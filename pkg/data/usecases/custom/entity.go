@@ -0,0 +1,105 @@
+package custom
+
+import (
+	"math/rand"
+	"strconv"
+	"time"
+
+	"github.com/bodhiye/tsbs/pkg/data"
+	"github.com/bodhiye/tsbs/pkg/data/usecases/common"
+)
+
+// measurementKind is a MeasurementSpec ready to make the measurements of
+// the entities.
+type measurementKind struct {
+	name   []byte
+	labels []common.LabeledDistributionMaker
+	ints   []bool
+}
+
+func newMeasurementKind(spec *MeasurementSpec) *measurementKind {
+	k := &measurementKind{
+		name:   []byte(spec.Name),
+		labels: make([]common.LabeledDistributionMaker, len(spec.Fields)),
+		ints:   make([]bool, len(spec.Fields)),
+	}
+	for i, f := range spec.Fields {
+		k.labels[i] = common.LabeledDistributionMaker{
+			Label:             []byte(f.Name),
+			DistributionMaker: f.Distribution.New,
+		}
+		k.ints[i] = f.Type == FieldTypeInt
+	}
+	return k
+}
+
+// Measurement is a measurement of a custom use case entity.
+type Measurement struct {
+	*common.SubsystemMeasurement
+	kind *measurementKind
+}
+
+func newMeasurement(kind *measurementKind, start time.Time) *Measurement {
+	return &Measurement{
+		SubsystemMeasurement: common.NewSubsystemMeasurementWithDistributionMakers(start, kind.labels),
+		kind:                 kind,
+	}
+}
+
+// ToPoint fills in the point with the fields of the measurement, as int64
+// or float64 as defined by their types.
+func (m *Measurement) ToPoint(p *data.Point) {
+	p.SetMeasurementName(m.kind.name)
+	p.SetTimestamp(&m.Timestamp)
+
+	for i, d := range m.Distributions {
+		if m.kind.ints[i] {
+			p.AppendField(m.kind.labels[i].Label, int64(d.Get()))
+		} else {
+			p.AppendField(m.kind.labels[i].Label, d.Get())
+		}
+	}
+}
+
+// Entity is a simulated entity of the custom use case, reporting all the
+// measurements of the schema with its own tag values.
+type Entity struct {
+	simulatedMeasurements []common.SimulatedMeasurement
+	tags                  []common.Tag
+}
+
+// TickAll advances all Distributions of an Entity.
+func (e *Entity) TickAll(d time.Duration) {
+	for i := range e.simulatedMeasurements {
+		e.simulatedMeasurements[i].Tick(d)
+	}
+}
+
+// Measurements returns the entity measurements.
+func (e Entity) Measurements() []common.SimulatedMeasurement {
+	return e.simulatedMeasurements
+}
+
+// Tags returns the entity tags.
+func (e Entity) Tags() []common.Tag {
+	return e.tags
+}
+
+// tagValue returns the value of the tag for entity i.
+func (t *TagSpec) tagValue(i int) string {
+	n := len(t.Values)
+	if n == 0 {
+		n = t.Cardinality
+	}
+	if n > 0 {
+		if t.Generator == TagGeneratorRandom {
+			i = rand.Intn(n)
+		} else {
+			i %= n
+		}
+	}
+	if len(t.Values) > 0 {
+		return t.Values[i]
+	}
+	return t.Prefix + strconv.Itoa(i)
+}
@@ -0,0 +1,247 @@
+package custom
+
+import (
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// TagGeneratorSequence gives the entities the values of a tag in turn,
+	// so entity i gets value i modulo the cardinality.
+	TagGeneratorSequence = "sequence"
+	// TagGeneratorRandom gives every entity a random value of a tag.
+	TagGeneratorRandom = "random"
+
+	// FieldTypeFloat is a field with float64 values.
+	FieldTypeFloat = "float"
+	// FieldTypeInt is a field with int64 values, truncated from its
+	// distribution.
+	FieldTypeInt = "int"
+
+	errNoMeasurements       = "schema must have at least one measurement"
+	errEmptyName            = "%s must have a name"
+	errDuplicateName        = "%s '%s' is more than once"
+	errNoFields             = "measurement '%s' must have at least one field"
+	errNegativeCardinality  = "cardinality of tag '%s' cannot be negative"
+	errValuesAndCardinality = "tag '%s' cannot have both values and a cardinality"
+	errUnknownTagGenerator  = "unknown generator '%s' of tag '%s'; choices are %s and %s"
+	errRandomUnbounded      = "random tag '%s' needs values or a cardinality"
+	errUnknownFieldType     = "unknown type '%s' of field '%s' of measurement '%s'; choices are %s and %s"
+	errNoDistribution       = "field '%s' of measurement '%s' must have a distribution"
+	errBadDistribution      = "bad distribution of field '%s' of measurement '%s': %v"
+	errNegativeInterval     = "interval of measurement '%s' cannot be negative"
+	errIntervalNotMultiple  = "interval of measurement '%s' (%s) must be a multiple of the log interval (%s)"
+)
+
+// Duration is a time.Duration written as a string like "10s" in a schema.
+type Duration time.Duration
+
+// UnmarshalYAML parses a Duration with time.ParseDuration.
+func (d *Duration) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// Schema defines the data of the custom use case: the tags of every
+// simulated entity and the measurements each entity reports.
+type Schema struct {
+	Tags         []*TagSpec         `yaml:"tags"`
+	Measurements []*MeasurementSpec `yaml:"measurements"`
+}
+
+// TagSpec defines a tag of the entities and how its values are generated.
+// Its values are Values if set, or Prefix followed by a number below
+// Cardinality. Without both, every entity gets its own value, like the
+// hostname of a host.
+type TagSpec struct {
+	Name string `yaml:"name"`
+	// Values are the values of the tag, if set
+	Values []string `yaml:"values"`
+	// Cardinality is the number of values of the tag without Values
+	Cardinality int `yaml:"cardinality"`
+	// Prefix is the prefix of the numbered values; defaults to Name + "_"
+	Prefix string `yaml:"prefix"`
+	// Generator is TagGeneratorSequence (default) or TagGeneratorRandom
+	Generator string `yaml:"generator"`
+}
+
+// MeasurementSpec defines a measurement reported by every entity.
+type MeasurementSpec struct {
+	Name string `yaml:"name"`
+	// Interval is how often the measurement is reported, a multiple of the
+	// log interval; defaults to the log interval
+	Interval Duration     `yaml:"interval"`
+	Fields   []*FieldSpec `yaml:"fields"`
+}
+
+// FieldSpec defines a field of a measurement and the distribution of its
+// values.
+type FieldSpec struct {
+	Name string `yaml:"name"`
+	// Type is FieldTypeFloat (default) or FieldTypeInt
+	Type         string            `yaml:"type"`
+	Distribution *DistributionSpec `yaml:"distribution"`
+}
+
+// ReadSchemaFile reads and validates a Schema from a YAML file, e.g.:
+//
+//	# sensors.yaml
+//	tags:
+//	  - name: sensor_id      # sensor_0, sensor_1, ... one per entity
+//	  - name: site
+//	    cardinality: 10      # site_0 to site_9
+//	    generator: random
+//	  - name: kind
+//	    values: [temperature, humidity]
+//	measurements:
+//	  - name: reading
+//	    fields:
+//	      - name: value
+//	        distribution:
+//	          type: CWD
+//	          step: {type: ND, mean: 0, stddev: 1}
+//	          min: -40
+//	          max: 60
+//	          state: 20
+//	      - name: battery
+//	        type: int
+//	        distribution: {type: UD, low: 0, high: 100}
+//	  - name: status
+//	    interval: 1m
+//	    fields:
+//	      - name: uptime
+//	        type: int
+//	        distribution: {type: MWD, step: {type: constant, value: 60}}
+func ReadSchemaFile(fileName string) (*Schema, error) {
+	contents, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("could not read schema file: %v", err)
+	}
+	s, err := ParseSchema(contents)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema file %s: %v", fileName, err)
+	}
+	return s, nil
+}
+
+// ParseSchema parses and validates a Schema from YAML.
+func ParseSchema(contents []byte) (*Schema, error) {
+	s := &Schema{}
+	if err := yaml.UnmarshalStrict(contents, s); err != nil {
+		return nil, err
+	}
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Schema) validate() error {
+	if len(s.Measurements) == 0 {
+		return fmt.Errorf(errNoMeasurements)
+	}
+
+	seen := make(map[string]bool, len(s.Tags))
+	for _, t := range s.Tags {
+		if err := checkName("tag", t.Name, seen); err != nil {
+			return err
+		}
+		if err := t.validate(); err != nil {
+			return err
+		}
+	}
+
+	seen = make(map[string]bool, len(s.Measurements))
+	for _, m := range s.Measurements {
+		if err := checkName("measurement", m.Name, seen); err != nil {
+			return err
+		}
+		if err := m.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkName checks that name is set and not in seen, and adds it to seen.
+func checkName(kind, name string, seen map[string]bool) error {
+	if name == "" {
+		return fmt.Errorf(errEmptyName, kind)
+	}
+	if seen[name] {
+		return fmt.Errorf(errDuplicateName, kind, name)
+	}
+	seen[name] = true
+	return nil
+}
+
+func (t *TagSpec) validate() error {
+	if t.Cardinality < 0 {
+		return fmt.Errorf(errNegativeCardinality, t.Name)
+	}
+	if len(t.Values) > 0 && t.Cardinality > 0 {
+		return fmt.Errorf(errValuesAndCardinality, t.Name)
+	}
+	switch t.Generator {
+	case "", TagGeneratorSequence:
+	case TagGeneratorRandom:
+		if len(t.Values) == 0 && t.Cardinality == 0 {
+			return fmt.Errorf(errRandomUnbounded, t.Name)
+		}
+	default:
+		return fmt.Errorf(errUnknownTagGenerator, t.Generator, t.Name, TagGeneratorSequence, TagGeneratorRandom)
+	}
+	if t.Prefix == "" {
+		t.Prefix = t.Name + "_"
+	}
+	return nil
+}
+
+func (m *MeasurementSpec) validate() error {
+	if m.Interval < 0 {
+		return fmt.Errorf(errNegativeInterval, m.Name)
+	}
+	if len(m.Fields) == 0 {
+		return fmt.Errorf(errNoFields, m.Name)
+	}
+	seen := make(map[string]bool, len(m.Fields))
+	for _, f := range m.Fields {
+		if err := checkName(fmt.Sprintf("field of measurement '%s'", m.Name), f.Name, seen); err != nil {
+			return err
+		}
+		switch f.Type {
+		case "", FieldTypeFloat, FieldTypeInt:
+		default:
+			return fmt.Errorf(errUnknownFieldType, f.Type, f.Name, m.Name, FieldTypeFloat, FieldTypeInt)
+		}
+		if f.Distribution == nil {
+			return fmt.Errorf(errNoDistribution, f.Name, m.Name)
+		}
+		if err := f.Distribution.validate(); err != nil {
+			return fmt.Errorf(errBadDistribution, f.Name, m.Name, err)
+		}
+	}
+	return nil
+}
+
+// CheckInterval checks that the measurement intervals are multiples of the
+// log interval, the time between two ticks of the simulation.
+func (s *Schema) CheckInterval(logInterval time.Duration) error {
+	for _, m := range s.Measurements {
+		if m.Interval%Duration(logInterval) != 0 {
+			return fmt.Errorf(errIntervalNotMultiple, m.Name, time.Duration(m.Interval), logInterval)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package custom
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testSchema = `
+tags:
+  - name: sensor_id
+  - name: site
+    cardinality: 10
+    generator: random
+  - name: kind
+    values: [temperature, humidity]
+measurements:
+  - name: reading
+    fields:
+      - name: value
+        distribution:
+          type: CWD
+          step: {type: ND, mean: 0, stddev: 1}
+          min: -40
+          max: 60
+          state: 20
+      - name: battery
+        type: int
+        distribution: {type: UD, low: 0, high: 100}
+  - name: status
+    interval: 1m
+    fields:
+      - name: uptime
+        type: int
+        distribution: {type: MWD, step: {type: constant, value: 60}}
+`
+
+func TestReadSchemaFile(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "schema.yaml")
+	if err := ioutil.WriteFile(fileName, []byte(testSchema), 0644); err != nil {
+		t.Fatalf("could not write schema file: %v", err)
+	}
+	s, err := ReadSchemaFile(fileName)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := len(s.Tags); got != 3 {
+		t.Fatalf("incorrect number of tags: got %d want 3", got)
+	}
+	if got := s.Tags[0].Prefix; got != "sensor_id_" {
+		t.Errorf("incorrect default prefix: got %s want sensor_id_", got)
+	}
+	if got := s.Tags[1].Generator; got != TagGeneratorRandom {
+		t.Errorf("incorrect generator: got %s want %s", got, TagGeneratorRandom)
+	}
+	if got := len(s.Measurements); got != 2 {
+		t.Fatalf("incorrect number of measurements: got %d want 2", got)
+	}
+	if got := time.Duration(s.Measurements[1].Interval); got != time.Minute {
+		t.Errorf("incorrect interval: got %s want 1m", got)
+	}
+	if got := s.Measurements[0].Fields[0].Distribution.Step.StdDev; got != 1 {
+		t.Errorf("incorrect nested distribution parameter: got %v want 1", got)
+	}
+
+	if _, err := ReadSchemaFile(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Errorf("unexpected lack of error for missing file")
+	}
+}
+
+func TestParseSchemaErrors(t *testing.T) {
+	const fields = "fields: [{name: f, distribution: {type: constant}}]"
+	cases := []struct {
+		desc     string
+		contents string
+		want     string
+	}{
+		{
+			desc:     "no measurements",
+			contents: "tags: [{name: a}]",
+			want:     errNoMeasurements,
+		},
+		{
+			desc:     "unknown key",
+			contents: "measurements: [{name: m, " + fields + "}]\nfoo: 1",
+			want:     "field foo not found",
+		},
+		{
+			desc:     "tag without name",
+			contents: "tags: [{cardinality: 2}]\nmeasurements: [{name: m, " + fields + "}]",
+			want:     "tag must have a name",
+		},
+		{
+			desc:     "duplicate tag",
+			contents: "tags: [{name: a}, {name: a}]\nmeasurements: [{name: m, " + fields + "}]",
+			want:     "tag 'a' is more than once",
+		},
+		{
+			desc:     "negative cardinality",
+			contents: "tags: [{name: a, cardinality: -1}]\nmeasurements: [{name: m, " + fields + "}]",
+			want:     "cardinality of tag 'a' cannot be negative",
+		},
+		{
+			desc:     "values and cardinality",
+			contents: "tags: [{name: a, cardinality: 2, values: [x]}]\nmeasurements: [{name: m, " + fields + "}]",
+			want:     "tag 'a' cannot have both values and a cardinality",
+		},
+		{
+			desc:     "unbounded random tag",
+			contents: "tags: [{name: a, generator: random}]\nmeasurements: [{name: m, " + fields + "}]",
+			want:     "random tag 'a' needs values or a cardinality",
+		},
+		{
+			desc:     "unknown tag generator",
+			contents: "tags: [{name: a, generator: zipf}]\nmeasurements: [{name: m, " + fields + "}]",
+			want:     "unknown generator 'zipf' of tag 'a'",
+		},
+		{
+			desc:     "duplicate measurement",
+			contents: "measurements: [{name: m, " + fields + "}, {name: m, " + fields + "}]",
+			want:     "measurement 'm' is more than once",
+		},
+		{
+			desc:     "no fields",
+			contents: "measurements: [{name: m}]",
+			want:     "measurement 'm' must have at least one field",
+		},
+		{
+			desc:     "bad interval",
+			contents: "measurements: [{name: m, interval: often, " + fields + "}]",
+			want:     "invalid duration",
+		},
+		{
+			desc:     "negative interval",
+			contents: "measurements: [{name: m, interval: -1s, " + fields + "}]",
+			want:     "interval of measurement 'm' cannot be negative",
+		},
+		{
+			desc:     "duplicate field",
+			contents: "measurements: [{name: m, fields: [{name: f, distribution: {type: constant}}, {name: f, distribution: {type: constant}}]}]",
+			want:     "field of measurement 'm' 'f' is more than once",
+		},
+		{
+			desc:     "unknown field type",
+			contents: "measurements: [{name: m, fields: [{name: f, type: bool, distribution: {type: constant}}]}]",
+			want:     "unknown type 'bool' of field 'f' of measurement 'm'",
+		},
+		{
+			desc:     "no distribution",
+			contents: "measurements: [{name: m, fields: [{name: f}]}]",
+			want:     "field 'f' of measurement 'm' must have a distribution",
+		},
+		{
+			desc:     "bad distribution",
+			contents: "measurements: [{name: m, fields: [{name: f, distribution: {type: CWD}}]}]",
+			want:     "bad distribution of field 'f' of measurement 'm': CWD needs a step distribution",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			_, err := ParseSchema([]byte(c.contents))
+			if err == nil {
+				t.Fatalf("unexpected lack of error")
+			}
+			if got := err.Error(); !strings.Contains(got, c.want) {
+				t.Errorf("incorrect error: got\n%s\nwant it to contain\n%s", got, c.want)
+			}
+		})
+	}
+}
+
+func TestSchemaCheckInterval(t *testing.T) {
+	s, err := ParseSchema([]byte(testSchema))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := s.CheckInterval(10 * time.Second); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	err = s.CheckInterval(7 * time.Second)
+	if err == nil {
+		t.Fatalf("unexpected lack of error")
+	}
+	want := "interval of measurement 'status' (1m0s) must be a multiple of the log interval (7s)"
+	if got := err.Error(); got != want {
+		t.Errorf("incorrect error: got\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,86 @@
+package custom
+
+import (
+	"time"
+
+	"github.com/bodhiye/tsbs/pkg/data"
+	"github.com/bodhiye/tsbs/pkg/data/usecases/common"
+)
+
+// SimulatorConfig is used to create a Simulator of the entities defined by
+// a Schema. It fulfills the common.SimulatorConfig interface.
+type SimulatorConfig struct {
+	// Start is the beginning time for the Simulator
+	Start time.Time
+	// End is the ending time for the Simulator
+	End time.Time
+	// InitEntityCount is the number of entities to start with in the first reporting period
+	InitEntityCount uint64
+	// EntityCount is the total number of entities to have in the last reporting period
+	EntityCount uint64
+	// Schema defines the tags and measurements of the entities
+	Schema *Schema
+}
+
+// NewSimulator produces a Simulator of the Schema entities over the
+// specified interval and points limit. The points of measurements with a
+// longer interval are skipped in between, but still count towards the
+// limit.
+func (sc *SimulatorConfig) NewSimulator(interval time.Duration, limit uint64) common.Simulator {
+	kinds := make([]*measurementKind, len(sc.Schema.Measurements))
+	intervals := make(map[string]time.Duration, len(sc.Schema.Measurements))
+	for i, m := range sc.Schema.Measurements {
+		kinds[i] = newMeasurementKind(m)
+		if m.Interval > 0 {
+			intervals[m.Name] = time.Duration(m.Interval)
+		}
+	}
+
+	base := &common.BaseSimulatorConfig{
+		Start:              sc.Start,
+		End:                sc.End,
+		InitGeneratorScale: sc.InitEntityCount,
+		GeneratorScale:     sc.EntityCount,
+		GeneratorConstructor: func(i int, start time.Time) common.Generator {
+			return sc.newEntity(i, start, kinds)
+		},
+	}
+	return &Simulator{
+		Simulator: base.NewSimulator(interval, limit),
+		start:     sc.Start,
+		intervals: intervals,
+	}
+}
+
+func (sc *SimulatorConfig) newEntity(i int, start time.Time, kinds []*measurementKind) common.Generator {
+	e := &Entity{
+		simulatedMeasurements: make([]common.SimulatedMeasurement, len(kinds)),
+		tags:                  make([]common.Tag, len(sc.Schema.Tags)),
+	}
+	for j, t := range sc.Schema.Tags {
+		e.tags[j] = common.Tag{Key: []byte(t.Name), Value: t.tagValue(i)}
+	}
+	for j, k := range kinds {
+		e.simulatedMeasurements[j] = newMeasurement(k, start)
+	}
+	return e
+}
+
+// Simulator simulates the entities of a Schema, only releasing the points
+// of a measurement every interval of the measurement.
+type Simulator struct {
+	common.Simulator
+	start     time.Time
+	intervals map[string]time.Duration
+}
+
+// Next advances a Point to the next state in the simulator, telling
+// whether it is due to be written.
+func (s *Simulator) Next(p *data.Point) bool {
+	write := s.Simulator.Next(p)
+	interval, ok := s.intervals[string(p.MeasurementName())]
+	if !ok {
+		return write
+	}
+	return write && p.Timestamp().Sub(s.start)%interval == 0
+}
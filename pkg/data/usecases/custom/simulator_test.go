@@ -0,0 +1,97 @@
+package custom
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bodhiye/tsbs/pkg/data"
+)
+
+func newTestSimulatorConfig(t *testing.T) *SimulatorConfig {
+	s, err := ParseSchema([]byte(testSchema))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	start := time.Date(2016, time.January, 1, 0, 0, 0, 0, time.UTC)
+	return &SimulatorConfig{
+		Start:           start,
+		End:             start.Add(2 * time.Minute),
+		InitEntityCount: 3,
+		EntityCount:     3,
+		Schema:          s,
+	}
+}
+
+func TestSimulatorHeaders(t *testing.T) {
+	rand.Seed(123)
+	sim := newTestSimulatorConfig(t).NewSimulator(10*time.Second, 0)
+	headers := sim.Headers()
+
+	if got, want := headers.TagKeys, []string{"sensor_id", "site", "kind"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("incorrect tag keys: got %v want %v", got, want)
+	}
+	if got, want := headers.TagTypes, []string{"string", "string", "string"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("incorrect tag types: got %v want %v", got, want)
+	}
+	want := map[string][]string{
+		"reading": {"value", "battery"},
+		"status":  {"uptime"},
+	}
+	if got := headers.FieldKeys; !reflect.DeepEqual(got, want) {
+		t.Errorf("incorrect field keys: got %v want %v", got, want)
+	}
+}
+
+func TestSimulatorNext(t *testing.T) {
+	rand.Seed(123)
+	sim := newTestSimulatorConfig(t).NewSimulator(10*time.Second, 0)
+
+	counts := map[string]int{}
+	tags := map[string][]interface{}{}
+	for !sim.Finished() {
+		p := data.NewPoint()
+		if !sim.Next(p) {
+			continue
+		}
+		name := string(p.MeasurementName())
+		counts[name]++
+
+		if name == "status" && p.Timestamp().Second() != 0 {
+			t.Errorf("status point not on its 1m interval: %v", p.Timestamp())
+		}
+		if _, ok := p.GetFieldValue([]byte("battery")).(int64); name == "reading" && !ok {
+			t.Errorf("battery is not an int64: %v", p.GetFieldValue([]byte("battery")))
+		}
+		if _, ok := p.GetFieldValue([]byte("value")).(float64); name == "reading" && !ok {
+			t.Errorf("value is not a float64: %v", p.GetFieldValue([]byte("value")))
+		}
+		id := p.GetTagValue([]byte("sensor_id")).(string)
+		tags[id] = p.TagValues()
+	}
+
+	// 12 readings of 3 sensors every 10s and 2 statuses every 1m
+	if got, want := counts, map[string]int{"reading": 36, "status": 6}; !reflect.DeepEqual(got, want) {
+		t.Errorf("incorrect number of points: got %v want %v", got, want)
+	}
+
+	if got := len(tags); got != 3 {
+		t.Fatalf("incorrect number of sensors: got %d want 3", got)
+	}
+	kinds := []string{"temperature", "humidity", "temperature"}
+	for i, kind := range kinds {
+		values, ok := tags[fmt.Sprintf("sensor_id_%d", i)]
+		if !ok {
+			t.Fatalf("no points of sensor_id_%d", i)
+		}
+		if got := values[2]; got != kind {
+			t.Errorf("incorrect kind of sensor_id_%d: got %v want %s", i, got, kind)
+		}
+		if site := values[1].(string); !strings.HasPrefix(site, "site_") || len(site) != len("site_0") {
+			t.Errorf("incorrect site of sensor_id_%d: got %s", i, site)
+		}
+	}
+}
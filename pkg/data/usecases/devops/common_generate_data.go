@@ -14,6 +14,8 @@ type HostContext struct {
 	// used for devops-generic use-case
 	metricCount  uint64 // number of metrics to generate
 	epochsToLive uint64 // number of epochs to live
+	// tags are the choices of tag values, nil for the default ones
+	tags *hostTags
 }
 
 type commonDevopsSimulatorConfig struct {
@@ -29,14 +31,16 @@ type commonDevopsSimulatorConfig struct {
 	HostConstructor func(ctx *HostContext) Host
 	// MaxMetricCount is the max number of metrics per host to create when using generic-devops use-case
 	MaxMetricCount uint64
+	// Tags sets the cardinality and the length of the tag values of the hosts
+	Tags TagConfig
 }
 
 func NewHostCtx(id int, start time.Time) *HostContext {
-	return &HostContext{id, start, 0, 0}
+	return &HostContext{id, start, 0, 0, nil}
 }
 
 func NewHostCtxTime(start time.Time) *HostContext {
-	return &HostContext{0, start, 0, 0}
+	return &HostContext{0, start, 0, 0, nil}
 }
 
 func calculateEpochs(c commonDevopsSimulatorConfig, interval time.Duration) uint64 {
@@ -49,6 +53,8 @@ type commonDevopsSimulator struct {
 
 	hostIndex uint64
 	hosts     []Host
+	// extraTagKeys are the keys of the synthetic tags of the hosts
+	extraTagKeys [][]byte
 
 	epoch      uint64
 	epochs     uint64
@@ -73,16 +79,19 @@ func (s *commonDevopsSimulator) Fields() map[string][]string {
 }
 
 func (s *commonDevopsSimulator) TagKeys() []string {
-	tagKeysAsStr := make([]string, len(MachineTagKeys))
-	for i, t := range MachineTagKeys {
-		tagKeysAsStr[i] = string(t)
+	tagKeysAsStr := make([]string, 0, len(MachineTagKeys)+len(s.extraTagKeys))
+	for _, t := range MachineTagKeys {
+		tagKeysAsStr = append(tagKeysAsStr, string(t))
+	}
+	for _, t := range s.extraTagKeys {
+		tagKeysAsStr = append(tagKeysAsStr, string(t))
 	}
 	return tagKeysAsStr
 }
 
 func (s *commonDevopsSimulator) TagTypes() []string {
-	types := make([]string, len(MachineTagKeys))
-	for i := 0; i < len(MachineTagKeys); i++ {
+	types := make([]string, len(MachineTagKeys)+len(s.extraTagKeys))
+	for i := 0; i < len(types); i++ {
 		types[i] = machineTagType.String()
 	}
 	return types
@@ -125,6 +134,9 @@ func (s *commonDevopsSimulator) populatePoint(p *data.Point, measureIdx int) boo
 	p.AppendTag(MachineTagKeys[7], host.Service)
 	p.AppendTag(MachineTagKeys[8], host.ServiceVersion)
 	p.AppendTag(MachineTagKeys[9], host.ServiceEnvironment)
+	for i, v := range host.ExtraTags {
+		p.AppendTag(s.extraTagKeys[i], v)
+	}
 
 	// Populate measurement-specific tags and fields:
 	host.SimulatedMeasurements[measureIdx].ToPoint(p)
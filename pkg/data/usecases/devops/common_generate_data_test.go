@@ -305,3 +305,29 @@ func TestCommonDevopsSimulatorTagTypes(t *testing.T) {
 		}
 	}
 }
+
+func TestCommonDevopsSimulatorExtraTags(t *testing.T) {
+	s := &commonDevopsSimulator{extraTagKeys: [][]byte{[]byte("tag_0"), []byte("tag_1")}}
+	host := Host{ExtraTags: []string{"3", "7"}}
+	host.SimulatedMeasurements = []common.SimulatedMeasurement{NewCPUMeasurement(time.Now())}
+	s.hosts = append(s.hosts, host)
+
+	keys := s.TagKeys()
+	if got := len(keys); got != len(MachineTagKeys)+2 {
+		t.Fatalf("incorrect number of tag keys: got %d want %d", got, len(MachineTagKeys)+2)
+	}
+	if got := keys[len(keys)-2:]; got[0] != "tag_0" || got[1] != "tag_1" {
+		t.Errorf("incorrect extra tag keys: got %v", got)
+	}
+	if got := len(s.TagTypes()); got != len(keys) {
+		t.Errorf("incorrect number of tag types: got %d want %d", got, len(keys))
+	}
+
+	p := data.NewPoint()
+	s.populatePoint(p, 0)
+	for i, want := range host.ExtraTags {
+		if got := p.GetTagValue(s.extraTagKeys[i]).(string); got != want {
+			t.Errorf("incorrect extra tag %d: got %s want %s", i, got, want)
+		}
+	}
+}
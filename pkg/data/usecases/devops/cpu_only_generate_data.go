@@ -47,9 +47,10 @@ type CPUOnlySimulatorConfig commonDevopsSimulatorConfig
 
 // NewSimulator produces a Simulator that conforms to the given SimulatorConfig over the specified interval
 func (c *CPUOnlySimulatorConfig) NewSimulator(interval time.Duration, limit uint64) common.Simulator {
+	tags := c.Tags.hostTags()
 	hostInfos := make([]Host, c.HostCount)
 	for i := 0; i < len(hostInfos); i++ {
-		hostInfos[i] = c.HostConstructor(NewHostCtx(i, c.Start))
+		hostInfos[i] = c.HostConstructor(&HostContext{i, c.Start, 0, 0, tags})
 	}
 
 	epochs := calculateEpochs(commonDevopsSimulatorConfig(*c), interval)
@@ -62,8 +63,9 @@ func (c *CPUOnlySimulatorConfig) NewSimulator(interval time.Duration, limit uint
 		madePoints: 0,
 		maxPoints:  maxPoints,
 
-		hostIndex: 0,
-		hosts:     hostInfos,
+		hostIndex:    0,
+		hosts:        hostInfos,
+		extraTagKeys: tags.extraTagKeys,
 
 		epoch:          0,
 		epochs:         epochs,
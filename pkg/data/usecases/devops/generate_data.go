@@ -35,38 +35,15 @@ func (d *DevopsSimulator) Next(p *data.Point) bool {
 	return d.populatePoint(p, d.simulatedMeasurementIndex)
 }
 
-func (s *DevopsSimulator) TagKeys() []string {
-	tagKeysAsStr := make([]string, len(MachineTagKeys))
-	for i, t := range MachineTagKeys {
-		tagKeysAsStr[i] = string(t)
-	}
-	return tagKeysAsStr
-}
-
-func (s *DevopsSimulator) TagTypes() []string {
-	types := make([]string, len(MachineTagKeys))
-	for i := 0; i < len(MachineTagKeys); i++ {
-		types[i] = machineTagType.String()
-	}
-	return types
-}
-
-func (d *DevopsSimulator) Headers() *common.GeneratedDataHeaders {
-	return &common.GeneratedDataHeaders{
-		TagTypes:  d.TagTypes(),
-		TagKeys:   d.TagKeys(),
-		FieldKeys: d.Fields(),
-	}
-}
-
 // DevopsSimulatorConfig is used to create a DevopsSimulator.
 type DevopsSimulatorConfig commonDevopsSimulatorConfig
 
 // NewSimulator produces a Simulator that conforms to the given SimulatorConfig over the specified interval
 func (d *DevopsSimulatorConfig) NewSimulator(interval time.Duration, limit uint64) common.Simulator {
+	tags := d.Tags.hostTags()
 	hostInfos := make([]Host, d.HostCount)
 	for i := 0; i < len(hostInfos); i++ {
-		hostInfos[i] = d.HostConstructor(NewHostCtx(i, d.Start))
+		hostInfos[i] = d.HostConstructor(&HostContext{i, d.Start, 0, 0, tags})
 	}
 
 	epochs := calculateEpochs(commonDevopsSimulatorConfig(*d), interval)
@@ -80,8 +57,9 @@ func (d *DevopsSimulatorConfig) NewSimulator(interval time.Duration, limit uint6
 			madePoints: 0,
 			maxPoints:  maxPoints,
 
-			hostIndex: 0,
-			hosts:     hostInfos,
+			hostIndex:    0,
+			hosts:        hostInfos,
+			extraTagKeys: tags.extraTagKeys,
 
 			epoch:          0,
 			epochs:         epochs,
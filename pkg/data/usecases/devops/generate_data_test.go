@@ -99,3 +99,26 @@ func TestDevopsSimulatorConfigNewSimulator(t *testing.T) {
 	}
 
 }
+
+func TestDevopsSimulatorHeadersExtraTags(t *testing.T) {
+	conf := *testDevopsConf
+	conf.Tags = TagConfig{ExtraTagCount: 3, ExtraTagCardinality: 4}
+	sim := conf.NewSimulator(time.Second, 0).(*DevopsSimulator)
+
+	headers := sim.Headers()
+	if got, want := len(headers.TagKeys), len(MachineTagKeys)+3; got != want {
+		t.Fatalf("incorrect number of tag keys: got %d want %d", got, want)
+	}
+	if got := headers.TagKeys[len(MachineTagKeys)+2]; got != "tag_2" {
+		t.Errorf("incorrect last tag key: got %s want %s", got, "tag_2")
+	}
+	if got, want := len(headers.TagTypes), len(headers.TagKeys); got != want {
+		t.Errorf("incorrect number of tag types: got %d want %d", got, want)
+	}
+
+	p := data.NewPoint()
+	sim.Next(p)
+	if got, want := len(p.TagKeys()), len(headers.TagKeys); got != want {
+		t.Errorf("incorrect number of point tags: got %d want %d", got, want)
+	}
+}
@@ -28,8 +28,9 @@ func (c *GenericMetricsSimulatorConfig) NewSimulator(interval time.Duration, lim
 	hostMetricCount := generateHostMetricCount(c.HostCount, c.MaxMetricCount)
 	epochs := calculateEpochs(commonDevopsSimulatorConfig(*c.DevopsSimulatorConfig), interval)
 	epochsToLive := generateHostEpochsToLive(c.HostCount, epochs)
+	tags := c.Tags.hostTags()
 	for i := 0; i < len(hostInfos); i++ {
-		hostInfos[i] = c.HostConstructor(&HostContext{i, c.Start, hostMetricCount[i], epochsToLive[i]})
+		hostInfos[i] = c.HostConstructor(&HostContext{i, c.Start, hostMetricCount[i], epochsToLive[i], tags})
 	}
 
 	// This is not an optimal upper limit as it doesn't take into account host liveness but should be good enough
@@ -42,8 +43,9 @@ func (c *GenericMetricsSimulatorConfig) NewSimulator(interval time.Duration, lim
 			madePoints: 0,
 			maxPoints:  maxPoints,
 
-			hostIndex: 0,
-			hosts:     hostInfos,
+			hostIndex:    0,
+			hosts:        hostInfos,
+			extraTagKeys: tags.extraTagKeys,
 
 			epoch:          0,
 			epochs:         epochs,
@@ -7,6 +7,7 @@ import (
 	"math/rand"
 	"reflect"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -15,7 +16,14 @@ const (
 	machineRackChoicesPerDatacenter = 100
 	machineServiceChoices           = 20
 	machineServiceVersionChoices    = 2
-	hostFmt                         = "host_%d"
+	// machineDatacenterChoicesPerRegion is the number of datacenters of the
+	// regions beyond the built-in ones
+	machineDatacenterChoicesPerRegion = 3
+	extraTagChoices                   = 10
+	hostFmt                           = "host_%d"
+	hostPrefix                        = "host_"
+	regionFmt                         = "region-%d"
+	extraTagKeyFmt                    = "tag_%d"
 )
 
 type region struct {
@@ -135,6 +143,112 @@ var (
 	machineTagType = reflect.TypeOf("some string")
 )
 
+// TagConfig sets the cardinality and the length of the tag values of the
+// hosts, to study how they affect index size and query speed. Zero values
+// keep the default tags.
+type TagConfig struct {
+	// RegionCount is the number of regions: the built-in ones first, then
+	// regions named region-N
+	RegionCount int
+	// DatacenterCount is the number of datacenters per region, 0 for the
+	// built-in ones of each region
+	DatacenterCount int
+	// RackCount is the number of racks per datacenter
+	RackCount int
+	// ServiceCount is the number of services
+	ServiceCount int
+	// ServiceVersionCount is the number of versions of the services
+	ServiceVersionCount int
+	// ExtraTagCount is the number of synthetic tags, tag_0 to tag_N-1, added
+	// after the default ones
+	ExtraTagCount int
+	// ExtraTagCardinality is the number of values of each synthetic tag
+	ExtraTagCardinality int
+	// TagValueLength is the minimum length of the numbered tag values, i.e.
+	// hostname, rack, service, service_version and the synthetic tags,
+	// which are padded with zeros
+	TagValueLength int
+}
+
+// hostTags are the choices of tag values of the hosts, made from a TagConfig.
+type hostTags struct {
+	regions             []region
+	racks               int64
+	services            int64
+	serviceVersions     int64
+	extraTagKeys        [][]byte
+	extraTagCardinality int64
+	valueLength         int
+}
+
+var defaultHostTags = TagConfig{}.hostTags()
+
+func (c TagConfig) hostTags() *hostTags {
+	t := &hostTags{
+		regions:             regions,
+		racks:               countOrDefault(c.RackCount, machineRackChoicesPerDatacenter),
+		services:            countOrDefault(c.ServiceCount, machineServiceChoices),
+		serviceVersions:     countOrDefault(c.ServiceVersionCount, machineServiceVersionChoices),
+		extraTagCardinality: countOrDefault(c.ExtraTagCardinality, extraTagChoices),
+		valueLength:         c.TagValueLength,
+	}
+	if c.RegionCount > 0 || c.DatacenterCount > 0 {
+		t.regions = newRegions(int(countOrDefault(c.RegionCount, len(regions))), c.DatacenterCount)
+	}
+	for i := 0; i < c.ExtraTagCount; i++ {
+		t.extraTagKeys = append(t.extraTagKeys, []byte(fmt.Sprintf(extraTagKeyFmt, i)))
+	}
+	return t
+}
+
+// randomValue returns a random number below limit, padded to the tag value
+// length.
+func (t *hostTags) randomValue(limit int64) string {
+	return padNumber(getStringRandomInt(limit), t.valueLength)
+}
+
+// newRegions returns regionCount regions of datacenterCount datacenters
+// each. The built-in regions keep their datacenters if datacenterCount is 0.
+func newRegions(regionCount, datacenterCount int) []region {
+	rs := make([]region, regionCount)
+	for i := range rs {
+		if i < len(regions) {
+			rs[i] = regions[i]
+			if datacenterCount == 0 {
+				continue
+			}
+		} else {
+			rs[i].Name = fmt.Sprintf(regionFmt, i)
+		}
+
+		n := datacenterCount
+		if n == 0 {
+			n = machineDatacenterChoicesPerRegion
+		}
+		rs[i].Datacenters = make([]string, n)
+		for j := range rs[i].Datacenters {
+			rs[i].Datacenters[j] = datacenterName(rs[i].Name, j)
+		}
+	}
+	return rs
+}
+
+// datacenterName names the datacenters of a region like the built-in ones,
+// e.g. us-east-1a, then with their number once out of letters.
+func datacenterName(region string, i int) string {
+	if i < 26 {
+		return fmt.Sprintf("%s%c", region, 'a'+i)
+	}
+	return fmt.Sprintf("%s-%d", region, i)
+}
+
+func countOrDefault(count, defaultCount int) int64 {
+	if count > 0 {
+		return int64(count)
+	}
+	return int64(defaultCount)
+}
+
 // Host models a machine being monitored for dev ops
 type Host struct {
 	SimulatedMeasurements []common.SimulatedMeasurement
@@ -150,6 +264,8 @@ type Host struct {
 	Service            string
 	ServiceVersion     string
 	ServiceEnvironment string
+	// ExtraTags are the values of the synthetic tags, see TagConfig
+	ExtraTags []string
 
 	// needed for generic use-casea
 	GenericMetricCount uint64 // number of metrics generated
@@ -215,18 +331,22 @@ func NewHostGenericMetrics(ctx *HostContext) Host {
 func newHostWithMeasurementGenerator(gen generator, ctx *HostContext) Host {
 	sm := gen(ctx)
 
-	region := randomRegionSliceChoice(regions)
+	tags := ctx.tags
+	if tags == nil {
+		tags = defaultHostTags
+	}
+	region := randomRegionSliceChoice(tags.regions)
 
 	h := Host{
 		// Tag Values that are static throughout the life of a Host:
-		Name:               fmt.Sprintf(hostFmt, ctx.id),
+		Name:               HostName(ctx.id, tags.valueLength),
 		Region:             region.Name,
 		Datacenter:         common.RandomStringSliceChoice(region.Datacenters),
-		Rack:               getStringRandomInt(machineRackChoicesPerDatacenter),
+		Rack:               tags.randomValue(tags.racks),
 		Arch:               common.RandomStringSliceChoice(MachineArchChoices),
 		OS:                 common.RandomStringSliceChoice(MachineOSChoices),
-		Service:            getStringRandomInt(machineServiceChoices),
-		ServiceVersion:     getStringRandomInt(machineServiceVersionChoices),
+		Service:            tags.randomValue(tags.services),
+		ServiceVersion:     tags.randomValue(tags.serviceVersions),
 		ServiceEnvironment: common.RandomStringSliceChoice(MachineServiceEnvironmentChoices),
 		Team:               common.RandomStringSliceChoice(MachineTeamChoices),
 
@@ -235,10 +355,25 @@ func newHostWithMeasurementGenerator(gen generator, ctx *HostContext) Host {
 		StartEpoch:            math.MaxUint64,
 		EpochsToLive:          ctx.epochsToLive,
 	}
+	if len(tags.extraTagKeys) > 0 {
+		h.ExtraTags = make([]string, len(tags.extraTagKeys))
+		for i := range h.ExtraTags {
+			h.ExtraTags[i] = tags.randomValue(tags.extraTagCardinality)
+		}
+	}
 
 	return h
 }
 
+// HostName returns the hostname of the host with the given id, its number
+// padded with zeros so that the name is at least valueLength long.
+func HostName(id int, valueLength int) string {
+	if valueLength <= len(hostPrefix) {
+		return fmt.Sprintf(hostFmt, id)
+	}
+	return hostPrefix + padNumber(strconv.Itoa(id), valueLength-len(hostPrefix))
+}
+
 // TickAll advances all Distributions of a Host.
 func (h *Host) TickAll(d time.Duration) {
 	for i := range h.SimulatedMeasurements {
@@ -250,6 +385,14 @@ func getStringRandomInt(limit int64) string {
 	return strconv.FormatInt(rand.Int63n(limit), 10)
 }
 
+// padNumber pads the number n with leading zeros to length.
+func padNumber(n string, length int) string {
+	if len(n) >= length {
+		return n
+	}
+	return strings.Repeat("0", length-len(n)) + n
+}
+
 func randomRegionSliceChoice(s []region) *region {
 	return &s[rand.Intn(len(s))]
 }
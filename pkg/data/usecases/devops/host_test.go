@@ -2,6 +2,7 @@ package devops
 
 import (
 	"fmt"
+	"reflect"
 	"strconv"
 	"testing"
 	"time"
@@ -166,7 +167,7 @@ func TestNewHostGenericMeasurments(t *testing.T) {
 	initGenericMetricFields(metricCount)
 	// test 1000 times to get diversity of results
 	for i := 0; i < 1000; i++ {
-		h := NewHostGenericMetrics(&HostContext{i, now, metricCount, 0})
+		h := NewHostGenericMetrics(&HostContext{i, now, metricCount, 0, nil})
 		if got := len(h.SimulatedMeasurements); got != 1 {
 			t.Errorf("incorrect number of measurements: got %d want %d", got, 1)
 		}
@@ -285,3 +286,109 @@ func TestRandomRegionSliceChoice(t *testing.T) {
 		testIfInRegionSlice(t, regions, r)
 	}
 }
+
+func TestHostName(t *testing.T) {
+	cases := []struct {
+		id          int
+		valueLength int
+		want        string
+	}{
+		{id: 12, valueLength: 0, want: "host_12"},
+		{id: 12, valueLength: 7, want: "host_12"},
+		{id: 12, valueLength: 10, want: "host_00012"},
+		{id: 123456, valueLength: 10, want: "host_123456"},
+	}
+	for _, c := range cases {
+		if got := HostName(c.id, c.valueLength); got != c.want {
+			t.Errorf("incorrect host name for %d and length %d: got %s want %s", c.id, c.valueLength, got, c.want)
+		}
+	}
+}
+
+func TestTagConfigHostTagsDefault(t *testing.T) {
+	tags := TagConfig{}.hostTags()
+	if !reflect.DeepEqual(tags.regions, regions) {
+		t.Errorf("default regions not used: got %v", tags.regions)
+	}
+	if tags.racks != machineRackChoicesPerDatacenter || tags.services != machineServiceChoices ||
+		tags.serviceVersions != machineServiceVersionChoices {
+		t.Errorf("incorrect default counts: got %d racks, %d services, %d versions", tags.racks, tags.services, tags.serviceVersions)
+	}
+	if len(tags.extraTagKeys) != 0 {
+		t.Errorf("unexpected extra tags: %v", tags.extraTagKeys)
+	}
+
+	tags = TagConfig{RegionCount: len(regions)}.hostTags()
+	if !reflect.DeepEqual(tags.regions, regions) {
+		t.Errorf("built-in regions not kept: got %v", tags.regions)
+	}
+}
+
+func TestNewRegions(t *testing.T) {
+	rs := newRegions(11, 0)
+	if got := len(rs); got != 11 {
+		t.Fatalf("incorrect number of regions: got %d want %d", got, 11)
+	}
+	if !reflect.DeepEqual(rs[:len(regions)], regions) {
+		t.Errorf("built-in regions not kept: got %v", rs[:len(regions)])
+	}
+	want := region{"region-10", []string{"region-10a", "region-10b", "region-10c"}}
+	if !reflect.DeepEqual(rs[10], want) {
+		t.Errorf("incorrect synthetic region: got %v want %v", rs[10], want)
+	}
+
+	rs = newRegions(2, 28)
+	for _, r := range rs {
+		if got := len(r.Datacenters); got != 28 {
+			t.Errorf("incorrect number of datacenters of %s: got %d want %d", r.Name, got, 28)
+		}
+	}
+	if got := rs[0].Datacenters[0]; got != "us-east-1a" {
+		t.Errorf("incorrect first datacenter: got %s want %s", got, "us-east-1a")
+	}
+	if got := rs[1].Datacenters[27]; got != "us-west-1-27" {
+		t.Errorf("incorrect last datacenter: got %s want %s", got, "us-west-1-27")
+	}
+	if len(regions[1].Datacenters) != 2 {
+		t.Errorf("built-in datacenters modified: %v", regions[1].Datacenters)
+	}
+}
+
+func TestNewHostWithTagConfig(t *testing.T) {
+	now := time.Now()
+	tags := TagConfig{
+		RegionCount:         20,
+		DatacenterCount:     1,
+		RackCount:           3,
+		ServiceCount:        4,
+		ServiceVersionCount: 1,
+		ExtraTagCount:       2,
+		ExtraTagCardinality: 5,
+		TagValueLength:      8,
+	}.hostTags()
+	for i := 0; i < 1000; i++ {
+		h := NewHost(&HostContext{i, now, 0, 0, tags})
+		if got, want := h.Name, HostName(i, 8); got != want {
+			t.Errorf("incorrect host name: got %s want %s", got, want)
+		}
+		if got := h.Datacenter; got != h.Region+"a" {
+			t.Errorf("incorrect datacenter of region %s: got %s", h.Region, got)
+		}
+		testPaddedValue(t, h.Rack, 3, 8)
+		testPaddedValue(t, h.Service, 4, 8)
+		testPaddedValue(t, h.ServiceVersion, 1, 8)
+		if got := len(h.ExtraTags); got != 2 {
+			t.Fatalf("incorrect number of extra tags: got %d want %d", got, 2)
+		}
+		for _, v := range h.ExtraTags {
+			testPaddedValue(t, v, 5, 8)
+		}
+	}
+}
+
+func testPaddedValue(t *testing.T, s string, limit int64, length int) {
+	if len(s) != length {
+		t.Errorf("value not padded to %d: got %s", length, s)
+	}
+	testStringNumberIsValid(t, limit, s)
+}
@@ -0,0 +1,217 @@
+package events
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+)
+
+const (
+	levelDebug = "debug"
+	levelInfo  = "info"
+	levelWarn  = "warn"
+	levelError = "error"
+
+	// latencySigma is the standard deviation of the log latency of the
+	// requests, whose medians depend on the level of their events.
+	latencySigma = 0.8
+	// maxUserID bounds the IDs of the users in the messages.
+	maxUserID = 100000
+)
+
+var (
+	serviceChoices = []string{
+		"api-gateway",
+		"auth",
+		"billing",
+		"catalog",
+		"checkout",
+		"inventory",
+		"notification",
+		"payment",
+		"search",
+		"shipping",
+	}
+
+	regionChoices = []string{
+		"us-east-1",
+		"us-west-2",
+		"eu-west-1",
+		"ap-southeast-1",
+	}
+
+	// levels are the levels of the events with their probabilities.
+	levels = []struct {
+		name          string
+		probability   float64
+		latencyMedian float64
+		statusCodes   []int64
+	}{
+		{levelDebug, 0.15, 5, []int64{200}},
+		{levelInfo, 0.7, 20, []int64{200, 200, 200, 201, 204, 304}},
+		{levelWarn, 0.1, 300, []int64{400, 404, 409, 429}},
+		{levelError, 0.05, 1000, []int64{500, 502, 503, 504}},
+	}
+
+	methodChoices = []string{"GET", "GET", "GET", "POST", "PUT", "DELETE"}
+	pathChoices   = []string{
+		"/api/v1/users",
+		"/api/v1/orders",
+		"/api/v1/cart",
+		"/api/v1/products",
+		"/login",
+		"/healthz",
+	}
+	dependencyChoices = []string{
+		"postgres:5432",
+		"redis:6379",
+		"kafka:9092",
+		"elasticsearch:9200",
+	}
+	paddingKeys = []string{"request_id", "session", "span", "shard", "attempt"}
+
+	// SearchTerms are phrases of the event messages to search for.
+	SearchTerms = []string{
+		"connection refused",
+		"timeout",
+		"rate limit",
+		"slow request",
+		"nil pointer",
+		"signed in",
+		"failed attempts",
+	}
+
+	// messageTemplates are the messages of the events of each level. They
+	// have no commas nor quotes, so they can be written in CSV formats as
+	// they are.
+	messageTemplates = map[string][]func(e *event) string{
+		levelDebug: {
+			func(e *event) string {
+				return fmt.Sprintf("cache lookup for key user:%d took %.0fus", rand.Intn(maxUserID), e.latency*1000)
+			},
+			func(e *event) string {
+				return fmt.Sprintf("acquired connection to %s in %.2fms", randomChoice(dependencyChoices), e.latency)
+			},
+		},
+		levelInfo: {
+			func(e *event) string {
+				return fmt.Sprintf("%s %s completed with status %d in %.0fms", e.method, e.path, e.statusCode, e.latency)
+			},
+			func(e *event) string {
+				return fmt.Sprintf("user %d signed in from 10.%d.%d.%d", rand.Intn(maxUserID), rand.Intn(256), rand.Intn(256), rand.Intn(256))
+			},
+			func(e *event) string {
+				return fmt.Sprintf("published %d messages to topic %s-events", 1+rand.Intn(100), e.service)
+			},
+		},
+		levelWarn: {
+			func(e *event) string {
+				return fmt.Sprintf("slow request %s %s took %.0fms", e.method, e.path, e.latency)
+			},
+			func(e *event) string {
+				return fmt.Sprintf("retrying call to %s after %d failed attempts", randomChoice(dependencyChoices), 1+rand.Intn(5))
+			},
+			func(e *event) string {
+				return fmt.Sprintf("rate limit exceeded for client %d", rand.Intn(maxUserID))
+			},
+		},
+		levelError: {
+			func(e *event) string {
+				return fmt.Sprintf("%s %s failed with status %d after %.0fms", e.method, e.path, e.statusCode, e.latency)
+			},
+			func(e *event) string {
+				return fmt.Sprintf("connection refused by %s", randomChoice(dependencyChoices))
+			},
+			func(e *event) string {
+				return fmt.Sprintf("timeout waiting for %s after %.0fms", randomChoice(dependencyChoices), e.latency)
+			},
+			func(e *event) string {
+				return fmt.Sprintf("panic recovered in %s handler: nil pointer dereference", e.path)
+			},
+		},
+	}
+)
+
+// event is a log line of a request handled by a service instance.
+type event struct {
+	service    string
+	level      string
+	method     string
+	path       string
+	statusCode int64
+	latency    float64
+}
+
+// newEvent draws a random event of the service.
+func newEvent(service string) *event {
+	e := &event{
+		service: service,
+		method:  randomChoice(methodChoices),
+		path:    randomChoice(pathChoices),
+	}
+
+	r := rand.Float64()
+	for i, l := range levels {
+		if r < l.probability || i == len(levels)-1 {
+			e.level = l.name
+			e.statusCode = l.statusCodes[rand.Intn(len(l.statusCodes))]
+			latency := l.latencyMedian * math.Exp(latencySigma*rand.NormFloat64())
+			e.latency = math.Round(latency*100) / 100
+			break
+		}
+		r -= l.probability
+	}
+	return e
+}
+
+// message returns the text of the event, padded with context to
+// messageSize bytes. Texts longer than messageSize are kept whole.
+func (e *event) message(messageSize int) string {
+	templates := messageTemplates[e.level]
+	msg := templates[rand.Intn(len(templates))](e)
+	if len(msg) >= messageSize {
+		return msg
+	}
+
+	buf := make([]byte, 0, messageSize+32)
+	buf = append(buf, msg...)
+	for len(buf) < messageSize {
+		buf = append(buf, fmt.Sprintf(" %s=%08x", randomChoice(paddingKeys), rand.Uint32())...)
+	}
+	return string(buf[:messageSize])
+}
+
+// instance is a service instance, whose events happen as a Poisson process.
+type instance struct {
+	service string
+	name    string
+	region  string
+
+	meanInterval time.Duration
+	// now is the time of the current event of the instance
+	now time.Time
+}
+
+func newInstance(i int, start time.Time, meanInterval time.Duration) *instance {
+	service := serviceChoices[i%len(serviceChoices)]
+	s := &instance{
+		service:      service,
+		name:         fmt.Sprintf("%s-%d", service, i/len(serviceChoices)),
+		region:       regionChoices[(i/len(serviceChoices))%len(regionChoices)],
+		meanInterval: meanInterval,
+		now:          start,
+	}
+	s.advance()
+	return s
+}
+
+// advance moves the instance to its next event, at least one nanosecond
+// later.
+func (s *instance) advance() {
+	s.now = s.now.Add(time.Duration(math.Max(1, rand.ExpFloat64()*float64(s.meanInterval))))
+}
+
+func randomChoice(choices []string) string {
+	return choices[rand.Intn(len(choices))]
+}
@@ -0,0 +1,69 @@
+package events
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEventMessage(t *testing.T) {
+	rand.Seed(123)
+	found := map[string]bool{}
+	levelCounts := map[string]int{}
+	for i := 0; i < 10000; i++ {
+		e := newEvent("auth")
+		levelCounts[e.level]++
+
+		msg := e.message(0)
+		if strings.ContainsAny(msg, ",\"\\\n") {
+			t.Fatalf("message with characters to escape: %s", msg)
+		}
+		for _, term := range SearchTerms {
+			if strings.Contains(msg, term) {
+				found[term] = true
+			}
+		}
+
+		padded := e.message(200)
+		if len(padded) != 200 {
+			t.Fatalf("incorrect length of padded message: got %d want 200: %s", len(padded), padded)
+		}
+		if strings.ContainsAny(padded, ",\"\\\n") {
+			t.Fatalf("padded message with characters to escape: %s", padded)
+		}
+	}
+
+	for _, term := range SearchTerms {
+		if !found[term] {
+			t.Errorf("search term %q not found in any message", term)
+		}
+	}
+	for _, l := range levels {
+		want := l.probability * 10000
+		if got := float64(levelCounts[l.name]); got < want*0.8 || got > want*1.2 {
+			t.Errorf("incorrect number of %s events: got %.0f want about %.0f", l.name, got, want)
+		}
+	}
+}
+
+func TestInstanceAdvance(t *testing.T) {
+	rand.Seed(123)
+	start := time.Date(2016, time.January, 1, 0, 0, 0, 0, time.UTC)
+	s := newInstance(12, start, time.Second)
+	if s.service != "billing" || s.name != "billing-1" || s.region != "us-west-2" {
+		t.Errorf("incorrect instance: got %s %s %s", s.service, s.name, s.region)
+	}
+
+	events := 0
+	for prev := start; s.now.Before(start.Add(time.Hour)); s.advance() {
+		if !s.now.After(prev) {
+			t.Fatalf("events out of order: %v then %v", prev, s.now)
+		}
+		prev = s.now
+		events++
+	}
+	if events < 3300 || events > 3900 {
+		t.Errorf("incorrect number of events: got %d want about 3600", events)
+	}
+}
@@ -0,0 +1,166 @@
+package events
+
+import (
+	"container/heap"
+	"math/rand"
+	"time"
+
+	"github.com/bodhiye/tsbs/pkg/data"
+	"github.com/bodhiye/tsbs/pkg/data/usecases/common"
+)
+
+var (
+	labelEvents = []byte("events")
+
+	labelService  = []byte("service")
+	labelInstance = []byte("instance")
+	labelRegion   = []byte("region")
+
+	labelLevel      = []byte("level")
+	labelTraceID    = []byte("trace_id")
+	labelMessage    = []byte("message")
+	labelStatusCode = []byte("status_code")
+	labelLatency    = []byte("latency_ms")
+
+	tagKeys    = [][]byte{labelService, labelInstance, labelRegion}
+	fieldKeys  = [][]byte{labelLevel, labelTraceID, labelMessage, labelStatusCode, labelLatency}
+	fieldTypes = []string{"string", "string", "string", "int64", "float64"}
+)
+
+// SimulatorConfig is used to create a Simulator of the log events of a set
+// of service instances. It fulfills the common.SimulatorConfig interface.
+type SimulatorConfig struct {
+	// Start is the beginning time for the Simulator
+	Start time.Time
+	// End is the ending time for the Simulator
+	End time.Time
+	// InstanceCount is the number of service instances to simulate
+	InstanceCount uint64
+	// MessageSize is the length in bytes the messages of the events are
+	// padded to
+	MessageSize int
+}
+
+// NewSimulator produces a Simulator of InstanceCount service instances and
+// the specified points limit. The timestamps of the events are irregular:
+// the interval is only the mean time between two events of an instance.
+func (sc *SimulatorConfig) NewSimulator(interval time.Duration, limit uint64) common.Simulator {
+	instances := make(instanceHeap, sc.InstanceCount)
+	for i := range instances {
+		instances[i] = newInstance(i, sc.Start, interval)
+	}
+	heap.Init(&instances)
+
+	return &Simulator{
+		instances:   instances,
+		end:         sc.End,
+		messageSize: sc.MessageSize,
+		maxPoints:   limit,
+	}
+}
+
+// Simulator merges the events of all the service instances in time order.
+// It fulfills the common.Simulator interface.
+type Simulator struct {
+	instances   instanceHeap
+	end         time.Time
+	messageSize int
+
+	madePoints uint64
+	// maxPoints is the limit of points to make, 0 for no limit
+	maxPoints uint64
+}
+
+// Finished tells whether we have simulated all the necessary points.
+func (s *Simulator) Finished() bool {
+	if s.maxPoints > 0 && s.madePoints >= s.maxPoints {
+		return true
+	}
+	return !s.instances[0].now.Before(s.end)
+}
+
+// Next populates a Point with the next event of all the service instances.
+func (s *Simulator) Next(p *data.Point) bool {
+	inst := s.instances[0]
+	ts := inst.now
+
+	p.SetMeasurementName(labelEvents)
+	p.AppendTag(labelService, inst.service)
+	p.AppendTag(labelInstance, inst.name)
+	p.AppendTag(labelRegion, inst.region)
+	p.SetTimestamp(&ts)
+
+	e := newEvent(inst.service)
+	p.AppendField(labelLevel, e.level)
+	p.AppendField(labelTraceID, TraceID(ts, rand.Intn(TraceCount(len(s.instances)))))
+	p.AppendField(labelMessage, e.message(s.messageSize))
+	p.AppendField(labelStatusCode, e.statusCode)
+	p.AppendField(labelLatency, e.latency)
+
+	inst.advance()
+	heap.Fix(&s.instances, 0)
+	s.madePoints++
+	return true
+}
+
+// Fields returns the field keys of the events.
+func (s *Simulator) Fields() map[string][]string {
+	return map[string][]string{
+		string(labelEvents): bytesToStrings(fieldKeys),
+	}
+}
+
+// TagKeys returns the tag keys of the service instances.
+func (s *Simulator) TagKeys() []string {
+	return bytesToStrings(tagKeys)
+}
+
+// TagTypes returns the type of each tag of the service instances.
+func (s *Simulator) TagTypes() []string {
+	types := make([]string, len(tagKeys))
+	for i := range types {
+		types[i] = "string"
+	}
+	return types
+}
+
+// Headers returns the tags and fields of the generated data, with the
+// types of the fields since most of them are strings.
+func (s *Simulator) Headers() *common.GeneratedDataHeaders {
+	return &common.GeneratedDataHeaders{
+		TagTypes:  s.TagTypes(),
+		TagKeys:   s.TagKeys(),
+		FieldKeys: s.Fields(),
+		FieldTypes: map[string][]string{
+			string(labelEvents): fieldTypes,
+		},
+	}
+}
+
+func bytesToStrings(b [][]byte) []string {
+	s := make([]string, len(b))
+	for i := range b {
+		s[i] = string(b[i])
+	}
+	return s
+}
+
+// instanceHeap orders the service instances by the time of their next
+// event. It implements heap.Interface.
+type instanceHeap []*instance
+
+func (h instanceHeap) Len() int           { return len(h) }
+func (h instanceHeap) Less(i, j int) bool { return h[i].now.Before(h[j].now) }
+func (h instanceHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *instanceHeap) Push(x interface{}) {
+	*h = append(*h, x.(*instance))
+}
+
+func (h *instanceHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+	return x
+}
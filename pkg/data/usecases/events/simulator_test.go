@@ -0,0 +1,94 @@
+package events
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/bodhiye/tsbs/pkg/data"
+)
+
+func TestSimulatorConfigNewSimulator(t *testing.T) {
+	start := time.Date(2016, time.January, 1, 0, 0, 0, 0, time.UTC)
+	sc := &SimulatorConfig{
+		Start:         start,
+		End:           start.Add(10 * time.Minute),
+		InstanceCount: 20,
+		MessageSize:   100,
+	}
+	sim := sc.NewSimulator(time.Second, 0)
+
+	wantTags := []string{"service", "instance", "region"}
+	if got := sim.TagKeys(); !reflect.DeepEqual(got, wantTags) {
+		t.Errorf("incorrect tag keys: got %v want %v", got, wantTags)
+	}
+	wantFields := map[string][]string{
+		"events": {"level", "trace_id", "message", "status_code", "latency_ms"},
+	}
+	if got := sim.Fields(); !reflect.DeepEqual(got, wantFields) {
+		t.Errorf("incorrect fields: got %v want %v", got, wantFields)
+	}
+	wantTypes := map[string][]string{
+		"events": {"string", "string", "string", "int64", "float64"},
+	}
+	if got := sim.Headers().FieldTypes; !reflect.DeepEqual(got, wantTypes) {
+		t.Errorf("incorrect field types: got %v want %v", got, wantTypes)
+	}
+
+	var prev time.Time
+	instances := map[string]bool{}
+	traces := map[string]int{}
+	points := 0
+	for !sim.Finished() {
+		p := data.NewPoint()
+		if !sim.Next(p) {
+			t.Fatalf("point not to be written")
+		}
+		ts := *p.Timestamp()
+		if ts.Before(prev) {
+			t.Fatalf("points out of order: %v then %v", prev, ts)
+		}
+		if !ts.Before(sc.End) {
+			t.Fatalf("point after the end: %v", ts)
+		}
+		prev = ts
+
+		if got := len(p.GetFieldValue(labelMessage).(string)); got < sc.MessageSize {
+			t.Fatalf("message shorter than the message size: got %d", got)
+		}
+		instances[p.GetTagValue(labelInstance).(string)] = true
+		traces[p.GetFieldValue(labelTraceID).(string)]++
+		points++
+	}
+
+	if got := len(instances); got != int(sc.InstanceCount) {
+		t.Errorf("incorrect number of instances: got %d want %d", got, sc.InstanceCount)
+	}
+	// 20 instances with an event per second over 10 minutes
+	if points < 11000 || points > 13000 {
+		t.Errorf("incorrect number of points: got %d want about 12000", points)
+	}
+	// 20 traces per minute over 10 minutes
+	if got := len(traces); got != 200 {
+		t.Errorf("incorrect number of traces: got %d want 200", got)
+	}
+}
+
+func TestSimulatorLimit(t *testing.T) {
+	start := time.Date(2016, time.January, 1, 0, 0, 0, 0, time.UTC)
+	sc := &SimulatorConfig{
+		Start:         start,
+		End:           start.Add(time.Hour),
+		InstanceCount: 3,
+	}
+	sim := sc.NewSimulator(time.Second, 100)
+
+	count := 0
+	for !sim.Finished() {
+		sim.Next(data.NewPoint())
+		count++
+	}
+	if count != 100 {
+		t.Errorf("incorrect number of points: got %d want 100", count)
+	}
+}
@@ -0,0 +1,42 @@
+package events
+
+import (
+	"fmt"
+	"time"
+)
+
+// TracePeriod is the time span of the traces: all the events of a trace
+// happen within the same period, aligned on the Unix epoch.
+const TracePeriod = time.Minute
+
+// TraceCount returns the number of traces per TracePeriod of instanceCount
+// service instances: one per instance, so that a trace spans about as many
+// events as an instance logs in a TracePeriod.
+func TraceCount(instanceCount int) int {
+	if instanceCount < 1 {
+		return 1
+	}
+	return instanceCount
+}
+
+// TraceID returns the ID of the n-th trace of the TracePeriod of t as 32
+// hexadecimal digits, like the W3C trace context ones. The IDs are
+// deterministic so that queries can look up traces that exist in the
+// generated data.
+func TraceID(t time.Time, n int) string {
+	period := t.Unix() / int64(TracePeriod/time.Second)
+	if t.Unix()%int64(TracePeriod/time.Second) < 0 {
+		period--
+	}
+	hi := splitMix64(splitMix64(uint64(period)) + uint64(n))
+	lo := splitMix64(hi)
+	return fmt.Sprintf("%016x%016x", hi, lo)
+}
+
+// splitMix64 scrambles x into a well distributed 64-bit value.
+func splitMix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
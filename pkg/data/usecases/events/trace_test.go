@@ -0,0 +1,49 @@
+package events
+
+import (
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestTraceID(t *testing.T) {
+	start := time.Date(2016, time.January, 1, 0, 0, 0, 0, time.UTC)
+	id := TraceID(start, 3)
+	if !regexp.MustCompile("^[0-9a-f]{32}$").MatchString(id) {
+		t.Errorf("trace ID not 32 hexadecimal digits: %s", id)
+	}
+	if got := TraceID(start.Add(TracePeriod-time.Nanosecond), 3); got != id {
+		t.Errorf("trace ID changed within its period: got %s want %s", got, id)
+	}
+	if got := TraceID(start.Add(TracePeriod), 3); got == id {
+		t.Errorf("trace ID did not change with the period: %s", got)
+	}
+	if got := TraceID(start, 4); got == id {
+		t.Errorf("trace ID did not change with the trace: %s", got)
+	}
+
+	// Periods before the epoch are aligned too.
+	before := time.Unix(-1, 0)
+	if TraceID(before, 0) != TraceID(time.Unix(-int64(TracePeriod/time.Second), 0), 0) {
+		t.Errorf("trace ID changed within a period before the epoch")
+	}
+	if TraceID(before, 0) == TraceID(time.Unix(0, 0), 0) {
+		t.Errorf("trace ID did not change at the epoch")
+	}
+}
+
+func TestTraceCount(t *testing.T) {
+	cases := []struct {
+		instances int
+		want      int
+	}{
+		{0, 1},
+		{1, 1},
+		{100, 100},
+	}
+	for _, c := range cases {
+		if got := TraceCount(c.instances); got != c.want {
+			t.Errorf("incorrect trace count for %d instances: got %d want %d", c.instances, got, c.want)
+		}
+	}
+}
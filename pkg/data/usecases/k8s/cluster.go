@@ -0,0 +1,111 @@
+package k8s
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+const (
+	// podsPerDeployment is the number of replicas of every deployment.
+	podsPerDeployment = 3
+	// podsPerNode is the number of pods a node runs on average.
+	podsPerNode = 30
+
+	deploymentFmt = "%s-%d"
+	nodeFmt       = "node-%d"
+	appImageFmt   = "registry.local/%s:v1.%d"
+
+	// podNameChars are the characters of the generated parts of pod names.
+	podNameChars = "bcdfghjklmnpqrstvwxz2456789"
+)
+
+var (
+	// NamespaceChoices contains all the namespace values for the k8s use
+	// case, in the order deployments are spread over them.
+	NamespaceChoices = []string{
+		"default",
+		"kube-system",
+		"monitoring",
+		"ingress",
+		"payments",
+		"checkout",
+		"search",
+		"auth",
+	}
+
+	appChoices = []string{
+		"api",
+		"web",
+		"worker",
+		"cache",
+		"scheduler",
+		"gateway",
+	}
+
+	// sidecars are added in turn to the app container of the deployments.
+	sidecars = []container{
+		{name: "istio-proxy", image: "istio/proxyv2:1.18.2"},
+		{name: "log-shipper", image: "fluent/fluent-bit:2.1.8"},
+	}
+)
+
+// container is a container of the pods of a deployment.
+type container struct {
+	name  string
+	image string
+}
+
+// deployment is a set of identical pods of a namespace.
+type deployment struct {
+	namespace  string
+	name       string
+	replicaSet string
+	containers []container
+}
+
+// newDeployment returns the i-th deployment of the cluster.
+func newDeployment(i int) *deployment {
+	app := appChoices[(i/len(NamespaceChoices))%len(appChoices)]
+	d := &deployment{
+		namespace:  NamespaceChoices[i%len(NamespaceChoices)],
+		name:       fmt.Sprintf(deploymentFmt, app, i),
+		replicaSet: randomPodNamePart(10),
+		containers: []container{{name: app, image: fmt.Sprintf(appImageFmt, app, i%5)}},
+	}
+	d.containers = append(d.containers, sidecars[:i%(len(sidecars)+1)]...)
+	return d
+}
+
+// deploymentCount returns the number of deployments for podCount pods.
+func deploymentCount(podCount uint64) int {
+	return int((podCount + podsPerDeployment - 1) / podsPerDeployment)
+}
+
+// nodeCount returns the number of nodes for podCount pods.
+func nodeCount(podCount uint64) int {
+	n := int(podCount / podsPerNode)
+	if n < 1 {
+		return 1
+	}
+	return n
+}
+
+// Namespaces returns the namespaces that have pods in a cluster of
+// podCount pods.
+func Namespaces(podCount uint64) []string {
+	n := deploymentCount(podCount)
+	if n > len(NamespaceChoices) {
+		n = len(NamespaceChoices)
+	}
+	return NamespaceChoices[:n]
+}
+
+// randomPodNamePart returns a random part of a pod name, like the hash of
+// the replica set or the suffix of the pod.
+func randomPodNamePart(n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = podNameChars[rand.Intn(len(podNameChars))]
+	}
+	return string(b)
+}
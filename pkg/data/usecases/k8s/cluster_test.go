@@ -0,0 +1,51 @@
+package k8s
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNamespaces(t *testing.T) {
+	cases := []struct {
+		podCount uint64
+		want     []string
+	}{
+		{podCount: 1, want: NamespaceChoices[:1]},
+		{podCount: 3, want: NamespaceChoices[:1]},
+		{podCount: 4, want: NamespaceChoices[:2]},
+		{podCount: 1000, want: NamespaceChoices},
+	}
+	for _, c := range cases {
+		if got := Namespaces(c.podCount); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("incorrect namespaces for %d pods: got %v want %v", c.podCount, got, c.want)
+		}
+	}
+}
+
+func TestNewDeployment(t *testing.T) {
+	for i := 0; i < 3*len(NamespaceChoices); i++ {
+		d := newDeployment(i)
+		if got, want := d.namespace, NamespaceChoices[i%len(NamespaceChoices)]; got != want {
+			t.Errorf("incorrect namespace of deployment %d: got %s want %s", i, got, want)
+		}
+		if got, want := len(d.containers), 1+i%(len(sidecars)+1); got != want {
+			t.Errorf("incorrect number of containers of deployment %d: got %d want %d", i, got, want)
+		}
+		if app := d.containers[0].name; !strings.HasPrefix(d.name, app+"-") {
+			t.Errorf("deployment %s not named after its app container %s", d.name, app)
+		}
+		if got := len(d.replicaSet); got != 10 {
+			t.Errorf("incorrect replica set hash length: got %d want 10", got)
+		}
+	}
+}
+
+func TestNodeCount(t *testing.T) {
+	cases := map[uint64]int{1: 1, podsPerNode: 1, 10 * podsPerNode: 10}
+	for pods, want := range cases {
+		if got := nodeCount(pods); got != want {
+			t.Errorf("incorrect node count for %d pods: got %d want %d", pods, got, want)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package k8s
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/bodhiye/tsbs/pkg/data/usecases/common"
+)
+
+const podNameFmt = "%s-%s-%s"
+
+var (
+	labelNamespace  = []byte("namespace")
+	labelDeployment = []byte("deployment")
+	labelPod        = []byte("pod")
+	labelContainer  = []byte("container")
+	labelNode       = []byte("node")
+	labelImage      = []byte("image")
+)
+
+// pod is a pod of a deployment running on a node. When it churns, it is
+// replaced by a new pod of the same deployment, with a new name and maybe
+// on another node, like a rolling update or an eviction would.
+type pod struct {
+	deployment *deployment
+	nodeCount  int
+	// churnProbability is the probability to be replaced on every tick
+	churnProbability float64
+
+	name string
+	node string
+	// generation counts the replacements of the pod, so that its containers
+	// notice they are new
+	generation int
+	// lastTick is the time of the last tick of the pod
+	lastTick time.Time
+}
+
+func newPod(d *deployment, nodeCount int, churnProbability float64) *pod {
+	p := &pod{
+		deployment:       d,
+		nodeCount:        nodeCount,
+		churnProbability: churnProbability,
+	}
+	p.schedule()
+	return p
+}
+
+// schedule names the pod and puts it on a random node.
+func (p *pod) schedule() {
+	p.name = fmt.Sprintf(podNameFmt, p.deployment.name, p.deployment.replicaSet, randomPodNamePart(5))
+	p.node = fmt.Sprintf(nodeFmt, rand.Intn(p.nodeCount))
+}
+
+// tick replaces the pod with churnProbability, once for all its containers
+// ticking to the same time.
+func (p *pod) tick(now time.Time) {
+	if !now.After(p.lastTick) {
+		return
+	}
+	p.lastTick = now
+	if p.churnProbability > 0 && rand.Float64() < p.churnProbability {
+		p.schedule()
+		p.generation++
+	}
+}
+
+// Container is a container of a pod, and the generator of the k8s use case:
+// each of its measurements is a series labeled with the namespace,
+// deployment, pod, container, node and image.
+type Container struct {
+	pod       *pod
+	container container
+	// restartProbability is the probability to restart on every tick
+	restartProbability float64
+
+	simulatedMeasurements []common.SimulatedMeasurement
+	tags                  []common.Tag
+
+	generation int
+	restarts   int64
+	now        time.Time
+	startedAt  time.Time
+}
+
+func newContainer(p *pod, c container, restartProbability float64, start time.Time) *Container {
+	ct := &Container{
+		pod:                p,
+		container:          c,
+		restartProbability: restartProbability,
+		generation:         p.generation,
+	}
+	ct.start(start)
+	return ct
+}
+
+// start (re)starts the container at the given time, with fresh counters.
+func (c *Container) start(now time.Time) {
+	c.now = now
+	c.startedAt = now
+	c.simulatedMeasurements = []common.SimulatedMeasurement{
+		NewCPUMeasurement(now),
+		NewMemoryMeasurement(now),
+		NewNetworkMeasurement(now),
+		NewStatusMeasurement(now, c),
+	}
+	c.tags = []common.Tag{
+		{Key: labelNamespace, Value: c.pod.deployment.namespace},
+		{Key: labelDeployment, Value: c.pod.deployment.name},
+		{Key: labelPod, Value: c.pod.name},
+		{Key: labelContainer, Value: c.container.name},
+		{Key: labelNode, Value: c.pod.node},
+		{Key: labelImage, Value: c.container.image},
+	}
+}
+
+// TickAll advances all Distributions of a Container. A container of a
+// replaced pod starts over in the new pod, and a container restarts in its
+// pod with restartProbability, resetting its counters.
+func (c *Container) TickAll(d time.Duration) {
+	c.now = c.now.Add(d)
+	c.pod.tick(c.now)
+	if c.generation != c.pod.generation {
+		c.generation = c.pod.generation
+		c.restarts = 0
+		c.start(c.now)
+		return
+	}
+	if c.restartProbability > 0 && rand.Float64() < c.restartProbability {
+		c.restarts++
+		c.start(c.now)
+		return
+	}
+	for i := range c.simulatedMeasurements {
+		c.simulatedMeasurements[i].Tick(d)
+	}
+}
+
+// Measurements returns the container measurements.
+func (c *Container) Measurements() []common.SimulatedMeasurement {
+	return c.simulatedMeasurements
+}
+
+// Tags returns the container tags.
+func (c *Container) Tags() []common.Tag {
+	return c.tags
+}
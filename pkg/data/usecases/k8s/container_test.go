@@ -0,0 +1,111 @@
+package k8s
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bodhiye/tsbs/pkg/data"
+)
+
+func tagValue(c *Container, key string) interface{} {
+	for _, tag := range c.Tags() {
+		if string(tag.Key) == key {
+			return tag.Value
+		}
+	}
+	return nil
+}
+
+func statusPoint(c *Container) *data.Point {
+	p := data.NewPoint()
+	c.Measurements()[3].ToPoint(p)
+	return p
+}
+
+func TestContainerTags(t *testing.T) {
+	now := time.Now()
+	p := newPod(newDeployment(1), 1, 0)
+	c := newContainer(p, p.deployment.containers[1], 0, now)
+
+	want := map[string]string{
+		"namespace":  "kube-system",
+		"deployment": "api-1",
+		"pod":        p.name,
+		"container":  "istio-proxy",
+		"node":       "node-0",
+		"image":      "istio/proxyv2:1.18.2",
+	}
+	if got := len(c.Tags()); got != len(want) {
+		t.Fatalf("incorrect number of tags: got %d want %d", got, len(want))
+	}
+	for k, v := range want {
+		if got := tagValue(c, k); got != v {
+			t.Errorf("incorrect %s tag: got %v want %s", k, got, v)
+		}
+	}
+	if got := len(c.Measurements()); got != 4 {
+		t.Errorf("incorrect number of measurements: got %d want 4", got)
+	}
+}
+
+func TestContainerTickAll(t *testing.T) {
+	now := time.Now()
+	p := newPod(newDeployment(0), 1, 0)
+	c := newContainer(p, p.deployment.containers[0], 0, now)
+	podName := p.name
+
+	c.TickAll(time.Minute)
+	c.TickAll(time.Minute)
+	status := statusPoint(c)
+	if got := status.GetFieldValue(labelUptimeSeconds); got != int64(120) {
+		t.Errorf("incorrect uptime: got %v want 120", got)
+	}
+	if got := tagValue(c, "pod"); got != podName {
+		t.Errorf("pod changed without churn: got %v want %s", got, podName)
+	}
+
+	// restart
+	c.restartProbability = 1
+	c.TickAll(time.Minute)
+	status = statusPoint(c)
+	if got := status.GetFieldValue(labelRestartsTotal); got != int64(1) {
+		t.Errorf("incorrect restarts: got %v want 1", got)
+	}
+	if got := status.GetFieldValue(labelUptimeSeconds); got != int64(0) {
+		t.Errorf("incorrect uptime after restart: got %v want 0", got)
+	}
+	if got := tagValue(c, "pod"); got != podName {
+		t.Errorf("pod changed on restart: got %v want %s", got, podName)
+	}
+
+	// churn
+	c.restartProbability = 0
+	p.churnProbability = 1
+	c.TickAll(time.Minute)
+	status = statusPoint(c)
+	if got := tagValue(c, "pod"); got == podName {
+		t.Errorf("pod did not change on churn: got %v", got)
+	}
+	if got := status.GetFieldValue(labelRestartsTotal); got != int64(0) {
+		t.Errorf("incorrect restarts in new pod: got %v want 0", got)
+	}
+	if got := status.Timestamp(); !got.Equal(now.Add(4 * time.Minute)) {
+		t.Errorf("incorrect timestamp in new pod: got %v want %v", got, now.Add(4*time.Minute))
+	}
+}
+
+func TestPodTickOncePerTime(t *testing.T) {
+	now := time.Now()
+	p := newPod(newDeployment(2), 1, 1)
+	a := newContainer(p, p.deployment.containers[0], 0, now)
+	b := newContainer(p, p.deployment.containers[1], 0, now)
+
+	a.TickAll(time.Minute)
+	b.TickAll(time.Minute)
+	if got := p.generation; got != 1 {
+		t.Errorf("pod replaced more than once in a tick: got %d generations want 1", got)
+	}
+	if tagValue(a, "pod") != tagValue(b, "pod") || tagValue(a, "node") != tagValue(b, "node") {
+		t.Errorf("containers of the same pod have different pods: %v %v", a.Tags(), b.Tags())
+	}
+}
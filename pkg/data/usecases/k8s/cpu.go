@@ -0,0 +1,60 @@
+package k8s
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/bodhiye/tsbs/pkg/data"
+	"github.com/bodhiye/tsbs/pkg/data/usecases/common"
+)
+
+const maxCPUMillicores = 2000.0
+
+var (
+	labelCPU  = []byte("container_cpu") // heap optimization
+	cpuFields = []common.LabeledDistributionMaker{
+		{
+			Label: []byte("usage_millicores"),
+			DistributionMaker: func() common.Distribution {
+				return common.CWD(cpuUsageND, 0.0, maxCPUMillicores, rand.Float64()*maxCPUMillicores/4)
+			},
+		},
+		{
+			Label: []byte("usage_seconds_total"),
+			DistributionMaker: func() common.Distribution {
+				return common.MWD(cpuSecondsND, 0.0)
+			},
+		},
+		{
+			Label: []byte("throttled_seconds_total"),
+			DistributionMaker: func() common.Distribution {
+				return common.MWD(cpuThrottledND, 0.0)
+			},
+		},
+	}
+)
+
+// Reuse NormalDistributions as arguments to other distributions. This is
+// safe to do because the higher-level distribution advances the ND and
+// immediately uses its value and saves the state
+var (
+	cpuUsageND     = common.ND(0.0, 20.0)
+	cpuSecondsND   = common.ND(0.0, 2.0)
+	cpuThrottledND = common.ND(0.0, 0.05)
+)
+
+// CPUMeasurement is the cpu usage of a container.
+type CPUMeasurement struct {
+	*common.SubsystemMeasurement
+}
+
+// NewCPUMeasurement creates a new CPUMeasurement with start time.
+func NewCPUMeasurement(start time.Time) *CPUMeasurement {
+	sub := common.NewSubsystemMeasurementWithDistributionMakers(start, cpuFields)
+	return &CPUMeasurement{sub}
+}
+
+// ToPoint serializes CPUMeasurement to serialize.Point.
+func (m *CPUMeasurement) ToPoint(p *data.Point) {
+	m.SubsystemMeasurement.ToPoint(p, labelCPU, cpuFields)
+}
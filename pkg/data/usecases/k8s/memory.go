@@ -0,0 +1,60 @@
+package k8s
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/bodhiye/tsbs/pkg/data"
+	"github.com/bodhiye/tsbs/pkg/data/usecases/common"
+)
+
+const (
+	minMemoryBytes = 16 << 20
+	maxMemoryBytes = 2 << 30
+	maxCacheBytes  = 512 << 20
+)
+
+var (
+	labelMemory  = []byte("container_memory") // heap optimization
+	memoryFields = []common.LabeledDistributionMaker{
+		{
+			Label: []byte("working_set_bytes"),
+			DistributionMaker: func() common.Distribution {
+				return common.CWD(memoryND, minMemoryBytes, maxMemoryBytes, minMemoryBytes+rand.Float64()*maxMemoryBytes/8)
+			},
+		},
+		{
+			Label: []byte("rss_bytes"),
+			DistributionMaker: func() common.Distribution {
+				return common.CWD(memoryND, minMemoryBytes/2, maxMemoryBytes, minMemoryBytes/2+rand.Float64()*maxMemoryBytes/8)
+			},
+		},
+		{
+			Label: []byte("cache_bytes"),
+			DistributionMaker: func() common.Distribution {
+				return common.CWD(memoryND, 0, maxCacheBytes, rand.Float64()*maxCacheBytes/8)
+			},
+		},
+	}
+)
+
+// Reuse NormalDistributions as arguments to other distributions. This is
+// safe to do because the higher-level distribution advances the ND and
+// immediately uses its value and saves the state
+var memoryND = common.ND(0.0, 2<<20)
+
+// MemoryMeasurement is the memory usage of a container.
+type MemoryMeasurement struct {
+	*common.SubsystemMeasurement
+}
+
+// NewMemoryMeasurement creates a new MemoryMeasurement with start time.
+func NewMemoryMeasurement(start time.Time) *MemoryMeasurement {
+	sub := common.NewSubsystemMeasurementWithDistributionMakers(start, memoryFields)
+	return &MemoryMeasurement{sub}
+}
+
+// ToPoint serializes MemoryMeasurement to serialize.Point.
+func (m *MemoryMeasurement) ToPoint(p *data.Point) {
+	m.ToPointAllInt64(p, labelMemory, memoryFields)
+}
@@ -0,0 +1,43 @@
+package k8s
+
+import (
+	"time"
+
+	"github.com/bodhiye/tsbs/pkg/data"
+	"github.com/bodhiye/tsbs/pkg/data/usecases/common"
+)
+
+var (
+	labelNetwork  = []byte("container_network") // heap optimization
+	networkFields = []common.LabeledDistributionMaker{
+		{Label: []byte("rx_bytes_total"), DistributionMaker: func() common.Distribution { return common.MWD(networkBytesND, 0) }},
+		{Label: []byte("tx_bytes_total"), DistributionMaker: func() common.Distribution { return common.MWD(networkBytesND, 0) }},
+		{Label: []byte("rx_errors_total"), DistributionMaker: func() common.Distribution { return common.MWD(networkErrorsND, 0) }},
+		{Label: []byte("tx_errors_total"), DistributionMaker: func() common.Distribution { return common.MWD(networkErrorsND, 0) }},
+	}
+)
+
+// Reuse NormalDistributions as arguments to other distributions. This is
+// safe to do because the higher-level distribution advances the ND and
+// immediately uses its value and saves the state
+var (
+	networkBytesND  = common.ND(0.0, 64<<10)
+	networkErrorsND = common.ND(0.0, 0.5)
+)
+
+// NetworkMeasurement is the network traffic of a container, as counters
+// since the container started.
+type NetworkMeasurement struct {
+	*common.SubsystemMeasurement
+}
+
+// NewNetworkMeasurement creates a new NetworkMeasurement with start time.
+func NewNetworkMeasurement(start time.Time) *NetworkMeasurement {
+	sub := common.NewSubsystemMeasurementWithDistributionMakers(start, networkFields)
+	return &NetworkMeasurement{sub}
+}
+
+// ToPoint serializes NetworkMeasurement to serialize.Point.
+func (m *NetworkMeasurement) ToPoint(p *data.Point) {
+	m.ToPointAllInt64(p, labelNetwork, networkFields)
+}
@@ -0,0 +1,68 @@
+package k8s
+
+import (
+	"math"
+	"time"
+
+	"github.com/bodhiye/tsbs/pkg/data/usecases/common"
+)
+
+// SimulatorConfig is used to create a Simulator of the containers of a
+// Kubernetes cluster. It fulfills the common.SimulatorConfig interface.
+type SimulatorConfig struct {
+	// Start is the beginning time for the Simulator
+	Start time.Time
+	// End is the ending time for the Simulator
+	End time.Time
+	// InitPodCount is the number of pods to start with in the first reporting period
+	InitPodCount uint64
+	// PodCount is the total number of pods to have in the last reporting period
+	PodCount uint64
+	// ChurnRate is how many times per hour a pod is replaced by a new one
+	ChurnRate float64
+	// RestartRate is how many times per hour a container restarts
+	RestartRate float64
+}
+
+// NewSimulator produces a Simulator of the containers of PodCount pods over
+// the specified interval and points limit. Every container reports its
+// cpu, memory, network and status measurements.
+func (sc *SimulatorConfig) NewSimulator(interval time.Duration, limit uint64) common.Simulator {
+	churnProbability := tickProbability(sc.ChurnRate, interval)
+	restartProbability := tickProbability(sc.RestartRate, interval)
+	nodes := nodeCount(sc.PodCount)
+
+	deployments := make([]*deployment, deploymentCount(sc.PodCount))
+	for i := range deployments {
+		deployments[i] = newDeployment(i)
+	}
+
+	var containers []*Container
+	initContainers := uint64(0)
+	for i := uint64(0); i < sc.PodCount; i++ {
+		p := newPod(deployments[i/podsPerDeployment], nodes, churnProbability)
+		for _, c := range p.deployment.containers {
+			containers = append(containers, newContainer(p, c, restartProbability, sc.Start))
+		}
+		if i < sc.InitPodCount {
+			initContainers = uint64(len(containers))
+		}
+	}
+
+	base := &common.BaseSimulatorConfig{
+		Start:              sc.Start,
+		End:                sc.End,
+		InitGeneratorScale: initContainers,
+		GeneratorScale:     uint64(len(containers)),
+		GeneratorConstructor: func(i int, _ time.Time) common.Generator {
+			return containers[i]
+		},
+	}
+	return base.NewSimulator(interval, limit)
+}
+
+// tickProbability returns the probability of an event happening rate times
+// per hour to happen within a tick of interval.
+func tickProbability(rate float64, interval time.Duration) float64 {
+	return 1 - math.Exp(-rate*interval.Hours())
+}
@@ -0,0 +1,89 @@
+package k8s
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/bodhiye/tsbs/pkg/data"
+)
+
+func TestSimulatorConfigNewSimulator(t *testing.T) {
+	start := time.Date(2016, time.January, 1, 0, 0, 0, 0, time.UTC)
+	sc := &SimulatorConfig{
+		Start:        start,
+		End:          start.Add(time.Hour),
+		InitPodCount: 4,
+		PodCount:     10,
+		ChurnRate:    6,
+		RestartRate:  0,
+	}
+	sim := sc.NewSimulator(10*time.Second, 0)
+
+	wantTags := []string{"namespace", "deployment", "pod", "container", "node", "image"}
+	if got := sim.TagKeys(); !reflect.DeepEqual(got, wantTags) {
+		t.Errorf("incorrect tag keys: got %v want %v", got, wantTags)
+	}
+	wantFields := map[string][]string{
+		"container_cpu":     {"usage_millicores", "usage_seconds_total", "throttled_seconds_total"},
+		"container_memory":  {"working_set_bytes", "rss_bytes", "cache_bytes"},
+		"container_network": {"rx_bytes_total", "tx_bytes_total", "rx_errors_total", "tx_errors_total"},
+		"container_status":  {"restarts_total", "uptime_seconds"},
+	}
+	if got := sim.Fields(); !reflect.DeepEqual(got, wantFields) {
+		t.Errorf("incorrect fields: got %v want %v", got, wantFields)
+	}
+
+	pods := map[string]bool{}
+	series := map[string]bool{}
+	for !sim.Finished() {
+		p := data.NewPoint()
+		if !sim.Next(p) {
+			continue
+		}
+		pods[p.GetTagValue(labelPod).(string)] = true
+		series[p.GetTagValue(labelPod).(string)+"/"+p.GetTagValue(labelContainer).(string)] = true
+	}
+	// 10 pods replaced ~6 times in the hour
+	if got := len(pods); got < 40 {
+		t.Errorf("not enough pod churn: got %d pods want at least 40", got)
+	}
+	if got := len(series); got <= len(pods) {
+		t.Errorf("no sidecar containers: got %d series for %d pods", got, len(pods))
+	}
+}
+
+func TestSimulatorConfigNoChurn(t *testing.T) {
+	start := time.Date(2016, time.January, 1, 0, 0, 0, 0, time.UTC)
+	sc := &SimulatorConfig{
+		Start:        start,
+		End:          start.Add(time.Hour),
+		InitPodCount: 6,
+		PodCount:     6,
+	}
+	sim := sc.NewSimulator(10*time.Second, 0)
+	pods := map[string]bool{}
+	for !sim.Finished() {
+		p := data.NewPoint()
+		if sim.Next(p) {
+			pods[p.GetTagValue(labelPod).(string)] = true
+		}
+	}
+	if got := len(pods); got != 6 {
+		t.Errorf("incorrect number of pods without churn: got %d want 6", got)
+	}
+}
+
+func TestTickProbability(t *testing.T) {
+	if got := tickProbability(0, time.Minute); got != 0 {
+		t.Errorf("incorrect probability for rate 0: got %v want 0", got)
+	}
+	// once per hour on average, ticking every hour
+	if got, want := tickProbability(1, time.Hour), 1-math.Exp(-1); got != want {
+		t.Errorf("incorrect probability: got %v want %v", got, want)
+	}
+	if got := tickProbability(1e6, time.Hour); got != 1 {
+		t.Errorf("incorrect probability for huge rate: got %v want 1", got)
+	}
+}
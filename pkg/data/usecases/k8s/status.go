@@ -0,0 +1,43 @@
+package k8s
+
+import (
+	"time"
+
+	"github.com/bodhiye/tsbs/pkg/data"
+)
+
+var (
+	labelStatus        = []byte("container_status") // heap optimization
+	labelRestartsTotal = []byte("restarts_total")
+	labelUptimeSeconds = []byte("uptime_seconds")
+)
+
+// StatusMeasurement is the status of a container: how many times it
+// restarted in its pod and for how long it has been running.
+type StatusMeasurement struct {
+	Timestamp time.Time
+	container *Container
+}
+
+// NewStatusMeasurement creates a new StatusMeasurement of a container with
+// start time.
+func NewStatusMeasurement(start time.Time, c *Container) *StatusMeasurement {
+	return &StatusMeasurement{
+		Timestamp: start,
+		container: c,
+	}
+}
+
+// Tick advances the time of the StatusMeasurement.
+func (m *StatusMeasurement) Tick(d time.Duration) {
+	m.Timestamp = m.Timestamp.Add(d)
+}
+
+// ToPoint serializes StatusMeasurement to serialize.Point.
+func (m *StatusMeasurement) ToPoint(p *data.Point) {
+	p.SetMeasurementName(labelStatus)
+	p.SetTimestamp(&m.Timestamp)
+
+	p.AppendField(labelRestartsTotal, m.container.restarts)
+	p.AppendField(labelUptimeSeconds, int64(m.Timestamp.Sub(m.container.startedAt).Seconds()))
+}
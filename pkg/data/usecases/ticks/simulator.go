@@ -0,0 +1,177 @@
+package ticks
+
+import (
+	"container/heap"
+	"math/rand"
+	"time"
+
+	"github.com/bodhiye/tsbs/pkg/data"
+	"github.com/bodhiye/tsbs/pkg/data/usecases/common"
+)
+
+var (
+	labelTrades = []byte("trades")
+	labelQuotes = []byte("quotes")
+
+	labelSymbol   = []byte("symbol")
+	labelExchange = []byte("exchange")
+	labelSector   = []byte("sector")
+
+	labelPrice    = []byte("price")
+	labelSize     = []byte("size")
+	labelBidPrice = []byte("bid_price")
+	labelBidSize  = []byte("bid_size")
+	labelAskPrice = []byte("ask_price")
+	labelAskSize  = []byte("ask_size")
+
+	tagKeys   = [][]byte{labelSymbol, labelExchange, labelSector}
+	tradeKeys = [][]byte{labelPrice, labelSize}
+	quoteKeys = [][]byte{labelBidPrice, labelBidSize, labelAskPrice, labelAskSize}
+)
+
+// SimulatorConfig is used to create a Simulator of the trades and quotes of
+// a set of symbols. It fulfills the common.SimulatorConfig interface.
+type SimulatorConfig struct {
+	// Start is the beginning time for the Simulator
+	Start time.Time
+	// End is the ending time for the Simulator
+	End time.Time
+	// SymbolCount is the number of symbols to simulate
+	SymbolCount uint64
+	// Rate is the mean number of trades and quotes per second of a symbol
+	// outside of bursts
+	Rate float64
+	// BurstFactor is how many times more trades and quotes a symbol has
+	// during a burst of activity
+	BurstFactor float64
+}
+
+// NewSimulator produces a Simulator of SymbolCount symbols and the
+// specified points limit. The timestamps of the points are irregular: the
+// interval is only the length of the sessions during which a symbol is in
+// a burst of activity or not.
+func (sc *SimulatorConfig) NewSimulator(interval time.Duration, limit uint64) common.Simulator {
+	symbols := make(symbolHeap, sc.SymbolCount)
+	for i := range symbols {
+		symbols[i] = newSymbol(i, sc.Start, sc.Rate, sc.BurstFactor, interval)
+	}
+	heap.Init(&symbols)
+
+	return &Simulator{
+		symbols:   symbols,
+		end:       sc.End,
+		maxPoints: limit,
+	}
+}
+
+// Simulator merges the trades and quotes of all the symbols in time order.
+// It fulfills the common.Simulator interface.
+type Simulator struct {
+	symbols symbolHeap
+	end     time.Time
+
+	madePoints uint64
+	// maxPoints is the limit of points to make, 0 for no limit
+	maxPoints uint64
+}
+
+// Finished tells whether we have simulated all the necessary points.
+func (s *Simulator) Finished() bool {
+	if s.maxPoints > 0 && s.madePoints >= s.maxPoints {
+		return true
+	}
+	return !s.symbols[0].now.Before(s.end)
+}
+
+// Next populates a Point with the next trade or quote of all the symbols.
+func (s *Simulator) Next(p *data.Point) bool {
+	sym := s.symbols[0]
+	ts := sym.now
+
+	p.AppendTag(labelSymbol, sym.name)
+	p.AppendTag(labelExchange, sym.exchange)
+	p.AppendTag(labelSector, sym.sector)
+	p.SetTimestamp(&ts)
+
+	bid, ask := sym.bidAsk()
+	if sym.quote {
+		p.SetMeasurementName(labelQuotes)
+		p.AppendField(labelBidPrice, bid)
+		p.AppendField(labelBidSize, int64(lotSize*(1+rand.Intn(maxQuoteLots))))
+		p.AppendField(labelAskPrice, ask)
+		p.AppendField(labelAskSize, int64(lotSize*(1+rand.Intn(maxQuoteLots))))
+	} else {
+		// Trades take the liquidity on either side of the quote.
+		price := bid
+		if rand.Intn(2) == 0 {
+			price = ask
+		}
+		p.SetMeasurementName(labelTrades)
+		p.AppendField(labelPrice, price)
+		p.AppendField(labelSize, int64(1+rand.ExpFloat64()*meanTradeSize))
+	}
+
+	sym.advance()
+	heap.Fix(&s.symbols, 0)
+	s.madePoints++
+	return true
+}
+
+// Fields returns the field keys of the trades and quotes.
+func (s *Simulator) Fields() map[string][]string {
+	return map[string][]string{
+		string(labelTrades): bytesToStrings(tradeKeys),
+		string(labelQuotes): bytesToStrings(quoteKeys),
+	}
+}
+
+// TagKeys returns the tag keys of the symbols.
+func (s *Simulator) TagKeys() []string {
+	return bytesToStrings(tagKeys)
+}
+
+// TagTypes returns the type of each tag of the symbols.
+func (s *Simulator) TagTypes() []string {
+	types := make([]string, len(tagKeys))
+	for i := range types {
+		types[i] = "string"
+	}
+	return types
+}
+
+// Headers returns the tags and fields of the generated data.
+func (s *Simulator) Headers() *common.GeneratedDataHeaders {
+	return &common.GeneratedDataHeaders{
+		TagTypes:  s.TagTypes(),
+		TagKeys:   s.TagKeys(),
+		FieldKeys: s.Fields(),
+	}
+}
+
+func bytesToStrings(b [][]byte) []string {
+	s := make([]string, len(b))
+	for i := range b {
+		s[i] = string(b[i])
+	}
+	return s
+}
+
+// symbolHeap orders the symbols by the time of their next event. It
+// implements heap.Interface.
+type symbolHeap []*symbol
+
+func (h symbolHeap) Len() int           { return len(h) }
+func (h symbolHeap) Less(i, j int) bool { return h[i].now.Before(h[j].now) }
+func (h symbolHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *symbolHeap) Push(x interface{}) {
+	*h = append(*h, x.(*symbol))
+}
+
+func (h *symbolHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+	return x
+}
@@ -0,0 +1,94 @@
+package ticks
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/bodhiye/tsbs/pkg/data"
+)
+
+func TestSimulatorConfigNewSimulator(t *testing.T) {
+	start := time.Date(2016, time.January, 1, 0, 0, 0, 0, time.UTC)
+	sc := &SimulatorConfig{
+		Start:       start,
+		End:         start.Add(time.Minute),
+		SymbolCount: 10,
+		Rate:        10,
+		BurstFactor: 1,
+	}
+	sim := sc.NewSimulator(10*time.Second, 0)
+
+	wantTags := []string{"symbol", "exchange", "sector"}
+	if got := sim.TagKeys(); !reflect.DeepEqual(got, wantTags) {
+		t.Errorf("incorrect tag keys: got %v want %v", got, wantTags)
+	}
+	wantFields := map[string][]string{
+		"trades": {"price", "size"},
+		"quotes": {"bid_price", "bid_size", "ask_price", "ask_size"},
+	}
+	if got := sim.Fields(); !reflect.DeepEqual(got, wantFields) {
+		t.Errorf("incorrect fields: got %v want %v", got, wantFields)
+	}
+
+	var prev time.Time
+	symbols := map[string]bool{}
+	points := map[string]int{}
+	for !sim.Finished() {
+		p := data.NewPoint()
+		if !sim.Next(p) {
+			t.Fatalf("point not to be written")
+		}
+		ts := *p.Timestamp()
+		if ts.Before(prev) {
+			t.Fatalf("points out of order: %v then %v", prev, ts)
+		}
+		if !ts.Before(sc.End) {
+			t.Fatalf("point after the end: %v", ts)
+		}
+		prev = ts
+
+		name := string(p.MeasurementName())
+		var keys []string
+		for _, k := range p.FieldKeys() {
+			keys = append(keys, string(k))
+		}
+		if !reflect.DeepEqual(keys, wantFields[name]) {
+			t.Fatalf("incorrect fields of %s: got %v want %v", name, keys, wantFields[name])
+		}
+		symbols[p.GetTagValue(labelSymbol).(string)] = true
+		points[name]++
+	}
+
+	if got := len(symbols); got != int(sc.SymbolCount) {
+		t.Errorf("incorrect number of symbols: got %d want %d", got, sc.SymbolCount)
+	}
+	// 10 symbols with 10 events per second over a minute, 3/4 of quotes
+	if got := points["trades"] + points["quotes"]; got < 5000 || got > 7000 {
+		t.Errorf("incorrect number of points: got %d want about 6000", got)
+	}
+	if points["quotes"] < 2*points["trades"] {
+		t.Errorf("not enough quotes: got %d quotes for %d trades", points["quotes"], points["trades"])
+	}
+}
+
+func TestSimulatorLimit(t *testing.T) {
+	start := time.Date(2016, time.January, 1, 0, 0, 0, 0, time.UTC)
+	sc := &SimulatorConfig{
+		Start:       start,
+		End:         start.Add(time.Hour),
+		SymbolCount: 3,
+		Rate:        10,
+		BurstFactor: 10,
+	}
+	sim := sc.NewSimulator(time.Minute, 100)
+
+	count := 0
+	for !sim.Finished() {
+		sim.Next(data.NewPoint())
+		count++
+	}
+	if count != 100 {
+		t.Errorf("incorrect number of points: got %d want 100", count)
+	}
+}
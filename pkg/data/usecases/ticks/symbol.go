@@ -0,0 +1,153 @@
+package ticks
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+const (
+	// symbolLetters is the number of letters of the symbol names.
+	symbolLetters = 4
+
+	// volatility is the standard deviation of the log price change of a
+	// symbol over one second.
+	volatility = 0.0001
+	// priceMin and priceMax bound the initial prices of the symbols.
+	priceMin = 10.0
+	priceMax = 500.0
+	// ticksPerDollar is the number of smallest price increments in a
+	// dollar.
+	ticksPerDollar = 100
+	tickSize       = 1.0 / ticksPerDollar
+	// maxSpreadTicks is the largest spread between bid and ask, in ticks.
+	maxSpreadTicks = 5
+	// lotSize is the number of shares of a round lot.
+	lotSize = 100
+	// maxQuoteLots is the largest quoted size, in lots.
+	maxQuoteLots = 20
+	// meanTradeSize is the mean number of shares of a trade.
+	meanTradeSize = 200
+
+	// quoteProbability is the probability of an event of a symbol to be a
+	// quote update rather than a trade.
+	quoteProbability = 0.75
+	// burstProbability is the probability of a symbol to be in a burst of
+	// activity for a session.
+	burstProbability = 0.1
+)
+
+var (
+	exchangeChoices = []string{
+		"NYSE",
+		"NASDAQ",
+		"ARCA",
+		"BATS",
+		"IEX",
+	}
+
+	sectorChoices = []string{
+		"technology",
+		"financials",
+		"healthcare",
+		"energy",
+		"industrials",
+		"utilities",
+		"consumer",
+	}
+)
+
+// SymbolName returns the name of the i-th symbol: AAAA, AAAB, ... AAAZ,
+// AABA and so on.
+func SymbolName(i int) string {
+	b := make([]byte, symbolLetters)
+	for j := symbolLetters - 1; j >= 0; j-- {
+		b[j] = byte('A' + i%26)
+		i /= 26
+	}
+	return string(b)
+}
+
+// SymbolNames returns the names of the first count symbols.
+func SymbolNames(count int) []string {
+	names := make([]string, count)
+	for i := range names {
+		names[i] = SymbolName(i)
+	}
+	return names
+}
+
+// symbol is a traded instrument, whose trades and quotes happen as a
+// Poisson process. The rate of the process goes up burstFactor times while
+// the symbol is in a burst of activity, which is drawn anew every session.
+type symbol struct {
+	name     string
+	exchange string
+	sector   string
+
+	rate          float64
+	burstFactor   float64
+	session       time.Duration
+	sessionEnd    time.Time
+	bursting      bool
+	spreadInTicks int
+
+	// now is the time of the current event of the symbol
+	now   time.Time
+	price float64
+	quote bool
+}
+
+func newSymbol(i int, start time.Time, rate, burstFactor float64, session time.Duration) *symbol {
+	s := &symbol{
+		name:          SymbolName(i),
+		exchange:      exchangeChoices[i%len(exchangeChoices)],
+		sector:        sectorChoices[(i/len(exchangeChoices))%len(sectorChoices)],
+		rate:          rate,
+		burstFactor:   burstFactor,
+		session:       session,
+		sessionEnd:    start,
+		spreadInTicks: 1 + rand.Intn(maxSpreadTicks),
+		now:           start,
+		price:         roundToTick(priceMin + rand.Float64()*(priceMax-priceMin)),
+	}
+	s.advance()
+	return s
+}
+
+// advance moves the symbol to its next event, at least one nanosecond
+// later, and walks its price over the elapsed time.
+func (s *symbol) advance() {
+	from := s.now
+	for {
+		rate := s.rate
+		if s.bursting {
+			rate *= s.burstFactor
+		}
+		next := s.now.Add(time.Duration(math.Max(1, rand.ExpFloat64()/rate*float64(time.Second))))
+		if next.Before(s.sessionEnd) {
+			s.now = next
+			break
+		}
+		// The process is memoryless, so the wait can restart at the end
+		// of the session with the rate of the next one.
+		s.now = s.sessionEnd
+		s.sessionEnd = s.sessionEnd.Add(s.session)
+		s.bursting = rand.Float64() < burstProbability
+	}
+
+	elapsed := s.now.Sub(from).Seconds()
+	s.price *= math.Exp(volatility * math.Sqrt(elapsed) * rand.NormFloat64())
+	s.price = math.Max(tickSize, s.price)
+	s.quote = rand.Float64() < quoteProbability
+}
+
+// bidAsk returns the best bid and ask around the price of the symbol.
+func (s *symbol) bidAsk() (float64, float64) {
+	bid := math.Max(tickSize, roundToTick(s.price-float64(s.spreadInTicks)*tickSize/2))
+	return bid, roundToTick(bid + float64(s.spreadInTicks)*tickSize)
+}
+
+func roundToTick(price float64) float64 {
+	return math.Round(price*ticksPerDollar) / ticksPerDollar
+}
@@ -0,0 +1,84 @@
+package ticks
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSymbolName(t *testing.T) {
+	cases := []struct {
+		i    int
+		want string
+	}{
+		{0, "AAAA"},
+		{1, "AAAB"},
+		{25, "AAAZ"},
+		{26, "AABA"},
+		{26*26*26 + 2, "BAAC"},
+	}
+	for _, c := range cases {
+		if got := SymbolName(c.i); got != c.want {
+			t.Errorf("incorrect name of symbol %d: got %s want %s", c.i, got, c.want)
+		}
+	}
+
+	if got, want := SymbolNames(3), []string{"AAAA", "AAAB", "AAAC"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("incorrect symbol names: got %v want %v", got, want)
+	}
+}
+
+func TestSymbolAdvance(t *testing.T) {
+	rand.Seed(123)
+	start := time.Date(2016, time.January, 1, 0, 0, 0, 0, time.UTC)
+	s := newSymbol(0, start, 1000, 1, time.Minute)
+
+	events := 0
+	for prev := start; s.now.Before(start.Add(time.Minute)); s.advance() {
+		if !s.now.After(prev) {
+			t.Fatalf("event not after the previous one: %v then %v", prev, s.now)
+		}
+		prev = s.now
+		if s.price <= 0 {
+			t.Fatalf("price not positive: %f", s.price)
+		}
+		bid, ask := s.bidAsk()
+		if ask <= bid {
+			t.Fatalf("ask not above bid: %f, %f", bid, ask)
+		}
+		if bid != roundToTick(bid) || ask != roundToTick(ask) {
+			t.Fatalf("quote not on ticks: %v, %v", bid, ask)
+		}
+		events++
+	}
+
+	// a Poisson process of 1000 events per second over a minute
+	if want := 60000.0; math.Abs(float64(events)-want) > 0.05*want {
+		t.Errorf("incorrect number of events: got %d want about %.0f", events, want)
+	}
+}
+
+func TestSymbolBurst(t *testing.T) {
+	rand.Seed(123)
+	start := time.Date(2016, time.January, 1, 0, 0, 0, 0, time.UTC)
+	s := newSymbol(0, start, 10, 50, time.Second)
+
+	perSession := map[time.Time]int{}
+	for s.now.Before(start.Add(time.Hour)) {
+		perSession[s.now.Truncate(time.Second)]++
+		s.advance()
+	}
+
+	bursts := 0
+	for _, n := range perSession {
+		if n > 100 {
+			bursts++
+		}
+	}
+	// about burstProbability of the 3600 sessions have 500 events
+	if bursts < 250 || bursts > 470 {
+		t.Errorf("incorrect number of burst sessions: got %d want about 360", bursts)
+	}
+}
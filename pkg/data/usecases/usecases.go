@@ -5,8 +5,12 @@ import (
 	"math"
 
 	"github.com/bodhiye/tsbs/pkg/data/usecases/common"
+	"github.com/bodhiye/tsbs/pkg/data/usecases/custom"
 	"github.com/bodhiye/tsbs/pkg/data/usecases/devops"
+	"github.com/bodhiye/tsbs/pkg/data/usecases/events"
 	"github.com/bodhiye/tsbs/pkg/data/usecases/iot"
+	"github.com/bodhiye/tsbs/pkg/data/usecases/k8s"
+	"github.com/bodhiye/tsbs/pkg/data/usecases/ticks"
 	"github.com/bodhiye/tsbs/tools/utils"
 )
 
@@ -33,6 +37,7 @@ func GetSimulatorConfig(dgc *common.DataGeneratorConfig) (common.SimulatorConfig
 			InitHostCount:   dgc.InitialScale,
 			HostCount:       dgc.Scale,
 			HostConstructor: devops.NewHost,
+			Tags:            devopsTagConfig(dgc),
 		}
 	case common.UseCaseIoT:
 		ret = &iot.SimulatorConfig{
@@ -51,6 +56,7 @@ func GetSimulatorConfig(dgc *common.DataGeneratorConfig) (common.SimulatorConfig
 			InitHostCount:   dgc.InitialScale,
 			HostCount:       dgc.Scale,
 			HostConstructor: devops.NewHostCPUOnly,
+			Tags:            devopsTagConfig(dgc),
 		}
 	case common.UseCaseCPUSingle:
 		ret = &devops.CPUOnlySimulatorConfig{
@@ -60,6 +66,7 @@ func GetSimulatorConfig(dgc *common.DataGeneratorConfig) (common.SimulatorConfig
 			InitHostCount:   dgc.InitialScale,
 			HostCount:       dgc.Scale,
 			HostConstructor: devops.NewHostCPUSingle,
+			Tags:            devopsTagConfig(dgc),
 		}
 	case common.UseCaseDevopsGeneric:
 		if dgc.InitialScale == dgc.Scale {
@@ -75,10 +82,68 @@ func GetSimulatorConfig(dgc *common.DataGeneratorConfig) (common.SimulatorConfig
 				HostCount:       dgc.Scale,
 				HostConstructor: devops.NewHostGenericMetrics,
 				MaxMetricCount:  dgc.MaxMetricCountPerHost,
+				Tags:            devopsTagConfig(dgc),
 			},
 		}
+	case common.UseCaseK8s:
+		ret = &k8s.SimulatorConfig{
+			Start: tsStart,
+			End:   tsEnd,
+
+			InitPodCount: dgc.InitialScale,
+			PodCount:     dgc.Scale,
+			ChurnRate:    dgc.K8sChurnRate,
+			RestartRate:  dgc.K8sRestartRate,
+		}
+	case common.UseCaseTicks:
+		ret = &ticks.SimulatorConfig{
+			Start: tsStart,
+			End:   tsEnd,
+
+			SymbolCount: dgc.Scale,
+			Rate:        dgc.TicksRate,
+			BurstFactor: dgc.TicksBurstFactor,
+		}
+	case common.UseCaseEvents:
+		ret = &events.SimulatorConfig{
+			Start: tsStart,
+			End:   tsEnd,
+
+			InstanceCount: dgc.Scale,
+			MessageSize:   dgc.EventsMessageSize,
+		}
+	case common.UseCaseCustom:
+		schema, err := custom.ReadSchemaFile(dgc.SchemaFile)
+		if err != nil {
+			return nil, err
+		}
+		if err := schema.CheckInterval(dgc.LogInterval); err != nil {
+			return nil, err
+		}
+		ret = &custom.SimulatorConfig{
+			Start: tsStart,
+			End:   tsEnd,
+
+			InitEntityCount: dgc.InitialScale,
+			EntityCount:     dgc.Scale,
+			Schema:          schema,
+		}
 	default:
 		err = fmt.Errorf("unknown use case: '%s'", dgc.Use)
 	}
 	return ret, err
 }
+
+// devopsTagConfig returns the tags of the hosts of the devops use cases.
+func devopsTagConfig(dgc *common.DataGeneratorConfig) devops.TagConfig {
+	return devops.TagConfig{
+		RegionCount:         dgc.DevopsRegions,
+		DatacenterCount:     dgc.DevopsDatacenters,
+		RackCount:           dgc.DevopsRacks,
+		ServiceCount:        dgc.DevopsServices,
+		ServiceVersionCount: dgc.DevopsServiceVersions,
+		ExtraTagCount:       dgc.DevopsExtraTags,
+		ExtraTagCardinality: dgc.DevopsExtraTagCardinality,
+		TagValueLength:      dgc.DevopsTagValueLength,
+	}
+}
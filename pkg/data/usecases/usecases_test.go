@@ -1,13 +1,19 @@
 package usecases
 
 import (
+	"io/ioutil"
+	"path/filepath"
 	"reflect"
 	"testing"
 	"time"
 
 	"github.com/bodhiye/tsbs/pkg/data/usecases/common"
+	"github.com/bodhiye/tsbs/pkg/data/usecases/custom"
 	"github.com/bodhiye/tsbs/pkg/data/usecases/devops"
+	"github.com/bodhiye/tsbs/pkg/data/usecases/events"
 	"github.com/bodhiye/tsbs/pkg/data/usecases/iot"
+	"github.com/bodhiye/tsbs/pkg/data/usecases/k8s"
+	"github.com/bodhiye/tsbs/pkg/data/usecases/ticks"
 )
 
 const defaultLogInterval = 10 * time.Second
@@ -39,6 +45,21 @@ func TestGetSimulatorConfig(t *testing.T) {
 	checkType(common.UseCaseIoT, &iot.SimulatorConfig{})
 	checkType(common.UseCaseCPUOnly, &devops.CPUOnlySimulatorConfig{})
 	checkType(common.UseCaseCPUSingle, &devops.CPUOnlySimulatorConfig{})
+	checkType(common.UseCaseK8s, &k8s.SimulatorConfig{})
+	checkType(common.UseCaseTicks, &ticks.SimulatorConfig{})
+	checkType(common.UseCaseEvents, &events.SimulatorConfig{})
+
+	dgc.SchemaFile = filepath.Join(t.TempDir(), "schema.yaml")
+	schema := "measurements: [{name: m, interval: 15s, fields: [{name: f, distribution: {type: constant}}]}]"
+	if err := ioutil.WriteFile(dgc.SchemaFile, []byte(schema), 0644); err != nil {
+		t.Fatalf("could not write schema file: %v", err)
+	}
+	dgc.Use = common.UseCaseCustom
+	if _, err := GetSimulatorConfig(dgc); err == nil {
+		t.Errorf("unexpected lack of error for interval not a multiple of the log interval")
+	}
+	dgc.LogInterval = 5 * time.Second
+	checkType(common.UseCaseCustom, &custom.SimulatorConfig{})
 
 	dgc.Use = "bogus use case"
 	_, err := GetSimulatorConfig(dgc)
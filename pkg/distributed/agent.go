@@ -0,0 +1,146 @@
+package distributed
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// progressTimeout is the timeout of progress reports, which are skipped
+// when the coordinator is slow to answer.
+const progressTimeout = 5 * time.Second
+
+// AbortedError is returned by the requests of an agent once the coordinator
+// aborted the benchmark, because another agent failed or timed out.
+type AbortedError struct {
+	Reason string
+}
+
+func (e *AbortedError) Error() string {
+	return fmt.Sprintf("coordinator aborted the benchmark: %s", e.Reason)
+}
+
+// Agent is a benchmark runner that joined a coordinator.
+type Agent struct {
+	Assignment
+	Name string
+
+	url    string
+	client *http.Client
+}
+
+// Join registers an agent of the given kind with the coordinator at address,
+// a host:port or an URL. The shard of the agent depends on its id, unique
+// among the agents, which is the host name if empty, so an agent restarted
+// on the same host gets the same shard. It returns once all agents joined,
+// with the shard assigned to the agent.
+func Join(address, kind, id string) (*Agent, error) {
+	url := strings.TrimSuffix(address, "/")
+	if !strings.Contains(url, "://") {
+		url = "http://" + url
+	}
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	if id == "" {
+		id = host
+	}
+	a := &Agent{
+		Name:   fmt.Sprintf("%s:%d", host, os.Getpid()),
+		url:    url,
+		client: &http.Client{},
+	}
+
+	resp, err := a.post("/register", registration{Name: a.Name, ID: id, Kind: kind})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(&a.Assignment); err != nil {
+		return nil, fmt.Errorf("cannot decode assignment: %v", err)
+	}
+	return a, nil
+}
+
+// Barrier returns once all agents reached the named barrier.
+func (a *Agent) Barrier(name string) error {
+	resp, err := a.post("/barrier/"+name, barrierArrival{Agent: a.Agent})
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// ReportProgress sends the counters returned by counters to the coordinator
+// every progress period. aborted is called with the reason when the
+// coordinator answers that the benchmark was aborted, after which no more
+// reports are sent. The returned function stops the reports.
+func (a *Agent) ReportProgress(counters func() Counters, aborted func(reason string)) func() {
+	if a.ProgressPeriod <= 0 {
+		return func() {}
+	}
+	done := make(chan struct{})
+	go func() {
+		client := &http.Client{Timeout: progressTimeout}
+		ticker := time.NewTicker(a.ProgressPeriod)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				// progress is best effort, the final report has the totals
+				resp, err := a.postWith(client, "/progress", Progress{Agent: a.Agent, Counters: counters()})
+				if abortErr, ok := err.(*AbortedError); ok {
+					aborted(abortErr.Reason)
+					return
+				}
+				if err == nil {
+					resp.Body.Close()
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+// Report sends the final report of the agent to the coordinator.
+func (a *Agent) Report(r *Report) error {
+	r.Agent = a.Agent
+	r.Name = a.Name
+	resp, err := a.post("/report", r)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+func (a *Agent) post(path string, v interface{}) (*http.Response, error) {
+	return a.postWith(a.client, path, v)
+}
+
+func (a *Agent) postWith(client *http.Client, path string, v interface{}) (*http.Response, error) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Post(a.url+path, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("cannot reach coordinator: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusGone {
+			return nil, &AbortedError{Reason: strings.TrimSpace(string(msg))}
+		}
+		return nil, fmt.Errorf("coordinator returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return resp, nil
+}
@@ -0,0 +1,629 @@
+package distributed
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/HdrHistogram/hdrhistogram-go"
+	"github.com/spf13/pflag"
+)
+
+const (
+	usecsPerMilli   = 1e3
+	shutdownTimeout = 5 * time.Second
+
+	// DefaultPhaseTimeout is the default time to wait for all agents to join
+	// or to reach a barrier
+	DefaultPhaseTimeout = 10 * time.Minute
+	// DefaultAgentTimeout is the default time after which a running agent
+	// that sent no progress is considered failed
+	DefaultAgentTimeout = time.Minute
+)
+
+// change for more useful testing
+var printFn = fmt.Printf
+
+// CoordinatorConfig is the configuration of the coordinator.
+type CoordinatorConfig struct {
+	Listen         string        `mapstructure:"listen"`
+	Agents         int           `mapstructure:"agents"`
+	ProgressPeriod time.Duration `mapstructure:"progress-period"`
+	PhaseTimeout   time.Duration `mapstructure:"phase-timeout"`
+	AgentTimeout   time.Duration `mapstructure:"agent-timeout"`
+	ResultsFile    string        `mapstructure:"results-file"`
+	Files          []string      `mapstructure:"files"`
+}
+
+// AddToFlagSet adds command line flags needed by the CoordinatorConfig to the flag set.
+func (c CoordinatorConfig) AddToFlagSet(fs *pflag.FlagSet) {
+	fs.String("listen", ":8099", "Address to listen on for agents")
+	fs.Int("agents", 2, "Number of agents to wait for before starting the benchmark")
+	fs.Duration("progress-period", DefaultProgressPeriod, "Period at which agents report their progress, 0 to disable")
+	fs.Duration("phase-timeout", DefaultPhaseTimeout, "Abort the benchmark if the agents don't all join or reach a barrier (e.g. database created) within this time, 0 = no timeout")
+	fs.Duration("agent-timeout", DefaultAgentTimeout, "Abort the benchmark if a running agent sends no progress for this long, 0 = no liveness check (requires progress-period)")
+	fs.String("results-file", "", "Write the aggregated results of all agents to this json file")
+	fs.StringSlice("files", nil, "Files the agents load instead of their own, one per agent, assigned in the order of the agent IDs")
+}
+
+// barrier is released once all agents reached it.
+type barrier struct {
+	arrived int
+	ch      chan struct{}
+	timer   *time.Timer
+}
+
+// Coordinator assigns shards to the agents, synchronizes their start and
+// aggregates their progress and results.
+type Coordinator struct {
+	CoordinatorConfig
+
+	mu   sync.Mutex
+	kind string
+	// agents holds the registrations of the agents, in the order of their
+	// IDs once all agents joined, and names their names
+	agents     []registration
+	names      []string
+	registered chan struct{}
+	barriers   map[string]*barrier
+	start      time.Time
+	started    chan struct{}
+	progress   []Counters
+	reports    []*Report
+	reported   int
+	lastSeen   []time.Time
+	done       chan struct{}
+	// aborted is closed when an agent failed or timed out
+	aborted     chan struct{}
+	abortOnce   sync.Once
+	abortReason string
+}
+
+// NewCoordinator returns a Coordinator waiting for c.Agents agents.
+func NewCoordinator(c CoordinatorConfig) (*Coordinator, error) {
+	if c.Agents < 1 {
+		return nil, fmt.Errorf("the number of agents must be at least 1, got %d", c.Agents)
+	}
+	if len(c.Files) > 0 && len(c.Files) != c.Agents {
+		return nil, fmt.Errorf("the number of files (%d) must be the number of agents (%d)", len(c.Files), c.Agents)
+	}
+	if c.AgentTimeout > 0 && c.ProgressPeriod > 0 && c.AgentTimeout < 2*c.ProgressPeriod {
+		return nil, fmt.Errorf("agent-timeout (%v) must be at least twice the progress-period (%v)", c.AgentTimeout, c.ProgressPeriod)
+	}
+	return &Coordinator{
+		CoordinatorConfig: c,
+		registered:        make(chan struct{}),
+		barriers:          make(map[string]*barrier),
+		started:           make(chan struct{}),
+		progress:          make([]Counters, c.Agents),
+		reports:           make([]*Report, c.Agents),
+		lastSeen:          make([]time.Time, c.Agents),
+		done:              make(chan struct{}),
+		aborted:           make(chan struct{}),
+	}, nil
+}
+
+// Handler returns the HTTP handler serving the agents.
+func (c *Coordinator) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/register", c.handleRegister)
+	mux.HandleFunc("/barrier/", c.handleBarrier)
+	mux.HandleFunc("/progress", c.handleProgress)
+	mux.HandleFunc("/report", c.handleReport)
+	return mux
+}
+
+// Run serves the agents until all of them reported their results, printing
+// their combined progress, and returns the aggregated results, which are
+// also written to the results file if set. It returns an error, and the
+// agents are told to stop, when an agent fails or times out.
+func (c *Coordinator) Run() (*Results, error) {
+	ln, err := net.Listen("tcp", c.Listen)
+	if err != nil {
+		return nil, fmt.Errorf("cannot listen on %s: %v", c.Listen, err)
+	}
+	srv := &http.Server{Handler: c.Handler()}
+	go srv.Serve(ln)
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		srv.Shutdown(ctx)
+	}()
+
+	printFn("waiting for %d agents on %s\n", c.Agents, ln.Addr())
+	if err := c.waitForPhase(c.registered, "all agents to join"); err != nil {
+		return nil, err
+	}
+	printFn("all agents joined: %s\n", strings.Join(c.agentIDs(), ", "))
+	select {
+	case <-c.started:
+	case <-c.done:
+	case <-c.aborted:
+		return nil, c.abortError()
+	}
+	stopMonitor := c.monitorAgents()
+	c.reportProgress()
+	stopMonitor()
+	if c.isAborted() {
+		c.waitForAgentsToStop()
+		return nil, c.abortError()
+	}
+
+	results, err := c.results()
+	if err != nil {
+		return nil, err
+	}
+	c.summary(results)
+	if c.ResultsFile != "" {
+		if err := c.saveResults(results); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// waitForPhase waits until ch is closed, aborting the benchmark if that
+// takes longer than the phase timeout.
+func (c *Coordinator) waitForPhase(ch <-chan struct{}, desc string) error {
+	var timeout <-chan time.Time
+	if c.PhaseTimeout > 0 {
+		timer := time.NewTimer(c.PhaseTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case <-ch:
+		return nil
+	case <-c.aborted:
+	case <-timeout:
+		c.abort(fmt.Sprintf("timed out after %v waiting for %s", c.PhaseTimeout, desc))
+	}
+	return c.abortError()
+}
+
+// monitorAgents aborts the benchmark when a running agent that did not
+// report its results yet sent no progress for the agent timeout. The
+// returned function stops the monitoring.
+func (c *Coordinator) monitorAgents() func() {
+	if c.AgentTimeout <= 0 || c.ProgressPeriod <= 0 {
+		return func() {}
+	}
+	stop := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(c.ProgressPeriod)
+		defer ticker.Stop()
+		for {
+			select {
+			case now := <-ticker.C:
+				if reason := c.deadAgent(now); reason != "" {
+					c.abort(reason)
+					return
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+	return func() { close(stop) }
+}
+
+// deadAgent returns why an agent is considered failed at now, or an empty
+// string if all agents are alive.
+func (c *Coordinator) deadAgent(now time.Time) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, seen := range c.lastSeen {
+		if c.reports[i] == nil && now.Sub(seen) > c.AgentTimeout {
+			return fmt.Sprintf("agent %d (%s) sent no progress for %v", i, c.names[i], now.Sub(seen).Round(time.Second))
+		}
+	}
+	return ""
+}
+
+// abort stops the benchmark, keeping the reason of the first abort. Agents
+// waiting for the others are answered at once, and running agents are told
+// to stop with the answer to their next progress report.
+func (c *Coordinator) abort(reason string) {
+	c.abortOnce.Do(func() {
+		printFn("aborting benchmark: %s\n", reason)
+		c.abortReason = reason
+		close(c.aborted)
+	})
+}
+
+// isAborted returns whether the benchmark has been aborted.
+func (c *Coordinator) isAborted() bool {
+	select {
+	case <-c.aborted:
+		return true
+	default:
+		return false
+	}
+}
+
+// abortError returns the reason the benchmark was aborted as an error. The
+// reason is set before aborted is closed, so it is only read once aborted is
+// closed.
+func (c *Coordinator) abortError() error {
+	<-c.aborted
+	return fmt.Errorf("benchmark aborted: %s", c.abortReason)
+}
+
+// rejectIfAborted answers the request of an agent with the abort reason if
+// the benchmark has been aborted, returning whether it did.
+func (c *Coordinator) rejectIfAborted(w http.ResponseWriter) bool {
+	if !c.isAborted() {
+		return false
+	}
+	http.Error(w, c.abortReason, http.StatusGone)
+	return true
+}
+
+// waitForAgentsToStop keeps serving the running agents after an abort until
+// they had the chance to send a progress report, so they learn about it.
+func (c *Coordinator) waitForAgentsToStop() {
+	if c.ProgressPeriod <= 0 {
+		return
+	}
+	wait := c.ProgressPeriod + progressTimeout
+	printFn("waiting %v for the agents to stop\n", wait)
+	time.Sleep(wait)
+}
+
+// reportProgress prints the combined progress of the agents every progress
+// period, until all agents reported their results or the benchmark is
+// aborted.
+func (c *Coordinator) reportProgress() {
+	if c.ProgressPeriod <= 0 {
+		select {
+		case <-c.done:
+		case <-c.aborted:
+		}
+		return
+	}
+	if c.kind == KindLoad {
+		printFn("time,metric total,overall metric/s,row total,overall row/s\n")
+	} else {
+		printFn("time,query total,overall query/s,errors\n")
+	}
+	ticker := time.NewTicker(c.ProgressPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			c.mu.Lock()
+			var total Counters
+			for _, p := range c.progress {
+				total.add(p)
+			}
+			took := now.Sub(c.start).Seconds()
+			c.mu.Unlock()
+			if c.kind == KindLoad {
+				printFn("%d,%E,%0.2f,%E,%0.2f\n", now.Unix(), float64(total.Metrics), float64(total.Metrics)/took, float64(total.Rows), float64(total.Rows)/took)
+			} else {
+				printFn("%d,%d,%0.2f,%d\n", now.Unix(), total.Queries, float64(total.Queries)/took, total.Errors)
+			}
+		case <-c.done:
+			return
+		case <-c.aborted:
+			return
+		}
+	}
+}
+
+func (c *Coordinator) handleRegister(w http.ResponseWriter, r *http.Request) {
+	var reg registration
+	if err := json.NewDecoder(r.Body).Decode(&reg); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if reg.ID == "" {
+		http.Error(w, "agent has no id", http.StatusBadRequest)
+		return
+	}
+	if c.rejectIfAborted(w) {
+		return
+	}
+
+	c.mu.Lock()
+	if len(c.agents) == c.Agents {
+		c.mu.Unlock()
+		http.Error(w, fmt.Sprintf("all %d agents already joined", c.Agents), http.StatusConflict)
+		return
+	}
+	if c.kind == "" {
+		c.kind = reg.Kind
+	} else if c.kind != reg.Kind {
+		c.mu.Unlock()
+		http.Error(w, fmt.Sprintf("agents of kind '%s' joined already, got '%s'", c.kind, reg.Kind), http.StatusConflict)
+		return
+	}
+	for _, other := range c.agents {
+		if other.ID == reg.ID {
+			c.mu.Unlock()
+			http.Error(w, fmt.Sprintf("an agent with id '%s' (%s) joined already, agents need unique ids", reg.ID, other.Name), http.StatusConflict)
+			return
+		}
+	}
+	c.agents = append(c.agents, reg)
+	if len(c.agents) == c.Agents {
+		c.assignShards()
+		close(c.registered)
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-c.registered:
+	case <-c.aborted:
+		c.rejectIfAborted(w)
+		return
+	case <-r.Context().Done():
+		c.abort(fmt.Sprintf("agent %s left before all agents joined", reg.Name))
+		return
+	}
+	c.mu.Lock()
+	assignment := c.assignment(reg.ID)
+	c.mu.Unlock()
+	writeJSON(w, assignment)
+}
+
+// assignShards numbers the agents in the order of their IDs, so the shard
+// of an agent does not depend on the order the agents joined in.
+func (c *Coordinator) assignShards() {
+	sort.Slice(c.agents, func(i, j int) bool {
+		return c.agents[i].ID < c.agents[j].ID
+	})
+	c.names = make([]string, len(c.agents))
+	for i, reg := range c.agents {
+		c.names[i] = reg.Name
+	}
+}
+
+// agentIDs returns the IDs and names of the agents, in the order of their
+// shards.
+func (c *Coordinator) agentIDs() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ids := make([]string, len(c.agents))
+	for i, reg := range c.agents {
+		ids[i] = fmt.Sprintf("%s (%s)", reg.ID, reg.Name)
+	}
+	return ids
+}
+
+// assignment returns the assignment of the agent with the given ID, once
+// all agents joined.
+func (c *Coordinator) assignment(id string) Assignment {
+	a := Assignment{Agents: c.Agents, ID: id, ProgressPeriod: c.ProgressPeriod}
+	for i, reg := range c.agents {
+		if reg.ID == id {
+			a.Agent = i
+		}
+	}
+	a.InterleavedGroupID = uint(a.Agent)
+	a.InterleavedNumGroups = uint(c.Agents)
+	if len(c.Files) > 0 {
+		a.File = c.Files[a.Agent]
+	}
+	return a
+}
+
+func (c *Coordinator) handleBarrier(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, "/barrier/")
+	var arrival barrierArrival
+	if err := json.NewDecoder(r.Body).Decode(&arrival); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if c.rejectIfAborted(w) {
+		return
+	}
+	c.mu.Lock()
+	b, ok := c.barriers[name]
+	if !ok {
+		b = &barrier{ch: make(chan struct{})}
+		c.barriers[name] = b
+		if c.PhaseTimeout > 0 {
+			b.timer = time.AfterFunc(c.PhaseTimeout, func() {
+				c.barrierTimedOut(name, b)
+			})
+		}
+	}
+	b.arrived++
+	if b.arrived == c.Agents {
+		if b.timer != nil {
+			b.timer.Stop()
+		}
+		if name == BarrierStart {
+			c.start = time.Now()
+			for i := range c.lastSeen {
+				c.lastSeen[i] = c.start
+			}
+			close(c.started)
+		}
+		close(b.ch)
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-b.ch:
+	case <-c.aborted:
+		c.rejectIfAborted(w)
+		return
+	case <-r.Context().Done():
+		c.abort(fmt.Sprintf("agent %d left while waiting at barrier '%s'", arrival.Agent, name))
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// barrierTimedOut aborts the benchmark if not all agents reached the barrier
+// within the phase timeout.
+func (c *Coordinator) barrierTimedOut(name string, b *barrier) {
+	c.mu.Lock()
+	arrived := b.arrived
+	c.mu.Unlock()
+	if arrived < c.Agents {
+		c.abort(fmt.Sprintf("timed out after %v waiting for all agents to reach barrier '%s', %d of %d arrived", c.PhaseTimeout, name, arrived, c.Agents))
+	}
+}
+
+func (c *Coordinator) handleProgress(w http.ResponseWriter, r *http.Request) {
+	var p Progress
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if p.Agent < 0 || p.Agent >= c.Agents {
+		http.Error(w, fmt.Sprintf("unknown agent %d", p.Agent), http.StatusBadRequest)
+		return
+	}
+	if c.rejectIfAborted(w) {
+		return
+	}
+	c.mu.Lock()
+	c.progress[p.Agent] = p.Counters
+	c.lastSeen[p.Agent] = time.Now()
+	c.mu.Unlock()
+	w.WriteHeader(http.StatusOK)
+}
+
+func (c *Coordinator) handleReport(w http.ResponseWriter, r *http.Request) {
+	var report Report
+	if err := json.NewDecoder(r.Body).Decode(&report); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if report.Agent < 0 || report.Agent >= c.Agents {
+		http.Error(w, fmt.Sprintf("unknown agent %d", report.Agent), http.StatusBadRequest)
+		return
+	}
+	if c.rejectIfAborted(w) {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.reports[report.Agent] != nil {
+		http.Error(w, fmt.Sprintf("agent %d reported already", report.Agent), http.StatusConflict)
+		return
+	}
+	c.reports[report.Agent] = &report
+	c.progress[report.Agent] = report.Counters
+	c.reported++
+	if c.reported == c.Agents {
+		close(c.done)
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// results aggregates the reports of all agents.
+func (c *Coordinator) results() (*Results, error) {
+	results := &Results{
+		ResultFormatVersion: ResultsFormatVersion,
+		Kind:                c.kind,
+		Agents:              c.Agents,
+		AgentReports:        c.reports,
+	}
+	histograms := make(map[string]*hdrhistogram.Histogram)
+	for i, report := range c.reports {
+		if i == 0 || report.StartTime < results.StartTime {
+			results.StartTime = report.StartTime
+		}
+		if report.EndTime > results.EndTime {
+			results.EndTime = report.EndTime
+		}
+		results.Totals.add(report.Counters)
+		results.Partial = results.Partial || report.Partial
+		for label, encoded := range report.Latencies {
+			h, err := hdrhistogram.Decode([]byte(encoded))
+			if err != nil {
+				return nil, fmt.Errorf("cannot decode '%s' latencies of agent %d: %v", label, report.Agent, err)
+			}
+			if merged, ok := histograms[label]; ok {
+				merged.Merge(h)
+			} else {
+				histograms[label] = h
+			}
+		}
+	}
+	results.DurationMillis = results.EndTime - results.StartTime
+	if seconds := float64(results.DurationMillis) / 1e3; seconds > 0 {
+		results.Rates = Rates{
+			MetricRate: float64(results.Totals.Metrics) / seconds,
+			RowRate:    float64(results.Totals.Rows) / seconds,
+			QueryRate:  float64(results.Totals.Queries) / seconds,
+		}
+	}
+	if len(histograms) > 0 {
+		results.Latencies = make(map[string]*LatencyStats, len(histograms))
+		for label, h := range histograms {
+			results.Latencies[label] = newLatencyStats(h)
+		}
+	}
+	return results, nil
+}
+
+func newLatencyStats(h *hdrhistogram.Histogram) *LatencyStats {
+	return &LatencyStats{
+		Count:  h.TotalCount(),
+		Min:    float64(h.Min()) / usecsPerMilli,
+		Mean:   h.Mean() / usecsPerMilli,
+		Max:    float64(h.Max()) / usecsPerMilli,
+		StdDev: h.StdDev() / usecsPerMilli,
+		P50:    float64(h.ValueAtQuantile(50.0)) / usecsPerMilli,
+		P90:    float64(h.ValueAtQuantile(90.0)) / usecsPerMilli,
+		P95:    float64(h.ValueAtQuantile(95.0)) / usecsPerMilli,
+		P99:    float64(h.ValueAtQuantile(99.0)) / usecsPerMilli,
+		P999:   float64(h.ValueAtQuantile(99.9)) / usecsPerMilli,
+	}
+}
+
+// summary prints the aggregated results.
+func (c *Coordinator) summary(results *Results) {
+	took := float64(results.DurationMillis) / 1e3
+	printFn("\nSummary:\n")
+	if results.Partial {
+		printFn("some agents were stopped before they finished, results are partial\n")
+	}
+	t := results.Totals
+	if results.Kind == KindLoad {
+		printFn("loaded %d metrics in %0.3fsec with %d agents (mean rate %0.2f metrics/sec)\n", t.Metrics, took, results.Agents, results.Rates.MetricRate)
+		if t.Rows > 0 {
+			printFn("loaded %d rows in %0.3fsec with %d agents (mean rate %0.2f rows/sec)\n", t.Rows, took, results.Agents, results.Rates.RowRate)
+		}
+	} else {
+		printFn("ran %d queries in %0.3fsec with %d agents (mean rate %0.2f queries/sec), %d failed\n", t.Queries, took, results.Agents, results.Rates.QueryRate, t.Errors)
+	}
+	labels := make([]string, 0, len(results.Latencies))
+	for label := range results.Latencies {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	for _, label := range labels {
+		s := results.Latencies[label]
+		printFn("%s latency: count %d, min %0.2fms, p50 %0.2fms, p90 %0.2fms, p99 %0.2fms, p99.9 %0.2fms, max %0.2fms\n", label, s.Count, s.Min, s.P50, s.P90, s.P99, s.P999, s.Max)
+	}
+}
+
+func (c *Coordinator) saveResults(results *Results) error {
+	printFn("Saving results json file to %s\n", c.ResultsFile)
+	file, err := json.MarshalIndent(results, "", " ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(c.ResultsFile, file, 0644)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
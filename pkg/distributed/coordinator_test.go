@@ -0,0 +1,244 @@
+package distributed
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/HdrHistogram/hdrhistogram-go"
+)
+
+func encodedHistogram(t *testing.T, values ...int64) string {
+	h := hdrhistogram.New(1, 3600000000, 3)
+	for _, v := range values {
+		h.RecordValue(v)
+	}
+	encoded, err := h.Encode(hdrhistogram.V2CompressedEncodingCookieBase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(encoded)
+}
+
+func TestNewCoordinator(t *testing.T) {
+	if _, err := NewCoordinator(CoordinatorConfig{Agents: 0}); err == nil {
+		t.Errorf("expected error for no agents")
+	}
+	if _, err := NewCoordinator(CoordinatorConfig{Agents: 2, Files: []string{"a.dat"}}); err == nil {
+		t.Errorf("expected error for fewer files than agents")
+	}
+}
+
+func TestCoordinatorAgents(t *testing.T) {
+	files := []string{"a.dat", "b.dat"}
+	c, err := NewCoordinator(CoordinatorConfig{Agents: 2, Files: files})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(c.Handler())
+	defer srv.Close()
+
+	// the agents are numbered in the order of their ids, whatever the order
+	// they join in
+	ids := []string{"agent-b", "agent-a"}
+	// agent i loads 1000*(i+1) metrics from 1000*i to 1000*(i+2) ms
+	var wg sync.WaitGroup
+	agents := make([]*Agent, 2)
+	errs := make([]error, 2)
+	for i := range agents {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			a, err := Join(srv.URL, KindLoad, ids[i])
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			agents[i] = a
+			if err := a.Barrier(BarrierStart); err != nil {
+				errs[i] = err
+				return
+			}
+			errs[i] = a.Report(&Report{
+				StartTime: int64(1000 * a.Agent),
+				EndTime:   int64(1000 * (a.Agent + 2)),
+				Counters:  Counters{Metrics: uint64(1000 * (a.Agent + 1))},
+				Partial:   a.Agent == 1,
+				Latencies: map[string]string{"batch insert": encodedHistogram(t, int64(1000*(a.Agent+1)))},
+			})
+		}(i)
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("agent %d: %v", i, err)
+		}
+	}
+	for i, a := range agents {
+		want := Assignment{
+			Agent:                1 - i,
+			Agents:               2,
+			ID:                   ids[i],
+			InterleavedGroupID:   uint(1 - i),
+			InterleavedNumGroups: 2,
+			File:                 files[1-i],
+		}
+		if a.Assignment != want {
+			t.Errorf("incorrect assignment of agent %s: got %+v want %+v", ids[i], a.Assignment, want)
+		}
+	}
+	select {
+	case <-c.done:
+	default:
+		t.Fatalf("coordinator not done after all agents reported")
+	}
+
+	results, err := c.results()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results.Kind != KindLoad || results.Agents != 2 {
+		t.Errorf("incorrect kind or agents: %s %d", results.Kind, results.Agents)
+	}
+	if results.StartTime != 0 || results.EndTime != 3000 || results.DurationMillis != 3000 {
+		t.Errorf("incorrect times: %d-%d (%dms)", results.StartTime, results.EndTime, results.DurationMillis)
+	}
+	if results.Totals.Metrics != 3000 || results.Rates.MetricRate != 1000 {
+		t.Errorf("incorrect totals: %+v %+v", results.Totals, results.Rates)
+	}
+	if !results.Partial {
+		t.Errorf("results not partial with a partial agent")
+	}
+	latencies := results.Latencies["batch insert"]
+	if latencies == nil || latencies.Count != 2 || latencies.Min != 1 || latencies.Max != 2 {
+		t.Errorf("incorrect merged latencies: %+v", latencies)
+	}
+
+	// all agents joined already
+	if _, err := Join(srv.URL, KindLoad, "agent-c"); err == nil {
+		t.Errorf("expected error for an agent too many")
+	}
+}
+
+func TestCoordinatorKindMismatch(t *testing.T) {
+	c, err := NewCoordinator(CoordinatorConfig{Agents: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(c.Handler())
+	defer srv.Close()
+	// the first agent waits for the second one until its connection is closed
+	defer srv.CloseClientConnections()
+
+	go Join(srv.URL, KindLoad, "agent-a")
+	// wait for the first agent to be registered
+	for {
+		c.mu.Lock()
+		n := len(c.agents)
+		c.mu.Unlock()
+		if n == 1 {
+			break
+		}
+	}
+	if _, err := Join(srv.URL, KindQueries, "agent-b"); err == nil {
+		t.Errorf("expected error for an agent of another kind")
+	}
+	if _, err := Join(srv.URL, KindLoad, "agent-a"); err == nil {
+		t.Errorf("expected error for an agent with the id of another one")
+	}
+}
+
+func TestCoordinatorBarrierTimeout(t *testing.T) {
+	oldPrintFn := printFn
+	defer func() { printFn = oldPrintFn }()
+	printFn = func(string, ...interface{}) (int, error) { return 0, nil }
+
+	c, err := NewCoordinator(CoordinatorConfig{Agents: 2, PhaseTimeout: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(c.Handler())
+	defer srv.Close()
+
+	// only one of the agents reaches the barrier
+	a := &Agent{Assignment: Assignment{Agent: 0, Agents: 2}, url: srv.URL, client: &http.Client{}}
+	err = a.Barrier(BarrierCreated)
+	if _, ok := err.(*AbortedError); !ok {
+		t.Fatalf("expected the benchmark to be aborted, got %v", err)
+	}
+	if err := c.abortError(); !strings.Contains(err.Error(), "barrier 'created'") {
+		t.Errorf("incorrect abort reason: %v", err)
+	}
+	// the other agents are told about the abort
+	if _, err := Join(srv.URL, KindLoad, "agent-b"); err == nil {
+		t.Errorf("expected an error joining an aborted benchmark")
+	}
+}
+
+func TestCoordinatorDeadAgent(t *testing.T) {
+	oldPrintFn := printFn
+	defer func() { printFn = oldPrintFn }()
+	printFn = func(string, ...interface{}) (int, error) { return 0, nil }
+
+	c, err := NewCoordinator(CoordinatorConfig{Agents: 2, ProgressPeriod: 10 * time.Millisecond, AgentTimeout: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.names = []string{"a", "b"}
+	start := time.Now()
+	c.lastSeen = []time.Time{start, start}
+	c.reports[0] = &Report{}
+	if reason := c.deadAgent(start.Add(40 * time.Millisecond)); reason != "" {
+		t.Errorf("agent considered dead before the agent timeout: %s", reason)
+	}
+	// agent 0 reported its results, so it is no longer expected to send progress
+	if reason := c.deadAgent(start.Add(time.Second)); !strings.Contains(reason, "agent 1 (b)") {
+		t.Errorf("incorrect dead agent: %s", reason)
+	}
+
+	stop := c.monitorAgents()
+	defer stop()
+	select {
+	case <-c.aborted:
+	case <-time.After(time.Second):
+		t.Fatalf("benchmark not aborted for an agent without progress")
+	}
+}
+
+func TestAgentReportProgressAborted(t *testing.T) {
+	oldPrintFn := printFn
+	defer func() { printFn = oldPrintFn }()
+	printFn = func(string, ...interface{}) (int, error) { return 0, nil }
+
+	c, err := NewCoordinator(CoordinatorConfig{Agents: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(c.Handler())
+	defer srv.Close()
+	c.abort("agent 1 failed")
+
+	a := &Agent{Assignment: Assignment{Agent: 0, Agents: 1, ProgressPeriod: 10 * time.Millisecond}, url: srv.URL, client: &http.Client{}}
+	aborted := make(chan string, 1)
+	stop := a.ReportProgress(func() Counters { return Counters{} }, func(reason string) {
+		aborted <- reason
+	})
+	defer stop()
+	select {
+	case reason := <-aborted:
+		if reason != "agent 1 failed" {
+			t.Errorf("incorrect abort reason: %s", reason)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("agent not told about the abort")
+	}
+}
+
+func TestNewCoordinatorAgentTimeout(t *testing.T) {
+	if _, err := NewCoordinator(CoordinatorConfig{Agents: 1, ProgressPeriod: time.Minute, AgentTimeout: time.Minute}); err == nil {
+		t.Errorf("expected error for an agent timeout shorter than two progress periods")
+	}
+}
@@ -0,0 +1,164 @@
+// Package distributed runs a benchmark on several client machines at once.
+// A coordinator waits for a number of agents, tsbs load or query runners
+// started with the address of the coordinator, assigns each of them a
+// shard of the data or queries, starts them at the same time, gathers
+// their progress and writes one results file for all of them.
+//
+// Agents talk to the coordinator with JSON over plain HTTP:
+//
+//	POST /register        agent joins with a unique ID, returns its Assignment once all agents joined
+//	POST /barrier/<name>  returns once all agents reached the barrier
+//	POST /progress        periodic Progress of an agent
+//	POST /report          final Report of an agent
+//
+// When an agent fails or times out, the coordinator aborts the benchmark and
+// answers all requests with 410 Gone and the reason, so the other agents
+// stop as well.
+package distributed
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	// KindLoad is the kind of agents loading data
+	KindLoad = "load"
+	// KindQueries is the kind of agents running queries
+	KindQueries = "queries"
+
+	// BarrierCreated is reached by the first agent once it created the
+	// database, and by the other agents before they use it
+	BarrierCreated = "created"
+	// BarrierStart is reached by the agents when they are ready to start
+	// the benchmark, so they all start at the same time
+	BarrierStart = "start"
+
+	// DefaultProgressPeriod is the default period of the progress reports
+	DefaultProgressPeriod = 10 * time.Second
+
+	// ResultsFormatVersion is the version of the format of Results
+	ResultsFormatVersion = "0.1"
+)
+
+// registration is sent by an agent to join the coordinator.
+type registration struct {
+	Name string `json:"name"`
+	ID   string `json:"id"`
+	Kind string `json:"kind"`
+}
+
+// barrierArrival is sent by an agent when it reaches a barrier.
+type barrierArrival struct {
+	Agent int `json:"agent"`
+}
+
+// Assignment tells an agent which shard of the data or queries is its own.
+// Agents are numbered in the order of their IDs, so an agent restarted
+// with the same ID gets the same shard. A loader simulating the data
+// generates the points of its interleaved group, and a loader reading a
+// file reads File, if the coordinator was given one file per agent, or else
+// its own file. A query runner runs, of every Agents queries, the one at
+// position Agent.
+type Assignment struct {
+	Agent                int           `json:"agent"`
+	Agents               int           `json:"agents"`
+	ID                   string        `json:"id"`
+	InterleavedGroupID   uint          `json:"interleavedGroupID"`
+	InterleavedNumGroups uint          `json:"interleavedNumGroups"`
+	File                 string        `json:"file,omitempty"`
+	ProgressPeriod       time.Duration `json:"progressPeriod"`
+}
+
+// Owns returns whether the i-th query, counting from 0, belongs to the
+// shard of the agent.
+func (a Assignment) Owns(i uint64) bool {
+	return i%uint64(a.Agents) == uint64(a.Agent)
+}
+
+// Share returns how many of the first n points or queries belong to the
+// shard of the agent, with 0 meaning all of them.
+func (a Assignment) Share(n uint64) uint64 {
+	if n == 0 {
+		return 0
+	}
+	share := n / uint64(a.Agents)
+	if uint64(a.Agent) < n%uint64(a.Agents) {
+		share++
+	}
+	return share
+}
+
+// Counters are the cumulative counts of an agent.
+type Counters struct {
+	Metrics uint64 `json:"metrics,omitempty"`
+	Rows    uint64 `json:"rows,omitempty"`
+	Queries uint64 `json:"queries,omitempty"`
+	Errors  uint64 `json:"errors,omitempty"`
+}
+
+func (c *Counters) add(o Counters) {
+	c.Metrics += o.Metrics
+	c.Rows += o.Rows
+	c.Queries += o.Queries
+	c.Errors += o.Errors
+}
+
+// Progress is sent by an agent every progress period.
+type Progress struct {
+	Agent    int      `json:"agent"`
+	Counters Counters `json:"counters"`
+}
+
+// Report is sent by an agent when it finished. Latencies holds the base64
+// encoded, compressed HDR histograms of its latencies in microseconds, by
+// label, which are merged with those of the other agents. Result is the
+// results json of the agent.
+type Report struct {
+	Agent     int               `json:"Agent"`
+	Name      string            `json:"Name"`
+	StartTime int64             `json:"StartTime"`
+	EndTime   int64             `json:"EndTime"`
+	Counters  Counters          `json:"Counters"`
+	Partial   bool              `json:"Partial,omitempty"`
+	Latencies map[string]string `json:"Latencies,omitempty"`
+	Result    json.RawMessage   `json:"Result,omitempty"`
+}
+
+// LatencyStats summarizes the latencies of all agents for a label. All
+// latencies are in milliseconds.
+type LatencyStats struct {
+	Count  int64   `json:"count"`
+	Min    float64 `json:"min"`
+	Mean   float64 `json:"mean"`
+	Max    float64 `json:"max"`
+	StdDev float64 `json:"stddev"`
+	P50    float64 `json:"p50"`
+	P90    float64 `json:"p90"`
+	P95    float64 `json:"p95"`
+	P99    float64 `json:"p99"`
+	P999   float64 `json:"p999"`
+}
+
+// Rates are the mean rates of all agents together, per second.
+type Rates struct {
+	MetricRate float64 `json:"metricRate,omitempty"`
+	RowRate    float64 `json:"rowRate,omitempty"`
+	QueryRate  float64 `json:"queryRate,omitempty"`
+}
+
+// Results aggregates the reports of all agents. StartTime and EndTime are
+// in milliseconds since the epoch.
+type Results struct {
+	ResultFormatVersion string                   `json:"ResultFormatVersion"`
+	Kind                string                   `json:"Kind"`
+	Agents              int                      `json:"Agents"`
+	StartTime           int64                    `json:"StartTime"`
+	EndTime             int64                    `json:"EndTime"`
+	DurationMillis      int64                    `json:"DurationMillis"`
+	Totals              Counters                 `json:"Totals"`
+	Rates               Rates                    `json:"Rates"`
+	Partial             bool                     `json:"Partial,omitempty"`
+	Latencies           map[string]*LatencyStats `json:"Latencies,omitempty"`
+	AgentReports        []*Report                `json:"AgentReports"`
+}
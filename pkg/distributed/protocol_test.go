@@ -0,0 +1,36 @@
+package distributed
+
+import "testing"
+
+func TestAssignmentShare(t *testing.T) {
+	cases := []struct {
+		agent, agents int
+		n, want       uint64
+	}{
+		{agent: 0, agents: 1, n: 10, want: 10},
+		{agent: 0, agents: 3, n: 10, want: 4},
+		{agent: 1, agents: 3, n: 10, want: 3},
+		{agent: 2, agents: 3, n: 10, want: 3},
+		{agent: 2, agents: 3, n: 0, want: 0},
+		{agent: 1, agents: 4, n: 1, want: 0},
+	}
+	for _, c := range cases {
+		a := Assignment{Agent: c.agent, Agents: c.agents}
+		if got := a.Share(c.n); got != c.want {
+			t.Errorf("agent %d of %d: incorrect share of %d: got %d want %d", c.agent, c.agents, c.n, got, c.want)
+		}
+		// the share is the number of owned positions
+		if c.n == 0 {
+			continue
+		}
+		owned := uint64(0)
+		for i := uint64(0); i < c.n; i++ {
+			if a.Owns(i) {
+				owned++
+			}
+		}
+		if owned != c.want {
+			t.Errorf("agent %d of %d: owns %d of %d, share is %d", c.agent, c.agents, owned, c.n, c.want)
+		}
+	}
+}
@@ -0,0 +1,275 @@
+// Package mixed runs a load benchmark and a query benchmark concurrently
+// against the same target, to measure query latencies under write load.
+package mixed
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/bodhiye/tsbs/load"
+	"github.com/bodhiye/tsbs/pkg/query"
+	"github.com/bodhiye/tsbs/pkg/targets"
+)
+
+const MixedTestResultVersion = "0.1"
+
+// RunnerConfig is the configuration of the mixed workload runner. The load
+// and query sides are configured with their own runner configs, including
+// their rate limits (insert-intervals and max-rps).
+type RunnerConfig struct {
+	// QueryStartDelay is how long to wait after the load has started before
+	// starting the queries, e.g. to let ingest reach a steady state
+	QueryStartDelay time.Duration `yaml:"query-start-delay" mapstructure:"query-start-delay"`
+	// BaselineResults is a results file written by tsbs_run_queries_* with
+	// --results-file, run without write load, to compare query latencies with
+	BaselineResults string `yaml:"baseline-results" mapstructure:"baseline-results"`
+	// ResultsFile is where the combined results are written to
+	ResultsFile string `yaml:"results-file" mapstructure:"results-file"`
+}
+
+// TestResult combines the results of the load and query benchmarks of a
+// mixed workload run.
+type TestResult struct {
+	ResultFormatVersion string       `json:"ResultFormatVersion"`
+	RunnerConfig        RunnerConfig `json:"RunnerConfig"`
+
+	StartTime      int64 `json:"StartTime"`
+	EndTime        int64 `json:"EndTime"`
+	DurationMillis int64 `json:"DurationMillis"`
+	// OverlapMillis is for how long queries ran while data was being loaded
+	OverlapMillis int64 `json:"OverlapMillis"`
+	// QueriesOutlastedLoad is set if queries were still running when the load
+	// finished, in which case part of the query latencies were not measured
+	// under write load
+	QueriesOutlastedLoad bool `json:"QueriesOutlastedLoad"`
+
+	Load    *load.LoaderTestResult  `json:"Load"`
+	Queries *query.LoaderTestResult `json:"Queries"`
+
+	// Degradation compares the query latencies per label with the baseline
+	// results, if given
+	Degradation map[string]*LatencyDegradation `json:"Degradation,omitempty"`
+}
+
+// LatencyDegradation compares the latencies of a query label under write
+// load with its latencies in the baseline run. Ratios above 1 mean queries
+// got slower under write load.
+type LatencyDegradation struct {
+	BaselineMean float64 `json:"baselineMean"`
+	Mean         float64 `json:"mean"`
+	MeanRatio    float64 `json:"meanRatio"`
+	BaselineP50  float64 `json:"baselineP50"`
+	P50          float64 `json:"p50"`
+	P50Ratio     float64 `json:"p50Ratio"`
+	BaselineP99  float64 `json:"baselineP99"`
+	P99          float64 `json:"p99"`
+	P99Ratio     float64 `json:"p99Ratio"`
+}
+
+// Runner runs a load benchmark and a query benchmark concurrently.
+type Runner struct {
+	RunnerConfig
+	loader  load.BenchmarkRunner
+	queries *query.BenchmarkRunner
+	result  *TestResult
+}
+
+// NewRunner creates a Runner from already configured load and query runners.
+func NewRunner(config RunnerConfig, loader load.BenchmarkRunner, queries *query.BenchmarkRunner) *Runner {
+	return &Runner{
+		RunnerConfig: config,
+		loader:       loader,
+		queries:      queries,
+	}
+}
+
+// Run loads the data of the benchmark while running the queries of the
+// query pool with processors created by processorCreateFn. Queries start
+// once the loader has created the database and started loading, after the
+// configured delay. It returns the error of the query benchmark, if it was
+// aborted, once the combined results are reported.
+func (r *Runner) Run(b targets.Benchmark, queryPool *sync.Pool, processorCreateFn query.ProcessorCreate) error {
+	var baseline *query.LoaderTestResult
+	if len(r.BaselineResults) > 0 {
+		var err error
+		baseline, err = readBaselineResults(r.BaselineResults)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	loadStarted := make(chan struct{})
+	loadDone := make(chan struct{})
+	bench := &startNotifyingBenchmark{Benchmark: b, started: loadStarted}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	start := time.Now()
+	var loadEnd, queriesStart, queriesEnd time.Time
+	var queriesErr error
+	go func() {
+		defer wg.Done()
+		defer close(loadDone)
+		r.loader.RunBenchmark(bench)
+		loadEnd = time.Now()
+	}()
+	go func() {
+		defer wg.Done()
+		// the load can return without ever loading, e.g. when it is stopped
+		// before the database is created, in which case no queries are run
+		select {
+		case <-loadStarted:
+		case <-loadDone:
+			fmt.Println("load finished before loading any data, not running the queries")
+			return
+		}
+		time.Sleep(r.QueryStartDelay)
+		queriesStart = time.Now()
+		queriesErr = r.queries.Run(queryPool, processorCreateFn)
+		queriesEnd = time.Now()
+	}()
+	wg.Wait()
+	end := time.Now()
+
+	r.result = &TestResult{
+		ResultFormatVersion:  MixedTestResultVersion,
+		RunnerConfig:         r.RunnerConfig,
+		StartTime:            start.Unix(),
+		EndTime:              end.Unix(),
+		DurationMillis:       end.Sub(start).Milliseconds(),
+		OverlapMillis:        overlap(queriesStart, queriesEnd, loadEnd).Milliseconds(),
+		QueriesOutlastedLoad: queriesEnd.After(loadEnd),
+		Load:                 r.loader.Result(),
+		Queries:              r.queries.Result(),
+	}
+	if baseline != nil {
+		r.result.Degradation = latencyDegradation(baseline, r.result.Queries)
+	}
+
+	if r.result.QueriesOutlastedLoad {
+		fmt.Printf("warning: queries were still running %0.3fsec after the load finished, "+
+			"their latencies were not all measured under write load\n", queriesEnd.Sub(loadEnd).Seconds())
+	}
+	r.summary()
+	if len(r.ResultsFile) > 0 {
+		r.saveTestResult()
+	}
+	return queriesErr
+}
+
+// Result returns the combined results of the run, or nil if it has not
+// finished running.
+func (r *Runner) Result() *TestResult {
+	return r.result
+}
+
+// overlap returns for how long the queries ran before the load finished.
+func overlap(queriesStart, queriesEnd, loadEnd time.Time) time.Duration {
+	end := queriesEnd
+	if loadEnd.Before(end) {
+		end = loadEnd
+	}
+	if end.Before(queriesStart) {
+		return 0
+	}
+	return end.Sub(queriesStart)
+}
+
+func readBaselineResults(fileName string) (*query.LoaderTestResult, error) {
+	contents, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read baseline results file %s: %v", fileName, err)
+	}
+	var baseline query.LoaderTestResult
+	if err := json.Unmarshal(contents, &baseline); err != nil {
+		return nil, fmt.Errorf("cannot decode baseline results file %s: %v", fileName, err)
+	}
+	if baseline.Latencies == nil {
+		return nil, fmt.Errorf("baseline results file %s has no latencies", fileName)
+	}
+	return &baseline, nil
+}
+
+// latencyDegradation compares the query latencies of each label present in
+// both results.
+func latencyDegradation(baseline, underLoad *query.LoaderTestResult) map[string]*LatencyDegradation {
+	if underLoad == nil || underLoad.Latencies == nil {
+		return nil
+	}
+	degradation := make(map[string]*LatencyDegradation)
+	for label, l := range underLoad.Latencies.All {
+		b, ok := baseline.Latencies.All[label]
+		if !ok {
+			continue
+		}
+		degradation[label] = &LatencyDegradation{
+			BaselineMean: b.Mean,
+			Mean:         l.Mean,
+			MeanRatio:    ratio(l.Mean, b.Mean),
+			BaselineP50:  b.P50,
+			P50:          l.P50,
+			P50Ratio:     ratio(l.P50, b.P50),
+			BaselineP99:  b.P99,
+			P99:          l.P99,
+			P99Ratio:     ratio(l.P99, b.P99),
+		}
+	}
+	return degradation
+}
+
+func ratio(value, baseline float64) float64 {
+	if baseline == 0 {
+		return 0
+	}
+	return value / baseline
+}
+
+// summary prints the latency degradation of each query label.
+func (r *Runner) summary() {
+	if len(r.result.Degradation) == 0 {
+		return
+	}
+	labels := make([]string, 0, len(r.result.Degradation))
+	for label := range r.result.Degradation {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+
+	fmt.Printf("\nQuery latency under write load compared to baseline:\n")
+	for _, label := range labels {
+		d := r.result.Degradation[label]
+		fmt.Printf("%s:\nmean: %0.2fms (x%0.2f), p50: %0.2fms (x%0.2f), p99: %0.2fms (x%0.2f)\n",
+			label, d.Mean, d.MeanRatio, d.P50, d.P50Ratio, d.P99, d.P99Ratio)
+	}
+}
+
+func (r *Runner) saveTestResult() {
+	_, _ = fmt.Printf("Saving results json file to %s\n", r.ResultsFile)
+	file, err := json.MarshalIndent(r.result, "", " ")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = ioutil.WriteFile(r.ResultsFile, file, 0644)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// startNotifyingBenchmark closes started when the loader asks for the data
+// source, which happens after the database has been created.
+type startNotifyingBenchmark struct {
+	targets.Benchmark
+	started chan struct{}
+	once    sync.Once
+}
+
+func (b *startNotifyingBenchmark) GetDataSource() targets.DataSource {
+	b.once.Do(func() { close(b.started) })
+	return b.Benchmark.GetDataSource()
+}
@@ -0,0 +1,183 @@
+package mixed
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bodhiye/tsbs/load"
+	"github.com/bodhiye/tsbs/pkg/data"
+	"github.com/bodhiye/tsbs/pkg/data/usecases/common"
+	"github.com/bodhiye/tsbs/pkg/query"
+	"github.com/bodhiye/tsbs/pkg/targets"
+)
+
+type testDataSource struct{}
+
+func (d *testDataSource) NextItem() data.LoadedPoint            { return data.LoadedPoint{} }
+func (d *testDataSource) Headers() *common.GeneratedDataHeaders { return nil }
+
+type testBenchmark struct {
+	targets.Benchmark
+}
+
+func (b *testBenchmark) GetDataSource() targets.DataSource {
+	return &testDataSource{}
+}
+
+// testLoader asks for the data source, like the load runners do after
+// creating the database, and then keeps loading for a while.
+type testLoader struct {
+	loadFor    time.Duration
+	dataSource time.Time
+	// stopEarly makes the loader return without asking for the data source
+	stopEarly bool
+}
+
+func (l *testLoader) DatabaseName() string { return "benchmark" }
+func (l *testLoader) RunBenchmark(b targets.Benchmark) {
+	time.Sleep(10 * time.Millisecond)
+	if l.stopEarly {
+		return
+	}
+	l.dataSource = time.Now()
+	b.GetDataSource()
+	time.Sleep(l.loadFor)
+}
+func (l *testLoader) Result() *load.LoaderTestResult {
+	return &load.LoaderTestResult{Totals: map[string]interface{}{"metricRate": 1.0}}
+}
+func (l *testLoader) Stop() {}
+
+type testQueryProcessor struct{}
+
+func (p *testQueryProcessor) Init(int) {}
+func (p *testQueryProcessor) ProcessQuery(query.Query, bool) ([]*query.Stat, error) {
+	return nil, nil
+}
+
+func TestRunnerRun(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mixed")
+	if err != nil {
+		t.Fatalf("could not create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	queriesFile := filepath.Join(dir, "queries")
+	if err := ioutil.WriteFile(queriesFile, nil, 0644); err != nil {
+		t.Fatalf("could not write queries file: %v", err)
+	}
+	resultsFile := filepath.Join(dir, "results.json")
+
+	loader := &testLoader{loadFor: 100 * time.Millisecond}
+	queries := query.NewBenchmarkRunner(query.BenchmarkRunnerConfig{Workers: 1, FileName: queriesFile})
+	r := NewRunner(RunnerConfig{QueryStartDelay: 20 * time.Millisecond, ResultsFile: resultsFile}, loader, queries)
+
+	var queriesStart time.Time
+	r.Run(&testBenchmark{}, &query.HTTPPool, func() query.Processor {
+		queriesStart = time.Now()
+		return &testQueryProcessor{}
+	})
+
+	if loader.dataSource.IsZero() {
+		t.Fatalf("loader did not ask for the data source")
+	}
+	if queries.Result() == nil {
+		t.Fatalf("queries were not run")
+	}
+	if got := queriesStart.Sub(loader.dataSource); got < r.QueryStartDelay {
+		t.Errorf("queries started too early: %v after the load started, want at least %v", got, r.QueryStartDelay)
+	}
+	res := r.Result()
+	if res.QueriesOutlastedLoad {
+		t.Errorf("queries should have finished before the load")
+	}
+	if res.Load == nil || res.Queries == nil {
+		t.Errorf("missing load or query results: %+v", res)
+	}
+
+	contents, err := ioutil.ReadFile(resultsFile)
+	if err != nil {
+		t.Fatalf("results file was not written: %v", err)
+	}
+	var saved TestResult
+	if err := json.Unmarshal(contents, &saved); err != nil {
+		t.Fatalf("could not decode results file: %v", err)
+	}
+	if saved.ResultFormatVersion != MixedTestResultVersion {
+		t.Errorf("incorrect results version: got %s want %s", saved.ResultFormatVersion, MixedTestResultVersion)
+	}
+}
+
+func TestRunnerRunLoadStoppedEarly(t *testing.T) {
+	loader := &testLoader{stopEarly: true}
+	queries := query.NewBenchmarkRunner(query.BenchmarkRunnerConfig{Workers: 1, FileName: "unused"})
+	r := NewRunner(RunnerConfig{}, loader, queries)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		r.Run(&testBenchmark{}, &query.HTTPPool, func() query.Processor {
+			return &testQueryProcessor{}
+		})
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("runner did not return after the load stopped early")
+	}
+
+	if queries.Result() != nil {
+		t.Errorf("queries should not have been run")
+	}
+	if res := r.Result(); res.QueriesOutlastedLoad || res.OverlapMillis != 0 {
+		t.Errorf("incorrect overlap for queries that did not run: %+v", res)
+	}
+}
+
+func TestLatencyDegradation(t *testing.T) {
+	baseline := &query.LoaderTestResult{Latencies: &query.LatencyResults{All: map[string]*query.LatencyStats{
+		"foo": {Mean: 10, P50: 8, P99: 20},
+		"bar": {Mean: 0, P50: 0, P99: 0},
+	}}}
+	underLoad := &query.LoaderTestResult{Latencies: &query.LatencyResults{All: map[string]*query.LatencyStats{
+		"foo": {Mean: 15, P50: 8, P99: 50},
+		"bar": {Mean: 1, P50: 1, P99: 1},
+		"baz": {Mean: 1, P50: 1, P99: 1},
+	}}}
+
+	got := latencyDegradation(baseline, underLoad)
+	if len(got) != 2 {
+		t.Fatalf("incorrect number of labels: got %d want %d", len(got), 2)
+	}
+	if d := got["foo"]; d.MeanRatio != 1.5 || d.P50Ratio != 1 || d.P99Ratio != 2.5 {
+		t.Errorf("incorrect degradation for foo: %+v", d)
+	}
+	if d := got["bar"]; d.MeanRatio != 0 || d.Mean != 1 {
+		t.Errorf("incorrect degradation for zero baseline: %+v", d)
+	}
+	if latencyDegradation(baseline, nil) != nil {
+		t.Errorf("degradation without results should be nil")
+	}
+}
+
+func TestOverlap(t *testing.T) {
+	base := time.Now()
+	cases := []struct {
+		desc                              string
+		queriesStart, queriesEnd, loadEnd time.Duration
+		want                              time.Duration
+	}{
+		{desc: "queries within load", queriesStart: 1, queriesEnd: 5, loadEnd: 10, want: 4},
+		{desc: "queries outlast load", queriesStart: 1, queriesEnd: 15, loadEnd: 10, want: 9},
+		{desc: "queries start after load", queriesStart: 11, queriesEnd: 15, loadEnd: 10, want: 0},
+	}
+	for _, c := range cases {
+		got := overlap(base.Add(c.queriesStart*time.Second), base.Add(c.queriesEnd*time.Second), base.Add(c.loadEnd*time.Second))
+		if got != c.want*time.Second {
+			t.Errorf("%s: incorrect overlap: got %v want %v", c.desc, got, c.want*time.Second)
+		}
+	}
+}
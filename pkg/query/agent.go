@@ -0,0 +1,80 @@
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/bodhiye/tsbs/pkg/distributed"
+)
+
+// joinCoordinator joins the coordinator set with --coordinator as an agent,
+// which runs its shard of the queries.
+func (b *BenchmarkRunner) joinCoordinator() {
+	agent, err := distributed.Join(b.Coordinator, distributed.KindQueries, b.AgentID)
+	if err != nil {
+		panic(fmt.Sprintf("could not join coordinator %s: %v", b.Coordinator, err))
+	}
+	fmt.Printf("joined coordinator %s as agent %d of %d\n", b.Coordinator, agent.Agent, agent.Agents)
+	b.agent = agent
+	b.scanner.assignment = &agent.Assignment
+}
+
+// waitForAgents waits for all agents to be ready to start, when running as
+// an agent.
+func (b *BenchmarkRunner) waitForAgents() {
+	if b.agent == nil {
+		return
+	}
+	if err := b.agent.Barrier(distributed.BarrierStart); err != nil {
+		panic(fmt.Sprintf("agent %d could not wait for the other agents: %v", b.agent.Agent, err))
+	}
+}
+
+// reportProgress sends the number of queries run and failed to the
+// coordinator periodically, until the returned function is called. The
+// benchmark is aborted when the coordinator aborted it.
+func (b *BenchmarkRunner) reportProgress() func() {
+	if b.agent == nil {
+		return func() {}
+	}
+	return b.agent.ReportProgress(func() distributed.Counters {
+		return distributed.Counters{
+			Queries: atomic.LoadUint64(&b.queryCount),
+			Errors:  atomic.LoadUint64(&b.errorCount),
+		}
+	}, func(reason string) {
+		b.abort(fmt.Sprintf("coordinator aborted the benchmark: %s", reason))
+	})
+}
+
+// reportResult sends the results of the agent to the coordinator.
+func (b *BenchmarkRunner) reportResult(result *LoaderTestResult, start, end time.Time) {
+	if b.agent == nil {
+		return
+	}
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		panic(fmt.Sprintf("could not encode results: %v", err))
+	}
+	report := &distributed.Report{
+		StartTime: start.UnixMilli(),
+		EndTime:   end.UnixMilli(),
+		Counters: distributed.Counters{
+			Queries: atomic.LoadUint64(&b.queryCount),
+			Errors:  atomic.LoadUint64(&b.errorCount),
+		},
+		Partial: result.Partial,
+		Result:  encoded,
+	}
+	if result.Latencies != nil {
+		report.Latencies = make(map[string]string, len(result.Latencies.All))
+		for label, s := range result.Latencies.All {
+			report.Latencies[label] = s.Histogram
+		}
+	}
+	if err := b.agent.Report(report); err != nil {
+		panic(fmt.Sprintf("could not report results to coordinator %s: %v", b.Coordinator, err))
+	}
+}
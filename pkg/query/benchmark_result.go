@@ -11,10 +11,42 @@ type LoaderTestResult struct {
 	RunnerConfig BenchmarkRunnerConfig `json:"RunnerConfig"`
 
 	// Run info
-	StartTime      int64 `json:"StartTime`
+	StartTime      int64 `json:"StartTime"`
 	EndTime        int64 `json:"EndTime"`
 	DurationMillis int64 `json:"DurationMillis"`
 
 	// Totals
 	Totals map[string]interface{} `json:"Totals"`
+
+	// Errors holds the number of failed and timed out queries per query
+	// label, when any query failed
+	Errors map[string]*ErrorStats `json:"Errors,omitempty"`
+
+	// AbortReason is set when the benchmark was aborted because of failed
+	// queries or a signal
+	AbortReason string `json:"AbortReason,omitempty"`
+
+	// Partial is set when the benchmark was aborted before all queries were
+	// run
+	Partial bool `json:"Partial,omitempty"`
+
+	// Latencies holds the latency summaries per query label
+	Latencies *LatencyResults `json:"Latencies"`
+
+	// Verification holds the result verification stats per query label,
+	// when run with --verify
+	Verification map[string]*VerificationStats `json:"Verification,omitempty"`
+
+	// OpenLoop holds the arrival rate, queue depth and queue delay of the
+	// queries, when run open-loop with --arrival-rate
+	OpenLoop *OpenLoopStats `json:"OpenLoop,omitempty"`
+}
+
+// LatencyResults holds the latency summaries of a benchmark, keyed by query
+// label. Cold and Warm split the summaries by cold and warm runs, and are
+// only set when prewarming queries.
+type LatencyResults struct {
+	All  map[string]*LatencyStats `json:"All"`
+	Cold map[string]*LatencyStats `json:"Cold,omitempty"`
+	Warm map[string]*LatencyStats `json:"Warm,omitempty"`
 }
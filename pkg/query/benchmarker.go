@@ -3,14 +3,17 @@ package query
 import (
 	"bufio"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"log"
 	"os"
 	"runtime/pprof"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/bodhiye/tsbs/pkg/distributed"
 	"github.com/spf13/pflag"
 	"golang.org/x/time/rate"
 )
@@ -25,46 +28,86 @@ const (
 
 // BenchmarkRunnerConfig is the configuration of the benchmark runner.
 type BenchmarkRunnerConfig struct {
-	DBName           string `mapstructure:"db-name"`
-	Limit            uint64 `mapstructure:"max-queries"`
-	LimitRPS         uint64 `mapstructure:"max-rps"`
-	MemProfile       string `mapstructure:"memprofile"`
-	HDRLatenciesFile string `mapstructure:"hdr-latencies"`
-	Workers          uint   `mapstructure:"workers"`
-	PrintResponses   bool   `mapstructure:"print-responses"`
-	Debug            int    `mapstructure:"debug"`
-	FileName         string `mapstructure:"file"`
-	BurnIn           uint64 `mapstructure:"burn-in"`
-	PrintInterval    uint64 `mapstructure:"print-interval"`
-	PrewarmQueries   bool   `mapstructure:"prewarm-queries"`
-	ResultsFile      string `mapstructure:"results-file"`
+	DBName              string        `mapstructure:"db-name"`
+	Limit               uint64        `mapstructure:"max-queries"`
+	LimitRPS            uint64        `mapstructure:"max-rps"`
+	MemProfile          string        `mapstructure:"memprofile"`
+	HDRLatenciesFile    string        `mapstructure:"hdr-latencies"`
+	Workers             uint          `mapstructure:"workers"`
+	PrintResponses      bool          `mapstructure:"print-responses"`
+	Debug               int           `mapstructure:"debug"`
+	FileName            string        `mapstructure:"file"`
+	BurnIn              uint64        `mapstructure:"burn-in"`
+	PrintInterval       uint64        `mapstructure:"print-interval"`
+	PrewarmQueries      bool          `mapstructure:"prewarm-queries"`
+	ResultsFile         string        `mapstructure:"results-file"`
+	VerifyFile          string        `mapstructure:"verify"`
+	RecordResults       string        `mapstructure:"record-results"`
+	VerifyTolerance     float64       `mapstructure:"verify-tolerance"`
+	ArrivalRate         float64       `mapstructure:"arrival-rate"`
+	ArrivalDistribution string        `mapstructure:"arrival-distribution"`
+	Seed                int64         `mapstructure:"seed"`
+	ErrorPolicy         string        `mapstructure:"error-policy"`
+	MaxErrors           uint64        `mapstructure:"max-errors"`
+	QueryTimeout        time.Duration `mapstructure:"query-timeout"`
+	Coordinator         string        `mapstructure:"coordinator"`
+	AgentID             string        `mapstructure:"agent-id"`
 }
 
 // AddToFlagSet adds command line flags needed by the BenchmarkRunnerConfig to the flag set.
 func (c BenchmarkRunnerConfig) AddToFlagSet(fs *pflag.FlagSet) {
-	fs.String("db-name", "benchmark", "Name of database to use for queries")
-	fs.Uint64("burn-in", 0, "Number of queries to ignore before collecting statistics.")
-	fs.Uint64("max-queries", 0, "Limit the number of queries to send, 0 = no limit")
-	fs.Uint64("max-rps", 0, "Limit the rate of queries per second, 0 = no limit")
-	fs.Uint64("print-interval", 100, "Print timing stats to stderr after this many queries (0 to disable)")
-	fs.String("memprofile", "", "Write a memory profile to this file.")
-	fs.String("hdr-latencies", "", "Write the High Dynamic Range (HDR) Histogram of Response Latencies to this file.")
-	fs.Uint("workers", 1, "Number of concurrent requests to make.")
-	fs.Bool("prewarm-queries", false, "Run each query twice in a row so the warm query is guaranteed to be a cache hit")
-	fs.Bool("print-responses", false, "Pretty print response bodies for correctness checking (default false).")
-	fs.Int("debug", 0, "Whether to print debug messages.")
-	fs.String("file", "", "File name to read queries from")
-	fs.String("results-file", "", "Write the test results summary json to this file")
+	c.AddToFlagSetWithPrefix("", fs)
+}
+
+// AddToFlagSetWithPrefix adds the flags of AddToFlagSet to the flag set,
+// with their names starting with prefix, e.g. "queries.runner.".
+func (c BenchmarkRunnerConfig) AddToFlagSetWithPrefix(prefix string, fs *pflag.FlagSet) {
+	fs.String(prefix+"db-name", "benchmark", "Name of database to use for queries")
+	fs.Uint64(prefix+"burn-in", 0, "Number of queries to ignore before collecting statistics.")
+	fs.Uint64(prefix+"max-queries", 0, "Limit the number of queries to send, 0 = no limit")
+	fs.Uint64(prefix+"max-rps", 0, "Limit the rate of queries per second, 0 = no limit")
+	fs.Uint64(prefix+"print-interval", 100, "Print timing stats to stderr after this many queries (0 to disable)")
+	fs.String(prefix+"memprofile", "", "Write a memory profile to this file.")
+	fs.String(prefix+"hdr-latencies", "", "Write the High Dynamic Range (HDR) Histogram of Response Latencies to this file.")
+	fs.Uint(prefix+"workers", 1, "Number of concurrent requests to make.")
+	fs.Bool(prefix+"prewarm-queries", false, "Run each query twice in a row so the warm query is guaranteed to be a cache hit")
+	fs.Bool(prefix+"print-responses", false, "Pretty print response bodies for correctness checking (default false).")
+	fs.Int(prefix+"debug", 0, "Whether to print debug messages.")
+	fs.String(prefix+"file", "", "File name to read queries from")
+	fs.String(prefix+"results-file", "", "Write the test results summary json to this file")
+	fs.String(prefix+"verify", "", "Verify query results against the reference results in this file, reporting mismatches per query type")
+	fs.String(prefix+"record-results", "", "Write the normalized query results to this file, to be used as reference with --verify")
+	fs.Float64(prefix+"verify-tolerance", defaultVerifyTolerance, "Relative tolerance used when comparing values with --verify")
+	fs.Float64(prefix+"arrival-rate", 0, "Send queries open-loop at this rate per second, regardless of when previous queries complete, measuring latency from the intended send time. 0 = closed-loop, each worker sends its next query when the previous one completes")
+	fs.String(prefix+"arrival-distribution", ArrivalConstant, "Distribution of the intervals between queries with --arrival-rate, 'constant' or 'poisson'")
+	fs.Int64(prefix+"seed", 0, "PRNG seed of the poisson intervals between queries with --arrival-rate (default: 0, which uses the current timestamp)")
+	fs.String(prefix+"error-policy", ErrorPolicyAbort, "What to do when a query fails or times out: 'abort' the benchmark, 'continue' counting failed queries, or 'abort-after' more than --max-errors queries failed")
+	fs.Uint64(prefix+"max-errors", 0, "Number of failed queries tolerated with --error-policy=abort-after")
+	fs.Duration(prefix+"query-timeout", 0, "Count queries that take longer than this as timed out and move on, 0 = no timeout")
+	fs.String(prefix+"coordinator", "", "Address (host:port) of a tsbs_coordinator to join as an agent, which runs its shard of the queries at the same time as the other agents")
+	fs.String(prefix+"agent-id", "", "ID of the agent, unique among the agents, which its shard depends on (default: the host name)")
 }
 
 // BenchmarkRunner contains the common components for running a query benchmarking
 // program against a database.
 type BenchmarkRunner struct {
 	BenchmarkRunnerConfig
-	br      *bufio.Reader
-	sp      statProcessor
-	scanner *scanner
-	ch      chan Query
+	br       *bufio.Reader
+	sp       statProcessor
+	scanner  *scanner
+	ch       chan Query
+	verifier *resultVerifier
+	openLoop *openLoop
+	agent    *distributed.Agent
+	result   *LoaderTestResult
+
+	processorCreate ProcessorCreate
+	errorCount      uint64
+	queryCount      uint64
+	// done is closed when the benchmark is aborted
+	done        chan struct{}
+	abortOnce   sync.Once
+	abortReason string
 }
 
 // NewBenchmarkRunner creates a new instance of BenchmarkRunner which is
@@ -116,6 +159,19 @@ type Processor interface {
 	ProcessQuery(q Query, isWarm bool) ([]*Stat, error)
 }
 
+// ProcessorCloser is a Processor that also needs to close or cleanup afterwards
+type ProcessorCloser interface {
+	Processor
+	// Close cleans up after a Processor
+	Close()
+}
+
+func closeProcessor(processor Processor) {
+	if c, ok := processor.(ProcessorCloser); ok {
+		c.Close()
+	}
+}
+
 // GetBufferedReader returns the buffered Reader that should be used by the loader
 func (b *BenchmarkRunner) GetBufferedReader() *bufio.Reader {
 	if b.br == nil {
@@ -134,19 +190,77 @@ func (b *BenchmarkRunner) GetBufferedReader() *bufio.Reader {
 	return b.br
 }
 
+// Validate checks the configuration of the benchmark runner against the query
+// processors created by processorCreateFn, so that an unsupported combination
+// of options fails before any query runs.
+func (b *BenchmarkRunner) Validate(processorCreateFn ProcessorCreate) error {
+	if b.Workers == 0 {
+		return errors.New("must have at least one worker")
+	}
+	if b.sp.getArgs().burnIn > b.Limit {
+		return errors.New("burn-in is larger than limit")
+	}
+	if b.ErrorPolicy == "" {
+		b.ErrorPolicy = ErrorPolicyAbort
+	}
+	if err := validateErrorPolicy(b.ErrorPolicy, b.MaxErrors); err != nil {
+		return err
+	}
+	if b.ArrivalRate > 0 && b.LimitRPS != 0 {
+		return errors.New("max-rps can not be used together with arrival-rate")
+	}
+	if len(b.VerifyFile) > 0 || len(b.RecordResults) > 0 {
+		if _, ok := processorCreateFn().(ResultProcessor); !ok {
+			return errors.New("query processor of this database does not support result verification, " +
+				"so verify and record-results can not be used")
+		}
+	}
+	return nil
+}
+
 // Run does the bulk of the benchmark execution.
 // It launches a gorountine to track stats, creates workers to process queries,
 // read in the input, execute the queries, and then does cleanup.
-func (b *BenchmarkRunner) Run(queryPool *sync.Pool, processorCreateFn ProcessorCreate) {
-	if b.Workers == 0 {
-		panic("must have at least one worker")
+// If the benchmark is aborted, e.g. by the error policy or a signal, Run
+// returns the reason as an error once the partial results are reported.
+func (b *BenchmarkRunner) Run(queryPool *sync.Pool, processorCreateFn ProcessorCreate) error {
+	if err := b.Validate(processorCreateFn); err != nil {
+		panic(err.Error())
 	}
+	b.ch = make(chan Query, b.Workers)
 
-	spArgs := b.sp.getArgs()
-	if spArgs.burnIn > b.Limit {
-		panic("burn-in is larger than limit")
+	b.processorCreate = processorCreateFn
+	if b.Coordinator != "" {
+		b.joinCoordinator()
+	}
+	b.done = make(chan struct{})
+	b.scanner.done = b.done
+	stopSignals := b.handleSignals()
+
+	if len(b.VerifyFile) > 0 || len(b.RecordResults) > 0 {
+		verifier, err := newResultVerifier(b.VerifyFile, b.RecordResults, b.VerifyTolerance)
+		if err != nil {
+			panic(fmt.Sprintf("could not initialize result verification: %v", err))
+		}
+		b.verifier = verifier
+	}
+
+	if b.ArrivalRate > 0 {
+		distribution := b.ArrivalDistribution
+		if distribution == "" {
+			distribution = ArrivalConstant
+		}
+		seed := b.Seed
+		if seed == 0 {
+			seed = time.Now().UnixNano()
+		}
+		openLoop, err := newOpenLoop(b.ArrivalRate, distribution, seed)
+		if err != nil {
+			panic(fmt.Sprintf("could not initialize open-loop query load: %v", err))
+		}
+		fmt.Printf("Open loop: %s arrivals at %0.2f queries/sec, using seed %d\n", distribution, b.ArrivalRate, seed)
+		b.openLoop = openLoop
 	}
-	b.ch = make(chan Query, b.Workers)
 
 	// Launch the stats processor:
 	go b.sp.process(b.Workers)
@@ -156,18 +270,30 @@ func (b *BenchmarkRunner) Run(queryPool *sync.Pool, processorCreateFn ProcessorC
 	// Launch query processors
 	var wg sync.WaitGroup
 	for i := 0; i < int(b.Workers); i++ {
+		processor := processorCreateFn()
 		wg.Add(1)
-		go b.processorHandler(&wg, rateLimiter, queryPool, processorCreateFn(), i)
+		if b.openLoop != nil {
+			go b.openLoopHandler(&wg, queryPool, processor, i)
+		} else {
+			go b.processorHandler(&wg, rateLimiter, queryPool, processor, i)
+		}
 	}
 
 	// Read in jobs, closing the job channel when done:
+	b.waitForAgents()
+	stopProgress := b.reportProgress()
 	// Wall clock start time
 	wallStart := time.Now()
+	if b.openLoop != nil {
+		go b.openLoop.schedule(b.ch)
+	}
 	b.scanner.setReader(b.GetBufferedReader()).scan(queryPool, b.ch)
 	close(b.ch)
 
 	// Block for workers to finish sending requests, closing the stats channel when done:
 	wg.Wait()
+	stopSignals()
+	stopProgress()
 	b.sp.CloseAndWait()
 
 	// Wall clock end time
@@ -178,6 +304,19 @@ func (b *BenchmarkRunner) Run(queryPool *sync.Pool, processorCreateFn ProcessorC
 		log.Fatal(err)
 	}
 
+	// (Optional) report the open-loop stats:
+	if b.openLoop != nil {
+		b.openLoop.summary(os.Stdout, wallTook)
+	}
+
+	// (Optional) report result verification:
+	if b.verifier != nil {
+		if err := b.verifier.close(); err != nil {
+			log.Fatal(err)
+		}
+		b.verifier.summary(os.Stdout)
+	}
+
 	// (Optional) create a memory profile:
 	if len(b.MemProfile) > 0 {
 		f, err := os.Create(b.MemProfile)
@@ -188,22 +327,56 @@ func (b *BenchmarkRunner) Run(queryPool *sync.Pool, processorCreateFn ProcessorC
 		f.Close()
 	}
 
+	b.result = b.testResult(wallTook, wallStart, wallEnd)
 	// (Optional) save the results file:
 	if len(b.BenchmarkRunnerConfig.ResultsFile) > 0 {
-		b.saveTestResult(wallTook, wallStart, wallEnd)
+		b.saveTestResult(b.result)
+	}
+	b.reportResult(b.result, wallStart, wallEnd)
+
+	if reason := b.getAbortReason(); len(reason) > 0 {
+		return fmt.Errorf("benchmark aborted: %s", reason)
 	}
+	return nil
+}
+
+// Result returns the results of the benchmark, or nil if it has not
+// finished running.
+func (b *BenchmarkRunner) Result() *LoaderTestResult {
+	return b.result
 }
 
-func (b *BenchmarkRunner) saveTestResult(took time.Duration, start time.Time, end time.Time) {
-	testResult := LoaderTestResult{
+func (b *BenchmarkRunner) testResult(took time.Duration, start time.Time, end time.Time) *LoaderTestResult {
+	testResult := &LoaderTestResult{
 		ResultFormatVersion: BenchmarkTestResultVersion,
 		RunnerConfig:        b.BenchmarkRunnerConfig,
 		StartTime:           start.UTC().Unix() * 1000,
 		EndTime:             end.UTC().Unix() * 1000,
 		DurationMillis:      took.Milliseconds(),
 		Totals:              b.sp.GetTotalsMap(),
+		Errors:              b.sp.GetErrors(),
+		AbortReason:         b.getAbortReason(),
+		Partial:             b.aborted(),
+	}
+	latencies, err := b.sp.GetLatencies()
+	if err != nil {
+		log.Fatal(err)
+	}
+	testResult.Latencies = latencies
+	if b.verifier != nil {
+		testResult.Verification = b.verifier.totals()
+	}
+	if b.openLoop != nil {
+		openLoopStats, err := b.openLoop.stats()
+		if err != nil {
+			log.Fatal(err)
+		}
+		testResult.OpenLoop = openLoopStats
 	}
+	return testResult
+}
 
+func (b *BenchmarkRunner) saveTestResult(testResult *LoaderTestResult) {
 	_, _ = fmt.Printf("Saving results json file to %s\n", b.BenchmarkRunnerConfig.ResultsFile)
 	file, err := json.MarshalIndent(testResult, "", " ")
 	if err != nil {
@@ -219,32 +392,103 @@ func (b *BenchmarkRunner) saveTestResult(took time.Duration, start time.Time, en
 func (b *BenchmarkRunner) processorHandler(wg *sync.WaitGroup, rateLimiter *rate.Limiter, queryPool *sync.Pool, processor Processor, workerNum int) {
 	processor.Init(workerNum)
 	for query := range b.ch {
+		if b.aborted() {
+			queryPool.Put(query)
+			continue
+		}
 		r := rateLimiter.Reserve()
 		time.Sleep(r.Delay())
+		if !b.handleQuery(processor, query, 0) {
+			processor = b.replaceProcessor(workerNum)
+			continue
+		}
+		queryPool.Put(query)
+	}
+	closeProcessor(processor)
+	wg.Done()
+}
 
-		stats, err := processor.ProcessQuery(query, false)
-		if err != nil {
-			panic(err)
+// openLoopHandler runs the queries released by the open-loop scheduler,
+// adding the time they waited for a free worker to their latency.
+func (b *BenchmarkRunner) openLoopHandler(wg *sync.WaitGroup, queryPool *sync.Pool, processor Processor, workerNum int) {
+	processor.Init(workerNum)
+	for sq := range b.openLoop.ch {
+		delay := b.openLoop.dequeue(sq)
+		if b.aborted() {
+			queryPool.Put(sq.query)
+			continue
 		}
-		b.sp.send(stats)
-
-		// If PrewarmQueries is set, we run the query as 'cold' first (see above),
-		// then we immediately run it a second time and report that as the 'warm' stat.
-		// This guarantees that the warm stat will reflect optimal cache performance.
-		spArgs := b.sp.getArgs()
-		if spArgs.prewarmQueries {
-			// Warm run
-			stats, err = processor.ProcessQuery(query, true)
-			if err != nil {
-				panic(err)
-			}
-			b.sp.sendWarm(stats)
+		if !b.handleQuery(processor, sq.query, delay) {
+			processor = b.replaceProcessor(workerNum)
+			continue
 		}
-		queryPool.Put(query)
+		queryPool.Put(sq.query)
 	}
+	closeProcessor(processor)
 	wg.Done()
 }
 
+// handleQuery runs a query, and its warm run if prewarming queries, sending
+// the stats to the stat processor. queueDelay is added to the latency of the
+// cold run. It returns false if the query timed out, in which case the
+// processor and the query are still in use and must not be reused; the
+// processor is closed once the timed out query completes.
+func (b *BenchmarkRunner) handleQuery(processor Processor, query Query, queueDelay time.Duration) bool {
+	abandoned := func() { closeProcessor(processor) }
+	stats, err := b.execute(func() ([]*Stat, error) {
+		return b.processQuery(processor, query)
+	}, abandoned)
+	if err != nil {
+		b.handleError(query, false, err)
+		return err != errQueryTimeout
+	}
+	if queueDelay > 0 {
+		addQueueDelay(stats, queueDelay)
+	}
+	b.sp.send(stats)
+	atomic.AddUint64(&b.queryCount, 1)
+
+	// If PrewarmQueries is set, we run the query as 'cold' first (see above),
+	// then we immediately run it a second time and report that as the 'warm' stat.
+	// This guarantees that the warm stat will reflect optimal cache performance.
+	spArgs := b.sp.getArgs()
+	if spArgs.prewarmQueries {
+		// Warm run
+		stats, err = b.execute(func() ([]*Stat, error) {
+			return processor.ProcessQuery(query, true)
+		}, abandoned)
+		if err != nil {
+			b.handleError(query, true, err)
+			return err != errQueryTimeout
+		}
+		b.sp.sendWarm(stats)
+	}
+	return true
+}
+
+// replaceProcessor creates a new processor for a worker whose processor is
+// still busy with a timed out query. The busy processor is closed by
+// handleQuery once the query completes.
+func (b *BenchmarkRunner) replaceProcessor(workerNum int) Processor {
+	processor := b.processorCreate()
+	processor.Init(workerNum)
+	return processor
+}
+
+// processQuery runs the cold execution of a query, verifying its result if
+// result verification is enabled.
+func (b *BenchmarkRunner) processQuery(processor Processor, query Query) ([]*Stat, error) {
+	if b.verifier == nil {
+		return processor.ProcessQuery(query, false)
+	}
+	stats, rs, err := processor.(ResultProcessor).ProcessQueryWithResult(query, false)
+	if err != nil {
+		return nil, err
+	}
+	b.verifier.check(query, rs)
+	return stats, nil
+}
+
 func getRateLimiter(limitRPS uint64, workers uint) *rate.Limiter {
 	var requestRate = rate.Inf
 	var requestBurst = 0
@@ -208,6 +208,62 @@ func TestBenchmarkRunnerRunPanicOnBurnInBiggerThanLimit(t *testing.T) {
 	t.Errorf("the code did not panic")
 }
 
+type testResultProcessor struct {
+	testProcessor
+}
+
+func (p *testResultProcessor) ProcessQueryWithResult(q Query, isWarm bool) ([]*Stat, *ResultSet, error) {
+	stats, err := p.ProcessQuery(q, isWarm)
+	return stats, &ResultSet{}, err
+}
+
+func TestBenchmarkRunnerValidateResultVerification(t *testing.T) {
+	cases := []struct {
+		desc            string
+		verifyFile      string
+		recordResults   string
+		processorCreate ProcessorCreate
+		wantErr         bool
+	}{
+		{
+			desc:            "no verification",
+			processorCreate: func() Processor { return &testProcessor{} },
+		},
+		{
+			desc:            "verify without result sets",
+			verifyFile:      "reference.json",
+			processorCreate: func() Processor { return &testProcessor{} },
+			wantErr:         true,
+		},
+		{
+			desc:            "record without result sets",
+			recordResults:   "reference.json",
+			processorCreate: func() Processor { return &testProcessor{} },
+			wantErr:         true,
+		},
+		{
+			desc:            "verify with result sets",
+			verifyFile:      "reference.json",
+			processorCreate: func() Processor { return &testResultProcessor{} },
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			runner := NewBenchmarkRunner(BenchmarkRunnerConfig{
+				Workers:       1,
+				VerifyFile:    c.verifyFile,
+				RecordResults: c.recordResults,
+			})
+			err := runner.Validate(c.processorCreate)
+			if c.wantErr && err == nil {
+				t.Errorf("expected an error")
+			} else if !c.wantErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
 func TestBenchmarkRunnerRunNoQueries(t *testing.T) {
 	// SETUP
 	// ..empty query file
@@ -224,7 +280,8 @@ func TestBenchmarkRunnerRunNoQueries(t *testing.T) {
 	spStarted := false
 	sendStatsCalled := false
 	// lock controlls access to spStarted and sendStatsCalled
-	// wg gets Done when sp is closed
+	// wg gets Done when sp is started and when sp is closed, as sp is
+	// started asynchronously
 	wg := &sync.WaitGroup{}
 	lock := &sync.Mutex{}
 	sp := mockStatProcessor{
@@ -233,6 +290,7 @@ func TestBenchmarkRunnerRunNoQueries(t *testing.T) {
 			lock.Lock()
 			spStarted = true
 			lock.Unlock()
+			wg.Done()
 		},
 		onSend: func(_ []*Stat) {
 			lock.Lock()
@@ -271,8 +329,10 @@ func TestBenchmarkRunnerRunNoQueries(t *testing.T) {
 	// no errors expected
 
 	// RUN
-	wg.Add(1)
-	b.Run(&TimescaleDBPool, createProcessorFn)
+	wg.Add(2)
+	if err := b.Run(&TimescaleDBPool, createProcessorFn); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
 	wg.Wait()
 	lock.Lock()
 	// ASSERT
@@ -293,9 +353,51 @@ func TestBenchmarkRunnerRunNoQueries(t *testing.T) {
 	}
 }
 
+func TestBenchmarkRunnerRunAborted(t *testing.T) {
+	fakeQueriesFile, err := ioutil.TempFile("", "fake_queries*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(fakeQueriesFile.Name())
+
+	wg := &sync.WaitGroup{}
+	sp := mockStatProcessor{
+		args:      &statProcessorArgs{},
+		onProcess: func(_ uint) { wg.Done() },
+		wg:        wg,
+	}
+	limit := uint64(0)
+	b := &BenchmarkRunner{
+		BenchmarkRunnerConfig: BenchmarkRunnerConfig{
+			Workers:  1,
+			FileName: fakeQueriesFile.Name(),
+		},
+		sp:      &sp,
+		scanner: newScanner(&limit),
+	}
+	createProcessorFn := func() Processor {
+		b.abort("stopped by test")
+		return &mockProcessor{}
+	}
+
+	wg.Add(2)
+	err = b.Run(&TimescaleDBPool, createProcessorFn)
+	wg.Wait()
+	if err == nil {
+		t.Fatalf("unexpected lack of error for an aborted benchmark")
+	}
+	if got, want := err.Error(), "benchmark aborted: stopped by test"; got != want {
+		t.Errorf("incorrect error: got %q want %q", got, want)
+	}
+	if b.Result() == nil || !b.Result().Partial {
+		t.Errorf("aborted benchmark did not report partial results: %+v", b.Result())
+	}
+}
+
 type mockStatProcessor struct {
 	args      *statProcessorArgs
 	onSend    func([]*Stat)
+	onError   func(label []byte, isWarm, timedOut bool)
 	onProcess func(uint)
 	closed    bool
 	wg        *sync.WaitGroup
@@ -314,6 +416,11 @@ func (m *mockStatProcessor) sendWarm(stats []*Stat) {
 		m.onSend(stats)
 	}
 }
+func (m *mockStatProcessor) sendError(label []byte, isWarm, timedOut bool) {
+	if m.onError != nil {
+		m.onError(label, isWarm, timedOut)
+	}
+}
 func (m *mockStatProcessor) process(workers uint) {
 	if m.onProcess != nil {
 		m.onProcess(workers)
@@ -327,6 +434,12 @@ func (m *mockStatProcessor) GetTotalsMap() map[string]interface{} {
 	totals := make(map[string]interface{})
 	return totals
 }
+func (m *mockStatProcessor) GetLatencies() (*LatencyResults, error) {
+	return &LatencyResults{}, nil
+}
+func (m *mockStatProcessor) GetErrors() map[string]*ErrorStats {
+	return nil
+}
 
 type mockProcessor struct {
 	processRes []*Stat
@@ -362,3 +475,30 @@ func TestGetRateLimiter(t *testing.T) {
 		})
 	}
 }
+
+type closingProcessor struct {
+	testProcessor
+	closed bool
+}
+
+func (p *closingProcessor) Close() {
+	p.closed = true
+}
+
+func TestProcessorHandlerClosesProcessor(t *testing.T) {
+	b := NewBenchmarkRunner(BenchmarkRunnerConfig{})
+	b.ch = make(chan Query, 1)
+	b.ch <- testQueryPool.Get().(*testQuery)
+	close(b.ch)
+
+	p := &closingProcessor{}
+	var wg sync.WaitGroup
+	wg.Add(1)
+	b.processorHandler(&wg, getRateLimiter(0, 1), &testQueryPool, p, 0)
+	if p.count != 1 {
+		t.Errorf("query not processed")
+	}
+	if !p.closed {
+		t.Errorf("processor not closed")
+	}
+}
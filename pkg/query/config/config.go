@@ -2,13 +2,24 @@ package config
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/bodhiye/tsbs/pkg/data/usecases/common"
 	"github.com/bodhiye/tsbs/tools/utils"
 	"github.com/spf13/pflag"
 )
 
-const ErrEmptyQueryType = "query type cannot be empty"
+const (
+	ErrEmptyQueryType    = "query type cannot be empty"
+	ErrQueryTypeAndMixes = "only one of query-type, query-mix and query-mix-file can be set"
+
+	// TimeDistributionUniform picks the time windows of the queries
+	// uniformly within the dataset.
+	TimeDistributionUniform = "uniform"
+	// TimeDistributionRecent picks the time windows of the queries biased
+	// towards the end of the dataset.
+	TimeDistributionRecent = "recent"
+)
 
 // QueryGeneratorConfig is the GeneratorConfig that should be used with a
 // QueryGenerator. It includes all the fields from a BaseConfig, as well as
@@ -18,9 +29,20 @@ type QueryGeneratorConfig struct {
 	common.BaseConfig
 	Limit                uint64 `mapstructure:"queries"`
 	QueryType            string `mapstructure:"query-type"`
+	QueryMix             string `mapstructure:"query-mix"`
+	QueryMixFile         string `mapstructure:"query-mix-file"`
+	CustomQueriesFile    string `mapstructure:"custom-queries-file"`
 	InterleavedGroupID   uint   `mapstructure:"interleaved-generation-group-id"`
 	InterleavedNumGroups uint   `mapstructure:"interleaved-generation-groups"`
 
+	EntityDistribution       string        `mapstructure:"entity-distribution"`
+	EntityZipfExponent       float64       `mapstructure:"entity-zipf-exponent"`
+	EntityHotSetPercent      float64       `mapstructure:"entity-hot-set-percent"`
+	EntityHotSetQueryPercent float64       `mapstructure:"entity-hot-set-query-percent"`
+	TimeDistribution         string        `mapstructure:"time-distribution"`
+	TimeRecencyMean          time.Duration `mapstructure:"time-recency-mean"`
+	DevopsTagValueLength     int           `mapstructure:"devops-tag-value-length"`
+
 	// TODO - I think this needs some rethinking, but a simple, elegant solution escapes me right now
 	TimescaleUseJSON       bool `mapstructure:"timescale-use-json"`
 	TimescaleUseTags       bool `mapstructure:"timescale-use-tags"`
@@ -39,24 +61,87 @@ func (c *QueryGeneratorConfig) Validate() error {
 		return err
 	}
 
-	if c.QueryType == "" {
+	set := 0
+	for _, v := range []string{c.QueryType, c.QueryMix, c.QueryMixFile} {
+		if v != "" {
+			set++
+		}
+	}
+	if set == 0 {
 		return fmt.Errorf(ErrEmptyQueryType)
+	} else if set > 1 {
+		return fmt.Errorf(ErrQueryTypeAndMixes)
+	}
+	if c.QueryMix != "" {
+		if _, err := ParseQueryMix(c.QueryMix); err != nil {
+			return err
+		}
+	}
+
+	switch c.TimeDistribution {
+	case "", TimeDistributionUniform:
+	case TimeDistributionRecent:
+		if c.TimeRecencyMean <= 0 {
+			return fmt.Errorf("time recency mean must be positive; got %v", c.TimeRecencyMean)
+		}
+	default:
+		return fmt.Errorf("unknown time distribution '%s'; choices are %s and %s", c.TimeDistribution, TimeDistributionUniform, TimeDistributionRecent)
+	}
+
+	if c.DevopsTagValueLength < 0 {
+		return fmt.Errorf("devops tag value length cannot be negative; got %d", c.DevopsTagValueLength)
 	}
 
 	err = utils.ValidateGroups(c.InterleavedGroupID, c.InterleavedNumGroups)
 	return err
 }
 
+// WindowRecencyMean returns the mean distance of the query time windows from
+// the end of the dataset, or 0 if they are picked uniformly.
+func (c *QueryGeneratorConfig) WindowRecencyMean() time.Duration {
+	if c.TimeDistribution == TimeDistributionRecent {
+		return c.TimeRecencyMean
+	}
+	return 0
+}
+
+// QueryMixEntries returns the query types to generate with their weights,
+// from the query mix or query mix file, or the single query type with a
+// weight of 1.
+func (c *QueryGeneratorConfig) QueryMixEntries() ([]QueryMixEntry, error) {
+	switch {
+	case c.QueryMix != "":
+		return ParseQueryMix(c.QueryMix)
+	case c.QueryMixFile != "":
+		return ReadQueryMixFile(c.QueryMixFile)
+	default:
+		return []QueryMixEntry{{QueryType: c.QueryType, Weight: 1}}, nil
+	}
+}
+
 func (c *QueryGeneratorConfig) AddToFlagSet(fs *pflag.FlagSet) {
 	c.BaseConfig.AddToFlagSet(fs)
 	fs.Uint64("queries", 1000, "Number of queries to generate.")
 	fs.String("query-type", "", "Query type. (Choices are in the use case matrix.)")
+	fs.String("query-mix", "", "Generate a mix of query types interleaved by weight instead of a single query type, e.g. 'single-groupby-1-1-1:40,lastpoint:10,high-cpu-1:50'")
+	fs.String("query-mix-file", "", "YAML file with the query mix to generate, mapping each query type to its weight")
+	fs.String("custom-queries-file", "", "YAML file with the named query templates and weights of the 'custom' query type")
 
 	fs.Uint("interleaved-generation-group-id", 0,
 		"Group (0-indexed) to perform round-robin serialization within. Use this to scale up data generation to multiple processes.")
 	fs.Uint("interleaved-generation-groups", 1,
 		"The number of round-robin serialization groups. Use this to scale up data generation to multiple processes.")
 
+	fs.String("entity-distribution", "uniform",
+		"How the random hosts or trucks of the queries are picked: 'uniform', 'zipf' (the first ones the most) or 'hot-set' (the first ones for a fixed share of the picks)")
+	fs.Float64("entity-zipf-exponent", 1.1, "Exponent (> 1) of the 'zipf' entity distribution. The larger, the more skewed.")
+	fs.Float64("entity-hot-set-percent", 20, "Percentage of the hosts or trucks in the hot set of the 'hot-set' entity distribution")
+	fs.Float64("entity-hot-set-query-percent", 80, "Percentage of the picks from the hot set of the 'hot-set' entity distribution")
+	fs.String("time-distribution", "uniform",
+		"How the random time windows of the queries are picked: 'uniform' or 'recent' (biased towards the end of the dataset)")
+	fs.Duration("time-recency-mean", time.Hour, "Mean distance of the time windows from the end of the dataset with the 'recent' time distribution")
+	fs.Int("devops-tag-value-length", 0, "Minimum length the hostnames were padded to with zeros, as set when generating the data. Used only in devops use-cases")
+
 	fs.Bool("clickhouse-use-tags", true, "ClickHouse only: Use separate tags table when querying")
 	fs.Bool("mongo-use-naive", true, "MongoDB only: Generate queries for the 'naive' data storage format for Mongo")
 	fs.Bool("timescale-use-json", false, "TimescaleDB only: Use separate JSON tags table when querying")
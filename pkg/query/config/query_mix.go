@@ -0,0 +1,94 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	queryMixEntrySeparator  = ","
+	queryMixWeightSeparator = ":"
+	queryMixFormatError     = "query mix entry '%s' could not be parsed. Required: 'query-type:weight' | weight is a positive integer"
+)
+
+// QueryMixEntry is a query type of a query mix, with its weight relative to
+// the other query types of the mix.
+type QueryMixEntry struct {
+	QueryType string
+	Weight    uint64
+}
+
+// ParseQueryMix parses a string representation of a query mix. It goes like
+// this:
+// string='single-groupby-1-1-1:40,lastpoint:10,high-cpu-1:50' => 40% of the
+// queries are single-groupby-1-1-1, 10% lastpoint and 50% high-cpu-1
+// The weights don't have to add up to 100, each query type gets its weight
+// divided by the sum of the weights.
+func ParseQueryMix(mix string) ([]QueryMixEntry, error) {
+	var entries []QueryMixEntry
+	for _, part := range strings.Split(mix, queryMixEntrySeparator) {
+		part = strings.TrimSpace(part)
+		parts := strings.SplitN(part, queryMixWeightSeparator, 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf(queryMixFormatError, part)
+		}
+		weight, err := strconv.ParseUint(strings.TrimSpace(parts[1]), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf(queryMixFormatError, part)
+		}
+		entries = append(entries, QueryMixEntry{QueryType: strings.TrimSpace(parts[0]), Weight: weight})
+	}
+	return entries, validateQueryMix(entries)
+}
+
+// ReadQueryMixFile reads a query mix from a YAML file mapping each query type
+// to its weight, e.g.:
+//
+//	single-groupby-1-1-1: 40
+//	lastpoint: 10
+//	high-cpu-1: 50
+func ReadQueryMixFile(fileName string) ([]QueryMixEntry, error) {
+	contents, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("could not read query mix file: %v", err)
+	}
+	// a MapSlice keeps the query types in the order of the file
+	var mix yaml.MapSlice
+	if err := yaml.Unmarshal(contents, &mix); err != nil {
+		return nil, fmt.Errorf("could not parse query mix file %s: %v", fileName, err)
+	}
+	entries := make([]QueryMixEntry, 0, len(mix))
+	for _, item := range mix {
+		queryType := fmt.Sprint(item.Key)
+		weight, ok := item.Value.(int)
+		if !ok || weight < 0 {
+			return nil, fmt.Errorf("weight of query type '%s' in query mix file %s must be a positive integer", queryType, fileName)
+		}
+		entries = append(entries, QueryMixEntry{QueryType: queryType, Weight: uint64(weight)})
+	}
+	return entries, validateQueryMix(entries)
+}
+
+func validateQueryMix(entries []QueryMixEntry) error {
+	if len(entries) == 0 {
+		return fmt.Errorf("query mix must have at least one query type")
+	}
+	seen := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		if e.QueryType == "" {
+			return fmt.Errorf(ErrEmptyQueryType)
+		}
+		if e.Weight == 0 {
+			return fmt.Errorf("weight of query type '%s' in query mix must be positive", e.QueryType)
+		}
+		if seen[e.QueryType] {
+			return fmt.Errorf("query type '%s' is more than once in query mix", e.QueryType)
+		}
+		seen[e.QueryType] = true
+	}
+	return nil
+}
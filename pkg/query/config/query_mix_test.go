@@ -0,0 +1,106 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseQueryMix(t *testing.T) {
+	cases := []struct {
+		desc      string
+		mix       string
+		want      []QueryMixEntry
+		shouldErr bool
+	}{
+		{
+			desc: "single query type",
+			mix:  "lastpoint:1",
+			want: []QueryMixEntry{{QueryType: "lastpoint", Weight: 1}},
+		},
+		{
+			desc: "several query types with spaces",
+			mix:  "single-groupby-1-1-1:40, lastpoint:10 ,high-cpu-1: 50",
+			want: []QueryMixEntry{
+				{QueryType: "single-groupby-1-1-1", Weight: 40},
+				{QueryType: "lastpoint", Weight: 10},
+				{QueryType: "high-cpu-1", Weight: 50},
+			},
+		},
+		{desc: "empty", mix: "", shouldErr: true},
+		{desc: "missing weight", mix: "lastpoint", shouldErr: true},
+		{desc: "missing query type", mix: ":10", shouldErr: true},
+		{desc: "zero weight", mix: "lastpoint:0", shouldErr: true},
+		{desc: "negative weight", mix: "lastpoint:-1", shouldErr: true},
+		{desc: "bad weight", mix: "lastpoint:a", shouldErr: true},
+		{desc: "duplicate query type", mix: "lastpoint:1,lastpoint:2", shouldErr: true},
+	}
+	for _, c := range cases {
+		got, err := ParseQueryMix(c.mix)
+		if c.shouldErr {
+			if err == nil {
+				t.Errorf("%s: unexpected lack of error", c.desc)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", c.desc, err)
+		} else if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: incorrect query mix: got %v want %v", c.desc, got, c.want)
+		}
+	}
+}
+
+func TestReadQueryMixFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "query-mix")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cases := []struct {
+		desc      string
+		contents  string
+		want      []QueryMixEntry
+		shouldErr bool
+	}{
+		{
+			desc:     "query types in file order",
+			contents: "single-groupby-1-1-1: 40\nlastpoint: 10\nhigh-cpu-1: 50\n",
+			want: []QueryMixEntry{
+				{QueryType: "single-groupby-1-1-1", Weight: 40},
+				{QueryType: "lastpoint", Weight: 10},
+				{QueryType: "high-cpu-1", Weight: 50},
+			},
+		},
+		{desc: "empty", contents: "", shouldErr: true},
+		{desc: "zero weight", contents: "lastpoint: 0\n", shouldErr: true},
+		{desc: "negative weight", contents: "lastpoint: -1\n", shouldErr: true},
+		{desc: "bad weight", contents: "lastpoint: a\n", shouldErr: true},
+		{desc: "not a map", contents: "- lastpoint\n", shouldErr: true},
+	}
+	for i, c := range cases {
+		fileName := filepath.Join(dir, string(rune('a'+i))+".yaml")
+		if err := ioutil.WriteFile(fileName, []byte(c.contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+		got, err := ReadQueryMixFile(fileName)
+		if c.shouldErr {
+			if err == nil {
+				t.Errorf("%s: unexpected lack of error", c.desc)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", c.desc, err)
+		} else if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: incorrect query mix: got %v want %v", c.desc, got, c.want)
+		}
+	}
+
+	if _, err := ReadQueryMixFile(filepath.Join(dir, "missing.yaml")); err == nil {
+		t.Errorf("unexpected lack of error for missing file")
+	}
+}
@@ -0,0 +1,195 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"sort"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+const (
+	// ErrorPolicyAbort stops the benchmark on the first failed query
+	ErrorPolicyAbort = "abort"
+	// ErrorPolicyContinue counts failed queries and keeps going
+	ErrorPolicyContinue = "continue"
+	// ErrorPolicyAbortAfter counts failed queries and stops the benchmark
+	// once more than max-errors queries failed
+	ErrorPolicyAbortAfter = "abort-after"
+)
+
+// errQueryTimeout is returned for queries that did not complete within the
+// query timeout.
+var errQueryTimeout = errors.New("query timed out")
+
+// errorCounts counts the failed and timed out queries of a label.
+type errorCounts struct {
+	errors   uint64
+	timeouts uint64
+}
+
+// ErrorStats holds the number of failed and timed out queries of a query
+// label. ErrorRate is the share of the executed queries that failed or
+// timed out.
+type ErrorStats struct {
+	Errors    uint64  `json:"errors"`
+	Timeouts  uint64  `json:"timeouts"`
+	ErrorRate float64 `json:"errorRate"`
+}
+
+func newErrorStats(counts *errorCounts, succeeded int64) *ErrorStats {
+	failed := counts.errors + counts.timeouts
+	return &ErrorStats{
+		Errors:    counts.errors,
+		Timeouts:  counts.timeouts,
+		ErrorRate: float64(failed) / float64(failed+uint64(succeeded)),
+	}
+}
+
+// writeErrorStatsMap writes the error stats per label, ordered by label.
+func writeErrorStatsMap(w io.Writer, errorStats map[string]*ErrorStats) error {
+	if len(errorStats) == 0 {
+		return nil
+	}
+	labels := make([]string, 0, len(errorStats))
+	for label := range errorStats {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+
+	if _, err := fmt.Fprintf(w, "Errors:\n"); err != nil {
+		return err
+	}
+	for _, label := range labels {
+		s := errorStats[label]
+		_, err := fmt.Fprintf(w, "%s: errors %d, timeouts %d, error rate %0.2f%%\n", label, s.Errors, s.Timeouts, s.ErrorRate*100)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateErrorPolicy(policy string, maxErrors uint64) error {
+	switch policy {
+	case ErrorPolicyAbort, ErrorPolicyContinue:
+		return nil
+	case ErrorPolicyAbortAfter:
+		if maxErrors == 0 {
+			return fmt.Errorf("error policy '%s' requires max-errors to be set", ErrorPolicyAbortAfter)
+		}
+		return nil
+	default:
+		return fmt.Errorf("error policy must be one of '%s', '%s' or '%s', got '%s'",
+			ErrorPolicyAbort, ErrorPolicyContinue, ErrorPolicyAbortAfter, policy)
+	}
+}
+
+type queryOutcome struct {
+	stats []*Stat
+	err   error
+}
+
+// execute runs fn, the execution of a query, enforcing the query timeout.
+// When the query times out it returns errQueryTimeout, while the processor
+// is still busy with the query, so neither the processor nor the query can
+// be reused. abandoned is called once such a timed out query completes.
+func (b *BenchmarkRunner) execute(fn func() ([]*Stat, error), abandoned func()) ([]*Stat, error) {
+	if b.QueryTimeout <= 0 {
+		return fn()
+	}
+	done := make(chan queryOutcome, 1)
+	go func() {
+		stats, err := fn()
+		done <- queryOutcome{stats: stats, err: err}
+	}()
+	timer := time.NewTimer(b.QueryTimeout)
+	defer timer.Stop()
+	select {
+	case outcome := <-done:
+		return outcome.stats, outcome.err
+	case <-timer.C:
+		go func() {
+			<-done
+			abandoned()
+		}()
+		return nil, errQueryTimeout
+	}
+}
+
+// handleError accounts a failed query according to the error policy,
+// stopping the benchmark if required.
+func (b *BenchmarkRunner) handleError(q Query, isWarm bool, err error) {
+	b.sp.sendError(q.HumanLabelName(), isWarm, err == errQueryTimeout)
+	failed := atomic.AddUint64(&b.errorCount, 1)
+
+	switch b.ErrorPolicy {
+	case ErrorPolicyContinue:
+		if b.Debug > 0 {
+			fmt.Fprintf(os.Stderr, "query %d (%s) failed: %v\n", q.GetID(), q.HumanLabelName(), err)
+		}
+	case ErrorPolicyAbortAfter:
+		if failed > b.MaxErrors {
+			b.abort(fmt.Sprintf("more than %d queries failed, last error: query %d (%s): %v", b.MaxErrors, q.GetID(), q.HumanLabelName(), err))
+		}
+	default:
+		b.abort(fmt.Sprintf("query %d (%s) failed: %v", q.GetID(), q.HumanLabelName(), err))
+	}
+}
+
+// abort stops sending queries, keeping the reason of the first abort. The
+// queries in flight still complete, and the results are marked as partial.
+func (b *BenchmarkRunner) abort(reason string) {
+	b.abortOnce.Do(func() {
+		fmt.Fprintf(os.Stderr, "aborting benchmark: %s\n", reason)
+		b.abortReason = reason
+		close(b.done)
+	})
+}
+
+// handleSignals aborts the benchmark on SIGINT or SIGTERM, so the queries
+// run so far are still summarized and written to the results file. A second
+// signal exits at once. The returned function stops handling signals.
+func (b *BenchmarkRunner) handleSignals() func() {
+	c := make(chan os.Signal, 2)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		sig, ok := <-c
+		if !ok {
+			return
+		}
+		b.abort(fmt.Sprintf("caught %v", sig))
+		if sig, ok = <-c; ok {
+			log.Fatalf("caught %v again, exiting", sig)
+		}
+	}()
+	return func() {
+		signal.Stop(c)
+		close(c)
+	}
+}
+
+// getAbortReason returns the reason the benchmark was aborted, or an empty
+// string if it was not. The reason is set before done is closed, so it is
+// only read once done is closed.
+func (b *BenchmarkRunner) getAbortReason() string {
+	if !b.aborted() {
+		return ""
+	}
+	return b.abortReason
+}
+
+// aborted returns whether the benchmark has been aborted.
+func (b *BenchmarkRunner) aborted() bool {
+	select {
+	case <-b.done:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,220 @@
+package query
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestValidateErrorPolicy(t *testing.T) {
+	cases := []struct {
+		policy    string
+		maxErrors uint64
+		wantErr   bool
+	}{
+		{policy: ErrorPolicyAbort},
+		{policy: ErrorPolicyContinue},
+		{policy: ErrorPolicyAbortAfter, maxErrors: 10},
+		{policy: ErrorPolicyAbortAfter, wantErr: true},
+		{policy: "retry", wantErr: true},
+	}
+	for _, c := range cases {
+		err := validateErrorPolicy(c.policy, c.maxErrors)
+		if c.wantErr && err == nil {
+			t.Errorf("%s: unexpected lack of error", c.policy)
+		} else if !c.wantErr && err != nil {
+			t.Errorf("%s: unexpected error: %v", c.policy, err)
+		}
+	}
+}
+
+func TestBenchmarkRunnerExecute(t *testing.T) {
+	b := &BenchmarkRunner{}
+	want := errors.New("failed")
+	abandoned := make(chan struct{})
+	onAbandoned := func() { close(abandoned) }
+	if _, err := b.execute(func() ([]*Stat, error) { return nil, want }, onAbandoned); err != want {
+		t.Errorf("without timeout: got %v want %v", err, want)
+	}
+
+	b.QueryTimeout = 10 * time.Millisecond
+	if _, err := b.execute(func() ([]*Stat, error) { return nil, want }, onAbandoned); err != want {
+		t.Errorf("with timeout: got %v want %v", err, want)
+	}
+	release := make(chan struct{})
+	_, err := b.execute(func() ([]*Stat, error) {
+		<-release
+		return nil, nil
+	}, onAbandoned)
+	if err != errQueryTimeout {
+		t.Errorf("slow query: got %v want %v", err, errQueryTimeout)
+	}
+	select {
+	case <-abandoned:
+		t.Fatalf("abandoned called before the slow query completed")
+	default:
+	}
+	close(release)
+	select {
+	case <-abandoned:
+	case <-time.After(time.Second):
+		t.Errorf("abandoned not called once the slow query completed")
+	}
+}
+
+// failingProcessor fails every query with an ID in fail, and blocks on
+// every query with an ID in block until release is closed.
+type failingProcessor struct {
+	fail    map[uint64]bool
+	block   map[uint64]bool
+	release chan struct{}
+	wNum    int
+	closed  chan struct{}
+}
+
+func (p *failingProcessor) Init(workerNum int) {
+	p.wNum = workerNum
+	p.closed = make(chan struct{})
+}
+
+func (p *failingProcessor) Close() {
+	close(p.closed)
+}
+
+func (p *failingProcessor) ProcessQuery(q Query, _ bool) ([]*Stat, error) {
+	if p.block[q.GetID()] {
+		<-p.release
+	}
+	if p.fail[q.GetID()] {
+		return nil, errors.New("failed")
+	}
+	return nil, nil
+}
+
+type errorRecorder struct {
+	mu       sync.Mutex
+	errors   int
+	timeouts int
+}
+
+func (r *errorRecorder) onError(_ []byte, _, timedOut bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if timedOut {
+		r.timeouts++
+	} else {
+		r.errors++
+	}
+}
+
+// runFailingQueries runs queries with IDs 0 to queries-1 through a single
+// processor handler.
+func runFailingQueries(b *BenchmarkRunner, p *failingProcessor, queries int) {
+	b.done = make(chan struct{})
+	b.ch = make(chan Query, queries)
+	for i := 0; i < queries; i++ {
+		q := testQueryPool.Get().(*testQuery)
+		q.SetID(uint64(i))
+		b.ch <- q
+	}
+	close(b.ch)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	b.processorHandler(&wg, getRateLimiter(0, 1), &testQueryPool, p, 0)
+}
+
+func TestProcessorHandlerErrorPolicy(t *testing.T) {
+	cases := []struct {
+		desc        string
+		policy      string
+		maxErrors   uint64
+		wantErrors  int
+		wantAborted bool
+	}{
+		{desc: "abort", policy: ErrorPolicyAbort, wantErrors: 1, wantAborted: true},
+		{desc: "continue", policy: ErrorPolicyContinue, wantErrors: 3},
+		{desc: "abort after 1", policy: ErrorPolicyAbortAfter, maxErrors: 1, wantErrors: 2, wantAborted: true},
+		{desc: "abort after 3", policy: ErrorPolicyAbortAfter, maxErrors: 3, wantErrors: 3},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			recorder := &errorRecorder{}
+			b := &BenchmarkRunner{
+				BenchmarkRunnerConfig: BenchmarkRunnerConfig{ErrorPolicy: c.policy, MaxErrors: c.maxErrors},
+				sp:                    &mockStatProcessor{args: &statProcessorArgs{}, onError: recorder.onError},
+			}
+			p := &failingProcessor{fail: map[uint64]bool{1: true, 3: true, 5: true}}
+			runFailingQueries(b, p, 8)
+			if recorder.errors != c.wantErrors {
+				t.Errorf("incorrect number of errors: got %d want %d", recorder.errors, c.wantErrors)
+			}
+			if got := b.aborted(); got != c.wantAborted {
+				t.Errorf("incorrect aborted: got %v want %v", got, c.wantAborted)
+			}
+			if c.wantAborted && b.getAbortReason() == "" {
+				t.Errorf("abort reason not set")
+			}
+		})
+	}
+}
+
+func TestProcessorHandlerTimeout(t *testing.T) {
+	recorder := &errorRecorder{}
+	release := make(chan struct{})
+	var created []*failingProcessor
+	b := &BenchmarkRunner{
+		BenchmarkRunnerConfig: BenchmarkRunnerConfig{
+			ErrorPolicy:  ErrorPolicyContinue,
+			QueryTimeout: 10 * time.Millisecond,
+		},
+		sp: &mockStatProcessor{args: &statProcessorArgs{}, onError: recorder.onError},
+	}
+	b.processorCreate = func() Processor {
+		p := &failingProcessor{}
+		created = append(created, p)
+		return p
+	}
+	p := &failingProcessor{block: map[uint64]bool{0: true}, release: release}
+	runFailingQueries(b, p, 3)
+	if recorder.timeouts != 1 || recorder.errors != 0 {
+		t.Errorf("expected a single timeout, got %d timeouts and %d errors", recorder.timeouts, recorder.errors)
+	}
+	if len(created) != 1 {
+		t.Fatalf("expected the timed out processor to be replaced once, got %d", len(created))
+	}
+	if created[0].wNum != 0 {
+		t.Errorf("replacement processor not initialized for the worker")
+	}
+	select {
+	case <-p.closed:
+		t.Fatalf("timed out processor closed while its query is running")
+	default:
+	}
+	close(release)
+	select {
+	case <-p.closed:
+	case <-time.After(time.Second):
+		t.Errorf("timed out processor not closed once its query completed")
+	}
+}
+
+func TestBenchmarkRunnerHandleSignals(t *testing.T) {
+	b := &BenchmarkRunner{done: make(chan struct{})}
+	stopSignals := b.handleSignals()
+	defer stopSignals()
+	if err := syscall.Kill(os.Getpid(), syscall.SIGTERM); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-b.done:
+	case <-time.After(time.Second):
+		t.Fatalf("benchmark not aborted on SIGTERM")
+	}
+	if reason := b.getAbortReason(); !strings.Contains(reason, "terminated") {
+		t.Errorf("incorrect abort reason: %s", reason)
+	}
+}
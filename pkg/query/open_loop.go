@@ -0,0 +1,207 @@
+package query
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// ArrivalConstant schedules queries at a fixed interval
+	ArrivalConstant = "constant"
+	// ArrivalPoisson schedules queries with exponentially distributed
+	// intervals, i.e. as a Poisson process
+	ArrivalPoisson = "poisson"
+
+	// openLoopQueueSize is the number of scheduled queries that can wait for
+	// a free worker before the scheduler blocks. Latencies are measured from
+	// the intended send time, so they stay correct even when it blocks.
+	openLoopQueueSize = 100000
+)
+
+// scheduledQuery is a query with the time it was intended to be sent at.
+type scheduledQuery struct {
+	query    Query
+	intended time.Time
+}
+
+// arrivalScheduler computes the intended send times of queries for a given
+// arrival rate, independent of when previous queries complete.
+type arrivalScheduler struct {
+	interval float64 // mean interval between queries in seconds
+	poisson  bool
+	rand     *rand.Rand
+	next     time.Time
+}
+
+func newArrivalScheduler(arrivalRate float64, distribution string, r *rand.Rand) (*arrivalScheduler, error) {
+	if arrivalRate <= 0 {
+		return nil, fmt.Errorf("arrival rate must be positive, got %v", arrivalRate)
+	}
+	if distribution != ArrivalConstant && distribution != ArrivalPoisson {
+		return nil, fmt.Errorf("arrival distribution must be '%s' or '%s', got '%s'", ArrivalConstant, ArrivalPoisson, distribution)
+	}
+	return &arrivalScheduler{
+		interval: 1 / arrivalRate,
+		poisson:  distribution == ArrivalPoisson,
+		rand:     r,
+	}, nil
+}
+
+// start sets the intended send time of the first query.
+func (s *arrivalScheduler) start(t time.Time) {
+	s.next = t
+}
+
+// nextArrival returns the intended send time of the next query.
+func (s *arrivalScheduler) nextArrival() time.Time {
+	arrival := s.next
+	interval := s.interval
+	if s.poisson {
+		interval = s.rand.ExpFloat64() * s.interval
+	}
+	s.next = s.next.Add(time.Duration(interval * float64(time.Second)))
+	return arrival
+}
+
+// OpenLoopStats holds the outcome of an open-loop run.
+type OpenLoopStats struct {
+	ArrivalRate  float64 `json:"arrivalRate"`
+	Distribution string  `json:"distribution"`
+	// Seed is the seed of the random intervals between queries
+	Seed    int64  `json:"seed"`
+	Queries uint64 `json:"queries"`
+	// MaxQueueDepth and MeanQueueDepth are the number of queries whose
+	// intended send time had passed, but were not yet picked up by a worker,
+	// seen by each query when it was picked up
+	MaxQueueDepth  int64   `json:"maxQueueDepth"`
+	MeanQueueDepth float64 `json:"meanQueueDepth"`
+	// QueueDelay is the time from the intended send time of the queries to
+	// when a worker picked them up
+	QueueDelay *LatencyStats `json:"queueDelay"`
+}
+
+// openLoop sends queries to the workers at the times set by an
+// arrivalScheduler. The delay between the intended send time and the time a
+// worker picks up a query is added to its latency, so latencies reflect what
+// users would experience when the database can't keep up, instead of being
+// hidden by workers that wait for their previous query (coordinated
+// omission).
+type openLoop struct {
+	scheduler    *arrivalScheduler
+	arrivalRate  float64
+	distribution string
+	seed         int64
+	ch           chan *scheduledQuery
+	queued       int64
+
+	mu          sync.Mutex
+	queries     uint64
+	depthSum    int64
+	maxDepth    int64
+	queueDelays *statGroup
+
+	nowFn   func() time.Time
+	sleepFn func(time.Duration)
+}
+
+func newOpenLoop(arrivalRate float64, distribution string, seed int64) (*openLoop, error) {
+	scheduler, err := newArrivalScheduler(arrivalRate, distribution, rand.New(rand.NewSource(seed)))
+	if err != nil {
+		return nil, err
+	}
+	return &openLoop{
+		scheduler:    scheduler,
+		arrivalRate:  arrivalRate,
+		distribution: distribution,
+		seed:         seed,
+		ch:           make(chan *scheduledQuery, openLoopQueueSize),
+		queueDelays:  newStatGroup(0),
+		nowFn:        time.Now,
+		sleepFn:      time.Sleep,
+	}, nil
+}
+
+// schedule releases the queries read from in to the workers at their
+// intended send times, closing the workers channel when in is closed.
+func (o *openLoop) schedule(in <-chan Query) {
+	o.scheduler.start(o.nowFn())
+	for q := range in {
+		intended := o.scheduler.nextArrival()
+		if wait := intended.Sub(o.nowFn()); wait > 0 {
+			o.sleepFn(wait)
+		}
+		atomic.AddInt64(&o.queued, 1)
+		o.ch <- &scheduledQuery{query: q, intended: intended}
+	}
+	close(o.ch)
+}
+
+// dequeue records that a worker picked up a scheduled query, and returns
+// how long the query waited past its intended send time.
+func (o *openLoop) dequeue(sq *scheduledQuery) time.Duration {
+	depth := atomic.AddInt64(&o.queued, -1) + 1
+	delay := o.nowFn().Sub(sq.intended)
+	if delay < 0 {
+		delay = 0
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.queries++
+	o.depthSum += depth
+	if depth > o.maxDepth {
+		o.maxDepth = depth
+	}
+	o.queueDelays.push(float64(delay.Nanoseconds()) / 1e6)
+	return delay
+}
+
+func (o *openLoop) stats() (*OpenLoopStats, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	queueDelay, err := o.queueDelays.latencyStats()
+	if err != nil {
+		return nil, err
+	}
+	stats := &OpenLoopStats{
+		ArrivalRate:   o.arrivalRate,
+		Distribution:  o.distribution,
+		Seed:          o.seed,
+		Queries:       o.queries,
+		MaxQueueDepth: o.maxDepth,
+		QueueDelay:    queueDelay,
+	}
+	if o.queries > 0 {
+		stats.MeanQueueDepth = float64(o.depthSum) / float64(o.queries)
+	}
+	return stats, nil
+}
+
+// summary writes the open-loop stats to w.
+func (o *openLoop) summary(w io.Writer, wallTook time.Duration) {
+	stats, err := o.stats()
+	if err != nil {
+		fmt.Fprintf(w, "open loop: could not summarize: %v\n", err)
+		return
+	}
+	fmt.Fprintf(w, "Open loop: target rate %0.2f queries/sec (%s), achieved rate %0.2f queries/sec\n",
+		stats.ArrivalRate, stats.Distribution, float64(stats.Queries)/wallTook.Seconds())
+	fmt.Fprintf(w, "queue depth: max %d, mean %0.2f\n", stats.MaxQueueDepth, stats.MeanQueueDepth)
+	fmt.Fprintf(w, "queue delay: %s\n", o.queueDelays.string())
+}
+
+// addQueueDelay adds the queue delay of a query to the latency of its
+// complete (non-partial) stats, so it is measured from the intended send
+// time.
+func addQueueDelay(stats []*Stat, delay time.Duration) {
+	ms := float64(delay.Nanoseconds()) / 1e6
+	for _, s := range stats {
+		if !s.isPartial {
+			s.value += ms
+		}
+	}
+}
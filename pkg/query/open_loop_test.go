@@ -0,0 +1,191 @@
+package query
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNewArrivalScheduler(t *testing.T) {
+	if _, err := newArrivalScheduler(0, ArrivalConstant, nil); err == nil {
+		t.Error("expected error for zero arrival rate")
+	}
+	if _, err := newArrivalScheduler(10, "bursty", nil); err == nil {
+		t.Error("expected error for unknown distribution")
+	}
+}
+
+func TestArrivalSchedulerConstant(t *testing.T) {
+	s, err := newArrivalScheduler(4, ArrivalConstant, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	start := time.Unix(0, 0)
+	s.start(start)
+	for i := 0; i < 5; i++ {
+		want := start.Add(time.Duration(i) * 250 * time.Millisecond)
+		if got := s.nextArrival(); !got.Equal(want) {
+			t.Errorf("arrival %d: got %v want %v", i, got, want)
+		}
+	}
+}
+
+func TestArrivalSchedulerPoisson(t *testing.T) {
+	s, err := newArrivalScheduler(100, ArrivalPoisson, rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	start := time.Unix(0, 0)
+	s.start(start)
+	const n = 10000
+	prev := s.nextArrival()
+	var last time.Time
+	for i := 1; i < n; i++ {
+		last = s.nextArrival()
+		if last.Before(prev) {
+			t.Fatalf("arrival %d is before the previous one", i)
+		}
+		prev = last
+	}
+	// the mean interval should be close to 1/rate
+	mean := last.Sub(start).Seconds() / (n - 1)
+	if math.Abs(mean-0.01) > 0.001 {
+		t.Errorf("mean interval too far from 10ms: %v", mean)
+	}
+}
+
+func TestOpenLoopSeed(t *testing.T) {
+	a, err := newOpenLoop(100, ArrivalPoisson, 42)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	b, err := newOpenLoop(100, ArrivalPoisson, 42)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	start := time.Unix(0, 0)
+	a.scheduler.start(start)
+	b.scheduler.start(start)
+	for i := 0; i < 100; i++ {
+		if got, want := a.scheduler.nextArrival(), b.scheduler.nextArrival(); !got.Equal(want) {
+			t.Fatalf("arrival %d differs with the same seed: got %v want %v", i, got, want)
+		}
+	}
+	stats, err := a.stats()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.Seed != 42 {
+		t.Errorf("incorrect seed in stats: got %d want %d", stats.Seed, 42)
+	}
+}
+
+func TestOpenLoopSchedule(t *testing.T) {
+	o, err := newOpenLoop(10, ArrivalConstant, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	now := time.Unix(0, 0)
+	var sleeps []time.Duration
+	o.nowFn = func() time.Time { return now }
+	o.sleepFn = func(d time.Duration) {
+		sleeps = append(sleeps, d)
+		now = now.Add(d)
+	}
+
+	in := make(chan Query, 3)
+	for i := 0; i < 3; i++ {
+		in <- &testQuery{}
+	}
+	close(in)
+	o.schedule(in)
+
+	if len(sleeps) != 2 || sleeps[0] != 100*time.Millisecond || sleeps[1] != 100*time.Millisecond {
+		t.Errorf("expected two sleeps of 100ms, got %v", sleeps)
+	}
+	if o.queued != 3 {
+		t.Errorf("expected 3 queued queries, got %d", o.queued)
+	}
+
+	// no worker picked up the queries until now, 1s after the start
+	now = time.Unix(1, 0)
+	var delays []time.Duration
+	for sq := range o.ch {
+		delays = append(delays, o.dequeue(sq))
+	}
+	want := []time.Duration{time.Second, 900 * time.Millisecond, 800 * time.Millisecond}
+	for i := range want {
+		if delays[i] != want[i] {
+			t.Errorf("delay %d: got %v want %v", i, delays[i], want[i])
+		}
+	}
+
+	stats, err := o.stats()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.Queries != 3 {
+		t.Errorf("expected 3 queries, got %d", stats.Queries)
+	}
+	if stats.MaxQueueDepth != 3 {
+		t.Errorf("expected max queue depth 3, got %d", stats.MaxQueueDepth)
+	}
+	if stats.MeanQueueDepth != 2 {
+		t.Errorf("expected mean queue depth 2, got %v", stats.MeanQueueDepth)
+	}
+	if stats.QueueDelay.Count != 3 || math.Abs(stats.QueueDelay.Max-1000) > 1 {
+		t.Errorf("unexpected queue delay stats: %+v", stats.QueueDelay)
+	}
+}
+
+func TestAddQueueDelay(t *testing.T) {
+	full := GetStat().Init([]byte("q"), 10)
+	partial := GetPartialStat().Init([]byte("q"), 10)
+	addQueueDelay([]*Stat{full, partial}, 5*time.Millisecond)
+	if full.value != 15 {
+		t.Errorf("expected delay to be added to complete stat, got %v", full.value)
+	}
+	if partial.value != 10 {
+		t.Errorf("expected delay not to be added to partial stat, got %v", partial.value)
+	}
+}
+
+func TestOpenLoopHandler(t *testing.T) {
+	b := &BenchmarkRunner{}
+	b.sp = newStatProcessor(&statProcessorArgs{limit: &b.Limit})
+	o, err := newOpenLoop(1000, ArrivalConstant, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	b.openLoop = o
+
+	const qLimit = 17
+	b.ch = make(chan Query, qLimit)
+	for i := 0; i < qLimit; i++ {
+		b.ch <- testQueryPool.Get().(*testQuery)
+	}
+	close(b.ch)
+	go o.schedule(b.ch)
+
+	p := &testProcessor{}
+	var wg sync.WaitGroup
+	wg.Add(1)
+	b.openLoopHandler(&wg, &testQueryPool, p, 3)
+	wg.Wait()
+
+	if p.wNum != 3 {
+		t.Errorf("Init() not called: want %d got %d", 3, p.wNum)
+	}
+	if p.count != qLimit {
+		t.Errorf("total queries wrong: want %d got %d", qLimit, p.count)
+	}
+	stats, err := o.stats()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.Queries != qLimit {
+		t.Errorf("open loop queries wrong: want %d got %d", qLimit, stats.Queries)
+	}
+}
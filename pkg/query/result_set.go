@@ -0,0 +1,138 @@
+package query
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ResultRow is a single row of a normalized query result. Group keys (e.g.
+// hostname or tag values) go into Tags, the time bucket of the row (if any)
+// into Timestamp as nanoseconds since the epoch, and all numeric columns, in
+// the order they were returned, into Values.
+type ResultRow struct {
+	Tags      []string  `json:"tags,omitempty"`
+	Timestamp int64     `json:"timestamp,omitempty"`
+	Values    []float64 `json:"values,omitempty"`
+}
+
+// ResultSet is a database-agnostic representation of the response to a query,
+// used to verify that different databases return the same answers.
+type ResultSet struct {
+	Rows []ResultRow `json:"rows"`
+}
+
+// NewResultRow creates a ResultRow from the column values of a database row:
+// time.Time values become the timestamp, numeric values (and strings that
+// parse as numbers) become values, and everything else becomes a group key.
+// nil values are skipped.
+func NewResultRow(columns ...interface{}) ResultRow {
+	row := ResultRow{}
+	for _, c := range columns {
+		switch v := c.(type) {
+		case nil:
+		case time.Time:
+			row.Timestamp = v.UTC().UnixNano()
+		case *time.Time:
+			if v != nil {
+				row.Timestamp = v.UTC().UnixNano()
+			}
+		case float64:
+			row.Values = append(row.Values, v)
+		case float32:
+			row.Values = append(row.Values, float64(v))
+		case int:
+			row.Values = append(row.Values, float64(v))
+		case int8:
+			row.Values = append(row.Values, float64(v))
+		case int16:
+			row.Values = append(row.Values, float64(v))
+		case int32:
+			row.Values = append(row.Values, float64(v))
+		case int64:
+			row.Values = append(row.Values, float64(v))
+		case uint8:
+			row.Values = append(row.Values, float64(v))
+		case uint16:
+			row.Values = append(row.Values, float64(v))
+		case uint32:
+			row.Values = append(row.Values, float64(v))
+		case uint64:
+			row.Values = append(row.Values, float64(v))
+		case bool:
+			if v {
+				row.Values = append(row.Values, 1)
+			} else {
+				row.Values = append(row.Values, 0)
+			}
+		case []byte:
+			row.appendString(string(v))
+		case string:
+			row.appendString(v)
+		default:
+			row.Tags = append(row.Tags, fmt.Sprintf("%v", v))
+		}
+	}
+	return row
+}
+
+func (r *ResultRow) appendString(s string) {
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		r.Values = append(r.Values, f)
+		return
+	}
+	r.Tags = append(r.Tags, s)
+}
+
+// Normalize sorts the rows of the result set by their group keys and
+// timestamps, so results are comparable regardless of the order in which
+// the database returned them.
+func (rs *ResultSet) Normalize() {
+	sort.SliceStable(rs.Rows, func(i, j int) bool {
+		a, b := rs.Rows[i], rs.Rows[j]
+		if ka, kb := strings.Join(a.Tags, ","), strings.Join(b.Tags, ","); ka != kb {
+			return ka < kb
+		}
+		return a.Timestamp < b.Timestamp
+	})
+}
+
+// Diff compares the result set to a reference result set, returning a
+// description of the first difference found or an empty string if they
+// match. Values are compared with the given relative tolerance. Both result
+// sets are expected to be normalized.
+func (rs *ResultSet) Diff(reference *ResultSet, tolerance float64) string {
+	if len(rs.Rows) != len(reference.Rows) {
+		return fmt.Sprintf("row count mismatch: got %d want %d", len(rs.Rows), len(reference.Rows))
+	}
+	for i := range rs.Rows {
+		got, want := rs.Rows[i], reference.Rows[i]
+		if strings.Join(got.Tags, ",") != strings.Join(want.Tags, ",") {
+			return fmt.Sprintf("row %d: group keys mismatch: got %v want %v", i, got.Tags, want.Tags)
+		}
+		if got.Timestamp != want.Timestamp {
+			return fmt.Sprintf("row %d: timestamp mismatch: got %d want %d", i, got.Timestamp, want.Timestamp)
+		}
+		if len(got.Values) != len(want.Values) {
+			return fmt.Sprintf("row %d: value count mismatch: got %d want %d", i, len(got.Values), len(want.Values))
+		}
+		for j := range got.Values {
+			if !floatsEqual(got.Values[j], want.Values[j], tolerance) {
+				return fmt.Sprintf("row %d: value %d mismatch: got %v want %v", i, j, got.Values[j], want.Values[j])
+			}
+		}
+	}
+	return ""
+}
+
+func floatsEqual(a, b, tolerance float64) bool {
+	if a == b {
+		return true
+	}
+	diff := math.Abs(a - b)
+	scale := math.Max(math.Abs(a), math.Abs(b))
+	return diff <= tolerance*scale
+}
@@ -5,6 +5,8 @@ import (
 	"io"
 	"log"
 	"sync"
+
+	"github.com/bodhiye/tsbs/pkg/distributed"
 )
 
 // scanner is used to read in Queries from a Reader where they are
@@ -12,6 +14,12 @@ import (
 type scanner struct {
 	r     io.Reader
 	limit *uint64
+	// done stops the scanner when closed
+	done <-chan struct{}
+	// assignment is the shard of the queries to send, when running as an
+	// agent of a coordinator. The limit counts the queries of all shards.
+	// The queries of the other shards are still decoded, and skipped.
+	assignment *distributed.Assignment
 }
 
 // newScanner returns a new scanner for a given Reader and its limit
@@ -47,9 +55,22 @@ func (s *scanner) scan(pool *sync.Pool, c chan Query) {
 			log.Fatal(err)
 		}
 
+		if s.assignment != nil && !s.assignment.Owns(n) {
+			// the query belongs to another agent
+			pool.Put(q)
+			n++
+			continue
+		}
+
 		// We have a query, send it to the runner
 		q.SetID(n)
-		c <- q
+		select {
+		case c <- q:
+		case <-s.done:
+			// benchmark stopped, time to quit
+			pool.Put(q)
+			return
+		}
 
 		// Queries counter
 		n++
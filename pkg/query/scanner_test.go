@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"sync"
 	"testing"
+
+	"github.com/bodhiye/tsbs/pkg/distributed"
 )
 
 type testQuery struct {
@@ -52,13 +54,14 @@ func runScan(t *testing.T, b *bytes.Buffer, limit, numQueries uint64, pool *sync
 	queryChan := make(chan Query, 1)
 	scanner := newScanner(&limit)
 	got := uint64(0)
+	var chkErr error
 	wg.Add(1)
 	go func() { // simply count the number of queries we process
 		i := 0
 		for q := range queryChan {
 			err := chk(i, q)
-			if err != nil {
-				t.Fatalf(err.Error())
+			if err != nil && chkErr == nil {
+				chkErr = err
 			}
 			i++
 			got++
@@ -69,6 +72,10 @@ func runScan(t *testing.T, b *bytes.Buffer, limit, numQueries uint64, pool *sync
 	scanner.setReader(input).scan(pool, queryChan)
 	close(queryChan)
 	wg.Wait()
+	if chkErr != nil {
+		// t.Fatal must be called from the test goroutine
+		t.Fatal(chkErr)
+	}
 	if got != numQueries {
 		return fmt.Errorf("incorrect num of queries scanned: got: %v want: %v", got, numQueries)
 	}
@@ -150,3 +157,37 @@ func TestScanTimescaleDB(t *testing.T) {
 		return nil
 	})
 }
+
+func TestScannerShard(t *testing.T) {
+	totalQueries := uint64(7)
+	var b bytes.Buffer
+	err := encodeQueries(&b, totalQueries, func(i uint64) Query {
+		return &testQuery{HumanLabel: []byte("testlabel")}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		limit   uint64
+		wantIDs []uint64
+	}{
+		{limit: 0, wantIDs: []uint64{1, 4}},
+		{limit: 4, wantIDs: []uint64{1}},
+	}
+	for _, c := range cases {
+		limit := c.limit
+		s := newScanner(&limit)
+		s.assignment = &distributed.Assignment{Agent: 1, Agents: 3}
+		queryChan := make(chan Query, totalQueries)
+		s.setReader(bytes.NewReader(b.Bytes())).scan(&testQueryPool, queryChan)
+		close(queryChan)
+		var ids []uint64
+		for q := range queryChan {
+			ids = append(ids, q.GetID())
+		}
+		if fmt.Sprint(ids) != fmt.Sprint(c.wantIDs) {
+			t.Errorf("limit %d: incorrect queries of shard: got %v want %v", c.limit, ids, c.wantIDs)
+		}
+	}
+}
@@ -19,9 +19,12 @@ type statProcessor interface {
 	getArgs() *statProcessorArgs
 	send(stats []*Stat)
 	sendWarm(stats []*Stat)
+	sendError(label []byte, isWarm, timedOut bool)
 	process(workers uint)
 	CloseAndWait()
 	GetTotalsMap() map[string]interface{}
+	GetLatencies() (*LatencyResults, error)
+	GetErrors() map[string]*ErrorStats
 }
 
 type statProcessorArgs struct {
@@ -42,6 +45,13 @@ type defaultStatProcessor struct {
 	startTime   time.Time
 	endTime     time.Time
 	statMapping map[string]*statGroup
+	// coldMapping and warmMapping split the per label stats into cold and
+	// warm runs, only when prewarming queries
+	coldMapping map[string]*statGroup
+	warmMapping map[string]*statGroup
+	// errorMapping counts the failed and timed out queries per label
+	errorsMu     sync.Mutex
+	errorMapping map[string]*errorCounts
 }
 
 func newStatProcessor(args *statProcessorArgs) statProcessor {
@@ -65,6 +75,14 @@ func (sp *defaultStatProcessor) send(stats []*Stat) {
 	}
 }
 
+func (sp *defaultStatProcessor) sendError(label []byte, isWarm, timedOut bool) {
+	s := GetStat().Init(label, 0)
+	s.isWarm = isWarm
+	s.isError = true
+	s.isTimeout = timedOut
+	sp.c <- s
+}
+
 func (sp *defaultStatProcessor) sendWarm(stats []*Stat) {
 	if stats == nil {
 		return
@@ -89,6 +107,8 @@ func (sp *defaultStatProcessor) process(workers uint) {
 	if sp.args.prewarmQueries {
 		sp.statMapping[labelColdQueries] = newStatGroup(*sp.args.limit)
 		sp.statMapping[labelWarmQueries] = newStatGroup(*sp.args.limit)
+		sp.coldMapping = map[string]*statGroup{}
+		sp.warmMapping = map[string]*statGroup{}
 	}
 
 	i := uint64(0)
@@ -108,30 +128,17 @@ func (sp *defaultStatProcessor) process(workers uint) {
 				log.Fatal(err)
 			}
 		}
-		if _, ok := sp.statMapping[string(stat.label)]; !ok {
-			sp.statMapping[string(stat.label)] = newStatGroup(*sp.args.limit)
+		if stat.isError {
+			sp.countError(stat)
+		} else {
+			sp.pushLatency(stat)
 		}
 
-		sp.statMapping[string(stat.label)].push(stat.value)
-
-		if !stat.isPartial {
-			sp.statMapping[allQueriesLabel].push(stat.value)
-
-			// Only needed when differentiating between cold & warm
-			if sp.args.prewarmQueries {
-				if stat.isWarm {
-					sp.statMapping[labelWarmQueries].push(stat.value)
-				} else {
-					sp.statMapping[labelColdQueries].push(stat.value)
-				}
-			}
-
-			// If we're prewarming queries (i.e., running them twice in a row),
-			// only increment the counter for the first (cold) query. Otherwise,
-			// increment for every query.
-			if !sp.args.prewarmQueries || !stat.isWarm {
-				i++
-			}
+		// If we're prewarming queries (i.e., running them twice in a row),
+		// only increment the counter for the first (cold) query. Otherwise,
+		// increment for every query.
+		if !stat.isPartial && (!sp.args.prewarmQueries || !stat.isWarm) {
+			i++
 		}
 
 		statPool.Put(stat)
@@ -175,6 +182,10 @@ func (sp *defaultStatProcessor) process(workers uint) {
 	if err != nil {
 		log.Fatal(err)
 	}
+	err = writeErrorStatsMap(os.Stdout, sp.GetErrors())
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	if len(sp.args.hdrLatenciesFile) > 0 {
 		_, _ = fmt.Printf("Saving High Dynamic Range (HDR) Histogram of Response Latencies to %s\n", sp.args.hdrLatenciesFile)
@@ -194,6 +205,61 @@ func (sp *defaultStatProcessor) process(workers uint) {
 	sp.wg.Done()
 }
 
+// pushLatency adds the latency of a successful query (or part of a query)
+// to the stat groups of its label.
+func (sp *defaultStatProcessor) pushLatency(stat *Stat) {
+	if _, ok := sp.statMapping[string(stat.label)]; !ok {
+		sp.statMapping[string(stat.label)] = newStatGroup(*sp.args.limit)
+	}
+
+	sp.statMapping[string(stat.label)].push(stat.value)
+	if sp.args.prewarmQueries {
+		split := sp.coldMapping
+		if stat.isWarm {
+			split = sp.warmMapping
+		}
+		if _, ok := split[string(stat.label)]; !ok {
+			split[string(stat.label)] = newStatGroup(*sp.args.limit)
+		}
+		split[string(stat.label)].push(stat.value)
+	}
+
+	if !stat.isPartial {
+		sp.statMapping[labelAllQueries].push(stat.value)
+
+		// Only needed when differentiating between cold & warm
+		if sp.args.prewarmQueries {
+			if stat.isWarm {
+				sp.statMapping[labelWarmQueries].push(stat.value)
+			} else {
+				sp.statMapping[labelColdQueries].push(stat.value)
+			}
+		}
+	}
+}
+
+// countError counts a failed or timed out query for its label and for all
+// queries.
+func (sp *defaultStatProcessor) countError(stat *Stat) {
+	sp.errorsMu.Lock()
+	defer sp.errorsMu.Unlock()
+	if sp.errorMapping == nil {
+		sp.errorMapping = make(map[string]*errorCounts)
+	}
+	for _, label := range []string{string(stat.label), labelAllQueries} {
+		counts, ok := sp.errorMapping[label]
+		if !ok {
+			counts = &errorCounts{}
+			sp.errorMapping[label] = counts
+		}
+		if stat.isTimeout {
+			counts.timeouts++
+		} else {
+			counts.errors++
+		}
+	}
+}
+
 func generateQuantileMap(hist *hdrhistogram.Histogram) (int64, map[string]float64) {
 	ops := hist.TotalCount()
 	q0 := 0.0
@@ -241,6 +307,43 @@ func (sp *defaultStatProcessor) GetTotalsMap() map[string]interface{} {
 	return totals
 }
 
+// GetLatencies returns the latency summaries per query label. The cold and
+// warm split is only set when prewarming queries.
+func (sp *defaultStatProcessor) GetLatencies() (*LatencyResults, error) {
+	all, err := latencyStatsMap(sp.statMapping)
+	if err != nil {
+		return nil, err
+	}
+	cold, err := latencyStatsMap(sp.coldMapping)
+	if err != nil {
+		return nil, err
+	}
+	warm, err := latencyStatsMap(sp.warmMapping)
+	if err != nil {
+		return nil, err
+	}
+	return &LatencyResults{All: all, Cold: cold, Warm: warm}, nil
+}
+
+// GetErrors returns the number of failed and timed out queries per query
+// label, or nil if no query failed.
+func (sp *defaultStatProcessor) GetErrors() map[string]*ErrorStats {
+	sp.errorsMu.Lock()
+	defer sp.errorsMu.Unlock()
+	if len(sp.errorMapping) == 0 {
+		return nil
+	}
+	errors := make(map[string]*ErrorStats, len(sp.errorMapping))
+	for label, counts := range sp.errorMapping {
+		var succeeded int64
+		if sg, ok := sp.statMapping[label]; ok {
+			succeeded = sg.count
+		}
+		errors[label] = newErrorStats(counts, succeeded)
+	}
+	return errors
+}
+
 func stripRegex(in string) string {
 	reg, _ := regexp.Compile("[^a-zA-Z0-9]+")
 	return reg.ReplaceAllString(in, "_")
@@ -77,3 +77,108 @@ func TestStatProcessorSendWarm(t *testing.T) {
 		t.Errorf("empty stat array changed channel length: got %d want %d", got, wantLen)
 	}
 }
+
+func TestStatProcessorGetLatencies(t *testing.T) {
+	cases := []struct {
+		desc           string
+		prewarmQueries bool
+	}{
+		{desc: "without prewarm"},
+		{desc: "with prewarm", prewarmQueries: true},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			limit := uint64(0)
+			sp := newStatProcessor(&statProcessorArgs{
+				prewarmQueries: c.prewarmQueries,
+				limit:          &limit,
+			}).(*defaultStatProcessor)
+			go sp.process(1)
+			// wait for the channel to be created by process
+			for sp.c == nil {
+				time.Sleep(time.Millisecond)
+			}
+			for i := 0; i < 3; i++ {
+				sp.send([]*Stat{GetStat().Init([]byte("foo"), 10)})
+				if c.prewarmQueries {
+					sp.sendWarm([]*Stat{GetStat().Init([]byte("foo"), 1)})
+				}
+			}
+			sp.CloseAndWait()
+
+			latencies, err := sp.GetLatencies()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			wantCount := int64(3)
+			if c.prewarmQueries {
+				wantCount = 6
+			}
+			if got := latencies.All["foo"].Count; got != wantCount {
+				t.Errorf("incorrect count: got %d want %d", got, wantCount)
+			}
+			if !c.prewarmQueries {
+				if latencies.Cold != nil || latencies.Warm != nil {
+					t.Errorf("cold/warm split set without prewarm")
+				}
+				return
+			}
+			if got := latencies.Cold["foo"]; got.Count != 3 || got.Max != 10 {
+				t.Errorf("incorrect cold latencies: got %+v", got)
+			}
+			if got := latencies.Warm["foo"]; got.Count != 3 || got.Max != 1 {
+				t.Errorf("incorrect warm latencies: got %+v", got)
+			}
+		})
+	}
+}
+
+func TestStatProcessorGetErrors(t *testing.T) {
+	limit := uint64(0)
+	sp := newStatProcessor(&statProcessorArgs{limit: &limit}).(*defaultStatProcessor)
+	go sp.process(1)
+	// wait for the channel to be created by process
+	for sp.c == nil {
+		time.Sleep(time.Millisecond)
+	}
+	if sp.GetErrors() != nil {
+		t.Errorf("errors set without failed queries")
+	}
+	sp.send([]*Stat{GetStat().Init([]byte("foo"), 10)})
+	sp.send([]*Stat{GetStat().Init([]byte("foo"), 10)})
+	sp.sendError([]byte("foo"), false, false)
+	sp.sendError([]byte("foo"), false, true)
+	sp.sendError([]byte("bar"), false, true)
+	sp.CloseAndWait()
+
+	errors := sp.GetErrors()
+	cases := []struct {
+		label string
+		want  ErrorStats
+	}{
+		{label: "foo", want: ErrorStats{Errors: 1, Timeouts: 1, ErrorRate: 0.5}},
+		{label: "bar", want: ErrorStats{Timeouts: 1, ErrorRate: 1}},
+		{label: labelAllQueries, want: ErrorStats{Errors: 1, Timeouts: 2, ErrorRate: 0.6}},
+	}
+	for _, c := range cases {
+		got, ok := errors[c.label]
+		if !ok {
+			t.Errorf("missing errors for %s", c.label)
+			continue
+		}
+		if *got != c.want {
+			t.Errorf("incorrect errors for %s: got %+v want %+v", c.label, *got, c.want)
+		}
+	}
+	// failed queries have no latency
+	latencies, err := sp.GetLatencies()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := latencies.All["foo"].Count; got != 2 {
+		t.Errorf("incorrect latency count: got %d want 2", got)
+	}
+	if _, ok := latencies.All["bar"]; ok {
+		t.Errorf("latencies set for query that never succeeded")
+	}
+}
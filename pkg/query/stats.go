@@ -20,6 +20,8 @@ type Stat struct {
 	value     float64
 	isWarm    bool
 	isPartial bool
+	isError   bool
+	isTimeout bool
 }
 
 var statPool = &sync.Pool{
@@ -49,6 +51,8 @@ func (s *Stat) Init(label []byte, value float64) *Stat {
 	s.label = append(s.label, label...)
 	s.value = value
 	s.isWarm = false
+	s.isError = false
+	s.isTimeout = false
 	return s
 }
 
@@ -57,6 +61,8 @@ func (s *Stat) reset() *Stat {
 	s.value = 0.0
 	s.isWarm = false
 	s.isPartial = false
+	s.isError = false
+	s.isTimeout = false
 	return s
 }
 
@@ -132,6 +138,62 @@ func (s *statGroup) StdDev() float64 {
 	return float64(s.latencyHDRHistogram.StdDev()) / hdrScaleFactor
 }
 
+// LatencyStats summarizes the latencies of a group of queries. All latencies
+// are in milliseconds.
+type LatencyStats struct {
+	Count  int64   `json:"count"`
+	Min    float64 `json:"min"`
+	Mean   float64 `json:"mean"`
+	Max    float64 `json:"max"`
+	StdDev float64 `json:"stddev"`
+	P50    float64 `json:"p50"`
+	P90    float64 `json:"p90"`
+	P95    float64 `json:"p95"`
+	P99    float64 `json:"p99"`
+	P999   float64 `json:"p999"`
+	// Histogram is the base64 encoded, compressed HDR histogram of the
+	// latencies in microseconds, which can be decoded with hdrhistogram.Decode
+	Histogram string `json:"histogram"`
+}
+
+// latencyStats returns the summary of the latencies of the statGroup.
+func (s *statGroup) latencyStats() (*LatencyStats, error) {
+	h := s.latencyHDRHistogram
+	encoded, err := h.Encode(hdrhistogram.V2CompressedEncodingCookieBase)
+	if err != nil {
+		return nil, err
+	}
+	return &LatencyStats{
+		Count:     s.count,
+		Min:       s.Min(),
+		Mean:      s.Mean(),
+		Max:       s.Max(),
+		StdDev:    s.StdDev(),
+		P50:       s.Median(),
+		P90:       float64(h.ValueAtQuantile(90.0)) / hdrScaleFactor,
+		P95:       float64(h.ValueAtQuantile(95.0)) / hdrScaleFactor,
+		P99:       float64(h.ValueAtQuantile(99.0)) / hdrScaleFactor,
+		P999:      float64(h.ValueAtQuantile(99.9)) / hdrScaleFactor,
+		Histogram: string(encoded),
+	}, nil
+}
+
+// latencyStatsMap returns the latency summaries of a map of statGroups.
+func latencyStatsMap(statGroups map[string]*statGroup) (map[string]*LatencyStats, error) {
+	if statGroups == nil {
+		return nil, nil
+	}
+	latencies := make(map[string]*LatencyStats, len(statGroups))
+	for k, v := range statGroups {
+		l, err := v.latencyStats()
+		if err != nil {
+			return nil, err
+		}
+		latencies[k] = l
+	}
+	return latencies, nil
+}
+
 // writeStatGroupMap writes a map of StatGroups in an ordered fashion by
 // key that they are stored by
 func writeStatGroupMap(w io.Writer, statGroups map[string]*statGroup) error {
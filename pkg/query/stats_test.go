@@ -4,8 +4,11 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"math"
 	"strings"
 	"testing"
+
+	"github.com/HdrHistogram/hdrhistogram-go"
 )
 
 func TestGetPartialStat(t *testing.T) {
@@ -405,3 +408,45 @@ func TestWriteStatGroupMap(t *testing.T) {
 		}
 	}
 }
+
+func TestStatGroupLatencyStats(t *testing.T) {
+	sg := newStatGroup(0)
+	for i := 1; i <= 1000; i++ {
+		sg.push(float64(i))
+	}
+	l, err := sg.latencyStats()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if l.Count != 1000 {
+		t.Errorf("incorrect count: got %d want %d", l.Count, 1000)
+	}
+	checks := []struct {
+		desc string
+		got  float64
+		want float64
+	}{
+		{"min", l.Min, 1},
+		{"max", l.Max, 1000},
+		{"mean", l.Mean, 500.5},
+		{"p50", l.P50, 500},
+		{"p90", l.P90, 900},
+		{"p95", l.P95, 950},
+		{"p99", l.P99, 990},
+		{"p999", l.P999, 999},
+	}
+	for _, c := range checks {
+		// HDR histogram keeps 4 significant digits
+		if math.Abs(c.got-c.want) > c.want*1e-3 {
+			t.Errorf("incorrect %s: got %v want %v", c.desc, c.got, c.want)
+		}
+	}
+
+	decoded, err := hdrhistogram.Decode([]byte(l.Histogram))
+	if err != nil {
+		t.Fatalf("could not decode histogram: %v", err)
+	}
+	if got := decoded.TotalCount(); got != 1000 {
+		t.Errorf("incorrect decoded histogram count: got %d want %d", got, 1000)
+	}
+}
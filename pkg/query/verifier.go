@@ -0,0 +1,202 @@
+package query
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+)
+
+const defaultVerifyTolerance = 1e-6
+
+// ResultProcessor is a Processor that can also return the normalized result
+// set of a query, which is needed to verify the correctness of the results.
+type ResultProcessor interface {
+	Processor
+
+	// ProcessQueryWithResult handles a given query like ProcessQuery, and also
+	// returns the normalized result set of the query
+	ProcessQueryWithResult(q Query, isWarm bool) ([]*Stat, *ResultSet, error)
+}
+
+// referenceResult is a single entry of a results file, stored as one JSON
+// object per line.
+type referenceResult struct {
+	ID          uint64     `json:"id"`
+	Label       string     `json:"label"`
+	Description string     `json:"description"`
+	Result      *ResultSet `json:"result"`
+}
+
+// VerificationStats holds the outcome of result verification for a group of
+// queries.
+type VerificationStats struct {
+	Verified   uint64 `json:"verified"`
+	Mismatched uint64 `json:"mismatched"`
+	Missing    uint64 `json:"missing"`
+}
+
+// resultVerifier compares query results with the results read from a
+// reference file and/or records them to a file that can later be used as a
+// reference.
+type resultVerifier struct {
+	tolerance float64
+	out       io.Writer
+	reference map[uint64]*referenceResult
+
+	mu         sync.Mutex
+	recordFile *os.File
+	recordBuf  *bufio.Writer
+	stats      map[string]*VerificationStats
+}
+
+func newResultVerifier(referenceFileName, recordFileName string, tolerance float64) (*resultVerifier, error) {
+	if tolerance <= 0 {
+		tolerance = defaultVerifyTolerance
+	}
+	v := &resultVerifier{
+		tolerance: tolerance,
+		out:       os.Stderr,
+		stats:     make(map[string]*VerificationStats),
+	}
+
+	if len(referenceFileName) > 0 {
+		reference, err := readReferenceResults(referenceFileName)
+		if err != nil {
+			return nil, err
+		}
+		v.reference = reference
+	}
+
+	if len(recordFileName) > 0 {
+		f, err := os.Create(recordFileName)
+		if err != nil {
+			return nil, fmt.Errorf("cannot open results file for write %s: %v", recordFileName, err)
+		}
+		v.recordFile = f
+		v.recordBuf = bufio.NewWriter(f)
+	}
+	return v, nil
+}
+
+func readReferenceResults(fileName string) (map[uint64]*referenceResult, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open reference results file %s: %v", fileName, err)
+	}
+	defer f.Close()
+
+	reference := make(map[uint64]*referenceResult)
+	decoder := json.NewDecoder(bufio.NewReader(f))
+	for {
+		r := &referenceResult{}
+		err := decoder.Decode(r)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("cannot decode reference results file %s: %v", fileName, err)
+		}
+		if r.Result != nil {
+			r.Result.Normalize()
+		}
+		reference[r.ID] = r
+	}
+	return reference, nil
+}
+
+// check verifies the result set of a query against the reference and, if
+// enabled, records it.
+func (v *resultVerifier) check(q Query, rs *ResultSet) {
+	if rs == nil {
+		rs = &ResultSet{}
+	}
+	rs.Normalize()
+	label := string(q.HumanLabelName())
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.recordBuf != nil {
+		line, err := json.Marshal(&referenceResult{
+			ID:          q.GetID(),
+			Label:       label,
+			Description: string(q.HumanDescriptionName()),
+			Result:      rs,
+		})
+		if err != nil {
+			panic(err)
+		}
+		v.recordBuf.Write(line)
+		v.recordBuf.WriteByte('\n')
+	}
+
+	if v.reference == nil {
+		return
+	}
+	stats, ok := v.stats[label]
+	if !ok {
+		stats = &VerificationStats{}
+		v.stats[label] = stats
+	}
+
+	ref, ok := v.reference[q.GetID()]
+	if !ok || ref.Label != label || ref.Result == nil {
+		stats.Missing++
+		return
+	}
+	if diff := rs.Diff(ref.Result, v.tolerance); diff != "" {
+		stats.Mismatched++
+		fmt.Fprintf(v.out, "verify: query %d (%s) does not match reference: %s\n", q.GetID(), label, diff)
+		return
+	}
+	stats.Verified++
+}
+
+// totals returns the verification stats per query label. It returns nil if
+// results were not compared against a reference.
+func (v *resultVerifier) totals() map[string]*VerificationStats {
+	if v.reference == nil {
+		return nil
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	totals := make(map[string]*VerificationStats, len(v.stats))
+	for label, s := range v.stats {
+		copied := *s
+		totals[label] = &copied
+	}
+	return totals
+}
+
+// summary writes the verification stats for each query label to w.
+func (v *resultVerifier) summary(w io.Writer) {
+	totals := v.totals()
+	if totals == nil {
+		return
+	}
+	labels := make([]string, 0, len(totals))
+	for label := range totals {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+
+	fmt.Fprintf(w, "Result verification:\n")
+	for _, label := range labels {
+		s := totals[label]
+		fmt.Fprintf(w, "%s: verified %d, mismatched %d, missing reference %d\n", label, s.Verified, s.Mismatched, s.Missing)
+	}
+}
+
+// close flushes and closes the recorded results file, if any.
+func (v *resultVerifier) close() error {
+	if v.recordBuf == nil {
+		return nil
+	}
+	if err := v.recordBuf.Flush(); err != nil {
+		return err
+	}
+	return v.recordFile.Close()
+}
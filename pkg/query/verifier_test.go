@@ -0,0 +1,173 @@
+package query
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewResultRow(t *testing.T) {
+	ts := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	row := NewResultRow("host_1", ts, 1.5, int64(2), "3.5", []byte("rack"), true, nil, uint8(4), int16(5))
+
+	if got := strings.Join(row.Tags, ","); got != "host_1,rack" {
+		t.Errorf("incorrect tags: got %s want %s", got, "host_1,rack")
+	}
+	if row.Timestamp != ts.UnixNano() {
+		t.Errorf("incorrect timestamp: got %d want %d", row.Timestamp, ts.UnixNano())
+	}
+	want := []float64{1.5, 2, 3.5, 1, 4, 5}
+	if len(row.Values) != len(want) {
+		t.Fatalf("incorrect number of values: got %d want %d", len(row.Values), len(want))
+	}
+	for i := range want {
+		if row.Values[i] != want[i] {
+			t.Errorf("incorrect value %d: got %v want %v", i, row.Values[i], want[i])
+		}
+	}
+}
+
+func TestResultSetDiff(t *testing.T) {
+	reference := &ResultSet{Rows: []ResultRow{
+		{Tags: []string{"host_0"}, Timestamp: 1, Values: []float64{1.0}},
+		{Tags: []string{"host_1"}, Timestamp: 1, Values: []float64{2.0}},
+	}}
+	reference.Normalize()
+
+	cases := []struct {
+		desc     string
+		rows     []ResultRow
+		wantDiff string
+	}{
+		{
+			desc: "same rows in different order",
+			rows: []ResultRow{
+				{Tags: []string{"host_1"}, Timestamp: 1, Values: []float64{2.0}},
+				{Tags: []string{"host_0"}, Timestamp: 1, Values: []float64{1.0}},
+			},
+		},
+		{
+			desc: "values within tolerance",
+			rows: []ResultRow{
+				{Tags: []string{"host_0"}, Timestamp: 1, Values: []float64{1.0000000001}},
+				{Tags: []string{"host_1"}, Timestamp: 1, Values: []float64{2.0}},
+			},
+		},
+		{
+			desc: "missing row",
+			rows: []ResultRow{
+				{Tags: []string{"host_0"}, Timestamp: 1, Values: []float64{1.0}},
+			},
+			wantDiff: "row count mismatch",
+		},
+		{
+			desc: "different group key",
+			rows: []ResultRow{
+				{Tags: []string{"host_0"}, Timestamp: 1, Values: []float64{1.0}},
+				{Tags: []string{"host_2"}, Timestamp: 1, Values: []float64{2.0}},
+			},
+			wantDiff: "row 1: group keys mismatch",
+		},
+		{
+			desc: "different timestamp",
+			rows: []ResultRow{
+				{Tags: []string{"host_0"}, Timestamp: 2, Values: []float64{1.0}},
+				{Tags: []string{"host_1"}, Timestamp: 1, Values: []float64{2.0}},
+			},
+			wantDiff: "row 0: timestamp mismatch",
+		},
+		{
+			desc: "different value",
+			rows: []ResultRow{
+				{Tags: []string{"host_0"}, Timestamp: 1, Values: []float64{1.0}},
+				{Tags: []string{"host_1"}, Timestamp: 1, Values: []float64{2.1}},
+			},
+			wantDiff: "row 1: value 0 mismatch",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			rs := &ResultSet{Rows: c.rows}
+			rs.Normalize()
+			diff := rs.Diff(reference, defaultVerifyTolerance)
+			if c.wantDiff == "" && diff != "" {
+				t.Errorf("unexpected diff: %s", diff)
+			} else if !strings.HasPrefix(diff, c.wantDiff) {
+				t.Errorf("incorrect diff: got %q want prefix %q", diff, c.wantDiff)
+			}
+		})
+	}
+}
+
+func TestResultVerifierRecordAndVerify(t *testing.T) {
+	dir, err := ioutil.TempDir("", "verify")
+	if err != nil {
+		t.Fatalf("could not create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	fileName := filepath.Join(dir, "results.json")
+
+	newQuery := func(id uint64, label string) Query {
+		q := NewHTTP()
+		q.SetID(id)
+		q.HumanLabel = []byte(label)
+		return q
+	}
+	resultFor := func(v float64) *ResultSet {
+		return &ResultSet{Rows: []ResultRow{{Tags: []string{"host_0"}, Timestamp: 1, Values: []float64{v}}}}
+	}
+
+	recorder, err := newResultVerifier("", fileName, 0)
+	if err != nil {
+		t.Fatalf("could not create recorder: %v", err)
+	}
+	recorder.check(newQuery(0, "foo"), resultFor(1))
+	recorder.check(newQuery(1, "foo"), resultFor(2))
+	recorder.check(newQuery(2, "bar"), resultFor(3))
+	if err := recorder.close(); err != nil {
+		t.Fatalf("could not close recorder: %v", err)
+	}
+	if recorder.totals() != nil {
+		t.Errorf("recorder should not have verification totals")
+	}
+
+	verifier, err := newResultVerifier(fileName, "", 0)
+	if err != nil {
+		t.Fatalf("could not create verifier: %v", err)
+	}
+	verifier.out = &bytes.Buffer{}
+	verifier.check(newQuery(0, "foo"), resultFor(1))
+	verifier.check(newQuery(1, "foo"), resultFor(5))
+	verifier.check(newQuery(2, "bar"), resultFor(3))
+	verifier.check(newQuery(3, "bar"), resultFor(3))
+
+	totals := verifier.totals()
+	want := map[string]VerificationStats{
+		"foo": {Verified: 1, Mismatched: 1},
+		"bar": {Verified: 1, Missing: 1},
+	}
+	for label, w := range want {
+		got, ok := totals[label]
+		if !ok {
+			t.Errorf("missing totals for label %s", label)
+			continue
+		}
+		if *got != w {
+			t.Errorf("incorrect totals for label %s: got %+v want %+v", label, *got, w)
+		}
+	}
+	if !strings.Contains(verifier.out.(*bytes.Buffer).String(), "query 1 (foo) does not match") {
+		t.Errorf("mismatch was not reported: %s", verifier.out.(*bytes.Buffer).String())
+	}
+
+	var summary bytes.Buffer
+	verifier.summary(&summary)
+	if !strings.Contains(summary.String(), "foo: verified 1, mismatched 1, missing reference 0") {
+		t.Errorf("incorrect summary: %s", summary.String())
+	}
+}
@@ -1,28 +1,47 @@
 package akumuli
 
 import (
+	"bytes"
 	"sync"
 
 	"github.com/bodhiye/tsbs/load"
+	"github.com/bodhiye/tsbs/pkg/data/source"
 	"github.com/bodhiye/tsbs/pkg/targets"
+	"github.com/bodhiye/tsbs/tools/inputs"
 )
 
-func NewBenchmark(loadFileName, endpoint string, bufPool *sync.Pool) targets.Benchmark {
-	return &benchmark{
-		loadFileName: loadFileName,
-		endpoint:     endpoint,
-		bufPool:      bufPool,
+func NewBenchmark(dbSpecificConfig *SpecificConfig, dataSourceConfig *source.DataSourceConfig) (targets.Benchmark, error) {
+	var ds targets.DataSource
+	if dataSourceConfig.Type == source.FileDataSourceType {
+		ds = newFileDataSource(load.GetBufferedReader(dataSourceConfig.File.Location))
+	} else {
+		dataGenerator := &inputs.DataGenerator{}
+		simulator, err := dataGenerator.CreateSimulator(dataSourceConfig.Simulator)
+		if err != nil {
+			return nil, err
+		}
+		ds = targets.NewSimulationDataSource(simulator, &Serializer{}, false, newFileDataSource)
 	}
+
+	return &benchmark{
+		ds:       ds,
+		endpoint: dbSpecificConfig.Endpoint,
+		bufPool: &sync.Pool{
+			New: func() interface{} {
+				return bytes.NewBuffer(make([]byte, 0, 4*1024*1024))
+			},
+		},
+	}, nil
 }
 
 type benchmark struct {
-	loadFileName string
-	endpoint     string
-	bufPool      *sync.Pool
+	ds       targets.DataSource
+	endpoint string
+	bufPool  *sync.Pool
 }
 
 func (b *benchmark) GetDataSource() targets.DataSource {
-	return &fileDataSource{reader: load.GetBufferedReader(b.loadFileName)}
+	return b.ds
 }
 
 func (b *benchmark) GetBatchFactory() targets.BatchFactory {
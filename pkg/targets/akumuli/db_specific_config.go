@@ -0,0 +1,15 @@
+package akumuli
+
+import "github.com/spf13/viper"
+
+type SpecificConfig struct {
+	Endpoint string `yaml:"endpoint" mapstructure:"endpoint"`
+}
+
+func parseSpecificConfig(v *viper.Viper) (*SpecificConfig, error) {
+	var conf SpecificConfig
+	if err := v.Unmarshal(&conf); err != nil {
+		return nil, err
+	}
+	return &conf, nil
+}
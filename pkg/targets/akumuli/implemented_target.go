@@ -1,9 +1,12 @@
 package akumuli
 
 import (
+	"sync"
+
 	"github.com/spf13/viper"
 	"github.com/bodhiye/tsbs/pkg/data/serialize"
 	"github.com/bodhiye/tsbs/pkg/data/source"
+	"github.com/bodhiye/tsbs/pkg/query"
 	"github.com/bodhiye/tsbs/pkg/targets"
 	"github.com/bodhiye/tsbs/pkg/targets/constants"
 	"github.com/spf13/pflag"
@@ -28,6 +31,28 @@ func (t *akumuliTarget) Serializer() serialize.PointSerializer {
 	return &Serializer{}
 }
 
-func (t *akumuliTarget) Benchmark(string, *source.DataSourceConfig, *viper.Viper) (targets.Benchmark, error) {
-	panic("not implemented")
+func (t *akumuliTarget) Benchmark(_ string, dataSourceConfig *source.DataSourceConfig, v *viper.Viper) (targets.Benchmark, error) {
+	akumuliSpecificConfig, err := parseSpecificConfig(v)
+	if err != nil {
+		return nil, err
+	}
+	return NewBenchmark(akumuliSpecificConfig, dataSourceConfig)
+}
+
+func (t *akumuliTarget) QueryPool() *sync.Pool {
+	return &query.HTTPPool
+}
+
+func (t *akumuliTarget) QueryProcessorCreate(
+	_ string, runnerConfig *query.BenchmarkRunnerConfig, v *viper.Viper,
+) (query.ProcessorCreate, error) {
+	return NewQueryProcessorCreate(&QueryOptions{
+		Endpoint:       v.GetString("query-endpoint"),
+		Debug:          runnerConfig.Debug,
+		PrintResponses: runnerConfig.PrintResponses,
+	}), nil
+}
+
+func (t *akumuliTarget) QueryFlags(flagPrefix string, flagSet *pflag.FlagSet) {
+	flagSet.String(flagPrefix+"query-endpoint", "http://localhost:8181", "Akumuli HTTP API endpoint to run queries against.")
 }
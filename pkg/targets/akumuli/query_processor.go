@@ -0,0 +1,44 @@
+package akumuli
+
+import (
+	"github.com/bodhiye/tsbs/pkg/query"
+)
+
+// QueryOptions configures the processors that run queries against Akumuli.
+type QueryOptions struct {
+	// Endpoint is the address of the HTTP API of Akumuli
+	Endpoint       string
+	Debug          int
+	PrintResponses bool
+}
+
+// NewQueryProcessorCreate returns a function creating query processors that
+// run Akumuli queries with the given options.
+func NewQueryProcessorCreate(opts *QueryOptions) query.ProcessorCreate {
+	return func() query.Processor { return &queryProcessor{opts: opts} }
+}
+
+type queryProcessor struct {
+	w      *HTTPClient
+	opts   *QueryOptions
+	doOpts *HTTPClientDoOptions
+}
+
+func (p *queryProcessor) Init(workerNumber int) {
+	p.doOpts = &HTTPClientDoOptions{
+		Debug:          p.opts.Debug,
+		PrintResponses: p.opts.PrintResponses,
+	}
+	p.w = NewHTTPClient(p.opts.Endpoint)
+}
+
+func (p *queryProcessor) ProcessQuery(q query.Query, _ bool) ([]*query.Stat, error) {
+	hq := q.(*query.HTTP)
+	lag, err := p.w.Do(hq, p.doOpts)
+	if err != nil {
+		return nil, err
+	}
+	stat := query.GetStat()
+	stat.Init(q.HumanLabelName(), lag)
+	return []*query.Stat{stat}, nil
+}
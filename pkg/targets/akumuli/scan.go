@@ -12,6 +12,10 @@ import (
 	"github.com/bodhiye/tsbs/pkg/targets"
 )
 
+func newFileDataSource(r *bufio.Reader) targets.DataSource {
+	return &fileDataSource{reader: r}
+}
+
 type fileDataSource struct {
 	reader *bufio.Reader
 }
@@ -1,27 +1,22 @@
 package cassandra
 
 import (
-	"bufio"
-	"errors"
 	"fmt"
 	"log"
 
 	"github.com/bodhiye/tsbs/load"
 	"github.com/bodhiye/tsbs/pkg/data/source"
 	"github.com/bodhiye/tsbs/pkg/targets"
+	"github.com/bodhiye/tsbs/tools/inputs"
 	"github.com/gocql/gocql"
 )
 
 type benchmark struct {
-	dbc                *dbCreator
-	dataSourceFileName string
+	dbc *dbCreator
+	ds  targets.DataSource
 }
 
-func NewBenchmark(dbSpecificConfig *SpecificConfig, dsConfig *source.DataSourceConfig) (targets.Benchmark, error) {
-	if dsConfig.Type != source.FileDataSourceType {
-		return nil, errors.New("only FILE data source implemented for Cassandra")
-	}
-
+func NewBenchmark(dbSpecificConfig *SpecificConfig, dataSourceConfig *source.DataSourceConfig) (targets.Benchmark, error) {
 	if _, ok := consistencyMapping[dbSpecificConfig.ConsistencyLevel]; !ok {
 		return nil, fmt.Errorf(
 			"invalid consistency level %s; allowed: %v",
@@ -29,6 +24,19 @@ func NewBenchmark(dbSpecificConfig *SpecificConfig, dsConfig *source.DataSourceC
 			consistencyMapping,
 		)
 	}
+
+	var ds targets.DataSource
+	if dataSourceConfig.Type == source.FileDataSourceType {
+		ds = newFileDataSource(load.GetBufferedReader(dataSourceConfig.File.Location))
+	} else {
+		dataGenerator := &inputs.DataGenerator{}
+		simulator, err := dataGenerator.CreateSimulator(dataSourceConfig.Simulator)
+		if err != nil {
+			return nil, err
+		}
+		ds = targets.NewSimulationDataSource(simulator, &Serializer{}, false, newFileDataSource)
+	}
+
 	return &benchmark{
 		dbc: &dbCreator{
 			hosts:             dbSpecificConfig.Hosts,
@@ -36,12 +44,12 @@ func NewBenchmark(dbSpecificConfig *SpecificConfig, dsConfig *source.DataSourceC
 			replicationFactor: dbSpecificConfig.ReplicationFactor,
 			writeTimeout:      dbSpecificConfig.WriteTimeout,
 		},
-		dataSourceFileName: dsConfig.File.Location,
+		ds: ds,
 	}, nil
 }
 
 func (b *benchmark) GetDataSource() targets.DataSource {
-	return &fileDataSource{scanner: bufio.NewScanner(load.GetBufferedReader(b.dataSourceFileName))}
+	return b.ds
 }
 
 func (b *benchmark) GetBatchFactory() targets.BatchFactory {
@@ -1,11 +1,14 @@
 package cassandra
 
 import (
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/spf13/viper"
 	"github.com/bodhiye/tsbs/pkg/data/serialize"
 	"github.com/bodhiye/tsbs/pkg/data/source"
+	"github.com/bodhiye/tsbs/pkg/query"
 	"github.com/bodhiye/tsbs/pkg/targets"
 	"github.com/bodhiye/tsbs/pkg/targets/constants"
 	"github.com/spf13/pflag"
@@ -33,6 +36,38 @@ func (t *cassandraTarget) Serializer() serialize.PointSerializer {
 	return &Serializer{}
 }
 
-func (t *cassandraTarget) Benchmark(string, *source.DataSourceConfig, *viper.Viper) (targets.Benchmark, error) {
-	panic("not implemented")
+func (t *cassandraTarget) Benchmark(_ string, dataSourceConfig *source.DataSourceConfig, v *viper.Viper) (targets.Benchmark, error) {
+	cassandraSpecificConfig, err := parseSpecificConfig(v)
+	if err != nil {
+		return nil, err
+	}
+	return NewBenchmark(cassandraSpecificConfig, dataSourceConfig)
+}
+
+func (t *cassandraTarget) QueryPool() *sync.Pool {
+	return &query.CassandraPool
+}
+
+func (t *cassandraTarget) QueryProcessorCreate(
+	targetDB string, runnerConfig *query.BenchmarkRunnerConfig, v *viper.Viper,
+) (query.ProcessorCreate, error) {
+	cassandraSpecificConfig, err := parseSpecificConfig(v)
+	if err != nil {
+		return nil, err
+	}
+	return NewQueryProcessorCreate(&QueryOptions{
+		Hosts:                  strings.Split(cassandraSpecificConfig.Hosts, ","),
+		Keyspace:               targetDB,
+		AggregationPlan:        v.GetString("aggregation-plan"),
+		ReadTimeout:            v.GetDuration("read-timeout"),
+		ClientSideIndexTimeout: v.GetDuration("client-side-index-timeout"),
+		Debug:                  runnerConfig.Debug,
+		PrintResponses:         runnerConfig.PrintResponses,
+	})
+}
+
+func (t *cassandraTarget) QueryFlags(flagPrefix string, flagSet *pflag.FlagSet) {
+	flagSet.String(flagPrefix+"aggregation-plan", "server", "Aggregation plan (choices: server, client)")
+	flagSet.Duration(flagPrefix+"read-timeout", 1*time.Second, "Maximum request timeout.")
+	flagSet.Duration(flagPrefix+"client-side-index-timeout", 10*time.Second, "Maximum client-side index timeout (only used at initialization).")
 }
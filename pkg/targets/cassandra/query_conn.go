@@ -1,4 +1,4 @@
-package main
+package cassandra
 
 import (
 	"log"
@@ -9,8 +9,8 @@ import (
 
 // NewCassandraSession creates a new Cassandra session. It is goroutine-safe
 // by default, and uses a connection pool.
-func NewCassandraSession(daemonURL, keyspace string, timeout time.Duration) *gocql.Session {
-	cluster := gocql.NewCluster(daemonURL)
+func NewCassandraSession(hosts []string, keyspace string, timeout time.Duration) *gocql.Session {
+	cluster := gocql.NewCluster(hosts...)
 	cluster.Keyspace = keyspace
 	cluster.Consistency = gocql.One
 	cluster.ProtoVersion = 4
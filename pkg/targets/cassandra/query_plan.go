@@ -1,4 +1,4 @@
-package main
+package cassandra
 
 import (
 	"fmt"
@@ -407,9 +407,12 @@ func (qp *QueryPlanForEvery) Execute(session *gocql.Session) ([]CQLResult, error
 	}
 
 	for _, q := range qp.cqlQueries {
-		iter := session.Query(q.PreparableQueryString, q.Args...).Iter()
-
 		rm := r.FindSubmatch([]byte(q.Args[0].(string)))
+		if rm == nil {
+			// Series without the tag, e.g. of iot trucks with no name,
+			// cannot be grouped
+			continue
+		}
 		key := string(rm[1])
 
 		// Only run the query if this forEveryTag has not been filled
@@ -425,6 +428,7 @@ func (qp *QueryPlanForEvery) Execute(session *gocql.Session) ([]CQLResult, error
 			continue
 		}
 
+		iter := session.Query(q.PreparableQueryString, q.Args...).Iter()
 		var timestampNs int64
 		var value float64
 		for iter.Scan(&timestampNs, &value) {
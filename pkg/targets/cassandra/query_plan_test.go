@@ -0,0 +1,25 @@
+package cassandra
+
+import (
+	"testing"
+)
+
+func TestQueryPlanForEveryExecuteWithoutTag(t *testing.T) {
+	cqlQueries := []CQLQuery{
+		NewCQLQuery("max", "series_double", "readings,fleet=South,driver=Trish#velocity#2016-01-01", "DESC", 0, 1),
+		NewCQLQuery("max", "series_double", "readings,fleet=North#velocity#2016-01-01", "DESC", 0, 1),
+	}
+	qp, err := NewQueryPlanForEvery([]string{"velocity"}, "name", 1, cqlQueries)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The series have no name tag, so no query is run on the nil session
+	results, err := qp.Execute(nil)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("unexpected results for series without the tag: %v", results)
+	}
+}
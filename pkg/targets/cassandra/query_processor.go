@@ -0,0 +1,149 @@
+package cassandra
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bodhiye/tsbs/pkg/query"
+	"github.com/gocql/gocql"
+)
+
+const (
+	BucketDuration   = 24 * time.Hour
+	BucketTimeLayout = "2006-01-02"
+)
+
+// Blessed tables that hold benchmark data:
+var (
+	BlessedTables = []string{
+		"series_bigint",
+		"series_float",
+		"series_double",
+		"series_boolean",
+		"series_blob",
+	}
+)
+
+// Helpers for choice-like flags:
+var (
+	aggrPlanChoices = map[string]int{
+		"server": AggrPlanTypeWithServerAggregation,
+		"client": AggrPlanTypeWithoutServerAggregation,
+	}
+)
+
+// QueryOptions configures the processors that run queries against
+// Cassandra.
+type QueryOptions struct {
+	// Hosts are the hostname and port combinations of the Cassandra nodes
+	Hosts    []string
+	Keyspace string
+	// AggregationPlan is where to aggregate, "server" or "client"
+	AggregationPlan        string
+	ReadTimeout            time.Duration
+	ClientSideIndexTimeout time.Duration
+	Debug                  int
+	PrintResponses         bool
+}
+
+// NewQueryProcessorCreate returns a function creating query processors that
+// run Cassandra queries with the given options. The processors share one
+// session and client-side index, built when the first one is initialized.
+func NewQueryProcessorCreate(opts *QueryOptions) (query.ProcessorCreate, error) {
+	aggrPlan, ok := aggrPlanChoices[opts.AggregationPlan]
+	if !ok {
+		return nil, fmt.Errorf("invalid aggregation plan '%s' (choices: server, client)", opts.AggregationPlan)
+	}
+	s := &querySession{opts: opts}
+	return func() query.Processor { return &queryProcessor{session: s, aggrPlan: aggrPlan} }, nil
+}
+
+// querySession holds the connection pool and client-side index shared by the
+// query processors. It is opened by the first processor and closed by the
+// last one.
+type querySession struct {
+	opts    *QueryOptions
+	mu      sync.Mutex
+	users   int
+	session *gocql.Session
+	csi     *ClientSideIndex
+}
+
+func (s *querySession) acquire() (*gocql.Session, *ClientSideIndex) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.users == 0 {
+		// Make client-side index:
+		session := NewCassandraSession(s.opts.Hosts, s.opts.Keyspace, s.opts.ClientSideIndexTimeout)
+		s.csi = NewClientSideIndex(FetchSeriesCollection(session))
+		session.Close()
+
+		// Make database connection pool:
+		s.session = NewCassandraSession(s.opts.Hosts, s.opts.Keyspace, s.opts.ReadTimeout)
+	}
+	s.users++
+	return s.session, s.csi
+}
+
+func (s *querySession) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.users--
+	if s.users == 0 {
+		s.session.Close()
+		s.session = nil
+	}
+}
+
+type queryProcessor struct {
+	session  *querySession
+	aggrPlan int
+	qe       *HLQueryExecutor
+	opts     *HLQueryExecutorDoOptions
+}
+
+func (p *queryProcessor) Init(workerNumber int) {
+	session, csi := p.session.acquire()
+	p.opts = &HLQueryExecutorDoOptions{
+		AggregationPlan:      p.aggrPlan,
+		Debug:                p.session.opts.Debug,
+		PrettyPrintResponses: p.session.opts.PrintResponses,
+	}
+	p.qe = NewHLQueryExecutor(session, csi, p.session.opts.Debug)
+}
+
+func (p *queryProcessor) Close() {
+	if p.qe != nil {
+		p.session.release()
+		p.qe = nil
+	}
+}
+
+func (p *queryProcessor) ProcessQuery(q query.Query, isWarm bool) ([]*query.Stat, error) {
+	cq := q.(*query.Cassandra)
+	hlq := &HLQuery{*cq}
+	hlq.ForceUTC()
+	labels := [][]byte{
+		q.HumanLabelName(),
+		append(q.HumanLabelName(), "-qp"...),
+		append(q.HumanLabelName(), "-req"...),
+	}
+	if isWarm {
+		for i, l := range labels {
+			labels[i] = append(l, " (warm)"...)
+		}
+	}
+	qpLagMs, reqLagMs, err := p.qe.Do(hlq, *p.opts)
+	if err != nil {
+		return nil, err
+	}
+	// total stat
+	totalMs := qpLagMs + reqLagMs
+	stats := []*query.Stat{
+		query.GetPartialStat().Init(labels[1], qpLagMs),
+		query.GetPartialStat().Init(labels[2], reqLagMs),
+		query.GetStat().Init(labels[0], totalMs),
+	}
+	return stats, nil
+}
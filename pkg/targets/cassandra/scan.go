@@ -12,8 +12,22 @@ import (
 	"github.com/bodhiye/tsbs/pkg/targets"
 )
 
+func newFileDataSource(r *bufio.Reader) targets.DataSource {
+	return &fileDataSource{scanner: targets.NewLineScanner(r)}
+}
+
 type fileDataSource struct {
-	scanner *bufio.Scanner
+	scanner *targets.LineScanner
+}
+
+// Offset implements targets.OffsetDataSource.
+func (d *fileDataSource) Offset() int64 {
+	return d.scanner.Offset()
+}
+
+// SkipTo implements targets.OffsetDataSource.
+func (d *fileDataSource) SkipTo(offset int64) error {
+	return d.scanner.SkipTo(offset)
 }
 
 // Reads and returns a CSV line that encodes a data point.
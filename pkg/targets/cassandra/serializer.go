@@ -31,8 +31,15 @@ func (s *Serializer) Serialize(p *data.Point, w io.Writer) (err error) {
 			seriesIDPrefix = append(seriesIDPrefix, tagKeys[i]...)
 			seriesIDPrefix = append(seriesIDPrefix, '=')
 			seriesIDPrefix = append(seriesIDPrefix, []byte(t)...)
+		case nil:
+			// missing tags, e.g. of some iot trucks, are left out of the series id
+			continue
 		default:
-			panic("non-string tags not implemented for cassandra")
+			// numeric tags, e.g. the iot truck capacities, are matched as strings
+			seriesIDPrefix = append(seriesIDPrefix, ',')
+			seriesIDPrefix = append(seriesIDPrefix, tagKeys[i]...)
+			seriesIDPrefix = append(seriesIDPrefix, '=')
+			seriesIDPrefix = serialize.FastFormatAppend(t, seriesIDPrefix)
 		}
 	}
 	timestamp := p.Timestamp()
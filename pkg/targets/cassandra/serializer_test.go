@@ -1,10 +1,21 @@
 package cassandra
 
 import (
+	"github.com/bodhiye/tsbs/pkg/data"
 	"github.com/bodhiye/tsbs/pkg/data/serialize"
 	"testing"
 )
 
+func testPointWithNumericTag() *data.Point {
+	p := data.NewPoint()
+	p.SetMeasurementName(serialize.TestMeasurement)
+	p.SetTimestamp(&serialize.TestNow)
+	p.AppendTag([]byte("hostname"), "host_0")
+	p.AppendTag([]byte("load_capacity"), float64(1500))
+	p.AppendField(serialize.TestColFloat, serialize.TestFloat)
+	return p
+}
+
 func TestCassandraSerializerSerialize(t *testing.T) {
 	cases := []serialize.SerializeCase{
 		{
@@ -22,6 +33,16 @@ func TestCassandraSerializerSerialize(t *testing.T) {
 			InputPoint: serialize.TestPointNoTags(),
 			Output:     "series_double,cpu,usage_guest_nice,2016-01-01,1451606400000000000,38.24311829\n",
 		},
+		{
+			Desc:       "a Point with a nil tag",
+			InputPoint: serialize.TestPointWithNilTag(),
+			Output:     "series_double,cpu,usage_guest_nice,2016-01-01,1451606400000000000,38.24311829\n",
+		},
+		{
+			Desc:       "a Point with a numeric tag",
+			InputPoint: testPointWithNumericTag(),
+			Output:     "series_double,cpu,hostname=host_0,load_capacity=1500,usage_guest_nice,2016-01-01,1451606400000000000,38.24311829\n",
+		},
 	}
 	serialize.SerializerTest(t, cases, &Serializer{})
 }
@@ -3,24 +3,38 @@ package clickhouse
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"log"
+	"sort"
 
 	"github.com/bodhiye/tsbs/load"
 	"github.com/bodhiye/tsbs/pkg/data"
+	"github.com/bodhiye/tsbs/pkg/data/source"
 	"github.com/bodhiye/tsbs/pkg/targets"
+	"github.com/bodhiye/tsbs/pkg/targets/timescaledb"
+	"github.com/bodhiye/tsbs/tools/inputs"
+	"github.com/spf13/viper"
 )
 
 const dbType = "clickhouse"
 
 type ClickhouseConfig struct {
-	Host     string
-	User     string
-	Password string
+	Host     string `yaml:"host" mapstructure:"host"`
+	User     string `yaml:"user" mapstructure:"user"`
+	Password string `yaml:"password" mapstructure:"password"`
+
+	LogBatches bool   `yaml:"log-batches" mapstructure:"log-batches"`
+	InTableTag bool   `yaml:"in-table-partition-tag" mapstructure:"in-table-partition-tag"`
+	Debug      int    `yaml:"debug" mapstructure:"debug"`
+	DbName     string `yaml:"-" mapstructure:"-"`
+}
 
-	LogBatches bool
-	InTableTag bool
-	Debug      int
-	DbName     string
+func parseSpecificConfig(v *viper.Viper) (*ClickhouseConfig, error) {
+	var conf ClickhouseConfig
+	if err := v.Unmarshal(&conf); err != nil {
+		return nil, err
+	}
+	return &conf, nil
 }
 
 // String values of tags and fields to insert - string representation
@@ -77,6 +91,29 @@ func (ta *tableArr) Append(item data.LoadedPoint) {
 	ta.cnt++
 }
 
+// targets.WriterToBatch interface implementation
+// Rows are written in the format they were read in, one table after the other
+func (ta *tableArr) WriteTo(w io.Writer) (int64, error) {
+	tables := make([]string, 0, len(ta.m))
+	for table := range ta.m {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	bw := bufio.NewWriter(w)
+	var n int64
+	for _, table := range tables {
+		for _, row := range ta.m[table] {
+			written, err := fmt.Fprintf(bw, "%s,%s\n%s,%s\n", tagsPrefix, row.tags, table, row.fields)
+			n += int64(written)
+			if err != nil {
+				return n, err
+			}
+		}
+	}
+	return n, bw.Flush()
+}
+
 // scan.BatchFactory interface implementation
 type factory struct{}
 
@@ -90,21 +127,29 @@ func (f *factory) New() targets.Batch {
 
 const tagsPrefix = "tags"
 
-func NewBenchmark(file string, hashWorkers bool, conf *ClickhouseConfig) targets.Benchmark {
-	return &benchmark{
-		ds: &fileDataSource{
-			scanner: bufio.NewScanner(load.GetBufferedReader(file)),
-		},
-		hashWorkers: hashWorkers,
-		conf:        conf,
+func NewBenchmark(conf *ClickhouseConfig, dataSourceConfig *source.DataSourceConfig) (targets.Benchmark, error) {
+	var ds targets.DataSource
+	if dataSourceConfig.Type == source.FileDataSourceType {
+		ds = newFileDataSource(load.GetBufferedReader(dataSourceConfig.File.Location))
+	} else {
+		dataGenerator := &inputs.DataGenerator{}
+		simulator, err := dataGenerator.CreateSimulator(dataSourceConfig.Simulator)
+		if err != nil {
+			return nil, err
+		}
+		ds = targets.NewSimulationDataSource(simulator, &timescaledb.Serializer{}, true, newFileDataSource)
 	}
+
+	return &benchmark{
+		ds:   ds,
+		conf: conf,
+	}, nil
 }
 
 // targets.Benchmark interface implementation
 type benchmark struct {
-	ds          targets.DataSource
-	hashWorkers bool
-	conf        *ClickhouseConfig
+	ds   targets.DataSource
+	conf *ClickhouseConfig
 }
 
 func (b *benchmark) GetDataSource() targets.DataSource {
@@ -116,7 +161,7 @@ func (b *benchmark) GetBatchFactory() targets.BatchFactory {
 }
 
 func (b *benchmark) GetPointIndexer(maxPartitions uint) targets.PointIndexer {
-	if b.hashWorkers {
+	if maxPartitions > 1 {
 		return &hostnameIndexer{
 			partitions: maxPartitions,
 		}
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/bodhiye/tsbs/pkg/data"
+	"github.com/bodhiye/tsbs/pkg/targets"
 )
 
 func TestGetConnectString(t *testing.T) {
@@ -66,6 +67,25 @@ func TestHypertableArr(t *testing.T) {
 	}
 }
 
+func TestTableArrWriteTo(t *testing.T) {
+	ta := (&factory{}).New().(*tableArr)
+	ta.Append(data.LoadedPoint{Data: &point{table: "table2", row: &insertData{tags: "t3,t4", fields: "1,f3,f4"}}})
+	ta.Append(data.LoadedPoint{Data: &point{table: "table1", row: &insertData{tags: "t1,t2", fields: "0,f1,f2"}}})
+
+	buf := &bytes.Buffer{}
+	n, err := ta.WriteTo(buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "tags,t1,t2\ntable1,0,f1,f2\ntags,t3,t4\ntable2,1,f3,f4\n"
+	if got := buf.String(); got != want {
+		t.Errorf("incorrect output: got\n%s\nwant\n%s", got, want)
+	}
+	if n != int64(len(want)) {
+		t.Errorf("incorrect byte count: got %d want %d", n, len(want))
+	}
+}
+
 func TestNextItem(t *testing.T) {
 	cases := []struct {
 		desc        string
@@ -95,7 +115,7 @@ func TestNextItem(t *testing.T) {
 	}
 	for _, c := range cases {
 		br := bufio.NewReader(bytes.NewReader([]byte(c.input)))
-		dataSource := &fileDataSource{scanner: bufio.NewScanner(br)}
+		dataSource := &fileDataSource{scanner: targets.NewLineScanner(br)}
 		if c.shouldFatal {
 			fmt.Println(c.desc)
 			isCalled := false
@@ -126,7 +146,7 @@ func TestNextItem(t *testing.T) {
 func TestDecodeEOF(t *testing.T) {
 	input := []byte("tags,tag1text,tag2text\ncpu,140,0.0,0.0\n")
 	br := bufio.NewReader(bytes.NewReader([]byte(input)))
-	dataSource := &fileDataSource{scanner: bufio.NewScanner(br)}
+	dataSource := &fileDataSource{scanner: targets.NewLineScanner(br)}
 	_ = dataSource.NextItem()
 	// nothing left, should be EOF
 	p := dataSource.NextItem()
@@ -191,7 +211,7 @@ func TestHeaders(t *testing.T) {
 
 	for _, c := range cases {
 		br := bufio.NewReader(bytes.NewReader([]byte(c.input)))
-		dataSource := &fileDataSource{bufio.NewScanner(br), nil}
+		dataSource := &fileDataSource{targets.NewLineScanner(br), nil}
 		if c.shouldFatal {
 			isCalled := false
 			fatal = func(fmt string, args ...interface{}) {
@@ -6,15 +6,32 @@ import (
 
 	"github.com/bodhiye/tsbs/pkg/data"
 	"github.com/bodhiye/tsbs/pkg/data/usecases/common"
+	"github.com/bodhiye/tsbs/pkg/targets"
 )
 
+func newFileDataSource(r *bufio.Reader) targets.DataSource {
+	return &fileDataSource{scanner: targets.NewLineScanner(r)}
+}
+
 // scan.PointDecoder interface implementation
 type fileDataSource struct {
-	scanner *bufio.Scanner
+	scanner *targets.LineScanner
 	//cached headers (should be read only at start of file)
 	headers *common.GeneratedDataHeaders
 }
 
+// Offset implements targets.OffsetDataSource.
+func (d *fileDataSource) Offset() int64 {
+	return d.scanner.Offset()
+}
+
+// SkipTo implements targets.OffsetDataSource.
+func (d *fileDataSource) SkipTo(offset int64) error {
+	// the headers are read first, as the points are skipped after them
+	d.Headers()
+	return d.scanner.SkipTo(offset)
+}
+
 // scan.PointDecoder interface implementation
 func (d *fileDataSource) NextItem() data.LoadedPoint {
 	// Data Point Example
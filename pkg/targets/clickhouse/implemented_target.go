@@ -1,9 +1,13 @@
 package clickhouse
 
 import (
+	"strings"
+	"sync"
+
 	"github.com/spf13/viper"
 	"github.com/bodhiye/tsbs/pkg/data/serialize"
 	"github.com/bodhiye/tsbs/pkg/data/source"
+	"github.com/bodhiye/tsbs/pkg/query"
 	"github.com/bodhiye/tsbs/pkg/targets"
 	"github.com/bodhiye/tsbs/pkg/targets/constants"
 	"github.com/bodhiye/tsbs/pkg/targets/timescaledb"
@@ -16,8 +20,13 @@ func NewTarget() targets.ImplementedTarget {
 
 type clickhouseTarget struct{}
 
-func (c clickhouseTarget) Benchmark(string, *source.DataSourceConfig, *viper.Viper) (targets.Benchmark, error) {
-	panic("implement me")
+func (c clickhouseTarget) Benchmark(targetDB string, dataSourceConfig *source.DataSourceConfig, v *viper.Viper) (targets.Benchmark, error) {
+	clickhouseSpecificConfig, err := parseSpecificConfig(v)
+	if err != nil {
+		return nil, err
+	}
+	clickhouseSpecificConfig.DbName = targetDB
+	return NewBenchmark(clickhouseSpecificConfig, dataSourceConfig)
 }
 
 func (c clickhouseTarget) Serializer() serialize.PointSerializer {
@@ -35,3 +44,27 @@ func (c clickhouseTarget) TargetSpecificFlags(flagPrefix string, flagSet *pflag.
 func (c clickhouseTarget) TargetName() string {
 	return constants.FormatClickhouse
 }
+
+func (c clickhouseTarget) QueryPool() *sync.Pool {
+	return &query.ClickHousePool
+}
+
+func (c clickhouseTarget) QueryProcessorCreate(
+	targetDB string, runnerConfig *query.BenchmarkRunnerConfig, v *viper.Viper,
+) (query.ProcessorCreate, error) {
+	clickhouseSpecificConfig, err := parseSpecificConfig(v)
+	if err != nil {
+		return nil, err
+	}
+	return NewQueryProcessorCreate(&QueryOptions{
+		// the host may be a comma separated list of hosts, for sharding reads
+		Hosts:          strings.Split(clickhouseSpecificConfig.Host, ","),
+		User:           clickhouseSpecificConfig.User,
+		Password:       clickhouseSpecificConfig.Password,
+		DBName:         targetDB,
+		Debug:          runnerConfig.Debug > 0,
+		PrintResponses: runnerConfig.PrintResponses,
+	}), nil
+}
+
+func (c clickhouseTarget) QueryFlags(string, *pflag.FlagSet) {}
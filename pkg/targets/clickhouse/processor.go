@@ -38,14 +38,28 @@ func (p *processor) Close(doLoad bool) {
 
 // load.Processor interface implementation
 func (p *processor) ProcessBatch(b targets.Batch, doLoad bool) (uint64, uint64) {
+	metricCnt, rowCnt, err := p.ProcessBatchWithError(b, doLoad)
+	if err != nil {
+		panic(err)
+	}
+	return metricCnt, rowCnt
+}
+
+// load.ProcessorWithError interface implementation
+// Tables are inserted one at a time and removed from the batch once inserted,
+// so that only the rest of it is retried if an insert fails.
+func (p *processor) ProcessBatchWithError(b targets.Batch, doLoad bool) (uint64, uint64, error) {
 	batches := b.(*tableArr)
 	rowCnt := 0
 	metricCnt := uint64(0)
 	for tableName, rows := range batches.m {
-		rowCnt += len(rows)
 		if doLoad {
 			start := time.Now()
-			metricCnt += p.processCSI(tableName, rows)
+			metrics, err := p.processCSI(tableName, rows)
+			if err != nil {
+				return metricCnt, uint64(rowCnt), err
+			}
+			metricCnt += metrics
 
 			if p.conf.LogBatches {
 				now := time.Now()
@@ -54,11 +68,14 @@ func (p *processor) ProcessBatch(b targets.Batch, doLoad bool) (uint64, uint64)
 				fmt.Printf("BATCH: batchsize %d row rate %f/sec (took %v)\n", batchSize, float64(batchSize)/took.Seconds(), took)
 			}
 		}
+		rowCnt += len(rows)
+		delete(batches.m, tableName)
+		batches.cnt -= uint(len(rows))
 	}
 	batches.m = map[string][]*insertData{}
 	batches.cnt = 0
 
-	return metricCnt, uint64(rowCnt)
+	return metricCnt, uint64(rowCnt), nil
 }
 
 func newSyncCSI() *syncCSI {
@@ -79,7 +96,8 @@ type syncCSI struct {
 var globalSyncCSI = newSyncCSI()
 
 // Process part of incoming data - insert into tables
-func (p *processor) processCSI(tableName string, rows []*insertData) uint64 {
+// Errors from the database are returned, so the rows can be retried
+func (p *processor) processCSI(tableName string, rows []*insertData) (uint64, error) {
 	tagRows := make([][]string, 0, len(rows))
 	dataRows := make([][]interface{}, 0, len(rows))
 	ret := uint64(0)
@@ -197,12 +215,15 @@ func (p *processor) processCSI(tableName string, rows []*insertData) uint64 {
 	if len(newTags) > 0 {
 		// We have new tags to insert
 		p.csi.mutex.Lock()
-		hostnameToTags := insertTags(p.conf, p.db, len(p.csi.m), newTags, true)
+		hostnameToTags, err := insertTags(p.conf, p.db, len(p.csi.m), newTags, true)
 		// Insert new tags into map as well
 		for hostName, tagsId := range hostnameToTags {
 			p.csi.m[hostName] = tagsId
 		}
 		p.csi.mutex.Unlock()
+		if err != nil {
+			return 0, err
+		}
 	}
 
 	// Deal with tag ids for each data row
@@ -242,31 +263,38 @@ func (p *processor) processCSI(tableName string, rows []*insertData) uint64 {
 		strings.Join(cols, ","),
 		strings.Repeat(",?", len(cols))[1:]) // We need '?,?,?', but repeat ",?" thus we need to chop off 1-st char
 
-	tx := p.db.MustBegin()
+	tx, err := p.db.Begin()
+	if err != nil {
+		return 0, err
+	}
 	stmt, err := tx.Prepare(sql)
 	if err != nil {
-		panic(err)
+		tx.Rollback()
+		return 0, err
 	}
 	for _, r := range dataRows {
 		_, err := stmt.Exec(r...)
 		if err != nil {
-			panic(err)
+			stmt.Close()
+			tx.Rollback()
+			return 0, err
 		}
 	}
 	err = stmt.Close()
 	if err != nil {
-		panic(err)
+		tx.Rollback()
+		return 0, err
 	}
 	err = tx.Commit()
 	if err != nil {
-		panic(err)
+		return 0, err
 	}
 
-	return ret
+	return ret, nil
 }
 
 // insertTags fills tags table with values
-func insertTags(conf *ClickhouseConfig, db *sqlx.DB, startID int, rows [][]string, returnResults bool) map[string]int64 {
+func insertTags(conf *ClickhouseConfig, db *sqlx.DB, startID int, rows [][]string, returnResults bool) (map[string]int64, error) {
 	// Map hostname to tags_id
 	ret := make(map[string]int64)
 
@@ -307,11 +335,12 @@ func insertTags(conf *ClickhouseConfig, db *sqlx.DB, startID int, rows [][]strin
 	// ClickHouse driver accumulates all rows inside a transaction into one batch
 	tx, err := db.Begin()
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 	stmt, err := tx.Prepare(sql)
 	if err != nil {
-		panic(err)
+		tx.Rollback()
+		return nil, err
 	}
 	defer stmt.Close()
 
@@ -335,7 +364,8 @@ func insertTags(conf *ClickhouseConfig, db *sqlx.DB, startID int, rows [][]strin
 		// And now expand []interface{} with the same data as 'row' contains (plus 'id') in Exec(args ...interface{})
 		_, err := stmt.Exec(variadicArgs...)
 		if err != nil {
-			panic(err)
+			tx.Rollback()
+			return nil, err
 		}
 
 		// Fill map hostname -> id
@@ -347,14 +377,14 @@ func insertTags(conf *ClickhouseConfig, db *sqlx.DB, startID int, rows [][]strin
 
 	err = tx.Commit()
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
 	if returnResults {
-		return ret
+		return ret, nil
 	}
 
-	return nil
+	return nil, nil
 }
 
 func convertBasedOnType(serializedType, value string) interface{} {
@@ -0,0 +1,165 @@
+package clickhouse
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/bodhiye/tsbs/pkg/query"
+	"github.com/jmoiron/sqlx"
+)
+
+// QueryOptions configures the processors that run queries against
+// ClickHouse.
+type QueryOptions struct {
+	// Hosts to run queries against, assigned round robin to the workers
+	// (pass multiple values for sharding reads on a multi-node setup)
+	Hosts          []string
+	User           string
+	Password       string
+	DBName         string
+	Debug          bool
+	PrintResponses bool
+}
+
+// getConnectString returns the connection string for a query worker.
+//
+// If we're running queries against multiple nodes we need to balance the queries
+// across replicas. Each worker is assigned a sequence number -- we'll use that
+// to evenly distribute hosts to worker connections
+func (o *QueryOptions) getConnectString(workerNumber int) string {
+	// Round robin the host/worker assignment by assigning a host based on workerNumber % totalNumberOfHosts
+	host := o.Hosts[workerNumber%len(o.Hosts)]
+
+	return fmt.Sprintf("tcp://%s:9000?username=%s&password=%s&database=%s", host, o.User, o.Password, o.DBName)
+}
+
+// NewQueryProcessorCreate returns a function creating query processors that
+// run ClickHouse queries with the given options.
+func NewQueryProcessorCreate(opts *QueryOptions) query.ProcessorCreate {
+	return func() query.Processor { return &queryProcessor{opts: opts} }
+}
+
+// prettyPrintResponse prints a Query and its response in JSON format with two
+// keys: 'query' which has a value of the SQL used to generate the second key
+// 'results' which is an array of each row in the return set.
+func prettyPrintResponse(cols []string, values [][]interface{}, q *query.ClickHouse) {
+	resp := make(map[string]interface{})
+	resp["query"] = string(q.SqlQuery)
+
+	results := []map[string]interface{}{}
+	for _, rowValues := range values {
+		r := make(map[string]interface{})
+		for i, column := range cols {
+			r[column] = rowValues[i]
+		}
+		results = append(results, r)
+		resp["results"] = results
+	}
+
+	line, err := json.MarshalIndent(resp, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(string(line) + "\n")
+}
+
+// readRows reads all rows of the response, returning the column names and
+// the values of each row.
+func readRows(rows *sqlx.Rows) ([]string, [][]interface{}, error) {
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, nil, err
+	}
+	var values [][]interface{}
+	for rows.Next() {
+		rowValues, err := rows.SliceScan()
+		if err != nil {
+			return nil, nil, err
+		}
+		values = append(values, rowValues)
+	}
+	return cols, values, rows.Err()
+}
+
+// newResultSet converts the rows of a response to a normalized result set.
+func newResultSet(values [][]interface{}) *query.ResultSet {
+	rs := &query.ResultSet{Rows: make([]query.ResultRow, 0, len(values))}
+	for _, rowValues := range values {
+		rs.Rows = append(rs.Rows, query.NewResultRow(rowValues...))
+	}
+	return rs
+}
+
+// query.Processor interface implementation
+type queryProcessor struct {
+	db   *sqlx.DB
+	opts *QueryOptions
+}
+
+// query.Processor interface implementation
+func (p *queryProcessor) Init(workerNumber int) {
+	p.db = sqlx.MustConnect(dbType, p.opts.getConnectString(workerNumber))
+}
+
+func (p *queryProcessor) Close() {
+	if p.db != nil {
+		p.db.Close()
+	}
+}
+
+// query.Processor interface implementation
+func (p *queryProcessor) ProcessQuery(q query.Query, _ bool) ([]*query.Stat, error) {
+	stats, _, err := p.processQuery(q, false)
+	return stats, err
+}
+
+// query.ResultProcessor interface implementation
+func (p *queryProcessor) ProcessQueryWithResult(q query.Query, _ bool) ([]*query.Stat, *query.ResultSet, error) {
+	return p.processQuery(q, true)
+}
+
+func (p *queryProcessor) processQuery(q query.Query, withResult bool) ([]*query.Stat, *query.ResultSet, error) {
+	// Ensure ClickHouse query
+	chQuery := q.(*query.ClickHouse)
+
+	start := time.Now()
+
+	// SqlQuery is []byte, so cast is needed
+	sql := string(chQuery.SqlQuery)
+
+	// Main action - run the query
+	rows, err := p.db.Queryx(sql)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Print some extra info if needed
+	if p.opts.Debug {
+		fmt.Println(sql)
+	}
+	var rs *query.ResultSet
+	if p.opts.PrintResponses || withResult {
+		cols, values, err := readRows(rows)
+		if err != nil {
+			rows.Close()
+			return nil, nil, err
+		}
+		if p.opts.PrintResponses {
+			prettyPrintResponse(cols, values, chQuery)
+		}
+		if withResult {
+			rs = newResultSet(values)
+		}
+	}
+
+	// Finalize the query
+	rows.Close()
+	took := float64(time.Since(start).Nanoseconds()) / 1e6
+
+	stat := query.GetStat()
+	stat.Init(q.HumanLabelName(), took)
+
+	return []*query.Stat{stat}, rs, err
+}
@@ -0,0 +1,75 @@
+package crate
+
+import (
+	"log"
+
+	"github.com/bodhiye/tsbs/load"
+	"github.com/bodhiye/tsbs/pkg/data/source"
+	"github.com/bodhiye/tsbs/pkg/targets"
+	"github.com/bodhiye/tsbs/tools/inputs"
+)
+
+// the logger is used in implementations of interface methods that
+// do not return error on failures to allow testing such methods
+var fatal = log.Fatalf
+
+func NewBenchmark(dbSpecificConfig *SpecificConfig, dataSourceConfig *source.DataSourceConfig) (targets.Benchmark, error) {
+	connConfig, err := dbSpecificConfig.connConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	// TODO implement or check if anything has to be done to support WorkerPerQueue mode
+	var ds targets.DataSource
+	if dataSourceConfig.Type == source.FileDataSourceType {
+		ds = newFileDataSource(load.GetBufferedReader(dataSourceConfig.File.Location))
+	} else {
+		dataGenerator := &inputs.DataGenerator{}
+		simulator, err := dataGenerator.CreateSimulator(dataSourceConfig.Simulator)
+		if err != nil {
+			return nil, err
+		}
+		ds = targets.NewSimulationDataSource(simulator, &Serializer{}, true, newFileDataSource)
+	}
+	return &benchmark{
+		dbc: &dbCreator{
+			cfg:         connConfig,
+			numReplicas: dbSpecificConfig.NumReplicas,
+			numShards:   dbSpecificConfig.NumShards,
+			ds:          ds,
+		},
+		ds: ds,
+	}, nil
+}
+
+type benchmark struct {
+	dbc *dbCreator
+	ds  targets.DataSource
+}
+
+func (b *benchmark) GetDataSource() targets.DataSource {
+	return b.ds
+}
+
+func (b *benchmark) GetBatchFactory() targets.BatchFactory {
+	return &factory{}
+}
+
+func (b *benchmark) GetPointIndexer(maxPartitions uint) targets.PointIndexer {
+	return &targets.ConstantIndexer{}
+}
+
+func (b *benchmark) GetProcessor() targets.Processor {
+	tableDefs := make(map[string]*tableDef)
+	for _, td := range b.dbc.tableDefs {
+		tableDefs[td.name] = td
+	}
+	return &processor{
+		tableDefs: tableDefs,
+		connCfg:   b.dbc.cfg,
+	}
+}
+
+func (b *benchmark) GetDBCreator() targets.DBCreator {
+	return b.dbc
+}
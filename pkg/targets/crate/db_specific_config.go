@@ -0,0 +1,36 @@
+package crate
+
+import (
+	"fmt"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/spf13/viper"
+)
+
+type SpecificConfig struct {
+	Hosts       string `yaml:"hosts" mapstructure:"hosts"`
+	Port        uint   `yaml:"port" mapstructure:"port"`
+	User        string `yaml:"user" mapstructure:"user"`
+	Pass        string `yaml:"pass" mapstructure:"pass"`
+	NumReplicas int    `yaml:"replicas" mapstructure:"replicas"`
+	NumShards   int    `yaml:"shards" mapstructure:"shards"`
+}
+
+func parseSpecificConfig(v *viper.Viper) (*SpecificConfig, error) {
+	var conf SpecificConfig
+	if err := v.Unmarshal(&conf); err != nil {
+		return nil, err
+	}
+	return &conf, nil
+}
+
+// connConfig builds the pgx connection config for the CrateDB PostgreSQL
+// wire protocol endpoint.
+func (c *SpecificConfig) connConfig() (*pgx.ConnConfig, error) {
+	connStr := fmt.Sprintf("host=%s port=%d user=%s password='%s' dbname=doc", c.Hosts, c.Port, c.User, c.Pass)
+	connConfig, err := pgx.ParseConfig(connStr)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse connection config: %v", err)
+	}
+	return connConfig, nil
+}
@@ -1,9 +1,12 @@
 package crate
 
 import (
+	"sync"
+
 	"github.com/spf13/viper"
 	"github.com/bodhiye/tsbs/pkg/data/serialize"
 	"github.com/bodhiye/tsbs/pkg/data/source"
+	"github.com/bodhiye/tsbs/pkg/query"
 	"github.com/bodhiye/tsbs/pkg/targets"
 	"github.com/bodhiye/tsbs/pkg/targets/constants"
 	"github.com/spf13/pflag"
@@ -33,6 +36,42 @@ func (t *crateTarget) Serializer() serialize.PointSerializer {
 	return &Serializer{}
 }
 
-func (t *crateTarget) Benchmark(string, *source.DataSourceConfig, *viper.Viper) (targets.Benchmark, error) {
-	panic("not implemented")
+func (t *crateTarget) Benchmark(_ string, dataSourceConfig *source.DataSourceConfig, v *viper.Viper) (targets.Benchmark, error) {
+	crateSpecificConfig, err := parseSpecificConfig(v)
+	if err != nil {
+		return nil, err
+	}
+	return NewBenchmark(crateSpecificConfig, dataSourceConfig)
+}
+
+func (t *crateTarget) QueryPool() *sync.Pool {
+	return &query.CrateDBPool
+}
+
+func (t *crateTarget) QueryProcessorCreate(
+	targetDB string, runnerConfig *query.BenchmarkRunnerConfig, v *viper.Viper,
+) (query.ProcessorCreate, error) {
+	crateSpecificConfig, err := parseSpecificConfig(v)
+	if err != nil {
+		return nil, err
+	}
+	opts := &QueryOptions{
+		Hosts:          crateSpecificConfig.Hosts,
+		Port:           crateSpecificConfig.Port,
+		User:           crateSpecificConfig.User,
+		Pass:           crateSpecificConfig.Pass,
+		DBName:         targetDB,
+		ShowExplain:    v.GetBool("show-explain"),
+		Debug:          runnerConfig.Debug > 0,
+		PrintResponses: runnerConfig.PrintResponses,
+	}
+	if opts.ShowExplain {
+		// only the EXPLAIN output of the first query is printed
+		runnerConfig.Limit = 1
+	}
+	return NewQueryProcessorCreate(opts)
+}
+
+func (t *crateTarget) QueryFlags(flagPrefix string, flagSet *pflag.FlagSet) {
+	flagSet.Bool(flagPrefix+"show-explain", false, "Print out the EXPLAIN output for sample query")
 }
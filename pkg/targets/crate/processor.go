@@ -1,4 +1,4 @@
-package main
+package crate
 
 import (
 	"context"
@@ -49,21 +49,38 @@ func (p *processor) createInsertStmt(table *tableDef) (string, error) {
 
 // load.Processor interface implementation
 func (p *processor) ProcessBatch(b targets.Batch, doLoad bool) (uint64, uint64) {
+	metricCnt, rowCnt, err := p.ProcessBatchWithError(b, doLoad)
+	if err != nil {
+		fatal("failed to close a batch operation %v", err)
+	}
+	return metricCnt, rowCnt
+}
+
+// load.ProcessorWithError interface implementation
+// Tables are inserted one at a time and removed from the batch once inserted,
+// so that only the rest of it is retried if an insert fails.
+func (p *processor) ProcessBatchWithError(b targets.Batch, doLoad bool) (uint64, uint64, error) {
 	eb := b.(*eventsBatch)
 	rowCnt := uint64(0)
 	metricCnt := uint64(0)
 
 	for table, rows := range eb.batches {
-		rowCnt += uint64(len(rows))
 		if doLoad {
-			metricCnt += p.InsertBatch(table, rows)
+			metrics, err := p.InsertBatch(table, rows)
+			if err != nil {
+				return metricCnt, rowCnt, err
+			}
+			metricCnt += metrics
 		}
+		rowCnt += uint64(len(rows))
+		delete(eb.batches, table)
+		eb.rowCnt -= uint(len(rows))
 	}
-	return metricCnt, rowCnt
+	return metricCnt, rowCnt, nil
 }
 
-// load.Processor interface implementation
-func (p *processor) InsertBatch(table string, rows []*row) uint64 {
+// InsertBatch inserts the rows of a table in a single batch operation
+func (p *processor) InsertBatch(table string, rows []*row) (uint64, error) {
 	metricCnt := uint64(0)
 	b := pgx.Batch{}
 	for _, row := range rows {
@@ -79,9 +96,9 @@ func (p *processor) InsertBatch(table string, rows []*row) uint64 {
 	}
 	batchResults := p.conn.SendBatch(context.Background(), &b)
 	if err := batchResults.Close(); err != nil {
-		fatal("failed to close a batch operation %v", err)
+		return 0, err
 	}
-	return metricCnt
+	return metricCnt, nil
 }
 
 // load.ProcessorCloser interface implementation
@@ -0,0 +1,168 @@
+package crate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/bodhiye/tsbs/pkg/query"
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+)
+
+// QueryOptions configures the processors that run queries against CrateDB.
+type QueryOptions struct {
+	Hosts          string
+	Port           uint
+	User           string
+	Pass           string
+	DBName         string
+	ShowExplain    bool
+	Debug          bool
+	PrintResponses bool
+}
+
+// NewQueryProcessorCreate returns a function creating query processors that
+// run CrateDB queries with the given options.
+func NewQueryProcessorCreate(opts *QueryOptions) (query.ProcessorCreate, error) {
+	connStr := fmt.Sprintf("host=%s port=%d user=%s password='%s' dbname=%s", opts.Hosts, opts.Port, opts.User, opts.Pass, opts.DBName)
+	connConfig, err := pgx.ParseConfig(connStr)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not parse connection config")
+	}
+	return func() query.Processor {
+		return &queryProcessor{connCfg: connConfig, opts: opts}
+	}, nil
+}
+
+type queryProcessor struct {
+	conn    *pgx.Conn
+	connCfg *pgx.ConnConfig
+	opts    *QueryOptions
+}
+
+func (p *queryProcessor) Init(workerNumber int) {
+	conn, err := pgx.ConnectConfig(context.Background(), p.connCfg)
+	if err != nil {
+		panic(err)
+	}
+	p.conn = conn
+}
+
+func (p *queryProcessor) Close() {
+	if p.conn != nil {
+		p.conn.Close(context.Background())
+	}
+}
+
+func (p *queryProcessor) ProcessQuery(q query.Query, isWarm bool) ([]*query.Stat, error) {
+	stats, _, err := p.processQuery(q, isWarm, false)
+	return stats, err
+}
+
+func (p *queryProcessor) ProcessQueryWithResult(q query.Query, isWarm bool) ([]*query.Stat, *query.ResultSet, error) {
+	return p.processQuery(q, isWarm, true)
+}
+
+func (p *queryProcessor) processQuery(q query.Query, isWarm, withResult bool) ([]*query.Stat, *query.ResultSet, error) {
+	// No need to run again for EXPLAIN
+	if isWarm && p.opts.ShowExplain {
+		return nil, nil, nil
+	}
+	tq := q.(*query.CrateDB)
+
+	start := time.Now()
+	qry := string(tq.SqlQuery)
+	if p.opts.ShowExplain {
+		qry = "EXPLAIN ANALYZE " + qry
+	}
+	rows, err := p.conn.Query(context.Background(), qry)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	if p.opts.Debug {
+		fmt.Println(qry)
+	}
+	var rs *query.ResultSet
+	if p.opts.ShowExplain {
+		fmt.Printf("Explian Query:\n")
+		prettyPrintResponse(readRows(rows), tq)
+		fmt.Printf("\n-----------\n\n")
+	} else if p.opts.PrintResponses || withResult {
+		results := readRows(rows)
+		if p.opts.PrintResponses {
+			prettyPrintResponse(results, tq)
+		}
+		if withResult {
+			rs = newResultSet(results.values)
+		}
+	}
+
+	took := float64(time.Since(start).Nanoseconds()) / 1e6
+	stat := query.GetStat()
+	stat.Init(q.HumanLabelName(), took)
+
+	return []*query.Stat{stat}, rs, err
+}
+
+// prettyPrintResponse prints a Query and its response in JSON format with two
+// keys: 'query' which has a value of the SQL used to generate the second key
+// 'results' which is an array of each row in the return set.
+func prettyPrintResponse(results *queryRows, q *query.CrateDB) {
+	resp := make(map[string]interface{})
+	resp["query"] = string(q.SqlQuery)
+	resp["results"] = results.mapRows()
+
+	line, err := json.MarshalIndent(resp, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(string(line) + "\n")
+}
+
+// queryRows are the column names and the row values of a response.
+type queryRows struct {
+	cols   []string
+	values [][]interface{}
+}
+
+// readRows reads all rows of the response.
+func readRows(r pgx.Rows) *queryRows {
+	results := &queryRows{}
+	for _, col := range r.FieldDescriptions() {
+		results.cols = append(results.cols, string(col.Name))
+	}
+	for r.Next() {
+		values, err := r.Values()
+		if err != nil {
+			panic(errors.Wrap(err, "error while reading values"))
+		}
+		results.values = append(results.values, values)
+	}
+	return results
+}
+
+func (r *queryRows) mapRows() []map[string]interface{} {
+	var rows []map[string]interface{}
+	for _, rowValues := range r.values {
+		row := make(map[string]interface{})
+		for i, column := range r.cols {
+			row[column] = rowValues[i]
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// newResultSet converts the rows of a response to a normalized result set.
+func newResultSet(values [][]interface{}) *query.ResultSet {
+	rs := &query.ResultSet{Rows: make([]query.ResultRow, 0, len(values))}
+	for _, rowValues := range values {
+		rs.Rows = append(rs.Rows, query.NewResultRow(rowValues...))
+	}
+	return rs
+}
@@ -1,4 +1,4 @@
-package main
+package crate
 
 import (
 	"bufio"
@@ -51,12 +51,28 @@ func (f *factory) New() targets.Batch {
 	return ePool.Get().(*eventsBatch)
 }
 
+func newFileDataSource(r *bufio.Reader) targets.DataSource {
+	return &fileDataSource{scanner: targets.NewLineScanner(r)}
+}
+
 // source.DataSource interface implementation
 type fileDataSource struct {
-	scanner *bufio.Scanner
+	scanner *targets.LineScanner
 	headers *common.GeneratedDataHeaders
 }
 
+// Offset implements targets.OffsetDataSource.
+func (d *fileDataSource) Offset() int64 {
+	return d.scanner.Offset()
+}
+
+// SkipTo implements targets.OffsetDataSource.
+func (d *fileDataSource) SkipTo(offset int64) error {
+	// the headers are read first, as the points are skipped after them
+	d.Headers()
+	return d.scanner.SkipTo(offset)
+}
+
 // source.DataSource interface implementation
 //
 // Decodes a data point of a following format:
@@ -1,4 +1,4 @@
-package main
+package crate
 
 import (
 	"bufio"
@@ -11,6 +11,7 @@ import (
 
 	"github.com/bodhiye/tsbs/pkg/data"
 	"github.com/bodhiye/tsbs/pkg/data/usecases/common"
+	"github.com/bodhiye/tsbs/pkg/targets"
 )
 
 func TestEventsBatch(t *testing.T) {
@@ -118,7 +119,7 @@ func TestDecode(t *testing.T) {
 	}
 	for _, c := range cases {
 		br := bufio.NewReader(bytes.NewReader([]byte(c.input)))
-		decoder := &fileDataSource{scanner: bufio.NewScanner(br)}
+		decoder := &fileDataSource{scanner: targets.NewLineScanner(br)}
 		if c.expectedToFail {
 			fmt.Println(c.desc)
 			isCalled := false
@@ -158,7 +159,7 @@ func TestDecode(t *testing.T) {
 func TestDecodeEOF(t *testing.T) {
 	input := []byte("cpu\t{\"hostname\":\"host_0\"}\t1454608400000000000\t38.24311829\n")
 	br := bufio.NewReader(bytes.NewReader([]byte(input)))
-	decoder := &fileDataSource{scanner: bufio.NewScanner(br)}
+	decoder := &fileDataSource{scanner: targets.NewLineScanner(br)}
 	_ = decoder.NextItem()
 	// nothing left, should be EOF
 	p := decoder.NextItem()
@@ -234,7 +235,7 @@ func TestDataSourceHeaders(t *testing.T) {
 
 	for _, c := range cases {
 		br := bufio.NewReader(bytes.NewReader([]byte(c.input)))
-		fds := &fileDataSource{scanner: bufio.NewScanner(br)}
+		fds := &fileDataSource{scanner: targets.NewLineScanner(br)}
 		if c.expectedToFail {
 			isCalled := false
 			fatal = func(fmt string, args ...interface{}) {
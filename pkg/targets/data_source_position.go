@@ -0,0 +1,85 @@
+package targets
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"time"
+)
+
+// OffsetDataSource is a DataSource reading its points from a stream of bytes,
+// like a file, that keeps track of how far into the stream it has read, so a
+// resumed load can skip the points it already inserted without decoding them.
+type OffsetDataSource interface {
+	DataSource
+	// Offset returns the number of bytes of the input consumed by the
+	// headers and the points read so far.
+	Offset() int64
+	// SkipTo skips the points up to the offset in the input, returned by
+	// Offset after the last of them was read.
+	SkipTo(offset int64) error
+}
+
+// SimulatorPosition is the position of a data source in the points of its
+// simulator: the number of simulated points all of whose loaded points
+// have been read, and the number of loaded points read of the next one,
+// for targets that load a simulated point as several points.
+type SimulatorPosition struct {
+	Points  uint64 `json:"points"`
+	Partial uint64 `json:"partial,omitempty"`
+	// Time is the timestamp of the last simulated point read
+	Time time.Time `json:"time"`
+}
+
+// SimulatedDataSource is a DataSource generating its points with a
+// simulator, that can fast-forward the simulator when a load is resumed.
+type SimulatedDataSource interface {
+	DataSource
+	// SimulatorPosition returns the position of the data source after the
+	// points read so far.
+	SimulatorPosition() SimulatorPosition
+	// FastForward skips the points up to the position, without pacing or
+	// serializing the simulated points. It must be called before any point
+	// is read.
+	FastForward(pos SimulatorPosition) error
+}
+
+// LineScanner is a bufio.Scanner of lines that keeps track of the offset of
+// the lines scanned in its input, for data sources of line-based formats to
+// implement OffsetDataSource.
+type LineScanner struct {
+	*bufio.Scanner
+	offset int64
+}
+
+// NewLineScanner returns a LineScanner reading lines from r.
+func NewLineScanner(r io.Reader) *LineScanner {
+	s := &LineScanner{Scanner: bufio.NewScanner(r)}
+	s.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		advance, token, err := bufio.ScanLines(data, atEOF)
+		s.offset += int64(advance)
+		return advance, token, err
+	})
+	return s
+}
+
+// Offset returns the number of bytes of the input scanned so far.
+func (s *LineScanner) Offset() int64 {
+	return s.offset
+}
+
+// SkipTo scans lines, without returning them, up to the offset in the input.
+func (s *LineScanner) SkipTo(offset int64) error {
+	for s.offset < offset {
+		if !s.Scan() {
+			if err := s.Err(); err != nil {
+				return err
+			}
+			return fmt.Errorf("input ended at offset %d, before offset %d", s.offset, offset)
+		}
+	}
+	if s.offset != offset {
+		return fmt.Errorf("offset %d is not at the start of a line", offset)
+	}
+	return nil
+}
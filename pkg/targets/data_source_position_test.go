@@ -0,0 +1,55 @@
+package targets
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLineScanner(t *testing.T) {
+	input := "cpu,1\n\nmem,22\r\ndisk,333"
+	s := NewLineScanner(strings.NewReader(input))
+	wantOffsets := []int64{6, 7, 15, 23}
+	for i, want := range wantOffsets {
+		if !s.Scan() {
+			t.Fatalf("line %d not scanned", i)
+		}
+		if got := s.Offset(); got != want {
+			t.Errorf("incorrect offset after line %d: got %d want %d", i, got, want)
+		}
+	}
+	if s.Scan() {
+		t.Errorf("unexpected line scanned: %q", s.Text())
+	}
+}
+
+func TestLineScannerSkipTo(t *testing.T) {
+	input := "cpu,1\n\nmem,22\r\ndisk,333"
+	cases := []struct {
+		desc     string
+		offset   int64
+		wantErr  bool
+		wantNext string
+	}{
+		{desc: "start", offset: 0, wantNext: "cpu,1"},
+		{desc: "line start", offset: 7, wantNext: "mem,22"},
+		{desc: "middle of a line", offset: 9, wantErr: true},
+		{desc: "past the end", offset: 30, wantErr: true},
+	}
+	for _, c := range cases {
+		s := NewLineScanner(strings.NewReader(input))
+		err := s.SkipTo(c.offset)
+		if c.wantErr {
+			if err == nil {
+				t.Errorf("%s: expected an error", c.desc)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", c.desc, err)
+			continue
+		}
+		if !s.Scan() || s.Text() != c.wantNext {
+			t.Errorf("%s: incorrect next line: got %q want %q", c.desc, s.Text(), c.wantNext)
+		}
+	}
+}
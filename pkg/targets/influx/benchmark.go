@@ -0,0 +1,81 @@
+package influx
+
+import (
+	"bytes"
+	"log"
+	"sync"
+
+	"github.com/bodhiye/tsbs/load"
+	"github.com/bodhiye/tsbs/pkg/data/source"
+	"github.com/bodhiye/tsbs/pkg/targets"
+	"github.com/bodhiye/tsbs/tools/inputs"
+)
+
+// allows for testing
+var fatal = log.Fatalf
+
+func NewBenchmark(dbName string, dbSpecificConfig *SpecificConfig, dataSourceConfig *source.DataSourceConfig) (targets.Benchmark, error) {
+	if err := dbSpecificConfig.validate(); err != nil {
+		return nil, err
+	}
+
+	var ds targets.DataSource
+	if dataSourceConfig.Type == source.FileDataSourceType {
+		ds = newFileDataSource(load.GetBufferedReader(dataSourceConfig.File.Location))
+	} else {
+		dataGenerator := &inputs.DataGenerator{}
+		simulator, err := dataGenerator.CreateSimulator(dataSourceConfig.Simulator)
+		if err != nil {
+			return nil, err
+		}
+		ds = targets.NewSimulationDataSource(simulator, &Serializer{}, false, newFileDataSource)
+	}
+
+	return &benchmark{
+		dbName: dbName,
+		conf:   dbSpecificConfig,
+		ds:     ds,
+		bufPool: &sync.Pool{
+			New: func() interface{} {
+				return bytes.NewBuffer(make([]byte, 0, 4*1024*1024))
+			},
+		},
+	}, nil
+}
+
+// targets.Benchmark interface implementation
+type benchmark struct {
+	dbName  string
+	conf    *SpecificConfig
+	ds      targets.DataSource
+	bufPool *sync.Pool
+}
+
+func (b *benchmark) GetDataSource() targets.DataSource {
+	return b.ds
+}
+
+func (b *benchmark) GetBatchFactory() targets.BatchFactory {
+	return &factory{bufPool: b.bufPool}
+}
+
+func (b *benchmark) GetPointIndexer(_ uint) targets.PointIndexer {
+	return &targets.ConstantIndexer{}
+}
+
+func (b *benchmark) GetProcessor() targets.Processor {
+	return &processor{
+		daemonURLs:  b.conf.URLs,
+		dbName:      b.dbName,
+		consistency: b.conf.Consistency,
+		useGzip:     b.conf.UseGzip,
+		bufPool:     b.bufPool,
+	}
+}
+
+func (b *benchmark) GetDBCreator() targets.DBCreator {
+	return &dbCreator{
+		daemonURLs:        b.conf.URLs,
+		replicationFactor: b.conf.ReplicationFactor,
+	}
+}
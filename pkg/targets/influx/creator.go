@@ -1,4 +1,4 @@
-package main
+package influx
 
 import (
 	"encoding/json"
@@ -11,11 +11,13 @@ import (
 )
 
 type dbCreator struct {
-	daemonURL string
+	daemonURLs        []string
+	replicationFactor int
+	daemonURL         string
 }
 
 func (d *dbCreator) Init() {
-	d.daemonURL = daemonURLs[0] // pick first one since it always exists
+	d.daemonURL = d.daemonURLs[0] // pick first one since it always exists
 }
 
 func (d *dbCreator) DBExists(dbName string) bool {
@@ -25,7 +27,7 @@ func (d *dbCreator) DBExists(dbName string) bool {
 	}
 
 	for _, db := range dbs {
-		if db == loader.DatabaseName() {
+		if db == dbName {
 			return true
 		}
 	}
@@ -95,7 +97,7 @@ func (d *dbCreator) CreateDB(dbName string) error {
 	u.Path = "query"
 	v := u.Query()
 	v.Set("consistency", "all")
-	v.Set("q", fmt.Sprintf("CREATE DATABASE %s WITH REPLICATION %d", dbName, replicationFactor))
+	v.Set("q", fmt.Sprintf("CREATE DATABASE %s WITH REPLICATION %d", dbName, d.replicationFactor))
 	u.RawQuery = v.Encode()
 
 	req, err := http.NewRequest("GET", u.String(), nil)
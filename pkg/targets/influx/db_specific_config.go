@@ -0,0 +1,39 @@
+package influx
+
+import (
+	"fmt"
+
+	"github.com/spf13/viper"
+)
+
+var consistencyChoices = map[string]struct{}{
+	"any":    {},
+	"one":    {},
+	"quorum": {},
+	"all":    {},
+}
+
+type SpecificConfig struct {
+	URLs              []string `yaml:"urls" mapstructure:"urls"`
+	ReplicationFactor int      `yaml:"replication-factor" mapstructure:"replication-factor"`
+	Consistency       string   `yaml:"consistency" mapstructure:"consistency"`
+	UseGzip           bool     `yaml:"gzip" mapstructure:"gzip"`
+}
+
+func parseSpecificConfig(v *viper.Viper) (*SpecificConfig, error) {
+	var conf SpecificConfig
+	if err := v.Unmarshal(&conf); err != nil {
+		return nil, err
+	}
+	return &conf, nil
+}
+
+func (c *SpecificConfig) validate() error {
+	if _, ok := consistencyChoices[c.Consistency]; !ok {
+		return fmt.Errorf("invalid consistency level %s; allowed: any, one, quorum, all", c.Consistency)
+	}
+	if len(c.URLs) == 0 {
+		return fmt.Errorf("missing 'urls' flag")
+	}
+	return nil
+}
@@ -1,4 +1,4 @@
-package main
+package influx
 
 import (
 	"context"
@@ -14,7 +14,7 @@ import (
 )
 
 const (
-	shouldBackoffParam = "shouldErr"
+	shouldErrParam     = "shouldErr"
 	shouldInvalidParam = "shouldInvalid"
 	httpServerPort     = ":8080"
 	httpDelay          = 50 * time.Millisecond
@@ -33,11 +33,11 @@ func runHTTPServer(c chan struct{}) {
 	s := http.Server{Addr: httpServerPort, Handler: m}
 	i := int64(0)
 	m.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		if strings.Contains(r.URL.RawQuery, shouldBackoffParam) {
+		if strings.Contains(r.URL.RawQuery, shouldErrParam) {
 			coinflip := atomic.AddInt64(&i, 1)
 			if coinflip%2 == 1 {
 				w.WriteHeader(http.StatusInternalServerError)
-				fmt.Fprintf(w, string(backoffMagicWords1))
+				fmt.Fprintf(w, "write failed: hinted handoff queue not empty")
 			} else {
 				w.WriteHeader(http.StatusNoContent)
 				fmt.Fprintf(w, "")
@@ -155,16 +155,16 @@ func TestHTTPWriterExecuteReq(t *testing.T) {
 		t.Errorf("latency is unrealistic (<= 0): %d", lat)
 	}
 
-	// Backoff case test, make sure its a backoff error and positive latency
+	// Server error case test, make sure its an error and positive latency
 	resp = fasthttp.AcquireResponse()
 	defer fasthttp.ReleaseResponse(resp)
-	w.url = []byte(fmt.Sprintf("%s&%s=true", string(normalURL), shouldBackoffParam))
+	w.url = []byte(fmt.Sprintf("%s&%s=true", string(normalURL), shouldErrParam))
 	req = fasthttp.AcquireRequest()
 	defer fasthttp.ReleaseRequest(req)
 	w.initializeReq(req, []byte(body), false)
 	lat, err = w.executeReq(req, resp)
-	if err != errBackoff {
-		t.Errorf("unexpected error response received (not backoff error): %v", err)
+	if err == nil {
+		t.Errorf("unexpected non-error response received for server error")
 	}
 	if lat <= 0 {
 		t.Errorf("latency is unrealistic (<= 0): %d", lat)
@@ -187,49 +187,3 @@ func TestHTTPWriterExecuteReq(t *testing.T) {
 
 	shutdownHTTPServer(c)
 }
-
-func TestBackpressurePred(t *testing.T) {
-	cases := []struct {
-		body string
-		want bool
-	}{
-		{
-			body: "yadda yadda" + string(backoffMagicWords0),
-			want: true,
-		},
-		{
-			body: "yadda" + string(backoffMagicWords1),
-			want: true,
-		},
-		{
-			body: string(backoffMagicWords2a),
-			want: false, // need both magic strings or it fails
-		},
-		{
-			body: string(backoffMagicWords2a) + " AND " + string(backoffMagicWords2b),
-			want: true,
-		},
-		{
-			body: string(backoffMagicWords3) + " yadda",
-			want: true,
-		},
-		{
-			body: "yadda " + string(backoffMagicWords4) + " yadda",
-			want: true,
-		},
-		{
-			body: "foo " + string(backoffMagicWords5) + " yadda",
-			want: true,
-		},
-		{
-			body: string(backoffMagicWords0[2:]),
-			want: false,
-		},
-	}
-
-	for _, c := range cases {
-		if got := backpressurePred([]byte(c.body)); got != c.want {
-			t.Errorf("'%s' did not give correct backpressure result: got %v want %v", c.body, got, c.want)
-		}
-	}
-}
@@ -1,11 +1,13 @@
 package influx
 
 import (
-	"time"
+	"fmt"
+	"sync"
 
 	"github.com/spf13/viper"
 	"github.com/bodhiye/tsbs/pkg/data/serialize"
 	"github.com/bodhiye/tsbs/pkg/data/source"
+	"github.com/bodhiye/tsbs/pkg/query"
 	"github.com/bodhiye/tsbs/pkg/targets"
 	"github.com/bodhiye/tsbs/pkg/targets/constants"
 	"github.com/spf13/pflag"
@@ -22,7 +24,6 @@ func (t *influxTarget) TargetSpecificFlags(flagPrefix string, flagSet *pflag.Fla
 	flagSet.String(flagPrefix+"urls", "http://localhost:8086", "InfluxDB URLs, comma-separated. Will be used in a round-robin fashion.")
 	flagSet.Int(flagPrefix+"replication-factor", 1, "Cluster replication factor (only applies to clustered databases).")
 	flagSet.String(flagPrefix+"consistency", "all", "Write consistency. Must be one of: any, one, quorum, all.")
-	flagSet.Duration(flagPrefix+"backoff", time.Second, "Time to sleep between requests when server indicates backpressure is needed.")
 	flagSet.Bool(flagPrefix+"gzip", true, "Whether to gzip encode requests (default true).")
 }
 
@@ -34,6 +35,37 @@ func (t *influxTarget) Serializer() serialize.PointSerializer {
 	return &Serializer{}
 }
 
-func (t *influxTarget) Benchmark(string, *source.DataSourceConfig, *viper.Viper) (targets.Benchmark, error) {
-	panic("not implemented")
+func (t *influxTarget) Benchmark(targetDB string, dataSourceConfig *source.DataSourceConfig, v *viper.Viper) (targets.Benchmark, error) {
+	influxSpecificConfig, err := parseSpecificConfig(v)
+	if err != nil {
+		return nil, err
+	}
+	return NewBenchmark(targetDB, influxSpecificConfig, dataSourceConfig)
+}
+
+func (t *influxTarget) QueryPool() *sync.Pool {
+	return &query.HTTPPool
+}
+
+func (t *influxTarget) QueryProcessorCreate(
+	targetDB string, runnerConfig *query.BenchmarkRunnerConfig, v *viper.Viper,
+) (query.ProcessorCreate, error) {
+	influxSpecificConfig, err := parseSpecificConfig(v)
+	if err != nil {
+		return nil, err
+	}
+	if len(influxSpecificConfig.URLs) == 0 {
+		return nil, fmt.Errorf("missing 'urls' flag")
+	}
+	return NewQueryProcessorCreate(&QueryOptions{
+		URLs:           influxSpecificConfig.URLs,
+		ChunkSize:      v.GetUint64("chunk-response-size"),
+		DBName:         targetDB,
+		Debug:          runnerConfig.Debug,
+		PrintResponses: runnerConfig.PrintResponses,
+	}), nil
+}
+
+func (t *influxTarget) QueryFlags(flagPrefix string, flagSet *pflag.FlagSet) {
+	flagSet.Uint64(flagPrefix+"chunk-response-size", 0, "Number of series to chunk results into. 0 means no chunking.")
 }
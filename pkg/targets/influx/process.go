@@ -0,0 +1,69 @@
+package influx
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+
+	"github.com/bodhiye/tsbs/pkg/targets"
+	"github.com/valyala/fasthttp"
+)
+
+type processor struct {
+	daemonURLs  []string
+	dbName      string
+	consistency string
+	useGzip     bool
+	bufPool     *sync.Pool
+
+	httpWriter *HTTPWriter
+}
+
+func (p *processor) Init(numWorker int, _, _ bool) {
+	daemonURL := p.daemonURLs[numWorker%len(p.daemonURLs)]
+	cfg := HTTPWriterConfig{
+		DebugInfo: fmt.Sprintf("worker #%d, dest url: %s", numWorker, daemonURL),
+		Host:      daemonURL,
+		Database:  p.dbName,
+	}
+	p.httpWriter = NewHTTPWriter(cfg, p.consistency)
+}
+
+func (p *processor) ProcessBatch(b targets.Batch, doLoad bool) (uint64, uint64) {
+	metricCnt, rowCnt, err := p.ProcessBatchWithError(b, doLoad)
+	if err != nil {
+		fatal("Error writing: %s\n", err.Error())
+	}
+	return metricCnt, rowCnt
+}
+
+// ProcessBatchWithError writes the batch. Errors, including those of an
+// overloaded server, are returned with the batch left intact, so it can be
+// retried.
+func (p *processor) ProcessBatchWithError(b targets.Batch, doLoad bool) (uint64, uint64, error) {
+	batch := b.(*batch)
+
+	if doLoad {
+		var err error
+		if p.useGzip {
+			compressedBatch := p.bufPool.Get().(*bytes.Buffer)
+			fasthttp.WriteGzip(compressedBatch, batch.buf.Bytes())
+			_, err = p.httpWriter.WriteLineProtocol(compressedBatch.Bytes(), true)
+			// Return the compressed batch buffer to the pool.
+			compressedBatch.Reset()
+			p.bufPool.Put(compressedBatch)
+		} else {
+			_, err = p.httpWriter.WriteLineProtocol(batch.buf.Bytes(), false)
+		}
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+	metricCnt := batch.metrics
+	rowCnt := batch.rows
+
+	// Return the batch buffer to the pool.
+	batch.buf.Reset()
+	p.bufPool.Put(batch.buf)
+	return metricCnt, uint64(rowCnt), nil
+}
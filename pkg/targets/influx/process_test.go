@@ -0,0 +1,179 @@
+package influx
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bodhiye/tsbs/pkg/data"
+)
+
+func TestProcessorInit(t *testing.T) {
+	daemonURLs := []string{"url1", "url2"}
+	dbName := "benchmark"
+	p := &processor{daemonURLs: daemonURLs, dbName: dbName}
+	p.Init(0, false, false)
+	if got := p.httpWriter.c.Host; got != daemonURLs[0] {
+		t.Errorf("incorrect host: got %s want %s", got, daemonURLs[0])
+	}
+	if got := p.httpWriter.c.Database; got != dbName {
+		t.Errorf("incorrect database: got %s want %s", got, dbName)
+	}
+
+	p = &processor{daemonURLs: daemonURLs, dbName: dbName}
+	p.Init(1, false, false)
+	if got := p.httpWriter.c.Host; got != daemonURLs[1] {
+		t.Errorf("incorrect host: got %s want %s", got, daemonURLs[1])
+	}
+
+	p = &processor{daemonURLs: daemonURLs, dbName: dbName}
+	p.Init(len(daemonURLs), false, false)
+	if got := p.httpWriter.c.Host; got != daemonURLs[0] {
+		t.Errorf("incorrect host: got %s want %s", got, daemonURLs[0])
+	}
+
+}
+
+func TestProcessorProcessBatch(t *testing.T) {
+	bufPool := &sync.Pool{
+		New: func() interface{} {
+			return bytes.NewBuffer(make([]byte, 0, 4*1024*1024))
+		},
+	}
+	f := &factory{bufPool: bufPool}
+	b := f.New().(*batch)
+	pt := data.LoadedPoint{
+		Data: []byte("tag1=tag1val,tag2=tag2val col1=0.0,col2=0.0 140"),
+	}
+	b.Append(pt)
+
+	cases := []struct {
+		doLoad      bool
+		useGzip     bool
+		shouldFatal bool
+	}{
+		{
+			doLoad:  false,
+			useGzip: false,
+		},
+		{
+			doLoad:  true,
+			useGzip: false,
+		},
+		{
+			doLoad:  true,
+			useGzip: true,
+		},
+		{
+			doLoad:      true,
+			shouldFatal: true,
+		},
+	}
+
+	for _, c := range cases {
+		var ch chan struct{}
+		fatalCalled := false
+		if c.shouldFatal {
+			fatal = func(format string, args ...interface{}) {
+				fatalCalled = true
+			}
+		} else {
+			fatal = func(format string, args ...interface{}) {
+				t.Errorf("fatal called for case %v unexpectedly\n", c)
+				fmt.Printf(format, args...)
+			}
+			ch = launchHTTPServer()
+		}
+
+		p := &processor{useGzip: c.useGzip, bufPool: bufPool}
+		p.httpWriter = NewHTTPWriter(testConf, testConsistency)
+		mCnt, rCnt := p.ProcessBatch(b, c.doLoad)
+		if c.shouldFatal {
+			if !fatalCalled {
+				t.Errorf("fatal was not called when it should have been")
+			}
+			continue
+		} else {
+			if mCnt != b.metrics {
+				t.Errorf("process batch returned less metrics than batch: got %d want %d", mCnt, b.metrics)
+			}
+			if rCnt != uint64(b.rows) {
+				t.Errorf("process batch returned less rows than batch: got %d want %d", rCnt, b.rows)
+			}
+			shutdownHTTPServer(ch)
+			time.Sleep(50 * time.Millisecond)
+		}
+	}
+}
+
+func TestProcessorProcessBatchWithError(t *testing.T) {
+	bufPool := &sync.Pool{
+		New: func() interface{} {
+			return bytes.NewBuffer(make([]byte, 0, 4*1024*1024))
+		},
+	}
+	f := &factory{bufPool: bufPool}
+	b := f.New().(*batch)
+	line := "tag1=tag1val,tag2=tag2val col1=0.0,col2=0.0 140"
+	b.Append(data.LoadedPoint{Data: []byte(line)})
+
+	fatal = func(format string, args ...interface{}) {
+		t.Errorf("fatal called unexpectedly")
+	}
+	// no server is listening, so the write fails
+	p := &processor{bufPool: bufPool}
+	p.httpWriter = NewHTTPWriter(testConf, testConsistency)
+	mCnt, rCnt, err := p.ProcessBatchWithError(b, true)
+	if err == nil {
+		t.Fatalf("expected error writing to a server that is not running")
+	}
+	if mCnt != 0 || rCnt != 0 {
+		t.Errorf("failed batch returned non-zero counts: %d metrics, %d rows", mCnt, rCnt)
+	}
+
+	// the failed batch is left intact to be retried or written elsewhere
+	var out bytes.Buffer
+	if _, err := b.WriteTo(&out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := out.String(); got != line+"\n" {
+		t.Errorf("incorrect batch contents: got %q want %q", got, line+"\n")
+	}
+}
+
+func TestProcessorProcessBatchWithErrorServerError(t *testing.T) {
+	bufPool := &sync.Pool{
+		New: func() interface{} {
+			return bytes.NewBuffer(make([]byte, 0, 4*1024*1024))
+		},
+	}
+	f := &factory{bufPool: bufPool}
+	b := f.New().(*batch)
+	b.Append(data.LoadedPoint{Data: []byte("tag1=tag1val,tag2=tag2val col1=0.0,col2=0.0 140")})
+
+	ch := launchHTTPServer()
+	defer shutdownHTTPServer(ch)
+
+	// the server fails every other request, like an overloaded server would
+	p := &processor{bufPool: bufPool}
+	p.httpWriter = NewHTTPWriter(testConf, testConsistency)
+	p.httpWriter.url = []byte(fmt.Sprintf("%s&%s=true", string(p.httpWriter.url), shouldErrParam))
+	mCnt, rCnt, err := p.ProcessBatchWithError(b, true)
+	if err == nil {
+		t.Fatalf("expected error when the server fails the write")
+	}
+	if mCnt != 0 || rCnt != 0 {
+		t.Errorf("failed batch returned non-zero counts: %d metrics, %d rows", mCnt, rCnt)
+	}
+
+	// the retry of the batch succeeds
+	mCnt, rCnt, err = p.ProcessBatchWithError(b, true)
+	if err != nil {
+		t.Fatalf("unexpected error on retry: %v", err)
+	}
+	if mCnt != b.metrics || rCnt != uint64(b.rows) {
+		t.Errorf("incorrect counts on retry: got %d metrics %d rows, want %d metrics %d rows", mCnt, rCnt, b.metrics, b.rows)
+	}
+}
@@ -1,4 +1,4 @@
-package main
+package influx
 
 import (
 	"encoding/json"
@@ -58,6 +58,17 @@ func NewHTTPClient(host string) *HTTPClient {
 // Do performs the action specified by the given Query. It uses fasthttp, and
 // tries to minimize heap allocations.
 func (w *HTTPClient) Do(q *query.HTTP, opts *HTTPClientDoOptions) (lag float64, err error) {
+	lag, _, err = w.do(q, opts)
+	return lag, err
+}
+
+// DoWithResponse performs the action specified by the given Query like Do,
+// and also returns the body of the response.
+func (w *HTTPClient) DoWithResponse(q *query.HTTP, opts *HTTPClientDoOptions) (lag float64, body []byte, err error) {
+	return w.do(q, opts)
+}
+
+func (w *HTTPClient) do(q *query.HTTP, opts *HTTPClientDoOptions) (lag float64, body []byte, err error) {
 	// populate uri from the reusable byte slice:
 	w.uri = w.uri[:0]
 	w.uri = append(w.uri, w.Host...)
@@ -86,7 +97,6 @@ func (w *HTTPClient) Do(q *query.HTTP, opts *HTTPClientDoOptions) (lag float64,
 		panic("http request did not return status 200 OK")
 	}
 
-	var body []byte
 	body, err = ioutil.ReadAll(resp.Body)
 
 	if err != nil {
@@ -132,5 +142,5 @@ func (w *HTTPClient) Do(q *query.HTTP, opts *HTTPClientDoOptions) (lag float64,
 		}
 	}
 
-	return lag, err
+	return lag, body, err
 }
@@ -0,0 +1,66 @@
+package influx
+
+import (
+	"github.com/bodhiye/tsbs/pkg/query"
+)
+
+// QueryOptions configures the processors that run queries against InfluxDB.
+type QueryOptions struct {
+	// URLs of the daemons, assigned round robin to the workers
+	URLs []string
+	// ChunkSize is the number of series to chunk results into, 0 means no
+	// chunking
+	ChunkSize      uint64
+	DBName         string
+	Debug          int
+	PrintResponses bool
+}
+
+// NewQueryProcessorCreate returns a function creating query processors that
+// run InfluxQL queries with the given options.
+func NewQueryProcessorCreate(opts *QueryOptions) query.ProcessorCreate {
+	return func() query.Processor { return &queryProcessor{opts: opts} }
+}
+
+type queryProcessor struct {
+	w      *HTTPClient
+	opts   *QueryOptions
+	doOpts *HTTPClientDoOptions
+}
+
+func (p *queryProcessor) Init(workerNumber int) {
+	p.doOpts = &HTTPClientDoOptions{
+		Debug:                p.opts.Debug,
+		PrettyPrintResponses: p.opts.PrintResponses,
+		chunkSize:            p.opts.ChunkSize,
+		database:             p.opts.DBName,
+	}
+	url := p.opts.URLs[workerNumber%len(p.opts.URLs)]
+	p.w = NewHTTPClient(url)
+}
+
+func (p *queryProcessor) ProcessQuery(q query.Query, _ bool) ([]*query.Stat, error) {
+	hq := q.(*query.HTTP)
+	lag, err := p.w.Do(hq, p.doOpts)
+	if err != nil {
+		return nil, err
+	}
+	stat := query.GetStat()
+	stat.Init(q.HumanLabelName(), lag)
+	return []*query.Stat{stat}, nil
+}
+
+func (p *queryProcessor) ProcessQueryWithResult(q query.Query, _ bool) ([]*query.Stat, *query.ResultSet, error) {
+	hq := q.(*query.HTTP)
+	lag, body, err := p.w.DoWithResponse(hq, p.doOpts)
+	if err != nil {
+		return nil, nil, err
+	}
+	rs, err := parseResultSet(body)
+	if err != nil {
+		return nil, nil, err
+	}
+	stat := query.GetStat()
+	stat.Init(q.HumanLabelName(), lag)
+	return []*query.Stat{stat}, rs, nil
+}
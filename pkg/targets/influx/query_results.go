@@ -0,0 +1,116 @@
+package influx
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/bodhiye/tsbs/pkg/query"
+)
+
+// influxResponse is the JSON response to an InfluxQL query. Chunked responses
+// consist of several of these objects, one after another.
+type influxResponse struct {
+	Results []struct {
+		Series []influxSeries `json:"series"`
+		Error  string         `json:"error"`
+	} `json:"results"`
+	Error string `json:"error"`
+}
+
+type influxSeries struct {
+	Tags    map[string]string `json:"tags"`
+	Columns []string          `json:"columns"`
+	Values  [][]interface{}   `json:"values"`
+}
+
+// parseResultSet converts the body of an InfluxDB response to a normalized
+// result set. Tag values of a series become the group keys of its rows, in
+// order of tag key, and the time column becomes the timestamp.
+func parseResultSet(body []byte) (*query.ResultSet, error) {
+	rs := &query.ResultSet{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	for {
+		var resp influxResponse
+		err := decoder.Decode(&resp)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("cannot decode response: %v", err)
+		}
+		if resp.Error != "" {
+			return nil, fmt.Errorf("query error: %s", resp.Error)
+		}
+		for _, result := range resp.Results {
+			if result.Error != "" {
+				return nil, fmt.Errorf("query error: %s", result.Error)
+			}
+			for _, series := range result.Series {
+				rows, err := seriesRows(series)
+				if err != nil {
+					return nil, err
+				}
+				rs.Rows = append(rs.Rows, rows...)
+			}
+		}
+	}
+	return rs, nil
+}
+
+func seriesRows(series influxSeries) ([]query.ResultRow, error) {
+	tagKeys := make([]string, 0, len(series.Tags))
+	for k := range series.Tags {
+		tagKeys = append(tagKeys, k)
+	}
+	sort.Strings(tagKeys)
+
+	rows := make([]query.ResultRow, 0, len(series.Values))
+	for _, values := range series.Values {
+		columns := make([]interface{}, 0, len(tagKeys)+len(values))
+		for _, k := range tagKeys {
+			columns = append(columns, series.Tags[k])
+		}
+		for i, v := range values {
+			if i < len(series.Columns) && series.Columns[i] == "time" {
+				t, err := parseTime(v)
+				if err != nil {
+					return nil, err
+				}
+				columns = append(columns, t)
+				continue
+			}
+			if n, ok := v.(json.Number); ok {
+				f, err := n.Float64()
+				if err != nil {
+					return nil, fmt.Errorf("cannot parse value %s: %v", n, err)
+				}
+				columns = append(columns, f)
+				continue
+			}
+			columns = append(columns, v)
+		}
+		rows = append(rows, query.NewResultRow(columns...))
+	}
+	return rows, nil
+}
+
+// parseTime parses the time column, which is either an RFC3339 string or,
+// when an epoch precision was requested, nanoseconds since the epoch.
+func parseTime(v interface{}) (time.Time, error) {
+	switch t := v.(type) {
+	case string:
+		return time.Parse(time.RFC3339Nano, t)
+	case json.Number:
+		ns, err := t.Int64()
+		if err != nil {
+			return time.Time{}, fmt.Errorf("cannot parse time %s: %v", t, err)
+		}
+		return time.Unix(0, ns), nil
+	default:
+		return time.Time{}, fmt.Errorf("unexpected time value %v", v)
+	}
+}
@@ -1,9 +1,11 @@
-package main
+package influx
 
 import (
 	"bufio"
 	"bytes"
+	"io"
 	"strings"
+	"sync"
 
 	"github.com/bodhiye/tsbs/pkg/data"
 	"github.com/bodhiye/tsbs/pkg/data/usecases/common"
@@ -14,8 +16,22 @@ const errNotThreeTuplesFmt = "parse error: line does not have 3 tuples, has %d"
 
 var newLine = []byte("\n")
 
+func newFileDataSource(r *bufio.Reader) targets.DataSource {
+	return &fileDataSource{scanner: targets.NewLineScanner(r)}
+}
+
 type fileDataSource struct {
-	scanner *bufio.Scanner
+	scanner *targets.LineScanner
+}
+
+// Offset implements targets.OffsetDataSource.
+func (d *fileDataSource) Offset() int64 {
+	return d.scanner.Offset()
+}
+
+// SkipTo implements targets.OffsetDataSource.
+func (d *fileDataSource) SkipTo(offset int64) error {
+	return d.scanner.SkipTo(offset)
 }
 
 func (d *fileDataSource) NextItem() data.LoadedPoint {
@@ -58,8 +74,16 @@ func (b *batch) Append(item data.LoadedPoint) {
 	b.buf.Write(newLine)
 }
 
-type factory struct{}
+// WriteTo writes the batch in line protocol.
+func (b *batch) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write(b.buf.Bytes())
+	return int64(n), err
+}
+
+type factory struct {
+	bufPool *sync.Pool
+}
 
 func (f *factory) New() targets.Batch {
-	return &batch{buf: bufPool.Get().(*bytes.Buffer)}
+	return &batch{buf: f.bufPool.Get().(*bytes.Buffer)}
 }
@@ -1,4 +1,4 @@
-package main
+package influx
 
 import (
 	"bufio"
@@ -8,15 +8,16 @@ import (
 	"testing"
 
 	"github.com/bodhiye/tsbs/pkg/data"
+	"github.com/bodhiye/tsbs/pkg/targets"
 )
 
 func TestBatch(t *testing.T) {
-	bufPool = sync.Pool{
+	bufPool := &sync.Pool{
 		New: func() interface{} {
 			return bytes.NewBuffer(make([]byte, 0, 4*1024*1024))
 		},
 	}
-	f := &factory{}
+	f := &factory{bufPool: bufPool}
 	b := f.New().(*batch)
 	if b.Len() != 0 {
 		t.Errorf("batch not initialized with count 0")
@@ -83,7 +84,7 @@ func TestFileDataSourceNextItem(t *testing.T) {
 
 	for _, c := range cases {
 		br := bufio.NewReader(bytes.NewReader([]byte(c.input)))
-		ds := &fileDataSource{scanner: bufio.NewScanner(br)}
+		ds := &fileDataSource{scanner: targets.NewLineScanner(br)}
 		p := ds.NextItem()
 		data := p.Data.([]byte)
 		if !bytes.Equal(data, c.result) {
@@ -95,7 +96,7 @@ func TestFileDataSourceNextItem(t *testing.T) {
 func TestDecodeEOF(t *testing.T) {
 	input := []byte("cpu,tag1=tag1text,tag2=tag2text col1=0.0,col2=0.0 140")
 	br := bufio.NewReader(bytes.NewReader([]byte(input)))
-	ds := &fileDataSource{scanner: bufio.NewScanner(br)}
+	ds := &fileDataSource{scanner: targets.NewLineScanner(br)}
 	_ = ds.NextItem()
 	// nothing left, should be EOF
 	p := ds.NextItem()
@@ -84,6 +84,12 @@ func appendField(buf, key []byte, v interface{}) []byte {
 	buf = append(buf, key...)
 	buf = append(buf, '=')
 
+	// String field values are double quoted, with quotes and backslashes
+	// escaped:
+	if s, ok := v.(string); ok {
+		return appendQuotedString(buf, s)
+	}
+
 	buf = serialize.FastFormatAppend(v, buf)
 
 	// Influx uses 'i' to indicate integers:
@@ -94,3 +100,14 @@ func appendField(buf, key []byte, v interface{}) []byte {
 
 	return buf
 }
+
+func appendQuotedString(buf []byte, s string) []byte {
+	buf = append(buf, '"')
+	for i := 0; i < len(s); i++ {
+		if s[i] == '"' || s[i] == '\\' {
+			buf = append(buf, '\\')
+		}
+		buf = append(buf, s[i])
+	}
+	return append(buf, '"')
+}
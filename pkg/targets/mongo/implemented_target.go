@@ -1,11 +1,13 @@
 package mongo
 
 import (
+	"sync"
 	"time"
 
 	"github.com/spf13/viper"
 	"github.com/bodhiye/tsbs/pkg/data/serialize"
 	"github.com/bodhiye/tsbs/pkg/data/source"
+	"github.com/bodhiye/tsbs/pkg/query"
 	"github.com/bodhiye/tsbs/pkg/targets"
 	"github.com/bodhiye/tsbs/pkg/targets/constants"
 	"github.com/spf13/pflag"
@@ -35,3 +37,23 @@ func (t *mongoTarget) Serializer() serialize.PointSerializer {
 func (t *mongoTarget) Benchmark(string, *source.DataSourceConfig, *viper.Viper) (targets.Benchmark, error) {
 	panic("not implemented")
 }
+
+func (t *mongoTarget) QueryPool() *sync.Pool {
+	return &query.MongoPool
+}
+
+func (t *mongoTarget) QueryProcessorCreate(
+	targetDB string, runnerConfig *query.BenchmarkRunnerConfig, v *viper.Viper,
+) (query.ProcessorCreate, error) {
+	return NewQueryProcessorCreate(&QueryOptions{
+		URL:            v.GetString("url"),
+		ReadTimeout:    v.GetDuration("read-timeout"),
+		DBName:         targetDB,
+		Debug:          runnerConfig.Debug,
+		PrintResponses: runnerConfig.PrintResponses,
+	})
+}
+
+func (t *mongoTarget) QueryFlags(flagPrefix string, flagSet *pflag.FlagSet) {
+	flagSet.Duration(flagPrefix+"read-timeout", 30*time.Second, "Timeout value for individual queries")
+}
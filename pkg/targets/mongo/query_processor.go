@@ -0,0 +1,87 @@
+package mongo
+
+import (
+	"encoding/gob"
+	"fmt"
+	"time"
+
+	"github.com/bodhiye/tsbs/pkg/query"
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+)
+
+func init() {
+	// needed for deserializing the mongo query from gob
+	gob.Register([]interface{}{})
+	gob.Register(map[string]interface{}{})
+	gob.Register([]map[string]interface{}{})
+	gob.Register(bson.M{})
+	gob.Register([]bson.M{})
+}
+
+// QueryOptions configures the processors that run queries against MongoDB.
+type QueryOptions struct {
+	URL            string
+	ReadTimeout    time.Duration
+	DBName         string
+	Debug          int
+	PrintResponses bool
+}
+
+// NewQueryProcessorCreate connects to MongoDB and returns a function creating
+// query processors that run Mongo queries with the given options, each one
+// with a copy of the session.
+func NewQueryProcessorCreate(opts *QueryOptions) (query.ProcessorCreate, error) {
+	session, err := mgo.DialWithTimeout(opts.URL, opts.ReadTimeout)
+	if err != nil {
+		return nil, err
+	}
+	return func() query.Processor { return &queryProcessor{session: session, opts: opts} }, nil
+}
+
+type queryProcessor struct {
+	session    *mgo.Session
+	opts       *QueryOptions
+	sess       *mgo.Session
+	collection *mgo.Collection
+}
+
+func (p *queryProcessor) Init(workerNumber int) {
+	p.sess = p.session.Copy()
+	db := p.sess.DB(p.opts.DBName)
+	p.collection = db.C("point_data")
+}
+
+func (p *queryProcessor) Close() {
+	if p.sess != nil {
+		p.sess.Close()
+	}
+}
+
+func (p *queryProcessor) ProcessQuery(q query.Query, _ bool) ([]*query.Stat, error) {
+	mq := q.(*query.Mongo)
+	start := time.Now().UnixNano()
+	pipe := p.collection.Pipe(mq.BsonDoc).AllowDiskUse()
+	iter := pipe.Iter()
+	if p.opts.Debug > 0 {
+		fmt.Println(mq.BsonDoc)
+	}
+	var result map[string]interface{}
+	cnt := 0
+	for iter.Next(&result) {
+		if p.opts.PrintResponses {
+			fmt.Printf("ID %d: %v\n", q.GetID(), result)
+		}
+		cnt++
+	}
+	if p.opts.Debug > 0 {
+		fmt.Println(cnt)
+	}
+	err := iter.Close()
+
+	took := time.Now().UnixNano() - start
+	lag := float64(took) / 1e6 // milliseconds
+	stat := query.GetStat()
+	stat.Init(q.HumanLabelName(), lag)
+	return []*query.Stat{stat}, err
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/binary"
 	"fmt"
 	"github.com/bodhiye/tsbs/pkg/data"
+	"github.com/bodhiye/tsbs/pkg/data/serialize"
 	"io"
 	"sync"
 
@@ -30,20 +31,22 @@ func (s *Serializer) Serialize(p *data.Point, w io.Writer) (err error) {
 	tagKeys := p.TagKeys()
 	tagValues := p.TagValues()
 	for i := len(tagKeys); i > 0; i-- {
+		var val flatbuffers.UOffsetT
 		switch v := tagValues[i-1].(type) {
 		case string:
-			k := string(tagKeys[i-1])
-			key := b.CreateString(k)
-			val := b.CreateString(v)
-			MongoTagStart(b)
-			MongoTagAddKey(b, key)
-			MongoTagAddValue(b, val)
-			tags = append(tags, MongoTagEnd(b))
+			val = b.CreateString(v)
 		case nil:
 			continue
 		default:
-			panic("non-string tags not implemented for mongo db")
+			// Tags are stored as strings, so non-string values (e.g. the
+			// iot truck capacities) are formatted as such.
+			val = b.CreateByteString(serialize.FastFormatAppend(v, []byte{}))
 		}
+		key := b.CreateString(string(tagKeys[i-1]))
+		MongoTagStart(b)
+		MongoTagAddKey(b, key)
+		MongoTagAddValue(b, val)
+		tags = append(tags, MongoTagEnd(b))
 	}
 	MongoPointStartTagsVector(b, len(tags))
 	for _, t := range tags {
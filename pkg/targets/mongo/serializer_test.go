@@ -77,6 +77,18 @@ func TestMongoSerializerSerialize(t *testing.T) {
 				readingVals: serialize.TestPointNoTags().FieldValues(),
 			},
 		},
+		{
+			desc:       "a Point with a numeric tag",
+			inputPoint: testPointWithNumericTag(),
+			want: output{
+				name:        string(serialize.TestMeasurement),
+				ts:          serialize.TestNow.UnixNano(),
+				tagKeys:     [][]byte{[]byte("hostname"), []byte("load_capacity")},
+				tagVals:     []interface{}{"host_0", "1500"},
+				readingKeys: testPointWithNumericTag().FieldKeys(),
+				readingVals: testPointWithNumericTag().FieldValues(),
+			},
+		},
 	}
 
 	ps := &Serializer{}
@@ -145,6 +157,16 @@ func TestMongoSerializerSerialize(t *testing.T) {
 	}
 }
 
+func testPointWithNumericTag() *data.Point {
+	p := data.NewPoint()
+	p.SetMeasurementName(serialize.TestMeasurement)
+	p.SetTimestamp(&serialize.TestNow)
+	p.AppendTag([]byte("hostname"), "host_0")
+	p.AppendTag([]byte("load_capacity"), float64(1500))
+	p.AppendField(serialize.TestColFloat, serialize.TestFloat)
+	return p
+}
+
 func deserializeMongo(r *bufio.Reader) *MongoPoint {
 	item := &MongoPoint{}
 	lenBuf := make([]byte, 8)
@@ -8,6 +8,18 @@ type Processor interface {
 	ProcessBatch(b Batch, doLoad bool) (metricCount, rowCount uint64)
 }
 
+// ProcessorWithError is a Processor that reports batches it could not insert
+// instead of handling the failure itself, so the runner can retry them
+type ProcessorWithError interface {
+	Processor
+	// ProcessBatchWithError handles a single batch of data like ProcessBatch,
+	// but returns an error if the batch could not be inserted. The batch may
+	// be retried afterwards, so on error it must be left with the points
+	// that were not inserted, and the counts returned are those of the
+	// points that were, if any
+	ProcessBatchWithError(b Batch, doLoad bool) (metricCount, rowCount uint64, err error)
+}
+
 // ProcessorCloser is a Processor that also needs to close or cleanup afterwards
 type ProcessorCloser interface {
 	Processor
@@ -1,6 +1,7 @@
 package prometheus
 
 import (
+	"fmt"
 	"log"
 	"time"
 
@@ -22,6 +23,43 @@ type simulationDataSource struct {
 	simulator       common.Simulator
 	headers         *common.GeneratedDataHeaders
 	generatedSeries *timeSeriesIterator
+	// pos is the position after the series read so far, and current the
+	// timestamp of the simulated point of the series being read
+	pos     targets.SimulatorPosition
+	current time.Time
+}
+
+// SimulatorPosition implements targets.SimulatedDataSource.
+func (d *simulationDataSource) SimulatorPosition() targets.SimulatorPosition {
+	return d.pos
+}
+
+// FastForward implements targets.SimulatedDataSource.
+func (d *simulationDataSource) FastForward(pos targets.SimulatorPosition) error {
+	skipped, last := common.FastForward(d.simulator, pos.Points)
+	if skipped < pos.Points {
+		return fmt.Errorf("simulator finished after %d points, before %d", skipped, pos.Points)
+	}
+	d.pos = targets.SimulatorPosition{Points: skipped, Time: last}
+	for i := uint64(0); i < pos.Partial; i++ {
+		if d.NextItem().Data == nil {
+			return fmt.Errorf("simulator finished before the position %+v", pos)
+		}
+	}
+	return nil
+}
+
+// read returns the next series of the current simulated point, advancing
+// the position.
+func (d *simulationDataSource) read() data.LoadedPoint {
+	next := d.generatedSeries.Next()
+	d.pos.Partial++
+	if !d.generatedSeries.HasNext() {
+		d.pos.Points++
+		d.pos.Partial = 0
+		d.pos.Time = d.current
+	}
+	return data.LoadedPoint{Data: next}
 }
 
 func (d *simulationDataSource) Headers() *common.GeneratedDataHeaders {
@@ -35,8 +73,7 @@ func (d *simulationDataSource) Headers() *common.GeneratedDataHeaders {
 
 func (d *simulationDataSource) NextItem() data.LoadedPoint {
 	if d.generatedSeries.HasNext() {
-		next := d.generatedSeries.Next()
-		return data.LoadedPoint{Data: next}
+		return d.read()
 	}
 
 	newSimulatorPoint := data.NewPoint()
@@ -56,8 +93,8 @@ func (d *simulationDataSource) NextItem() data.LoadedPoint {
 	if err != nil {
 		log.Printf("Couldn't convert simulated point to Prometheus TimeSeries: %v", err)
 	}
-	next := d.generatedSeries.Next()
-	return data.LoadedPoint{Data: next}
+	d.current = *newSimulatorPoint.Timestamp()
+	return d.read()
 }
 
 type timeSeriesIterator struct {
@@ -1,9 +1,13 @@
 package questdb
 
 import (
+	"strings"
+	"sync"
+
 	"github.com/spf13/viper"
 	"github.com/bodhiye/tsbs/pkg/data/serialize"
 	"github.com/bodhiye/tsbs/pkg/data/source"
+	"github.com/bodhiye/tsbs/pkg/query"
 	"github.com/bodhiye/tsbs/pkg/targets"
 	"github.com/bodhiye/tsbs/pkg/targets/constants"
 	"github.com/spf13/pflag"
@@ -32,3 +36,20 @@ func (t *influxTarget) Serializer() serialize.PointSerializer {
 func (t *influxTarget) Benchmark(string, *source.DataSourceConfig, *viper.Viper) (targets.Benchmark, error) {
 	panic("not implemented")
 }
+
+func (t *influxTarget) QueryPool() *sync.Pool {
+	return &query.HTTPPool
+}
+
+func (t *influxTarget) QueryProcessorCreate(
+	_ string, runnerConfig *query.BenchmarkRunnerConfig, v *viper.Viper,
+) (query.ProcessorCreate, error) {
+	return NewQueryProcessorCreate(&QueryOptions{
+		// the url may be a comma separated list of end points
+		URLs:           strings.Split(v.GetString("url"), ","),
+		Debug:          runnerConfig.Debug,
+		PrintResponses: runnerConfig.PrintResponses,
+	}), nil
+}
+
+func (t *influxTarget) QueryFlags(string, *pflag.FlagSet) {}
@@ -0,0 +1,100 @@
+package questdb
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/bodhiye/tsbs/pkg/query"
+)
+
+// QueryOptions configures the processors that run queries against QuestDB.
+type QueryOptions struct {
+	// URLs of the REST end points, assigned round robin to the workers
+	URLs           []string
+	Debug          int
+	PrintResponses bool
+}
+
+// NewQueryProcessorCreate returns a function creating query processors that
+// run QuestDB queries with the given options. It adds an index to the
+// hostname column of the cpu table first, if the table exists.
+func NewQueryProcessorCreate(opts *QueryOptions) query.ProcessorCreate {
+	// Add an index to the hostname column in the cpu table
+	r, err := execQuery(opts.URLs[0], "show columns from cpu")
+	if err == nil && r.Count != 0 {
+		_, err := execQuery(opts.URLs[0], "ALTER TABLE cpu ALTER COLUMN hostname ADD INDEX")
+		if err == nil {
+			fmt.Println("Added index to hostname column of cpu table")
+		}
+	}
+	return func() query.Processor { return &queryProcessor{opts: opts} }
+}
+
+type queryProcessor struct {
+	w      *HTTPClient
+	opts   *QueryOptions
+	doOpts *HTTPClientDoOptions
+}
+
+func (p *queryProcessor) Init(workerNumber int) {
+	p.doOpts = &HTTPClientDoOptions{
+		Debug:                p.opts.Debug,
+		PrettyPrintResponses: p.opts.PrintResponses,
+	}
+	url := p.opts.URLs[workerNumber%len(p.opts.URLs)]
+	p.w = NewHTTPClient(url)
+}
+
+func (p *queryProcessor) ProcessQuery(q query.Query, _ bool) ([]*query.Stat, error) {
+	hq := q.(*query.HTTP)
+	lag, err := p.w.Do(hq, p.doOpts)
+	if err != nil {
+		return nil, err
+	}
+	stat := query.GetStat()
+	stat.Init(q.HumanLabelName(), lag)
+	return []*query.Stat{stat}, nil
+}
+
+type QueryResponseColumns struct {
+	Name string
+	Type string
+}
+
+type QueryResponse struct {
+	Query   string
+	Columns []QueryResponseColumns
+	Dataset []interface{}
+	Count   int
+	Error   string
+}
+
+func execQuery(uriRoot string, query string) (QueryResponse, error) {
+	var qr QueryResponse
+	if strings.HasSuffix(uriRoot, "/") {
+		uriRoot = uriRoot[:len(uriRoot)-1]
+	}
+	uriRoot = uriRoot + "/exec?query=" + url.QueryEscape(query)
+	resp, err := http.Get(uriRoot)
+	if err != nil {
+		return qr, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return qr, err
+	}
+	err = json.Unmarshal(body, &qr)
+	if err != nil {
+		return qr, err
+	}
+	if qr.Error != "" {
+		return qr, errors.New(qr.Error)
+	}
+	return qr, nil
+}
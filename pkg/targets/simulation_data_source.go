@@ -0,0 +1,203 @@
+package targets
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/bodhiye/tsbs/pkg/data"
+	"github.com/bodhiye/tsbs/pkg/data/serialize"
+	"github.com/bodhiye/tsbs/pkg/data/usecases/common"
+)
+
+const simulationReadSize = 4 << 20 // 4 MB
+
+// fatal is used to stop the load when a simulated point can't be
+// serialized, like the file data sources do on malformed input. Returning
+// the error instead would make the scanner of the data source treat it as
+// the end of the data.
+var fatal = log.Fatalf
+
+// ReaderDataSourceFn creates a DataSource that decodes serialized points from
+// the supplied reader. Targets usually pass the same constructor they use to
+// read files generated by tsbs_generate_data.
+type ReaderDataSourceFn func(r *bufio.Reader) DataSource
+
+// NewSimulationDataSource returns a DataSource that reads points straight
+// from the simulator instead of from a pre-generated file. Each simulated
+// point is serialized with the target's serializer and the resulting stream
+// is decoded by the DataSource created with newDataSource, so a target only
+// needs its serializer and file parser to support the SIMULATOR data source.
+// If writeHeaders is set, the stream starts with the same header
+// tsbs_generate_data writes for formats that require one.
+// The DataSource is a SimulatedDataSource if the one created with
+// newDataSource is an OffsetDataSource.
+func NewSimulationDataSource(
+	sim common.Simulator,
+	serializer serialize.PointSerializer,
+	writeHeaders bool,
+	newDataSource ReaderDataSourceFn,
+) DataSource {
+	r := &simulatorReader{
+		sim:        sim,
+		serializer: serializer,
+	}
+	if writeHeaders {
+		WriteHeaders(&r.buf, sim.Headers())
+		r.written = int64(r.buf.Len())
+	}
+	ds := newDataSource(bufio.NewReaderSize(r, simulationReadSize))
+	decoder, ok := ds.(OffsetDataSource)
+	if !ok {
+		return ds
+	}
+	return &simulationDataSource{DataSource: ds, decoder: decoder, reader: r}
+}
+
+// simulatedPoint is the end offset in the serialized stream and the
+// timestamp of a simulated point.
+type simulatedPoint struct {
+	end  int64
+	time time.Time
+}
+
+// simulatorReader is an io.Reader over the serialized output of a simulator.
+// Points are only generated when the buffered output has been consumed, so
+// memory usage does not grow with the size of the simulated dataset.
+type simulatorReader struct {
+	sim        common.Simulator
+	serializer serialize.PointSerializer
+	buf        bytes.Buffer
+	// written is the number of bytes serialized so far
+	written int64
+	// points holds the points serialized but not yet decoded
+	points []simulatedPoint
+}
+
+func (r *simulatorReader) Read(p []byte) (int, error) {
+	for r.buf.Len() == 0 {
+		if r.sim.Finished() {
+			return 0, io.EOF
+		}
+		point := data.NewPoint()
+		if !r.sim.Next(point) {
+			continue
+		}
+		if err := r.serializer.Serialize(point, &r.buf); err != nil {
+			fatal("can not serialize point: %v", err)
+			return 0, err
+		}
+		r.written += int64(r.buf.Len())
+		sp := simulatedPoint{end: r.written}
+		if ts := point.Timestamp(); ts != nil {
+			sp.time = *ts
+		}
+		r.points = append(r.points, sp)
+	}
+	return r.buf.Read(p)
+}
+
+// simulationDataSource maps the points decoded from the serialized stream
+// back to the simulated points they come from, using the offset of the
+// decoder in the stream, to keep track of its SimulatorPosition.
+type simulationDataSource struct {
+	DataSource
+	decoder OffsetDataSource
+	reader  *simulatorReader
+	pos     SimulatorPosition
+}
+
+func (d *simulationDataSource) NextItem() data.LoadedPoint {
+	item := d.DataSource.NextItem()
+	if item.Data == nil {
+		return item
+	}
+	d.pos.Partial++
+	offset := d.decoder.Offset()
+	points := d.reader.points
+	for len(points) > 0 && points[0].end <= offset {
+		d.pos.Points++
+		d.pos.Partial = 0
+		d.pos.Time = points[0].time
+		points = points[1:]
+	}
+	d.reader.points = points
+	return item
+}
+
+// SimulatorPosition implements SimulatedDataSource.
+func (d *simulationDataSource) SimulatorPosition() SimulatorPosition {
+	return d.pos
+}
+
+// FastForward implements SimulatedDataSource. The simulated points skipped
+// are not serialized, so only the loaded points of a partially read
+// simulated point are decoded.
+func (d *simulationDataSource) FastForward(pos SimulatorPosition) error {
+	skipped, last := common.FastForward(d.reader.sim, pos.Points)
+	if skipped < pos.Points {
+		return fmt.Errorf("simulator finished after %d points, before %d", skipped, pos.Points)
+	}
+	d.pos = SimulatorPosition{Points: skipped, Time: last}
+	if pos.Partial > 0 {
+		// the headers, if any, are still ahead of the points in the stream
+		d.Headers()
+	}
+	for i := uint64(0); i < pos.Partial; i++ {
+		if d.NextItem().Data == nil {
+			return fmt.Errorf("simulator finished before the position %+v", pos)
+		}
+	}
+	return nil
+}
+
+// WriteHeaders writes the header describing the tags and fields of the
+// generated data, as expected by the pseudo-CSV formats (e.g. TimescaleDB,
+// ClickHouse and CrateDB):
+//
+//	tags,<tag1> <type1>,...,<tagN> <typeN>
+//	<measurement1>,<field1>,...,<fieldN>
+//	...
+//	<empty line>
+//
+// The fields of a measurement are written with their types, like the tags,
+// if the headers have any for it.
+func WriteHeaders(w io.Writer, headers *common.GeneratedDataHeaders) {
+	buf := make([]byte, 0, 1024)
+	buf = append(buf, "tags"...)
+
+	types := headers.TagTypes
+	for i, key := range headers.TagKeys {
+		buf = append(buf, ',')
+		buf = append(buf, key...)
+		buf = append(buf, ' ')
+		buf = append(buf, types[i]...)
+	}
+	buf = append(buf, '\n')
+	// sort the keys so the header is deterministic
+	keys := make([]string, 0)
+	fields := headers.FieldKeys
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, measurementName := range keys {
+		buf = append(buf, measurementName...)
+		fieldTypes := headers.FieldTypes[measurementName]
+		for i, field := range fields[measurementName] {
+			buf = append(buf, ',')
+			buf = append(buf, field...)
+			if fieldTypes != nil {
+				buf = append(buf, ' ')
+				buf = append(buf, fieldTypes[i]...)
+			}
+		}
+		buf = append(buf, '\n')
+	}
+	buf = append(buf, '\n')
+	w.Write(buf)
+}
@@ -0,0 +1,232 @@
+package targets
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/bodhiye/tsbs/pkg/data"
+	"github.com/bodhiye/tsbs/pkg/data/serialize"
+	"github.com/bodhiye/tsbs/pkg/data/usecases/common"
+)
+
+var keyIteration = []byte("iteration")
+
+type testSimulator struct {
+	limit     uint64
+	iteration uint64
+}
+
+func (s *testSimulator) Finished() bool {
+	return s.iteration >= s.limit
+}
+
+func (s *testSimulator) Next(p *data.Point) bool {
+	p.AppendField(keyIteration, s.iteration)
+	s.iteration++
+	// skip every third point to check that unwritten points are not serialized
+	return s.iteration%3 != 0
+}
+
+func (s *testSimulator) Fields() map[string][]string {
+	return map[string][]string{"cpu": {"usage_user", "usage_system"}}
+}
+
+func (s *testSimulator) TagKeys() []string {
+	return []string{"hostname"}
+}
+
+func (s *testSimulator) TagTypes() []string {
+	return []string{"string"}
+}
+
+func (s *testSimulator) Headers() *common.GeneratedDataHeaders {
+	return &common.GeneratedDataHeaders{
+		TagTypes:  s.TagTypes(),
+		TagKeys:   s.TagKeys(),
+		FieldKeys: s.Fields(),
+	}
+}
+
+type testSerializer struct{}
+
+func (s *testSerializer) Serialize(p *data.Point, w io.Writer) error {
+	_, err := fmt.Fprintf(w, "iteration=%d\n", p.GetFieldValue(keyIteration).(uint64))
+	return err
+}
+
+// pairSerializer serializes a point as two lines, like targets that load a
+// simulated point as several points.
+type pairSerializer struct{}
+
+func (s *pairSerializer) Serialize(p *data.Point, w io.Writer) error {
+	it := p.GetFieldValue(keyIteration).(uint64)
+	_, err := fmt.Fprintf(w, "iteration=%d,a\niteration=%d,b\n", it, it)
+	return err
+}
+
+type lineDataSource struct {
+	scanner *LineScanner
+}
+
+func (d *lineDataSource) NextItem() data.LoadedPoint {
+	if !d.scanner.Scan() {
+		return data.LoadedPoint{}
+	}
+	return data.NewLoadedPoint(d.scanner.Text())
+}
+
+func (d *lineDataSource) Headers() *common.GeneratedDataHeaders {
+	return nil
+}
+
+func (d *lineDataSource) Offset() int64 {
+	return d.scanner.Offset()
+}
+
+func (d *lineDataSource) SkipTo(offset int64) error {
+	return d.scanner.SkipTo(offset)
+}
+
+func newLineDataSource(r *bufio.Reader) DataSource {
+	return &lineDataSource{scanner: NewLineScanner(r)}
+}
+
+func TestNewSimulationDataSource(t *testing.T) {
+	cases := []struct {
+		desc         string
+		writeHeaders bool
+		want         []string
+	}{
+		{
+			desc: "without headers",
+			want: []string{"iteration=0", "iteration=1", "iteration=3", "iteration=4"},
+		},
+		{
+			desc:         "with headers",
+			writeHeaders: true,
+			want: []string{
+				"tags,hostname string", "cpu,usage_user,usage_system", "",
+				"iteration=0", "iteration=1", "iteration=3", "iteration=4",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			sim := &testSimulator{limit: 6}
+			ds := NewSimulationDataSource(sim, &testSerializer{}, c.writeHeaders, newLineDataSource)
+			var got []string
+			for item := ds.NextItem(); item.Data != nil; item = ds.NextItem() {
+				got = append(got, item.Data.(string))
+			}
+			if strings.Join(got, "\n") != strings.Join(c.want, "\n") {
+				t.Errorf("incorrect lines read:\ngot\n%v\nwant\n%v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestSimulationDataSourcePosition(t *testing.T) {
+	cases := []struct {
+		desc       string
+		serializer serialize.PointSerializer
+		read       int
+		wantPos    SimulatorPosition
+		wantNext   string
+	}{
+		{
+			desc:       "point per simulated point",
+			serializer: &testSerializer{},
+			read:       3,
+			wantPos:    SimulatorPosition{Points: 3},
+			wantNext:   "iteration=4",
+		},
+		{
+			desc:       "points per simulated point",
+			serializer: &pairSerializer{},
+			read:       3,
+			wantPos:    SimulatorPosition{Points: 1, Partial: 1},
+			wantNext:   "iteration=1,b",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			ds := NewSimulationDataSource(&testSimulator{limit: 9}, c.serializer, false, newLineDataSource).(SimulatedDataSource)
+			for i := 0; i < c.read; i++ {
+				ds.NextItem()
+			}
+			pos := ds.SimulatorPosition()
+			if pos != c.wantPos {
+				t.Errorf("incorrect position: got %+v want %+v", pos, c.wantPos)
+			}
+
+			// a new data source fast-forwarded to the position continues
+			// with the same point
+			ds = NewSimulationDataSource(&testSimulator{limit: 9}, c.serializer, false, newLineDataSource).(SimulatedDataSource)
+			if err := ds.FastForward(pos); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := ds.NextItem().Data; got != c.wantNext {
+				t.Errorf("incorrect point after fast-forwarding: got %v want %v", got, c.wantNext)
+			}
+		})
+	}
+}
+
+func TestSimulationDataSourceFastForwardPastEnd(t *testing.T) {
+	ds := NewSimulationDataSource(&testSimulator{limit: 9}, &testSerializer{}, false, newLineDataSource).(SimulatedDataSource)
+	if err := ds.FastForward(SimulatorPosition{Points: 7}); err == nil {
+		t.Errorf("expected an error fast-forwarding past the end of the simulator")
+	}
+}
+
+type failingSerializer struct{}
+
+func (s *failingSerializer) Serialize(p *data.Point, w io.Writer) error {
+	return fmt.Errorf("serialize failed")
+}
+
+func TestNewSimulationDataSourceSerializeError(t *testing.T) {
+	oldFatal := fatal
+	defer func() { fatal = oldFatal }()
+	var got string
+	fatal = func(format string, args ...interface{}) {
+		got = fmt.Sprintf(format, args...)
+	}
+
+	ds := NewSimulationDataSource(&testSimulator{limit: 6}, &failingSerializer{}, false, newLineDataSource)
+	if item := ds.NextItem(); item.Data != nil {
+		t.Errorf("unexpected item read: %v", item.Data)
+	}
+	if want := "can not serialize point: serialize failed"; got != want {
+		t.Errorf("incorrect fatal message: got %q want %q", got, want)
+	}
+}
+
+func TestWriteHeaders(t *testing.T) {
+	headers := &common.GeneratedDataHeaders{
+		TagTypes: []string{"string", "string"},
+		TagKeys:  []string{"service", "region"},
+		FieldKeys: map[string][]string{
+			"events": {"level", "status_code", "latency_ms"},
+			"cpu":    {"usage_user", "usage_system"},
+		},
+		FieldTypes: map[string][]string{
+			"events": {"string", "int64", "float64"},
+		},
+	}
+	want := "tags,service string,region string\n" +
+		"cpu,usage_user,usage_system\n" +
+		"events,level string,status_code int64,latency_ms float64\n" +
+		"\n"
+
+	var b strings.Builder
+	WriteHeaders(&b, headers)
+	if got := b.String(); got != want {
+		t.Errorf("incorrect headers:\ngot\n%s\nwant\n%s", got, want)
+	}
+}
@@ -1,9 +1,12 @@
 package siridb
 
 import (
+	"sync"
+
 	"github.com/spf13/viper"
 	"github.com/bodhiye/tsbs/pkg/data/serialize"
 	"github.com/bodhiye/tsbs/pkg/data/source"
+	"github.com/bodhiye/tsbs/pkg/query"
 	"github.com/bodhiye/tsbs/pkg/targets"
 	"github.com/bodhiye/tsbs/pkg/targets/constants"
 	"github.com/spf13/pflag"
@@ -38,3 +41,37 @@ func (t *siriTarget) Serializer() serialize.PointSerializer {
 func (t *siriTarget) Benchmark(string, *source.DataSourceConfig, *viper.Viper) (targets.Benchmark, error) {
 	panic("not implemented")
 }
+
+func (t *siriTarget) QueryPool() *sync.Pool {
+	return &query.SiriDBPool
+}
+
+func (t *siriTarget) QueryProcessorCreate(
+	targetDB string, runnerConfig *query.BenchmarkRunnerConfig, v *viper.Viper,
+) (query.ProcessorCreate, error) {
+	opts := &QueryOptions{
+		Hosts:          v.GetString("hosts"),
+		DBUser:         v.GetString("dbuser"),
+		DBPass:         v.GetString("dbpass"),
+		DBName:         targetDB,
+		Scale:          v.GetUint64("scale"),
+		TagValueLength: v.GetInt("devops-tag-value-length"),
+		QueryLimit:     v.GetUint64("query-limit"),
+		WriteTimeout:   v.GetInt("write-timeout"),
+		ShowExplain:    v.GetBool("show-explain"),
+		Debug:          runnerConfig.Debug > 0,
+		PrintResponses: runnerConfig.PrintResponses,
+	}
+	if opts.ShowExplain {
+		// only the EXPLAIN output of the first query is printed
+		runnerConfig.Limit = 1
+	}
+	return NewQueryProcessorCreate(opts)
+}
+
+func (t *siriTarget) QueryFlags(flagPrefix string, flagSet *pflag.FlagSet) {
+	flagSet.Uint64(flagPrefix+"scale", 8, "Scaling variable (Must be the equal to the scalevar used for data generation).")
+	flagSet.Int(flagPrefix+"devops-tag-value-length", 0, "Minimum length the hostnames were padded to (Must be equal to the one used for data generation).")
+	flagSet.Uint64(flagPrefix+"query-limit", 1000000, "Changes the maximum points which can be returned by a select query.")
+	flagSet.Bool(flagPrefix+"show-explain", false, "Print out the EXPLAIN output for sample query")
+}
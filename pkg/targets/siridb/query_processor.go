@@ -0,0 +1,188 @@
+package siridb
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	siridb "github.com/SiriDB/go-siridb-connector"
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/devops"
+	devopsData "github.com/bodhiye/tsbs/pkg/data/usecases/devops"
+	"github.com/bodhiye/tsbs/pkg/query"
+)
+
+var errNotConnected = errors.New("not even a single server is connected...")
+
+// QueryOptions configures the processors that run queries against SiriDB.
+type QueryOptions struct {
+	// Hosts is a comma separated list of host:port of the SiriDB servers
+	Hosts  string
+	DBUser string
+	DBPass string
+	DBName string
+	// Scale must be the same as the one the data was generated with
+	Scale uint64
+	// TagValueLength must be the devops tag value length the data was
+	// generated with, as the groups are named after the hostnames
+	TagValueLength int
+	// QueryLimit is the maximum number of points returned by a select query
+	QueryLimit     uint64
+	WriteTimeout   int
+	ShowExplain    bool
+	Debug          bool
+	PrintResponses bool
+}
+
+// NewQueryProcessorCreate connects to SiriDB, sets the query limit and creates
+// the groups the queries use, and returns a function creating query
+// processors that run SiriDB queries with the given options. The connection
+// is closed once the last processor is closed.
+func NewQueryProcessorCreate(opts *QueryOptions) (query.ProcessorCreate, error) {
+	hostlist := [][]interface{}{}
+	for _, hostport := range strings.Split(opts.Hosts, ",") {
+		x := strings.Split(hostport, ":")
+		if len(x) != 2 {
+			return nil, fmt.Errorf("invalid SiriDB host '%s', expected host:port", hostport)
+		}
+		port, err := strconv.ParseInt(x[1], 10, 0)
+		if err != nil {
+			return nil, err
+		}
+		hostlist = append(hostlist, []interface{}{x[0], int(port)})
+	}
+
+	c := &queryClient{
+		opts: opts,
+		client: siridb.NewClient(
+			opts.DBUser, // username
+			opts.DBPass, // password
+			opts.DBName, // database
+			hostlist,    // siridb server(s)
+			nil,         // optional log channel
+		),
+	}
+	c.client.Connect()
+	if err := c.changeQueryLimit(); err != nil {
+		c.client.Close()
+		return nil, err
+	}
+	if err := c.createGroups(); err != nil {
+		c.client.Close()
+		return nil, err
+	}
+	return func() query.Processor { return &queryProcessor{client: c} }, nil
+}
+
+// queryClient is the connection to SiriDB shared by the query processors.
+type queryClient struct {
+	opts   *QueryOptions
+	client *siridb.Client
+	mu     sync.Mutex
+	users  int
+}
+
+func (c *queryClient) query(qry string) (interface{}, error) {
+	if !c.client.IsConnected() {
+		return nil, errNotConnected
+	}
+	return c.client.Query(qry, uint16(c.opts.WriteTimeout))
+}
+
+// changeQueryLimit changes the maximum points which can be returned by a select query. The default
+// and recommended value is set to one million points. This value is chosen to
+// prevent a single query for taking to much memory and ensures SiriDB can respond
+// to almost any query in a reasonable amount of time.
+func (c *queryClient) changeQueryLimit() error {
+	_, err := c.query(fmt.Sprintf("alter database set select_points_limit %d", c.opts.QueryLimit))
+	return err
+}
+
+// createGroups makes groups representing regular expression to enhance performance
+func (c *queryClient) createGroups() error {
+	if !c.client.IsConnected() {
+		return errNotConnected
+	}
+	created := true
+	metrics := devops.GetAllCPUMetrics()
+	siriql := make([]string, 0, 2048)
+	for _, m := range metrics {
+		siriql = append(siriql, fmt.Sprintf("create group `%s` for /.*%s$/", m, m))
+	}
+
+	var n uint64
+	for n = 0; n < c.opts.Scale; n++ {
+		host := devopsData.HostName(int(n), c.opts.TagValueLength)
+		siriql = append(siriql, fmt.Sprintf("create group `%s` for /.*%s,.*/", host, host))
+	}
+	siriql = append(siriql, fmt.Sprintf("create group `cpu` for /.*^cpu.*/"))
+	for _, qry := range siriql {
+		if _, err := c.query(qry); err != nil {
+			created = false
+		}
+	}
+	if created {
+		time.Sleep(6 * time.Second) // because the groups are created in a seperate thread every 2 seconds.
+	}
+	return nil
+}
+
+func (c *queryClient) acquire() {
+	c.mu.Lock()
+	c.users++
+	c.mu.Unlock()
+}
+
+func (c *queryClient) release() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.users--
+	if c.users == 0 {
+		c.client.Close()
+	}
+}
+
+type queryProcessor struct {
+	client *queryClient
+}
+
+func (p *queryProcessor) Init(numWorker int) {
+	p.client.acquire()
+}
+
+func (p *queryProcessor) Close() {
+	p.client.release()
+}
+
+func (p *queryProcessor) ProcessQuery(q query.Query, isWarm bool) ([]*query.Stat, error) {
+	opts := p.client.opts
+	// No need to run again for EXPLAIN
+	if isWarm && opts.ShowExplain {
+		return nil, nil
+	}
+	tq := q.(*query.SiriDB)
+
+	start := time.Now()
+	qry := string(tq.SqlQuery)
+
+	res, err := p.client.query(qry)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Debug {
+		fmt.Println(qry)
+	}
+
+	if opts.PrintResponses {
+		fmt.Println("\n", res)
+	}
+
+	took := float64(time.Since(start).Nanoseconds()) / 1e6
+	stat := query.GetStat()
+	stat.Init(q.HumanLabelName(), took)
+
+	return []*query.Stat{stat}, nil
+}
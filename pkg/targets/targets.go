@@ -1,11 +1,15 @@
 package targets
 
 import (
+	"io"
+	"sync"
+
 	"github.com/spf13/viper"
 	"github.com/bodhiye/tsbs/pkg/data"
 	"github.com/bodhiye/tsbs/pkg/data/serialize"
 	"github.com/bodhiye/tsbs/pkg/data/source"
 	"github.com/bodhiye/tsbs/pkg/data/usecases/common"
+	"github.com/bodhiye/tsbs/pkg/query"
 	"github.com/spf13/pflag"
 )
 
@@ -22,6 +26,21 @@ type ImplementedTarget interface {
 	TargetName() string
 }
 
+// ImplementedQueryTarget is implemented by targets that can also run the
+// queries generated by tsbs_generate_queries against the database.
+type ImplementedQueryTarget interface {
+	// QueryPool returns the pool of the queries run against this target
+	QueryPool() *sync.Pool
+	// QueryProcessorCreate returns a function creating query processors that
+	// run queries against targetDB. The target-specific properties in v are
+	// the same ones passed to Benchmark, plus those of QueryFlags.
+	QueryProcessorCreate(targetDB string, runnerConfig *query.BenchmarkRunnerConfig, v *viper.Viper) (query.ProcessorCreate, error)
+	// QueryFlags adds to the supplied flagSet the target-specific flags only
+	// used to run queries, next to those of TargetSpecificFlags, with the
+	// same flagPrefix.
+	QueryFlags(flagPrefix string, flagSet *pflag.FlagSet)
+}
+
 // Batch is an aggregate of points for a particular data system.
 // It needs to have a way to measure it's size to make sure
 // it does not get too large and it needs a way to append a point
@@ -30,6 +49,14 @@ type Batch interface {
 	Append(data.LoadedPoint)
 }
 
+// WriterToBatch is a Batch that can write its points in the format they
+// were read in, so batches that could not be inserted can be stored in a
+// file and loaded again later
+type WriterToBatch interface {
+	Batch
+	io.WriterTo
+}
+
 // PointIndexer determines the index of the Batch (and subsequently the channel)
 // that a particular point belongs to
 type PointIndexer interface {
@@ -24,6 +24,10 @@ var fatal = log.Fatalf
 
 var tableCols = make(map[string][]string)
 
+// tableColTypes holds the serialized types of the columns of the tables
+// whose header has types; the columns of other tables are numbers
+var tableColTypes = make(map[string][]string)
+
 type dbCreator struct {
 	driver  string
 	ds      targets.DataSource
@@ -100,7 +104,8 @@ func (d *dbCreator) PostCreateDB(dbName string) error {
 	for tableName, columns := range headers.FieldKeys {
 		// tableCols is a global map. Globally cache the available columns for the given table
 		tableCols[tableName] = columns
-		fieldDefs, indexDefs := d.getFieldAndIndexDefinitions(tableName, columns)
+		tableColTypes[tableName] = headers.FieldTypes[tableName]
+		fieldDefs, indexDefs := d.getFieldAndIndexDefinitions(tableName, columns, headers.FieldTypes[tableName])
 		if d.opts.CreateMetricsTable {
 			d.createTableAndIndexes(dbBench, tableName, fieldDefs, indexDefs)
 		} else {
@@ -122,8 +127,9 @@ func (d *dbCreator) PostCreateDB(dbName string) error {
 }
 
 // getFieldAndIndexDefinitions iterates over a list of table columns, populating lists of
-// definitions for each desired field and index. Returns separate lists of fieldDefs and indexDefs
-func (d *dbCreator) getFieldAndIndexDefinitions(tableName string, columns []string) ([]string, []string) {
+// definitions for each desired field and index. Returns separate lists of fieldDefs and indexDefs.
+// The columns are DOUBLE PRECISION unless their serialized types are given.
+func (d *dbCreator) getFieldAndIndexDefinitions(tableName string, columns, types []string) ([]string, []string) {
 	var fieldDefs []string
 	var indexDefs []string
 	var allCols []string
@@ -135,6 +141,7 @@ func (d *dbCreator) getFieldAndIndexDefinitions(tableName string, columns []stri
 		allCols = append(allCols, partitioningField)
 	}
 
+	typesOffset := len(allCols)
 	allCols = append(allCols, columns...)
 	extraCols := 0 // set to 1 when hostname is kept in-table
 	for idx, field := range allCols {
@@ -142,6 +149,9 @@ func (d *dbCreator) getFieldAndIndexDefinitions(tableName string, columns []stri
 			continue
 		}
 		fieldType := "DOUBLE PRECISION"
+		if types != nil && idx >= typesOffset {
+			fieldType = serializedTypeToPgType(types[idx-typesOffset])
+		}
 		idxType := d.opts.FieldIndex
 		// This condition handles the case where we keep the primary tag key in the table
 		// and partition on it. Since under the current implementation this tag is always
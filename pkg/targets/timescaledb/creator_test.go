@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"log"
 	"testing"
+
+	"github.com/bodhiye/tsbs/pkg/targets"
 )
 
 func TestDBCreatorInit(t *testing.T) {
@@ -52,7 +54,7 @@ func TestDBCreatorInit(t *testing.T) {
 	}
 	for _, c := range cases {
 		br := bufio.NewReader(bytes.NewBufferString(buf))
-		dbc := &dbCreator{ds: &fileDataSource{scanner: bufio.NewScanner(br)}, connStr: c.connStr, connDB: c.connDB}
+		dbc := &dbCreator{ds: &fileDataSource{scanner: targets.NewLineScanner(br)}, connStr: c.connStr, connDB: c.connDB}
 		dbc.initConnectString()
 		if got := dbc.connStr; got != c.want {
 			t.Errorf("%s: incorrect connstr: got %s want %s", c.desc, got, c.want)
@@ -129,6 +131,7 @@ func TestDBCreatorGetFieldAndIndexDefinitions(t *testing.T) {
 		desc            string
 		tableName       string
 		columns         []string
+		types           []string
 		fieldIndexCount int
 		inTableTag      bool
 		wantFieldDefs   []string
@@ -179,6 +182,16 @@ func TestDBCreatorGetFieldAndIndexDefinitions(t *testing.T) {
 			wantFieldDefs:   []string{"usage_user DOUBLE PRECISION", "usage_system DOUBLE PRECISION", "usage_idle DOUBLE PRECISION", "usage_nice DOUBLE PRECISION"},
 			wantIndexDefs:   []string{"CREATE INDEX ON cpu (usage_user, time DESC)", "CREATE INDEX ON cpu (usage_system, time DESC)"},
 		},
+		{
+			desc:            "typed fields, in table tag",
+			tableName:       "events",
+			columns:         []string{"level", "status_code", "latency_ms"},
+			types:           []string{"string", "int64", "float64"},
+			fieldIndexCount: 0,
+			inTableTag:      true,
+			wantFieldDefs:   []string{"hostname TEXT", "level TEXT", "status_code BIGINT", "latency_ms DOUBLE PRECISION"},
+			wantIndexDefs:   []string{},
+		},
 	}
 
 	for _, c := range cases {
@@ -190,7 +203,7 @@ func TestDBCreatorGetFieldAndIndexDefinitions(t *testing.T) {
 			InTableTag:      c.inTableTag,
 			FieldIndexCount: c.fieldIndexCount,
 		}}
-		fieldDefs, indexDefs := dbc.getFieldAndIndexDefinitions(c.tableName, c.columns)
+		fieldDefs, indexDefs := dbc.getFieldAndIndexDefinitions(c.tableName, c.columns, c.types)
 		for i, fieldDef := range fieldDefs {
 			if fieldDef != c.wantFieldDefs[i] {
 				t.Errorf("%s: incorrect fieldDef at idx %d: got %s want %s", c.desc, i, fieldDef, c.wantFieldDefs[i])
@@ -1,7 +1,6 @@
 package timescaledb
 
 import (
-	"bufio"
 	"strings"
 
 	"github.com/bodhiye/tsbs/load"
@@ -12,14 +11,26 @@ import (
 
 func newFileDataSource(fileName string) targets.DataSource {
 	br := load.GetBufferedReader(fileName)
-	return &fileDataSource{scanner: bufio.NewScanner(br)}
+	return &fileDataSource{scanner: targets.NewLineScanner(br)}
 }
 
 type fileDataSource struct {
-	scanner *bufio.Scanner
+	scanner *targets.LineScanner
 	headers *common.GeneratedDataHeaders
 }
 
+// Offset implements targets.OffsetDataSource.
+func (d *fileDataSource) Offset() int64 {
+	return d.scanner.Offset()
+}
+
+// SkipTo implements targets.OffsetDataSource.
+func (d *fileDataSource) SkipTo(offset int64) error {
+	// the headers are read first, as the points are skipped after them
+	d.Headers()
+	return d.scanner.SkipTo(offset)
+}
+
 func (d *fileDataSource) Headers() *common.GeneratedDataHeaders {
 	// headers are read from the input file, and should be read first
 	if d.headers != nil {
@@ -57,20 +68,27 @@ func (d *fileDataSource) Headers() *common.GeneratedDataHeaders {
 
 	tagsarr := strings.Split(tags, ",")
 	if tagsarr[0] != tagsKey {
-		fatal("input header in wrong format. got '%s', expected 'tags'", tags[0])
+		fatal("input header in wrong format. got '%s', expected 'tags'", tagsarr[0])
 	}
 	tagNames, tagTypes := extractTagNamesAndTypes(tagsarr[1:])
 	fieldKeys := make(map[string][]string)
+	fieldTypes := make(map[string][]string)
 	for _, tableDef := range cols {
 		columns := strings.Split(tableDef, ",")
 		tableName := columns[0]
 		colNames := columns[1:]
+		// Columns have types like the tags, e.g. 'level string', when
+		// they are not all numbers
+		if strings.Contains(tableDef, " ") {
+			colNames, fieldTypes[tableName] = extractTagNamesAndTypes(colNames)
+		}
 		fieldKeys[tableName] = colNames
 	}
 	d.headers = &common.GeneratedDataHeaders{
-		TagTypes:  tagTypes,
-		TagKeys:   tagNames,
-		FieldKeys: fieldKeys,
+		TagTypes:   tagTypes,
+		TagKeys:    tagNames,
+		FieldKeys:  fieldKeys,
+		FieldTypes: fieldTypes,
 	}
 	return d.headers
 }
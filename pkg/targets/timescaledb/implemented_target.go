@@ -1,11 +1,13 @@
 package timescaledb
 
 import (
+	"sync"
 	"time"
 
 	"github.com/spf13/viper"
 	"github.com/bodhiye/tsbs/pkg/data/serialize"
 	"github.com/bodhiye/tsbs/pkg/data/source"
+	"github.com/bodhiye/tsbs/pkg/query"
 	"github.com/bodhiye/tsbs/pkg/targets"
 	"github.com/bodhiye/tsbs/pkg/targets/constants"
 	"github.com/spf13/pflag"
@@ -36,6 +38,30 @@ func (t *timescaleTarget) Benchmark(
 	return NewBenchmark(targetDB, &loadingOptions, dataSourceConfig)
 }
 
+func (t *timescaleTarget) QueryPool() *sync.Pool {
+	return &query.TimescaleDBPool
+}
+
+func (t *timescaleTarget) QueryProcessorCreate(
+	targetDB string, runnerConfig *query.BenchmarkRunnerConfig, v *viper.Viper,
+) (query.ProcessorCreate, error) {
+	var loadingOptions LoadingOptions
+	if err := v.Unmarshal(&loadingOptions); err != nil {
+		return nil, err
+	}
+	opts := NewQueryOptions(targetDB, &loadingOptions, runnerConfig)
+	opts.ShowExplain = v.GetBool("show-explain")
+	if opts.ShowExplain {
+		// only the EXPLAIN output of the first query is printed
+		runnerConfig.Limit = 1
+	}
+	return NewQueryProcessorCreate(opts), nil
+}
+
+func (t *timescaleTarget) QueryFlags(flagPrefix string, flagSet *pflag.FlagSet) {
+	flagSet.Bool(flagPrefix+"show-explain", false, "Print out the EXPLAIN output for sample query")
+}
+
 func (t *timescaleTarget) TargetSpecificFlags(flagPrefix string, flagSet *pflag.FlagSet) {
 	flagSet.String(flagPrefix+"postgres", "sslmode=disable", "PostgreSQL connection string")
 	flagSet.String(flagPrefix+"host", "localhost", "Hostname of TimescaleDB (PostgreSQL) instance")
@@ -56,7 +56,7 @@ func subsystemTagsToJSON(tags []string) map[string]interface{} {
 	return jsonToReturn
 }
 
-func (p *processor) insertTags(db *sql.DB, tagRows [][]string) map[string]int64 {
+func (p *processor) insertTags(db *sql.DB, tagRows [][]string) (map[string]int64, error) {
 	tagCols := tableCols[tagsKey]
 	cols := tagCols
 	values := make([]string, 0)
@@ -82,15 +82,21 @@ func (p *processor) insertTags(db *sql.DB, tagRows [][]string) map[string]int64
 			values = append(values, row)
 		}
 	}
-	tx := MustBegin(db)
-	defer tx.Commit()
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
 	res, err := tx.Query(fmt.Sprintf(insertTagsSQL, strings.Join(cols, ","), strings.Join(values, ",")))
 	if err != nil {
-		panic(err)
+		tx.Rollback()
+		return nil, err
 	}
 
 	ret := p.sqlTagsToCacheLine(res, err, tagCols)
-	return ret
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return ret, nil
 }
 
 func (p *processor) sqlTagsToCacheLine(res *sql.Rows, err error, tagCols []string) map[string]int64 {
@@ -126,7 +132,8 @@ func (p *processor) sqlTagsToCacheLine(res *sql.Rows, err error, tagCols []strin
 // divides the tags from data into appropriate slices that can then be used in
 // SQL queries to insert into their respective tables. Additionally, it also
 // returns the number of metrics (i.e., non-tag fields) for the data processed.
-func (p *processor) splitTagsAndMetrics(rows []*insertData, dataCols int) ([][]string, [][]interface{}, uint64) {
+// The metrics are parsed as numbers unless their serialized types are given.
+func (p *processor) splitTagsAndMetrics(rows []*insertData, dataCols int, colTypes []string) ([][]string, [][]interface{}, uint64) {
 	tagRows := make([][]string, 0, len(rows))
 	dataRows := make([][]interface{}, 0, len(rows))
 	numMetrics := uint64(0)
@@ -162,18 +169,17 @@ func (p *processor) splitTagsAndMetrics(rows []*insertData, dataCols int) ([][]s
 		if p.opts.InTableTag {
 			r = append(r, tags[0])
 		}
-		for _, v := range metrics[1:] {
+		for i, v := range metrics[1:] {
 			if v == "" {
 				r = append(r, nil)
 				continue
 			}
 
-			num, err := strconv.ParseFloat(v, 64)
-			if err != nil {
-				panic(err)
+			colType := ""
+			if colTypes != nil {
+				colType = colTypes[i]
 			}
-
-			r = append(r, num)
+			r = append(r, parseMetric(v, colType))
 		}
 
 		dataRows = append(dataRows, r)
@@ -183,12 +189,35 @@ func (p *processor) splitTagsAndMetrics(rows []*insertData, dataCols int) ([][]s
 	return tagRows, dataRows, numMetrics
 }
 
-func (p *processor) processCSI(hypertable string, rows []*insertData) uint64 {
+// parseMetric parses the metric value v of the serialized type colType, as
+// a float if the type is not known.
+func parseMetric(v, colType string) interface{} {
+	switch colType {
+	case "string":
+		return v
+	case "int64", "int32":
+		num, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			panic(err)
+		}
+		return num
+	}
+
+	num, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		panic(err)
+	}
+	return num
+}
+
+// processCSI inserts the rows of a hypertable, with their tags if they are
+// new. Errors from the database are returned, so the rows can be retried.
+func (p *processor) processCSI(hypertable string, rows []*insertData) (uint64, error) {
 	colLen := len(tableCols[hypertable]) + numExtraCols
 	if p.opts.InTableTag {
 		colLen++
 	}
-	tagRows, dataRows, numMetrics := p.splitTagsAndMetrics(rows, colLen)
+	tagRows, dataRows, numMetrics := p.splitTagsAndMetrics(rows, colLen, tableColTypes[hypertable])
 
 	// Check if any of these tags has yet to be inserted
 	newTags := make([][]string, 0, len(rows))
@@ -201,11 +230,14 @@ func (p *processor) processCSI(hypertable string, rows []*insertData) uint64 {
 	p._csi.mutex.RUnlock()
 	if len(newTags) > 0 {
 		p._csi.mutex.Lock()
-		res := p.insertTags(p._db, newTags)
+		res, err := p.insertTags(p._db, newTags)
 		for k, v := range res {
 			p._csi.m[k] = v
 		}
 		p._csi.mutex.Unlock()
+		if err != nil {
+			return 0, err
+		}
 	}
 
 	p._csi.mutex.RLock()
@@ -223,10 +255,14 @@ func (p *processor) processCSI(hypertable string, rows []*insertData) uint64 {
 	cols = append(cols, tableCols[hypertable]...)
 
 	if p.opts.ForceTextFormat {
-		tx := MustBegin(p._db)
+		tx, err := p._db.Begin()
+		if err != nil {
+			return 0, err
+		}
 		stmt, err := tx.Prepare(pq.CopyIn(hypertable, cols...))
 		if err != nil {
-			panic(err)
+			tx.Rollback()
+			return 0, err
 		}
 
 		for _, r := range dataRows {
@@ -234,17 +270,20 @@ func (p *processor) processCSI(hypertable string, rows []*insertData) uint64 {
 		}
 		_, err = stmt.Exec()
 		if err != nil {
-			panic(err)
+			stmt.Close()
+			tx.Rollback()
+			return 0, err
 		}
 
 		err = stmt.Close()
 		if err != nil {
-			panic(err)
+			tx.Rollback()
+			return 0, err
 		}
 
 		err = tx.Commit()
 		if err != nil {
-			panic(err)
+			return 0, err
 		}
 	} else {
 		if !p.opts.UseInsert {
@@ -252,7 +291,7 @@ func (p *processor) processCSI(hypertable string, rows []*insertData) uint64 {
 			inserted, err := p._pgxConn.CopyFrom(context.Background(), pgx.Identifier{hypertable}, cols, rows)
 
 			if err != nil {
-				panic(err)
+				return 0, err
 			}
 
 			if inserted != int64(len(dataRows)) {
@@ -260,31 +299,38 @@ func (p *processor) processCSI(hypertable string, rows []*insertData) uint64 {
 				os.Exit(1)
 			}
 		} else {
-			tx := MustBegin(p._db)
-			var stmt *sql.Stmt
-			var err error
-
 			stmtString := genBatchInsertStmt(hypertable, cols, len(dataRows))
-			stmt, err = tx.Prepare(stmtString)
+			tx, err := p._db.Begin()
+			if err != nil {
+				return 0, err
+			}
+			stmt, err := tx.Prepare(stmtString)
+			if err != nil {
+				tx.Rollback()
+				return 0, err
+			}
 
 			_, err = stmt.Exec(flatten(dataRows)...)
 			if err != nil {
-				panic(err)
+				stmt.Close()
+				tx.Rollback()
+				return 0, err
 			}
 
 			err = stmt.Close()
 			if err != nil {
-				panic(err)
+				tx.Rollback()
+				return 0, err
 			}
 
 			err = tx.Commit()
 			if err != nil {
-				panic(err)
+				return 0, err
 			}
 		}
 	}
 
-	return numMetrics
+	return numMetrics, nil
 }
 
 func newProcessor(opts *LoadingOptions, driver, dbName string) *processor {
@@ -382,14 +428,28 @@ func (p *processor) Close(doLoad bool) {
 }
 
 func (p *processor) ProcessBatch(b targets.Batch, doLoad bool) (uint64, uint64) {
+	metricCnt, rowCnt, err := p.ProcessBatchWithError(b, doLoad)
+	if err != nil {
+		panic(err)
+	}
+	return metricCnt, rowCnt
+}
+
+// ProcessBatchWithError inserts the batch one hypertable at a time. The rows
+// of every hypertable inserted are removed from the batch, so that only the
+// rest of it is retried if an insert fails.
+func (p *processor) ProcessBatchWithError(b targets.Batch, doLoad bool) (uint64, uint64, error) {
 	batches := b.(*hypertableArr)
 	rowCnt := 0
 	metricCnt := uint64(0)
 	for hypertable, rows := range batches.m {
-		rowCnt += len(rows)
 		if doLoad {
 			start := time.Now()
-			metricCnt += p.processCSI(hypertable, rows)
+			metrics, err := p.processCSI(hypertable, rows)
+			if err != nil {
+				return metricCnt, uint64(rowCnt), err
+			}
+			metricCnt += metrics
 
 			if p.opts.LogBatches {
 				now := time.Now()
@@ -398,10 +458,13 @@ func (p *processor) ProcessBatch(b targets.Batch, doLoad bool) (uint64, uint64)
 				fmt.Printf("BATCH: batchsize %d row rate %f/sec (took %v)\n", batchSize, float64(batchSize)/float64(took.Seconds()), took)
 			}
 		}
+		rowCnt += len(rows)
+		delete(batches.m, hypertable)
+		batches.cnt -= uint(len(rows))
 	}
 	batches.m = map[string][]*insertData{}
 	batches.cnt = 0
-	return metricCnt, uint64(rowCnt)
+	return metricCnt, uint64(rowCnt), nil
 }
 func convertValsToSQLBasedOnType(values []string, types []string) []string {
 	return convertValsToBasedOnType(values, types, "'", "NULL")
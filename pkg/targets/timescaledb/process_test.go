@@ -85,6 +85,7 @@ func TestSplitTagsAndMetrics(t *testing.T) {
 	cases := []struct {
 		desc        string
 		rows        []*insertData
+		colTypes    []string
 		inTableTag  bool
 		wantMetrics uint64
 		wantTags    [][]string
@@ -182,6 +183,21 @@ func TestSplitTagsAndMetrics(t *testing.T) {
 				[]interface{}{toTS("100"), nil, nil, nil, 5.0, 42.0},
 			},
 		},
+		{
+			desc: "typed field values",
+			rows: []*insertData{
+				{
+					tags:   "tag1=foo,tag2=bar",
+					fields: "100,error,503,42.5",
+				},
+			},
+			colTypes:    []string{"string", "int64", "float64"},
+			wantMetrics: 3,
+			wantTags:    [][]string{{"foo", "bar"}},
+			wantData: [][]interface{}{
+				[]interface{}{toTS("100"), nil, nil, "error", int64(503), 42.5},
+			},
+		},
 	}
 
 	for _, c := range cases {
@@ -194,13 +210,13 @@ func TestSplitTagsAndMetrics(t *testing.T) {
 					t.Errorf("%s: did not panic when should", c.desc)
 				}
 			}()
-			p.splitTagsAndMetrics(c.rows, numCols+numExtraCols)
+			p.splitTagsAndMetrics(c.rows, numCols+numExtraCols, c.colTypes)
 		}
 
 		oldInTableTag := p.opts.InTableTag
 		p.opts.InTableTag = c.inTableTag
 
-		gotTags, gotData, numMetrics := p.splitTagsAndMetrics(c.rows, numCols+numExtraCols)
+		gotTags, gotData, numMetrics := p.splitTagsAndMetrics(c.rows, numCols+numExtraCols, c.colTypes)
 		if numMetrics != c.wantMetrics {
 			t.Errorf("%s: number of metrics incorrect: got %d want %d", c.desc, numMetrics, c.wantMetrics)
 		}
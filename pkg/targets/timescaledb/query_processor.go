@@ -0,0 +1,225 @@
+package timescaledb
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/bodhiye/tsbs/pkg/query"
+	"github.com/pkg/errors"
+)
+
+// QueryOptions configures the processors that run queries against
+// TimescaleDB.
+type QueryOptions struct {
+	PostgresConnect string
+	// Hosts to run queries against, assigned round robin to the workers
+	// (pass multiple values for sharding reads on a multi-node setup)
+	Hosts           []string
+	User            string
+	Pass            string
+	Port            string
+	DBName          string
+	ShowExplain     bool
+	ForceTextFormat bool
+	Debug           bool
+	PrintResponses  bool
+}
+
+// NewQueryOptions creates the options for running queries against the
+// database the loading options connect to. The host of the loading options
+// may be a comma separated list of hosts.
+func NewQueryOptions(dbName string, opts *LoadingOptions, runnerConfig *query.BenchmarkRunnerConfig) *QueryOptions {
+	return &QueryOptions{
+		PostgresConnect: opts.PostgresConnect,
+		Hosts:           strings.Split(opts.Host, ","),
+		User:            opts.User,
+		Pass:            opts.Pass,
+		Port:            opts.Port,
+		DBName:          dbName,
+		ForceTextFormat: opts.ForceTextFormat,
+		Debug:           runnerConfig.Debug > 0,
+		PrintResponses:  runnerConfig.PrintResponses,
+	}
+}
+
+// getConnectString returns the connection string for a query worker.
+//
+// If we're running queries against multiple nodes we need to balance the queries
+// across replicas. Each worker is assigned a sequence number -- we'll use that
+// to evenly distribute hosts to worker connections
+func (o *QueryOptions) getConnectString(workerNumber int) string {
+	// User might be passing in host=hostname the connect string out of habit which may override the
+	// multi host configuration. Same for dbname= and user=. This sanitizes that.
+	re := regexp.MustCompile(`(host|dbname|user)=\S*\b`)
+	connectString := re.ReplaceAllString(o.PostgresConnect, "")
+
+	// Round robin the host/worker assignment by assigning a host based on workerNumber % totalNumberOfHosts
+	host := o.Hosts[workerNumber%len(o.Hosts)]
+	connectString = fmt.Sprintf("host=%s dbname=%s user=%s %s", host, o.DBName, o.User, connectString)
+
+	// For optional parameters, ensure they exist then interpolate them into the connectString
+	if len(o.Port) > 0 {
+		connectString = fmt.Sprintf("%s port=%s", connectString, o.Port)
+	}
+	if len(o.Pass) > 0 {
+		connectString = fmt.Sprintf("%s password=%s", connectString, o.Pass)
+	}
+	if o.ForceTextFormat {
+		connectString = fmt.Sprintf("%s disable_prepared_binary_result=yes binary_parameters=no", connectString)
+	}
+
+	return connectString
+}
+
+// NewQueryProcessorCreate returns a function creating query processors that
+// run TimescaleDB queries with the given options.
+func NewQueryProcessorCreate(opts *QueryOptions) query.ProcessorCreate {
+	return func() query.Processor { return &queryProcessor{opts: opts} }
+}
+
+type queryProcessor struct {
+	db   *sql.DB
+	opts *QueryOptions
+}
+
+func (p *queryProcessor) Init(workerNumber int) {
+	db, err := sql.Open(getDriver(p.opts.ForceTextFormat), p.opts.getConnectString(workerNumber))
+	if err != nil {
+		panic(err)
+	}
+	p.db = db
+}
+
+func (p *queryProcessor) Close() {
+	if p.db != nil {
+		p.db.Close()
+	}
+}
+
+func (p *queryProcessor) ProcessQuery(q query.Query, isWarm bool) ([]*query.Stat, error) {
+	stats, _, err := p.processQuery(q, isWarm, false)
+	return stats, err
+}
+
+func (p *queryProcessor) ProcessQueryWithResult(q query.Query, isWarm bool) ([]*query.Stat, *query.ResultSet, error) {
+	return p.processQuery(q, isWarm, true)
+}
+
+func (p *queryProcessor) processQuery(q query.Query, isWarm, withResult bool) ([]*query.Stat, *query.ResultSet, error) {
+	// No need to run again for EXPLAIN
+	if isWarm && p.opts.ShowExplain {
+		return nil, nil, nil
+	}
+	tq := q.(*query.TimescaleDB)
+
+	start := time.Now()
+	qry := string(tq.SqlQuery)
+	if p.opts.ShowExplain {
+		qry = "EXPLAIN ANALYZE " + qry
+	}
+	rows, err := p.db.Query(qry)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if p.opts.Debug {
+		fmt.Println(qry)
+	}
+	var rs *query.ResultSet
+	if p.opts.ShowExplain {
+		text := ""
+		for rows.Next() {
+			var s string
+			if err2 := rows.Scan(&s); err2 != nil {
+				panic(err2)
+			}
+			text += s + "\n"
+		}
+		fmt.Printf("%s\n\n%s\n-----\n\n", qry, text)
+	} else if p.opts.PrintResponses || withResult {
+		cols, values := readRows(rows)
+		if p.opts.PrintResponses {
+			prettyPrintResponse(cols, values, tq)
+		}
+		if withResult {
+			rs = newResultSet(values)
+		}
+	}
+	// Fetching all the rows to confirm that the query is fully completed.
+	for rows.Next() {
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	took := float64(time.Since(start).Nanoseconds()) / 1e6
+	stat := query.GetStat()
+	stat.Init(q.HumanLabelName(), took)
+
+	return []*query.Stat{stat}, rs, err
+}
+
+// prettyPrintResponse prints a Query and its response in JSON format with two
+// keys: 'query' which has a value of the SQL used to generate the second key
+// 'results' which is an array of each row in the return set.
+func prettyPrintResponse(cols []string, values [][]interface{}, q *query.TimescaleDB) {
+	resp := make(map[string]interface{})
+	resp["query"] = string(q.SqlQuery)
+	resp["results"] = mapRows(cols, values)
+
+	line, err := json.MarshalIndent(resp, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(string(line) + "\n")
+}
+
+func mapRows(cols []string, values [][]interface{}) []map[string]interface{} {
+	rows := []map[string]interface{}{}
+	for _, rowValues := range values {
+		row := make(map[string]interface{})
+		for i, column := range cols {
+			row[column] = rowValues[i]
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// readRows reads all rows of the response, returning the column names and
+// the values of each row.
+func readRows(r *sql.Rows) ([]string, [][]interface{}) {
+	cols, _ := r.Columns()
+	var rows [][]interface{}
+	for r.Next() {
+		values := make([]interface{}, len(cols))
+		for i := range values {
+			values[i] = new(interface{})
+		}
+
+		err := r.Scan(values...)
+		if err != nil {
+			panic(errors.Wrap(err, "error while reading values"))
+		}
+
+		for i := range values {
+			values[i] = *values[i].(*interface{})
+		}
+		rows = append(rows, values)
+	}
+	return cols, rows
+}
+
+// newResultSet converts the rows of a response to a normalized result set.
+func newResultSet(values [][]interface{}) *query.ResultSet {
+	rs := &query.ResultSet{Rows: make([]query.ResultRow, 0, len(values))}
+	for _, rowValues := range values {
+		rs.Rows = append(rs.Rows, query.NewResultRow(rowValues...))
+	}
+	return rs
+}
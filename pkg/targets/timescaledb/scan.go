@@ -1,7 +1,11 @@
 package timescaledb
 
 import (
+	"bufio"
+	"fmt"
 	"hash/fnv"
+	"io"
+	"sort"
 	"strings"
 
 	"github.com/bodhiye/tsbs/pkg/data"
@@ -47,6 +51,29 @@ func (ha *hypertableArr) Append(item data.LoadedPoint) {
 	ha.cnt++
 }
 
+// WriteTo writes the rows of the batch in the format they were read in,
+// one hypertable after the other.
+func (ha *hypertableArr) WriteTo(w io.Writer) (int64, error) {
+	hypertables := make([]string, 0, len(ha.m))
+	for hypertable := range ha.m {
+		hypertables = append(hypertables, hypertable)
+	}
+	sort.Strings(hypertables)
+
+	bw := bufio.NewWriter(w)
+	var n int64
+	for _, hypertable := range hypertables {
+		for _, row := range ha.m[hypertable] {
+			written, err := fmt.Fprintf(bw, "%s,%s\n%s,%s\n", tagsKey, row.tags, hypertable, row.fields)
+			n += int64(written)
+			if err != nil {
+				return n, err
+			}
+		}
+	}
+	return n, bw.Flush()
+}
+
 type factory struct{}
 
 func (f *factory) New() targets.Batch {
@@ -10,6 +10,7 @@ import (
 
 	"github.com/bodhiye/tsbs/pkg/data"
 	"github.com/bodhiye/tsbs/pkg/data/usecases/common"
+	"github.com/bodhiye/tsbs/pkg/targets"
 )
 
 func TestHostnameIndexer(t *testing.T) {
@@ -103,6 +104,37 @@ func TestHypertableArr(t *testing.T) {
 	}
 }
 
+func TestHypertableArrWriteTo(t *testing.T) {
+	ha := (&factory{}).New().(*hypertableArr)
+	ha.Append(data.LoadedPoint{Data: &point{hypertable: "table2", row: &insertData{tags: "t3,t4", fields: "1,f3,f4"}}})
+	ha.Append(data.LoadedPoint{Data: &point{hypertable: "table1", row: &insertData{tags: "t1,t2", fields: "0,f1,f2"}}})
+	ha.Append(data.LoadedPoint{Data: &point{hypertable: "table1", row: &insertData{tags: "t5,t6", fields: "2,f5,f6"}}})
+
+	buf := &bytes.Buffer{}
+	n, err := ha.WriteTo(buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "tags,t1,t2\ntable1,0,f1,f2\ntags,t5,t6\ntable1,2,f5,f6\ntags,t3,t4\ntable2,1,f3,f4\n"
+	if got := buf.String(); got != want {
+		t.Errorf("incorrect output: got\n%s\nwant\n%s", got, want)
+	}
+	if n != int64(len(want)) {
+		t.Errorf("incorrect byte count: got %d want %d", n, len(want))
+	}
+
+	// the rows written must be decodable again
+	decoder := &fileDataSource{
+		scanner: targets.NewLineScanner(strings.NewReader(want)),
+		headers: &common.GeneratedDataHeaders{},
+	}
+	for i := 0; i < 3; i++ {
+		if p := decoder.NextItem(); p.Data == nil {
+			t.Fatalf("could not decode row %d", i)
+		}
+	}
+}
+
 func TestDecode(t *testing.T) {
 	cases := []struct {
 		desc        string
@@ -133,7 +165,7 @@ func TestDecode(t *testing.T) {
 	for _, c := range cases {
 		br := bufio.NewReader(bytes.NewReader([]byte(c.input)))
 		dataSource := &fileDataSource{
-			scanner: bufio.NewScanner(br),
+			scanner: targets.NewLineScanner(br),
 			headers: &common.GeneratedDataHeaders{},
 		}
 		if c.shouldFatal {
@@ -166,7 +198,7 @@ func TestDecode(t *testing.T) {
 func TestDecodeEOF(t *testing.T) {
 	input := []byte("tags,tag1text,tag2text\ncpu,140,0.0,0.0\n")
 	br := bufio.NewReader(bytes.NewReader(input))
-	decoder := &fileDataSource{headers: &common.GeneratedDataHeaders{}, scanner: bufio.NewScanner(br)}
+	decoder := &fileDataSource{headers: &common.GeneratedDataHeaders{}, scanner: targets.NewLineScanner(br)}
 	_ = decoder.NextItem()
 	// nothing left, should be EOF
 	p := decoder.NextItem()
@@ -177,12 +209,13 @@ func TestDecodeEOF(t *testing.T) {
 
 func TestFileDataSourceHeaders(t *testing.T) {
 	cases := []struct {
-		desc        string
-		input       string
-		wantTags    string
-		wantTypes   string
-		wantCols    map[string]string
-		shouldFatal bool
+		desc         string
+		input        string
+		wantTags     string
+		wantTypes    string
+		wantCols     map[string]string
+		wantColTypes map[string]string
+		shouldFatal  bool
 	}{
 		{
 			desc:      "min case: exactly three lines",
@@ -212,6 +245,14 @@ func TestFileDataSourceHeaders(t *testing.T) {
 			wantTypes: "tagT,tag2",
 			wantCols:  map[string]string{"cols": "col1,col2", "cols2": "col21,col22"},
 		},
+		{
+			desc:         "typed cols",
+			input:        "tags,tag1 string\ncols,col1,col2\nevents,level string,code int64\n\n",
+			wantTags:     "tag1",
+			wantTypes:    "string",
+			wantCols:     map[string]string{"cols": "col1,col2", "events": "level,code"},
+			wantColTypes: map[string]string{"events": "string,int64"},
+		},
 		{
 			desc:        "too few lines",
 			input:       "tags\ncols\n",
@@ -227,7 +268,7 @@ func TestFileDataSourceHeaders(t *testing.T) {
 	for _, c := range cases {
 		br := bufio.NewReader(bytes.NewReader([]byte(c.input)))
 		ds := &fileDataSource{
-			scanner: bufio.NewScanner(br),
+			scanner: targets.NewLineScanner(br),
 		}
 
 		if c.shouldFatal {
@@ -260,7 +301,35 @@ func TestFileDataSourceHeaders(t *testing.T) {
 				if got != c.wantCols[table] {
 					t.Errorf("%s: cols for table %s, incorrect: got\n%s\nwant\n%s\n", c.desc, table, got, c.wantCols[table])
 				}
+				gotTypes := strings.Join(headers.FieldTypes[table], ",")
+				if gotTypes != c.wantColTypes[table] {
+					t.Errorf("%s: col types for table %s, incorrect: got\n%s\nwant\n%s\n", c.desc, table, gotTypes, c.wantColTypes[table])
+				}
 			}
 		}
 	}
 }
+
+func TestFileDataSourceSkipTo(t *testing.T) {
+	input := "tags,tag1 string\ncpu,usage\n\ntags,host1\ncpu,1,10\ntags,host2\ncpu,2,20\n"
+	newDataSource := func() *fileDataSource {
+		return &fileDataSource{scanner: targets.NewLineScanner(strings.NewReader(input))}
+	}
+	ds := newDataSource()
+	ds.Headers()
+	ds.NextItem()
+	offset := ds.Offset()
+
+	// the headers are read before skipping to the offset
+	ds = newDataSource()
+	if err := ds.SkipTo(offset); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ds.Headers() == nil {
+		t.Errorf("headers not read")
+	}
+	p := ds.NextItem().Data.(*point)
+	if p.row.tags != "host2" || p.row.fields != "2,20" {
+		t.Errorf("incorrect point after the offset: got %+v", p.row)
+	}
+}
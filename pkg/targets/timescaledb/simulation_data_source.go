@@ -19,6 +19,22 @@ func newSimulationDataSource(sim common.Simulator) targets.DataSource {
 type simulationDataSource struct {
 	simulator common.Simulator
 	headers   *common.GeneratedDataHeaders
+	pos       targets.SimulatorPosition
+}
+
+// SimulatorPosition implements targets.SimulatedDataSource.
+func (d *simulationDataSource) SimulatorPosition() targets.SimulatorPosition {
+	return d.pos
+}
+
+// FastForward implements targets.SimulatedDataSource.
+func (d *simulationDataSource) FastForward(pos targets.SimulatorPosition) error {
+	skipped, last := common.FastForward(d.simulator, pos.Points)
+	if skipped < pos.Points {
+		return fmt.Errorf("simulator finished after %d points, before %d", skipped, pos.Points)
+	}
+	d.pos = targets.SimulatorPosition{Points: skipped, Time: last}
+	return nil
 }
 
 func (d *simulationDataSource) Headers() *common.GeneratedDataHeaders {
@@ -47,6 +63,8 @@ func (d *simulationDataSource) NextItem() data.LoadedPoint {
 	if d.simulator.Finished() || !write {
 		return data.LoadedPoint{}
 	}
+	d.pos.Points++
+	d.pos.Time = *newSimulatorPoint.Timestamp()
 	newLoadPoint := &insertData{}
 	tagValues := newSimulatorPoint.TagValues()
 	tagKeys := newSimulatorPoint.TagKeys()
@@ -1,7 +1,6 @@
 package timestream
 
 import (
-	"bufio"
 	"fmt"
 	"log"
 	"time"
@@ -92,7 +91,7 @@ func initDataSource(config *source.DataSourceConfig, useCurrentTs bool) (targets
 	if config.Type == source.FileDataSourceType {
 		br := load.GetBufferedReader(config.File.Location)
 		return &fileDataSource{
-			scanner:      bufio.NewScanner(br),
+			scanner:      targets.NewLineScanner(br),
 			useCurrentTs: useCurrentTs,
 		}, nil
 	} else if config.Type == source.SimulatorDataSourceType {
@@ -1,7 +1,6 @@
 package timestream
 
 import (
-	"bufio"
 	"fmt"
 	"log"
 	"strconv"
@@ -10,6 +9,7 @@ import (
 
 	"github.com/bodhiye/tsbs/pkg/data"
 	"github.com/bodhiye/tsbs/pkg/data/usecases/common"
+	"github.com/bodhiye/tsbs/pkg/targets"
 )
 
 const (
@@ -18,10 +18,22 @@ const (
 
 type fileDataSource struct {
 	_headers     *common.GeneratedDataHeaders
-	scanner      *bufio.Scanner
+	scanner      *targets.LineScanner
 	useCurrentTs bool
 }
 
+// Offset implements targets.OffsetDataSource.
+func (f *fileDataSource) Offset() int64 {
+	return f.scanner.Offset()
+}
+
+// SkipTo implements targets.OffsetDataSource.
+func (f *fileDataSource) SkipTo(offset int64) error {
+	// the headers are read first, as the points are skipped after them
+	f.Headers()
+	return f.scanner.SkipTo(offset)
+}
+
 func (f *fileDataSource) Headers() *common.GeneratedDataHeaders {
 	// headers are read from the input file, and should be read first
 	if f._headers != nil {
@@ -1,9 +1,13 @@
 package timestream
 
 import (
+	"sync"
+	"time"
+
 	"github.com/spf13/viper"
 	"github.com/bodhiye/tsbs/pkg/data/serialize"
 	"github.com/bodhiye/tsbs/pkg/data/source"
+	"github.com/bodhiye/tsbs/pkg/query"
 	"github.com/bodhiye/tsbs/pkg/targets"
 	"github.com/bodhiye/tsbs/pkg/targets/constants"
 	"github.com/pkg/errors"
@@ -35,3 +39,26 @@ func (i implementedTarget) TargetSpecificFlags(flagPrefix string, flagSet *pflag
 func (i implementedTarget) TargetName() string {
 	return constants.FormatTimestream
 }
+
+func (i implementedTarget) QueryPool() *sync.Pool {
+	return &query.TimestreamPool
+}
+
+func (i implementedTarget) QueryProcessorCreate(
+	_ string, runnerConfig *query.BenchmarkRunnerConfig, v *viper.Viper,
+) (query.ProcessorCreate, error) {
+	specificConfig, err := parseSpecificConfig(v)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create query processor")
+	}
+	return NewQueryProcessorCreate(&QueryOptions{
+		AwsRegion:      specificConfig.AwsRegion,
+		ClientTimeout:  v.GetDuration("client-timeout"),
+		Debug:          runnerConfig.Debug > 0,
+		PrintResponses: runnerConfig.PrintResponses,
+	}), nil
+}
+
+func (i implementedTarget) QueryFlags(flagPrefix string, flagSet *pflag.FlagSet) {
+	flagSet.Duration(flagPrefix+"client-timeout", time.Minute, "Configuration for aws sdk client to timeout after")
+}
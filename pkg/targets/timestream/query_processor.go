@@ -0,0 +1,111 @@
+package timestream
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/timestreamquery"
+	"github.com/bodhiye/tsbs/pkg/query"
+)
+
+// QueryOptions configures the processors that run queries against
+// Timestream. The database is encoded in the queries themselves.
+type QueryOptions struct {
+	AwsRegion string
+	// ClientTimeout is the timeout of the aws sdk client
+	ClientTimeout  time.Duration
+	Debug          bool
+	PrintResponses bool
+}
+
+// NewQueryProcessorCreate returns a function creating query processors that
+// run Timestream queries with the given options.
+func NewQueryProcessorCreate(opts *QueryOptions) query.ProcessorCreate {
+	return func() query.Processor { return &queryProcessor{opts: opts} }
+}
+
+// prettyPrintResponse prints a Query and its response in JSON format with two
+// keys: 'query' which has a value of the SQL used to generate the second key
+// 'results' which is an array of each row in the return set.
+func prettyPrintResponse(qry string, page *timestreamquery.QueryOutput, pageNum int) {
+	resp := make(map[string]interface{})
+	resp["query"] = qry
+	resp["results"] = mapRows(page)
+	resp["page"] = pageNum
+
+	line, err := json.MarshalIndent(resp, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(string(line) + "\n")
+}
+
+func mapRows(page *timestreamquery.QueryOutput) []map[string]string {
+	var rows []map[string]string
+	cols := page.ColumnInfo
+	for _, row := range page.Rows {
+		rowAsMap := make(map[string]string)
+		for i, val := range row.Data {
+			colName := cols[i].Name
+			rowAsMap[*colName] = val.String()
+		}
+
+		rows = append(rows, rowAsMap)
+	}
+	return rows
+}
+
+type queryProcessor struct {
+	opts    *QueryOptions
+	readSvc *timestreamquery.TimestreamQuery
+}
+
+func (p *queryProcessor) Init(_ int) {
+	awsSession, err := OpenAWSSession(&p.opts.AwsRegion, p.opts.ClientTimeout)
+	if err != nil {
+		panic("could not open aws session")
+	}
+	p.readSvc = timestreamquery.New(awsSession)
+}
+
+func (p *queryProcessor) ProcessQuery(q query.Query, _ bool) ([]*query.Stat, error) {
+	tq := q.(*query.Timestream)
+
+	start := time.Now()
+	qry := string(tq.SqlQuery)
+
+	if p.opts.Debug {
+		fmt.Println(qry)
+	}
+
+	queryInput := &timestreamquery.QueryInput{
+		QueryString: &qry,
+	}
+	totalRows := 0
+	pageNum := 1
+	err := p.readSvc.QueryPages(queryInput,
+		func(page *timestreamquery.QueryOutput, lastPage bool) bool {
+			// process query response
+			// making sure all the returned data is read
+			totalRows += len(page.Rows)
+			if p.opts.PrintResponses {
+				prettyPrintResponse(qry, page, pageNum)
+			}
+			pageNum++
+			// return true to continue to next page
+			return true
+		})
+	if err != nil {
+		return nil, err
+	}
+	if p.opts.Debug {
+		fmt.Printf("Total rows: %d\n", totalRows)
+	}
+	took := float64(time.Since(start).Nanoseconds()) / 1e6
+	stat := query.GetStat()
+	stat.Init(q.HumanLabelName(), took)
+
+	return []*query.Stat{stat}, err
+}
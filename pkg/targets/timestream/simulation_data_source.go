@@ -1,6 +1,7 @@
 package timestream
 
 import (
+	"fmt"
 	"log"
 	"strconv"
 	"time"
@@ -8,12 +9,29 @@ import (
 	"github.com/bodhiye/tsbs/pkg/data"
 	"github.com/bodhiye/tsbs/pkg/data/serialize"
 	"github.com/bodhiye/tsbs/pkg/data/usecases/common"
+	"github.com/bodhiye/tsbs/pkg/targets"
 )
 
 type simulatorDataSource struct {
 	_headers     *common.GeneratedDataHeaders
 	simulator    common.Simulator
 	useCurrentTs bool
+	pos          targets.SimulatorPosition
+}
+
+// SimulatorPosition implements targets.SimulatedDataSource.
+func (s *simulatorDataSource) SimulatorPosition() targets.SimulatorPosition {
+	return s.pos
+}
+
+// FastForward implements targets.SimulatedDataSource.
+func (s *simulatorDataSource) FastForward(pos targets.SimulatorPosition) error {
+	skipped, last := common.FastForward(s.simulator, pos.Points)
+	if skipped < pos.Points {
+		return fmt.Errorf("simulator finished after %d points, before %d", skipped, pos.Points)
+	}
+	s.pos = targets.SimulatorPosition{Points: skipped, Time: last}
+	return nil
 }
 
 func (s *simulatorDataSource) NextItem() data.LoadedPoint {
@@ -33,6 +51,8 @@ func (s *simulatorDataSource) NextItem() data.LoadedPoint {
 	if s.simulator.Finished() || !write {
 		return data.LoadedPoint{}
 	}
+	s.pos.Points++
+	s.pos.Time = *newSimulatorPoint.Timestamp()
 	timeUnixNano := s.prepareTimestamp(newSimulatorPoint.Timestamp())
 	return data.NewLoadedPoint(&deserializedPoint{
 		timeUnixNano: timeUnixNano,
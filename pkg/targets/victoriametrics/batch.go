@@ -3,6 +3,7 @@ package victoriametrics
 import (
 	"bytes"
 	"github.com/bodhiye/tsbs/pkg/data"
+	"io"
 	"log"
 )
 
@@ -44,3 +45,9 @@ func (b *batch) Append(item data.LoadedPoint) {
 	b.buf.Write(that)
 	b.buf.Write(newLine)
 }
+
+// WriteTo writes the batch in line protocol.
+func (b *batch) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write(b.buf.Bytes())
+	return int64(n), err
+}
@@ -1,15 +1,15 @@
 package victoriametrics
 
 import (
-	"bufio"
 	"bytes"
-	"errors"
 	"sync"
 
 	"github.com/spf13/viper"
 	"github.com/bodhiye/tsbs/load"
 	"github.com/bodhiye/tsbs/pkg/data/source"
 	"github.com/bodhiye/tsbs/pkg/targets"
+	"github.com/bodhiye/tsbs/pkg/targets/influx"
+	"github.com/bodhiye/tsbs/tools/inputs"
 )
 
 type SpecificConfig struct {
@@ -31,15 +31,20 @@ type benchmark struct {
 }
 
 func NewBenchmark(vmSpecificConfig *SpecificConfig, dataSourceConfig *source.DataSourceConfig) (targets.Benchmark, error) {
-	if dataSourceConfig.Type != source.FileDataSourceType {
-		return nil, errors.New("only FILE data source type is supported for VictoriaMetrics")
+	var ds targets.DataSource
+	if dataSourceConfig.Type == source.FileDataSourceType {
+		ds = newFileDataSource(load.GetBufferedReader(dataSourceConfig.File.Location))
+	} else {
+		dataGenerator := &inputs.DataGenerator{}
+		simulator, err := dataGenerator.CreateSimulator(dataSourceConfig.Simulator)
+		if err != nil {
+			return nil, err
+		}
+		ds = targets.NewSimulationDataSource(simulator, &influx.Serializer{}, false, newFileDataSource)
 	}
 
-	br := load.GetBufferedReader(dataSourceConfig.File.Location)
 	return &benchmark{
-		dataSource: &fileDataSource{
-			scanner: bufio.NewScanner(br),
-		},
+		dataSource: ds,
 		serverURLs: vmSpecificConfig.ServerURLs,
 	}, nil
 }
@@ -6,10 +6,25 @@ import (
 
 	"github.com/bodhiye/tsbs/pkg/data"
 	"github.com/bodhiye/tsbs/pkg/data/usecases/common"
+	"github.com/bodhiye/tsbs/pkg/targets"
 )
 
+func newFileDataSource(r *bufio.Reader) targets.DataSource {
+	return &fileDataSource{scanner: targets.NewLineScanner(r)}
+}
+
 type fileDataSource struct {
-	scanner *bufio.Scanner
+	scanner *targets.LineScanner
+}
+
+// Offset implements targets.OffsetDataSource.
+func (f *fileDataSource) Offset() int64 {
+	return f.scanner.Offset()
+}
+
+// SkipTo implements targets.OffsetDataSource.
+func (f *fileDataSource) SkipTo(offset int64) error {
+	return f.scanner.SkipTo(offset)
 }
 
 func (f fileDataSource) NextItem() data.LoadedPoint {
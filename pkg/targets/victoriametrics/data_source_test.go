@@ -3,9 +3,11 @@ package victoriametrics
 import (
 	"bufio"
 	"bytes"
-	"github.com/bodhiye/tsbs/pkg/data"
 	"sync"
 	"testing"
+
+	"github.com/bodhiye/tsbs/pkg/data"
+	"github.com/bodhiye/tsbs/pkg/targets"
 )
 
 func TestBatch(t *testing.T) {
@@ -68,7 +70,7 @@ func TestDecode(t *testing.T) {
 
 	for _, c := range cases {
 		br := bufio.NewReader(bytes.NewReader([]byte(c.input)))
-		decoder := &fileDataSource{scanner: bufio.NewScanner(br)}
+		decoder := &fileDataSource{scanner: targets.NewLineScanner(br)}
 		p := decoder.NextItem()
 		dataBytes := p.Data.([]byte)
 		if !bytes.Equal(dataBytes, c.result) {
@@ -80,7 +82,7 @@ func TestDecode(t *testing.T) {
 func TestDecodeEOF(t *testing.T) {
 	input := []byte("cpu,tag1=tag1text,tag2=tag2text col1=0.0,col2=0.0 140")
 	br := bufio.NewReader(bytes.NewReader(input))
-	decoder := &fileDataSource{scanner: bufio.NewScanner(br)}
+	decoder := &fileDataSource{scanner: targets.NewLineScanner(br)}
 	_ = decoder.NextItem()
 	// nothing left, should be EOF
 	p := decoder.NextItem()
@@ -1,9 +1,14 @@
 package victoriametrics
 
 import (
+	"fmt"
+	"strings"
+	"sync"
+
 	"github.com/spf13/viper"
 	"github.com/bodhiye/tsbs/pkg/data/serialize"
 	"github.com/bodhiye/tsbs/pkg/data/source"
+	"github.com/bodhiye/tsbs/pkg/query"
 	"github.com/bodhiye/tsbs/pkg/targets"
 	"github.com/bodhiye/tsbs/pkg/targets/constants"
 	"github.com/bodhiye/tsbs/pkg/targets/influx"
@@ -41,3 +46,28 @@ func (vm vmTarget) TargetSpecificFlags(flagPrefix string, flagSet *pflag.FlagSet
 func (vm vmTarget) TargetName() string {
 	return constants.FormatVictoriaMetrics
 }
+
+func (vm vmTarget) QueryPool() *sync.Pool {
+	return &query.HTTPPool
+}
+
+func (vm vmTarget) QueryProcessorCreate(
+	_ string, runnerConfig *query.BenchmarkRunnerConfig, v *viper.Viper,
+) (query.ProcessorCreate, error) {
+	urls := v.GetString("query-urls")
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("missing `query-urls` flag")
+	}
+	return NewQueryProcessorCreate(&QueryOptions{
+		URLs:                 strings.Split(urls, ","),
+		PrettyPrintResponses: runnerConfig.PrintResponses,
+	}), nil
+}
+
+func (vm vmTarget) QueryFlags(flagPrefix string, flagSet *pflag.FlagSet) {
+	flagSet.String(
+		flagPrefix+"query-urls",
+		"http://localhost:8428",
+		"Comma-separated list of VictoriaMetrics URLs to run queries against (single-node or VMSelect)",
+	)
+}
@@ -18,15 +18,25 @@ func (p *processor) Init(workerNum int, doLoad, hashWorkers bool) {
 }
 
 func (p *processor) ProcessBatch(b targets.Batch, doLoad bool) (metricCount, rowCount uint64) {
+	mc, rc, err := p.ProcessBatchWithError(b, doLoad)
+	if err != nil {
+		log.Fatalf("error while executing request: %s", err)
+	}
+	return mc, rc
+}
+
+// ProcessBatchWithError writes the batch, retrying while the server returns
+// an unexpected HTTP status. Request errors are returned with the batch left
+// intact, so it can be retried.
+func (p *processor) ProcessBatchWithError(b targets.Batch, doLoad bool) (metricCount, rowCount uint64, err error) {
 	batch := b.(*batch)
 	if !doLoad {
-		return batch.metrics, batch.rows
+		return batch.metrics, batch.rows, nil
 	}
-	mc, rc := p.do(batch)
-	return mc, rc
+	return p.do(batch)
 }
 
-func (p *processor) do(b *batch) (uint64, uint64) {
+func (p *processor) do(b *batch) (uint64, uint64, error) {
 	for {
 		r := bytes.NewReader(b.buf.Bytes())
 		req, err := http.NewRequest("POST", p.url, r)
@@ -35,12 +45,12 @@ func (p *processor) do(b *batch) (uint64, uint64) {
 		}
 		resp, err := http.DefaultClient.Do(req)
 		if err != nil {
-			log.Fatalf("error while executing request: %s", err)
+			return 0, 0, err
 		}
 		resp.Body.Close()
 		if resp.StatusCode == http.StatusNoContent {
 			b.buf.Reset()
-			return b.metrics, b.rows
+			return b.metrics, b.rows, nil
 		}
 		log.Printf("server returned HTTP status %d. Retrying", resp.StatusCode)
 		time.Sleep(time.Millisecond * 10)
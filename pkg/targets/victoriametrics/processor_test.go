@@ -87,6 +87,47 @@ func TestProcessorProcessBatch(t *testing.T) {
 	}
 }
 
+func TestProcessorProcessBatchWithError(t *testing.T) {
+	f := &factory{bufPool: &sync.Pool{
+		New: func() interface{} {
+			return bytes.NewBuffer(make([]byte, 0, 1024))
+		},
+	}}
+	const point = "tag1=tag1val,tag2=tag2val col1=0.0,col2=0.0 140"
+	b := f.New().(*batch)
+	b.Append(data.LoadedPoint{Data: []byte(point)})
+
+	vm := startFakeVMServer(t)
+	vm.server.Close()
+	p := &processor{vmURLs: []string{vm.server.URL}}
+	p.Init(0, true, false)
+	if _, _, err := p.ProcessBatchWithError(b, true); err == nil {
+		t.Fatalf("expected an error for an unreachable server")
+	}
+
+	// the failed batch is left intact, so it can be retried or written to
+	// a dead-letter file
+	var buf bytes.Buffer
+	if _, err := b.WriteTo(&buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := buf.String(), point+"\n"; got != want {
+		t.Errorf("incorrect batch written: got %q want %q", got, want)
+	}
+
+	vm = startFakeVMServer(t)
+	defer vm.server.Close()
+	p = &processor{vmURLs: []string{vm.server.URL}}
+	p.Init(0, true, false)
+	metrics, rows, err := p.ProcessBatchWithError(b, true)
+	if err != nil {
+		t.Fatalf("unexpected error on retry: %v", err)
+	}
+	if metrics != 2 || rows != 1 {
+		t.Errorf("incorrect counts on retry: got %d metrics, %d rows", metrics, rows)
+	}
+}
+
 type fakeVMServer struct {
 	t      *testing.T
 	calls  uint64
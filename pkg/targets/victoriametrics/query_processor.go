@@ -0,0 +1,88 @@
+package victoriametrics
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/bodhiye/tsbs/pkg/query"
+)
+
+// QueryOptions configures the processors that run queries against
+// VictoriaMetrics.
+type QueryOptions struct {
+	// URLs of single-node VictoriaMetrics or VMSelect, assigned round robin
+	// to the workers
+	URLs                 []string
+	PrettyPrintResponses bool
+}
+
+// NewQueryProcessorCreate returns a function creating query processors that
+// run PromQL queries with the given options.
+func NewQueryProcessorCreate(opts *QueryOptions) query.ProcessorCreate {
+	return func() query.Processor { return &queryProcessor{opts: opts} }
+}
+
+// query.Processor interface implementation
+type queryProcessor struct {
+	url  string
+	opts *QueryOptions
+}
+
+// query.Processor interface implementation
+func (p *queryProcessor) Init(workerNum int) {
+	p.url = p.opts.URLs[workerNum%len(p.opts.URLs)]
+}
+
+// query.Processor interface implementation
+func (p *queryProcessor) ProcessQuery(q query.Query, isWarm bool) ([]*query.Stat, error) {
+	hq := q.(*query.HTTP)
+	lag, err := p.do(hq)
+	if err != nil {
+		return nil, err
+	}
+	stat := query.GetStat()
+	stat.Init(q.HumanLabelName(), lag)
+	return []*query.Stat{stat}, nil
+}
+
+func (p *queryProcessor) do(q *query.HTTP) (float64, error) {
+	// populate a request with data from the Query:
+	req, err := http.NewRequest(string(q.Method), p.url+string(q.Path), nil)
+	if err != nil {
+		return 0, fmt.Errorf("error while creating request: %s", err)
+	}
+
+	start := time.Now()
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("query execution error: %s", err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return 0, fmt.Errorf("error while reading response body: %s", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("non-200 statuscode received: %d; Body: %s", resp.StatusCode, string(body))
+	}
+	lag := float64(time.Since(start).Nanoseconds()) / 1e6 // milliseconds
+
+	// Pretty print JSON responses, if applicable:
+	if p.opts.PrettyPrintResponses {
+		var pretty bytes.Buffer
+		prefix := fmt.Sprintf("ID %d: ", q.GetID())
+		if err := json.Indent(&pretty, body, prefix, "  "); err != nil {
+			return lag, err
+		}
+		_, err = fmt.Fprintf(os.Stderr, "%s%s\n", prefix, pretty.Bytes())
+		if err != nil {
+			return lag, err
+		}
+	}
+	return lag, nil
+}
@@ -14,7 +14,6 @@ DATA_FILE=${DATA_FILE:-${BULK_DATA_DIR}/${DATA_FILE_NAME}}
 BATCH_SIZE=${BATCH_SIZE:-10000}
 # How many concurrent worker would load data - match num of cores, or default to 4
 NUM_WORKERS=${NUM_WORKERS:-$(grep -c ^processor /proc/cpuinfo 2> /dev/null || echo 4)}
-BACKOFF_SECS=${BACKOFF_SECS:-1s}
 REPORTING_PERIOD=${REPORTING_PERIOD:-10s}
 
 DO_CREATE_DB=${DO_CREATE_DB:-true}
@@ -24,7 +24,6 @@ curl -X POST http://${DATABASE_HOST}:${DATABASE_PORT}/query?q=drop%20database%20
 # Load new data
 cat ${DATA_FILE} | gunzip | $EXE_FILE_NAME \
                                 --db-name=${DATABASE_NAME} \
-                                --backoff=${BACKOFF_SECS} \
                                 --workers=${NUM_WORKERS} \
                                 --batch-size=${BATCH_SIZE} \
                                 --reporting-period=${REPORTING_PERIOD} \
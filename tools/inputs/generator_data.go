@@ -6,7 +6,6 @@ import (
 	"io"
 	"math/rand"
 	"os"
-	"sort"
 
 	"github.com/bodhiye/tsbs/pkg/data"
 	"github.com/bodhiye/tsbs/pkg/data/serialize"
@@ -31,6 +30,11 @@ type DataGenerator struct {
 	// Generate.
 	Out io.Writer
 
+	// Sink, if set, receives every point that is written out. Generation
+	// stays constant-memory when Sink is nil, since points are only
+	// serialized and counted.
+	Sink PointSink
+
 	config *common.DataGeneratorConfig
 
 	// bufOut represents the buffered writer that should actually be passed to
@@ -38,6 +42,32 @@ type DataGenerator struct {
 	bufOut *bufio.Writer
 }
 
+// PointSink is a consumer of the points produced by a DataGenerator. The
+// point passed to Consume is only valid for the duration of the call, so
+// implementations that retain it must make a copy (e.g. Point.DeepCopy).
+type PointSink interface {
+	Consume(*data.Point) error
+}
+
+// PointSinkFunc is an adapter to allow the use of ordinary functions as a
+// PointSink.
+type PointSinkFunc func(*data.Point) error
+
+// Consume calls f(p).
+func (f PointSinkFunc) Consume(p *data.Point) error {
+	return f(p)
+}
+
+// ChannelPointSink is a PointSink that sends a copy of every consumed point
+// to a channel. The channel is not closed by the sink.
+type ChannelPointSink chan<- *data.Point
+
+// Consume sends a deep copy of p to the channel.
+func (c ChannelPointSink) Consume(p *data.Point) error {
+	c <- p.DeepCopy()
+	return nil
+}
+
 func (g *DataGenerator) init(config common.GeneratorConfig) error {
 	if config == nil {
 		return fmt.Errorf(ErrNoConfig)
@@ -65,28 +95,34 @@ func (g *DataGenerator) init(config common.GeneratorConfig) error {
 	return nil
 }
 
-func (g *DataGenerator) Generate(config common.GeneratorConfig, target targets.ImplementedTarget) ([]*data.Point, error) {
+// Generate runs the configured simulator, serializing every point for the
+// target to the output and passing it to Sink if one is set. It returns the
+// number of points written.
+func (g *DataGenerator) Generate(config common.GeneratorConfig, target targets.ImplementedTarget) (uint64, error) {
 	err := g.init(config)
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
 
 	rand.Seed(g.config.Seed)
 
 	scfg, err := usecases.GetSimulatorConfig(g.config)
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
 
-	sim := scfg.NewSimulator(g.config.LogInterval, g.config.Limit)
+	sim := g.paced(scfg.NewSimulator(g.config.LogInterval, g.config.Limit))
 	serializer, err := g.getSerializer(sim, target)
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
 
 	return g.runSimulator(sim, serializer, g.config)
 }
 
+// CreateSimulator creates the configured simulator for a load to read its
+// points from. With several interleaved groups, the simulator only writes
+// the points of its group, like Generate.
 func (g *DataGenerator) CreateSimulator(config *common.DataGeneratorConfig) (common.Simulator, error) {
 	err := g.init(config)
 	if err != nil {
@@ -98,33 +134,50 @@ func (g *DataGenerator) CreateSimulator(config *common.DataGeneratorConfig) (com
 		return nil, err
 	}
 
-	return scfg.NewSimulator(g.config.LogInterval, g.config.Limit), nil
+	sim := scfg.NewSimulator(g.config.LogInterval, g.config.Limit)
+	if g.config.InterleavedNumGroups > 1 {
+		sim = common.NewInterleavedSimulator(sim, g.config.InterleavedGroupID, g.config.InterleavedNumGroups)
+	}
+	return g.paced(sim), nil
+}
+
+// paced paces the simulator in real time if configured.
+func (g *DataGenerator) paced(sim common.Simulator) common.Simulator {
+	if g.config.RealTime {
+		return common.NewPacedSimulator(sim, g.config.RealTimeAcceleration, g.config.RealTimeShiftToNow)
+	}
+	return sim
 }
 
-func (g *DataGenerator) runSimulator(sim common.Simulator, serializer serialize.PointSerializer, dgc *common.DataGeneratorConfig) ([]*data.Point, error) {
+func (g *DataGenerator) runSimulator(sim common.Simulator, serializer serialize.PointSerializer, dgc *common.DataGeneratorConfig) (uint64, error) {
 	defer g.bufOut.Flush()
 
 	currGroupID := uint(0)
-	var points = make([]*data.Point, 0)
+	written := uint64(0)
 	for !sim.Finished() {
 		point := data.NewPoint()
 		write := sim.Next(point)
 		if !write {
 			continue
 		}
-		points = append(points, point.DeepCopy())
 
 		// in the default case this is always true
 		if currGroupID == dgc.InterleavedGroupID {
 			err := serializer.Serialize(point, g.bufOut)
 			if err != nil {
-				return nil, fmt.Errorf("can not serialize point: %s", err)
+				return written, fmt.Errorf("can not serialize point: %s", err)
+			}
+			if g.Sink != nil {
+				if err := g.Sink.Consume(point); err != nil {
+					return written, fmt.Errorf("point sink failed: %s", err)
+				}
 			}
+			written++
 		}
 
 		currGroupID = (currGroupID + 1) % dgc.InterleavedNumGroups
 	}
-	return points, nil
+	return written, nil
 }
 
 func (g *DataGenerator) getSerializer(sim common.Simulator, target targets.ImplementedTarget) (serialize.PointSerializer, error) {
@@ -139,32 +192,6 @@ func (g *DataGenerator) getSerializer(sim common.Simulator, target targets.Imple
 	return target.Serializer(), nil
 }
 
-// TODO should be implemented in targets package
 func (g *DataGenerator) writeHeader(headers *common.GeneratedDataHeaders) {
-	g.bufOut.WriteString("tags")
-
-	types := headers.TagTypes
-	for i, key := range headers.TagKeys {
-		g.bufOut.WriteString(",")
-		g.bufOut.Write([]byte(key))
-		g.bufOut.WriteString(" ")
-		g.bufOut.WriteString(types[i])
-	}
-	g.bufOut.WriteString("\n")
-	// sort the keys so the header is deterministic
-	keys := make([]string, 0)
-	fields := headers.FieldKeys
-	for k := range fields {
-		keys = append(keys, k)
-	}
-	sort.Strings(keys)
-	for _, measurementName := range keys {
-		g.bufOut.WriteString(measurementName)
-		for _, field := range fields[measurementName] {
-			g.bufOut.WriteString(",")
-			g.bufOut.Write([]byte(field))
-		}
-		g.bufOut.WriteString("\n")
-	}
-	g.bufOut.WriteString("\n")
+	targets.WriteHeaders(g.bufOut, headers)
 }
@@ -60,6 +60,46 @@ func TestDataGeneratorInit(t *testing.T) {
 		InterleavedNumGroups: 1,
 	}
 
+	// Test that the custom use case needs a schema file
+	c.Use = common.UseCaseCustom
+	err = dg.init(c)
+	if err == nil {
+		t.Errorf("unexpected lack of error for custom use case without schema file")
+	}
+	c.Use = common.UseCaseDevops
+
+	// Test that the k8s rates cannot be negative
+	c.K8sChurnRate = -1
+	err = dg.init(c)
+	if err == nil {
+		t.Errorf("unexpected lack of error for negative k8s churn rate")
+	}
+	c.K8sChurnRate = 0
+
+	// Test that the ticks use case needs a positive rate
+	c.Use = common.UseCaseTicks
+	err = dg.init(c)
+	if err == nil {
+		t.Errorf("unexpected lack of error for ticks use case without rate")
+	}
+	c.Use = common.UseCaseDevops
+
+	// Test that the events message size cannot be negative
+	c.EventsMessageSize = -1
+	err = dg.init(c)
+	if err == nil {
+		t.Errorf("unexpected lack of error for negative events message size")
+	}
+	c.EventsMessageSize = 0
+
+	// Test that the devops tag counts cannot be negative
+	c.DevopsRacks = -1
+	err = dg.init(c)
+	if err == nil {
+		t.Errorf("unexpected lack of error for negative devops racks")
+	}
+	c.DevopsRacks = 0
+
 	// Test that Out is set to os.Stdout if unset
 	err = dg.init(c)
 	if err != nil {
@@ -110,11 +150,17 @@ func TestDataGeneratorGenerate(t *testing.T) {
 	dg.Out = &buf
 	mockSerializer := &mockSerializer{}
 	mockTarget.serializer = mockSerializer
-	_, err = dg.Generate(c, mockTarget)
+	points := make(chan *data.Point, c.Limit)
+	dg.Sink = ChannelPointSink(points)
+	numPoints, err := dg.Generate(c, mockTarget)
 	if err != nil {
 		t.Errorf("unexpected error when generating: got %v", err)
 	} else if len(mockSerializer.sentPoints) != int(c.Limit) {
 		t.Errorf("unexpected number of points sent to serializer. expected %d, got %d", c.Limit, len(mockSerializer.sentPoints))
+	} else if numPoints != c.Limit {
+		t.Errorf("unexpected number of points reported. expected %d, got %d", c.Limit, numPoints)
+	} else if len(points) != int(c.Limit) {
+		t.Errorf("unexpected number of points sent to sink. expected %d, got %d", c.Limit, len(points))
 	}
 }
 
@@ -257,12 +303,24 @@ func TestRunSimulator(t *testing.T) {
 		}
 		serializer := &testSerializer{shouldError: c.shouldError}
 
-		_, err := g.runSimulator(sim, serializer, dgc)
+		var sunk uint
+		g.Sink = PointSinkFunc(func(*data.Point) error {
+			sunk++
+			return nil
+		})
+
+		written, err := g.runSimulator(sim, serializer, dgc)
 		if c.shouldError && err == nil {
 			t.Errorf("%s: unexpected lack of error", c.desc)
 		} else if !c.shouldError && err != nil {
 			t.Errorf("%s: unexpected error: got %v", c.desc, err)
 		} else if !c.shouldError {
+			if written != uint64(c.wantPoints) {
+				t.Errorf("%s: incorrect number of points written: got %d want %d", c.desc, written, c.wantPoints)
+			}
+			if sunk != c.wantPoints {
+				t.Errorf("%s: incorrect number of points sent to sink: got %d want %d", c.desc, sunk, c.wantPoints)
+			}
 			scanner := bufio.NewScanner(bytes.NewReader(buf.Bytes()))
 			lines := uint(0)
 			for {
@@ -12,10 +12,15 @@ import (
 	"strings"
 	"time"
 
-	queryUtils "github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/utils"
-	"github.com/bodhiye/tsbs/pkg/data/usecases/common"
+	usesCommon "github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/common"
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/custom"
 	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/devops"
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/events"
 	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/iot"
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/k8s"
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/ticks"
+	queryUtils "github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/utils"
+	"github.com/bodhiye/tsbs/pkg/data/usecases/common"
 
 	"github.com/bodhiye/tsbs/pkg/query"
 	"github.com/bodhiye/tsbs/pkg/query/config"
@@ -34,6 +39,7 @@ const (
 	errUnknownUseCaseFmt        = "use case '%s' is undefined"
 	errCannotParseTimeFmt       = "cannot parse time from string '%s': %v"
 	errBadUseFmt                = "invalid use case specified: '%v'"
+	errNoCustomQueriesFile      = "query type '" + custom.LabelCustom + "' requires a custom queries file"
 )
 
 var UseCaseMatrix = map[string]map[string]queryUtils.QueryFillerMaker{
@@ -70,6 +76,21 @@ var UseCaseMatrix = map[string]map[string]queryUtils.QueryFillerMaker{
 		iot.LabelDailyActivity:                 iot.NewDailyTruckActivity,
 		iot.LabelBreakdownFrequency:            iot.NewTruckBreakdownFrequency,
 	},
+	"k8s": {
+		k8s.LabelNamespaceCPU: k8s.NewNamespaceCPU,
+		k8s.LabelTopPodsCPU:   k8s.NewTopPodsCPU(k8s.TopPodsCPULimit),
+	},
+	"ticks": {
+		ticks.LabelOHLCV:        ticks.NewOHLCV,
+		ticks.LabelVWAP + "-1":  ticks.NewVWAP(1),
+		ticks.LabelVWAP + "-10": ticks.NewVWAP(10),
+		ticks.LabelLastQuote:    ticks.NewLastQuote,
+	},
+	"events": {
+		events.LabelErrorsByService: events.NewErrorsByService,
+		events.LabelTraceLookup:     events.NewTraceLookup,
+		events.LabelMessageSearch:   events.NewMessageSearch,
+	},
 }
 
 // DevopsGeneratorMaker creates a query generator for devops use case
@@ -82,6 +103,21 @@ type IoTGeneratorMaker interface {
 	NewIoT(start, end time.Time, scale int) (queryUtils.QueryGenerator, error)
 }
 
+// K8sGeneratorMaker creates a query generator for k8s use case
+type K8sGeneratorMaker interface {
+	NewK8s(start, end time.Time, scale int) (queryUtils.QueryGenerator, error)
+}
+
+// TicksGeneratorMaker creates a query generator for ticks use case
+type TicksGeneratorMaker interface {
+	NewTicks(start, end time.Time, scale int) (queryUtils.QueryGenerator, error)
+}
+
+// EventsGeneratorMaker creates a query generator for events use case
+type EventsGeneratorMaker interface {
+	NewEvents(start, end time.Time, scale int) (queryUtils.QueryGenerator, error)
+}
+
 // QueryGenerator is a type of Generator for creating queries to test against a
 // database. The output is specific to the type of database (due to each using
 // different querying techniques, e.g. SQL or REST), but is consumed by TSBS
@@ -95,8 +131,12 @@ type QueryGenerator struct {
 	// will be os.Stderr.
 	DebugOut io.Writer
 
-	conf          *config.QueryGeneratorConfig
-	useCaseMatrix map[string]map[string]queryUtils.QueryFillerMaker
+	conf     *config.QueryGeneratorConfig
+	queryMix []config.QueryMixEntry
+	// customTemplates are the query templates of the custom query type, if
+	// it is generated.
+	customTemplates []*custom.Template
+	useCaseMatrix   map[string]map[string]queryUtils.QueryFillerMaker
 	// factories contains all the database implementations which can create
 	// devops query generators.
 	factories map[string]interface{}
@@ -128,12 +168,67 @@ func (g *QueryGenerator) Generate(config common.GeneratorConfig) ([]string, erro
 	if err != nil {
 		return nil, err
 	}
+	if err := g.initSelection(useGen); err != nil {
+		return nil, err
+	}
 
-	filler := g.useCaseMatrix[g.conf.Use][g.conf.QueryType](useGen)
+	filler, err := g.getFiller(useGen)
+	if err != nil {
+		return nil, err
+	}
 
 	return g.runQueryGeneration(useGen, filler, g.conf)
 }
 
+// getFiller returns the filler of the query type to generate, or one
+// interleaving the query types of the query mix by weight.
+func (g *QueryGenerator) getFiller(useGen queryUtils.QueryGenerator) (queryUtils.QueryFiller, error) {
+	if len(g.queryMix) == 1 {
+		return g.getQueryTypeFiller(useGen, g.queryMix[0].QueryType)
+	}
+	fillers := make([]queryUtils.QueryFiller, len(g.queryMix))
+	weights := make([]uint64, len(g.queryMix))
+	for i, e := range g.queryMix {
+		filler, err := g.getQueryTypeFiller(useGen, e.QueryType)
+		if err != nil {
+			return nil, err
+		}
+		fillers[i] = filler
+		weights[i] = e.Weight
+	}
+	return newQueryMixFiller(fillers, weights), nil
+}
+
+// getQueryTypeFiller returns the filler of a single query type. The custom
+// query type interleaves the queries of its templates by weight.
+func (g *QueryGenerator) getQueryTypeFiller(useGen queryUtils.QueryGenerator, queryType string) (queryUtils.QueryFiller, error) {
+	if queryType != custom.LabelCustom {
+		return g.useCaseMatrix[g.conf.Use][queryType](useGen), nil
+	}
+
+	core, err := custom.NewCore(g.tsStart, g.tsEnd, int(g.conf.Scale))
+	if err != nil {
+		return nil, err
+	}
+	if err := g.initSelection(core); err != nil {
+		return nil, err
+	}
+	fillers := make([]queryUtils.QueryFiller, len(g.customTemplates))
+	weights := make([]uint64, len(g.customTemplates))
+	for i, t := range g.customTemplates {
+		filler, err := custom.NewCustom(useGen, core, t)
+		if err != nil {
+			return nil, err
+		}
+		fillers[i] = filler
+		weights[i] = t.Weight
+	}
+	if len(fillers) == 1 {
+		return fillers[0], nil
+	}
+	return newQueryMixFiller(fillers, weights), nil
+}
+
 func (g *QueryGenerator) init(conf common.GeneratorConfig) error {
 	if conf == nil {
 		return fmt.Errorf(ErrNoConfig)
@@ -158,8 +253,20 @@ func (g *QueryGenerator) init(conf common.GeneratorConfig) error {
 		return fmt.Errorf(errBadUseFmt, g.conf.Use)
 	}
 
-	if _, ok := g.useCaseMatrix[g.conf.Use][g.conf.QueryType]; !ok {
-		return fmt.Errorf(errBadQueryTypeFmt, g.conf.Use, g.conf.QueryType)
+	g.queryMix, err = g.conf.QueryMixEntries()
+	if err != nil {
+		return err
+	}
+	for _, e := range g.queryMix {
+		if e.QueryType == custom.LabelCustom {
+			if err := g.initCustomTemplates(); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, ok := g.useCaseMatrix[g.conf.Use][e.QueryType]; !ok {
+			return fmt.Errorf(errBadQueryTypeFmt, g.conf.Use, e.QueryType)
+		}
 	}
 
 	g.tsStart, err = internalUtils.ParseUTCTime(g.conf.TimeStart)
@@ -186,6 +293,55 @@ func (g *QueryGenerator) init(conf common.GeneratorConfig) error {
 	return nil
 }
 
+// selectingCore is implemented by the cores of the use cases, which pick
+// the random hosts, trucks and time windows of the queries.
+type selectingCore interface {
+	SetEntitySelection(usesCommon.EntitySelection) error
+	SetWindowRecencyMean(time.Duration) error
+}
+
+// tagValueLengthSetter is implemented by the cores of the use cases picking
+// hosts, whose names depend on the padding of the tag values of the data.
+type tagValueLengthSetter interface {
+	SetTagValueLength(int)
+}
+
+// initSelection sets how the core of the queries picks their random hosts,
+// trucks and time windows, and how it pads the hostnames.
+func (g *QueryGenerator) initSelection(core interface{}) error {
+	if c, ok := core.(tagValueLengthSetter); ok {
+		c.SetTagValueLength(g.conf.DevopsTagValueLength)
+	}
+	c, ok := core.(selectingCore)
+	if !ok {
+		return nil
+	}
+	err := c.SetEntitySelection(usesCommon.EntitySelection{
+		Distribution:       g.conf.EntityDistribution,
+		ZipfExponent:       g.conf.EntityZipfExponent,
+		HotSetPercent:      g.conf.EntityHotSetPercent,
+		HotSetQueryPercent: g.conf.EntityHotSetQueryPercent,
+	})
+	if err != nil {
+		return err
+	}
+	return c.SetWindowRecencyMean(g.conf.WindowRecencyMean())
+}
+
+// initCustomTemplates reads the query templates of the custom query type
+// for the format queries are generated for.
+func (g *QueryGenerator) initCustomTemplates() error {
+	if g.conf.CustomQueriesFile == "" {
+		return fmt.Errorf(errNoCustomQueriesFile)
+	}
+	templates, err := custom.ReadTemplatesFile(g.conf.CustomQueriesFile, g.conf.Format)
+	if err != nil {
+		return err
+	}
+	g.customTemplates = templates
+	return nil
+}
+
 func (g *QueryGenerator) initFactories() error {
 	factoryMap := factories.InitQueryFactories(g.conf)
 	for db, fac := range factoryMap {
@@ -200,7 +356,7 @@ func (g *QueryGenerator) addFactory(database string, factory interface{}) error
 	validFactory := false
 
 	switch factory.(type) {
-	case DevopsGeneratorMaker, IoTGeneratorMaker:
+	case DevopsGeneratorMaker, IoTGeneratorMaker, K8sGeneratorMaker, TicksGeneratorMaker, EventsGeneratorMaker:
 		validFactory = true
 	}
 
@@ -231,6 +387,27 @@ func (g *QueryGenerator) getUseCaseGenerator(c *config.QueryGeneratorConfig) (qu
 		}
 
 		return iotFactory.NewIoT(g.tsStart, g.tsEnd, scale)
+	case common.UseCaseK8s:
+		k8sFactory, ok := factory.(K8sGeneratorMaker)
+		if !ok {
+			return nil, fmt.Errorf(errUseCaseNotImplementedFmt, c.Use, c.Format)
+		}
+
+		return k8sFactory.NewK8s(g.tsStart, g.tsEnd, scale)
+	case common.UseCaseTicks:
+		ticksFactory, ok := factory.(TicksGeneratorMaker)
+		if !ok {
+			return nil, fmt.Errorf(errUseCaseNotImplementedFmt, c.Use, c.Format)
+		}
+
+		return ticksFactory.NewTicks(g.tsStart, g.tsEnd, scale)
+	case common.UseCaseEvents:
+		eventsFactory, ok := factory.(EventsGeneratorMaker)
+		if !ok {
+			return nil, fmt.Errorf(errUseCaseNotImplementedFmt, c.Use, c.Format)
+		}
+
+		return eventsFactory.NewEvents(g.tsStart, g.tsEnd, scale)
 	case common.UseCaseDevops, common.UseCaseCPUOnly, common.UseCaseCPUSingle:
 		devopsFactory, ok := factory.(DevopsGeneratorMaker)
 		if !ok {
@@ -286,12 +463,13 @@ func (g *QueryGenerator) runQueryGeneration(useGen queryUtils.QueryGenerator, fi
 				}
 			}
 		}
-		hq := q.(*query.HTTP)
-		decodedPath, err := url.QueryUnescape(string(hq.Path))
-		if err != nil {
-			return nil, err
+		if hq, ok := q.(*query.HTTP); ok {
+			decodedPath, err := url.QueryUnescape(string(hq.Path))
+			if err != nil {
+				return nil, err
+			}
+			queries = append(queries, strings.TrimPrefix(decodedPath, "/query?q="))
 		}
-		queries = append(queries, strings.TrimPrefix(decodedPath, "/query?q="))
 		q.Release()
 
 		currentGroup++
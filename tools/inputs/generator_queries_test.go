@@ -8,7 +8,9 @@ import (
 	"io"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"reflect"
+	"regexp"
 	"strings"
 	"testing"
 	"time"
@@ -21,7 +23,11 @@ import (
 	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/databases/questdb"
 	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/databases/siridb"
 	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/databases/timescaledb"
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/custom"
 	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/devops"
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/events"
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/k8s"
+	"github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/uses/ticks"
 	queryUtils "github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/utils"
 	"github.com/bodhiye/tsbs/pkg/data/usecases/common"
 	"github.com/bodhiye/tsbs/pkg/query"
@@ -69,6 +75,54 @@ func TestQueryGeneratorConfigValidate(t *testing.T) {
 	}
 	c.QueryType = "foo"
 
+	// Test query mix validation
+	c.QueryMix = "foo:1,bar:2"
+	err = c.Validate()
+	if err == nil {
+		t.Errorf("unexpected lack of error for query type and query mix")
+	} else if got := err.Error(); got != config.ErrQueryTypeAndMixes {
+		t.Errorf("incorrect error for query type and query mix: got\n%s\nwant\n%s", got, config.ErrQueryTypeAndMixes)
+	}
+	c.QueryType = ""
+	err = c.Validate()
+	if err != nil {
+		t.Errorf("unexpected error for correct query mix: %v", err)
+	}
+	c.QueryMix = "foo:1,bar"
+	err = c.Validate()
+	if err == nil {
+		t.Errorf("unexpected lack of error for bad query mix")
+	}
+	c.QueryMix = ""
+	c.QueryType = "foo"
+
+	// Test time distribution validation
+	c.TimeDistribution = config.TimeDistributionRecent
+	err = c.Validate()
+	if err == nil {
+		t.Errorf("unexpected lack of error for recent time distribution without mean")
+	}
+	c.TimeRecencyMean = time.Hour
+	err = c.Validate()
+	if err != nil {
+		t.Errorf("unexpected error for correct time distribution: %v", err)
+	}
+	c.TimeDistribution = "foo"
+	err = c.Validate()
+	if err == nil {
+		t.Errorf("unexpected lack of error for unknown time distribution")
+	}
+	c.TimeDistribution = ""
+	c.TimeRecencyMean = 0
+
+	// Test devops tag value length validation
+	c.DevopsTagValueLength = -1
+	err = c.Validate()
+	if err == nil {
+		t.Errorf("unexpected lack of error for negative devops tag value length")
+	}
+	c.DevopsTagValueLength = 0
+
 	// Test groups validation
 	c.InterleavedNumGroups = 0
 	err = c.Validate()
@@ -373,6 +427,97 @@ func TestGetUseCaseGenerator(t *testing.T) {
 	}
 }
 
+func TestGetUseCaseGeneratorUseCases(t *testing.T) {
+	cases := []struct {
+		use       string
+		queryType string
+		maker     queryUtils.QueryFillerMaker
+		formats   []string
+		isFiller  func(queryUtils.QueryGenerator) bool
+	}{
+		{
+			use:       common.UseCaseK8s,
+			queryType: k8s.LabelNamespaceCPU,
+			maker:     k8s.NewNamespaceCPU,
+			formats:   []string{constants.FormatInflux, constants.FormatTimescaleDB},
+			isFiller: func(g queryUtils.QueryGenerator) bool {
+				_, ok := g.(k8s.NamespaceCPUFiller)
+				return ok
+			},
+		},
+		{
+			use:       common.UseCaseTicks,
+			queryType: ticks.LabelOHLCV,
+			maker:     ticks.NewOHLCV,
+			formats:   []string{constants.FormatInflux, constants.FormatQuestDB, constants.FormatTimescaleDB},
+			isFiller: func(g queryUtils.QueryGenerator) bool {
+				_, ok := g.(ticks.OHLCVFiller)
+				return ok
+			},
+		},
+		{
+			use:       common.UseCaseEvents,
+			queryType: events.LabelErrorsByService,
+			maker:     events.NewErrorsByService,
+			formats:   []string{constants.FormatInflux, constants.FormatQuestDB, constants.FormatTimescaleDB},
+			isFiller: func(g queryUtils.QueryGenerator) bool {
+				_, ok := g.(events.ErrorsByServiceFiller)
+				return ok
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.use, func(t *testing.T) {
+			c := &config.QueryGeneratorConfig{
+				BaseConfig: common.BaseConfig{
+					Use:       tc.use,
+					Scale:     10,
+					TimeStart: defaultTimeStart,
+					TimeEnd:   defaultTimeEnd,
+				},
+				QueryType:            tc.queryType,
+				InterleavedNumGroups: 1,
+			}
+			g := &QueryGenerator{
+				factories: make(map[string]interface{}),
+				useCaseMatrix: map[string]map[string]queryUtils.QueryFillerMaker{
+					tc.use: {
+						tc.queryType: tc.maker,
+					},
+				},
+			}
+
+			for _, format := range tc.formats {
+				c.Format = format
+				if err := g.init(c); err != nil {
+					t.Fatalf("Error initializing query generator: %s", err)
+				}
+				useGen, err := g.getUseCaseGenerator(c)
+				if err != nil {
+					t.Errorf("unexpected error with format '%s': %v", format, err)
+				}
+				if !tc.isFiller(useGen) {
+					t.Errorf("format '%s' does not give a %s use case gen: got %T", format, tc.use, useGen)
+				}
+			}
+
+			// Test format without the use case
+			c.Format = constants.FormatCassandra
+			if err := g.init(c); err != nil {
+				t.Fatalf("Error initializing query generator: %s", err)
+			}
+			_, err := g.getUseCaseGenerator(c)
+			want := fmt.Sprintf(errUseCaseNotImplementedFmt, tc.use, constants.FormatCassandra)
+			if err == nil {
+				t.Errorf("unexpected lack of error for format without %s", tc.use)
+			} else if got := err.Error(); got != want {
+				t.Errorf("incorrect error:\ngot\n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
+
 // Decoded previously
 var wantQueries = []query.TimescaleDB{
 	{
@@ -623,3 +768,230 @@ func TestQueryGeneratorGenerate(t *testing.T) {
 	}
 	checkGeneratedOutput(t, &buf)
 }
+
+func TestQueryGeneratorGenerateQueryMix(t *testing.T) {
+	const (
+		label1h  = "TimescaleDB 1 cpu metric(s), random    1 hosts, random 1h0m0s by 1m"
+		label12h = "TimescaleDB 1 cpu metric(s), random    1 hosts, random 12h0m0s by 1m"
+	)
+	c, g := getTestConfigAndGenerator()
+	c.Limit = 400
+	c.QueryType = ""
+	c.QueryMix = "single-groupby-1-1-1:3,single-groupby-1-1-12:1"
+	g.useCaseMatrix[common.UseCaseCPUOnly]["single-groupby-1-1-12"] = devops.NewSingleGroupby(1, 1, 12)
+	var buf bytes.Buffer
+	g.Out = &buf
+	g.DebugOut = ioutil.Discard
+	_, err := g.Generate(c)
+	if err != nil {
+		t.Fatalf("unexpected error when generating: got %v", err)
+	}
+
+	// Query types are mixed by weight
+	labels := countQueryLabels(t, &buf)
+	checkQueryMix(t, labels, map[string]int{label1h: 300, label12h: 100}, 40)
+
+	// Test that a query type of the mix missing from the use case fails
+	c, g = getTestConfigAndGenerator()
+	c.QueryType = ""
+	c.QueryMix = "single-groupby-1-1-1:3,bar:1"
+	_, err = g.Generate(c)
+	want := fmt.Sprintf(errBadQueryTypeFmt, common.UseCaseCPUOnly, "bar")
+	if err == nil {
+		t.Errorf("unexpected lack of error for bad query type in query mix")
+	} else if got := err.Error(); got != want {
+		t.Errorf("incorrect error for bad query type in query mix: got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestQueryGeneratorGenerateQueryMixGroups(t *testing.T) {
+	const (
+		label1h  = "TimescaleDB 1 cpu metric(s), random    1 hosts, random 1h0m0s by 1m"
+		label12h = "TimescaleDB 1 cpu metric(s), random    1 hosts, random 12h0m0s by 1m"
+	)
+	// Every interleaved group has the mix of all the queries, even when the
+	// number of groups divides the sum of the weights
+	for groupID := uint(0); groupID < 2; groupID++ {
+		c, g := getTestConfigAndGenerator()
+		c.Limit = 400
+		c.QueryType = ""
+		c.QueryMix = "single-groupby-1-1-1:1,single-groupby-1-1-12:1"
+		c.InterleavedGroupID = groupID
+		c.InterleavedNumGroups = 2
+		g.useCaseMatrix[common.UseCaseCPUOnly]["single-groupby-1-1-12"] = devops.NewSingleGroupby(1, 1, 12)
+		var buf bytes.Buffer
+		g.Out = &buf
+		g.DebugOut = ioutil.Discard
+		if _, err := g.Generate(c); err != nil {
+			t.Fatalf("unexpected error when generating group %d: got %v", groupID, err)
+		}
+
+		labels := countQueryLabels(t, &buf)
+		checkQueryMix(t, labels, map[string]int{label1h: 100, label12h: 100}, 30)
+	}
+}
+
+// countQueryLabels returns the number of queries of each label generated to r.
+func countQueryLabels(t *testing.T, r io.Reader) map[string]int {
+	labels := make(map[string]int)
+	decoder := gob.NewDecoder(r)
+	for {
+		var q query.TimescaleDB
+		if err := decoder.Decode(&q); err == io.EOF {
+			return labels
+		} else if err != nil {
+			t.Fatalf("unexpected error while decoding query: got %v", err)
+		}
+		labels[string(q.HumanLabel)]++
+	}
+}
+
+// checkQueryMix checks that the number of queries of each label is within
+// tolerance of the wanted one.
+func checkQueryMix(t *testing.T, labels, want map[string]int, tolerance int) {
+	if len(labels) != len(want) {
+		t.Errorf("incorrect query labels: got %v want %v", labels, want)
+	}
+	for label, count := range want {
+		if got := labels[label]; got < count-tolerance || got > count+tolerance {
+			t.Errorf("incorrect number of queries of %s: got %d want %d±%d", label, got, count, tolerance)
+		}
+	}
+}
+
+func TestQueryGeneratorGenerateSelection(t *testing.T) {
+	hostname := regexp.MustCompile(`'host_[0-9]+'`)
+	cases := []struct {
+		desc           string
+		tagValueLength int
+		distribution   string
+		wantHost       *regexp.Regexp
+		errMsg         string
+	}{
+		{
+			desc:           "padded hostnames",
+			tagValueLength: 10,
+			wantHost:       regexp.MustCompile(`^'host_[0-9]{5}'$`),
+		},
+		{
+			// the padding of a previous generator is not kept
+			desc:     "hostnames",
+			wantHost: regexp.MustCompile(`^'host_[1-9]?[0-9]'$`),
+		},
+		{
+			desc:         "unknown entity distribution",
+			distribution: "normal",
+			errMsg:       "unknown entity distribution 'normal'; choices are uniform, zipf and hot-set",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			conf, g := getTestConfigAndGenerator()
+			conf.DevopsTagValueLength = c.tagValueLength
+			conf.EntityDistribution = c.distribution
+			var buf bytes.Buffer
+			g.Out = &buf
+			g.DebugOut = ioutil.Discard
+			_, err := g.Generate(conf)
+			if c.errMsg != "" {
+				if err == nil {
+					t.Fatalf("unexpected lack of error")
+				} else if got := err.Error(); got != c.errMsg {
+					t.Errorf("incorrect error: got\n%s\nwant\n%s", got, c.errMsg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error when generating: got %v", err)
+			}
+
+			decoder := gob.NewDecoder(&buf)
+			for i := 0; i < int(conf.Limit); i++ {
+				var q query.TimescaleDB
+				if err := decoder.Decode(&q); err != nil {
+					t.Fatalf("unexpected error while decoding query %d: got %v", i, err)
+				}
+				hosts := hostname.FindAllString(string(q.SqlQuery), -1)
+				if len(hosts) == 0 {
+					t.Errorf("no hostname in query %d: %s", i, q.SqlQuery)
+				}
+				for _, host := range hosts {
+					if !c.wantHost.MatchString(host) {
+						t.Errorf("incorrect hostname in query %d: %s", i, host)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestQueryGeneratorGenerateCustom(t *testing.T) {
+	const (
+		labelDashboard = "TimescaleDB custom query dashboard"
+		labelLastpoint = "TimescaleDB custom query lastpoint"
+	)
+	fileName := filepath.Join(t.TempDir(), "custom.yaml")
+	contents := `
+- name: dashboard
+  weight: 2
+  hosts: 2
+  window: 1h
+  table: cpu
+  queries:
+    timescaledb: SELECT * FROM cpu WHERE hostname IN ({{join (quote .Hosts) ", "}}) AND time >= '{{rfc3339 .Start}}'
+- name: lastpoint
+  weight: 1
+  queries:
+    timescaledb: SELECT DISTINCT ON (hostname) * FROM cpu ORDER BY hostname, time DESC
+`
+	if err := ioutil.WriteFile(fileName, []byte(contents), 0644); err != nil {
+		t.Fatalf("could not write custom queries file: %v", err)
+	}
+
+	c, g := getTestConfigAndGenerator()
+	c.Limit = 4
+	c.QueryType = custom.LabelCustom
+	c.CustomQueriesFile = fileName
+	var buf bytes.Buffer
+	g.Out = &buf
+	g.DebugOut = ioutil.Discard
+	_, err := g.Generate(c)
+	if err != nil {
+		t.Fatalf("unexpected error when generating: got %v", err)
+	}
+
+	want := []struct {
+		label, table, sql string
+	}{
+		{labelDashboard, "cpu", "SELECT * FROM cpu WHERE hostname IN ('host_3', 'host_5') AND time >= '2016-01-01T04:05:29Z'"},
+		{labelDashboard, "cpu", "SELECT * FROM cpu WHERE hostname IN ('host_5', 'host_1') AND time >= '2016-01-01T02:08:47Z'"},
+		{labelDashboard, "cpu", "SELECT * FROM cpu WHERE hostname IN ('host_2', 'host_5') AND time >= '2016-01-01T14:38:18Z'"},
+		{labelLastpoint, "", "SELECT DISTINCT ON (hostname) * FROM cpu ORDER BY hostname, time DESC"},
+	}
+	decoder := gob.NewDecoder(&buf)
+	for i, w := range want {
+		var q query.TimescaleDB
+		if err := decoder.Decode(&q); err != nil {
+			t.Fatalf("unexpected error while decoding query %d: got %v", i, err)
+		}
+		if got := string(q.HumanLabel); got != w.label {
+			t.Errorf("incorrect label for query %d: got %s want %s", i, got, w.label)
+		}
+		if got := string(q.Hypertable); got != w.table {
+			t.Errorf("incorrect table for query %d: got %s want %s", i, got, w.table)
+		}
+		if got := string(q.SqlQuery); got != w.sql {
+			t.Errorf("incorrect query %d:\ngot\n%s\nwant\n%s", i, got, w.sql)
+		}
+	}
+
+	// Test that the custom query type requires a custom queries file
+	c, g = getTestConfigAndGenerator()
+	c.QueryType = custom.LabelCustom
+	_, err = g.Generate(c)
+	if err == nil {
+		t.Errorf("unexpected lack of error for custom query type without file")
+	} else if got := err.Error(); got != errNoCustomQueriesFile {
+		t.Errorf("incorrect error for custom query type without file: got\n%s\nwant\n%s", got, errNoCustomQueriesFile)
+	}
+}
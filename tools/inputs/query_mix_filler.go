@@ -0,0 +1,50 @@
+package inputs
+
+import (
+	"math/rand"
+
+	queryUtils "github.com/bodhiye/tsbs/cmd/tsbs_generate_queries/utils"
+	"github.com/bodhiye/tsbs/pkg/query"
+)
+
+// queryMixFiller is a QueryFiller that interleaves the queries of several
+// fillers by weight. The filler of each query is drawn from the seeded
+// random source in proportion to the weights, so the queries of any subset,
+// like an interleaved generation group, have the same mix as all of them,
+// which a fixed pattern such as round-robin repeating every sum-of-weights
+// queries would not guarantee.
+type queryMixFiller struct {
+	fillers []queryUtils.QueryFiller
+	weights []int64
+	total   int64
+}
+
+func newQueryMixFiller(fillers []queryUtils.QueryFiller, weights []uint64) *queryMixFiller {
+	f := &queryMixFiller{
+		fillers: fillers,
+		weights: make([]int64, len(weights)),
+	}
+	for i, w := range weights {
+		f.weights[i] = int64(w)
+		f.total += int64(w)
+	}
+	return f
+}
+
+// next returns the index of the filler of the next query.
+func (f *queryMixFiller) next() int {
+	r := rand.Int63n(f.total)
+	for i, w := range f.weights {
+		if r < w {
+			return i
+		}
+		r -= w
+	}
+	return len(f.weights) - 1
+}
+
+// Fill fills in the query.Query with the details of the next query type of
+// the mix.
+func (f *queryMixFiller) Fill(q query.Query) query.Query {
+	return f.fillers[f.next()].Fill(q)
+}
@@ -21,3 +21,18 @@ func SetupConfigFile() error {
 
 	return nil
 }
+
+// SubWithFlags returns a Viper with the settings under key, like Sub, but
+// including the values of the flags bound to v, so a section can be set
+// entirely with flags.
+func SubWithFlags(v *viper.Viper, key string) (*viper.Viper, error) {
+	sub := viper.New()
+	settings, ok := v.AllSettings()[key].(map[string]interface{})
+	if !ok {
+		return sub, nil
+	}
+	if err := sub.MergeConfigMap(settings); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
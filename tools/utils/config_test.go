@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+func TestSubWithFlags(t *testing.T) {
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	fs.Uint("queries.runner.workers", 1, "")
+	fs.String("queries.runner.file", "", "")
+	if err := fs.Parse([]string{"--queries.runner.workers=4"}); err != nil {
+		t.Fatal(err)
+	}
+	v := viper.New()
+	if err := v.BindPFlags(fs); err != nil {
+		t.Fatal(err)
+	}
+	v.Set("queries.runner.file", "queries.gz")
+
+	queries, err := SubWithFlags(v, "queries")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	runner, err := SubWithFlags(queries, "runner")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := runner.GetUint("workers"); got != 4 {
+		t.Errorf("incorrect workers from flag: got %d want %d", got, 4)
+	}
+	if got := runner.GetString("file"); got != "queries.gz" {
+		t.Errorf("incorrect file: got %s want %s", got, "queries.gz")
+	}
+
+	missing, err := SubWithFlags(v, "loader")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(missing.AllKeys()) != 0 {
+		t.Errorf("expected no settings for a missing key, got %v", missing.AllKeys())
+	}
+}
@@ -20,6 +20,9 @@ const (
 type TimeInterval struct {
 	start time.Time
 	end   time.Time
+	// recencyMean is the mean distance of the windows of RandWindow from
+	// the end, or 0 for uniformly-random windows
+	recencyMean time.Duration
 }
 
 // NewTimeInterval creates a new TimeInterval for a given start and end. If end
@@ -28,7 +31,7 @@ func NewTimeInterval(start, end time.Time) (*TimeInterval, error) {
 	if end.Before(start) {
 		return nil, fmt.Errorf(ErrEndBeforeStart)
 	}
-	return &TimeInterval{start.UTC(), end.UTC()}, nil
+	return &TimeInterval{start: start.UTC(), end: end.UTC()}, nil
 }
 
 // Duration returns the time.Duration of the TimeInterval.
@@ -67,8 +70,22 @@ func (ti *TimeInterval) Overlap(other *TimeInterval) bool {
 	return true
 }
 
-// RandWindow creates a TimeInterval of duration `window` at a uniformly-random
-// start time within the time period represented by this TimeInterval.
+// SetWindowRecencyMean makes RandWindow pick windows biased towards the end
+// of the TimeInterval, like dashboards showing recent data do: the distance
+// of a window from the end is exponentially distributed with the given mean
+// (wrapped around at the start of the TimeInterval). A mean of 0 picks
+// windows uniformly again.
+func (ti *TimeInterval) SetWindowRecencyMean(mean time.Duration) error {
+	if mean < 0 {
+		return fmt.Errorf("window recency mean cannot be negative; got %v", mean)
+	}
+	ti.recencyMean = mean
+	return nil
+}
+
+// RandWindow creates a TimeInterval of duration `window` at a random start
+// time within the time period represented by this TimeInterval. The start
+// time is uniformly-random unless set otherwise with SetWindowRecencyMean.
 func (ti *TimeInterval) RandWindow(window time.Duration) (*TimeInterval, error) {
 	lower := ti.start.UnixNano()
 	upper := ti.end.Add(-window).UnixNano()
@@ -78,7 +95,13 @@ func (ti *TimeInterval) RandWindow(window time.Duration) (*TimeInterval, error)
 
 	}
 
-	start := lower + rand.Int63n(upper-lower)
+	var start int64
+	if ti.recencyMean > 0 {
+		offset := int64(rand.ExpFloat64()*float64(ti.recencyMean)) % (upper - lower)
+		start = upper - offset
+	} else {
+		start = lower + rand.Int63n(upper-lower)
+	}
 	end := start + window.Nanoseconds()
 
 	x, err := NewTimeInterval(time.Unix(0, start), time.Unix(0, end))
@@ -318,3 +318,37 @@ func TestTimeIntervalMustRandWindow(t *testing.T) {
 		})
 	}
 }
+
+func TestSetWindowRecencyMean(t *testing.T) {
+	start := time.Date(2016, time.January, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2016, time.January, 2, 0, 0, 0, 0, time.UTC)
+	ti, err := NewTimeInterval(start, end) // 1 day duration
+	if err != nil {
+		t.Fatalf("unexpected error creating TimeInterval: got %v", err)
+	}
+
+	err = ti.SetWindowRecencyMean(-time.Minute)
+	if err == nil {
+		t.Fatalf("unexpected lack of error")
+	}
+	if got, want := err.Error(), "window recency mean cannot be negative; got -1m0s"; got != want {
+		t.Errorf("unexpected error:\ngot\n%v\nwant\n%v", got, want)
+	}
+	if err := ti.SetWindowRecencyMean(time.Hour); err != nil {
+		t.Fatalf("unexpected error: got %v", err)
+	}
+
+	c := randWindowCase{desc: "recent window", window: time.Minute}
+	recent := 0
+	for i := 0; i < 1000; i++ {
+		x := ti.MustRandWindow(c.window)
+		c.checkTimeInterval(t, ti, x)
+		if end.Sub(x.End()) <= 3*time.Hour {
+			recent++
+		}
+	}
+	// with a mean of an hour, ~95% of the windows end in the last 3 hours
+	if recent < 900 {
+		t.Errorf("windows not biased towards the end: got %d of 1000 in the last 3 hours", recent)
+	}
+}